@@ -6,21 +6,25 @@ import (
 
 // Config contains configuration parameters for a batch policy.
 type Config struct {
-	ByteSize   int                `json:"byte_size" yaml:"byte_size"`
-	Count      int                `json:"count" yaml:"count"`
-	Check      string             `json:"check" yaml:"check"`
-	Period     string             `json:"period" yaml:"period"`
-	Processors []processor.Config `json:"processors" yaml:"processors"`
+	ByteSize     int                `json:"byte_size" yaml:"byte_size"`
+	Count        int                `json:"count" yaml:"count"`
+	MinCount     int                `json:"min_count" yaml:"min_count"`
+	Check        string             `json:"check" yaml:"check"`
+	Period       string             `json:"period" yaml:"period"`
+	PeriodJitter string             `json:"period_jitter" yaml:"period_jitter"`
+	Processors   []processor.Config `json:"processors" yaml:"processors"`
 }
 
 // NewConfig creates a default PolicyConfig.
 func NewConfig() Config {
 	return Config{
-		ByteSize:   0,
-		Count:      0,
-		Check:      "",
-		Period:     "",
-		Processors: []processor.Config{},
+		ByteSize:     0,
+		Count:        0,
+		MinCount:     0,
+		Check:        "",
+		Period:       "",
+		PeriodJitter: "",
+		Processors:   []processor.Config{},
 	}
 }
 
@@ -39,6 +39,15 @@ Allows you to configure a xref:configuration:batching.adoc[batching policy].`,
 				"A period in which an incomplete batch should be flushed regardless of its size.",
 				"1s", "1m", "500ms",
 			).HasDefault(""),
+			docs.FieldString(
+				"period_jitter",
+				"An optional maximum duration of random jitter to add to the period of each batch, which helps to avoid many pipelines flushing their batches at the same time.",
+				"100ms", "5s",
+			).Advanced().HasDefault(""),
+			docs.FieldInt(
+				"min_count",
+				"A minimum number of messages that must be collected before a batch can be flushed due to the period elapsing. If `0` the period flushes batches of any size.",
+			).Advanced().HasDefault(0),
 			docs.FieldBloblang(
 				"check",
 				"A xref:guides:bloblang/about.adoc[Bloblang query] that should return a boolean value indicating whether a message should end a batch.",
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"strconv"
 	"time"
 
@@ -21,13 +22,16 @@ import (
 type Batcher struct {
 	log log.Modular
 
-	byteSize  int
-	count     int
-	period    time.Duration
-	check     *mapping.Executor
-	procs     []iprocessor.V1
-	sizeTally int
-	parts     []*message.Part
+	byteSize     int
+	count        int
+	minCount     int
+	period       time.Duration
+	periodJitter time.Duration
+	nextPeriod   time.Duration
+	check        *mapping.Executor
+	procs        []iprocessor.V1
+	sizeTally    int
+	parts        []*message.Part
 
 	triggered bool
 	lastBatch time.Time
@@ -59,6 +63,18 @@ func New(conf batchconfig.Config, mgr bundle.NewManagement) (*Batcher, error) {
 			return nil, fmt.Errorf("failed to parse duration string: %v", err)
 		}
 	}
+	var periodJitter time.Duration
+	if conf.PeriodJitter != "" {
+		if periodJitter, err = time.ParseDuration(conf.PeriodJitter); err != nil {
+			return nil, fmt.Errorf("failed to parse period jitter duration string: %v", err)
+		}
+		if periodJitter < 0 {
+			return nil, errors.New("period jitter must not be negative")
+		}
+	}
+	if conf.MinCount < 0 {
+		return nil, errors.New("min count must not be negative")
+	}
 	var procs []iprocessor.V1
 	for i, pconf := range conf.Processors {
 		pMgr := mgr.IntoPath("processors", strconv.Itoa(i))
@@ -70,22 +86,40 @@ func New(conf batchconfig.Config, mgr bundle.NewManagement) (*Batcher, error) {
 	}
 
 	batchOn := mgr.Metrics().GetCounterVec("batch_created", "mechanism")
-	return &Batcher{
+	p := &Batcher{
 		log: mgr.Logger(),
 
-		byteSize: conf.ByteSize,
-		count:    conf.Count,
-		period:   period,
-		check:    check,
-		procs:    procs,
-
-		lastBatch: time.Now(),
+		byteSize:     conf.ByteSize,
+		count:        conf.Count,
+		minCount:     conf.MinCount,
+		period:       period,
+		periodJitter: periodJitter,
+		check:        check,
+		procs:        procs,
 
 		mSizeBatch:   batchOn.With("size"),
 		mCountBatch:  batchOn.With("count"),
 		mPeriodBatch: batchOn.With("period"),
 		mCheckBatch:  batchOn.With("check"),
-	}, nil
+	}
+	p.resetPeriod()
+	return p, nil
+}
+
+// resetPeriod marks the start of a new batch and, if a period jitter is
+// configured, picks a new randomised period for it.
+func (p *Batcher) resetPeriod() {
+	p.lastBatch = time.Now()
+	p.nextPeriod = p.period
+	if p.period > 0 && p.periodJitter > 0 {
+		p.nextPeriod += time.Duration(rand.Int63n(int64(p.periodJitter)))
+	}
+}
+
+// periodElapsed returns true if the period of the current batch has elapsed
+// and the minimum count of messages required for a period flush is met.
+func (p *Batcher) periodElapsed() bool {
+	return p.period > 0 && p.MinCountReached() && time.Since(p.lastBatch) > p.nextPeriod
 }
 
 //------------------------------------------------------------------------------
@@ -123,7 +157,7 @@ func (p *Batcher) Add(part *message.Part) bool {
 			p.log.Trace("Batching based on check query")
 		}
 	}
-	return p.triggered || (p.period > 0 && time.Since(p.lastBatch) > p.period)
+	return p.triggered || p.periodElapsed()
 }
 
 // Flush clears all messages stored by this batch policy. Returns nil if the
@@ -148,7 +182,7 @@ func (p *Batcher) Flush(ctx context.Context) message.Batch {
 func (p *Batcher) flushAny(ctx context.Context) []message.Batch {
 	var newMsg message.Batch
 	if len(p.parts) > 0 {
-		if !p.triggered && p.period > 0 && time.Since(p.lastBatch) > p.period {
+		if !p.triggered && p.periodElapsed() {
 			p.mPeriodBatch.Incr(1)
 			p.log.Trace("Batching based on period")
 		}
@@ -156,7 +190,7 @@ func (p *Batcher) flushAny(ctx context.Context) []message.Batch {
 	}
 	p.parts = nil
 	p.sizeTally = 0
-	p.lastBatch = time.Now()
+	p.resetPeriod()
 	p.triggered = false
 
 	if newMsg == nil {
@@ -181,14 +215,22 @@ func (p *Batcher) Count() int {
 	return len(p.parts)
 }
 
+// MinCountReached returns true if the number of currently buffered message
+// parts meets the minimum required in order for the batch to be flushed due to
+// a configured period.
+func (p *Batcher) MinCountReached() bool {
+	return len(p.parts) >= p.minCount
+}
+
 // UntilNext returns a duration indicating how long until the current batch
 // should be flushed due to a configured period. A negative duration indicates
-// a period has not been set.
+// a period has not been set, or that the minimum count required for a period
+// flush has not yet been reached.
 func (p *Batcher) UntilNext() time.Duration {
-	if p.period <= 0 {
+	if p.period <= 0 || !p.MinCountReached() {
 		return -1
 	}
-	tUntil := time.Until(p.lastBatch.Add(p.period))
+	tUntil := time.Until(p.lastBatch.Add(p.nextPeriod))
 	if tUntil <= 0 {
 		tUntil = 1
 	}
@@ -127,6 +127,67 @@ func TestPolicyPeriod(t *testing.T) {
 	}
 }
 
+func TestPolicyPeriodMinCount(t *testing.T) {
+	conf := batchconfig.NewConfig()
+	conf.Period = "100ms"
+	conf.MinCount = 2
+
+	pol, err := policy.New(conf, mock.NewManager())
+	require.NoError(t, err)
+
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	t.Cleanup(func() {
+		require.NoError(t, pol.Close(tCtx))
+		done()
+	})
+
+	assert.Less(t, pol.UntilNext(), time.Duration(0))
+	assert.False(t, pol.Add(message.NewPart([]byte("foo"))))
+	assert.False(t, pol.MinCountReached())
+	assert.Less(t, pol.UntilNext(), time.Duration(0))
+
+	// The period has elapsed but only triggers once the min count is reached
+	<-time.After(time.Millisecond * 200)
+	assert.True(t, pol.Add(message.NewPart([]byte("bar"))))
+	assert.True(t, pol.MinCountReached())
+	assert.Greater(t, pol.UntilNext(), time.Duration(0))
+
+	msg := pol.Flush(tCtx)
+	assert.Equal(t, [][]byte{[]byte("foo"), []byte("bar")}, message.GetAllBytes(msg))
+	assert.False(t, pol.MinCountReached())
+	assert.Less(t, pol.UntilNext(), time.Duration(0))
+}
+
+func TestPolicyPeriodJitter(t *testing.T) {
+	conf := batchconfig.NewConfig()
+	conf.Period = "100ms"
+	conf.PeriodJitter = "1s"
+
+	pol, err := policy.New(conf, mock.NewManager())
+	require.NoError(t, err)
+
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	t.Cleanup(func() {
+		require.NoError(t, pol.Close(tCtx))
+		done()
+	})
+
+	var sawJitter bool
+	for i := 0; i < 20; i++ {
+		v := pol.UntilNext()
+		assert.LessOrEqual(t, v, time.Millisecond*1100)
+		if v > time.Millisecond*110 {
+			sawJitter = true
+		}
+		_ = pol.Flush(tCtx)
+	}
+	assert.True(t, sawJitter)
+
+	conf.PeriodJitter = "-1s"
+	_, err = policy.New(conf, mock.NewManager())
+	require.Error(t, err)
+}
+
 func TestPolicySize(t *testing.T) {
 	conf := batchconfig.NewConfig()
 	conf.ByteSize = 10
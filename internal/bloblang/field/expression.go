@@ -92,3 +92,21 @@ func (e *Expression) String(index int, msg Message) (string, error) {
 	}
 	return string(b), nil
 }
+
+// Template returns the expression with each dynamic interpolation function
+// replaced by a placeholder string, which is useful for validating the static
+// portions of an expression ahead of resolving it.
+func (e *Expression) Template(placeholder string) string {
+	if len(e.resolvers) == 0 {
+		return e.static
+	}
+	var buf bytes.Buffer
+	for _, r := range e.resolvers {
+		if s, is := r.(StaticResolver); is {
+			_, _ = buf.WriteString(string(s))
+		} else {
+			_, _ = buf.WriteString(placeholder)
+		}
+	}
+	return buf.String()
+}
@@ -1,6 +1,7 @@
 package mapping
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -43,8 +44,10 @@ type Executor struct {
 	annotation string
 	input      []rune
 	maps       map[string]query.Function
+	params     []string
 	statements []Statement
 
+	pluginStates *query.PluginStates
 	maxMapStacks int
 }
 
@@ -71,6 +74,36 @@ func (e *Executor) SetMaxMapRecursion(m int) {
 	e.maxMapStacks = m
 }
 
+// SetParams configures the names of parameters that must be provided as
+// variables when the mapping is executed as a map.
+func (e *Executor) SetParams(params []string) {
+	e.params = params
+}
+
+// Params returns the names of parameters expected by the mapping when it is
+// executed as a map.
+func (e *Executor) Params() []string {
+	return e.params
+}
+
+// SetPluginStates configures the plugin states initialised during the parsing
+// of the mapping, which are cleaned up when the executor is closed.
+func (e *Executor) SetPluginStates(states *query.PluginStates) {
+	e.pluginStates = states
+}
+
+// PluginStates returns the plugin states initialised during the parsing of the
+// mapping, which may be nil.
+func (e *Executor) PluginStates() *query.PluginStates {
+	return e.pluginStates
+}
+
+// Close cleans up any plugin states that were initialised for the mapping. The
+// executor should not be used after it has been closed.
+func (e *Executor) Close(ctx context.Context) error {
+	return e.pluginStates.Close(ctx)
+}
+
 // Annotation returns a string annotation that describes the mapping executor.
 func (e *Executor) Annotation() string {
 	return e.annotation
@@ -16,6 +16,8 @@ type Context struct {
 	Methods      *query.MethodSet
 	namedContext *namedContext
 	importer     Importer
+	mapScope     *mapScope
+	pluginStates *query.PluginStates
 }
 
 // EmptyContext returns a parser context with no functions, methods or import
@@ -62,15 +64,35 @@ func (pCtx Context) HasNamedContext(name string) bool {
 	return false
 }
 
+// withMapScope returns a Context where maps and import namespaces declared
+// within a mapping are tracked by the provided scope.
+func (pCtx Context) withMapScope(scope *mapScope) Context {
+	pCtx.mapScope = scope
+	return pCtx
+}
+
+// WithPluginStates returns a Context where functions and methods are
+// initialised with access to the provided plugin states.
+func (pCtx Context) WithPluginStates(states *query.PluginStates) Context {
+	pCtx.pluginStates = states
+	return pCtx
+}
+
 // InitFunction attempts to initialise a function from the available
 // constructors of the parser context.
 func (pCtx Context) InitFunction(name string, args *query.ParsedParams) (query.Function, error) {
+	if args != nil {
+		args.SetPluginStates(pCtx.pluginStates)
+	}
 	return pCtx.Functions.Init(name, args)
 }
 
 // InitMethod attempts to initialise a method from the available constructors of
 // the parser context.
 func (pCtx Context) InitMethod(name string, target query.Function, args *query.ParsedParams) (query.Function, error) {
+	if args != nil {
+		args.SetPluginStates(pCtx.pluginStates)
+	}
 	return pCtx.Methods.Init(name, target, args)
 }
 
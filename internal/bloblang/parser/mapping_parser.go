@@ -3,6 +3,7 @@ package parser
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/Jeffail/gabs/v2"
@@ -19,11 +20,18 @@ import (
 func ParseMapping(pCtx Context, expr string) (*mapping.Executor, *Error) {
 	in := []rune(expr)
 
+	// Plugin states are shared by the whole mapping, including any mappings
+	// that it imports, and are closed along with the resulting executor.
+	if pCtx.pluginStates == nil {
+		pCtx = pCtx.WithPluginStates(query.NewPluginStates())
+	}
+
 	resDirectImport := singleRootImport(pCtx)(in)
 	if resDirectImport.Err != nil && resDirectImport.Err.IsFatal() {
 		return nil, resDirectImport.Err
 	}
 	if resDirectImport.Err == nil && len(resDirectImport.Remaining) == 0 {
+		resDirectImport.Payload.SetPluginStates(pCtx.pluginStates)
 		return resDirectImport.Payload, nil
 	}
 
@@ -37,6 +45,7 @@ func ParseMapping(pCtx Context, expr string) (*mapping.Executor, *Error) {
 	if res.Err != nil {
 		return nil, res.Err
 	}
+	res.Payload.SetPluginStates(pCtx.pluginStates)
 	return res.Payload, nil
 }
 
@@ -67,7 +76,8 @@ func parseExecutor(pCtx Context) Func[*mapping.Executor] {
 		maps := map[string]query.Function{}
 		statements := []mapping.Statement{}
 
-		statementPattern := mappingStatement(pCtx, true, maps)
+		scope := newMapScope(maps)
+		statementPattern := mappingStatement(pCtx.withMapScope(scope), true, maps)
 
 		res := statementPattern(DiscardedWhitespaceNewlineComments(input).Remaining)
 		if res.Err != nil {
@@ -100,6 +110,9 @@ func parseExecutor(pCtx Context) Func[*mapping.Executor] {
 				statements = append(statements, res.Payload)
 			}
 		}
+		if err := scope.resolveDeferred(); err != nil {
+			return Fail[*mapping.Executor](err, input)
+		}
 		return Success(mapping.NewExecutor("", input, maps, statements...), res.Remaining)
 	}
 }
@@ -183,22 +196,174 @@ var varNameParser = JoinStringPayloads(
 	),
 )
 
-var importParserComb = TakeOnly(2, Sequence(
-	Term("import"),
-	SpacesAndTabs,
-	MustBe(
+// mapScope tracks the maps and import namespaces declared so far within a
+// mapping so that maps can be called as functions.
+type mapScope struct {
+	maps       map[string]query.Function
+	params     map[string][]string
+	namespaces map[string]map[string]query.Function
+	deferred   []*deferredMapCall
+}
+
+// deferredMapCall is a call to a map that was not yet declared when the call
+// was parsed, which is resolved once the whole mapping has been parsed.
+type deferredMapCall struct {
+	name  string
+	args  []any
+	input []rune
+	err   error
+	fn    query.Function
+}
+
+func (d *deferredMapCall) Annotation() string {
+	return "map " + d.name
+}
+
+func (d *deferredMapCall) Exec(ctx query.FunctionContext) (any, error) {
+	return d.fn.Exec(ctx)
+}
+
+func (d *deferredMapCall) QueryTargets(ctx query.TargetsContext) (query.TargetsContext, []query.TargetPath) {
+	return d.fn.QueryTargets(ctx)
+}
+
+func newMapScope(maps map[string]query.Function) *mapScope {
+	return &mapScope{
+		maps:       maps,
+		params:     map[string][]string{},
+		namespaces: map[string]map[string]query.Function{},
+	}
+}
+
+func mapParams(fn query.Function) []string {
+	if e, ok := fn.(*mapping.Executor); ok {
+		return e.Params()
+	}
+	return nil
+}
+
+// call returns a function that calls a map within the scope, or false if
+// there is no scope. When the map has not yet been declared the call is
+// resolved once the mapping has been fully parsed, at which point notFoundErr
+// is returned if the map still doesn't exist.
+func (s *mapScope) call(input []rune, name string, args []any, notFoundErr error) (query.Function, bool, error) {
+	if s == nil {
+		return nil, false, nil
+	}
+	if params, exists := s.params[name]; exists {
+		fn, err := newMapCall(s.maps, name, params, args)
+		return fn, true, err
+	}
+	d := &deferredMapCall{name: name, args: args, input: input, err: notFoundErr}
+	s.deferred = append(s.deferred, d)
+	return d, true, nil
+}
+
+// resolveDeferred binds all calls to maps that were made before the map was
+// declared.
+func (s *mapScope) resolveDeferred() *Error {
+	for _, d := range s.deferred {
+		params, exists := s.params[d.name]
+		if !exists {
+			return NewFatalError(d.input, d.err)
+		}
+		var err error
+		if d.fn, err = newMapCall(s.maps, d.name, params, d.args); err != nil {
+			return NewFatalError(d.input, err)
+		}
+	}
+	s.deferred = nil
+	return nil
+}
+
+// namespaceCall returns a function that calls a map from an import namespace,
+// or false if the namespace does not exist.
+func (s *mapScope) namespaceCall(namespace, name string, args []any) (query.Function, bool, error) {
+	if s == nil {
+		return nil, false, nil
+	}
+	maps, exists := s.namespaces[namespace]
+	if !exists {
+		return nil, false, nil
+	}
+	m, exists := maps[name]
+	if !exists {
+		return nil, true, fmt.Errorf("map %v was not found in import %v", name, namespace)
+	}
+	fn, err := newMapCall(maps, name, mapParams(m), args)
+	return fn, true, err
+}
+
+// newMapCall binds the arguments of a call, which may be either all nameless or
+// all named, to the parameters of a map.
+func newMapCall(maps map[string]query.Function, name string, params []string, args []any) (query.Function, error) {
+	var namelessArgs []query.Function
+	var namedArgs map[string]query.Function
+	for _, arg := range args {
+		if nArg, isNamed := arg.(namedArg); isNamed {
+			if namedArgs == nil {
+				namedArgs = map[string]query.Function{}
+			}
+			if _, exists := namedArgs[nArg.name]; exists {
+				return nil, fmt.Errorf("duplicate named arg: %v", nArg.name)
+			}
+			namedArgs[nArg.name] = nArg.value.(query.Function)
+		} else {
+			namelessArgs = append(namelessArgs, arg.(query.Function))
+		}
+	}
+	if len(namelessArgs) > 0 && len(namedArgs) > 0 {
+		return nil, errors.New("cannot mix named and nameless arguments")
+	}
+
+	if namedArgs == nil {
+		if len(namelessArgs) != len(params) {
+			return nil, fmt.Errorf("map %v expected %v arguments, received %v", name, len(params), len(namelessArgs))
+		}
+		return query.NewMapCallFunction(maps, name, params, namelessArgs), nil
+	}
+
+	boundArgs := make([]query.Function, len(params))
+	for i, p := range params {
+		arg, exists := namedArgs[p]
+		if !exists {
+			return nil, fmt.Errorf("map %v missing argument: %v", name, p)
+		}
+		boundArgs[i] = arg
+		delete(namedArgs, p)
+	}
+	if len(namedArgs) > 0 {
+		unknown := make([]string, 0, len(namedArgs))
+		for k := range namedArgs {
+			unknown = append(unknown, k)
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("map %v has no parameters: %v", name, strings.Join(unknown, ", "))
+	}
+	return query.NewMapCallFunction(maps, name, params, boundArgs), nil
+}
+
+var importParserComb = Sequence(
+	FuncAsAny(Term("import")),
+	FuncAsAny(SpacesAndTabs),
+	FuncAsAny(MustBe(
 		Expect(
 			QuotedString,
 			"filepath",
 		),
-	),
-))
+	)),
+	FuncAsAny(Optional(TakeOnly(2, Sequence(
+		SpacesAndTabs,
+		Term("as"),
+		MustBe(Expect(TakeOnly(1, Sequence(SpacesAndTabs, varNameParser)), "namespace")),
+	)))),
+)
 
 func importParser(pCtx Context, maps map[string]query.Function) Func[string] {
 	return func(input []rune) Result[string] {
 		res := importParserComb(input)
 		if res.Err != nil {
-			return res
+			return Fail[string](res.Err, input)
 		}
 
 		if maps == nil {
@@ -208,7 +373,15 @@ func importParser(pCtx Context, maps map[string]query.Function) Func[string] {
 			)
 		}
 
-		fpath := res.Payload
+		fpath := res.Payload[2].(string)
+		namespace := res.Payload[3].(string)
+		if namespace != "" {
+			if _, exists := pCtx.mapScope.namespaces[namespace]; exists {
+				err := fmt.Errorf("import namespace collision: %v", namespace)
+				return Fail[string](NewFatalError(input, err), input)
+			}
+		}
+
 		contents, err := pCtx.importer.Import(fpath)
 		if err != nil {
 			return Fail[string](NewFatalError(input, fmt.Errorf("failed to read import: %w", err)), input)
@@ -228,12 +401,18 @@ func importParser(pCtx Context, maps map[string]query.Function) Func[string] {
 			return Fail[string](NewFatalError(input, err), input)
 		}
 
+		if namespace != "" {
+			pCtx.mapScope.namespaces[namespace] = exec.Maps()
+			return Success(fpath, res.Remaining)
+		}
+
 		collisions := []string{}
 		for k, v := range exec.Maps() {
 			if _, exists := maps[k]; exists {
 				collisions = append(collisions, k)
 			} else {
 				maps[k] = v
+				pCtx.mapScope.params[k] = mapParams(v)
 			}
 		}
 		if len(collisions) > 0 {
@@ -246,7 +425,7 @@ func importParser(pCtx Context, maps map[string]query.Function) Func[string] {
 }
 
 func mapParser(pCtx Context, maps map[string]query.Function) Func[string] {
-	p := Sequence(
+	header := Sequence(
 		FuncAsAny(Term("map")),
 		FuncAsAny(SpacesAndTabs),
 		// Prevents a missing path from being captured by the next parser
@@ -259,28 +438,38 @@ func mapParser(pCtx Context, maps map[string]query.Function) Func[string] {
 				"map name",
 			),
 		)),
-		FuncAsAny(SpacesAndTabs),
-		FuncAsAny(DelimitedPattern(
+		FuncAsAny(Optional(DelimitedPattern(
 			Sequence(
-				charSquigOpen,
+				charBracketOpen,
 				DiscardedWhitespaceNewlineComments,
 			),
-			// Prevent imports, maps and metadata assignments.
-			mappingStatement(pCtx, false, nil),
-			Sequence(
-				Discard(SpacesAndTabs),
-				NewlineAllowComment,
-				DiscardedWhitespaceNewlineComments,
-			),
-			Sequence(
-				DiscardedWhitespaceNewlineComments,
-				charSquigClose,
-			),
-		)),
+			MustBe(Expect(varNameParser, "parameter name")),
+			MustBe(Expect(Sequence(Discard(SpacesAndTabs), charComma, DiscardedWhitespaceNewlineComments), "comma")),
+			MustBe(Expect(Sequence(DiscardedWhitespaceNewlineComments, charBracketClose), "closing bracket")),
+		))),
+		FuncAsAny(SpacesAndTabs),
+	)
+
+	body := DelimitedPattern(
+		Sequence(
+			charSquigOpen,
+			DiscardedWhitespaceNewlineComments,
+		),
+		// Prevent imports, maps and metadata assignments.
+		mappingStatement(pCtx, false, nil),
+		Sequence(
+			Discard(SpacesAndTabs),
+			NewlineAllowComment,
+			DiscardedWhitespaceNewlineComments,
+		),
+		Sequence(
+			DiscardedWhitespaceNewlineComments,
+			charSquigClose,
+		),
 	)
 
 	return func(input []rune) Result[string] {
-		res := p(input)
+		res := header(input)
 		if res.Err != nil {
 			return Fail[string](res.Err, input)
 		}
@@ -294,14 +483,33 @@ func mapParser(pCtx Context, maps map[string]query.Function) Func[string] {
 
 		seqSlice := res.Payload
 		ident := seqSlice[2].(string)
-		stmtSlice := seqSlice[4].([]mapping.Statement)
+		params, _ := seqSlice[3].([]string)
 
 		if _, exists := maps[ident]; exists {
 			return Fail[string](NewFatalError(input, fmt.Errorf("map name collision: %v", ident)), input)
 		}
+		seenParams := map[string]struct{}{}
+		for _, p := range params {
+			if _, exists := seenParams[p]; exists {
+				return Fail[string](NewFatalError(input, fmt.Errorf("duplicate parameter %v in map %v", p, ident)), input)
+			}
+			seenParams[p] = struct{}{}
+		}
+
+		// The map is declared before its body is parsed so that it can call
+		// itself recursively.
+		pCtx.mapScope.params[ident] = params
+
+		bodyRes := body(res.Remaining)
+		if bodyRes.Err != nil {
+			delete(pCtx.mapScope.params, ident)
+			return Fail[string](bodyRes.Err, input)
+		}
 
-		maps[ident] = mapping.NewExecutor("map "+ident, input, maps, stmtSlice...)
-		return Success(ident, res.Remaining)
+		exec := mapping.NewExecutor("map "+ident, input, maps, bodyRes.Payload...)
+		exec.SetParams(params)
+		maps[ident] = exec
+		return Success(ident, bodyRes.Remaining)
 	}
 }
 
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
 	"github.com/redpanda-data/benthos/v4/internal/message"
 )
 
@@ -23,6 +24,9 @@ func TestMappingErrors(t *testing.T) {
 	require.NoError(t, os.WriteFile(noMapsFile, []byte(`foo = "this is valid but has no maps"`), 0o777))
 	require.NoError(t, os.WriteFile(goodMapFile, []byte(`map foo { foo = "this is valid" }`), 0o777))
 
+	paramMapFile := filepath.Join(dir, "param_map.blobl")
+	require.NoError(t, os.WriteFile(paramMapFile, []byte(`map add(a, b) { root = $a + $b }`), 0o777))
+
 	tests := map[string]struct {
 		mapping     string
 		errContains string
@@ -166,6 +170,64 @@ import "%v"
 foo = bar.apply("foo")`, goodMapFile),
 			errContains: fmt.Sprintf(`line 3 char 1: map name collisions from import '%v': [foo]`, goodMapFile),
 		},
+		"map call unknown map": {
+			mapping: `map foo(a) {
+  root = $a
+}
+root = bar(this)`,
+			errContains: `line 4 char 17: unrecognised function 'bar'`,
+		},
+		"map call wrong arg count": {
+			mapping: `map foo(a) {
+  root = $a
+}
+root = foo(this, 10)`,
+			errContains: `line 4 char 21: map foo expected 1 arguments, received 2`,
+		},
+		"map call before declaration wrong arg count": {
+			mapping: `root = foo()
+map foo(a) {
+  root = $a
+}`,
+			errContains: `line 1 char 13: map foo expected 1 arguments, received 0`,
+		},
+		"map call missing named arg": {
+			mapping: `map foo(a, b) {
+  root = $a
+}
+root = foo(a: this)`,
+			errContains: `line 4 char 20: map foo missing argument: b`,
+		},
+		"map call unknown named arg": {
+			mapping: `map foo(a) {
+  root = $a
+}
+root = foo(a: this, c: 10, b: 5)`,
+			errContains: `line 4 char 33: map foo has no parameters: b, c`,
+		},
+		"map duplicate param": {
+			mapping: `map foo(a, a) {
+  root = $a
+}
+root = foo(1, 2)`,
+			errContains: `line 1 char 1: duplicate parameter a in map foo`,
+		},
+		"namespaced import unknown map": {
+			mapping: fmt.Sprintf(`import "%v" as lib
+root = lib.nope(1)`, paramMapFile),
+			errContains: `line 2 char 19: map nope was not found in import lib`,
+		},
+		"namespaced import collision": {
+			mapping: fmt.Sprintf(`import "%v" as lib
+import "%v" as lib
+root = lib.add(1, 2)`, paramMapFile, goodMapFile),
+			errContains: `line 2 char 1: import namespace collision: lib`,
+		},
+		"namespaced import missing namespace": {
+			mapping: fmt.Sprintf(`import "%v" as
+root = 10`, paramMapFile),
+			errContains: `required: expected namespace`,
+		},
 		"quotes at root": {
 			mapping: `
 "root.something" = 5 + 2`,
@@ -196,6 +258,15 @@ func TestMappings(t *testing.T) {
 	directMapFile := filepath.Join(dir, "direct_map.blobl")
 	require.NoError(t, os.WriteFile(directMapFile, []byte(`root.nested = this`), 0o777))
 
+	libMapFile := filepath.Join(dir, "lib_map.blobl")
+	require.NoError(t, os.WriteFile(libMapFile, []byte(`map greet(name, greeting) {
+  root = "%s %s%s".format($greeting, $name, punctuation())
+}
+
+map punctuation {
+  root = "!"
+}`), 0o777))
+
 	type part struct {
 		Content string
 		Meta    map[string]any
@@ -530,6 +601,85 @@ root = this.apply("foo")`, goodMapFile),
 				Content: `{"foo":"this is valid","nested":{"outer":{"inner":"hello world"}}}`,
 			},
 		},
+		"test map called as function": {
+			mapping: `map add(a, b) {
+  root = $a + $b
+}
+root.sum = add(this.a, 2)
+root.named = add(b: 3, a: this.a)`,
+			input: []part{
+				{Content: `{"a":5}`},
+			},
+			output: part{
+				Content: `{"named":8,"sum":7}`,
+			},
+		},
+		"test map called before declaration": {
+			mapping: `root.sum = add(this.a, 2)
+root.context = nested()
+map add(a, b) {
+  root = $a + $b
+}
+map nested {
+  root = this.a
+}`,
+			input: []part{
+				{Content: `{"a":5}`},
+			},
+			output: part{
+				Content: `{"context":5,"sum":7}`,
+			},
+		},
+		"test recursive map call": {
+			mapping: `map fact(n) {
+  root = if $n <= 1 { 1 } else { $n * fact($n - 1) }
+}
+root = fact(this.n)`,
+			input: []part{
+				{Content: `{"n":5}`},
+			},
+			output: part{
+				Content: `120`,
+			},
+		},
+		"test map call variables isolated": {
+			mapping: `map foo(a) {
+  root.a = $a
+  root.b = $b | "none"
+}
+let b = "outer"
+root = foo(this.a)`,
+			input: []part{
+				{Content: `{"a":"inner"}`},
+			},
+			output: part{
+				Content: `{"a":"inner","b":"none"}`,
+			},
+		},
+		"test namespaced import": {
+			mapping: fmt.Sprintf(`import "%v" as lib
+
+root.a = lib.greet(this.name, "hello")
+root.b = lib.greet(greeting: "hi", name: "bob")
+root.c = this.lib.name`, libMapFile),
+			input: []part{
+				{Content: `{"name":"alice","lib":{"name":"not a map"}}`},
+			},
+			output: part{
+				Content: `{"a":"hello alice!","b":"hi bob!","c":"not a map"}`,
+			},
+		},
+		"test imported parameterized map": {
+			mapping: fmt.Sprintf(`import "%v"
+
+root = greet(this.name, "hello")`, libMapFile),
+			input: []part{
+				{Content: `{"name":"alice"}`},
+			},
+			output: part{
+				Content: `hello alice!`,
+			},
+		},
 		"test directly imported mapping": {
 			mapping: fmt.Sprintf(`from "%v"`, directMapFile),
 			input: []part{
@@ -599,3 +749,24 @@ root.baz = this.baz.slice(0, 10)
 		}
 	}
 }
+
+func TestMappingParameterizedMaps(t *testing.T) {
+	exec, err := ParseMapping(GlobalContext(), `map fact(n) {
+  root = if $n <= 1 { this.base } else { $n * fact($n - 1) }
+}
+root.applied = this.apply("fact")
+root.called = fact(this.n)`)
+	require.Nil(t, err)
+
+	// Walking the targets of a recursive map must terminate.
+	_, targets := exec.QueryTargets(query.TargetsContext{
+		Maps: exec.Maps(),
+	})
+	assert.Contains(t, targets, query.NewTargetPath(query.TargetValue, "base"))
+	assert.Contains(t, targets, query.NewTargetPath(query.TargetValue, "n"))
+
+	msg := message.QuickBatch([][]byte{[]byte(`{"n":3,"base":1}`)})
+	_, mapErr := exec.MapPart(0, msg)
+	require.Error(t, mapErr)
+	assert.Contains(t, mapErr.Error(), "map fact has parameters and must be called as a function")
+}
@@ -355,7 +355,16 @@ func functionParser(pCtx Context) Func[query.Function] {
 		targetFunc := seqSlice[0].(string)
 		params, err := pCtx.Functions.Params(targetFunc)
 		if err != nil {
-			return Fail[query.Function](NewFatalError(res.Remaining, err), input)
+			// Maps declared within the mapping can also be called as
+			// functions.
+			mapFn, isMap, mapErr := pCtx.mapScope.call(res.Remaining, targetFunc, seqSlice[1].([]any), err)
+			if isMap {
+				err = mapErr
+			}
+			if err != nil {
+				return Fail[query.Function](NewFatalError(res.Remaining, err), input)
+			}
+			return Success(mapFn, res.Remaining)
 		}
 
 		parsedParams, err := extractArgsParserResult(params, seqSlice[1].([]any))
@@ -370,3 +379,35 @@ func functionParser(pCtx Context) Func[query.Function] {
 		return Success(fn, res.Remaining)
 	}
 }
+
+func namespacedMapCallParser(pCtx Context) Func[query.Function] {
+	namespaceP := Sequence(varNameParser, charDot)
+	p := Sequence(FuncAsAny(Expect(varNameParser, "map")), FuncAsAny(functionArgsParser(pCtx)))
+
+	return func(input []rune) Result[query.Function] {
+		if pCtx.mapScope == nil || len(pCtx.mapScope.namespaces) == 0 {
+			return Fail[query.Function](NewError(input, "map call"), input)
+		}
+
+		nsRes := namespaceP(input)
+		if nsRes.Err != nil {
+			return Fail[query.Function](nsRes.Err, input)
+		}
+		namespace := nsRes.Payload[0]
+		if _, exists := pCtx.mapScope.namespaces[namespace]; !exists {
+			return Fail[query.Function](NewError(input, "map call"), input)
+		}
+
+		res := p(nsRes.Remaining)
+		if res.Err != nil {
+			return Fail[query.Function](res.Err, input)
+		}
+
+		seqSlice := res.Payload
+		fn, _, err := pCtx.mapScope.namespaceCall(namespace, seqSlice[0].(string), seqSlice[1].([]any))
+		if err != nil {
+			return Fail[query.Function](NewFatalError(res.Remaining, err), input)
+		}
+		return Success(fn, res.Remaining)
+	}
+}
@@ -12,6 +12,7 @@ func queryParser(pCtx Context) Func[query.Function] {
 			lambdaExpressionParser(pCtx),
 			bracketsExpressionParser(pCtx),
 			literalValueParser(pCtx),
+			namespacedMapCallParser(pCtx),
 			functionParser(pCtx),
 			metadataReferenceParser,
 			variableReferenceParser,
@@ -93,24 +93,52 @@ func arithmeticFunc[T any](lhs, rhs Function, op arithmeticOpFunc[T]) (Function,
 var ErrDivideByZero = errors.New("attempted to divide by zero")
 
 type (
-	intArithmeticFunc[T any]   func(left, right int64) (T, error)
-	uintArithmeticFunc[T any]  func(left, right uint64) (T, error)
-	floatArithmeticFunc[T any] func(left, right float64) (T, error)
+	intArithmeticFunc[T any]     func(left, right int64) (T, error)
+	uintArithmeticFunc[T any]    func(left, right uint64) (T, error)
+	floatArithmeticFunc[T any]   func(left, right float64) (T, error)
+	decimalArithmeticFunc[T any] func(left, right *value.Decimal) (T, error)
 )
 
-// Takes two arithmetic funcs, one for integer values and one for float values
-// and returns a generic arithmetic func. If both values can be represented as
-// integers the integer func is called, otherwise the float func is called.
+// decimalOperands returns both values as decimals when either of them is a
+// decimal, in which case the calculation should be made with decimals in order
+// to retain precision.
+func decimalOperands(left, right any) (leftDec, rightDec *value.Decimal, isDec bool, err error) {
+	_, leftIsDec := left.(*value.Decimal)
+	_, rightIsDec := right.(*value.Decimal)
+	if !leftIsDec && !rightIsDec {
+		return nil, nil, false, nil
+	}
+	if value.ITypeOf(left) != value.TNumber || value.ITypeOf(right) != value.TNumber {
+		return nil, nil, true, errors.New("not a number")
+	}
+	if leftDec, err = value.IToDecimal(left); err == nil {
+		rightDec, err = value.IToDecimal(right)
+	}
+	return leftDec, rightDec, true, err
+}
+
+// Takes arithmetic funcs for integer, float and decimal values and returns a
+// generic arithmetic func. If either value is a decimal the decimal func is
+// called, if both values can be represented as integers the integer func is
+// called, otherwise the float func is called.
 func numberDegradationFunc[T any](
 	op ArithmeticOperator,
 	uiFn uintArithmeticFunc[T],
 	iFn intArithmeticFunc[T],
 	fFn floatArithmeticFunc[T],
+	dFn decimalArithmeticFunc[T],
 ) arithmeticOpFunc[T] {
 	return func(lhs, rhs Function, left, right any) (t T, err error) {
 		left = value.ISanitize(left)
 		right = value.ISanitize(right)
 
+		if leftDec, rightDec, isDec, err := decimalOperands(left, right); isDec {
+			if err != nil {
+				return t, NewTypeMismatch(op.String(), lhs, rhs, left, right)
+			}
+			return dFn(leftDec, rightDec)
+		}
+
 		// If either value is a float then we degrade into a float calculation.
 		if leftFloat, leftIsFloat := left.(float64); leftIsFloat {
 			rightFloat, err := value.IGetNumber(right)
@@ -171,10 +199,22 @@ func prodOp(op ArithmeticOperator) (arithmeticOpFunc[any], bool) {
 			func(lhs, rhs float64) (any, error) {
 				return lhs * rhs, nil
 			},
+			func(lhs, rhs *value.Decimal) (any, error) {
+				return lhs.Mul(rhs)
+			},
 		), true
 	case ArithmeticDiv:
-		// Only executes on float values.
+		// Only executes on float or decimal values.
 		return func(lFn, rFn Function, left, right any) (any, error) {
+			if lhs, rhs, isDec, err := decimalOperands(left, right); isDec {
+				if err != nil {
+					return nil, NewTypeMismatch(op.String(), lFn, rFn, left, right)
+				}
+				if rhs.IsZero() {
+					return nil, ErrFrom(ErrDivideByZero, rFn)
+				}
+				return lhs.Quo(rhs)
+			}
 			lhs, err := value.IGetNumber(left)
 			if err != nil {
 				return nil, NewTypeMismatch(op.String(), lFn, rFn, left, right)
@@ -189,8 +229,17 @@ func prodOp(op ArithmeticOperator) (arithmeticOpFunc[any], bool) {
 			return lhs / rhs, nil
 		}, true
 	case ArithmeticMod:
-		// Only executes on integer values.
+		// Only executes on integer or decimal values.
 		return func(lFn, rFn Function, left, right any) (any, error) {
+			if lhs, rhs, isDec, err := decimalOperands(left, right); isDec {
+				if err != nil {
+					return nil, NewTypeMismatch(op.String(), lFn, rFn, left, right)
+				}
+				if rhs.IsZero() {
+					return nil, ErrFrom(ErrDivideByZero, rFn)
+				}
+				return lhs.Rem(rhs)
+			}
 			lhs, err := value.IGetInt(left)
 			if err != nil {
 				return nil, NewTypeMismatch(op.String(), lFn, rFn, left, right)
@@ -221,10 +270,13 @@ func sumOp(op ArithmeticOperator) (arithmeticOpFunc[any], bool) {
 			func(left, right float64) (any, error) {
 				return left + right, nil
 			},
+			func(left, right *value.Decimal) (any, error) {
+				return left.Add(right)
+			},
 		)
 		return func(lFn, rFn Function, left, right any) (any, error) {
 			switch left.(type) {
-			case float64, int, int64, uint64, json.Number:
+			case float64, int, int64, uint64, json.Number, *value.Decimal:
 				return numberAdd(lFn, rFn, left, right)
 			case string, []byte:
 				lhs, err := value.IGetString(left)
@@ -250,6 +302,9 @@ func sumOp(op ArithmeticOperator) (arithmeticOpFunc[any], bool) {
 			func(lhs, rhs float64) (any, error) {
 				return lhs - rhs, nil
 			},
+			func(lhs, rhs *value.Decimal) (any, error) {
+				return lhs.Sub(rhs)
+			},
 		), true
 	}
 	return nil, false
@@ -336,6 +391,8 @@ func compareOp(op ArithmeticOperator) (arithmeticOpFunc[bool], bool) {
 		return intCompareFn(left, right), nil
 	}, func(left, right float64) (bool, error) {
 		return floatCompareFn(left, right), nil
+	}, func(left, right *value.Decimal) (bool, error) {
+		return intCompareFn(int64(left.Cmp(right)), 0), nil
 	})
 
 	boolOpFn := compareBoolFn(op)
@@ -356,7 +413,7 @@ func compareOp(op ArithmeticOperator) (arithmeticOpFunc[bool], bool) {
 			}
 			return strOpFn(lhs, rhs), nil
 
-		case float64, int64, uint64:
+		case float64, int64, uint64, *value.Decimal:
 			if numOpFn == nil {
 				return false, NewTypeMismatch(op.String(), lFn, rFn, left, right)
 			}
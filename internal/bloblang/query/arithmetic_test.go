@@ -23,8 +23,21 @@ func TestArithmeticNumberDegradation(t *testing.T) {
 		func(left, right float64) (any, error) {
 			return left / right, nil
 		},
+		func(left, right *value.Decimal) (any, error) {
+			res, err := left.Quo(right)
+			if err != nil {
+				return nil, err
+			}
+			return res.String(), nil
+		},
 	)
 
+	mustDecimal := func(s string) *value.Decimal {
+		d, err := value.ParseDecimal(s)
+		require.NoError(t, err)
+		return d
+	}
+
 	testCases := []struct {
 		name   string
 		left   any
@@ -104,6 +117,30 @@ func TestArithmeticNumberDegradation(t *testing.T) {
 			right: "not a number",
 			err:   "cannot add types number (from left) and string (from right)",
 		},
+		{
+			name:   "left is decimal",
+			left:   mustDecimal("12.6"),
+			right:  uint32(3),
+			result: "4.2",
+		},
+		{
+			name:   "right is decimal",
+			left:   0.9,
+			right:  mustDecimal("0.3"),
+			result: "3",
+		},
+		{
+			name:   "both are decimal",
+			left:   mustDecimal("1"),
+			right:  mustDecimal("3"),
+			result: "0.3333333333333333333333333333333333",
+		},
+		{
+			name:  "right is invalid decimal",
+			left:  mustDecimal("1"),
+			right: "not a number",
+			err:   "cannot add types number (from left) and string (from right)",
+		},
 	}
 
 	for _, test := range testCases {
@@ -172,6 +172,7 @@ var (
 	MethodCategoryObjectAndArray = "Object & Array Manipulation"
 	MethodCategoryJWT            = "JSON Web Tokens"
 	MethodCategoryGeoIP          = "GeoIP"
+	MethodCategoryNetwork        = "Network"
 	MethodCategoryDeprecated     = "Deprecated"
 	MethodCategoryPlugin         = "Plugin"
 )
@@ -23,6 +23,22 @@ func (e ErrNoContext) Error() string {
 
 //------------------------------------------------------------------------------
 
+// ErrThrown is an error raised by the throw function, which carries an
+// optional code and metadata in addition to its message in order to allow
+// errors to be classified once they have been attached to a message.
+type ErrThrown struct {
+	Code     any
+	Message  string
+	Metadata map[string]any
+}
+
+// Error returns the message of the thrown error.
+func (e *ErrThrown) Error() string {
+	return e.Message
+}
+
+//------------------------------------------------------------------------------
+
 type errFrom struct {
 	from Function
 	err  error
@@ -181,6 +181,44 @@ func NewLiteralFunction(annotation string, v any) *Literal {
 
 //------------------------------------------------------------------------------
 
+var _ = registerFunction(
+	NewFunctionSpec(
+		FunctionCategoryMessage, "batch",
+		"Returns an array containing the JSON contents of every message of the batch being mapped, in order. This allows a mapping to aggregate values across a whole batch without the need to archive it into a single message first.",
+		NewExampleSpec("",
+			`root = this
+root.batch_total = batch().map_each(doc -> doc.price).sum()`,
+			`{"id":"a","price":5}`,
+			`{"batch_total":5,"id":"a","price":5}`,
+		),
+		NewExampleSpec("The array can be combined with the index of the current message in order to compare messages with their neighbours.",
+			`root = this
+root.is_duplicate = batch_index() > 0 && batch().index(batch_index() - 1).id == this.id`,
+		),
+	).AtVersion("4.29.0"),
+	func(*ParsedParams) (Function, error) {
+		return ClosureFunction("function batch", func(ctx FunctionContext) (any, error) {
+			values := make([]any, ctx.MsgBatch.Len())
+			for i := range values {
+				v, err := ctx.MsgBatch.Get(i).AsStructured()
+				if err != nil {
+					return nil, fmt.Errorf("message %v: %w", i, err)
+				}
+				values[i] = value.ISanitize(v)
+			}
+			return values, nil
+		}, func(ctx TargetsContext) (TargetsContext, []TargetPath) {
+			paths := []TargetPath{
+				NewTargetPath(TargetValue),
+			}
+			ctx = ctx.WithValues(paths)
+			return ctx, paths
+		}), nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleFunction(
 	NewFunctionSpec(
 		FunctionCategoryMessage, "batch_index",
@@ -265,6 +303,82 @@ var _ = registerSimpleFunction(
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleFunction(
+	NewFunctionSpec(
+		FunctionCategoryMessage, "tracing_trace_id",
+		"Provides the trace ID of the message tracing span as a hex encoded string. The returned value will be `null` if the message does not have a span.",
+		NewExampleSpec("",
+			`root.trace_id = tracing_trace_id()`,
+		),
+	).AtVersion("4.29.0"),
+	func(fCtx FunctionContext) (any, error) {
+		sc := tracing.GetSpanContext(fCtx.MsgBatch.Get(fCtx.Index))
+		if !sc.HasTraceID() {
+			return nil, nil
+		}
+		return sc.TraceID().String(), nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleFunction(
+	NewFunctionSpec(
+		FunctionCategoryMessage, "tracing_span_id",
+		"Provides the span ID of the message tracing span as a hex encoded string. The returned value will be `null` if the message does not have a span.",
+		NewExampleSpec("",
+			`root.span_id = tracing_span_id()`,
+		),
+	).AtVersion("4.29.0"),
+	func(fCtx FunctionContext) (any, error) {
+		sc := tracing.GetSpanContext(fCtx.MsgBatch.Get(fCtx.Index))
+		if !sc.HasSpanID() {
+			return nil, nil
+		}
+		return sc.SpanID().String(), nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerFunction(
+	NewFunctionSpec(
+		FunctionCategoryMessage, "tracing_set_attribute",
+		"Sets an attribute of the message tracing span and returns the value of the attribute, which allows it to be set and mapped in a single assignment. Boolean, number and string values are set as attributes of the same type, and other values are set as strings. Messages that do not have a span are unaffected.",
+		NewExampleSpec("",
+			`root = this
+root.user_id = tracing_set_attribute("user_id", this.user_id)`,
+			`{"user_id":"u123"}`,
+			`{"user_id":"u123"}`,
+		),
+	).Param(ParamString("key", "The key of the attribute.")).
+		Param(ParamAny("value", "The value of the attribute.")).
+		AtVersion("4.29.0").
+		MarkImpure(),
+	func(args *ParsedParams) (Function, error) {
+		key, err := args.FieldString("key")
+		if err != nil {
+			return nil, err
+		}
+		v, err := args.Field("value")
+		if err != nil {
+			return nil, err
+		}
+		attr := value.ISanitize(v)
+		switch attr.(type) {
+		case bool, int64, float64, string:
+		default:
+			attr = value.IToString(attr)
+		}
+		return ClosureFunction("function tracing_set_attribute", func(fCtx FunctionContext) (any, error) {
+			tracing.GetActiveSpan(fCtx.MsgBatch.Get(fCtx.Index)).SetAttribute(key, attr)
+			return v, nil
+		}, nil), nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerFunction(
 	NewDeprecatedFunctionSpec(
 		"count",
@@ -365,6 +479,46 @@ var _ = registerSimpleFunction(
 	},
 )
 
+func thrownErrorOf(ctx FunctionContext) *ErrThrown {
+	var thrown *ErrThrown
+	if err := ctx.MsgBatch.Get(ctx.Index).ErrorGet(); err != nil && errors.As(err, &thrown) {
+		return thrown
+	}
+	return nil
+}
+
+var _ = registerSimpleFunction(
+	NewFunctionSpec(
+		FunctionCategoryMessage, "error_code",
+		"If the error of a message was raised with the <<throw, `throw`>> function and given a code then this function returns that code, otherwise `null`. This allows messages to be handled differently depending on the kind of error that occurred.",
+		NewExampleSpec("",
+			`root = if error_code() == "E_NEGATIVE" { deleted() }`,
+		),
+	).AtVersion("4.29.0"),
+	func(ctx FunctionContext) (any, error) {
+		if thrown := thrownErrorOf(ctx); thrown != nil {
+			return value.IClone(thrown.Code), nil
+		}
+		return nil, nil
+	},
+)
+
+var _ = registerSimpleFunction(
+	NewFunctionSpec(
+		FunctionCategoryMessage, "error_metadata",
+		"If the error of a message was raised with the <<throw, `throw`>> function and given metadata then this function returns that metadata as an object, otherwise `null`.",
+		NewExampleSpec("",
+			`root.failure = error_metadata()`,
+		),
+	).AtVersion("4.29.0"),
+	func(ctx FunctionContext) (any, error) {
+		if thrown := thrownErrorOf(ctx); thrown != nil && thrown.Metadata != nil {
+			return value.IClone(thrown.Metadata), nil
+		}
+		return nil, nil
+	},
+)
+
 //------------------------------------------------------------------------------
 
 var _ = registerFunction(
@@ -826,7 +980,7 @@ var _ = registerSimpleFunction(
 var _ = registerFunction(
 	NewFunctionSpec(
 		FunctionCategoryGeneral, "throw",
-		"Throws an error similar to a regular mapping error. This is useful for abandoning a mapping entirely given certain conditions.",
+		"Throws an error similar to a regular mapping error. This is useful for abandoning a mapping entirely given certain conditions.\n\nThe error can either be a string or an object with a `message` field along with an optional `code` and an optional object of `metadata`. When a thrown error is attached to a message the code and metadata can be obtained downstream with the <<error_code, `error_code`>> and <<error_metadata, `error_metadata`>> functions.",
 		NewExampleSpec("",
 			`root.doc.type = match {
   this.exists("header.id") => "foo"
@@ -839,18 +993,65 @@ root.doc.contents = (this.body.content | this.thing.body)`,
 			`{"nothing":"matches"}`,
 			`Error("failed assignment (line 1): unknown type")`,
 		),
-	).Param(ParamString("why", "A string explanation for why an error was thrown, this will be added to the resulting error message.")),
+		NewExampleSpec("Errors thrown as objects can be classified by their code, which allows messages to be routed based on the kind of error that occurred.",
+			`root = if this.amount < 0 {
+  throw({"code": "E_NEGATIVE", "message": "amount must not be negative", "metadata": {"amount": this.amount}})
+} else { this }`,
+			`{"amount":10}`,
+			`{"amount":10}`,
+			`{"amount":-5}`,
+			`Error("failed assignment (line 1): amount must not be negative")`,
+		),
+	).Param(ParamAny("why", "A string explanation for why an error was thrown, this will be added to the resulting error message. Alternatively, an object containing a `message` string field, an optional `code` and an optional `metadata` object.")),
 	func(args *ParsedParams) (Function, error) {
-		msg, err := args.FieldString("why")
+		why, err := args.Field("why")
+		if err != nil {
+			return nil, err
+		}
+		thrown, err := newErrThrown(why)
 		if err != nil {
 			return nil, err
 		}
 		return ClosureFunction("function throw", func(_ FunctionContext) (any, error) {
-			return nil, errors.New(msg)
+			return nil, thrown
 		}, nil), nil
 	},
 )
 
+func newErrThrown(why any) (*ErrThrown, error) {
+	switch t := why.(type) {
+	case string:
+		return &ErrThrown{Message: t}, nil
+	case map[string]any:
+		thrown := &ErrThrown{}
+		for k, v := range t {
+			switch k {
+			case "message":
+				msg, ok := v.(string)
+				if !ok {
+					return nil, fmt.Errorf("field message: %w", value.NewTypeError(v, value.TString))
+				}
+				thrown.Message = msg
+			case "code":
+				thrown.Code = v
+			case "metadata":
+				meta, ok := v.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("field metadata: %w", value.NewTypeError(v, value.TObject))
+				}
+				thrown.Metadata = meta
+			default:
+				return nil, fmt.Errorf("unrecognised field %v, expected message, code or metadata", k)
+			}
+		}
+		if _, exists := t["message"]; !exists {
+			return nil, errors.New("field message is required")
+		}
+		return thrown, nil
+	}
+	return nil, value.NewTypeError(why, value.TString, value.TObject)
+}
+
 //------------------------------------------------------------------------------
 
 var _ = registerSimpleFunction(
@@ -870,6 +1071,133 @@ var _ = registerSimpleFunction(
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleFunction(
+	NewFunctionSpec(
+		FunctionCategoryGeneral, "uuid_v7",
+		"Generates a new time-ordered UUID version 7 each time it is invoked and prints a string representation. Version 7 UUIDs begin with a millisecond precision unix timestamp and are therefore sortable by the time at which they were generated.",
+		NewExampleSpec("", `root.id = uuid_v7()`),
+	).AtVersion("4.29.0"),
+	func(_ FunctionContext) (any, error) {
+		u7, err := uuid.NewV7()
+		if err != nil {
+			return nil, err
+		}
+		return u7.String(), nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	snowflakeTimeBits     = 63 - snowflakeNodeBits - snowflakeSequenceBits
+
+	snowflakeMaxNode     = 1<<snowflakeNodeBits - 1
+	snowflakeMaxSequence = 1<<snowflakeSequenceBits - 1
+
+	// The default epoch of snowflake IDs, which is 2010-11-04T01:42:54.657Z.
+	snowflakeDefaultEpochMillis = 1288834974657
+)
+
+// snowflakeGenerator produces snowflake IDs for a single node and epoch. The
+// generators are shared by all invocations of the snowflake function within a
+// process so that IDs are unique and increasing across mappings.
+type snowflakeGenerator struct {
+	mut       sync.Mutex
+	epochMs   int64
+	node      int64
+	lastMs    int64
+	sequence  int64
+	nowMillis func() int64
+}
+
+type snowflakeGeneratorKey struct {
+	node    int64
+	epochMs int64
+}
+
+var (
+	snowflakeGeneratorsMut sync.Mutex
+	snowflakeGenerators    = map[snowflakeGeneratorKey]*snowflakeGenerator{}
+)
+
+func getSnowflakeGenerator(node, epochMs int64) *snowflakeGenerator {
+	snowflakeGeneratorsMut.Lock()
+	defer snowflakeGeneratorsMut.Unlock()
+
+	key := snowflakeGeneratorKey{node: node, epochMs: epochMs}
+	if g, exists := snowflakeGenerators[key]; exists {
+		return g
+	}
+	g := &snowflakeGenerator{
+		epochMs:   epochMs,
+		node:      node,
+		nowMillis: func() int64 { return time.Now().UnixMilli() },
+	}
+	snowflakeGenerators[key] = g
+	return g
+}
+
+func (g *snowflakeGenerator) next() (int64, error) {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+
+	ms := g.nowMillis() - g.epochMs
+	if ms < 0 {
+		return 0, errors.New("the current time is before the snowflake epoch")
+	}
+	if ms <= g.lastMs {
+		// Either multiple IDs are generated within the same millisecond or
+		// the clock has gone backwards, in both cases we continue from the
+		// last timestamp in order to remain increasing.
+		if g.sequence++; g.sequence > snowflakeMaxSequence {
+			g.lastMs++
+			g.sequence = 0
+		}
+		ms = g.lastMs
+	} else {
+		g.lastMs = ms
+		g.sequence = 0
+	}
+	if ms >= 1<<snowflakeTimeBits {
+		return 0, errors.New("the current time exceeds the range of snowflake IDs for the epoch")
+	}
+	return ms<<(snowflakeNodeBits+snowflakeSequenceBits) | g.node<<snowflakeSequenceBits | g.sequence, nil
+}
+
+var _ = registerFunction(
+	NewFunctionSpec(
+		FunctionCategoryGeneral, "snowflake",
+		"Generates a new snowflake ID each time it is invoked, which is a 64-bit integer composed of a millisecond timestamp relative to an epoch, a node ID and a sequence number. Snowflake IDs are sortable by the time at which they were generated and are unique across processes as long as each process generating IDs uses a distinct node ID, which is commonly obtained from an environment variable.",
+		NewExampleSpec("", `root.id = snowflake()`),
+		NewExampleSpec("The node ID can be obtained from the environment in order to give each deployment a distinct range of IDs.", `root.id = snowflake(env("NODE_ID").number())`),
+	).AtVersion("4.29.0").
+		Param(ParamInt64("node_id", fmt.Sprintf("A node ID between 0 and %v that identifies the process generating IDs.", snowflakeMaxNode)).Default(0)).
+		Param(ParamTimestamp("epoch", "The epoch that the timestamps of IDs are relative to.").Default(time.UnixMilli(snowflakeDefaultEpochMillis).UTC().Format(time.RFC3339Nano))),
+	snowflakeFunction,
+)
+
+func snowflakeFunction(args *ParsedParams) (Function, error) {
+	node, err := args.FieldInt64("node_id")
+	if err != nil {
+		return nil, err
+	}
+	if node < 0 || node > snowflakeMaxNode {
+		return nil, fmt.Errorf("node_id must be between 0 and %v, got %v", snowflakeMaxNode, node)
+	}
+	epoch, err := args.FieldTimestamp("epoch")
+	if err != nil {
+		return nil, err
+	}
+	gen := getSnowflakeGenerator(node, epoch.UnixMilli())
+	return ClosureFunction("function snowflake", func(ctx FunctionContext) (any, error) {
+		return gen.next()
+	}, nil), nil
+}
+
+//------------------------------------------------------------------------------
+
 var _ = registerFunction(
 	NewFunctionSpec(
 		FunctionCategoryGeneral, "nanoid",
@@ -950,3 +1278,71 @@ func NewVarFunction(name string) Function {
 		return ctx, paths
 	})
 }
+
+// NewMapCallFunction creates a function that executes a map from a table of
+// maps with a list of arguments, where each argument is bound to a variable of
+// the map named after the corresponding parameter. The map is executed against
+// the current context of the caller and maps called from within it are
+// resolved from the same table.
+func NewMapCallFunction(maps map[string]Function, name string, params []string, args []Function) Function {
+	return &mapCallFunction{
+		maps:   maps,
+		name:   name,
+		params: params,
+		args:   args,
+	}
+}
+
+type mapCallFunction struct {
+	maps   map[string]Function
+	name   string
+	params []string
+	args   []Function
+}
+
+func (f *mapCallFunction) Annotation() string {
+	return "map " + f.name
+}
+
+func (f *mapCallFunction) Exec(ctx FunctionContext) (any, error) {
+	m, ok := f.maps[f.name]
+	if !ok {
+		return nil, fmt.Errorf("map %v was not found", f.name)
+	}
+
+	// ISOLATED VARIABLES
+	vars := make(map[string]any, len(f.params))
+	for i, p := range f.params {
+		v, err := f.args[i].Exec(ctx)
+		if err != nil {
+			return nil, err
+		}
+		vars[p] = v
+	}
+
+	ctx.Maps = f.maps
+	ctx.Vars = vars
+	return m.Exec(ctx)
+}
+
+func (f *mapCallFunction) QueryTargets(ctx TargetsContext) (TargetsContext, []TargetPath) {
+	var targets []TargetPath
+	for _, a := range f.args {
+		_, argTargets := a.QueryTargets(ctx)
+		targets = append(targets, argTargets...)
+	}
+
+	m, ok := f.maps[f.name]
+	if !ok {
+		return ctx, targets
+	}
+
+	// Recursive maps are only walked once.
+	mapCtx, first := ctx.withCalledMap(f)
+	if !first {
+		return ctx, targets
+	}
+	mapCtx.Maps = f.maps
+	_, mapTargets := m.QueryTargets(mapCtx)
+	return ctx, append(targets, mapTargets...)
+}
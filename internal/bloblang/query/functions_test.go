@@ -1,13 +1,20 @@
 package query
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 
 	"github.com/redpanda-data/benthos/v4/internal/message"
 )
@@ -40,6 +47,38 @@ func TestFunctions(t *testing.T) {
 		vars     map[string]any
 		index    int
 	}{
+		"check batch function": {
+			input: mustFunc("batch"),
+			messages: []easyMsg{
+				{content: `{"price":5}`},
+				{content: `{"price":7}`},
+				{content: `"foo"`},
+			},
+			index:  1,
+			output: []any{map[string]any{"price": json.Number("5")}, map[string]any{"price": json.Number("7")}, "foo"},
+		},
+		"check batch function sum": {
+			input: mustMethod(
+				mustMethod(
+					mustFunc("batch"),
+					"map_each", NewFieldFunction("price"),
+				),
+				"sum",
+			),
+			messages: []easyMsg{
+				{content: `{"price":5}`},
+				{content: `{"price":7}`},
+			},
+			output: 12.0,
+		},
+		"check batch function bad message": {
+			input: mustFunc("batch"),
+			messages: []easyMsg{
+				{content: `{"price":5}`},
+				{content: `not json`},
+			},
+			err: "message 1: invalid character 'o' in literal null (expecting 'u')",
+		},
 		"check throw function 1": {
 			input: mustFunc("throw", "foo"),
 			err:   "foo",
@@ -51,6 +90,13 @@ func TestFunctions(t *testing.T) {
 			),
 			output: "bar",
 		},
+		"check throw function object": {
+			input: mustFunc("throw", map[string]any{
+				"code":    "E1",
+				"message": "foo",
+			}),
+			err: "foo",
+		},
 		"check var function": {
 			input: mustMethod(
 				mustFunc("var", "foo"),
@@ -187,6 +233,72 @@ func TestFunctions(t *testing.T) {
 	}
 }
 
+func TestThrowFunctionErrorCode(t *testing.T) {
+	fn, err := InitFunctionHelper("throw", map[string]any{
+		"code":     int64(42),
+		"message":  "bad thing",
+		"metadata": map[string]any{"reason": "because"},
+	})
+	require.NoError(t, err)
+
+	_, thrownErr := fn.Exec(FunctionContext{})
+	require.EqualError(t, thrownErr, "bad thing")
+
+	errCodeFn, err := InitFunctionHelper("error_code")
+	require.NoError(t, err)
+	errMetaFn, err := InitFunctionHelper("error_metadata")
+	require.NoError(t, err)
+
+	batch := message.QuickBatch([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	batch.Get(0).ErrorSet(fmt.Errorf("failed assignment (line 1): %w", thrownErr))
+	batch.Get(1).ErrorSet(errors.New("plain error"))
+
+	for i, exp := range []struct {
+		code any
+		meta any
+	}{
+		{code: int64(42), meta: map[string]any{"reason": "because"}},
+		{},
+		{},
+	} {
+		ctx := FunctionContext{MsgBatch: batch, Index: i}
+
+		res, err := errCodeFn.Exec(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, exp.code, res, i)
+
+		res, err = errMetaFn.Exec(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, exp.meta, res, i)
+	}
+
+	stringFn, err := InitFunctionHelper("throw", "just a string")
+	require.NoError(t, err)
+	_, thrownErr = stringFn.Exec(FunctionContext{})
+	batch.Get(2).ErrorSet(thrownErr)
+
+	res, err := errCodeFn.Exec(FunctionContext{MsgBatch: batch, Index: 2})
+	require.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestThrowFunctionBadObject(t *testing.T) {
+	for _, test := range []struct {
+		why any
+		err string
+	}{
+		{why: map[string]any{"code": "E1"}, err: "field message is required"},
+		{why: map[string]any{"message": int64(5)}, err: "field message: expected string value, got number"},
+		{why: map[string]any{"message": "foo", "metadata": "bar"}, err: "field metadata: expected object value, got string"},
+		{why: map[string]any{"message": "foo", "reason": "bar"}, err: "unrecognised field reason, expected message, code or metadata"},
+		{why: int64(5), err: "expected string or object value, got number"},
+	} {
+		_, err := InitFunctionHelper("throw", test.why)
+		require.Error(t, err, test.why)
+		assert.Contains(t, err.Error(), test.err)
+	}
+}
+
 func TestFunctionTargets(t *testing.T) {
 	function := func(name string, args ...any) Function {
 		t.Helper()
@@ -277,6 +389,84 @@ func TestKsuidFunction(t *testing.T) {
 	assert.NotEmpty(t, res)
 }
 
+func TestUUIDV7Function(t *testing.T) {
+	e, err := InitFunctionHelper("uuid_v7")
+	require.NoError(t, err)
+
+	res, err := e.Exec(FunctionContext{})
+	require.NoError(t, err)
+
+	first, ok := res.(string)
+	require.True(t, ok)
+	require.Len(t, first, 36)
+	assert.Equal(t, byte('7'), first[14])
+
+	time.Sleep(time.Millisecond * 2)
+
+	res, err = e.Exec(FunctionContext{})
+	require.NoError(t, err)
+	assert.Greater(t, res, first)
+}
+
+func TestSnowflakeFunction(t *testing.T) {
+	e, err := InitFunctionHelper("snowflake", int64(5))
+	require.NoError(t, err)
+
+	before := time.Now().UnixMilli() - snowflakeDefaultEpochMillis
+
+	var last int64
+	for i := 0; i < 10000; i++ {
+		res, err := e.Exec(FunctionContext{})
+		require.NoError(t, err)
+
+		id, ok := res.(int64)
+		require.True(t, ok)
+		require.Greater(t, id, last)
+		last = id
+
+		assert.Equal(t, int64(5), (id>>snowflakeSequenceBits)&snowflakeMaxNode)
+		assert.GreaterOrEqual(t, id>>(snowflakeNodeBits+snowflakeSequenceBits), before)
+	}
+}
+
+func TestSnowflakeGenerator(t *testing.T) {
+	now := int64(1000)
+	g := &snowflakeGenerator{
+		epochMs:   0,
+		node:      1,
+		nowMillis: func() int64 { return now },
+	}
+
+	id, err := g.next()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000<<22|1<<12), id)
+
+	id, err = g.next()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000<<22|1<<12|1), id)
+
+	// Exhausting the sequence borrows from the next millisecond.
+	g.sequence = snowflakeMaxSequence
+	id, err = g.next()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1001<<22|1<<12), id)
+
+	// A clock moving backwards continues from the last timestamp.
+	now = 900
+	id, err = g.next()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1001<<22|1<<12|1), id)
+
+	now = -1
+	_, err = g.next()
+	require.EqualError(t, err, "the current time is before the snowflake epoch")
+}
+
+func TestSnowflakeFunctionBadNode(t *testing.T) {
+	_, err := InitFunctionHelper("snowflake", int64(1024))
+	require.EqualError(t, err, "node_id must be between 0 and 1023, got 1024")
+}
+
 func TestRandomInt(t *testing.T) {
 	e, err := InitFunctionHelper("random_int")
 	require.NoError(t, err)
@@ -491,3 +681,67 @@ func TestRandomIntWithinRange(t *testing.T) {
 	_, err = InitFunctionHelper("random_int", tsFn, 0, math.MaxInt64)
 	require.Error(t, err)
 }
+
+type attributeRecordingSpan struct {
+	noop.Span
+	sc    trace.SpanContext
+	attrs []attribute.KeyValue
+}
+
+func (s *attributeRecordingSpan) SpanContext() trace.SpanContext {
+	return s.sc
+}
+
+func (s *attributeRecordingSpan) IsRecording() bool {
+	return true
+}
+
+func (s *attributeRecordingSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.attrs = append(s.attrs, kv...)
+}
+
+func TestTracingFunctions(t *testing.T) {
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+
+	span := &attributeRecordingSpan{
+		sc: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  spanID,
+		}),
+	}
+	part := message.NewPart([]byte(`{}`))
+	part = part.WithContext(trace.ContextWithSpan(context.Background(), span))
+
+	exec := func(t *testing.T, batch message.Batch, name string, args ...any) any {
+		t.Helper()
+		fn, err := InitFunctionHelper(name, args...)
+		require.NoError(t, err)
+		res, err := fn.Exec(FunctionContext{MsgBatch: batch})
+		require.NoError(t, err)
+		return res
+	}
+
+	withSpan := message.Batch{part}
+	withoutSpan := message.QuickBatch([][]byte{[]byte(`{}`)})
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", exec(t, withSpan, "tracing_trace_id"))
+	assert.Equal(t, "00f067aa0ba902b7", exec(t, withSpan, "tracing_span_id"))
+	assert.Nil(t, exec(t, withoutSpan, "tracing_trace_id"))
+	assert.Nil(t, exec(t, withoutSpan, "tracing_span_id"))
+
+	assert.Equal(t, "bar", exec(t, withSpan, "tracing_set_attribute", "foo", "bar"))
+	assert.Equal(t, int64(5), exec(t, withSpan, "tracing_set_attribute", "num", int64(5)))
+	assert.Equal(t, true, exec(t, withSpan, "tracing_set_attribute", "flag", true))
+	assert.Equal(t, []any{"a", "b"}, exec(t, withSpan, "tracing_set_attribute", "list", []any{"a", "b"}))
+	assert.Equal(t, "baz", exec(t, withoutSpan, "tracing_set_attribute", "foo", "baz"))
+
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("foo", "bar"),
+		attribute.Int64("num", 5),
+		attribute.Bool("flag", true),
+		attribute.String("list", `["a","b"]`),
+	}, span.attrs)
+}
@@ -63,7 +63,7 @@ func arrayIterator(arr []any) Iterator {
 }
 
 func drainIter(iter Iterator) ([]any, error) {
-	var arr []any
+	arr := []any{}
 	if l, ok := iter.Len(); ok {
 		arr = make([]any, 0, l)
 	}
@@ -127,7 +127,7 @@ func (f *filterMethod) TryIterate(ctx FunctionContext) (Iterator, any, error) {
 	}
 	if iter == nil {
 		res, err = f.execFallback(ctx, res)
-		return nil, res, err
+		return nil, res, ErrFrom(err, f.target)
 	}
 	return closureIterator{
 		next: func() (any, error) {
@@ -139,11 +139,11 @@ func (f *filterMethod) TryIterate(ctx FunctionContext) (Iterator, any, error) {
 					}
 					return nil, err
 				}
-				f, err := f.mapFn.Exec(ctx.WithValue(v))
+				res, err := f.mapFn.Exec(ctx.WithValue(v))
 				if err != nil {
-					return nil, err
+					return nil, ErrFrom(err, f.target)
 				}
-				if b, _ := f.(bool); b {
+				if b, _ := res.(bool); b {
 					return v, nil
 				}
 			}
@@ -219,8 +219,9 @@ func (m *mapEachMethod) TryIterate(ctx FunctionContext) (Iterator, any, error) {
 	}
 	if iter == nil {
 		res, err = m.execFallback(ctx, res)
-		return nil, res, err
+		return nil, res, ErrFrom(err, m.target)
 	}
+	i := -1
 	return closureIterator{
 		next: func() (any, error) {
 			for {
@@ -232,9 +233,10 @@ func (m *mapEachMethod) TryIterate(ctx FunctionContext) (Iterator, any, error) {
 					return nil, err
 				}
 
+				i++
 				newV, err := m.mapFn.Exec(ctx.WithValue(v))
 				if err != nil {
-					return nil, ErrFrom(err, m.mapFn)
+					return nil, ErrFrom(fmt.Errorf("failed to process element %v: %w", i, ErrFrom(err, m.mapFn)), m.target)
 				}
 				switch newV.(type) {
 				case value.Delete:
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/Jeffail/gabs/v2"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -214,3 +215,44 @@ func BenchmarkFilterIter(b *testing.B) {
 		}
 	}
 }
+
+func TestIteratorMethodsChainedLazily(t *testing.T) {
+	var log []string
+	logFn := func(name string, fn func(v any) any) Function {
+		return ClosureFunction("", func(ctx FunctionContext) (any, error) {
+			v := *ctx.Value()
+			log = append(log, fmt.Sprintf("%v(%v)", name, v))
+			return fn(v), nil
+		}, nil)
+	}
+
+	var fn Function = NewLiteralFunction("", []any{int64(1), int64(2), int64(3)})
+
+	var err error
+	fn, err = InitMethodHelper("map_each", fn, logFn("double", func(v any) any {
+		return v.(int64) * 2
+	}))
+	require.NoError(t, err)
+
+	fn, err = InitMethodHelper("filter", fn, logFn("gt2", func(v any) any {
+		return v.(int64) > 2
+	}))
+	require.NoError(t, err)
+
+	fn, err = InitMethodHelper("map_each", fn, logFn("str", func(v any) any {
+		return fmt.Sprintf("n%v", v)
+	}))
+	require.NoError(t, err)
+
+	res, err := fn.Exec(FunctionContext{})
+	require.NoError(t, err)
+	assert.Equal(t, []any{"n4", "n6"}, res)
+
+	// Each element passes through the whole chain before the next element is
+	// processed, and therefore no intermediate arrays are created.
+	assert.Equal(t, []string{
+		"double(1)", "gt2(2)",
+		"double(2)", "gt2(4)", "str(4)",
+		"double(3)", "gt2(6)", "str(6)",
+	}, log)
+}
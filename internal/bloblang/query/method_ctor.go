@@ -64,6 +64,14 @@ func numberMethod(fn func(f *float64, i *int64, ui *uint64) (any, error)) simple
 			} else {
 				return nil, fmt.Errorf("failed to parse number: %v", err)
 			}
+		case *value.Decimal:
+			if di, err := t.Int64(); err == nil {
+				i = &di
+			} else if df, err := t.Float64(); err == nil {
+				f = &df
+			} else {
+				return nil, fmt.Errorf("failed to parse number: %v", err)
+			}
 		default:
 			return nil, value.NewTypeError(v, value.TNumber)
 		}
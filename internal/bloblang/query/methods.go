@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/Jeffail/gabs/v2"
+	"github.com/OneOfOne/xxhash"
 
 	"github.com/redpanda-data/benthos/v4/internal/value"
 )
@@ -58,6 +59,9 @@ func applyMethod(target Function, args *ParsedParams) (Function, error) {
 		if !ok {
 			return nil, fmt.Errorf("map %v was not found", targetMap)
 		}
+		if p, ok := m.(interface{ Params() []string }); ok && len(p.Params()) > 0 {
+			return nil, fmt.Errorf("map %v has parameters and must be called as a function", targetMap)
+		}
 
 		// ISOLATED VARIABLES
 		ctx.Vars = map[string]any{}
@@ -466,6 +470,49 @@ func orMethod(fn Function, args *ParsedParams) (Function, error) {
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"sample",
+		"Deterministically samples values at a given rate, returning `true` for a value when it is within the sample and `false` otherwise. The decision is made by hashing the value, so the same value always results in the same decision, which makes it possible to sample consistently by a key such as a user identifier. An optional seed key is mixed into the hash so that independent samples can be taken of the same values.",
+		NewExampleSpec("",
+			`root = if !this.user_id.sample(0.5) { deleted() }`,
+			`{"user_id":"bar"}`,
+			`{"user_id":"bar"}`,
+			`{"user_id":"baz"}`,
+			`<Message deleted>`,
+		),
+		NewExampleSpec("Using a different seed key for each experiment results in different, but still consistent, groupings of users.",
+			`root.in_experiment_a = this.user_id.sample(0.5, "experiment_a")
+root.in_experiment_b = this.user_id.sample(0.5, "experiment_b")`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamFloat("rate", "The proportion of values to sample, between 0 and 1.")).
+		Param(ParamString("seed_key", "A key to mix into the hash of each value.").Default("")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		rate, err := args.FieldFloat("rate")
+		if err != nil {
+			return nil, err
+		}
+		if rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("rate must be between 0 and 1, got %v", rate)
+		}
+		seedKey, err := args.FieldString("seed_key")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			h := xxhash.New64()
+			_, _ = h.WriteString(seedKey)
+			_, _ = h.Write([]byte{0})
+			_, _ = h.Write(value.IToBytes(v))
+			// The top 53 bits of the hash are scaled into the range [0,1).
+			return float64(h.Sum64()>>11)/(1<<53) < rate, nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"type", "",
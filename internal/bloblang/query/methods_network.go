@@ -0,0 +1,222 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/netip"
+
+	"github.com/redpanda-data/benthos/v4/internal/value"
+)
+
+func ipAddrFromValue(v any) (netip.Addr, error) {
+	var s string
+	switch t := v.(type) {
+	case string:
+		s = t
+	case []byte:
+		s = string(t)
+	default:
+		return netip.Addr{}, value.NewTypeError(v, value.TString)
+	}
+	return netip.ParseAddr(s)
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"parse_ip", "",
+	).InCategory(
+		MethodCategoryNetwork,
+		"Parses an IPv4 or IPv6 address and returns an object containing the address in its canonical form, the IP `version`, and a series of boolean fields that classify the address.",
+		NewExampleSpec("",
+			`root.ip = this.ip.parse_ip()`,
+			`{"ip":"192.168.0.10"}`,
+			`{"ip":{"address":"192.168.0.10","is_global_unicast":true,"is_link_local":false,"is_loopback":false,"is_multicast":false,"is_private":true,"is_unspecified":false,"version":4}}`,
+			`{"ip":"2001:0db8:0000:0000:0000:0000:0000:0001"}`,
+			`{"ip":{"address":"2001:db8::1","is_global_unicast":true,"is_link_local":false,"is_loopback":false,"is_multicast":false,"is_private":false,"is_unspecified":false,"version":6}}`,
+		),
+	).AtVersion("4.29.0"),
+	func(*ParsedParams) (simpleMethod, error) {
+		return func(v any, ctx FunctionContext) (any, error) {
+			addr, err := ipAddrFromValue(v)
+			if err != nil {
+				return nil, err
+			}
+			version := int64(6)
+			if addr.Is4() {
+				version = 4
+			}
+			return map[string]any{
+				"address":           addr.String(),
+				"version":           version,
+				"is_global_unicast": addr.IsGlobalUnicast(),
+				"is_link_local":     addr.IsLinkLocalUnicast(),
+				"is_loopback":       addr.IsLoopback(),
+				"is_multicast":      addr.IsMulticast(),
+				"is_private":        addr.IsPrivate(),
+				"is_unspecified":    addr.IsUnspecified(),
+			}, nil
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"in_cidr", "",
+	).InCategory(
+		MethodCategoryNetwork,
+		"Checks whether an IP address is within a CIDR range, or any of an array of CIDR ranges, and returns a boolean. IPv4 addresses mapped to IPv6 are treated as IPv4 addresses.",
+		NewExampleSpec("",
+			`root.internal = this.ip.in_cidr("10.0.0.0/8")`,
+			`{"ip":"10.1.2.3"}`,
+			`{"internal":true}`,
+			`{"ip":"11.1.2.3"}`,
+			`{"internal":false}`,
+		),
+		NewExampleSpec("",
+			`root.internal = this.ip.in_cidr(["10.0.0.0/8", "172.16.0.0/12", "fd00::/8"])`,
+			`{"ip":"fd12:3456::1"}`,
+			`{"internal":true}`,
+		),
+	).AtVersion("4.29.0").Param(ParamAny("range", "A CIDR range as a string, or an array of CIDR ranges.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		rangeV, err := args.Field("range")
+		if err != nil {
+			return nil, err
+		}
+		var rangeStrs []any
+		switch t := rangeV.(type) {
+		case string:
+			rangeStrs = []any{t}
+		case []any:
+			rangeStrs = t
+		default:
+			return nil, value.NewTypeError(rangeV, value.TString, value.TArray)
+		}
+		prefixes := make([]netip.Prefix, 0, len(rangeStrs))
+		for i, r := range rangeStrs {
+			rStr, ok := r.(string)
+			if !ok {
+				return nil, fmt.Errorf("range index %v: %w", i, value.NewTypeError(r, value.TString))
+			}
+			prefix, err := netip.ParsePrefix(rStr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse range: %w", err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			addr, err := ipAddrFromValue(v)
+			if err != nil {
+				return nil, err
+			}
+			addr = addr.Unmap().WithZone("")
+			for _, p := range prefixes {
+				if p.Contains(addr) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"ip_to_int", "",
+	).InCategory(
+		MethodCategoryNetwork,
+		"Converts an IP address into its integer representation. IPv4 addresses result in a 64-bit integer, and as IPv6 addresses exceed the range of 64-bit integers they result in an arbitrary-precision decimal number. The integer can be converted back into an IP address with <<int_to_ip, `int_to_ip`>>.",
+		NewExampleSpec("",
+			`root.ip_num = this.ip.ip_to_int()`,
+			`{"ip":"192.168.0.10"}`,
+			`{"ip_num":3232235530}`,
+			`{"ip":"2001:db8::1"}`,
+			`{"ip_num":42540766411282592856903984951653826561}`,
+		),
+	).AtVersion("4.29.0"),
+	func(*ParsedParams) (simpleMethod, error) {
+		return func(v any, ctx FunctionContext) (any, error) {
+			addr, err := ipAddrFromValue(v)
+			if err != nil {
+				return nil, err
+			}
+			if addr.Is4() {
+				b := addr.As4()
+				return int64(b[0])<<24 | int64(b[1])<<16 | int64(b[2])<<8 | int64(b[3]), nil
+			}
+			b := addr.As16()
+			return value.ParseDecimal(new(big.Int).SetBytes(b[:]).String())
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"int_to_ip", "",
+	).InCategory(
+		MethodCategoryNetwork,
+		"Converts an integer into an IP address of a given version, reversing the effect of <<ip_to_int, `ip_to_int`>>. The integer can be a number, including an arbitrary-precision decimal, or a string of decimal digits.",
+		NewExampleSpec("",
+			`root.ip = this.ip_num.int_to_ip()`,
+			`{"ip_num":3232235530}`,
+			`{"ip":"192.168.0.10"}`,
+		),
+		NewExampleSpec("",
+			`root.ip = this.ip_num.int_to_ip(6)`,
+			`{"ip_num":"42540766411282592856903984951653826561"}`,
+			`{"ip":"2001:db8::1"}`,
+		),
+	).AtVersion("4.29.0").Param(ParamInt64("version", "The IP version of the address, either 4 or 6.").Default(4)),
+	func(args *ParsedParams) (simpleMethod, error) {
+		version, err := args.FieldInt64("version")
+		if err != nil {
+			return nil, err
+		}
+		size := 4
+		switch version {
+		case 4:
+		case 6:
+			size = 16
+		default:
+			return nil, fmt.Errorf("version must be either 4 or 6, got %v", version)
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			n, err := ipBigIntFromValue(v)
+			if err != nil {
+				return nil, err
+			}
+			if n.Sign() < 0 || n.BitLen() > size*8 {
+				return nil, fmt.Errorf("value %v exceeds the range of IPv%v addresses", n, version)
+			}
+			b := make([]byte, size)
+			addr, _ := netip.AddrFromSlice(n.FillBytes(b))
+			return addr.String(), nil
+		}, nil
+	},
+)
+
+func ipBigIntFromValue(v any) (*big.Int, error) {
+	var s string
+	switch t := v.(type) {
+	case string:
+		s = t
+	case []byte:
+		s = string(t)
+	case json.Number:
+		s = t.String()
+	case *value.Decimal:
+		s = t.String()
+	default:
+		i, err := value.IToInt(v)
+		if err != nil {
+			return nil, err
+		}
+		return big.NewInt(i), nil
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("value %q is not an integer", s)
+	}
+	return n, nil
+}
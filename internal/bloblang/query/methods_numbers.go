@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"slices"
 
 	"github.com/redpanda-data/benthos/v4/internal/value"
 )
@@ -233,3 +234,234 @@ var _ = registerSimpleMethod(
 		}), nil
 	},
 )
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"parse_decimal", "",
+	).InCategory(
+		MethodCategoryNumbers,
+		"Parses a string or number into an arbitrary-precision decimal. Arithmetic where either operand is a decimal results in a decimal, where addition, subtraction and multiplication are exact and division is rounded to 34 significant digits. This makes decimals suitable for calculations where floating point rounding errors are unacceptable, such as those involving currency. Numbers are converted using their shortest representation, and so `0.1` becomes a decimal of exactly `0.1`. Decimals are serialised as JSON number literals with all of their digits, and can be converted back into floating point numbers with <<number, `number`>>.",
+		NewExampleSpec("",
+			`root.total = this.price.parse_decimal() * this.quantity`,
+			`{"price":"0.1","quantity":3}`,
+			`{"total":0.3}`,
+		),
+		NewExampleSpec("",
+			`root.sum = this.a.parse_decimal() + this.b`,
+			`{"a":0.1,"b":0.2}`,
+			`{"sum":0.3}`,
+		),
+	).AtVersion("4.29.0"),
+	func(*ParsedParams) (simpleMethod, error) {
+		return func(v any, ctx FunctionContext) (any, error) {
+			switch value.ITypeOf(v) {
+			case value.TNumber, value.TString, value.TBytes:
+				return value.IToDecimal(v)
+			}
+			return nil, value.NewTypeError(v, value.TNumber, value.TString)
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"round_decimal", "",
+	).InCategory(
+		MethodCategoryNumbers,
+		"Rounds a number or numerical string to a number of decimal places with a configurable rounding mode, returning a decimal. Values that are not decimals are converted as they would be by <<parse_decimal, `parse_decimal`>>. The supported rounding modes are `half_up` (ties away from zero), `half_even` (ties to the nearest even digit, also known as banker's rounding), `half_down` (ties towards zero), `up` (away from zero), `down` (towards zero), `ceiling` (towards positive infinity) and `floor` (towards negative infinity).",
+		NewExampleSpec("",
+			`root.rounded = this.value.parse_decimal().round_decimal(2)`,
+			`{"value":"2.345"}`,
+			`{"rounded":2.35}`,
+		),
+		NewExampleSpec("",
+			`root.rounded = this.value.round_decimal(places: 2, mode: "half_even")`,
+			`{"value":"2.345"}`,
+			`{"rounded":2.34}`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamInt64("places", "The number of decimal places to round to, negative values round to the left of the decimal point.").Default(0)).
+		Param(ParamString("mode", "The rounding mode to use.").Default("half_up")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		places, err := args.FieldInt64("places")
+		if err != nil {
+			return nil, err
+		}
+		if places > math.MaxInt32 || places < math.MinInt32 {
+			return nil, fmt.Errorf("places value %v is out of range", places)
+		}
+		mode, err := args.FieldString("mode")
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(value.DecimalRoundingModes, mode) {
+			return nil, fmt.Errorf("unrecognised rounding mode %q, expected one of %v", mode, value.DecimalRoundingModes)
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			switch value.ITypeOf(v) {
+			case value.TNumber, value.TString, value.TBytes:
+			default:
+				return nil, value.NewTypeError(v, value.TNumber, value.TString)
+			}
+			d, err := value.IToDecimal(v)
+			if err != nil {
+				return nil, err
+			}
+			return d.Round(int32(places), mode)
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+// strictInt extracts a 64-bit signed integer from a number without truncating
+// or wrapping it.
+func strictInt(v any) (int64, error) {
+	if value.ITypeOf(v) != value.TNumber {
+		return 0, value.NewTypeError(v, value.TInt)
+	}
+	return value.IToInt(v)
+}
+
+func checkedIntMethod(op func(lhs, rhs int64) (int64, error)) func(args *ParsedParams) (simpleMethod, error) {
+	return func(args *ParsedParams) (simpleMethod, error) {
+		rawRHS, err := args.Field("value")
+		if err != nil {
+			return nil, err
+		}
+		rhs, err := strictInt(rawRHS)
+		if err != nil {
+			return nil, fmt.Errorf("value argument: %w", err)
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			lhs, err := strictInt(v)
+			if err != nil {
+				return nil, err
+			}
+			return op(lhs, rhs)
+		}, nil
+	}
+}
+
+func intOverflowErr(lhs int64, op string, rhs int64) error {
+	return fmt.Errorf("integer overflow: %v %v %v exceeds the range of a 64-bit signed integer", lhs, op, rhs)
+}
+
+const checkedIntDescription = " Both the target and argument must be integers, or floating point numbers without a fractional part, and an error is returned rather than the result being converted into a floating point number or wrapping when it does not fit within a 64-bit signed integer."
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"checked_add", "",
+	).InCategory(
+		MethodCategoryNumbers,
+		"Adds an integer to the target integer."+checkedIntDescription,
+		NewExampleSpec("",
+			`root.next_id = this.id.checked_add(1)`,
+			`{"id":9007199254740993}`,
+			`{"next_id":9007199254740994}`,
+		),
+		NewExampleSpec("",
+			`root.next_id = this.id.checked_add(1).catch("overflow")`,
+			`{"id":9223372036854775807}`,
+			`{"next_id":"overflow"}`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamAny("value", "The integer to add.")),
+	checkedIntMethod(func(lhs, rhs int64) (int64, error) {
+		if (rhs > 0 && lhs > math.MaxInt64-rhs) || (rhs < 0 && lhs < math.MinInt64-rhs) {
+			return 0, intOverflowErr(lhs, "+", rhs)
+		}
+		return lhs + rhs, nil
+	}),
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"checked_sub", "",
+	).InCategory(
+		MethodCategoryNumbers,
+		"Subtracts an integer from the target integer."+checkedIntDescription,
+		NewExampleSpec("",
+			`root.offset = this.end.checked_sub(this.start)`,
+			`{"start":1000,"end":4500}`,
+			`{"offset":3500}`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamAny("value", "The integer to subtract.")),
+	checkedIntMethod(func(lhs, rhs int64) (int64, error) {
+		if (rhs < 0 && lhs > math.MaxInt64+rhs) || (rhs > 0 && lhs < math.MinInt64+rhs) {
+			return 0, intOverflowErr(lhs, "-", rhs)
+		}
+		return lhs - rhs, nil
+	}),
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"checked_mul", "",
+	).InCategory(
+		MethodCategoryNumbers,
+		"Multiplies the target integer by an integer."+checkedIntDescription,
+		NewExampleSpec("",
+			`root.shard_key = this.id.checked_mul(1000).checked_add(this.shard)`,
+			`{"id":123456789,"shard":7}`,
+			`{"shard_key":123456789007}`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamAny("value", "The integer to multiply by.")),
+	checkedIntMethod(func(lhs, rhs int64) (int64, error) {
+		if lhs == 0 || rhs == 0 {
+			return 0, nil
+		}
+		res := lhs * rhs
+		if res/rhs != lhs || (lhs == -1 && rhs == math.MinInt64) || (rhs == -1 && lhs == math.MinInt64) {
+			return 0, intOverflowErr(lhs, "*", rhs)
+		}
+		return res, nil
+	}),
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"checked_div", "",
+	).InCategory(
+		MethodCategoryNumbers,
+		"Divides the target integer by an integer, truncating the result towards zero."+checkedIntDescription,
+		NewExampleSpec("",
+			`root.bucket = this.id.checked_div(1000)`,
+			`{"id":123456789}`,
+			`{"bucket":123456}`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamAny("value", "The integer to divide by.")),
+	checkedIntMethod(func(lhs, rhs int64) (int64, error) {
+		if rhs == 0 {
+			return 0, ErrDivideByZero
+		}
+		if lhs == math.MinInt64 && rhs == -1 {
+			return 0, intOverflowErr(lhs, "/", rhs)
+		}
+		return lhs / rhs, nil
+	}),
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"checked_mod", "",
+	).InCategory(
+		MethodCategoryNumbers,
+		"Returns the remainder of dividing the target integer by an integer, where the result has the sign of the target."+checkedIntDescription,
+		NewExampleSpec("",
+			`root.partition = this.id.checked_mod(16)`,
+			`{"id":123456789}`,
+			`{"partition":5}`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamAny("value", "The integer to divide by.")),
+	checkedIntMethod(func(lhs, rhs int64) (int64, error) {
+		if rhs == 0 {
+			return 0, ErrDivideByZero
+		}
+		return lhs % rhs, nil
+	}),
+)
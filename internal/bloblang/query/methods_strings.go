@@ -6,9 +6,11 @@ import (
 	"crypto/cipher"
 	"crypto/hmac"
 	"crypto/md5"
+	"crypto/rand"
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"crypto/subtle"
 	"encoding/ascii85"
 	"encoding/base64"
 	"encoding/csv"
@@ -20,19 +22,31 @@ import (
 	"hash/crc32"
 	"html"
 	"io"
+	"math"
 	"net/url"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/OneOfOne/xxhash"
 	"github.com/tilinna/z85"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/chacha20poly1305"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 	"gopkg.in/yaml.v3"
 
+	"github.com/redpanda-data/benthos/v4/internal/cbor"
+	"github.com/redpanda-data/benthos/v4/internal/glob"
+	"github.com/redpanda-data/benthos/v4/internal/msgpack"
+	"github.com/redpanda-data/benthos/v4/internal/semver"
 	"github.com/redpanda-data/benthos/v4/internal/value"
+	"github.com/redpanda-data/benthos/v4/internal/xpath"
 )
 
 var _ = registerSimpleMethod(
@@ -450,6 +464,179 @@ root.decrypted = this.value.decode("hex").decrypt_aes("ctr", $key, $vector).stri
 
 //------------------------------------------------------------------------------
 
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func aeadEncryptMethod(newAEAD func(key []byte) (cipher.AEAD, error)) func(args *ParsedParams) (simpleMethod, error) {
+	return func(args *ParsedParams) (simpleMethod, error) {
+		keyStr, err := args.FieldString("key")
+		if err != nil {
+			return nil, err
+		}
+		aead, err := newAEAD([]byte(keyStr))
+		if err != nil {
+			return nil, err
+		}
+
+		nonceOpt, err := args.FieldOptionalString("nonce")
+		if err != nil {
+			return nil, err
+		}
+		if nonceOpt != nil && len(*nonceOpt) != aead.NonceSize() {
+			return nil, fmt.Errorf("the nonce must be %v bytes long", aead.NonceSize())
+		}
+
+		adStr, err := args.FieldString("additional_data")
+		if err != nil {
+			return nil, err
+		}
+		ad := []byte(adStr)
+
+		return func(v any, ctx FunctionContext) (any, error) {
+			var plaintext []byte
+			switch t := v.(type) {
+			case string:
+				plaintext = []byte(t)
+			case []byte:
+				plaintext = t
+			default:
+				return nil, value.NewTypeError(v, value.TString)
+			}
+
+			nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+			if nonceOpt != nil {
+				copy(nonce, *nonceOpt)
+			} else if _, err := rand.Read(nonce); err != nil {
+				return nil, fmt.Errorf("failed to generate nonce: %w", err)
+			}
+			return aead.Seal(nonce, nonce, plaintext, ad), nil
+		}, nil
+	}
+}
+
+func aeadDecryptMethod(newAEAD func(key []byte) (cipher.AEAD, error)) func(args *ParsedParams) (simpleMethod, error) {
+	return func(args *ParsedParams) (simpleMethod, error) {
+		keyStr, err := args.FieldString("key")
+		if err != nil {
+			return nil, err
+		}
+		aead, err := newAEAD([]byte(keyStr))
+		if err != nil {
+			return nil, err
+		}
+
+		adStr, err := args.FieldString("additional_data")
+		if err != nil {
+			return nil, err
+		}
+		ad := []byte(adStr)
+
+		return func(v any, ctx FunctionContext) (any, error) {
+			var ciphertext []byte
+			switch t := v.(type) {
+			case string:
+				ciphertext = []byte(t)
+			case []byte:
+				ciphertext = t
+			default:
+				return nil, value.NewTypeError(v, value.TString)
+			}
+
+			if len(ciphertext) < aead.NonceSize()+aead.Overhead() {
+				return nil, errors.New("ciphertext is too short")
+			}
+			nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+			plaintext, err := aead.Open(nil, nonce, ciphertext, ad)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt: %w", err)
+			}
+			return plaintext, nil
+		}, nil
+	}
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"encrypt_aes_gcm", "",
+	).InCategory(
+		MethodCategoryEncoding,
+		"Encrypts and authenticates a string or byte array target with AES-GCM and returns a byte array result, where the nonce is prepended to the ciphertext. A random nonce is generated for each invocation unless one is provided. A nonce must never be reused with the same key, as doing so breaks both the confidentiality and the authenticity of every message encrypted with it, and therefore it is strongly recommended that the nonce is omitted. The key must be 16, 24 or 32 bytes long in order to select AES-128, AES-192 or AES-256 respectively.",
+		NewExampleSpec("",
+			`let key = "2b7e151628aed2a6abf7158809cf4f3c".decode("hex")
+root.encrypted = this.value.encrypt_aes_gcm($key).encode("base64")`,
+		),
+		NewExampleSpec("Additional data is authenticated but not encrypted, and must be provided again when decrypting. As the nonce is omitted a fresh random nonce is generated for each message.",
+			`let key = "2b7e151628aed2a6abf7158809cf4f3c".decode("hex")
+let encrypted = this.value.encrypt_aes_gcm(key: $key, additional_data: this.id)
+root.decrypted = $encrypted.decrypt_aes_gcm(key: $key, additional_data: this.id).string()`,
+			`{"id":"foo","value":"hello world!"}`,
+			`{"decrypted":"hello world!"}`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamString("key", "A key to encrypt with.")).
+		Param(ParamString("nonce", "An optional 12 byte nonce, when omitted a random nonce is generated. A nonce must never be reused with the same key.").Optional()).
+		Param(ParamString("additional_data", "Optional additional data to authenticate.").Default("")),
+	aeadEncryptMethod(newAESGCM),
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"decrypt_aes_gcm", "",
+	).InCategory(
+		MethodCategoryEncoding,
+		"Decrypts and authenticates a string or byte array target produced with <<encrypt_aes_gcm, `encrypt_aes_gcm`>>, where the nonce is expected as a prefix of the ciphertext, and returns a byte array result. An error is returned if the ciphertext or additional data has been tampered with.",
+		NewExampleSpec("",
+			`let key = "2b7e151628aed2a6abf7158809cf4f3c".decode("hex")
+root.decrypted = this.value.decode("hex").decrypt_aes_gcm(key: $key, additional_data: this.id).string()`,
+			`{"id":"foo","value":"cafebabefacedbaddecaf88869630b793b7c62ba340afc07658f4b3d540ad8e0e7d287b0d5ffdcb9"}`,
+			`{"decrypted":"hello world!"}`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamString("key", "A key to decrypt with.")).
+		Param(ParamString("additional_data", "Optional additional data to authenticate, which must match the data provided during encryption.").Default("")),
+	aeadDecryptMethod(newAESGCM),
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"encrypt_chacha20_poly1305", "",
+	).InCategory(
+		MethodCategoryEncoding,
+		"Encrypts and authenticates a string or byte array target with ChaCha20-Poly1305 and returns a byte array result, where the nonce is prepended to the ciphertext. A random nonce is generated for each invocation unless one is provided. A nonce must never be reused with the same key, as doing so breaks both the confidentiality and the authenticity of every message encrypted with it, and therefore it is strongly recommended that the nonce is omitted. The key must be 32 bytes long.",
+		NewExampleSpec("",
+			`let key = "808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f".decode("hex")
+root.encrypted = this.value.encrypt_chacha20_poly1305($key).encode("base64")`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamString("key", "A key to encrypt with.")).
+		Param(ParamString("nonce", "An optional 12 byte nonce, when omitted a random nonce is generated. A nonce must never be reused with the same key.").Optional()).
+		Param(ParamString("additional_data", "Optional additional data to authenticate.").Default("")),
+	aeadEncryptMethod(chacha20poly1305.New),
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"decrypt_chacha20_poly1305", "",
+	).InCategory(
+		MethodCategoryEncoding,
+		"Decrypts and authenticates a string or byte array target produced with <<encrypt_chacha20_poly1305, `encrypt_chacha20_poly1305`>>, where the nonce is expected as a prefix of the ciphertext, and returns a byte array result. An error is returned if the ciphertext or additional data has been tampered with.",
+		NewExampleSpec("",
+			`let key = "808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f".decode("hex")
+root.decrypted = this.value.decode("base64").decrypt_chacha20_poly1305($key).string()`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamString("key", "A key to decrypt with.")).
+		Param(ParamString("additional_data", "Optional additional data to authenticate, which must match the data provided during encryption.").Default("")),
+	aeadDecryptMethod(chacha20poly1305.New),
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"escape_html", "",
@@ -852,6 +1039,244 @@ root.h2 = this.value.hash(algorithm: "crc32", polynomial: "Koopman").encode("hex
 
 //------------------------------------------------------------------------------
 
+func passwordBytes(v any) ([]byte, error) {
+	switch t := v.(type) {
+	case string:
+		return []byte(t), nil
+	case []byte:
+		return t, nil
+	}
+	return nil, value.NewTypeError(v, value.TString)
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"hash_bcrypt", "",
+	).InCategory(
+		MethodCategoryEncoding,
+		"Hashes a string or byte array password with bcrypt and returns the encoded hash as a string, which includes the cost and a randomly generated salt. The resulting hash can be checked with <<verify_bcrypt, `verify_bcrypt`>>. Passwords longer than 72 bytes are rejected.",
+		NewExampleSpec("",
+			`root.password_hash = this.password.hash_bcrypt()`,
+		),
+		NewExampleSpec("A higher cost makes brute forcing the hash more expensive, at the cost of slower hashing.",
+			`root.password_hash = this.password.hash_bcrypt(12)`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamInt64("cost", "The cost of the hash, between 4 and 31.").Default(bcrypt.DefaultCost)),
+	func(args *ParsedParams) (simpleMethod, error) {
+		cost64, err := args.FieldInt64("cost")
+		if err != nil {
+			return nil, err
+		}
+		cost := int(cost64)
+		if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("cost must be between %v and %v, got %v", bcrypt.MinCost, bcrypt.MaxCost, cost)
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			password, err := passwordBytes(v)
+			if err != nil {
+				return nil, err
+			}
+			hash, err := bcrypt.GenerateFromPassword(password, cost)
+			if err != nil {
+				return nil, err
+			}
+			return string(hash), nil
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"verify_bcrypt", "",
+	).InCategory(
+		MethodCategoryEncoding,
+		"Checks whether a string or byte array password matches a bcrypt hash, returning a boolean. An error is returned if the hash is malformed.",
+		NewExampleSpec("",
+			`root.valid = this.password.verify_bcrypt(this.hash)`,
+			`{"password":"hunter2","hash":"$2a$04$ckJlLZjkH6XtTWOQW.U1DecBvXTPpKedOmZxyCkfjKyiKyv8.awVK"}`,
+			`{"valid":true}`,
+			`{"password":"hunter3","hash":"$2a$04$ckJlLZjkH6XtTWOQW.U1DecBvXTPpKedOmZxyCkfjKyiKyv8.awVK"}`,
+			`{"valid":false}`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamString("hash", "The bcrypt hash to check against.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		hash, err := args.FieldString("hash")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			password, err := passwordBytes(v)
+			if err != nil {
+				return nil, err
+			}
+			err = bcrypt.CompareHashAndPassword([]byte(hash), password)
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return false, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			return true, nil
+		}, nil
+	},
+)
+
+// argon2idMaxMemory is the largest amount of memory in KiB (4 GiB) that an
+// argon2id hash may request, which prevents a crafted hash from allocating an
+// unbounded amount of memory.
+const argon2idMaxMemory = 4 * 1024 * 1024
+
+// argon2idHash is an argon2id hash in the PHC string format:
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>
+type argon2idHash struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+func (h argon2idHash) String() string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.memory, h.iterations, h.parallelism,
+		base64.RawStdEncoding.EncodeToString(h.salt),
+		base64.RawStdEncoding.EncodeToString(h.key))
+}
+
+func parseArgon2idHash(s string) (h argon2idHash, err error) {
+	parts := strings.Split(s, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return h, errors.New("expected an argon2id hash in the format $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>")
+	}
+
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return h, fmt.Errorf("failed to parse argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return h, fmt.Errorf("unsupported argon2id version: %v", version)
+	}
+
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &h.memory, &h.iterations, &h.parallelism); err != nil {
+		return h, fmt.Errorf("failed to parse argon2id parameters: %w", err)
+	}
+	if h.iterations < 1 {
+		return h, fmt.Errorf("argon2id iterations must be at least 1, got %v", h.iterations)
+	}
+	if h.parallelism < 1 {
+		return h, fmt.Errorf("argon2id parallelism must be at least 1, got %v", h.parallelism)
+	}
+	if h.memory > argon2idMaxMemory {
+		return h, fmt.Errorf("argon2id memory must not exceed %v KiB, got %v", argon2idMaxMemory, h.memory)
+	}
+	if h.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return h, fmt.Errorf("failed to decode argon2id salt: %w", err)
+	}
+	if h.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return h, fmt.Errorf("failed to decode argon2id key: %w", err)
+	}
+	if len(h.key) == 0 {
+		return h, errors.New("argon2id key must not be empty")
+	}
+	return h, nil
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"hash_argon2id", "",
+	).InCategory(
+		MethodCategoryEncoding,
+		"Hashes a string or byte array password with argon2id and a randomly generated salt, returning the hash as a string in the PHC format `$argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>`. The resulting hash can be checked with <<verify_argon2id, `verify_argon2id`>>.",
+		NewExampleSpec("",
+			`root.password_hash = this.password.hash_argon2id()`,
+		),
+		NewExampleSpec("The cost parameters can be tuned in order to trade hashing speed and memory usage for resistance to brute forcing.",
+			`root.password_hash = this.password.hash_argon2id(iterations: 3, memory: 32768, parallelism: 2)`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamInt64("iterations", "The number of passes over the memory.").Default(1)).
+		Param(ParamInt64("memory", "The amount of memory to use in KiB, which must not exceed 4194304 (4 GiB).").Default(65536)).
+		Param(ParamInt64("parallelism", "The number of threads to use.").Default(4)).
+		Param(ParamInt64("salt_length", "The length of the randomly generated salt in bytes.").Default(16)).
+		Param(ParamInt64("key_length", "The length of the resulting key in bytes.").Default(32)),
+	func(args *ParsedParams) (simpleMethod, error) {
+		var params [5]int64
+		for i, name := range []string{"iterations", "memory", "parallelism", "salt_length", "key_length"} {
+			var err error
+			if params[i], err = args.FieldInt64(name); err != nil {
+				return nil, err
+			}
+			if params[i] < 1 || params[i] > math.MaxUint32 {
+				return nil, fmt.Errorf("%v must be between 1 and %v, got %v", name, uint32(math.MaxUint32), params[i])
+			}
+		}
+		iterations, memory, parallelism, saltLength, keyLength := params[0], params[1], params[2], params[3], params[4]
+		if parallelism > math.MaxUint8 {
+			return nil, fmt.Errorf("parallelism must be between 1 and %v, got %v", math.MaxUint8, parallelism)
+		}
+		if memory > argon2idMaxMemory {
+			return nil, fmt.Errorf("memory must be between 1 and %v, got %v", argon2idMaxMemory, memory)
+		}
+
+		return func(v any, ctx FunctionContext) (any, error) {
+			password, err := passwordBytes(v)
+			if err != nil {
+				return nil, err
+			}
+			h := argon2idHash{
+				memory:      uint32(memory),
+				iterations:  uint32(iterations),
+				parallelism: uint8(parallelism),
+				salt:        make([]byte, saltLength),
+			}
+			if _, err := rand.Read(h.salt); err != nil {
+				return nil, fmt.Errorf("failed to generate salt: %w", err)
+			}
+			h.key = argon2.IDKey(password, h.salt, h.iterations, h.memory, h.parallelism, uint32(keyLength))
+			return h.String(), nil
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"verify_argon2id", "",
+	).InCategory(
+		MethodCategoryEncoding,
+		"Checks whether a string or byte array password matches an argon2id hash in the PHC format, returning a boolean. An error is returned if the hash is malformed, has iterations or parallelism below 1, or requests more than 4194304 KiB (4 GiB) of memory.",
+		NewExampleSpec("",
+			`root.valid = this.password.verify_argon2id(this.hash)`,
+			`{"password":"hunter2","hash":"$argon2id$v=19$m=64,t=1,p=1$c29tZXNhbHQ$JbERrFqJ6sqDRxwJTmTun3ZYHAGKT6gtMt8atMtLP+E"}`,
+			`{"valid":true}`,
+			`{"password":"hunter3","hash":"$argon2id$v=19$m=64,t=1,p=1$c29tZXNhbHQ$JbERrFqJ6sqDRxwJTmTun3ZYHAGKT6gtMt8atMtLP+E"}`,
+			`{"valid":false}`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamString("hash", "The argon2id hash to check against.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		hashStr, err := args.FieldString("hash")
+		if err != nil {
+			return nil, err
+		}
+		h, err := parseArgon2idHash(hashStr)
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			password, err := passwordBytes(v)
+			if err != nil {
+				return nil, err
+			}
+			key := argon2.IDKey(password, h.salt, h.iterations, h.memory, h.parallelism, uint32(len(h.key)))
+			return subtle.ConstantTimeCompare(key, h.key) == 1, nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"join", "",
@@ -982,10 +1407,17 @@ var _ = registerSimpleMethod(
 			`root.orders = this.orders.parse_csv(lazy_quotes:true)`,
 			`{"orders":"foo,bar\nfoo 1,bar 1\nfoo\" \"2,bar\" \"2"}`,
 			`{"orders":[{"bar":"bar 1","foo":"foo 1"},{"bar":"bar\" \"2","foo":"foo\" \"2"}]}`,
+		),
+		NewExampleSpec("Parses semicolon delimited CSV data containing comments",
+			`root.orders = this.orders.parse_csv(delimiter: ";", comment: "#", trim_leading_space: true)`,
+			`{"orders":"# exported orders\nfoo; bar\nfoo 1; bar 1"}`,
+			`{"orders":[{"bar":"bar 1","foo":"foo 1"}]}`,
 		)).
 		Param(ParamBool("parse_header_row", "Whether to reference the first row as a header row. If set to true the output structure for messages will be an object where field keys are determined by the header row. Otherwise, the output will be an array of row arrays.").Default(true)).
 		Param(ParamString("delimiter", "The delimiter to use for splitting values in each record. It must be a single character.").Default(",")).
-		Param(ParamBool("lazy_quotes", "If set to `true`, a quote may appear in an unquoted field and a non-doubled quote may appear in a quoted field.").Default(false)),
+		Param(ParamBool("lazy_quotes", "If set to `true`, a quote may appear in an unquoted field and a non-doubled quote may appear in a quoted field.").Default(false)).
+		Param(ParamString("comment", "An optional character that marks lines to be ignored when it appears at the beginning of the line. It must be a single character.").Default("")).
+		Param(ParamBool("trim_leading_space", "If set to `true`, leading white space in a field is ignored.").Default(false)),
 	parseCSVMethod,
 )
 
@@ -1016,6 +1448,24 @@ func parseCSVMethod(args *ParsedParams) (simpleMethod, error) {
 		}
 		lazyQuotes = *optBool
 
+		var comment rune
+		if optString, err = args.FieldOptionalString("comment"); err != nil {
+			return nil, err
+		}
+		if optString != nil && *optString != "" {
+			commentRunes := []rune(*optString)
+			if len(commentRunes) != 1 {
+				return nil, errors.New("comment value must be exactly one character")
+			}
+			comment = commentRunes[0]
+		}
+
+		var trimLeadingSpace bool
+		if optBool, err = args.FieldOptionalBool("trim_leading_space"); err != nil {
+			return nil, err
+		}
+		trimLeadingSpace = *optBool
+
 		var csvBytes []byte
 		switch t := v.(type) {
 		case string:
@@ -1029,6 +1479,8 @@ func parseCSVMethod(args *ParsedParams) (simpleMethod, error) {
 		r := csv.NewReader(bytes.NewReader(csvBytes))
 		r.Comma = delimiter
 		r.LazyQuotes = lazyQuotes
+		r.Comment = comment
+		r.TrimLeadingSpace = trimLeadingSpace
 		strRecords, err := r.ReadAll()
 		if err != nil {
 			return nil, err
@@ -1069,38 +1521,209 @@ func parseCSVMethod(args *ParsedParams) (simpleMethod, error) {
 	}, nil
 }
 
-//------------------------------------------------------------------------------
-
 var _ = registerSimpleMethod(
 	NewMethodSpec(
-		"parse_json", "",
-	).Param(
-		ParamBool("use_number", "An optional flag that when set makes parsing numbers as json.Number instead of the default float64.").Optional(),
+		"format_csv", "",
 	).InCategory(
 		MethodCategoryParsing,
-		"Attempts to parse a string as a JSON document and returns the result.",
-		NewExampleSpec("",
-			`root.doc = this.doc.parse_json()`,
-			`{"doc":"{\"foo\":\"bar\"}"}`,
-			`{"doc":{"foo":"bar"}}`,
+		"Serializes an array of objects or an array of row arrays into a CSV string following the format described in RFC 4180. When the rows are objects a header row is written with the column names, which are the sorted keys of all objects unless a list of `columns` is provided, and fields missing from an object are left empty. Values that are not strings are converted as they would be with <<string, `string`>>, with the exception of `null`, which results in an empty field.",
+		NewExampleSpec("Formats an array of objects with a header row",
+			`root.orders = this.orders.format_csv()`,
+			`{"orders":[{"foo":"foo 1","bar":"bar 1"},{"foo":"foo 2","bar":"bar, 2"}]}`,
+			`{"orders":"bar,foo\nbar 1,foo 1\n\"bar, 2\",foo 2"}`,
 		),
-		NewExampleSpec("",
-			`root.doc = this.doc.parse_json(use_number: true)`,
-			`{"doc":"{\"foo\":\"11380878173205700000000000000000000000000000000\"}"}`,
-			`{"doc":{"foo":"11380878173205700000000000000000000000000000000"}}`,
+		NewExampleSpec("Formats an array of objects with explicit columns, delimiter and quoting",
+			`root.orders = this.orders.format_csv(columns: ["id", "total"], delimiter: ";", quote_all: true)`,
+			`{"orders":[{"id":1,"total":10.5,"note":"ignored"},{"id":2}]}`,
+			`{"orders":"\"id\";\"total\"\n\"1\";\"10.5\"\n\"2\";\"\""}`,
 		),
-	),
-	func(args *ParsedParams) (simpleMethod, error) {
-		useNumber, err := args.FieldOptionalBool("use_number")
-		if err != nil {
-			return nil, err
-		}
-		return func(v any, ctx FunctionContext) (any, error) {
-			var jsonBytes []byte
-			switch t := v.(type) {
-			case string:
-				jsonBytes = []byte(t)
-			case []byte:
+		NewExampleSpec("Formats an array of row arrays",
+			`root.orders = this.orders.format_csv()`,
+			`{"orders":[["foo 1","bar 1"],["foo 2","bar 2"]]}`,
+			`{"orders":"foo 1,bar 1\nfoo 2,bar 2"}`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamBool("include_header", "Whether to write a header row with the column names when the rows are objects.").Default(true)).
+		Param(ParamString("delimiter", "The delimiter to use for separating values in each record. It must be a single character.").Default(",")).
+		Param(ParamBool("quote_all", "Whether to quote all fields, otherwise fields are only quoted when they contain a delimiter, quote, new line or leading white space.").Default(false)).
+		Param(ParamBool("use_crlf", "Whether to terminate lines with `\\r\\n` rather than `\\n`.").Default(false)).
+		Param(ParamArray("columns", "An optional list of column names that determines which fields of each object are written and in which order.").Optional()),
+	formatCSVMethod,
+)
+
+func formatCSVMethod(args *ParsedParams) (simpleMethod, error) {
+	var columns []string
+	optColumns, err := args.FieldOptionalArray("columns")
+	if err != nil {
+		return nil, err
+	}
+	if optColumns != nil {
+		for i, c := range *optColumns {
+			s, ok := c.(string)
+			if !ok {
+				return nil, fmt.Errorf("columns: index %v: %w", i, value.NewTypeError(c, value.TString))
+			}
+			columns = append(columns, s)
+		}
+	}
+
+	includeHeader, err := args.FieldBool("include_header")
+	if err != nil {
+		return nil, err
+	}
+
+	delimStr, err := args.FieldString("delimiter")
+	if err != nil {
+		return nil, err
+	}
+	delimRunes := []rune(delimStr)
+	if len(delimRunes) != 1 || delimRunes[0] == '"' || delimRunes[0] == '\r' || delimRunes[0] == '\n' {
+		return nil, errors.New("delimiter value must be exactly one character and cannot be a quote or new line")
+	}
+	delimiter := delimRunes[0]
+
+	quoteAll, err := args.FieldBool("quote_all")
+	if err != nil {
+		return nil, err
+	}
+
+	lineEnd := "\n"
+	if useCRLF, err := args.FieldBool("use_crlf"); err != nil {
+		return nil, err
+	} else if useCRLF {
+		lineEnd = "\r\n"
+	}
+
+	writeRecord := func(buf *strings.Builder, fields []string) {
+		if buf.Len() > 0 {
+			buf.WriteString(lineEnd)
+		}
+		for i, f := range fields {
+			if i > 0 {
+				buf.WriteRune(delimiter)
+			}
+			if !quoteAll && !csvFieldNeedsQuotes(f, delimiter) {
+				buf.WriteString(f)
+				continue
+			}
+			buf.WriteByte('"')
+			buf.WriteString(strings.ReplaceAll(f, `"`, `""`))
+			buf.WriteByte('"')
+		}
+	}
+
+	return func(v any, ctx FunctionContext) (any, error) {
+		rows, ok := v.([]any)
+		if !ok {
+			return nil, value.NewTypeError(v, value.TArray)
+		}
+
+		var buf strings.Builder
+		if len(rows) == 0 {
+			return "", nil
+		}
+		if _, isObj := rows[0].(map[string]any); !isObj {
+			for i, row := range rows {
+				arr, ok := row.([]any)
+				if !ok {
+					return nil, fmt.Errorf("index %v: %w", i, value.NewTypeError(row, value.TArray))
+				}
+				fields := make([]string, len(arr))
+				for j, f := range arr {
+					fields[j] = csvFieldString(f)
+				}
+				writeRecord(&buf, fields)
+			}
+			return buf.String(), nil
+		}
+
+		rowColumns := columns
+		if rowColumns == nil {
+			keys := map[string]struct{}{}
+			for _, row := range rows {
+				if obj, ok := row.(map[string]any); ok {
+					for k := range obj {
+						keys[k] = struct{}{}
+					}
+				}
+			}
+			for k := range keys {
+				rowColumns = append(rowColumns, k)
+			}
+			sort.Strings(rowColumns)
+		}
+
+		if includeHeader {
+			writeRecord(&buf, rowColumns)
+		}
+		for i, row := range rows {
+			obj, ok := row.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("index %v: %w", i, value.NewTypeError(row, value.TObject))
+			}
+			fields := make([]string, len(rowColumns))
+			for j, c := range rowColumns {
+				fields[j] = csvFieldString(obj[c])
+			}
+			writeRecord(&buf, fields)
+		}
+		return buf.String(), nil
+	}, nil
+}
+
+func csvFieldString(v any) string {
+	if v == nil {
+		return ""
+	}
+	return value.IToString(v)
+}
+
+// csvFieldNeedsQuotes follows the same rules as the standard library CSV
+// writer, where fields containing special characters or beginning with a space
+// must be quoted.
+func csvFieldNeedsQuotes(field string, delimiter rune) bool {
+	if field == "" {
+		return false
+	}
+	if field == `\.` || strings.ContainsRune(field, delimiter) || strings.ContainsAny(field, "\"\r\n") {
+		return true
+	}
+	r1, _ := utf8.DecodeRuneInString(field)
+	return unicode.IsSpace(r1)
+}
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"parse_json", "",
+	).Param(
+		ParamBool("use_number", "An optional flag that when set makes parsing numbers as json.Number instead of the default float64.").Optional(),
+	).InCategory(
+		MethodCategoryParsing,
+		"Attempts to parse a string as a JSON document and returns the result.",
+		NewExampleSpec("",
+			`root.doc = this.doc.parse_json()`,
+			`{"doc":"{\"foo\":\"bar\"}"}`,
+			`{"doc":{"foo":"bar"}}`,
+		),
+		NewExampleSpec("",
+			`root.doc = this.doc.parse_json(use_number: true)`,
+			`{"doc":"{\"foo\":\"11380878173205700000000000000000000000000000000\"}"}`,
+			`{"doc":{"foo":"11380878173205700000000000000000000000000000000"}}`,
+		),
+	),
+	func(args *ParsedParams) (simpleMethod, error) {
+		useNumber, err := args.FieldOptionalBool("use_number")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			var jsonBytes []byte
+			switch t := v.(type) {
+			case string:
+				jsonBytes = []byte(t)
+			case []byte:
 				jsonBytes = t
 			default:
 				return nil, value.NewTypeError(v, value.TString)
@@ -1118,6 +1741,70 @@ var _ = registerSimpleMethod(
 	},
 )
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"parse_cbor", "",
+	).InCategory(
+		MethodCategoryParsing,
+		"Attempts to parse a string or byte array as a single CBOR data item and returns the result. Integers are parsed as integer values, byte strings as byte arrays and date/time tags as timestamps, all other tags are ignored and yield their content.",
+		NewExampleSpec("",
+			`root.doc = this.doc.decode("hex").parse_cbor()`,
+			`{"doc":"a163666f6f63626172"}`,
+			`{"doc":{"foo":"bar"}}`,
+		),
+	).AtVersion("4.29.0"),
+	func(*ParsedParams) (simpleMethod, error) {
+		return func(v any, ctx FunctionContext) (any, error) {
+			var cborBytes []byte
+			switch t := v.(type) {
+			case string:
+				cborBytes = []byte(t)
+			case []byte:
+				cborBytes = t
+			default:
+				return nil, value.NewTypeError(v, value.TString)
+			}
+			res, err := cbor.Unmarshal(cborBytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse value as CBOR: %w", err)
+			}
+			return res, nil
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"parse_msgpack", "",
+	).InCategory(
+		MethodCategoryParsing,
+		"Attempts to parse a string or byte array as a single MessagePack object and returns the result. Integers are parsed as integer values, binary data as byte arrays and timestamp extensions as timestamps, the data of all other extension types is returned as a byte array.",
+		NewExampleSpec("",
+			`root.doc = this.doc.decode("hex").parse_msgpack()`,
+			`{"doc":"81a3666f6fa3626172"}`,
+			`{"doc":{"foo":"bar"}}`,
+		),
+	).AtVersion("4.29.0"),
+	func(*ParsedParams) (simpleMethod, error) {
+		return func(v any, ctx FunctionContext) (any, error) {
+			var msgpackBytes []byte
+			switch t := v.(type) {
+			case string:
+				msgpackBytes = []byte(t)
+			case []byte:
+				msgpackBytes = t
+			default:
+				return nil, value.NewTypeError(v, value.TString)
+			}
+			res, err := msgpack.Unmarshal(msgpackBytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse value as MessagePack: %w", err)
+			}
+			return res, nil
+		}, nil
+	},
+)
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"parse_yaml", "",
@@ -1175,6 +1862,84 @@ var _ = registerSimpleMethod(
 	},
 )
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"xpath", "",
+	).Param(
+		ParamString("expression", "The XPath expression to evaluate."),
+	).Param(
+		ParamObject("namespaces", "An object mapping namespace prefixes used within the expression to their URIs.").Default(map[string]any{}),
+	).InCategory(
+		MethodCategoryParsing,
+		"Evaluates an XPath 1.0 expression against an XML document and returns the result. The document can either be a raw string or byte array, or a structured value where attributes are keys prefixed with a hyphen and text content is the key `#text`. When the expression results in a set of nodes an array of their string values is returned, otherwise the resulting string, number or boolean is returned. Elements within a namespace can only be selected with a prefix declared with the `namespaces` parameter.",
+		NewExampleSpec("",
+			`root.titles = this.doc.xpath("//book[@lang = 'en']/title")
+root.count = this.doc.xpath("count(//book)")`,
+			`{"doc":"<library><book lang=\"en\"><title>Dune</title></book><book lang=\"fr\"><title>Candide</title></book></library>"}`,
+			`{"count":2,"titles":["Dune"]}`,
+		),
+		NewExampleSpec("",
+			`root.prices = this.doc.xpath("/s:envelope/s:body/price", {"s": "http://example.com/soap"})`,
+			`{"doc":"<env:envelope xmlns:env=\"http://example.com/soap\"><env:body><price>10</price><price>12</price></env:body></env:envelope>"}`,
+			`{"prices":["10","12"]}`,
+		),
+	).AtVersion("4.29.0"),
+	func(args *ParsedParams) (simpleMethod, error) {
+		exprStr, err := args.FieldString("expression")
+		if err != nil {
+			return nil, err
+		}
+		nsArg, err := args.Field("namespaces")
+		if err != nil {
+			return nil, err
+		}
+		namespaces := map[string]string{}
+		if nsObj, ok := nsArg.(map[string]any); ok {
+			for k, v := range nsObj {
+				s, ok := v.(string)
+				if !ok {
+					return nil, fmt.Errorf("namespace %v: %w", k, value.NewTypeError(v, value.TString))
+				}
+				namespaces[k] = s
+			}
+		}
+		xExpr, err := xpath.Compile(exprStr, namespaces)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile xpath expression: %w", err)
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			var doc *xpath.Node
+			var err error
+			switch t := v.(type) {
+			case string:
+				doc, err = xpath.ParseXML([]byte(t))
+			case []byte:
+				doc, err = xpath.ParseXML(t)
+			case map[string]any:
+				doc, err = xpath.FromStructured(t)
+			default:
+				return nil, value.NewTypeError(v, value.TString, value.TObject)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse value as XML: %w", err)
+			}
+
+			res, err := xExpr.Evaluate(doc)
+			if err != nil {
+				return nil, err
+			}
+			if nodes, ok := res.([]*xpath.Node); ok {
+				strs := make([]any, len(nodes))
+				for i, n := range nodes {
+					strs[i] = n.StringValue()
+				}
+				return strs, nil
+			}
+			return res, nil
+		}, nil
+	},
+)
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"format_json", "",
@@ -1241,13 +2006,51 @@ var _ = registerSimpleMethod(
 
 var _ = registerSimpleMethod(
 	NewMethodSpec(
-		"parse_url", "Attempts to parse a URL from a string value, returning a structured result that describes the various facets of the URL. The fields returned within the structured result roughly follow https://pkg.go.dev/net/url#URL, and may be expanded in future in order to present more information.",
+		"format_cbor", "",
+	).InCategory(
+		MethodCategoryParsing,
+		"Serializes a target value into a CBOR byte array. Object keys are written in a deterministic order and timestamps are written as tagged date/time strings.",
+		NewExampleSpec("",
+			`root = this.doc.format_cbor().encode("hex")`,
+			`{"doc":{"foo":"bar"}}`,
+			`a163666f6f63626172`,
+		),
+	).AtVersion("4.29.0"),
+	func(*ParsedParams) (simpleMethod, error) {
+		return func(v any, ctx FunctionContext) (any, error) {
+			return cbor.Marshal(v)
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"format_msgpack", "",
+	).InCategory(
+		MethodCategoryParsing,
+		"Serializes a target value into a MessagePack byte array. Integers are written in their most compact form, object keys are written in lexicographical order and timestamps are written with the timestamp extension type.",
+		NewExampleSpec("",
+			`root = this.doc.format_msgpack().encode("hex")`,
+			`{"doc":{"foo":"bar"}}`,
+			`81a3666f6fa3626172`,
+		),
+	).AtVersion("4.29.0"),
+	func(*ParsedParams) (simpleMethod, error) {
+		return func(v any, ctx FunctionContext) (any, error) {
+			return msgpack.Marshal(v)
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"parse_url", "Attempts to parse a URL from a string value, returning a structured result that describes the various facets of the URL. The fields returned within the structured result roughly follow https://pkg.go.dev/net/url#URL, and may be expanded in future in order to present more information. The `hostname` and `port` fields are extracted from `host`, and the `query` field contains the parsed query parameters, where parameters with multiple values are represented as an array. The result can be modified and converted back into a URL string with <<format_url, `format_url`>>.",
 	).InCategory(
 		MethodCategoryParsing, "",
 		NewExampleSpec("",
 			`root.foo_url = this.foo_url.parse_url()`,
 			`{"foo_url":"https://www.docs.redpanda.com/redpanda-connect/guides/bloblang/about/"}`,
-			`{"foo_url":{"fragment":"","host":"www.docs.redpanda.com","opaque":"","path":"/redpanda-connect/guides/bloblang/about/","raw_fragment":"","raw_path":"","raw_query":"","scheme":"https"}}`,
+			`{"foo_url":{"fragment":"","host":"www.docs.redpanda.com","hostname":"www.docs.redpanda.com","opaque":"","path":"/redpanda-connect/guides/bloblang/about/","port":"","query":{},"raw_fragment":"","raw_path":"","raw_query":"","scheme":"https"}}`,
 		),
 		NewExampleSpec("",
 			`root.username = this.url.parse_url().user.name | "unknown"`,
@@ -1256,6 +2059,11 @@ var _ = registerSimpleMethod(
 			`{"url":"redis://localhost:6379"}`,
 			`{"username":"unknown"}`,
 		),
+		NewExampleSpec("",
+			`root = this.url.parse_url().without("fragment", "opaque", "path", "raw_fragment", "raw_path", "scheme")`,
+			`{"url":"http://localhost:4195/search?q=cats&tag=fluffy&tag=orange"}`,
+			`{"host":"localhost:4195","hostname":"localhost","port":"4195","query":{"q":"cats","tag":["fluffy","orange"]},"raw_query":"q=cats&tag=fluffy&tag=orange"}`,
+		),
 	),
 	func(*ParsedParams) (simpleMethod, error) {
 		return stringMethod(func(data string) (any, error) {
@@ -1263,13 +2071,28 @@ var _ = registerSimpleMethod(
 			if err != nil {
 				return nil, err
 			}
+			query := map[string]any{}
+			for k, v := range urlParsed.Query() {
+				if len(v) == 1 {
+					query[k] = v[0]
+					continue
+				}
+				elements := make([]any, 0, len(v))
+				for _, e := range v {
+					elements = append(elements, e)
+				}
+				query[k] = elements
+			}
 			values := map[string]any{
 				"scheme":       urlParsed.Scheme,
 				"opaque":       urlParsed.Opaque,
 				"host":         urlParsed.Host,
+				"hostname":     urlParsed.Hostname(),
+				"port":         urlParsed.Port(),
 				"path":         urlParsed.Path,
 				"raw_path":     urlParsed.RawPath,
 				"raw_query":    urlParsed.RawQuery,
+				"query":        query,
 				"fragment":     urlParsed.Fragment,
 				"raw_fragment": urlParsed.RawFragment,
 			}
@@ -1287,6 +2110,124 @@ var _ = registerSimpleMethod(
 	},
 )
 
+func urlStringField(obj map[string]any, path ...string) (string, error) {
+	v, exists := obj[path[len(path)-1]]
+	if !exists || v == nil {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("field %v: %w", strings.Join(path, "."), value.NewTypeError(v, value.TString))
+	}
+	return s, nil
+}
+
+func formatURL(obj map[string]any) (string, error) {
+	var u url.URL
+	var err error
+	for _, f := range []struct {
+		key string
+		dst *string
+	}{
+		{"scheme", &u.Scheme},
+		{"opaque", &u.Opaque},
+		{"host", &u.Host},
+		{"path", &u.Path},
+		{"raw_path", &u.RawPath},
+		{"raw_query", &u.RawQuery},
+		{"fragment", &u.Fragment},
+		{"raw_fragment", &u.RawFragment},
+	} {
+		if *f.dst, err = urlStringField(obj, f.key); err != nil {
+			return "", err
+		}
+	}
+
+	if _, exists := obj["hostname"]; exists {
+		hostname, err := urlStringField(obj, "hostname")
+		if err != nil {
+			return "", err
+		}
+		port, err := urlStringField(obj, "port")
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(hostname, ":") {
+			hostname = "[" + hostname + "]"
+		}
+		if u.Host = hostname; port != "" {
+			u.Host += ":" + port
+		}
+	}
+
+	if queryV, exists := obj["query"]; exists && queryV != nil {
+		queryObj, ok := queryV.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("field query: %w", value.NewTypeError(queryV, value.TObject))
+		}
+		query := url.Values{}
+		for k, v := range queryObj {
+			if arr, isArr := v.([]any); isArr {
+				for _, e := range arr {
+					query.Add(k, value.IToString(e))
+				}
+				continue
+			}
+			query.Add(k, value.IToString(v))
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	if userV, exists := obj["user"]; exists && userV != nil {
+		userObj, ok := userV.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("field user: %w", value.NewTypeError(userV, value.TObject))
+		}
+		name, err := urlStringField(userObj, "user", "name")
+		if err != nil {
+			return "", err
+		}
+		if _, exists := userObj["password"]; exists {
+			password, err := urlStringField(userObj, "user", "password")
+			if err != nil {
+				return "", err
+			}
+			u.User = url.UserPassword(name, password)
+		} else {
+			u.User = url.User(name)
+		}
+	}
+	return u.String(), nil
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"format_url", "",
+	).InCategory(
+		MethodCategoryParsing,
+		"Formats an object into a URL string, performing the inverse of <<parse_url, `parse_url`>>. All fields are optional. The `hostname` and `port` fields take precedence over `host` when `hostname` is present, and the `query` field takes precedence over `raw_query` when present, where query parameters with an array value are added once for each element. The `raw_path` and `raw_fragment` fields are only used when they are valid encodings of `path` and `fragment` respectively.",
+		NewExampleSpec("",
+			`root.url = this.url.parse_url().assign({"hostname":"example.com","query":{"page":"2"}}).format_url()`,
+			`{"url":"http://localhost:4195/search?q=cats&page=1#results"}`,
+			`{"url":"http://example.com:4195/search?page=2&q=cats#results"}`,
+		),
+		NewExampleSpec("",
+			`root.url = {"scheme":"https","host":"example.com","path":"/a path","query":{"tag":["foo","bar"]}}.format_url()`,
+			`{}`,
+			`{"url":"https://example.com/a%20path?tag=foo&tag=bar"}`,
+		),
+	).AtVersion("4.29.0"),
+	func(*ParsedParams) (simpleMethod, error) {
+		return func(v any, ctx FunctionContext) (any, error) {
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return nil, value.NewTypeError(v, value.TObject)
+			}
+			return formatURL(obj)
+		}, nil
+	},
+)
+
 //------------------------------------------------------------------------------
 
 var _ = registerSimpleMethod(
@@ -1648,69 +2589,242 @@ var _ = registerSimpleMethod(
 
 var _ = registerSimpleMethod(
 	NewMethodSpec(
-		"re_find_all_object", "",
+		"re_find_all_object", "",
+	).InCategory(
+		MethodCategoryRegexp,
+		"Returns an array of objects containing all matches of the regular expression and the matches of its subexpressions. The key of each match value is the name of the group when specified, otherwise it is the index of the matching group, starting with the expression as a whole at 0.",
+		NewExampleSpec("",
+			`root.matches = this.value.re_find_all_object("a(?P<foo>x*)b")`,
+			`{"value":"-axxb-ab-"}`,
+			`{"matches":[{"0":"axxb","foo":"xx"},{"0":"ab","foo":""}]}`,
+		),
+		NewExampleSpec("",
+			`root.matches = this.value.re_find_all_object("(?m)(?P<key>\\w+):\\s+(?P<value>\\w+)$")`,
+			`{"value":"option1: value1\noption2: value2\noption3: value3"}`,
+			`{"matches":[{"0":"option1: value1","key":"option1","value":"value1"},{"0":"option2: value2","key":"option2","value":"value2"},{"0":"option3: value3","key":"option3","value":"value3"}]}`,
+		),
+	).Param(ParamString("pattern", "The pattern to match against.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		reStr, err := args.FieldString("pattern")
+		if err != nil {
+			return nil, err
+		}
+		re, err := regexp.Compile(reStr)
+		if err != nil {
+			return nil, err
+		}
+		groups := re.SubexpNames()
+		for i, k := range groups {
+			if k == "" {
+				groups[i] = strconv.Itoa(i)
+			}
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			var result []any
+			switch t := v.(type) {
+			case string:
+				reMatches := re.FindAllStringSubmatch(t, -1)
+				result = make([]any, 0, len(reMatches))
+				for _, matches := range reMatches {
+					obj := make(map[string]any, len(groups))
+					for i, match := range matches {
+						key := groups[i]
+						obj[key] = match
+					}
+					result = append(result, obj)
+				}
+			case []byte:
+				reMatches := re.FindAllSubmatch(t, -1)
+				result = make([]any, 0, len(reMatches))
+				for _, matches := range reMatches {
+					obj := make(map[string]any, len(groups))
+					for i, match := range matches {
+						key := groups[i]
+						obj[key] = match
+					}
+					result = append(result, obj)
+				}
+			default:
+				return nil, value.NewTypeError(v, value.TString)
+			}
+			return result, nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"re_capture_object", "",
+	).InCategory(
+		MethodCategoryRegexp,
+		"Returns an object containing the named capture groups of the first match of a regular expression, where each key is the name of a group and each value is the string it matched. Groups that did not participate in the match are `null`, and if the expression does not match at all then `null` is returned. Unnamed groups are ignored, and the pattern must contain at least one named group.",
+		NewExampleSpec("",
+			`root = this.line.re_capture_object("^(?P<ip>\\S+) \\S+ (?P<user>\\S+) \\[(?P<time>[^\\]]+)\\] \"(?P<method>\\w+) (?P<path>\\S+)")`,
+			`{"line":"127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] \"GET /apache_pb.gif HTTP/1.0\" 200 2326"}`,
+			`{"ip":"127.0.0.1","method":"GET","path":"/apache_pb.gif","time":"10/Oct/2000:13:55:36 -0700","user":"frank"}`,
+		),
+		NewExampleSpec("",
+			`root.version = this.value.re_capture_object("v(?P<major>\\d+)(\\.(?P<minor>\\d+))?")`,
+			`{"value":"v12"}`,
+			`{"version":{"major":"12","minor":null}}`,
+			`{"value":"nope"}`,
+			`{"version":null}`,
+		),
+	).AtVersion("4.29.0").Param(ParamString("pattern", "The pattern to match against.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		re, err := reNamedCapturePattern(args)
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			s, err := value.IGetString(v)
+			if err != nil {
+				return nil, err
+			}
+			loc := re.FindStringSubmatchIndex(s)
+			if loc == nil {
+				return nil, nil
+			}
+			return reNamedCaptures(re, s, loc), nil
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"re_capture_all_objects", "",
+	).InCategory(
+		MethodCategoryRegexp,
+		"Returns an array of objects containing the named capture groups of each match of a regular expression, in the same format as <<re_capture_object, `re_capture_object`>>. If the expression does not match then an empty array is returned.",
+		NewExampleSpec("",
+			`root.pairs = this.value.re_capture_all_objects("(?P<key>\\w+)=(?P<value>\\w+)")`,
+			`{"value":"a=1 b=2 c=3"}`,
+			`{"pairs":[{"key":"a","value":"1"},{"key":"b","value":"2"},{"key":"c","value":"3"}]}`,
+		),
+	).AtVersion("4.29.0").Param(ParamString("pattern", "The pattern to match against.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		re, err := reNamedCapturePattern(args)
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			s, err := value.IGetString(v)
+			if err != nil {
+				return nil, err
+			}
+			locs := re.FindAllStringSubmatchIndex(s, -1)
+			result := make([]any, 0, len(locs))
+			for _, loc := range locs {
+				result = append(result, reNamedCaptures(re, s, loc))
+			}
+			return result, nil
+		}, nil
+	},
+)
+
+func reNamedCapturePattern(args *ParsedParams) (*regexp.Regexp, error) {
+	reStr, err := args.FieldString("pattern")
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(reStr)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			return re, nil
+		}
+	}
+	return nil, errors.New("pattern does not contain any named capture groups")
+}
+
+func reNamedCaptures(re *regexp.Regexp, s string, loc []int) map[string]any {
+	obj := map[string]any{}
+	for i, name := range re.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if start, end := loc[2*i], loc[2*i+1]; start >= 0 {
+			obj[name] = s[start:end]
+		} else if _, exists := obj[name]; !exists {
+			obj[name] = nil
+		}
+	}
+	return obj
+}
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"match_glob", "",
 	).InCategory(
-		MethodCategoryRegexp,
-		"Returns an array of objects containing all matches of the regular expression and the matches of its subexpressions. The key of each match value is the name of the group when specified, otherwise it is the index of the matching group, starting with the expression as a whole at 0.",
+		MethodCategoryStrings,
+		"Checks whether a string matches a glob pattern in its entirety and returns a boolean. The pattern supports the wildcards `*`, which matches any sequence of characters, and `?`, which matches any single character, character classes such as `[a-z]` or `[!0-9]`, and alternatives such as `{foo,bar}`. Special characters can be escaped with a backslash. In order to match paths where wildcards should not cross path separators use <<path_match, `path_match`>> instead.",
 		NewExampleSpec("",
-			`root.matches = this.value.re_find_all_object("a(?P<foo>x*)b")`,
-			`{"value":"-axxb-ab-"}`,
-			`{"matches":[{"0":"axxb","foo":"xx"},{"0":"ab","foo":""}]}`,
+			`root.is_order = this.topic.match_glob("orders.{created,updated}.*")`,
+			`{"topic":"orders.created.eu"}`,
+			`{"is_order":true}`,
+			`{"topic":"orders.deleted.eu"}`,
+			`{"is_order":false}`,
+		),
+	).AtVersion("4.29.0").Param(ParamString("pattern", "The glob pattern to match against.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		return globMatchMethod(args, glob.Compile)
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"path_match", "",
+	).InCategory(
+		MethodCategoryStrings,
+		"Checks whether a slash separated path matches a glob pattern in its entirety and returns a boolean. The pattern supports the same syntax as <<match_glob, `match_glob`>>, except that wildcards and negated character classes never match a `/`, and a path segment consisting of `**` matches zero or more whole path segments.",
+		NewExampleSpec("",
+			`root.is_log = this.key.path_match("logs/**/*.{log,txt}")`,
+			`{"key":"logs/2024/01/app.log"}`,
+			`{"is_log":true}`,
+			`{"key":"logs/app.log"}`,
+			`{"is_log":true}`,
+			`{"key":"data/logs/app.log"}`,
+			`{"is_log":false}`,
 		),
 		NewExampleSpec("",
-			`root.matches = this.value.re_find_all_object("(?m)(?P<key>\\w+):\\s+(?P<value>\\w+)$")`,
-			`{"value":"option1: value1\noption2: value2\noption3: value3"}`,
-			`{"matches":[{"0":"option1: value1","key":"option1","value":"value1"},{"0":"option2: value2","key":"option2","value":"value2"},{"0":"option3: value3","key":"option3","value":"value3"}]}`,
+			`root.top_level_json = this.key.path_match("*.json")`,
+			`{"key":"foo.json"}`,
+			`{"top_level_json":true}`,
+			`{"key":"foo/bar.json"}`,
+			`{"top_level_json":false}`,
 		),
-	).Param(ParamString("pattern", "The pattern to match against.")),
+	).AtVersion("4.29.0").Param(ParamString("pattern", "The glob pattern to match against.")),
 	func(args *ParsedParams) (simpleMethod, error) {
-		reStr, err := args.FieldString("pattern")
-		if err != nil {
-			return nil, err
-		}
-		re, err := regexp.Compile(reStr)
-		if err != nil {
-			return nil, err
-		}
-		groups := re.SubexpNames()
-		for i, k := range groups {
-			if k == "" {
-				groups[i] = strconv.Itoa(i)
-			}
-		}
-		return func(v any, ctx FunctionContext) (any, error) {
-			var result []any
-			switch t := v.(type) {
-			case string:
-				reMatches := re.FindAllStringSubmatch(t, -1)
-				result = make([]any, 0, len(reMatches))
-				for _, matches := range reMatches {
-					obj := make(map[string]any, len(groups))
-					for i, match := range matches {
-						key := groups[i]
-						obj[key] = match
-					}
-					result = append(result, obj)
-				}
-			case []byte:
-				reMatches := re.FindAllSubmatch(t, -1)
-				result = make([]any, 0, len(reMatches))
-				for _, matches := range reMatches {
-					obj := make(map[string]any, len(groups))
-					for i, match := range matches {
-						key := groups[i]
-						obj[key] = match
-					}
-					result = append(result, obj)
-				}
-			default:
-				return nil, value.NewTypeError(v, value.TString)
-			}
-			return result, nil
-		}, nil
+		return globMatchMethod(args, glob.CompilePath)
 	},
 )
 
+func globMatchMethod(args *ParsedParams, compile func(string) (*regexp.Regexp, error)) (simpleMethod, error) {
+	pattern, err := args.FieldString("pattern")
+	if err != nil {
+		return nil, err
+	}
+	re, err := compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse glob pattern: %w", err)
+	}
+	return func(v any, ctx FunctionContext) (any, error) {
+		switch t := v.(type) {
+		case string:
+			return re.MatchString(t), nil
+		case []byte:
+			return re.Match(t), nil
+		}
+		return nil, value.NewTypeError(v, value.TString)
+	}, nil
+}
+
 //------------------------------------------------------------------------------
 
 var _ = registerSimpleMethod(
@@ -1974,3 +3088,356 @@ root.description = this.description.trim_suffix("_foobar")`,
 		}, nil
 	},
 )
+
+//------------------------------------------------------------------------------
+
+func levenshteinDistance(a, b []rune) int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			prev, row[j] = row[j], min(row[j]+1, row[j-1]+1, prev+cost)
+		}
+	}
+	return row[len(b)]
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"levenshtein", "",
+	).InCategory(
+		MethodCategoryStrings,
+		"Returns the Levenshtein edit distance between a string target and the argument, which is the minimum number of single character insertions, deletions or substitutions required to change one into the other. The comparison is case sensitive.",
+		NewExampleSpec("",
+			`root.distance = this.a.levenshtein(this.b)`,
+			`{"a":"kitten","b":"sitting"}`,
+			`{"distance":3}`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamString("value", "The string to compare against.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		other, err := args.FieldString("value")
+		if err != nil {
+			return nil, err
+		}
+		otherRunes := []rune(other)
+		return func(v any, ctx FunctionContext) (any, error) {
+			switch t := v.(type) {
+			case string:
+				return int64(levenshteinDistance([]rune(t), otherRunes)), nil
+			case []byte:
+				return int64(levenshteinDistance([]rune(string(t)), otherRunes)), nil
+			}
+			return nil, value.NewTypeError(v, value.TString)
+		}, nil
+	},
+)
+
+func jaroWinklerSimilarity(a, b []rune) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	window := max(len(a), len(b))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	aMatched, bMatched := make([]bool, len(a)), make([]bool, len(b))
+	matches := 0
+	for i := range a {
+		for j := max(0, i-window); j < min(len(b), i+window+1); j++ {
+			if !bMatched[j] && a[i] == b[j] {
+				aMatched[i], bMatched[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, j := 0, 0
+	for i := range a {
+		if !aMatched[i] {
+			continue
+		}
+		for !bMatched[j] {
+			j++
+		}
+		if a[i] != b[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(a)) + m/float64(len(b)) + (m-float64(transpositions/2))/m) / 3
+	if jaro <= 0.7 {
+		return jaro
+	}
+
+	prefix := 0
+	for prefix < min(4, len(a), len(b)) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"jaro_winkler", "",
+	).InCategory(
+		MethodCategoryStrings,
+		"Returns the Jaro-Winkler similarity between a string target and the argument as a number between `0` (no similarity) and `1` (an exact match). Strings that share a common prefix are given a higher score, which makes it well suited to comparing short strings such as names. The comparison is case sensitive.",
+		NewExampleSpec("",
+			`root.similarity = this.a.jaro_winkler(this.b)`,
+			`{"a":"martha","b":"marhta"}`,
+			`{"similarity":0.9611111111111111}`,
+		),
+		NewExampleSpec("Strings can be normalised before they are compared in order to ignore differences in case.",
+			`root.similar = this.a.lowercase().jaro_winkler(this.b.lowercase()) > 0.8`,
+			`{"a":"Dwayne","b":"DUANE"}`,
+			`{"similar":true}`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamString("value", "The string to compare against.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		other, err := args.FieldString("value")
+		if err != nil {
+			return nil, err
+		}
+		otherRunes := []rune(other)
+		return func(v any, ctx FunctionContext) (any, error) {
+			switch t := v.(type) {
+			case string:
+				return jaroWinklerSimilarity([]rune(t), otherRunes), nil
+			case []byte:
+				return jaroWinklerSimilarity([]rune(string(t)), otherRunes), nil
+			}
+			return nil, value.NewTypeError(v, value.TString)
+		}, nil
+	},
+)
+
+func soundexCode(r rune) byte {
+	switch r {
+	case 'B', 'F', 'P', 'V':
+		return '1'
+	case 'C', 'G', 'J', 'K', 'Q', 'S', 'X', 'Z':
+		return '2'
+	case 'D', 'T':
+		return '3'
+	case 'L':
+		return '4'
+	case 'M', 'N':
+		return '5'
+	case 'R':
+		return '6'
+	}
+	return 0
+}
+
+func soundex(s string) string {
+	var code []byte
+	var last byte
+	for _, r := range strings.ToUpper(s) {
+		if r < 'A' || r > 'Z' {
+			continue
+		}
+		c := soundexCode(r)
+		if len(code) == 0 {
+			code = append(code, byte(r))
+			last = c
+			continue
+		}
+		if c != 0 && c != last {
+			code = append(code, c)
+			if len(code) == 4 {
+				break
+			}
+		}
+		// H and W do not separate letters with the same code, whereas vowels
+		// do.
+		if r != 'H' && r != 'W' {
+			last = c
+		}
+	}
+	if len(code) == 0 {
+		return ""
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"soundex", "",
+	).InCategory(
+		MethodCategoryStrings,
+		"Returns the American Soundex code of a string, which is a letter followed by three digits that encodes how the string sounds when pronounced in English. Strings that sound alike, such as variations in the spelling of a surname, share the same code. Characters other than the letters A to Z are ignored, and an empty string is returned when there are no such letters.",
+		NewExampleSpec("",
+			`root.codes = this.names.map_each(n -> n.soundex())`,
+			`{"names":["Robert","Rupert","Tymczak","Pfister"]}`,
+			`{"codes":["R163","R163","T522","P236"]}`,
+		),
+	).AtVersion("4.29.0"),
+	func(args *ParsedParams) (simpleMethod, error) {
+		return func(v any, ctx FunctionContext) (any, error) {
+			switch t := v.(type) {
+			case string:
+				return soundex(t), nil
+			case []byte:
+				return soundex(string(t)), nil
+			}
+			return nil, value.NewTypeError(v, value.TString)
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+func semverFromValue(v any) (*semver.Version, error) {
+	var str string
+	switch t := v.(type) {
+	case string:
+		str = t
+	case []byte:
+		str = string(t)
+	default:
+		return nil, value.NewTypeError(v, value.TString)
+	}
+	return semver.Parse(str)
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"parse_semver", "",
+	).InCategory(
+		MethodCategoryParsing,
+		"Attempts to parse a string as a https://semver.org[semantic version^] and returns an object containing the fields `major`, `minor` and `patch` as integers, and `prerelease` and `build` as strings, which are empty when absent. A leading `v` is permitted and ignored.",
+		NewExampleSpec("",
+			`root.version = this.version.parse_semver()`,
+			`{"version":"v1.2.3-beta.1+linux"}`,
+			`{"version":{"build":"linux","major":1,"minor":2,"patch":3,"prerelease":"beta.1"}}`,
+			`{"version":"10.0.1"}`,
+			`{"version":{"build":"","major":10,"minor":0,"patch":1,"prerelease":""}}`,
+		),
+	).AtVersion("4.29.0"),
+	func(args *ParsedParams) (simpleMethod, error) {
+		return func(v any, ctx FunctionContext) (any, error) {
+			ver, err := semverFromValue(v)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{
+				"major":      int64(ver.Major),
+				"minor":      int64(ver.Minor),
+				"patch":      int64(ver.Patch),
+				"prerelease": strings.Join(ver.Prerelease, "."),
+				"build":      strings.Join(ver.Build, "."),
+			}, nil
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"semver_compare", "",
+	).InCategory(
+		MethodCategoryStrings,
+		"Compares a semantic version string against another according to their precedence, returning `-1` if the target is lower, `0` if they are equal and `1` if the target is higher. Build metadata is ignored during comparison.",
+		NewExampleSpec("",
+			`root.cmp = this.a.semver_compare(this.b)`,
+			`{"a":"1.10.0","b":"1.9.3"}`,
+			`{"cmp":1}`,
+			`{"a":"1.0.0-rc.1","b":"1.0.0"}`,
+			`{"cmp":-1}`,
+		),
+		NewExampleSpec("Arrays of versions can be sorted by precedence with the `sort` method.",
+			`root.sorted = this.versions.sort(v -> v.left.semver_compare(v.right) < 0)`,
+			`{"versions":["1.10.0","1.2.0","1.9.0-beta","1.9.0"]}`,
+			`{"sorted":["1.2.0","1.9.0-beta","1.9.0","1.10.0"]}`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamString("version", "The semantic version to compare against.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		otherStr, err := args.FieldString("version")
+		if err != nil {
+			return nil, err
+		}
+		other, err := semver.Parse(otherStr)
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			ver, err := semverFromValue(v)
+			if err != nil {
+				return nil, err
+			}
+			return int64(ver.Compare(other)), nil
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"semver_satisfies", "",
+	).InCategory(
+		MethodCategoryStrings,
+		`Checks whether a semantic version string satisfies a range constraint, returning a boolean.
+
+A constraint consists of one or more ranges separated by `+"`||`"+`, and is satisfied when any of the ranges are. A range is a list of comparators separated by whitespace or commas, all of which must be satisfied. Supported comparators are:
+
+- `+"`1.2.3` or `=1.2.3`"+`: an exact version
+- `+"`!=1.2.3`"+`: anything other than a version
+- `+"`>1.2.3`, `>=1.2.3`, `<1.2.3`, `<=1.2.3`"+`: an inequality
+- `+"`~1.2.3`"+`: patch updates, equivalent to `+"`>=1.2.3 <1.3.0`"+`
+- `+"`^1.2.3`"+`: updates that do not modify the left-most non-zero component, equivalent to `+"`>=1.2.3 <2.0.0`"+`
+- `+"`1.2.3 - 2.3.4`"+`: an inclusive range, equivalent to `+"`>=1.2.3 <=2.3.4`"+`
+
+Versions within comparators may be partial or contain the wildcards `+"`x`, `X` or `*`"+`, in which case the missing components match any value. For example, `+"`1.2`, `1.2.x` and `1.2.*`"+` are all equivalent to `+"`>=1.2.0 <1.3.0`"+`.`,
+		NewExampleSpec("",
+			`root.supported = this.version.semver_satisfies("^1.4 || ~2.0.3")`,
+			`{"version":"1.9.2"}`,
+			`{"supported":true}`,
+			`{"version":"2.1.0"}`,
+			`{"supported":false}`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamString("constraint", "The range constraint to check against.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		constraintStr, err := args.FieldString("constraint")
+		if err != nil {
+			return nil, err
+		}
+		constraint, err := semver.ParseConstraint(constraintStr)
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			ver, err := semverFromValue(v)
+			if err != nil {
+				return nil, err
+			}
+			return constraint.Check(ver), nil
+		}, nil
+	},
+)
@@ -5,12 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/Jeffail/gabs/v2"
 	jsonschema "github.com/xeipuuv/gojsonschema"
 
+	"github.com/redpanda-data/benthos/v4/internal/jsonpatch"
+	"github.com/redpanda-data/benthos/v4/internal/jsonpath"
 	"github.com/redpanda-data/benthos/v4/internal/value"
 )
 
@@ -363,7 +367,7 @@ Exploding objects results in an object where the keys match the target object, a
 
 //------------------------------------------------------------------------------
 
-var _ = registerSimpleMethod(
+var _ = registerMethod(
 	NewMethodSpec(
 		"filter", "",
 	).InCategory(
@@ -382,47 +386,12 @@ When filtering objects the mapping query argument is provided a context with a f
 			`{"new_dict":{"first":"hello foo","third":"this foo is great"}}`,
 		),
 	).Param(ParamQuery("test", "A query to apply to each element, if this query resolves to any value other than a boolean `true` the element will be removed from the result.", false)),
-	func(args *ParsedParams) (simpleMethod, error) {
+	func(target Function, args *ParsedParams) (Function, error) {
 		mapFn, err := args.FieldQuery("test")
 		if err != nil {
 			return nil, err
 		}
-		return func(res any, ctx FunctionContext) (any, error) {
-			var resValue any
-			switch t := res.(type) {
-			case []any:
-				newSlice := make([]any, 0, len(t))
-				for _, v := range t {
-					f, err := mapFn.Exec(ctx.WithValue(v))
-					if err != nil {
-						return nil, err
-					}
-					if b, _ := f.(bool); b {
-						newSlice = append(newSlice, v)
-					}
-				}
-				resValue = newSlice
-			case map[string]any:
-				newMap := make(map[string]any, len(t))
-				for k, v := range t {
-					var ctxMap any = map[string]any{
-						"key":   k,
-						"value": v,
-					}
-					f, err := mapFn.Exec(ctx.WithValue(ctxMap))
-					if err != nil {
-						return nil, err
-					}
-					if b, _ := f.(bool); b {
-						newMap[k] = v
-					}
-				}
-				resValue = newMap
-			default:
-				return nil, value.NewTypeError(res, value.TArray, value.TObject)
-			}
-			return resValue, nil
-		}, nil
+		return newFilterMethod(target, mapFn)
 	},
 )
 
@@ -636,6 +605,275 @@ var _ = registerSimpleMethod(
 
 //------------------------------------------------------------------------------
 
+const (
+	flattenArrayModeIndex    = "index"
+	flattenArrayModeBrackets = "brackets"
+	flattenArrayModeKeep     = "keep"
+)
+
+var flattenArrayModes = []string{flattenArrayModeIndex, flattenArrayModeBrackets, flattenArrayModeKeep}
+
+func flattenArrayModeParam(args *ParsedParams) (string, error) {
+	mode, err := args.FieldString("array_mode")
+	if err != nil {
+		return "", err
+	}
+	for _, m := range flattenArrayModes {
+		if m == mode {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unrecognised array mode %q, expected one of %v", mode, flattenArrayModes)
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"flatten_keys",
+		"Flattens an object into an object with a single level of keys, where each key is the full path of a nested value with path segments separated by a delimiter. The way in which arrays are flattened is determined by the `array_mode`, which can be `index` in order to add each array index as a path segment, `brackets` in order to append array indexes to the parent segment within square brackets (`foo[0]`), or `keep` in order to leave arrays as they are. Empty objects and arrays are preserved as values. The resulting object can be reverted with <<unflatten_keys, `unflatten_keys`>>.",
+	).InCategory(
+		MethodCategoryObjectAndArray, "",
+		NewExampleSpec(``,
+			`root = this.flatten_keys()`,
+			`{"id":"foo","user":{"name":"bar","tags":["a","b"]}}`,
+			`{"id":"foo","user.name":"bar","user.tags.0":"a","user.tags.1":"b"}`,
+		),
+		NewExampleSpec(``,
+			`root = this.flatten_keys(delimiter: "_", array_mode: "brackets")`,
+			`{"cpu":{"cores":[{"usage":0.5},{"usage":0.25}]}}`,
+			`{"cpu_cores[0]_usage":0.5,"cpu_cores[1]_usage":0.25}`,
+		),
+		NewExampleSpec(``,
+			`root = this.flatten_keys(delimiter: "/", array_mode: "keep")`,
+			`{"user":{"name":"bar","tags":["a","b"]}}`,
+			`{"user/name":"bar","user/tags":["a","b"]}`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamString("delimiter", "The delimiter to separate path segments with.").Default(".")).
+		Param(ParamString("array_mode", "The way in which arrays are flattened, one of `index`, `brackets` or `keep`.").Default(flattenArrayModeIndex)),
+	func(args *ParsedParams) (simpleMethod, error) {
+		delim, err := args.FieldString("delimiter")
+		if err != nil {
+			return nil, err
+		}
+		mode, err := flattenArrayModeParam(args)
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return nil, value.NewTypeError(v, value.TObject)
+			}
+			result := map[string]any{}
+			flattenKeys(result, "", obj, delim, mode)
+			return result, nil
+		}, nil
+	},
+)
+
+func flattenKeys(result map[string]any, path string, v any, delim, mode string) {
+	switch t := v.(type) {
+	case map[string]any:
+		if len(t) > 0 {
+			for k, child := range t {
+				childPath := k
+				if path != "" {
+					childPath = path + delim + k
+				}
+				flattenKeys(result, childPath, child, delim, mode)
+			}
+			return
+		}
+	case []any:
+		if len(t) > 0 && mode != flattenArrayModeKeep {
+			for i, child := range t {
+				var childPath string
+				if mode == flattenArrayModeBrackets {
+					childPath = path + "[" + strconv.Itoa(i) + "]"
+				} else if childPath = strconv.Itoa(i); path != "" {
+					childPath = path + delim + childPath
+				}
+				flattenKeys(result, childPath, child, delim, mode)
+			}
+			return
+		}
+	}
+	result[path] = v
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"unflatten_keys",
+		"Expands an object with keys containing paths separated by a delimiter into a nested object, reversing the effect of <<flatten_keys, `flatten_keys`>>. When the `array_mode` is `index` any object with keys that are exactly the indexes `0` to `n-1` becomes an array, and when the `array_mode` is `brackets` segments ending in an index within square brackets (`foo[0]`) become arrays. When the `array_mode` is `keep` arrays are never created. An error is returned if a path conflicts with the value of another path.",
+	).InCategory(
+		MethodCategoryObjectAndArray, "",
+		NewExampleSpec(``,
+			`root = this.unflatten_keys()`,
+			`{"id":"foo","user.name":"bar","user.tags.0":"a","user.tags.1":"b"}`,
+			`{"id":"foo","user":{"name":"bar","tags":["a","b"]}}`,
+		),
+		NewExampleSpec(``,
+			`root = this.unflatten_keys(delimiter: "_", array_mode: "brackets")`,
+			`{"cpu_cores[0]_usage":0.5,"cpu_cores[1]_usage":0.25}`,
+			`{"cpu":{"cores":[{"usage":0.5},{"usage":0.25}]}}`,
+		),
+	).AtVersion("4.29.0").
+		Param(ParamString("delimiter", "The delimiter that separates path segments.").Default(".")).
+		Param(ParamString("array_mode", "The way in which arrays are expanded, one of `index`, `brackets` or `keep`.").Default(flattenArrayModeIndex)),
+	func(args *ParsedParams) (simpleMethod, error) {
+		delim, err := args.FieldString("delimiter")
+		if err != nil {
+			return nil, err
+		}
+		if delim == "" {
+			return nil, errors.New("delimiter must not be empty")
+		}
+		mode, err := flattenArrayModeParam(args)
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return nil, value.NewTypeError(v, value.TObject)
+			}
+			return unflattenKeys(obj, delim, mode)
+		}, nil
+	},
+)
+
+// Intermediate objects created whilst expanding keys are distinguished from
+// object values of the input, which are never expanded into.
+type (
+	unflattenObject map[string]any
+	unflattenArray  map[string]any
+)
+
+func unflattenKeys(obj map[string]any, delim, mode string) (any, error) {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	root := unflattenObject{}
+	for _, k := range keys {
+		var segments []string
+		var arrays []bool
+		for _, seg := range strings.Split(k, delim) {
+			if mode != flattenArrayModeBrackets {
+				segments = append(segments, seg)
+				arrays = append(arrays, false)
+				continue
+			}
+			// Extract any trailing indexes, e.g. foo[0][1] becomes foo, 0, 1.
+			var indexes []string
+			for strings.HasSuffix(seg, "]") {
+				open := strings.LastIndexByte(seg, '[')
+				if open < 0 {
+					break
+				}
+				if _, err := strconv.Atoi(seg[open+1 : len(seg)-1]); err != nil {
+					break
+				}
+				indexes = append([]string{seg[open+1 : len(seg)-1]}, indexes...)
+				seg = seg[:open]
+			}
+			if seg != "" || len(indexes) == 0 {
+				segments = append(segments, seg)
+				arrays = append(arrays, len(indexes) > 0)
+			} else if len(arrays) > 0 {
+				arrays[len(arrays)-1] = true
+			}
+			for i, idx := range indexes {
+				segments = append(segments, idx)
+				arrays = append(arrays, i < len(indexes)-1)
+			}
+		}
+
+		current := root
+		for i, seg := range segments[:len(segments)-1] {
+			child, exists := current[seg]
+			if !exists {
+				if arrays[i] {
+					child = unflattenArray{}
+				} else {
+					child = unflattenObject{}
+				}
+				current[seg] = child
+			}
+			switch c := child.(type) {
+			case unflattenObject:
+				current = c
+			case unflattenArray:
+				current = unflattenObject(c)
+			default:
+				return nil, fmt.Errorf("key %v conflicts with key %v", k, strings.Join(segments[:i+1], delim))
+			}
+		}
+
+		last := segments[len(segments)-1]
+		if _, exists := current[last]; exists {
+			return nil, fmt.Errorf("key %v conflicts with another key", k)
+		}
+		current[last] = obj[k]
+	}
+	return unflattenConvert(root, mode)
+}
+
+// unflattenConvert converts intermediate objects into arrays where appropriate.
+func unflattenConvert(v any, mode string) (any, error) {
+	var obj map[string]any
+	forceArray := false
+	switch t := v.(type) {
+	case unflattenObject:
+		obj = t
+	case unflattenArray:
+		obj, forceArray = t, true
+	default:
+		return v, nil
+	}
+
+	for k, child := range obj {
+		var err error
+		if obj[k], err = unflattenConvert(child, mode); err != nil {
+			return nil, err
+		}
+	}
+
+	if forceArray {
+		length := 0
+		for k := range obj {
+			i, err := strconv.Atoi(k)
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("invalid array index %q", k)
+			}
+			length = max(length, i+1)
+		}
+		arr := make([]any, length)
+		for k, child := range obj {
+			i, _ := strconv.Atoi(k)
+			arr[i] = child
+		}
+		return arr, nil
+	}
+
+	if mode == flattenArrayModeIndex && len(obj) > 0 {
+		arr := make([]any, len(obj))
+		for k, child := range obj {
+			i, err := strconv.Atoi(k)
+			if err != nil || i < 0 || i >= len(obj) || strconv.Itoa(i) != k {
+				return map[string]any(obj), nil
+			}
+			arr[i] = child
+		}
+		return arr, nil
+	}
+	return map[string]any(obj), nil
+}
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"fold",
@@ -805,6 +1043,116 @@ var _ = registerSimpleMethod(
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"jsonpath",
+		"Executes a https://www.rfc-editor.org/rfc/rfc9535[JSONPath^] expression against a value and returns an array of all matched values. The expression is parsed once when the mapping is parsed, and must therefore be a literal string.",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"",
+		NewExampleSpec("",
+			`root.cheap = this.store.jsonpath("$.book[?(@.price < 10)].title")`,
+			`{"store":{"book":[{"title":"Sayings of the Century","price":8.95},{"title":"Sword of Honour","price":12.99},{"title":"Moby Dick","price":8.99}]}}`,
+			`{"cheap":["Sayings of the Century","Moby Dick"]}`,
+		),
+		NewExampleSpec("Descendant segments, slices and functions are also supported.",
+			`root.authors = this.jsonpath("$..book[-2:].author")
+root.long_titles = this.jsonpath("$..book[?length(@.title) > 10].title")`,
+			`{"store":{"book":[{"author":"Nigel Rees","title":"Sayings of the Century"},{"author":"Evelyn Waugh","title":"Sword of Honour"},{"author":"Herman Melville","title":"Moby Dick"}]}}`,
+			`{"authors":["Evelyn Waugh","Herman Melville"],"long_titles":["Sayings of the Century","Sword of Honour"]}`,
+		),
+	).AtVersion("4.29.0").Param(ParamString("expression", "The JSONPath expression to execute.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		exprStr, err := args.FieldString("expression")
+		if err != nil {
+			return nil, err
+		}
+		path, err := jsonpath.Compile(exprStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse jsonpath expression: %w", err)
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			return path.Query(v), nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"json_diff",
+		"Computes a https://www.rfc-editor.org/rfc/rfc6902[JSON Patch^] that transforms the target value into the value provided. Objects are compared field by field and arrays are compared index by index, and the resulting patch contains only `add`, `remove` and `replace` operations, which can be applied with <<apply_json_patch, `apply_json_patch`>>.",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"",
+		NewExampleSpec("",
+			`root = this.before.json_diff(this.after)`,
+			`{"before":{"id":"1","name":"foo","tags":["a","b"]},"after":{"id":"1","name":"bar","tags":["a"],"active":true}}`,
+			`[{"op":"replace","path":"/name","value":"bar"},{"op":"remove","path":"/tags/1"},{"op":"add","path":"/active","value":true}]`,
+		),
+	).AtVersion("4.29.0").Param(ParamAny("to", "The value to compute the difference towards.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		to, err := args.Field("to")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			return jsonpatch.Diff(v, to), nil
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"apply_json_patch",
+		"Applies a https://www.rfc-editor.org/rfc/rfc6902[JSON Patch^], which is an array of operation objects, to the target value and returns the result. All operations (`add`, `remove`, `replace`, `move`, `copy` and `test`) are supported, and an error is returned if any of them fail.",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"",
+		NewExampleSpec("",
+			`root = this.doc.apply_json_patch(this.patch)`,
+			`{"doc":{"name":"foo","tags":["a"]},"patch":[{"op":"test","path":"/name","value":"foo"},{"op":"replace","path":"/name","value":"bar"},{"op":"add","path":"/tags/-","value":"b"},{"op":"move","from":"/name","path":"/title"}]}`,
+			`{"tags":["a","b"],"title":"bar"}`,
+		),
+	).AtVersion("4.29.0").Param(ParamArray("patch", "An array of JSON Patch operations to apply.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		patch, err := args.FieldArray("patch")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			return jsonpatch.Apply(v, patch)
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"merge_patch",
+		"Applies a https://www.rfc-editor.org/rfc/rfc7386[JSON Merge Patch^] to the target value and returns the result. Objects within the patch are merged recursively into the target, fields of the patch that are `null` remove the corresponding fields from the target, and any other value replaces the target value entirely. Unlike <<merge, `merge`>> collisions are resolved in favour of the patch.",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"",
+		NewExampleSpec("",
+			`root = this.doc.merge_patch(this.patch)`,
+			`{"doc":{"title":"Hello","author":{"given":"John","family":"Doe"},"tags":["a","b"]},"patch":{"title":"Goodbye","author":{"family":null},"tags":["c"]}}`,
+			`{"author":{"given":"John"},"tags":["c"],"title":"Goodbye"}`,
+		),
+	).AtVersion("4.29.0").Param(ParamAny("patch", "The merge patch to apply.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		patch, err := args.Field("patch")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			return jsonpatch.MergePatch(v, patch), nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"keys",
@@ -908,7 +1256,7 @@ var _ = registerSimpleMethod(
 
 //------------------------------------------------------------------------------
 
-var _ = registerSimpleMethod(
+var _ = registerMethod(
 	NewMethodSpec(
 		"map_each", "",
 	).InCategory(
@@ -932,59 +1280,12 @@ Apply a mapping to each value of an object and replace the value with the result
 			`{"new_dict":{"bar":"WORLD","foo":"HELLO"}}`,
 		),
 	).Param(ParamQuery("query", "A query that will be used to map each element.", false)),
-	func(args *ParsedParams) (simpleMethod, error) {
+	func(target Function, args *ParsedParams) (Function, error) {
 		mapFn, err := args.FieldQuery("query")
 		if err != nil {
 			return nil, err
 		}
-		return func(res any, ctx FunctionContext) (any, error) {
-			var resValue any
-			var err error
-			switch t := res.(type) {
-			case []any:
-				newSlice := make([]any, 0, len(t))
-				for i, v := range t {
-					newV, mapErr := mapFn.Exec(ctx.WithValue(v))
-					if mapErr != nil {
-						return nil, fmt.Errorf("failed to process element %v: %w", i, ErrFrom(mapErr, mapFn))
-					}
-					switch newV.(type) {
-					case value.Delete:
-					case value.Nothing:
-						newSlice = append(newSlice, v)
-					default:
-						newSlice = append(newSlice, newV)
-					}
-				}
-				resValue = newSlice
-			case map[string]any:
-				newMap := make(map[string]any, len(t))
-				for k, v := range t {
-					var ctxMap any = map[string]any{
-						"key":   k,
-						"value": v,
-					}
-					newV, mapErr := mapFn.Exec(ctx.WithValue(ctxMap))
-					if mapErr != nil {
-						return nil, fmt.Errorf("failed to process element %v: %w", k, ErrFrom(mapErr, mapFn))
-					}
-					switch newV.(type) {
-					case value.Delete:
-					case value.Nothing:
-						newMap[k] = v
-					default:
-						newMap[k] = newV
-					}
-				}
-				resValue = newMap
-			default:
-				return nil, value.NewTypeError(res, value.TArray)
-			}
-			if err != nil {
-				return nil, err
-			}
-			return resValue, nil
-		}, nil
+		return newMapEachMethod(target, mapFn)
 	},
 )
 
@@ -1199,6 +1500,61 @@ var _ = registerSimpleMethod(
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"random_weighted", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Returns a random element of an array, where the likelihood of each element being chosen is proportional to its weight within an array of weights of the same length. Weights must be non-negative and at least one weight must be greater than zero.",
+		NewExampleSpec("",
+			`root.destination = ["primary","canary"].random_weighted([ 95, 5 ])`,
+		),
+		NewExampleSpec("Elements with a weight of zero are never chosen.",
+			`root.colour = this.colours.random_weighted([ 0, 1, 0 ])`,
+			`{"colours":["red","green","blue"]}`,
+			`{"colour":"green"}`,
+		),
+	).AtVersion("4.29.0").Param(ParamArray("weights", "An array of numerical weights, one for each element of the array.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		weights, err := args.FieldArray("weights")
+		if err != nil {
+			return nil, err
+		}
+		cumulative := make([]float64, len(weights))
+		total := 0.0
+		for i, w := range weights {
+			f, err := value.IGetNumber(w)
+			if err != nil {
+				return nil, fmt.Errorf("weight index %v: %w", i, err)
+			}
+			if f < 0 {
+				return nil, fmt.Errorf("weight index %v: weights must not be negative, got %v", i, f)
+			}
+			total += f
+			cumulative[i] = total
+		}
+		if total <= 0 {
+			return nil, errors.New("at least one weight must be greater than zero")
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			arr, ok := v.([]any)
+			if !ok {
+				return nil, value.NewTypeError(v, value.TArray)
+			}
+			if len(arr) != len(cumulative) {
+				return nil, fmt.Errorf("array of length %v does not match the %v weights provided", len(arr), len(cumulative))
+			}
+			target := rand.Float64() * total
+			i := sort.Search(len(cumulative), func(i int) bool {
+				return cumulative[i] > target
+			})
+			return arr[min(i, len(arr)-1)], nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerMethod(
 	NewMethodSpec(
 		"sort", "",
@@ -149,3 +149,8 @@ func TestMethodImmutability(t *testing.T) {
 		})
 	}
 }
+
+func TestMethodJSONPathBadExpression(t *testing.T) {
+	_, err := InitMethodHelper("jsonpath", NewLiteralFunction("", map[string]any{}), "store.book")
+	require.EqualError(t, err, "failed to parse jsonpath expression: char 0: expected path to begin with '$'")
+}
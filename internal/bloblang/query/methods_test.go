@@ -2,6 +2,7 @@ package query
 
 import (
 	"encoding/json"
+	"math"
 	"strconv"
 	"testing"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/redpanda-data/benthos/v4/internal/message"
+	"github.com/redpanda-data/benthos/v4/internal/value"
 )
 
 var linebreakStr = `foo
@@ -170,6 +172,370 @@ func TestMethods(t *testing.T) {
 			),
 			err: "string literal: record on line 2: wrong number of fields",
 		},
+		"check parse csv dialect": {
+			input: methods(
+				literalFn("# comment\nfoo; bar\n1; 2\n# another\n3;4"),
+				method("parse_csv", true, ";", false, "#", true),
+				method("string"),
+			),
+			output: `[{"bar":"2","foo":"1"},{"bar":"4","foo":"3"}]`,
+		},
+		"check format csv objects": {
+			input: methods(
+				jsonFn(`[{"b":"x,y","a":1},{"a":"say \"hi\"","c":null},{"b":" lead","c":true}]`),
+				method("format_csv"),
+			),
+			output: "a,b,c\n1,\"x,y\",\n\"say \"\"hi\"\"\",,\n,\" lead\",true",
+		},
+		"check format csv columns no header": {
+			input: methods(
+				jsonFn(`[{"b":"1","a":"2"},{"b":"3\n4"}]`),
+				method("format_csv", false, "|", false, true, []any{"b", "a"}),
+			),
+			output: "1|2\r\n\"3\n4\"|",
+		},
+		"check format csv arrays quote all": {
+			input: methods(
+				jsonFn(`[["a",1],["b",null,2.5]]`),
+				method("format_csv", true, ",", true),
+			),
+			output: `"a","1"` + "\n" + `"b","","2.5"`,
+		},
+		"check format csv empty": {
+			input: methods(
+				jsonFn(`[]`),
+				method("format_csv"),
+			),
+			output: "",
+		},
+		"check format csv round trip": {
+			input: methods(
+				jsonFn(`[{"foo":"1, 2","bar":"say \"hi\""}]`),
+				method("format_csv"),
+				method("parse_csv"),
+				method("string"),
+			),
+			output: `[{"bar":"say \"hi\"","foo":"1, 2"}]`,
+		},
+		"check format csv mixed rows": {
+			input: methods(
+				jsonFn(`[{"a":1},["b"]]`),
+				method("format_csv"),
+			),
+			err: "array literal: index 1: expected object value, got array",
+		},
+		"check format csv not array": {
+			input: methods(
+				literalFn("foo"),
+				method("format_csv"),
+			),
+			err: "expected array value, got string from string literal (\"foo\")",
+		},
+		"check json diff": {
+			input: methods(
+				jsonFn(`{"a":1,"b":[1,2]}`),
+				method("json_diff", map[string]any{"a": int64(1), "b": []any{int64(1)}, "c": "new"}),
+				method("string"),
+			),
+			output: `[{"op":"remove","path":"/b/1"},{"op":"add","path":"/c","value":"new"}]`,
+		},
+		"check apply json patch": {
+			input: methods(
+				jsonFn(`{"a":1,"b":[1,2]}`),
+				method("apply_json_patch", []any{
+					map[string]any{"op": "copy", "from": "/a", "path": "/b/0"},
+					map[string]any{"op": "remove", "path": "/a"},
+				}),
+				method("string"),
+			),
+			output: `{"b":[1,1,2]}`,
+		},
+		"check apply json patch error": {
+			input: methods(
+				jsonFn(`{"a":1}`),
+				method("apply_json_patch", []any{
+					map[string]any{"op": "test", "path": "/a", "value": int64(2)},
+				}),
+			),
+			err: "object literal: operation 0: test failed, value at /a does not match",
+		},
+		"check merge patch": {
+			input: methods(
+				jsonFn(`{"a":1,"b":{"c":2,"d":3}}`),
+				method("merge_patch", map[string]any{"a": nil, "b": map[string]any{"c": "x"}}),
+				method("string"),
+			),
+			output: `{"b":{"c":"x","d":3}}`,
+		},
+		"check flatten keys": {
+			input: methods(
+				jsonFn(`{"a":{"b":[1,{"c":true}],"d":{},"e":[]},"f.g":"h"}`),
+				method("flatten_keys"),
+				method("string"),
+			),
+			output: `{"a.b.0":1,"a.b.1.c":true,"a.d":{},"a.e":[],"f.g":"h"}`,
+		},
+		"check flatten keys brackets": {
+			input: methods(
+				jsonFn(`{"a":[[1,2],{"b":"c"}]}`),
+				method("flatten_keys", "::", "brackets"),
+				method("string"),
+			),
+			output: `{"a[0][0]":1,"a[0][1]":2,"a[1]::b":"c"}`,
+		},
+		"check flatten keys not object": {
+			input: methods(
+				literalFn("foo"),
+				method("flatten_keys"),
+			),
+			err: "expected object value, got string from string literal (\"foo\")",
+		},
+		"check unflatten keys": {
+			input: methods(
+				jsonFn(`{"a.b.0":1,"a.b.1.c":true,"a.d":{},"x.1":"y","x.2":"z"}`),
+				method("unflatten_keys"),
+				method("string"),
+			),
+			output: `{"a":{"b":[1,{"c":true}],"d":{}},"x":{"1":"y","2":"z"}}`,
+		},
+		"check unflatten keys brackets": {
+			input: methods(
+				jsonFn(`{"a[0][1]":2,"a[2]::b":"c","d::0":"e"}`),
+				method("unflatten_keys", "::", "brackets"),
+				method("string"),
+			),
+			output: `{"a":[[null,2],null,{"b":"c"}],"d":{"0":"e"}}`,
+		},
+		"check unflatten keys duplicate path": {
+			input: methods(
+				jsonFn(`{"a.0":1,"a[0]":2}`),
+				method("unflatten_keys", ".", "brackets"),
+			),
+			err: "object literal: key a[0] conflicts with another key",
+		},
+		"check unflatten keys keep": {
+			input: methods(
+				jsonFn(`{"a.0":1,"a.1":2,"b":[3]}`),
+				method("unflatten_keys", ".", "keep"),
+				method("string"),
+			),
+			output: `{"a":{"0":1,"1":2},"b":[3]}`,
+		},
+		"check unflatten keys conflict": {
+			input: methods(
+				jsonFn(`{"a":1,"a.b":2}`),
+				method("unflatten_keys"),
+			),
+			err: "object literal: key a.b conflicts with key a",
+		},
+		"check unflatten keys conflict object value": {
+			input: methods(
+				jsonFn(`{"a":{},"a.b":2}`),
+				method("unflatten_keys"),
+			),
+			err: "object literal: key a.b conflicts with key a",
+		},
+		"check match glob": {
+			input: methods(
+				literalFn("foo/bar.json"),
+				method("match_glob", "*.json"),
+			),
+			output: true,
+		},
+		"check match glob bytes": {
+			input: methods(
+				literalFn([]byte("foo.yaml")),
+				method("match_glob", "*.{yml,json}"),
+			),
+			output: false,
+		},
+		"check match glob not string": {
+			input: methods(
+				literalFn(int64(5)),
+				method("match_glob", "*"),
+			),
+			err: "expected string value, got number from number literal (5)",
+		},
+		"check path match": {
+			input: methods(
+				literalFn("foo/bar.json"),
+				method("path_match", "*.json"),
+			),
+			output: false,
+		},
+		"check path match doublestar": {
+			input: methods(
+				literalFn("foo/bar/baz.json"),
+				method("path_match", "foo/**/*.json"),
+			),
+			output: true,
+		},
+		"check parse ip mapped": {
+			input: methods(
+				literalFn("::ffff:127.0.0.1"),
+				method("parse_ip"),
+				method("string"),
+			),
+			output: `{"address":"::ffff:127.0.0.1","is_global_unicast":false,"is_link_local":false,"is_loopback":true,"is_multicast":false,"is_private":false,"is_unspecified":false,"version":6}`,
+		},
+		"check parse ip invalid": {
+			input: methods(
+				literalFn("nope"),
+				method("parse_ip"),
+			),
+			err: `string literal: ParseAddr("nope"): unable to parse IP`,
+		},
+		"check in cidr": {
+			input: methods(
+				literalFn("192.168.1.200"),
+				method("in_cidr", "192.168.1.128/25"),
+			),
+			output: true,
+		},
+		"check in cidr unmasked range": {
+			input: methods(
+				literalFn("192.168.1.1"),
+				method("in_cidr", "192.168.1.200/24"),
+			),
+			output: true,
+		},
+		"check in cidr mapped": {
+			input: methods(
+				literalFn("::ffff:10.0.0.1"),
+				method("in_cidr", []any{"fd00::/8", "10.0.0.0/8"}),
+			),
+			output: true,
+		},
+		"check in cidr mixed families": {
+			input: methods(
+				literalFn("10.0.0.1"),
+				method("in_cidr", "::/0"),
+			),
+			output: false,
+		},
+		"check ip to int": {
+			input: methods(
+				literalFn("255.255.255.255"),
+				method("ip_to_int"),
+			),
+			output: int64(4294967295),
+		},
+		"check ip to int round trip": {
+			input: methods(
+				literalFn("fe80::1:2"),
+				method("ip_to_int"),
+				method("int_to_ip", int64(6)),
+			),
+			output: "fe80::1:2",
+		},
+		"check int to ip": {
+			input: methods(
+				literalFn(int64(167772161)),
+				method("int_to_ip"),
+			),
+			output: "10.0.0.1",
+		},
+		"check int to ip json number": {
+			input: methods(
+				literalFn(json.Number("1")),
+				method("int_to_ip", int64(6)),
+			),
+			output: "::1",
+		},
+		"check int to ip out of range": {
+			input: methods(
+				literalFn(int64(4294967296)),
+				method("int_to_ip"),
+			),
+			err: "number literal: value 4294967296 exceeds the range of IPv4 addresses",
+		},
+		"check int to ip negative": {
+			input: methods(
+				literalFn(int64(-1)),
+				method("int_to_ip"),
+			),
+			err: "number literal: value -1 exceeds the range of IPv4 addresses",
+		},
+		"check random weighted single weight": {
+			input: methods(
+				jsonFn(`["a","b","c"]`),
+				method("random_weighted", []any{int64(0), 2.5, int64(0)}),
+			),
+			output: "b",
+		},
+		"check random weighted length mismatch": {
+			input: methods(
+				literalFn([]any{"a", "b"}),
+				method("random_weighted", []any{int64(1)}),
+			),
+			err: "array literal: array of length 2 does not match the 1 weights provided",
+		},
+		"check random weighted not array": {
+			input: methods(
+				literalFn("a"),
+				method("random_weighted", []any{int64(1)}),
+			),
+			err: "expected array value, got string from string literal (\"a\")",
+		},
+		"check sample rate zero": {
+			input: methods(
+				literalFn("foo"),
+				method("sample", 0.0),
+			),
+			output: false,
+		},
+		"check sample rate one": {
+			input: methods(
+				literalFn("foo"),
+				method("sample", 1.0),
+			),
+			output: true,
+		},
+		"check re capture object": {
+			input: methods(
+				literalFn("key: foo, other: bar"),
+				method("re_capture_object", `(?P<key>\w+): (?P<value>\w+)(, (?P<missing>nope))?`),
+			),
+			output: map[string]any{"key": "key", "value": "foo", "missing": nil},
+		},
+		"check re capture object bytes": {
+			input: methods(
+				literalFn([]byte("v1.2")),
+				method("re_capture_object", `v(?P<major>\d+)\.(\d+)`),
+			),
+			output: map[string]any{"major": "1"},
+		},
+		"check re capture object no match": {
+			input: methods(
+				literalFn("nope"),
+				method("re_capture_object", `(?P<num>\d+)`),
+			),
+			output: nil,
+		},
+		"check re capture all objects": {
+			input: methods(
+				literalFn("a=1 b=2"),
+				method("re_capture_all_objects", `(?P<key>\w+)=(?P<value>\w+)`),
+			),
+			output: []any{
+				map[string]any{"key": "a", "value": "1"},
+				map[string]any{"key": "b", "value": "2"},
+			},
+		},
+		"check re capture all objects no match": {
+			input: methods(
+				literalFn("nope"),
+				method("re_capture_all_objects", `(?P<num>\d+)`),
+			),
+			output: []any{},
+		},
+		"check re capture object not string": {
+			input: methods(
+				literalFn(int64(5)),
+				method("re_capture_object", `(?P<num>\d+)`),
+			),
+			err: "expected string value, got number from number literal (5)",
+		},
 		"check explode 1": {
 			input: methods(
 				jsonFn(`{"foo":[1,2,3],"id":"bar"}`),
@@ -1206,6 +1572,103 @@ func TestMethods(t *testing.T) {
 			},
 			output: "foo +(70)",
 		},
+		"check xpath node-set": {
+			input: methods(
+				literalFn(`<library><book lang="en"><title>Dune</title></book><book lang="fr"><title>Candide</title></book></library>`),
+				method("xpath", "//book[@lang = 'fr']/title"),
+			),
+			output: []any{"Candide"},
+		},
+		"check xpath scalar": {
+			input: methods(
+				literalFn([]byte(`<library><book><price>5</price></book><book><price>7.5</price></book></library>`)),
+				method("xpath", "sum(//price)"),
+			),
+			output: 12.5,
+		},
+		"check xpath namespaces": {
+			input: methods(
+				literalFn(`<e:envelope xmlns:e="http://example.com/e"><e:body>hello</e:body><body>nope</body></e:envelope>`),
+				method("xpath", "//x:body", map[string]any{"x": "http://example.com/e"}),
+			),
+			output: []any{"hello"},
+		},
+		"check xpath structured": {
+			input: methods(
+				jsonFn(`{"library":{"book":[{"-id":"1","title":"Dune"},{"-id":"2","title":"Candide"}]}}`),
+				method("xpath", "/library/book[@id = '2']/title"),
+			),
+			output: []any{"Candide"},
+		},
+		"check xpath bad document": {
+			input: methods(
+				literalFn(`<library><book></library>`),
+				method("xpath", "//book"),
+			),
+			err: "string literal: failed to parse value as XML: XML syntax error on line 1: element <book> closed by </library>",
+		},
+		"check parse cbor": {
+			input: methods(
+				literalFn("\xa2\x63foo\x63bar\x63baz\x83\x01\x20\xf5"),
+				method("parse_cbor"),
+			),
+			output: map[string]any{
+				"foo": "bar",
+				"baz": []any{int64(1), int64(-1), true},
+			},
+		},
+		"check parse cbor invalid": {
+			input: methods(
+				literalFn("\xa1\x63foo"),
+				method("parse_cbor"),
+			),
+			err: "string literal: failed to parse value as CBOR: unexpected end of data",
+		},
+		"check format cbor": {
+			input: methods(
+				jsonFn(`{"doc":{"foo":"bar"}}`),
+				method("format_cbor"),
+			),
+			output: []byte("\xa1\x63doc\xa1\x63foo\x63bar"),
+		},
+		"check parse msgpack": {
+			input: methods(
+				literalFn("\x82\xa3foo\xa3bar\xa3baz\x93\x01\xff\xc3"),
+				method("parse_msgpack"),
+			),
+			output: map[string]any{
+				"foo": "bar",
+				"baz": []any{int64(1), int64(-1), true},
+			},
+		},
+		"check parse msgpack invalid": {
+			input: methods(
+				literalFn("\x81\xa3foo"),
+				method("parse_msgpack"),
+			),
+			err: "string literal: failed to parse value as MessagePack: unexpected end of data",
+		},
+		"check format msgpack": {
+			input: methods(
+				jsonFn(`{"doc":{"foo":"bar"}}`),
+				method("format_msgpack"),
+			),
+			output: []byte("\x81\xa3doc\x81\xa3foo\xa3bar"),
+		},
+		"check jsonpath": {
+			input: methods(
+				jsonFn(`{"store":{"book":[{"title":"a","price":8.95},{"title":"b","price":12.99},{"title":"c","price":5}]}}`),
+				method("jsonpath", "$.store.book[?(@.price < 10)].title"),
+			),
+			output: []any{"a", "c"},
+		},
+		"check jsonpath no matches": {
+			input: methods(
+				jsonFn(`{"foo":"bar"}`),
+				method("jsonpath", "$..baz"),
+			),
+			output: []any{},
+		},
 		"check parse json": {
 			input: methods(
 				literalFn("{\"foo\":\"bar\"}"),
@@ -1787,6 +2250,404 @@ func TestMethods(t *testing.T) {
 			),
 			err: `method decode: ciphertext is not a multiple of the block size`,
 		},
+		"check aes-gcm encryption": {
+			input: methods(
+				literalFn("hello world!"),
+				method(
+					"encrypt_aes_gcm",
+					methods(
+						literalFn("2b7e151628aed2a6abf7158809cf4f3c"),
+						method("decode", "hex"),
+					),
+					methods(
+						literalFn("cafebabefacedbaddecaf888"),
+						method("decode", "hex"),
+					),
+					"foo",
+				),
+				method("encode", "hex"),
+			),
+			output: `cafebabefacedbaddecaf88869630b793b7c62ba340afc07658f4b3d540ad8e0e7d287b0d5ffdcb9`,
+		},
+		"check aes-gcm decryption": {
+			input: methods(
+				literalFn("cafebabefacedbaddecaf88869630b793b7c62ba340afc07658f4b3d540ad8e0e7d287b0d5ffdcb9"),
+				method("decode", "hex"),
+				method(
+					"decrypt_aes_gcm",
+					methods(
+						literalFn("2b7e151628aed2a6abf7158809cf4f3c"),
+						method("decode", "hex"),
+					),
+					"foo",
+				),
+				method("string"),
+			),
+			output: `hello world!`,
+		},
+		"check aes-gcm decryption bad additional data": {
+			input: methods(
+				literalFn("cafebabefacedbaddecaf88869630b793b7c62ba340afc07658f4b3d540ad8e0e7d287b0d5ffdcb9"),
+				method("decode", "hex"),
+				method(
+					"decrypt_aes_gcm",
+					methods(
+						literalFn("2b7e151628aed2a6abf7158809cf4f3c"),
+						method("decode", "hex"),
+					),
+					"bar",
+				),
+			),
+			err: `method decode: failed to decrypt: cipher: message authentication failed`,
+		},
+		"check aes-gcm decryption too short": {
+			input: methods(
+				literalFn("cafebabe"),
+				method(
+					"decrypt_aes_gcm",
+					methods(
+						literalFn("2b7e151628aed2a6abf7158809cf4f3c"),
+						method("decode", "hex"),
+					),
+				),
+			),
+			err: `string literal: ciphertext is too short`,
+		},
+		"check aes-gcm random nonce roundtrip": {
+			input: methods(
+				literalFn("hello world!"),
+				method("encrypt_aes_gcm", "0123456789abcdef"),
+				method("decrypt_aes_gcm", "0123456789abcdef"),
+				method("string"),
+			),
+			output: `hello world!`,
+		},
+		"check chacha20-poly1305 encryption": {
+			input: methods(
+				literalFn("hello world!"),
+				method(
+					"encrypt_chacha20_poly1305",
+					methods(
+						literalFn("808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f"),
+						method("decode", "hex"),
+					),
+					"000000000000",
+				),
+				method("encode", "hex"),
+			),
+			output: `30303030303030303030303063ffe764b52231b1450ddbc6bdc7f8f1486c192c95f37a17028dd803`,
+		},
+		"check chacha20-poly1305 random nonce roundtrip": {
+			input: methods(
+				literalFn("hello world!"),
+				method("encrypt_chacha20_poly1305", "0123456789abcdef0123456789abcdef"),
+				method("decrypt_chacha20_poly1305", "0123456789abcdef0123456789abcdef"),
+				method("string"),
+			),
+			output: `hello world!`,
+		},
+		"check verify bcrypt": {
+			input: methods(
+				literalFn("hunter2"),
+				method("verify_bcrypt", "$2a$04$ckJlLZjkH6XtTWOQW.U1DecBvXTPpKedOmZxyCkfjKyiKyv8.awVK"),
+			),
+			output: true,
+		},
+		"check verify bcrypt mismatch": {
+			input: methods(
+				literalFn("hunter3"),
+				method("verify_bcrypt", "$2a$04$ckJlLZjkH6XtTWOQW.U1DecBvXTPpKedOmZxyCkfjKyiKyv8.awVK"),
+			),
+			output: false,
+		},
+		"check verify bcrypt bad hash": {
+			input: methods(
+				literalFn("hunter2"),
+				method("verify_bcrypt", "nope"),
+			),
+			err: "string literal: crypto/bcrypt: hashedSecret too short to be a bcrypted password",
+		},
+		"check verify argon2id": {
+			input: methods(
+				literalFn("hunter2"),
+				method("verify_argon2id", "$argon2id$v=19$m=64,t=1,p=1$c29tZXNhbHQ$JbERrFqJ6sqDRxwJTmTun3ZYHAGKT6gtMt8atMtLP+E"),
+			),
+			output: true,
+		},
+		"check verify argon2id mismatch": {
+			input: methods(
+				literalFn("hunter3"),
+				method("verify_argon2id", "$argon2id$v=19$m=64,t=1,p=1$c29tZXNhbHQ$JbERrFqJ6sqDRxwJTmTun3ZYHAGKT6gtMt8atMtLP+E"),
+			),
+			output: false,
+		},
+		"check verify argon2id bad type": {
+			input: methods(
+				literalFn(int64(10)),
+				method("verify_argon2id", "$argon2id$v=19$m=64,t=1,p=1$c29tZXNhbHQ$JbERrFqJ6sqDRxwJTmTun3ZYHAGKT6gtMt8atMtLP+E"),
+			),
+			err: "expected string value, got number from number literal (10)",
+		},
+		"check levenshtein": {
+			input: methods(
+				literalFn("kitten"),
+				method("levenshtein", "sitting"),
+			),
+			output: int64(3),
+		},
+		"check levenshtein bytes": {
+			input: methods(
+				literalFn("flaw"),
+				method("bytes"),
+				method("levenshtein", "lawn"),
+			),
+			output: int64(2),
+		},
+		"check levenshtein unicode": {
+			input: methods(
+				literalFn("naïve"),
+				method("levenshtein", "naive"),
+			),
+			output: int64(1),
+		},
+		"check levenshtein empty": {
+			input: methods(
+				literalFn(""),
+				method("levenshtein", "foo"),
+			),
+			output: int64(3),
+		},
+		"check levenshtein bad type": {
+			input: methods(
+				literalFn(int64(5)),
+				method("levenshtein", "foo"),
+			),
+			err: "expected string value, got number from number literal (5)",
+		},
+		"check jaro winkler": {
+			input: methods(
+				literalFn("DIXON"),
+				method("jaro_winkler", "DICKSONX"),
+			),
+			output: 0.8133333333333332,
+		},
+		"check jaro winkler exact": {
+			input: methods(
+				literalFn("foo"),
+				method("jaro_winkler", "foo"),
+			),
+			output: 1.0,
+		},
+		"check jaro winkler no match": {
+			input: methods(
+				literalFn("abc"),
+				method("jaro_winkler", "xyz"),
+			),
+			output: 0.0,
+		},
+		"check jaro winkler empty": {
+			input: methods(
+				literalFn(""),
+				method("jaro_winkler", "foo"),
+			),
+			output: 0.0,
+		},
+		"check soundex": {
+			input: methods(
+				jsonFn(`["Robert","Rupert","Rubin","Ashcraft","Tymczak","Pfister","Honeyman","Lee","O'Hara","","123"]`),
+				method("map_each", methods(
+					NewFieldFunction(""),
+					method("soundex"),
+				)),
+			),
+			output: []any{"R163", "R163", "R150", "A261", "T522", "P236", "H555", "L000", "O600", "", ""},
+		},
+		"check parse semver": {
+			input: methods(
+				literalFn("v1.2.3-beta.1+linux"),
+				method("parse_semver"),
+			),
+			output: map[string]any{
+				"major":      int64(1),
+				"minor":      int64(2),
+				"patch":      int64(3),
+				"prerelease": "beta.1",
+				"build":      "linux",
+			},
+		},
+		"check parse semver bad": {
+			input: methods(
+				literalFn("1.2"),
+				method("parse_semver"),
+			),
+			err: `string literal: expected version "1.2" to have the format MAJOR.MINOR.PATCH`,
+		},
+		"check semver compare": {
+			input: methods(
+				literalFn("1.10.0"),
+				method("semver_compare", "1.9.3"),
+			),
+			output: int64(1),
+		},
+		"check semver compare prerelease": {
+			input: methods(
+				literalFn("1.0.0-alpha"),
+				method("bytes"),
+				method("semver_compare", "1.0.0"),
+			),
+			output: int64(-1),
+		},
+		"check semver compare equal": {
+			input: methods(
+				literalFn("1.0.0+foo"),
+				method("semver_compare", "v1.0.0"),
+			),
+			output: int64(0),
+		},
+		"check semver satisfies": {
+			input: methods(
+				literalFn("1.9.2"),
+				method("semver_satisfies", ">=1.4, <2"),
+			),
+			output: true,
+		},
+		"check semver satisfies not": {
+			input: methods(
+				literalFn("2.1.0"),
+				method("semver_satisfies", "^1.4 || ~2.0.3"),
+			),
+			output: false,
+		},
+		"check semver satisfies bad type": {
+			input: methods(
+				literalFn(int64(2)),
+				method("semver_satisfies", "^1.4"),
+			),
+			err: "expected string value, got number from number literal (2)",
+		},
+		"check parse url": {
+			input: methods(
+				literalFn("https://foo:bar@[::1]:8080/a%2Fb?x=1&x=2&y=3#frag"),
+				method("parse_url"),
+			),
+			output: map[string]any{
+				"scheme":       "https",
+				"opaque":       "",
+				"host":         "[::1]:8080",
+				"hostname":     "::1",
+				"port":         "8080",
+				"path":         "/a/b",
+				"raw_path":     "/a%2Fb",
+				"raw_query":    "x=1&x=2&y=3",
+				"query":        map[string]any{"x": []any{"1", "2"}, "y": "3"},
+				"fragment":     "frag",
+				"raw_fragment": "",
+				"user":         map[string]any{"name": "foo", "password": "bar"},
+			},
+		},
+		"check format url round trip": {
+			input: methods(
+				literalFn("https://foo:bar@[::1]:8080/a%2Fb?x=1&x=2&y=3#frag"),
+				method("parse_url"),
+				method("format_url"),
+			),
+			output: "https://foo:bar@[::1]:8080/a%2Fb?x=1&x=2&y=3#frag",
+		},
+		"check format url": {
+			input: methods(
+				jsonFn(`{"scheme":"http","hostname":"example.com","port":"4195","host":"ignored","path":"/foo bar","raw_query":"ignored","query":{"b":["1",2],"a":true},"user":{"name":"foo"}}`),
+				method("format_url"),
+			),
+			output: "http://foo@example.com:4195/foo%20bar?a=true&b=1&b=2",
+		},
+		"check format url raw query": {
+			input: methods(
+				jsonFn(`{"host":"example.com","raw_query":"b=2&a=1"}`),
+				method("format_url"),
+			),
+			output: "//example.com?b=2&a=1",
+		},
+		"check format url bad field": {
+			input: methods(
+				jsonFn(`{"host":10}`),
+				method("format_url"),
+			),
+			err: "object literal: field host: expected string value, got number (10)",
+		},
+		"check format url bad user": {
+			input: methods(
+				jsonFn(`{"host":"example.com","user":{"name":"foo","password":false}}`),
+				method("format_url"),
+			),
+			err: "object literal: field user.password: expected string value, got bool (false)",
+		},
+		"check format url not object": {
+			input: methods(
+				literalFn("http://example.com"),
+				method("format_url"),
+			),
+			err: `expected object value, got string from string literal ("http://example.com")`,
+		},
+		"check checked add": {
+			input:  methods(literalFn(int64(9007199254740993)), method("checked_add", int64(1))),
+			output: int64(9007199254740994),
+		},
+		"check checked add json number": {
+			input:  methods(literalFn(json.Number("-5")), method("checked_add", 2.0)),
+			output: int64(-3),
+		},
+		"check checked add overflow": {
+			input: methods(literalFn(int64(math.MaxInt64)), method("checked_add", int64(1))),
+			err:   "number literal: integer overflow: 9223372036854775807 + 1 exceeds the range of a 64-bit signed integer",
+		},
+		"check checked add fractional": {
+			input: methods(literalFn(1.5), method("checked_add", int64(1))),
+			err:   "number literal: float value contains decimals and therefore cannot be cast as a signed integer, if you intend to round the value then call `.round()` explicitly before this cast",
+		},
+		"check checked add string": {
+			input: methods(literalFn("5"), method("checked_add", int64(1))),
+			err:   `expected integer value, got string from string literal ("5")`,
+		},
+		"check checked sub": {
+			input:  methods(literalFn(int64(4500)), method("checked_sub", int64(1000))),
+			output: int64(3500),
+		},
+		"check checked sub overflow": {
+			input: methods(literalFn(int64(math.MinInt64)), method("checked_sub", int64(1))),
+			err:   "number literal: integer overflow: -9223372036854775808 - 1 exceeds the range of a 64-bit signed integer",
+		},
+		"check checked mul": {
+			input:  methods(literalFn(int64(-123456789)), method("checked_mul", int64(1000))),
+			output: int64(-123456789000),
+		},
+		"check checked mul overflow": {
+			input: methods(literalFn(int64(4294967296)), method("checked_mul", int64(4294967296))),
+			err:   "number literal: integer overflow: 4294967296 * 4294967296 exceeds the range of a 64-bit signed integer",
+		},
+		"check checked mul min int overflow": {
+			input: methods(literalFn(int64(math.MinInt64)), method("checked_mul", int64(-1))),
+			err:   "number literal: integer overflow: -9223372036854775808 * -1 exceeds the range of a 64-bit signed integer",
+		},
+		"check checked div": {
+			input:  methods(literalFn(int64(-7)), method("checked_div", int64(2))),
+			output: int64(-3),
+		},
+		"check checked div by zero": {
+			input: methods(literalFn(int64(7)), method("checked_div", int64(0))),
+			err:   "number literal: attempted to divide by zero",
+		},
+		"check checked div overflow": {
+			input: methods(literalFn(int64(math.MinInt64)), method("checked_div", int64(-1))),
+			err:   "number literal: integer overflow: -9223372036854775808 / -1 exceeds the range of a 64-bit signed integer",
+		},
+		"check checked mod": {
+			input:  methods(literalFn(int64(-7)), method("checked_mod", int64(3))),
+			output: int64(-1),
+		},
+		"check checked mod min int": {
+			input:  methods(literalFn(int64(math.MinInt64)), method("checked_mod", int64(-1))),
+			output: int64(0),
+		},
 		"check any no array": {
 			input: methods(
 				literalFn("foo"),
@@ -2009,6 +2870,233 @@ func TestMethods(t *testing.T) {
 	}
 }
 
+func TestMethodAEADBadParams(t *testing.T) {
+	_, err := InitMethodHelper("encrypt_aes_gcm", NewLiteralFunction("", "foo"), "tooshort")
+	require.EqualError(t, err, "crypto/aes: invalid key size 8")
+
+	_, err = InitMethodHelper("encrypt_aes_gcm", NewLiteralFunction("", "foo"), "0123456789abcdef", "abc")
+	require.EqualError(t, err, "the nonce must be 12 bytes long")
+
+	_, err = InitMethodHelper("decrypt_chacha20_poly1305", NewLiteralFunction("", "foo"), "0123456789abcdef")
+	require.EqualError(t, err, "chacha20poly1305: bad key length")
+}
+
+func TestMethodPasswordHashRoundTrip(t *testing.T) {
+	for _, m := range [][2]string{
+		{"hash_bcrypt", "verify_bcrypt"},
+		{"hash_argon2id", "verify_argon2id"},
+	} {
+		hashFn, err := InitMethodHelper(m[0], NewLiteralFunction("", "hunter2"))
+		require.NoError(t, err, m[0])
+
+		hash, err := hashFn.Exec(FunctionContext{})
+		require.NoError(t, err, m[0])
+
+		otherHash, err := hashFn.Exec(FunctionContext{})
+		require.NoError(t, err, m[0])
+		assert.NotEqual(t, hash, otherHash, "salt should be random")
+
+		for password, exp := range map[string]bool{"hunter2": true, "hunter3": false} {
+			verifyFn, err := InitMethodHelper(m[1], NewLiteralFunction("", password), hash)
+			require.NoError(t, err, m[1])
+
+			res, err := verifyFn.Exec(FunctionContext{})
+			require.NoError(t, err, m[1])
+			assert.Equal(t, exp, res, m[1])
+		}
+	}
+}
+
+func TestMethodPasswordHashBadParams(t *testing.T) {
+	_, err := InitMethodHelper("hash_bcrypt", NewLiteralFunction("", "foo"), int64(2))
+	require.EqualError(t, err, "cost must be between 4 and 31, got 2")
+
+	_, err = InitMethodHelper("hash_argon2id", NewLiteralFunction("", "foo"), int64(0))
+	require.EqualError(t, err, "iterations must be between 1 and 4294967295, got 0")
+
+	_, err = InitMethodHelper("hash_argon2id", NewLiteralFunction("", "foo"), int64(1), int64(64), int64(256))
+	require.EqualError(t, err, "parallelism must be between 1 and 255, got 256")
+
+	_, err = InitMethodHelper("hash_argon2id", NewLiteralFunction("", "foo"), int64(1), int64(4194305))
+	require.EqualError(t, err, "memory must be between 1 and 4194304, got 4194305")
+}
+
+func TestMethodVerifyArgon2idBadHash(t *testing.T) {
+	for hash, errStr := range map[string]string{
+		"$argon2id$v=16$m=64,t=1,p=1$c29tZXNhbHQ$JbERrFqJ6sqDRxwJTmTun3ZYHAGKT6gtMt8atMtLP+E":         "unsupported argon2id version: 16",
+		"$argon2i$v=19$m=64,t=1,p=1$c29tZXNhbHQ$JbERrFqJ6sqDRxwJTmTun3ZYHAGKT6gtMt8atMtLP+E":          "expected an argon2id hash in the format $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>",
+		"$argon2id$v=19$m=64,t=0,p=1$c29tZXNhbHQ$JbERrFqJ6sqDRxwJTmTun3ZYHAGKT6gtMt8atMtLP+E":         "argon2id iterations must be at least 1, got 0",
+		"$argon2id$v=19$m=64,t=1,p=0$c29tZXNhbHQ$JbERrFqJ6sqDRxwJTmTun3ZYHAGKT6gtMt8atMtLP+E":         "argon2id parallelism must be at least 1, got 0",
+		"$argon2id$v=19$m=4294967295,t=1,p=1$c29tZXNhbHQ$JbERrFqJ6sqDRxwJTmTun3ZYHAGKT6gtMt8atMtLP+E": "argon2id memory must not exceed 4194304 KiB, got 4294967295",
+	} {
+		// A static hash is parsed once when the method is created.
+		_, err := InitMethodHelper("verify_argon2id", NewLiteralFunction("", "hunter2"), hash)
+		require.EqualError(t, err, errStr, hash)
+
+		// A dynamic hash is parsed each time the method is executed.
+		fn, err := InitMethodHelper("verify_argon2id", NewLiteralFunction("", "hunter2"), NewFieldFunction("hash"))
+		require.NoError(t, err, hash)
+
+		_, err = fn.Exec(FunctionContext{}.WithValue(map[string]any{"hash": hash}))
+		require.Error(t, err, hash)
+		assert.Contains(t, err.Error(), errStr, hash)
+	}
+}
+
+func TestMethodSemverBadParams(t *testing.T) {
+	_, err := InitMethodHelper("semver_compare", NewLiteralFunction("", "1.0.0"), "nope")
+	require.EqualError(t, err, `expected version "nope" to have the format MAJOR.MINOR.PATCH`)
+
+	_, err = InitMethodHelper("semver_satisfies", NewLiteralFunction("", "1.0.0"), "=>1.0.0")
+	require.EqualError(t, err, `invalid constraint "=>1.0.0": unrecognised operator "=>"`)
+}
+
+func TestMethodDecimals(t *testing.T) {
+	for _, test := range []struct {
+		target any
+		method string
+		args   []any
+		output string
+	}{
+		{target: "0.1", method: "parse_decimal", output: "0.1"},
+		{target: 0.1, method: "parse_decimal", output: "0.1"},
+		{target: json.Number("123456789012345678901234567890.5"), method: "parse_decimal", output: "123456789012345678901234567890.5"},
+		{target: "2.345", method: "round_decimal", args: []any{int64(2), "half_up"}, output: "2.35"},
+		{target: 2.345, method: "round_decimal", args: []any{int64(2), "half_even"}, output: "2.34"},
+		{target: int64(1250), method: "round_decimal", args: []any{int64(-2), "floor"}, output: "1200"},
+	} {
+		fn, err := InitMethodHelper(test.method, NewLiteralFunction("", test.target), test.args...)
+		require.NoError(t, err)
+
+		res, err := fn.Exec(FunctionContext{
+			Maps:     map[string]Function{},
+			Vars:     map[string]any{},
+			MsgBatch: message.QuickBatch(nil),
+		})
+		require.NoError(t, err)
+		require.IsType(t, &value.Decimal{}, res)
+		assert.Equal(t, test.output, res.(*value.Decimal).String(), "%v.%v(%v)", test.target, test.method, test.args)
+	}
+
+	fn, err := InitMethodHelper("parse_decimal", NewLiteralFunction("", "nope"))
+	require.NoError(t, err)
+	_, err = fn.Exec(FunctionContext{MsgBatch: message.QuickBatch(nil)})
+	require.EqualError(t, err, `string literal: failed to parse "nope" as a decimal`)
+
+	fn, err = InitMethodHelper("parse_decimal", NewLiteralFunction("", true))
+	require.NoError(t, err)
+	_, err = fn.Exec(FunctionContext{MsgBatch: message.QuickBatch(nil)})
+	require.EqualError(t, err, "expected number or string value, got bool from bool literal (true)")
+
+	_, err = InitMethodHelper("round_decimal", NewLiteralFunction("", "1.5"), int64(0), "sideways")
+	require.EqualError(t, err, `unrecognised rounding mode "sideways", expected one of [half_up half_even half_down up down ceiling floor]`)
+}
+
+func TestMethodCheckedIntBadParams(t *testing.T) {
+	_, err := InitMethodHelper("checked_add", NewLiteralFunction("", int64(1)), 1.5)
+	require.EqualError(t, err, "value argument: float value contains decimals and therefore cannot be cast as a signed integer, if you intend to round the value then call `.round()` explicitly before this cast")
+
+	_, err = InitMethodHelper("checked_mul", NewLiteralFunction("", int64(1)), "2")
+	require.EqualError(t, err, "value argument: expected integer value, got string (\"2\")")
+}
+
+func TestMethodCSVBadParams(t *testing.T) {
+	_, err := InitMethodHelper("format_csv", NewLiteralFunction("", []any{}), true, ";;")
+	require.EqualError(t, err, "delimiter value must be exactly one character and cannot be a quote or new line")
+
+	_, err = InitMethodHelper("format_csv", NewLiteralFunction("", []any{}), true, ",", false, false, []any{"a", 5})
+	require.EqualError(t, err, "columns: index 1: expected string value, got number (5)")
+}
+
+func TestMethodFlattenKeysBadParams(t *testing.T) {
+	_, err := InitMethodHelper("flatten_keys", NewLiteralFunction("", map[string]any{}), ".", "nope")
+	require.EqualError(t, err, "unrecognised array mode \"nope\", expected one of [index brackets keep]")
+
+	_, err = InitMethodHelper("unflatten_keys", NewLiteralFunction("", map[string]any{}), "")
+	require.EqualError(t, err, "delimiter must not be empty")
+}
+
+func TestMethodGlobBadPattern(t *testing.T) {
+	_, err := InitMethodHelper("match_glob", NewLiteralFunction("", "foo"), "{foo")
+	require.EqualError(t, err, "failed to parse glob pattern: pattern contains an unterminated brace")
+
+	_, err = InitMethodHelper("path_match", NewLiteralFunction("", "foo"), "[a")
+	require.EqualError(t, err, "failed to parse glob pattern: character class at position 0 is unterminated")
+}
+
+func TestMethodNetworkBadParams(t *testing.T) {
+	_, err := InitMethodHelper("in_cidr", NewLiteralFunction("", "10.0.0.1"), "10.0.0.0")
+	require.EqualError(t, err, `failed to parse range: netip.ParsePrefix("10.0.0.0"): no '/'`)
+
+	_, err = InitMethodHelper("in_cidr", NewLiteralFunction("", "10.0.0.1"), []any{"10.0.0.0/8", int64(5)})
+	require.EqualError(t, err, "range index 1: expected string value, got number (5)")
+
+	_, err = InitMethodHelper("int_to_ip", NewLiteralFunction("", int64(1)), int64(5))
+	require.EqualError(t, err, "version must be either 4 or 6, got 5")
+}
+
+func TestMethodRandomWeightedBadParams(t *testing.T) {
+	_, err := InitMethodHelper("random_weighted", NewLiteralFunction("", []any{"a"}), []any{"nope"})
+	require.EqualError(t, err, `weight index 0: expected number value, got string ("nope")`)
+
+	_, err = InitMethodHelper("random_weighted", NewLiteralFunction("", []any{"a"}), []any{int64(-1)})
+	require.EqualError(t, err, "weight index 0: weights must not be negative, got -1")
+
+	_, err = InitMethodHelper("random_weighted", NewLiteralFunction("", []any{"a", "b"}), []any{int64(0), 0.0})
+	require.EqualError(t, err, "at least one weight must be greater than zero")
+}
+
+func TestMethodRandomWeightedDistribution(t *testing.T) {
+	fn, err := InitMethodHelper("random_weighted", NewLiteralFunction("", []any{"a", "b"}), []any{int64(1), int64(3)})
+	require.NoError(t, err)
+
+	counts := map[any]int{}
+	for i := 0; i < 4000; i++ {
+		v, err := fn.Exec(FunctionContext{})
+		require.NoError(t, err)
+		counts[v]++
+	}
+	assert.InDelta(t, 1000, counts["a"], 200)
+	assert.InDelta(t, 3000, counts["b"], 200)
+}
+
+func TestMethodSample(t *testing.T) {
+	_, err := InitMethodHelper("sample", NewLiteralFunction("", "foo"), 1.5)
+	require.EqualError(t, err, "rate must be between 0 and 1, got 1.5")
+
+	sampled := func(v any, rate float64, seedKey string) bool {
+		t.Helper()
+		fn, err := InitMethodHelper("sample", NewLiteralFunction("", v), rate, seedKey)
+		require.NoError(t, err)
+		res, err := fn.Exec(FunctionContext{})
+		require.NoError(t, err)
+		return res.(bool)
+	}
+
+	count, diffSeed := 0, 0
+	for i := 0; i < 4000; i++ {
+		key := "user-" + strconv.Itoa(i)
+		a := sampled(key, 0.25, "")
+		assert.Equal(t, a, sampled(key, 0.25, ""), key)
+		if a {
+			count++
+		}
+		if a != sampled(key, 0.25, "other") {
+			diffSeed++
+		}
+	}
+	assert.InDelta(t, 1000, count, 200)
+	assert.Greater(t, diffSeed, 0)
+}
+
+func TestMethodReCaptureBadPattern(t *testing.T) {
+	_, err := InitMethodHelper("re_capture_object", NewLiteralFunction("", "foo"), `(\w+)`)
+	require.EqualError(t, err, "pattern does not contain any named capture groups")
+
+	_, err = InitMethodHelper("re_capture_all_objects", NewLiteralFunction("", "foo"), `(?P<foo>`)
+	require.Error(t, err)
+}
+
 func TestMethodTargets(t *testing.T) {
 	function := func(name string, args ...any) Function {
 		t.Helper()
@@ -448,6 +448,22 @@ type ParsedParams struct {
 	source  Params
 	dynArgs []dynamicArgIndex
 	values  []any
+	states  *PluginStates
+}
+
+// SetPluginStates sets the plugin states of the mapping that the parameters
+// belong to, allowing plugins to share state across instantiations.
+func (p *ParsedParams) SetPluginStates(states *PluginStates) {
+	p.states = states
+}
+
+// PluginStates returns the plugin states of the mapping that the parameters
+// belong to, which may be nil.
+func (p *ParsedParams) PluginStates() *PluginStates {
+	if p == nil {
+		return nil
+	}
+	return p.states
 }
 
 // dynamic returns any argument functions that must be evaluated at query time.
@@ -493,6 +509,7 @@ func (p *ParsedParams) ResolveDynamic(ctx FunctionContext) (*ParsedParams, error
 	return &ParsedParams{
 		source: p.source,
 		values: newValues,
+		states: p.states,
 	}, nil
 }
 
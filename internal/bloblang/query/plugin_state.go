@@ -0,0 +1,156 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// PluginStateCloser is called in order to clean up the state of a plugin once
+// the mapping it belongs to is no longer in use.
+type PluginStateCloser func(ctx context.Context) error
+
+// PluginStateInit initialises the state of a plugin, returning the state and
+// an optional closer to be called when the state is no longer needed.
+type PluginStateInit func() (any, PluginStateCloser, error)
+
+// PersistentPluginState is an optional interface implemented by plugin states
+// that can be stored and later restored, allowing the state of a mapping to
+// continue where it left off after a restart.
+type PersistentPluginState interface {
+	MarshalState() ([]byte, error)
+	UnmarshalState(b []byte) error
+}
+
+// PluginStates stores state values that are shared by all instantiations of a
+// plugin within a mapping, along with the closers that clean them up.
+type PluginStates struct {
+	mut      sync.Mutex
+	states   map[string]any
+	closers  []PluginStateCloser
+	restored map[string][]byte
+}
+
+// NewPluginStates returns an empty set of plugin states.
+func NewPluginStates() *PluginStates {
+	return &PluginStates{
+		states: map[string]any{},
+	}
+}
+
+// GetOrInit returns the state stored under a given key, calling init in order
+// to create it if it does not yet exist.
+//
+// A nil PluginStates is valid and results in the state being initialised on
+// each call and never closed.
+func (p *PluginStates) GetOrInit(key string, init PluginStateInit) (any, error) {
+	if p == nil {
+		v, _, err := init()
+		return v, err
+	}
+
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	if v, exists := p.states[key]; exists {
+		return v, nil
+	}
+	v, closer, err := init()
+	if err != nil {
+		return nil, err
+	}
+	if b, exists := p.restored[key]; exists {
+		if ps, ok := v.(PersistentPluginState); ok {
+			if err := ps.UnmarshalState(b); err != nil {
+				return nil, fmt.Errorf("failed to restore state: %w", err)
+			}
+		}
+		delete(p.restored, key)
+	}
+	p.states[key] = v
+	if closer != nil {
+		p.closers = append(p.closers, closer)
+	}
+	return v, nil
+}
+
+// Snapshot returns the marshalled form of all initialised states that
+// implement PersistentPluginState, keyed by the same keys as the states.
+func (p *PluginStates) Snapshot() (map[string][]byte, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	var snapshot map[string][]byte
+	for k, v := range p.states {
+		ps, ok := v.(PersistentPluginState)
+		if !ok {
+			continue
+		}
+		b, err := ps.MarshalState()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal state %v: %w", k, err)
+		}
+		if snapshot == nil {
+			snapshot = map[string][]byte{}
+		}
+		snapshot[k] = b
+	}
+	return snapshot, nil
+}
+
+// Restore applies a snapshot obtained from Snapshot. States that are already
+// initialised are restored immediately, and the remainder are restored once
+// they are initialised.
+func (p *PluginStates) Restore(snapshot map[string][]byte) error {
+	if p == nil {
+		return nil
+	}
+
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	for k, b := range snapshot {
+		v, exists := p.states[k]
+		if !exists {
+			if p.restored == nil {
+				p.restored = map[string][]byte{}
+			}
+			p.restored[k] = b
+			continue
+		}
+		if ps, ok := v.(PersistentPluginState); ok {
+			if err := ps.UnmarshalState(b); err != nil {
+				return fmt.Errorf("failed to restore state %v: %w", k, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Close calls the closers of all initialised states in the reverse order of
+// their initialisation and resets the states. Subsequent calls to Close are
+// no-ops unless more states are initialised.
+func (p *PluginStates) Close(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+
+	p.mut.Lock()
+	closers := p.closers
+	p.closers = nil
+	p.states = map[string]any{}
+	p.mut.Unlock()
+
+	var errs []error
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := closers[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
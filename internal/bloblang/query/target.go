@@ -74,6 +74,7 @@ type TargetsContext struct {
 	mainContext   []TargetPath
 	prevContext   *prevContextPath
 	namedContext  *namedContextPath
+	calledMaps    *calledMapPath
 }
 
 type prevContextPath struct {
@@ -87,6 +88,11 @@ type namedContextPath struct {
 	next  *namedContextPath
 }
 
+type calledMapPath struct {
+	call *mapCallFunction
+	next *calledMapPath
+}
+
 // Value returns the current value of the targets context, which is the path(s)
 // being executed upon by methods.
 func (ctx TargetsContext) Value() []TargetPath {
@@ -148,6 +154,19 @@ func (ctx TargetsContext) WithContextAsNamed(name string) TargetsContext {
 	return ctx
 }
 
+// withCalledMap returns a targets context that records a map call as having
+// been walked, or false if the call has already been walked within the context,
+// which happens with recursive maps.
+func (ctx TargetsContext) withCalledMap(call *mapCallFunction) (TargetsContext, bool) {
+	for current := ctx.calledMaps; current != nil; current = current.next {
+		if current.call == call {
+			return ctx, false
+		}
+	}
+	ctx.calledMaps = &calledMapPath{call: call, next: ctx.calledMaps}
+	return ctx, true
+}
+
 // PopContext returns a targets context with the latest context dropped and the
 // previous (when applicable) returned.
 func (ctx TargetsContext) PopContext() TargetsContext {
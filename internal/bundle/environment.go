@@ -51,6 +51,9 @@ func (e *Environment) Clone() *Environment {
 	for _, v := range e.outputs.specs {
 		_ = newEnv.outputs.Add(v.constructor, v.spec)
 	}
+	for _, m := range e.outputs.sinkMiddleware {
+		newEnv.outputs.AddSinkMiddleware(m)
+	}
 	for _, v := range e.processors.specs {
 		_ = newEnv.processors.Add(v.constructor, v.spec)
 	}
@@ -37,6 +37,18 @@ func (e *Environment) OutputDocs() []docs.ComponentSpec {
 	return e.outputs.Docs()
 }
 
+// OutputAddSinkMiddleware adds a middleware to this environment that is
+// applied to the sinks of output plugins as they are constructed.
+func (e *Environment) OutputAddSinkMiddleware(m OutputSinkMiddleware) {
+	e.outputs.AddSinkMiddleware(m)
+}
+
+// OutputWrapSink applies all sink middleware of this environment to the sink
+// of an output plugin.
+func (e *Environment) OutputWrapSink(typeName string, mgr NewManagement, sink output.AsyncSink) output.AsyncSink {
+	return e.outputs.WrapSink(typeName, mgr, sink)
+}
+
 //------------------------------------------------------------------------------
 
 // OutputConstructor constructs an output component.
@@ -47,9 +59,30 @@ type outputSpec struct {
 	spec        docs.ComponentSpec
 }
 
+// OutputSinkMiddleware wraps the sink of an output plugin, allowing the
+// writes and connection attempts of the output to be intercepted.
+type OutputSinkMiddleware func(typeName string, mgr NewManagement, sink output.AsyncSink) output.AsyncSink
+
 // OutputSet contains an explicit set of outputs available to a Benthos service.
 type OutputSet struct {
-	specs map[string]outputSpec
+	specs          map[string]outputSpec
+	sinkMiddleware []OutputSinkMiddleware
+}
+
+// AddSinkMiddleware adds a middleware to be applied to the sinks of output
+// plugins, where middleware are applied in the order that they are added and
+// therefore the last middleware added is the outermost.
+func (s *OutputSet) AddSinkMiddleware(m OutputSinkMiddleware) {
+	s.sinkMiddleware = append(s.sinkMiddleware, m)
+}
+
+// WrapSink applies all sink middleware of the set to the sink of an output
+// plugin.
+func (s *OutputSet) WrapSink(typeName string, mgr NewManagement, sink output.AsyncSink) output.AsyncSink {
+	for _, m := range s.sinkMiddleware {
+		sink = m(typeName, mgr, sink)
+	}
+	return sink
 }
 
 // Add a new output to this set by providing a spec (name, documentation, and
@@ -93,6 +93,10 @@ type NewManagement interface {
 	GetPipe(name string) (<-chan message.Transaction, error)
 	SetPipe(name string, t <-chan message.Transaction)
 	UnsetPipe(name string, t <-chan message.Transaction)
+
+	SubscribePipe(name, subscription string, buffer int) <-chan message.Transaction
+	UnsubscribePipe(name, subscription string)
+	GetPipeSubscriptions(name string) ([]PipeSubscription, <-chan struct{})
 }
 
 type componentErr struct {
@@ -0,0 +1,138 @@
+package bundle
+
+import (
+	"context"
+	"sync"
+
+	"github.com/redpanda-data/benthos/v4/internal/component"
+	"github.com/redpanda-data/benthos/v4/internal/message"
+)
+
+// PipeSubscription is a subscription to a named pipe, each subscription of a
+// pipe receives every transaction sent to it.
+type PipeSubscription struct {
+	Transactions chan<- message.Transaction
+
+	// Closed is closed once the subscription has no remaining subscribers, at
+	// which point transactions should no longer be sent to it.
+	Closed <-chan struct{}
+}
+
+type pipeSubscriber struct {
+	transactions chan message.Transaction
+	closed       chan struct{}
+	refs         int
+}
+
+type pipeSubscribers struct {
+	subs    map[string]*pipeSubscriber
+	changed chan struct{}
+}
+
+// PipeSubscriptions is a registry of subscriptions to named pipes, where
+// multiple subscribers of the same subscription share a transaction chan and
+// therefore each receive a portion of the transactions sent to it.
+type PipeSubscriptions struct {
+	mut   sync.Mutex
+	pipes map[string]*pipeSubscribers
+}
+
+// NewPipeSubscriptions creates an empty registry of pipe subscriptions.
+func NewPipeSubscriptions() *PipeSubscriptions {
+	return &PipeSubscriptions{
+		pipes: map[string]*pipeSubscribers{},
+	}
+}
+
+func (p *PipeSubscriptions) getPipe(name string) *pipeSubscribers {
+	s, exists := p.pipes[name]
+	if !exists {
+		s = &pipeSubscribers{
+			subs:    map[string]*pipeSubscriber{},
+			changed: make(chan struct{}),
+		}
+		p.pipes[name] = s
+	}
+	return s
+}
+
+func (s *pipeSubscribers) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// Subscribe adds a subscriber to a subscription of a named pipe and returns the
+// transaction chan of the subscription. The buffer size of the chan is
+// determined by the first subscriber of a subscription.
+func (p *PipeSubscriptions) Subscribe(name, subscription string, buffer int) <-chan message.Transaction {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	s := p.getPipe(name)
+	sub, exists := s.subs[subscription]
+	if !exists {
+		sub = &pipeSubscriber{
+			transactions: make(chan message.Transaction, buffer),
+			closed:       make(chan struct{}),
+		}
+		s.subs[subscription] = sub
+		s.notify()
+	}
+	sub.refs++
+	return sub.transactions
+}
+
+// Unsubscribe removes a subscriber from a subscription of a named pipe, and
+// once a subscription has no subscribers it is removed. Transactions remaining
+// within the buffer of a removed subscription are rejected.
+func (p *PipeSubscriptions) Unsubscribe(name, subscription string) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	s := p.getPipe(name)
+	sub, exists := s.subs[subscription]
+	if !exists {
+		return
+	}
+	if sub.refs--; sub.refs > 0 {
+		return
+	}
+	close(sub.closed)
+	delete(s.subs, subscription)
+	s.notify()
+
+	var remaining []message.Transaction
+drainLoop:
+	for {
+		select {
+		case t := <-sub.transactions:
+			remaining = append(remaining, t)
+		default:
+			break drainLoop
+		}
+	}
+	if len(remaining) > 0 {
+		go func() {
+			for _, t := range remaining {
+				_ = t.Ack(context.Background(), component.ErrTypeClosed)
+			}
+		}()
+	}
+}
+
+// Get returns the current subscriptions of a named pipe along with a chan that
+// is closed the next time that the subscriptions change.
+func (p *PipeSubscriptions) Get(name string) ([]PipeSubscription, <-chan struct{}) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	s := p.getPipe(name)
+	subs := make([]PipeSubscription, 0, len(s.subs))
+	for _, sub := range s.subs {
+		subs = append(subs, PipeSubscription{
+			Transactions: sub.transactions,
+			Closed:       sub.closed,
+		})
+	}
+	return subs, s.changed
+}
@@ -0,0 +1,91 @@
+// Package bytesize parses and formats human readable quantities of bytes such
+// as `512KB` or `10MiB`.
+package bytesize
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var units = map[string]float64{
+	"":  1,
+	"k": 1e3,
+	"m": 1e6,
+	"g": 1e9,
+	"t": 1e12,
+	"p": 1e15,
+	"e": 1e18,
+
+	"ki": 1 << 10,
+	"mi": 1 << 20,
+	"gi": 1 << 30,
+	"ti": 1 << 40,
+	"pi": 1 << 50,
+	"ei": 1 << 60,
+}
+
+// Pattern is a regular expression that matches strings accepted by Parse.
+const Pattern = `(?i)^\s*[0-9]+(\.[0-9]+)?\s*([kmgtpe]i?)?b?\s*$`
+
+// Parse a human readable quantity of bytes. Units are case insensitive, where
+// SI units (KB, MB, GB, etc) are powers of 1000 and IEC units (KiB, MiB, GiB,
+// etc) are powers of 1024. A number without a unit is a quantity of bytes.
+func Parse(str string) (int64, error) {
+	s := strings.ToLower(strings.TrimSpace(str))
+
+	numEnd := 0
+	for numEnd < len(s) && (s[numEnd] == '.' || (s[numEnd] >= '0' && s[numEnd] <= '9')) {
+		numEnd++
+	}
+	if numEnd == 0 {
+		return 0, fmt.Errorf("byte size '%v' must begin with a number", str)
+	}
+
+	num, err := strconv.ParseFloat(s[:numEnd], 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse byte size '%v': %w", str, err)
+	}
+
+	unit := strings.TrimSuffix(strings.TrimSpace(s[numEnd:]), "b")
+	multiplier, exists := units[unit]
+	if !exists {
+		return 0, fmt.Errorf("byte size '%v' has an unrecognised unit", str)
+	}
+
+	bytes := num * multiplier
+	if bytes >= math.MaxInt64 {
+		return 0, fmt.Errorf("byte size '%v' is too large", str)
+	}
+	return int64(bytes), nil
+}
+
+var (
+	siSuffixes  = []string{"B", "KB", "MB", "GB", "TB", "PB", "EB"}
+	iecSuffixes = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+)
+
+// Format a quantity of bytes in the largest unit that it is at least one of,
+// using IEC units (KiB, MiB, etc) unless si is true, in which case SI units
+// (KB, MB, etc) are used. The number is rounded to at most precision decimal
+// places, and the result can be parsed again with Parse.
+func Format(bytes float64, si bool, precision int) string {
+	base, suffixes := float64(1024), iecSuffixes
+	if si {
+		base, suffixes = 1000, siSuffixes
+	}
+
+	scale := math.Pow(10, float64(precision))
+	round := func(f float64) float64 {
+		return math.Round(f*scale) / scale
+	}
+
+	// Rounding is checked at each step so that values just below a unit, such
+	// as 1023.999KiB, are formatted as the next unit up.
+	i := 0
+	for ; i < len(suffixes)-1 && math.Abs(round(bytes)) >= base; i++ {
+		bytes /= base
+	}
+	return strconv.FormatFloat(round(bytes), 'f', -1, 64) + suffixes[i]
+}
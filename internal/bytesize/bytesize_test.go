@@ -0,0 +1,55 @@
+package bytesize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for input, exp := range map[string]int64{
+		"10MiB":  10 * 1024 * 1024,
+		"512kb":  512 * 1000,
+		"1024":   1024,
+		"1.5 GB": 1500 * 1000 * 1000,
+		"20b":    20,
+		" 2Ki ":  2048,
+	} {
+		v, err := Parse(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, exp, v, input)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{"", "MiB", "10 mebibytes", "10XB", "1.2.3KB", "100EiB"} {
+		_, err := Parse(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		bytes     float64
+		si        bool
+		precision int
+		exp       string
+	}{
+		{bytes: 0, exp: "0B"},
+		{bytes: 1000, exp: "1000B"},
+		{bytes: 1000, si: true, exp: "1KB"},
+		{bytes: 10 * 1024 * 1024, exp: "10MiB"},
+		{bytes: 1536, precision: 2, exp: "1.5KiB"},
+		{bytes: 1234567, si: true, precision: 2, exp: "1.23MB"},
+		{bytes: 1234567, si: true, precision: 0, exp: "1MB"},
+		{bytes: 1048575, precision: 2, exp: "1MiB"},
+		{bytes: -2048, exp: "-2KiB"},
+		{bytes: 1 << 62, exp: "4EiB"},
+		{bytes: 1 << 70, exp: "1024EiB"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.exp, Format(test.bytes, test.si, test.precision), test)
+	}
+}
@@ -0,0 +1,502 @@
+// Package cbor implements encoding and decoding of CBOR (RFC 8949) documents
+// to and from the generic structured values used throughout Benthos.
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	majorUint   = 0
+	majorNegInt = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorArray  = 4
+	majorMap    = 5
+	majorTag    = 6
+	majorSimple = 7
+
+	// The additional info value signalling an indefinite length item, or the
+	// break code when combined with the simple major type.
+	infoIndefinite = 31
+
+	tagDateTimeString = 0
+	tagEpochDateTime  = 1
+
+	maxDepth = 1000
+)
+
+var errUnexpectedEnd = errors.New("unexpected end of data")
+
+// Unmarshal decodes a single CBOR data item into a structured value, where
+// maps are returned as map[string]any, arrays as []any, integers as int64 (or
+// uint64 when too large), floats as float64, text strings as string and byte
+// strings as []byte.
+//
+// Map keys that are not text strings are converted into their string
+// representations. Date/time tags are decoded as time.Time values and all
+// other tags are ignored, yielding their content.
+func Unmarshal(data []byte) (any, error) {
+	d := &decoder{data: data}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.off != len(d.data) {
+		return nil, fmt.Errorf("unexpected %v bytes of data after the end of the item", len(d.data)-d.off)
+	}
+	return v, nil
+}
+
+type decoder struct {
+	data []byte
+	off  int
+}
+
+func (d *decoder) byte() (byte, error) {
+	if d.off >= len(d.data) {
+		return 0, errUnexpectedEnd
+	}
+	b := d.data[d.off]
+	d.off++
+	return b, nil
+}
+
+func (d *decoder) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, errUnexpectedEnd
+	}
+	b := d.data[d.off : d.off+int(n)]
+	d.off += int(n)
+	return b, nil
+}
+
+// argument reads the argument of an item following its initial byte.
+func (d *decoder) argument(info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		b, err := d.byte()
+		return uint64(b), err
+	case info == 25:
+		b, err := d.bytes(2)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case info == 26:
+		b, err := d.bytes(4)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	case info == 27:
+		b, err := d.bytes(8)
+		if err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(b), nil
+	}
+	return 0, fmt.Errorf("invalid additional information %v at offset %v", info, d.off-1)
+}
+
+// isBreak consumes the break code if it's next.
+func (d *decoder) isBreak() (bool, error) {
+	if d.off >= len(d.data) {
+		return false, errUnexpectedEnd
+	}
+	if d.data[d.off] == 0xff {
+		d.off++
+		return true, nil
+	}
+	return false, nil
+}
+
+func (d *decoder) decode(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errors.New("maximum nesting depth exceeded")
+	}
+
+	initial, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	major, info := initial>>5, initial&0x1f
+
+	if info == infoIndefinite {
+		switch major {
+		case majorBytes, majorText:
+			return d.decodeIndefiniteString(major)
+		case majorArray:
+			arr := []any{}
+			for {
+				if brk, err := d.isBreak(); err != nil {
+					return nil, err
+				} else if brk {
+					return arr, nil
+				}
+				v, err := d.decode(depth + 1)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, v)
+			}
+		case majorMap:
+			obj := map[string]any{}
+			for {
+				if brk, err := d.isBreak(); err != nil {
+					return nil, err
+				} else if brk {
+					return obj, nil
+				}
+				if err := d.decodeMapEntry(obj, depth); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return nil, fmt.Errorf("unexpected indefinite length item at offset %v", d.off-1)
+	}
+
+	if major == majorSimple {
+		return d.decodeSimple(info)
+	}
+
+	arg, err := d.argument(info)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case majorUint:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+	case majorNegInt:
+		if arg > math.MaxInt64 {
+			return nil, fmt.Errorf("negative integer at offset %v overflows int64", d.off)
+		}
+		return -1 - int64(arg), nil
+	case majorBytes:
+		b, err := d.bytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, b...), nil
+	case majorText:
+		b, err := d.bytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(b) {
+			return nil, fmt.Errorf("invalid UTF-8 text string at offset %v", d.off-len(b))
+		}
+		return string(b), nil
+	case majorArray:
+		// Each element takes at least one byte, which protects against huge
+		// allocations from malicious lengths.
+		if arg > uint64(len(d.data)-d.off) {
+			return nil, errUnexpectedEnd
+		}
+		arr := make([]any, 0, int(arg))
+		for i := uint64(0); i < arg; i++ {
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+	case majorMap:
+		if arg > uint64(len(d.data)-d.off) {
+			return nil, errUnexpectedEnd
+		}
+		obj := make(map[string]any, int(arg))
+		for i := uint64(0); i < arg; i++ {
+			if err := d.decodeMapEntry(obj, depth); err != nil {
+				return nil, err
+			}
+		}
+		return obj, nil
+	}
+
+	// Tags
+	v, err := d.decode(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	switch arg {
+	case tagDateTimeString:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected text string content for date/time tag, got %T", v)
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse date/time tag: %w", err)
+		}
+		return t, nil
+	case tagEpochDateTime:
+		switch n := v.(type) {
+		case int64:
+			return time.Unix(n, 0).UTC(), nil
+		case float64:
+			sec, frac := math.Modf(n)
+			return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+		}
+		return nil, fmt.Errorf("expected numeric content for epoch date/time tag, got %T", v)
+	}
+	return v, nil
+}
+
+func (d *decoder) decodeIndefiniteString(major byte) (any, error) {
+	var buf bytes.Buffer
+	for {
+		if brk, err := d.isBreak(); err != nil {
+			return nil, err
+		} else if brk {
+			break
+		}
+		initial, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		if initial>>5 != major || initial&0x1f == infoIndefinite {
+			return nil, fmt.Errorf("invalid chunk of indefinite length string at offset %v", d.off-1)
+		}
+		n, err := d.argument(initial & 0x1f)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.bytes(n)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	if major == majorBytes {
+		return append([]byte{}, buf.Bytes()...), nil
+	}
+	if !utf8.Valid(buf.Bytes()) {
+		return nil, errors.New("invalid UTF-8 text string")
+	}
+	return buf.String(), nil
+}
+
+func (d *decoder) decodeMapEntry(obj map[string]any, depth int) error {
+	k, err := d.decode(depth + 1)
+	if err != nil {
+		return err
+	}
+	v, err := d.decode(depth + 1)
+	if err != nil {
+		return err
+	}
+	switch t := k.(type) {
+	case string:
+		obj[t] = v
+	case []byte:
+		obj[string(t)] = v
+	default:
+		obj[fmt.Sprintf("%v", t)] = v
+	}
+	return nil
+}
+
+func (d *decoder) decodeSimple(info byte) (any, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		b, err := d.bytes(2)
+		if err != nil {
+			return nil, err
+		}
+		return halfToFloat64(binary.BigEndian.Uint16(b)), nil
+	case 26:
+		b, err := d.bytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case 27:
+		b, err := d.bytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	}
+	return nil, fmt.Errorf("unsupported simple value %v at offset %v", info, d.off-1)
+}
+
+func halfToFloat64(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+//------------------------------------------------------------------------------
+
+// Marshal encodes a structured value as a CBOR data item. Map keys are
+// written in the bytewise lexicographic order of their encodings, following
+// the core deterministic encoding requirements of RFC 8949, and timestamps
+// are written as tagged RFC 3339 strings.
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, v, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeHead(buf *bytes.Buffer, major byte, arg uint64) {
+	major <<= 5
+	switch {
+	case arg < 24:
+		buf.WriteByte(major | byte(arg))
+	case arg <= math.MaxUint8:
+		buf.WriteByte(major | 24)
+		buf.WriteByte(byte(arg))
+	case arg <= math.MaxUint16:
+		buf.WriteByte(major | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(arg)))
+	case arg <= math.MaxUint32:
+		buf.WriteByte(major | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(arg)))
+	default:
+		buf.WriteByte(major | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, arg))
+	}
+}
+
+func writeInt(buf *bytes.Buffer, i int64) {
+	if i < 0 {
+		writeHead(buf, majorNegInt, uint64(-1-i))
+		return
+	}
+	writeHead(buf, majorUint, uint64(i))
+}
+
+func writeFloat(buf *bytes.Buffer, f float64) {
+	buf.WriteByte(majorSimple<<5 | 27)
+	buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+}
+
+func encode(buf *bytes.Buffer, v any, depth int) error {
+	if depth > maxDepth {
+		return errors.New("maximum nesting depth exceeded")
+	}
+
+	switch t := v.(type) {
+	case nil:
+		buf.WriteByte(majorSimple<<5 | 22)
+	case bool:
+		if t {
+			buf.WriteByte(majorSimple<<5 | 21)
+		} else {
+			buf.WriteByte(majorSimple<<5 | 20)
+		}
+	case int:
+		writeInt(buf, int64(t))
+	case int8:
+		writeInt(buf, int64(t))
+	case int16:
+		writeInt(buf, int64(t))
+	case int32:
+		writeInt(buf, int64(t))
+	case int64:
+		writeInt(buf, t)
+	case uint:
+		writeHead(buf, majorUint, uint64(t))
+	case uint8:
+		writeHead(buf, majorUint, uint64(t))
+	case uint16:
+		writeHead(buf, majorUint, uint64(t))
+	case uint32:
+		writeHead(buf, majorUint, uint64(t))
+	case uint64:
+		writeHead(buf, majorUint, t)
+	case float32:
+		writeFloat(buf, float64(t))
+	case float64:
+		writeFloat(buf, t)
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			writeInt(buf, i)
+		} else if f, err := t.Float64(); err == nil {
+			writeFloat(buf, f)
+		} else {
+			return fmt.Errorf("invalid number: %v", t)
+		}
+	case string:
+		writeHead(buf, majorText, uint64(len(t)))
+		buf.WriteString(t)
+	case []byte:
+		writeHead(buf, majorBytes, uint64(len(t)))
+		buf.Write(t)
+	case time.Time:
+		writeHead(buf, majorTag, tagDateTimeString)
+		s := t.Format(time.RFC3339Nano)
+		writeHead(buf, majorText, uint64(len(s)))
+		buf.WriteString(s)
+	case []any:
+		writeHead(buf, majorArray, uint64(len(t)))
+		for _, e := range t {
+			if err := encode(buf, e, depth+1); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		keys := make([][]byte, 0, len(t))
+		values := make(map[string]any, len(t))
+		for k, e := range t {
+			var kBuf bytes.Buffer
+			writeHead(&kBuf, majorText, uint64(len(k)))
+			kBuf.WriteString(k)
+			keys = append(keys, kBuf.Bytes())
+			values[string(kBuf.Bytes())] = e
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return bytes.Compare(keys[i], keys[j]) < 0
+		})
+		writeHead(buf, majorMap, uint64(len(t)))
+		for _, k := range keys {
+			buf.Write(k)
+			if err := encode(buf, values[string(k)], depth+1); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported type %T", v)
+	}
+	return nil
+}
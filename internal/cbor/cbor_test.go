@@ -0,0 +1,167 @@
+package cbor
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshal(t *testing.T) {
+	// Vectors taken from Appendix A of RFC 8949.
+	tests := map[string]any{
+		"00":                         int64(0),
+		"17":                         int64(23),
+		"1818":                       int64(24),
+		"1903e8":                     int64(1000),
+		"1b000000e8d4a51000":         int64(1000000000000),
+		"1bffffffffffffffff":         uint64(18446744073709551615),
+		"20":                         int64(-1),
+		"3903e7":                     int64(-1000),
+		"f90000":                     float64(0),
+		"f93c00":                     float64(1),
+		"f97bff":                     float64(65504),
+		"f90001":                     5.960464477539063e-8,
+		"f9c400":                     float64(-4),
+		"fa47c35000":                 float64(100000),
+		"fb3ff199999999999a":         1.1,
+		"f4":                         false,
+		"f5":                         true,
+		"f6":                         nil,
+		"f7":                         nil,
+		"40":                         []byte{},
+		"4401020304":                 []byte{1, 2, 3, 4},
+		"60":                         "",
+		"6449455446":                 "IETF",
+		"62c3bc":                     "ü",
+		"80":                         []any{},
+		"83010203":                   []any{int64(1), int64(2), int64(3)},
+		"8301820203820405":           []any{int64(1), []any{int64(2), int64(3)}, []any{int64(4), int64(5)}},
+		"a0":                         map[string]any{},
+		"a201020304":                 map[string]any{"1": int64(2), "3": int64(4)},
+		"a26161016162820203":         map[string]any{"a": int64(1), "b": []any{int64(2), int64(3)}},
+		"5f42010243030405ff":         []byte{1, 2, 3, 4, 5},
+		"7f657374726561646d696e67ff": "streaming",
+		"9f018202039f0405ffff":       []any{int64(1), []any{int64(2), int64(3)}, []any{int64(4), int64(5)}},
+		"bf61610161629f0203ffff":     map[string]any{"a": int64(1), "b": []any{int64(2), int64(3)}},
+		"d74401020304":               []byte{1, 2, 3, 4},
+		"c074323031332d30332d32315432303a30343a30305a": time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC),
+		"c11a514b67b0":         time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC),
+		"c1fb41d452d9ec200000": time.Date(2013, 3, 21, 20, 4, 0, 500000000, time.UTC),
+	}
+
+	for input, exp := range tests {
+		input, exp := input, exp
+		t.Run(input, func(t *testing.T) {
+			b, err := hex.DecodeString(input)
+			require.NoError(t, err)
+
+			v, err := Unmarshal(b)
+			require.NoError(t, err)
+			if et, ok := exp.(time.Time); ok {
+				require.IsType(t, time.Time{}, v)
+				assert.True(t, et.Equal(v.(time.Time)), "%v != %v", et, v)
+			} else {
+				assert.Equal(t, exp, v)
+			}
+		})
+	}
+}
+
+func TestUnmarshalSpecialFloats(t *testing.T) {
+	v, err := Unmarshal([]byte{0xf9, 0x7c, 0x00})
+	require.NoError(t, err)
+	assert.Equal(t, math.Inf(1), v)
+
+	v, err = Unmarshal([]byte{0xf9, 0x7e, 0x00})
+	require.NoError(t, err)
+	assert.True(t, math.IsNaN(v.(float64)))
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := map[string]string{
+		"":                   "unexpected end of data",
+		"19":                 "unexpected end of data",
+		"62c3":               "unexpected end of data",
+		"62c328":             "invalid UTF-8 text string at offset 1",
+		"0001":               "unexpected 1 bytes of data after the end of the item",
+		"1c":                 "invalid additional information 28 at offset 0",
+		"9f01":               "unexpected end of data",
+		"5f6161ff":           "invalid chunk of indefinite length string at offset 1",
+		"3bffffffffffffffff": "negative integer at offset 9 overflows int64",
+		"c001":               "expected text string content for date/time tag, got int64",
+		"9b00000000ffffffff": "unexpected end of data",
+		"1f":                 "unexpected indefinite length item at offset 0",
+		"f8ff":               "unsupported simple value 24 at offset 0",
+	}
+
+	for input, exp := range tests {
+		b, err := hex.DecodeString(input)
+		require.NoError(t, err, input)
+
+		_, err = Unmarshal(b)
+		require.Error(t, err, input)
+		assert.EqualError(t, err, exp, input)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		input any
+		exp   string
+	}{
+		{input: nil, exp: "f6"},
+		{input: true, exp: "f5"},
+		{input: int64(0), exp: "00"},
+		{input: 24, exp: "1818"},
+		{input: int64(-1000), exp: "3903e7"},
+		{input: uint64(18446744073709551615), exp: "1bffffffffffffffff"},
+		{input: int64(1000000000000), exp: "1b000000e8d4a51000"},
+		{input: 1.1, exp: "fb3ff199999999999a"},
+		{input: json.Number("100"), exp: "1864"},
+		{input: json.Number("1.1"), exp: "fb3ff199999999999a"},
+		{input: "IETF", exp: "6449455446"},
+		{input: []byte{1, 2, 3, 4}, exp: "4401020304"},
+		{input: []any{int64(1), []any{2, 3}}, exp: "8201820203"},
+		{
+			input: map[string]any{"bb": 2, "a": 1, "c": 3},
+			exp:   "a361610161630362626202",
+		},
+		{
+			input: time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC),
+			exp:   "c074323031332d30332d32315432303a30343a30305a",
+		},
+	}
+
+	for _, test := range tests {
+		b, err := Marshal(test.input)
+		require.NoError(t, err, test.input)
+		assert.Equal(t, test.exp, hex.EncodeToString(b), test.input)
+	}
+
+	_, err := Marshal(struct{}{})
+	require.Error(t, err)
+}
+
+func TestRoundTrip(t *testing.T) {
+	input := map[string]any{
+		"id":      int64(-42),
+		"name":    "sensor",
+		"active":  true,
+		"reading": 21.5,
+		"raw":     []byte("hello"),
+		"tags":    []any{"a", "b", nil},
+		"nested":  map[string]any{"big": uint64(math.MaxUint64)},
+	}
+
+	b, err := Marshal(input)
+	require.NoError(t, err)
+
+	v, err := Unmarshal(b)
+	require.NoError(t, err)
+	assert.Equal(t, input, v)
+}
@@ -49,6 +49,36 @@ func lintFile(path string, skipEnvVarCheck bool, spec docs.FieldSpecs, lConf doc
 	return
 }
 
+func fixFile(path string, spec docs.FieldSpecs, prov docs.Provider) error {
+	info, err := ifs.OS().Stat(path)
+	if err != nil {
+		return err
+	}
+
+	rawBytes, err := ifs.ReadFile(ifs.OS(), path)
+	if err != nil {
+		return err
+	}
+
+	node, err := docs.UnmarshalYAML(rawBytes)
+	if err != nil {
+		return err
+	}
+
+	changed, err := spec.MigrateRenamedYAML(node, prov)
+	if err != nil {
+		return fmt.Errorf("failed to migrate renamed fields: %w", err)
+	}
+	if !changed {
+		return nil
+	}
+
+	if rawBytes, err = docs.MarshalYAML(*node); err != nil {
+		return err
+	}
+	return ifs.WriteFile(ifs.OS(), path, rawBytes, info.Mode().Perm())
+}
+
 func lintMDSnippets(path string, spec docs.FieldSpecs, lConf docs.LintConfig) (pathLints []pathLint) {
 	rawBytes, err := ifs.ReadFile(ifs.OS(), path)
 	if err != nil {
@@ -160,6 +190,11 @@ files with the .yaml or .yml extension.`)[1:],
 				Value: false,
 				Usage: "Print linting errors when components do not have labels.",
 			},
+			&cli.BoolFlag{
+				Name:  "fix",
+				Value: false,
+				Usage: "Rewrite config files in place in order to migrate fields that have been renamed to their current names.",
+			},
 			&cli.BoolFlag{
 				Name:  "skip-env-var-check",
 				Value: false,
@@ -192,6 +227,7 @@ func LintAction(c *cli.Context, opts *common.CLIOpts, stderr io.Writer) int {
 	lConf.RejectDeprecated = c.Bool("deprecated")
 	lConf.RequireLabels = c.Bool("labels")
 	skipEnvVarCheck := c.Bool("skip-env-var-check")
+	fix := c.Bool("fix")
 
 	spec := opts.MainConfigSpecCtor()
 
@@ -214,7 +250,15 @@ func LintAction(c *cli.Context, opts *common.CLIOpts, stderr io.Writer) int {
 				if path.Ext(target) == ".md" {
 					lints = lintMDSnippets(target, spec, lConf)
 				} else {
-					lints = lintFile(target, skipEnvVarCheck, spec, lConf)
+					if fix {
+						if err := fixFile(target, spec, lConf.DocsProvider); err != nil {
+							lints = append(lints, pathLint{
+								source: target,
+								lint:   docs.NewLintError(1, docs.LintFailedRead, err),
+							})
+						}
+					}
+					lints = append(lints, lintFile(target, skipEnvVarCheck, spec, lConf)...)
 				}
 				if len(lints) > 0 {
 					pathLintMut.Lock()
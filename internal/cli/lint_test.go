@@ -2,6 +2,7 @@ package cli_test
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -12,6 +13,7 @@ import (
 
 	icli "github.com/redpanda-data/benthos/v4/internal/cli"
 	"github.com/redpanda-data/benthos/v4/internal/cli/common"
+	"github.com/redpanda-data/benthos/v4/public/service"
 
 	_ "github.com/redpanda-data/benthos/v4/public/components/io"
 	_ "github.com/redpanda-data/benthos/v4/public/components/pure"
@@ -172,3 +174,47 @@ output:
 		})
 	}
 }
+
+func TestLintFix(t *testing.T) {
+	require.NoError(t, service.RegisterInput("lint_fix_test_input",
+		service.NewConfigSpec().Field(service.NewStringField("new_name").RenamedFrom("old_name")),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			return nil, errors.New("not implemented")
+		}))
+
+	tmpDir := t.TempDir()
+	tFile := func(name string) string {
+		return filepath.Join(tmpDir, name)
+	}
+
+	require.NoError(t, os.WriteFile(tFile("foo.yaml"), []byte(`
+input:
+  lint_fix_test_input:
+    old_name: hello
+output:
+  drop: {}
+`), 0o600))
+
+	code, outStr := executeLintSubcmd(t, []string{"benthos", "lint", "--fix", tFile("foo.yaml")})
+	assert.Equal(t, 0, code, outStr)
+
+	fixed, err := os.ReadFile(tFile("foo.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(fixed), "new_name: hello")
+	assert.NotContains(t, string(fixed), "old_name")
+
+	info, err := os.Stat(tFile("foo.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	require.NoError(t, os.WriteFile(tFile("bar.yaml"), []byte(`
+input:
+  nah: nope
+output:
+  drop: {}
+`), 0o600))
+
+	code, outStr = executeLintSubcmd(t, []string{"benthos", "lint", "--fix", tFile("bar.yaml")})
+	assert.Equal(t, 1, code)
+	assert.Contains(t, outStr, "failed to migrate renamed fields")
+}
@@ -263,6 +263,7 @@ variables have been resolved:
 						sanitConf := docs.NewSanitiseConfig(bundle.GlobalEnvironment)
 						sanitConf.RemoveTypeField = true
 						sanitConf.ScrubSecrets = true
+						sanitConf.ResolveEnvDefaults = true
 						err = opts.MainConfigSpecCtor().SanitiseYAML(&node, sanitConf)
 					}
 					if err == nil {
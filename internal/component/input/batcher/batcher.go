@@ -21,13 +21,27 @@ type Impl struct {
 	child   input.Streamed
 	batcher *policy.Batcher
 
+	maxByteSize int
+
 	messagesOut chan message.Transaction
 
 	shutSig *shutdown.Signaller
 }
 
+// OptFunc is an optional setting for a Batcher.
+type OptFunc func(*Impl)
+
+// OptMaxByteSize sets a maximum size in bytes of each batch, where a batch is
+// flushed early when the next message consumed would cause it to exceed the
+// limit. A message that exceeds the limit by itself is batched alone.
+func OptMaxByteSize(n int) OptFunc {
+	return func(i *Impl) {
+		i.maxByteSize = n
+	}
+}
+
 // New creates a new Batcher around an input.
-func New(batcher *policy.Batcher, child input.Streamed, log log.Modular) input.Streamed {
+func New(batcher *policy.Batcher, child input.Streamed, log log.Modular, opts ...OptFunc) input.Streamed {
 	b := Impl{
 		log:         log,
 		child:       child,
@@ -35,6 +49,9 @@ func New(batcher *policy.Batcher, child input.Streamed, log log.Modular) input.S
 		messagesOut: make(chan message.Transaction),
 		shutSig:     shutdown.NewSignaller(),
 	}
+	for _, opt := range opts {
+		opt(&b)
+	}
 	go b.loop()
 	return &b
 }
@@ -62,8 +79,10 @@ func (m *Impl) loop() {
 
 	pendingTrans := []*transaction.Tracked{}
 	pendingAcks := sync.WaitGroup{}
+	pendingBytes := 0
 
 	flushBatchFn := func() {
+		pendingBytes = 0
 		sendMsg := m.batcher.Flush(closeNowCtx)
 		if sendMsg == nil {
 			return
@@ -97,6 +116,11 @@ func (m *Impl) loop() {
 		pendingTrans = nil
 	}
 
+	// A partial batch is flushed as soon as we're instructed to stop consuming
+	// rather than waiting for the child input to close, which might not happen
+	// before the input is forced to close.
+	softStopChan := m.shutSig.SoftStopChan()
+
 	defer func() {
 		// Final flush of remaining documents.
 		m.log.Debug("Flushing remaining messages of batch.")
@@ -130,6 +154,19 @@ func (m *Impl) loop() {
 				return
 			}
 
+			if m.maxByteSize > 0 {
+				tranBytes := 0
+				_ = tran.Payload.Iter(func(i int, p *message.Part) error {
+					tranBytes += len(p.AsBytes())
+					return nil
+				})
+				if pendingBytes > 0 && pendingBytes+tranBytes > m.maxByteSize {
+					m.log.Trace("Batching early based on max_byte_size")
+					flushBatchFn()
+				}
+				pendingBytes += tranBytes
+			}
+
 			trackedTran := transaction.NewTracked(tran.Payload, tran.Ack)
 			_ = trackedTran.Message().Iter(func(i int, p *message.Part) error {
 				if m.batcher.Add(p) {
@@ -139,8 +176,13 @@ func (m *Impl) loop() {
 			})
 			pendingTrans = append(pendingTrans, trackedTran)
 		case <-nextTimedBatchChan:
-			flushBatch = true
+			// The timer might have been scheduled for a previous batch, in
+			// which case the minimum count may not yet be met.
+			flushBatch = m.batcher.MinCountReached()
 			nextTimedBatchChan = nil
+		case <-softStopChan:
+			softStopChan = nil
+			flushBatch = true
 		case <-m.shutSig.HardStopChan():
 			return
 		}
@@ -362,3 +362,103 @@ func TestBatcherFinalFlush(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// lingeringInput remains open after being instructed to stop consuming.
+type lingeringInput struct {
+	*mock.Input
+}
+
+func (l lingeringInput) TriggerStopConsuming() {}
+
+func TestBatcherSoftStopFlush(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	mockInput := lingeringInput{Input: &mock.Input{
+		TChan: make(chan message.Transaction),
+	}}
+
+	batchConf := batchconfig.NewConfig()
+	batchConf.Count = 10
+
+	batchPol, err := policy.New(batchConf, mock.NewManager())
+	require.NoError(t, err)
+
+	b := batcher.New(batchPol, mockInput, log.Noop())
+
+	resChan := make(chan error, 1)
+	select {
+	case mockInput.TChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("foo1")}), resChan):
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	b.TriggerStopConsuming()
+
+	var tran message.Transaction
+	select {
+	case tran = <-b.TransactionChan():
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+	require.Equal(t, 1, tran.Payload.Len())
+	assert.Equal(t, "foo1", string(tran.Payload.Get(0).AsBytes()))
+
+	require.NoError(t, tran.Ack(tCtx, nil))
+	select {
+	case err := <-resChan:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	b.TriggerCloseNow()
+	require.NoError(t, b.WaitForClose(tCtx))
+}
+
+func TestBatcherMaxByteSize(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	mockInput := &mock.Input{
+		TChan: make(chan message.Transaction),
+	}
+
+	batchConf := batchconfig.NewConfig()
+	batchConf.Count = 10
+
+	batchPol, err := policy.New(batchConf, mock.NewManager())
+	require.NoError(t, err)
+
+	b := batcher.New(batchPol, mockInput, log.Noop(), batcher.OptMaxByteSize(10))
+
+	go func() {
+		for _, p := range []string{"aaaa", "bbbb", "cccc", "dddddddddddd", "ee"} {
+			select {
+			case mockInput.TChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte(p)}), make(chan error, 1)):
+			case <-tCtx.Done():
+				return
+			}
+		}
+		mockInput.TriggerStopConsuming()
+	}()
+
+	var batches [][]string
+	for tran := range b.TransactionChan() {
+		var parts []string
+		_ = tran.Payload.Iter(func(i int, p *message.Part) error {
+			parts = append(parts, string(p.AsBytes()))
+			return nil
+		})
+		batches = append(batches, parts)
+		require.NoError(t, tran.Ack(tCtx, nil))
+	}
+
+	assert.Equal(t, [][]string{
+		{"aaaa", "bbbb"},
+		{"cccc"},
+		{"dddddddddddd"},
+		{"ee"},
+	}, batches)
+	require.NoError(t, b.WaitForClose(tCtx))
+}
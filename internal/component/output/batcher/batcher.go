@@ -115,7 +115,9 @@ func (m *Impl) loop() {
 				pendingTrans = append(pendingTrans, trackedTran)
 			}
 		case <-nextTimedBatchChan:
-			flushBatch = true
+			// The timer might have been scheduled for a previous batch, in
+			// which case the minimum count may not yet be met.
+			flushBatch = m.batcher.MinCountReached()
 			nextTimedBatchChan = nil
 		case <-m.shutSig.SoftStopChan():
 			flushBatch = true
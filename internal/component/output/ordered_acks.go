@@ -0,0 +1,131 @@
+package output
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Jeffail/shutdown"
+
+	"github.com/redpanda-data/benthos/v4/internal/component"
+	"github.com/redpanda-data/benthos/v4/internal/message"
+)
+
+type pendingAck struct {
+	tran message.Transaction
+	done bool
+	err  error
+}
+
+type orderedAcksOutput struct {
+	out Streamed
+
+	inChan  <-chan message.Transaction
+	outChan chan message.Transaction
+
+	pendingMut sync.Mutex
+	pending    []*pendingAck
+
+	shutSig *shutdown.Signaller
+}
+
+// WithOrderedAcks wraps an output such that transactions are still written
+// concurrently up to the max in flight of the wrapped output, but are
+// acknowledged upstream in the order that they were received. A transaction
+// that completes early is held until all transactions received before it have
+// also completed.
+func WithOrderedAcks(out Streamed) Streamed {
+	return &orderedAcksOutput{
+		out:     out,
+		outChan: make(chan message.Transaction),
+		shutSig: shutdown.NewSignaller(),
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// resolve marks a pending transaction as complete and then acknowledges all
+// complete transactions at the head of the queue. Acknowledgements are made
+// whilst holding the lock in order to guarantee their ordering.
+func (o *orderedAcksOutput) resolve(ctx context.Context, p *pendingAck, err error) {
+	o.pendingMut.Lock()
+	defer o.pendingMut.Unlock()
+
+	p.done, p.err = true, err
+
+	var i int
+	for ; i < len(o.pending) && o.pending[i].done; i++ {
+		_ = o.pending[i].tran.Ack(ctx, o.pending[i].err)
+		o.pending[i] = nil
+	}
+	o.pending = o.pending[i:]
+}
+
+func (o *orderedAcksOutput) loop() {
+	ctx, done := o.shutSig.HardStopCtx(context.Background())
+	defer done()
+
+	defer func() {
+		close(o.outChan)
+		o.out.TriggerCloseNow()
+		_ = o.out.WaitForClose(ctx)
+		o.shutSig.TriggerHasStopped()
+	}()
+
+	for {
+		var tran message.Transaction
+		var open bool
+		select {
+		case tran, open = <-o.inChan:
+			if !open {
+				return
+			}
+		case <-o.shutSig.SoftStopChan():
+			return
+		}
+
+		p := &pendingAck{tran: tran}
+		o.pendingMut.Lock()
+		o.pending = append(o.pending, p)
+		o.pendingMut.Unlock()
+
+		select {
+		case o.outChan <- message.NewTransactionFunc(tran.Payload, func(ctx context.Context, err error) error {
+			o.resolve(ctx, p, err)
+			return nil
+		}):
+		case <-o.shutSig.HardStopChan():
+			return
+		}
+	}
+}
+
+//------------------------------------------------------------------------------
+
+func (o *orderedAcksOutput) Consume(ts <-chan message.Transaction) error {
+	if o.inChan != nil {
+		return component.ErrAlreadyStarted
+	}
+	if err := o.out.Consume(o.outChan); err != nil {
+		return err
+	}
+	o.inChan = ts
+	go o.loop()
+	return nil
+}
+
+func (o *orderedAcksOutput) Connected() bool {
+	return o.out.Connected()
+}
+
+func (o *orderedAcksOutput) TriggerCloseNow() {
+	o.shutSig.TriggerHardStop()
+}
+
+func (o *orderedAcksOutput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-o.shutSig.HasStoppedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
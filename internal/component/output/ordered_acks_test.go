@@ -0,0 +1,106 @@
+package output
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/component"
+	"github.com/redpanda-data/benthos/v4/internal/message"
+)
+
+type mockBlockingWriter struct {
+	blockOn string
+	errorOn string
+	release chan struct{}
+}
+
+func (m *mockBlockingWriter) Connect(context.Context) error {
+	return nil
+}
+
+func (m *mockBlockingWriter) WriteBatch(ctx context.Context, msg message.Batch) error {
+	switch string(msg.Get(0).AsBytes()) {
+	case m.blockOn:
+		select {
+		case <-m.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	case m.errorOn:
+		return errors.New("test err")
+	}
+	return nil
+}
+
+func (m *mockBlockingWriter) Close(ctx context.Context) error {
+	return nil
+}
+
+func TestOrderedAcks(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	w := &mockBlockingWriter{blockOn: "a", errorOn: "c", release: make(chan struct{})}
+	aw, err := NewAsyncWriter("foo", 3, w, component.NoopObservability())
+	require.NoError(t, err)
+
+	out := WithOrderedAcks(aw)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, out.Consume(tChan))
+
+	var ackMut sync.Mutex
+	var acked []string
+	var ackErrs []error
+	ackedChan := make(chan struct{}, 3)
+
+	for _, c := range []string{"a", "b", "c"} {
+		c := c
+		select {
+		case tChan <- message.NewTransactionFunc(message.QuickBatch([][]byte{[]byte(c)}), func(ctx context.Context, err error) error {
+			ackMut.Lock()
+			acked = append(acked, c)
+			ackErrs = append(ackErrs, err)
+			ackMut.Unlock()
+			ackedChan <- struct{}{}
+			return nil
+		}):
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+	}
+
+	// Messages written after the blocked message must not be acknowledged
+	// until it completes.
+	select {
+	case <-ackedChan:
+		t.Fatal("unexpected ack before the first message completed")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	close(w.release)
+	for i := 0; i < 3; i++ {
+		select {
+		case <-ackedChan:
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+	}
+
+	ackMut.Lock()
+	assert.Equal(t, []string{"a", "b", "c"}, acked)
+	require.Len(t, ackErrs, 3)
+	assert.NoError(t, ackErrs[0])
+	assert.NoError(t, ackErrs[1])
+	assert.EqualError(t, ackErrs[2], "test err")
+	ackMut.Unlock()
+
+	close(tChan)
+	require.NoError(t, out.WaitForClose(ctx))
+}
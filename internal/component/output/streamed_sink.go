@@ -0,0 +1,80 @@
+package output
+
+import (
+	"context"
+	"sync"
+
+	"github.com/redpanda-data/benthos/v4/internal/component"
+	"github.com/redpanda-data/benthos/v4/internal/message"
+)
+
+// StreamedSink implements AsyncSink around a Streamed output by writing each
+// batch as a transaction and blocking until it is acknowledged. This allows
+// outputs that are only available as a Streamed to be wrapped by components
+// that operate on sinks, such as sink middleware.
+//
+// The Streamed output does not begin consuming until the first call to
+// Connect, and therefore a StreamedSink that is never connected can be
+// discarded without affecting the output.
+type StreamedSink struct {
+	out   Streamed
+	tChan chan message.Transaction
+
+	startOnce sync.Once
+	started   bool
+	startErr  error
+	closeOnce sync.Once
+}
+
+// NewStreamedSink creates an AsyncSink that writes to a Streamed output.
+func NewStreamedSink(out Streamed) *StreamedSink {
+	return &StreamedSink{
+		out:   out,
+		tChan: make(chan message.Transaction),
+	}
+}
+
+// Connect starts the Streamed output consuming transactions.
+func (s *StreamedSink) Connect(ctx context.Context) error {
+	s.startOnce.Do(func() {
+		if s.startErr = s.out.Consume(s.tChan); s.startErr == nil {
+			s.started = true
+		}
+	})
+	return s.startErr
+}
+
+// WriteBatch writes a batch to the Streamed output and blocks until it is
+// acknowledged.
+func (s *StreamedSink) WriteBatch(ctx context.Context, msg message.Batch) error {
+	resChan := make(chan error, 1)
+	select {
+	case s.tChan <- message.NewTransaction(msg, resChan):
+	case <-ctx.Done():
+		return component.ErrTypeClosed
+	}
+	select {
+	case err := <-resChan:
+		return err
+	case <-ctx.Done():
+		return component.ErrTypeClosed
+	}
+}
+
+// Close shuts down the Streamed output. Any transactions still held by the
+// output are abandoned, as all writes are expected to have returned by the time
+// that Close is called.
+func (s *StreamedSink) Close(ctx context.Context) error {
+	// Prevent the output from being started after it has been closed.
+	s.startOnce.Do(func() {
+		s.startErr = component.ErrTypeClosed
+	})
+	s.closeOnce.Do(func() {
+		close(s.tChan)
+	})
+	s.out.TriggerCloseNow()
+	if !s.started {
+		return nil
+	}
+	return s.out.WaitForClose(ctx)
+}
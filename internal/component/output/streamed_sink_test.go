@@ -0,0 +1,44 @@
+package output
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/component"
+	"github.com/redpanda-data/benthos/v4/internal/message"
+)
+
+func TestStreamedSink(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	w := &mockBlockingWriter{errorOn: "b"}
+	aw, err := NewAsyncWriter("foo", 1, w, component.NoopObservability())
+	require.NoError(t, err)
+
+	s := NewStreamedSink(aw)
+	require.NoError(t, s.Connect(ctx))
+	require.NoError(t, s.Connect(ctx))
+
+	require.NoError(t, s.WriteBatch(ctx, message.QuickBatch([][]byte{[]byte("a")})))
+	require.Error(t, s.WriteBatch(ctx, message.QuickBatch([][]byte{[]byte("b")})))
+
+	require.NoError(t, s.Close(ctx))
+	require.NoError(t, aw.WaitForClose(ctx))
+}
+
+func TestStreamedSinkCloseUnconnected(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	aw, err := NewAsyncWriter("foo", 1, &mockBlockingWriter{}, component.NoopObservability())
+	require.NoError(t, err)
+
+	s := NewStreamedSink(aw)
+	require.NoError(t, s.Close(ctx))
+	assert.ErrorIs(t, s.Connect(ctx), component.ErrTypeClosed)
+}
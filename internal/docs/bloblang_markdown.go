@@ -312,6 +312,7 @@ func BloblangMethodsMarkdown() ([]byte, error) {
 		query.MethodCategoryEncoding,
 		query.MethodCategoryJWT,
 		query.MethodCategoryGeoIP,
+		query.MethodCategoryNetwork,
 		query.MethodCategoryDeprecated,
 	} {
 		methods := methodCategory{
@@ -133,6 +133,11 @@ type SanitiseConfig struct {
 	ForExample       bool
 	Filter           FieldFilter
 	DocsProvider     Provider
+
+	// When enabled fields that are omitted from the config but have a default
+	// value provided by an environment variable are added, with a comment
+	// noting the variable the value was sourced from.
+	ResolveEnvDefaults bool
 }
 
 // NewSanitiseConfig creates a new sanitise config.
@@ -3,8 +3,11 @@ package docs
 import (
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 
+	"gopkg.in/yaml.v3"
+
 	"github.com/redpanda-data/benthos/v4/internal/value"
 	"github.com/redpanda-data/benthos/v4/public/bloblang"
 )
@@ -106,6 +109,10 @@ type FieldSpec struct {
 	// Default value of the field.
 	Default *any `json:"default,omitempty"`
 
+	// DefaultEnvVar is the name of an environment variable that, when set,
+	// provides the default value of the field in place of Default.
+	DefaultEnvVar string `json:"default_env_var,omitempty"`
+
 	// Interpolation indicates that the field supports interpolation
 	// functions.
 	Interpolated bool `json:"interpolated,omitempty"`
@@ -136,8 +143,22 @@ type FieldSpec struct {
 	// scrub sensitive information from field values when echoed.
 	Scrubber string `json:"scrubber,omitempty"`
 
-	omitWhenFn   func(field, parent any) (why string, shouldOmit bool)
-	customLintFn LintFunc
+	// FormerNames is an optional list of names that the field was previously
+	// known by, which are still accepted within configs.
+	FormerNames []string `json:"former_names,omitempty"`
+
+	// RequiredSiblings is an optional list of sibling fields that must also be
+	// set when this field is set.
+	RequiredSiblings []string `json:"requires,omitempty"`
+
+	// ConflictingSiblings is an optional list of sibling fields that must not
+	// be set when this field is set.
+	ConflictingSiblings []string `json:"conflicts_with,omitempty"`
+
+	omitWhenFn    func(field, parent any) (why string, shouldOmit bool)
+	customLintFn  LintFunc
+	customScrubFn func(v any) (any, error)
+	urlSchemes    []string
 }
 
 // IsInterpolated indicates that the field supports interpolation functions.
@@ -226,6 +247,37 @@ func (f FieldSpec) HasDefault(v any) FieldSpec {
 	return f
 }
 
+// HasDefaultFromEnv returns a new FieldSpec that takes its default value from
+// an environment variable when the field is omitted from a config. If the
+// variable is not set then the regular default value, if any, applies.
+func (f FieldSpec) HasDefaultFromEnv(name string) FieldSpec {
+	f.DefaultEnvVar = name
+	return f
+}
+
+// RenamedFrom returns a new FieldSpec that accepts a former name for the field
+// within configs, which is migrated to the current name when configs are
+// parsed, echoed or fixed.
+func (f FieldSpec) RenamedFrom(name string) FieldSpec {
+	f.FormerNames = append(append([]string{}, f.FormerNames...), name)
+	return f
+}
+
+// Requires returns a new FieldSpec that, when set within a config, requires
+// the named sibling fields to also be set, which is enforced when linting.
+func (f FieldSpec) Requires(names ...string) FieldSpec {
+	f.RequiredSiblings = append(append([]string{}, f.RequiredSiblings...), names...)
+	return f
+}
+
+// ConflictsWith returns a new FieldSpec that, when set within a config,
+// requires the named sibling fields to not be set, which is enforced when
+// linting.
+func (f FieldSpec) ConflictsWith(names ...string) FieldSpec {
+	f.ConflictingSiblings = append(append([]string{}, f.ConflictingSiblings...), names...)
+	return f
+}
+
 // AtVersion specifies the version at which this fields behaviour was last
 // modified.
 func (f FieldSpec) AtVersion(v string) FieldSpec {
@@ -305,6 +357,54 @@ func (f FieldSpec) LinterFunc(fn LintFunc) FieldSpec {
 	return f
 }
 
+// URLSchemes restricts the schemes of the URLs of a field, which is enforced by
+// the component that parses the field rather than the docs engine.
+func (f FieldSpec) URLSchemes(schemes ...string) FieldSpec {
+	f.urlSchemes = schemes
+	return f
+}
+
+// AllowedURLSchemes returns the schemes that the URLs of a field are restricted
+// to, or nil if any scheme is allowed.
+func (f FieldSpec) AllowedURLSchemes() []string {
+	return f.urlSchemes
+}
+
+// LinterFrom returns a new FieldSpec with the linting rules of another field
+// spec, replacing any linting rules of the field.
+func (f FieldSpec) LinterFrom(from FieldSpec) FieldSpec {
+	f.Linter = from.Linter
+	f.customLintFn = from.customLintFn
+	return f
+}
+
+// LinterBloblAppend adds a bloblang linting rule to a field in addition to any
+// existing linting rule of the field, where the lints of both are reported. The
+// Linter mapping of the field is preserved when one already exists, and
+// therefore only the existing rule is included in portable schemas.
+func (f FieldSpec) LinterBloblAppend(blobl string) FieldSpec {
+	prev := f.customLintFn
+	if prev == nil && f.Linter != "" {
+		prev = f.LinterBlobl(f.Linter).customLintFn
+	}
+	prevLinter := f.Linter
+
+	f = f.LinterBlobl(blobl)
+	if prev == nil || blobl == "" {
+		if blobl == "" {
+			f.Linter, f.customLintFn = prevLinter, prev
+		}
+		return f
+	}
+
+	next := f.customLintFn
+	f.Linter = prevLinter
+	f.customLintFn = func(ctx LintContext, line, col int, value any) []Lint {
+		return append(prev(ctx, line, col, value), next(ctx, line, col, value)...)
+	}
+	return f
+}
+
 func lintsFromAny(line int, v any) (lints []Lint) {
 	switch t := v.(type) {
 	case []any:
@@ -409,7 +509,19 @@ root = if !$options.exists(this.string()%v) {
 	return f
 }
 
+// ScrubberFunc sets a Go function to be used when scrubbing the value of the
+// field, which takes precedence over the Scrubber mapping. The mapping is
+// preserved, and should be set to a reasonable approximation of the function
+// as it is used in order to determine whether the field requires scrubbing.
+func (f FieldSpec) ScrubberFunc(fn func(v any) (any, error)) FieldSpec {
+	f.customScrubFn = fn
+	return f
+}
+
 func (f FieldSpec) ScrubValue(v any) (any, error) {
+	if f.customScrubFn != nil {
+		return f.customScrubFn(v)
+	}
 	if f.Scrubber == "" {
 		return v, nil
 	}
@@ -622,6 +734,21 @@ func (f FieldSpecs) Add(specs ...FieldSpec) FieldSpecs {
 	return append(f, specs...)
 }
 
+// formerNames returns a map of former field names to the specs of the fields
+// they were renamed to, or nil if no fields have been renamed.
+func (f FieldSpecs) formerNames() map[string]FieldSpec {
+	var m map[string]FieldSpec
+	for _, field := range f {
+		for _, n := range field.FormerNames {
+			if m == nil {
+				m = map[string]FieldSpec{}
+			}
+			m[n] = field
+		}
+	}
+	return m
+}
+
 // FieldFilter defines a filter closure that returns a boolean for a component
 // field indicating whether the field should be kept within a generated config.
 type FieldFilter func(spec FieldSpec, v any) bool
@@ -823,7 +950,44 @@ func (f FieldSpec) needsDefault() bool {
 	return true
 }
 
+// envDefault attempts to obtain the default value of a field from its
+// environment variable, parsed according to the field type.
+func (f FieldSpec) envDefault() (v any, exists bool, err error) {
+	if f.DefaultEnvVar == "" {
+		return nil, false, nil
+	}
+	var str string
+	if str, exists = os.LookupEnv(f.DefaultEnvVar); !exists {
+		return nil, false, nil
+	}
+
+	node := &yaml.Node{Kind: yaml.ScalarNode, Value: str}
+	if f.Kind != KindScalar || f.Type == FieldTypeObject || f.Type == FieldTypeUnknown {
+		var tmp yaml.Node
+		if err = yaml.Unmarshal([]byte(str), &tmp); err != nil {
+			return nil, true, err
+		}
+		node = &tmp
+	}
+	v, err = f.YAMLToValue(node, ToValueConfig{})
+	return v, true, err
+}
+
+func (f FieldSpec) hasEnvDefault() bool {
+	if f.DefaultEnvVar == "" {
+		return false
+	}
+	_, exists := os.LookupEnv(f.DefaultEnvVar)
+	return exists
+}
+
 func getDefault(pathName string, field FieldSpec) (any, error) {
+	if v, exists, err := field.envDefault(); exists {
+		if err != nil {
+			return nil, fmt.Errorf("field '%v': failed to parse default from environment variable %v: %w", pathName, field.DefaultEnvVar, err)
+		}
+		return v, nil
+	}
 	if field.Default != nil {
 		if len(field.Children) > 0 && field.Kind == KindScalar {
 			if tmp, ok := value.IClone(*field.Default).(map[string]any); ok {
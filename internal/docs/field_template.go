@@ -53,6 +53,8 @@ This field supports xref:configuration:interpolation.adoc#bloblang-queries[inter
 
 {{if gt (len $field.DefaultMarshalled) 0}}*Default*: ` + "`{{$field.DefaultMarshalled}}`" + `
 {{end -}}
+{{if gt (len $field.Spec.DefaultEnvVar) 0}}*Default environment variable*: ` + "`{{$field.Spec.DefaultEnvVar}}`" + `
+{{end -}}
 {{if gt (len $field.Spec.Version) 0}}Requires version {{$field.Spec.Version}} or newer
 {{end -}}
 {{if gt (len $field.Spec.AnnotatedOptions) 0}}
@@ -108,6 +108,15 @@ func (f FieldSpecs) AnyToMap(v any, conf ToValueConfig) (map[string]any, error)
 		pendingFieldsMap[field.Name] = field
 	}
 
+	for former, field := range f.formerNames() {
+		if v, exists := m[former]; exists {
+			if _, exists := m[field.Name]; !exists {
+				m[field.Name] = v
+				delete(m, former)
+			}
+		}
+	}
+
 	for fieldName, fieldValue := range m {
 		f, exists := pendingFieldsMap[fieldName]
 		if !exists {
@@ -439,6 +439,13 @@ func (f FieldSpecs) SanitiseYAML(node *yaml.Node, conf SanitiseConfig) error {
 	for i := 0; i < len(node.Content)-1; i += 2 {
 		nodeKeys[node.Content[i].Value] = node.Content[i+1]
 	}
+	for former, field := range f.formerNames() {
+		if v, exists := nodeKeys[former]; exists {
+			if _, exists := nodeKeys[field.Name]; !exists {
+				nodeKeys[field.Name] = v
+			}
+		}
+	}
 
 	// Following the order of our field specs, extract each field.
 	newNodes := []*yaml.Node{}
@@ -448,7 +455,16 @@ func (f FieldSpecs) SanitiseYAML(node *yaml.Node, conf SanitiseConfig) error {
 		}
 		value, exists := nodeKeys[field.Name]
 		if !exists {
-			continue
+			if !conf.ResolveEnvDefaults {
+				continue
+			}
+			var err error
+			if value, err = field.envDefaultYAML(); err != nil {
+				return err
+			}
+			if value == nil {
+				continue
+			}
 		}
 		if conf.Filter.shouldDrop(field, value) {
 			continue
@@ -463,12 +479,37 @@ func (f FieldSpecs) SanitiseYAML(node *yaml.Node, conf SanitiseConfig) error {
 		if err := keyNode.Encode(field.Name); err != nil {
 			return err
 		}
+		if !exists {
+			comment := "default from env " + field.DefaultEnvVar
+			if value.Kind == yaml.ScalarNode {
+				value.LineComment = comment
+			} else {
+				keyNode.LineComment = comment
+			}
+		}
 		newNodes = append(newNodes, &keyNode, value)
 	}
 	node.Content = newNodes
 	return nil
 }
 
+// envDefaultYAML returns a YAML node containing the default value of a field
+// obtained from its environment variable, or nil if the variable is not set.
+func (f FieldSpec) envDefaultYAML() (*yaml.Node, error) {
+	v, exists, err := f.envDefault()
+	if !exists {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("field '%v': failed to parse default from environment variable %v: %w", f.Name, f.DefaultEnvVar, err)
+	}
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
 //------------------------------------------------------------------------------
 
 func lintYAMLFromOmit(parentSpec FieldSpecs, lintTargetSpec FieldSpec, parent, node *yaml.Node) []Lint {
@@ -677,6 +718,16 @@ func (f FieldSpecs) LintYAML(ctx LintContext, node *yaml.Node) []Lint {
 		specNamesAll[field.Name] = field
 	}
 
+	formerNames := f.formerNames()
+	nodeKeys := map[string]struct{}{}
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		nodeKeys[node.Content[i].Value] = struct{}{}
+	}
+
+	// The lines of each field that is set, used for checking the requirements
+	// and conflicts between sibling fields.
+	setFields := map[string]int{}
+
 	var walkNodeContent func(*yaml.Node)
 	walkNodeContent = func(walkNode *yaml.Node) {
 		for i := 0; i < len(walkNode.Content)-1; i += 2 {
@@ -685,6 +736,17 @@ func (f FieldSpecs) LintYAML(ctx LintContext, node *yaml.Node) []Lint {
 				continue
 			}
 			spec, exists := specNamesAll[walkNode.Content[i].Value]
+			if !exists {
+				if spec, exists = formerNames[walkNode.Content[i].Value]; exists {
+					if _, exists := nodeKeys[spec.Name]; exists {
+						lints = append(lints, NewLintError(walkNode.Content[i].Line, LintShouldOmit, fmt.Errorf("field %v has been renamed to %v and cannot be specified alongside it", walkNode.Content[i].Value, spec.Name)))
+						continue
+					}
+					if ctx.conf.RejectDeprecated {
+						lints = append(lints, NewLintError(walkNode.Content[i].Line, LintDeprecated, fmt.Errorf("field %v has been renamed to %v", walkNode.Content[i].Value, spec.Name)))
+					}
+				}
+			}
 			if !exists {
 				if walkNode.Content[i+1].Kind != yaml.AliasNode {
 					lints = append(lints, NewLintError(walkNode.Content[i].Line, LintUnknown, fmt.Errorf("field %v not recognised", walkNode.Content[i].Value)))
@@ -693,15 +755,34 @@ func (f FieldSpecs) LintYAML(ctx LintContext, node *yaml.Node) []Lint {
 			}
 			lints = append(lints, lintYAMLFromOmit(f, spec, walkNode, walkNode.Content[i+1])...)
 			lints = append(lints, spec.LintYAML(ctx, walkNode.Content[i+1])...)
-			delete(specNamesMissing, walkNode.Content[i].Value)
+			delete(specNamesMissing, spec.Name)
+			setFields[spec.Name] = walkNode.Content[i].Line
 		}
 	}
 	walkNodeContent(node)
 
+	for _, field := range f {
+		line, isSet := setFields[field.Name]
+		if !isSet {
+			continue
+		}
+		for _, r := range field.RequiredSiblings {
+			if _, exists := setFields[r]; !exists {
+				lints = append(lints, NewLintError(line, LintMissing, fmt.Errorf("field %v is required when %v is set", r, field.Name)))
+			}
+		}
+		for _, c := range field.ConflictingSiblings {
+			if _, exists := setFields[c]; exists {
+				lints = append(lints, NewLintError(line, LintShouldOmit, fmt.Errorf("field %v cannot be set alongside %v", field.Name, c)))
+			}
+		}
+	}
+
 	for name, remaining := range specNamesMissing {
 		_, isCore := remaining.Type.IsCoreComponent()
 		if remaining.needsDefault() &&
 			remaining.Default == nil &&
+			!remaining.hasEnvDefault() &&
 			!isCore &&
 			remaining.Kind == KindScalar &&
 			len(remaining.Children) == 0 {
@@ -929,8 +1010,19 @@ func (f FieldSpecs) YAMLToMap(node *yaml.Node, conf ToValueConfig) (map[string]a
 
 	resultMap := map[string]any{}
 
+	formerNames := f.formerNames()
+	nodeKeys := map[string]struct{}{}
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		nodeKeys[node.Content[i].Value] = struct{}{}
+	}
+
 	for i := 0; i < len(node.Content)-1; i += 2 {
 		fieldName := node.Content[i].Value
+		if f, exists := formerNames[fieldName]; exists {
+			if _, exists := nodeKeys[f.Name]; !exists {
+				fieldName = f.Name
+			}
+		}
 
 		if f, exists := pendingFieldsMap[fieldName]; exists {
 			delete(pendingFieldsMap, f.Name)
@@ -1108,6 +1200,99 @@ func (f FieldSpecs) WalkYAML(node *yaml.Node, prov Provider, fn ComponentWalkYAM
 	return nil
 }
 
+// MigrateRenamedYAML walks a YAML config and renames any fields, including
+// those of nested components, that are specified by a former name to their
+// current name. Returns true if the config was modified.
+func (f FieldSpecs) MigrateRenamedYAML(node *yaml.Node, prov Provider) (bool, error) {
+	changed := f.renameFormerYAML(node)
+	err := f.WalkYAML(node, prov, func(c WalkedYAMLComponent) error {
+		cSpec, exists := prov.GetDocs(c.Name, c.ComponentType)
+		if !exists {
+			return nil
+		}
+		for i := 0; i < len(c.Conf.Content)-1; i += 2 {
+			if c.Conf.Content[i].Value == c.Name {
+				if cSpec.Config.renameFormerYAML(c.Conf.Content[i+1]) {
+					changed = true
+				}
+				break
+			}
+		}
+		return nil
+	})
+	return changed, err
+}
+
+func (f FieldSpec) renameFormerYAML(node *yaml.Node) (changed bool) {
+	node = unwrapDocumentNode(node)
+	if _, isCore := f.Type.IsCoreComponent(); isCore || len(f.Children) == 0 {
+		return false
+	}
+
+	switch f.Kind {
+	case Kind2DArray:
+		for i := 0; i < len(node.Content); i++ {
+			for j := 0; j < len(node.Content[i].Content); j++ {
+				if f.Children.renameFormerYAML(node.Content[i].Content[j]) {
+					changed = true
+				}
+			}
+		}
+	case KindArray:
+		for i := 0; i < len(node.Content); i++ {
+			if f.Children.renameFormerYAML(node.Content[i]) {
+				changed = true
+			}
+		}
+	case KindMap:
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			if f.Children.renameFormerYAML(node.Content[i+1]) {
+				changed = true
+			}
+		}
+	default:
+		changed = f.Children.renameFormerYAML(node)
+	}
+	return
+}
+
+func (f FieldSpecs) renameFormerYAML(node *yaml.Node) (changed bool) {
+	node = unwrapDocumentNode(node)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return false
+	}
+
+	if formerNames := f.formerNames(); formerNames != nil {
+		nodeKeys := map[string]struct{}{}
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			nodeKeys[node.Content[i].Value] = struct{}{}
+		}
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			spec, exists := formerNames[node.Content[i].Value]
+			if !exists {
+				continue
+			}
+			if _, exists := nodeKeys[spec.Name]; exists {
+				continue
+			}
+			node.Content[i].Value = spec.Name
+			nodeKeys[spec.Name] = struct{}{}
+			changed = true
+		}
+	}
+
+	nodeKeys := map[string]*yaml.Node{}
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		nodeKeys[node.Content[i].Value] = node.Content[i+1]
+	}
+	for _, field := range f {
+		if value, exists := nodeKeys[field.Name]; exists && field.renameFormerYAML(value) {
+			changed = true
+		}
+	}
+	return
+}
+
 //------------------------------------------------------------------------------
 
 func unwrapDocumentNode(node *yaml.Node) *yaml.Node {
@@ -1406,3 +1406,219 @@ processors:
 		})
 	}
 }
+
+func TestYAMLRenamedFields(t *testing.T) {
+	prov := docs.NewMappedDocsProvider()
+	prov.RegisterDocs(docs.ComponentSpec{
+		Name: "testyamlrenamedprocessor",
+		Type: docs.TypeProcessor,
+		Config: docs.FieldComponent().WithChildren(
+			docs.FieldString("new_a", "").RenamedFrom("old_a"),
+			docs.FieldObject("b", "").WithChildren(
+				docs.FieldInt("new_c", "").RenamedFrom("old_c"),
+			),
+		),
+	})
+
+	spec := docs.FieldSpecs{
+		docs.FieldProcessor("processors", "").Array().RenamedFrom("procs"),
+	}
+
+	node, err := docs.UnmarshalYAML([]byte(`
+procs:
+  - testyamlrenamedprocessor:
+      old_a: foo
+      b:
+        old_c: 10
+`))
+	require.NoError(t, err)
+
+	// Parsing accepts former names
+	pConf, err := spec.ParsedConfigFromAny(node)
+	require.NoError(t, err)
+
+	procs, err := pConf.FieldAnyList("processors")
+	require.NoError(t, err)
+	require.Len(t, procs, 1)
+
+	pSpec, exists := prov.GetDocs("testyamlrenamedprocessor", docs.TypeProcessor)
+	require.True(t, exists)
+
+	pNode, err := docs.UnmarshalYAML([]byte(`
+old_a: foo
+b:
+  old_c: 10
+`))
+	require.NoError(t, err)
+
+	pProcConf, err := pSpec.Config.Children.ParsedConfigFromAny(pNode)
+	require.NoError(t, err)
+
+	a, err := pProcConf.FieldString("new_a")
+	require.NoError(t, err)
+	assert.Equal(t, "foo", a)
+
+	c, err := pProcConf.FieldInt("b", "new_c")
+	require.NoError(t, err)
+	assert.Equal(t, 10, c)
+
+	pAnyConf, err := pSpec.Config.Children.ParsedConfigFromAny(map[string]any{
+		"old_a": "bar",
+		"b":     map[string]any{"old_c": 20},
+	})
+	require.NoError(t, err)
+
+	a, err = pAnyConf.FieldString("new_a")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", a)
+
+	c, err = pAnyConf.FieldInt("b", "new_c")
+	require.NoError(t, err)
+	assert.Equal(t, 20, c)
+
+	// Linting only flags former names when deprecated fields are rejected
+	lConf := docs.NewLintConfig(prov)
+	assert.Empty(t, spec.LintYAML(docs.NewLintContext(lConf), node))
+
+	lConf.RejectDeprecated = true
+	var lintWhats []string
+	for _, l := range spec.LintYAML(docs.NewLintContext(lConf), node) {
+		lintWhats = append(lintWhats, l.What)
+	}
+	assert.ElementsMatch(t, []string{
+		"field procs has been renamed to processors",
+		"field old_a has been renamed to new_a",
+		"field old_c has been renamed to new_c",
+	}, lintWhats)
+
+	bothNode, err := docs.UnmarshalYAML([]byte(`
+new_a: foo
+old_a: bar
+`))
+	require.NoError(t, err)
+
+	lints := pSpec.Config.Children.LintYAML(docs.NewLintContext(docs.NewLintConfig(prov)), bothNode)
+	require.Len(t, lints, 1)
+	assert.Equal(t, "field old_a has been renamed to new_a and cannot be specified alongside it", lints[0].What)
+
+	// Sanitising (echoing) a config uses current names
+	sanitNode, err := docs.UnmarshalYAML([]byte(`
+procs:
+  - testyamlrenamedprocessor:
+      old_a: foo
+`))
+	require.NoError(t, err)
+
+	sanitConf := docs.NewSanitiseConfig(prov)
+	sanitConf.RemoveTypeField = true
+	require.NoError(t, spec.SanitiseYAML(sanitNode, sanitConf))
+
+	resBytes, err := docs.MarshalYAML(*sanitNode)
+	require.NoError(t, err)
+	assert.Equal(t, `processors:
+  - testyamlrenamedprocessor:
+      new_a: foo
+`, string(resBytes))
+
+	// Migration rewrites former names in place
+	changed, err := spec.MigrateRenamedYAML(node, prov)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	resBytes, err = docs.MarshalYAML(*node)
+	require.NoError(t, err)
+	assert.Equal(t, `processors:
+  - testyamlrenamedprocessor:
+      new_a: foo
+      b:
+        new_c: 10
+`, string(resBytes))
+
+	changed, err = spec.MigrateRenamedYAML(node, prov)
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestYAMLEnvDefaults(t *testing.T) {
+	t.Setenv("TEST_YAML_ENV_DEFAULT_A", "from env")
+	t.Setenv("TEST_YAML_ENV_DEFAULT_B", "42")
+	t.Setenv("TEST_YAML_ENV_DEFAULT_D", "[ foo, bar ]")
+
+	spec := docs.FieldSpecs{
+		docs.FieldString("a", "").HasDefaultFromEnv("TEST_YAML_ENV_DEFAULT_A"),
+		docs.FieldInt("b", "").HasDefaultFromEnv("TEST_YAML_ENV_DEFAULT_B").HasDefault(1),
+		docs.FieldInt("c", "").HasDefaultFromEnv("TEST_YAML_ENV_DEFAULT_UNSET").HasDefault(1),
+		docs.FieldString("d", "").Array().HasDefaultFromEnv("TEST_YAML_ENV_DEFAULT_D"),
+	}
+
+	node, err := docs.UnmarshalYAML([]byte(`
+c: 5
+`))
+	require.NoError(t, err)
+
+	assert.Empty(t, spec.LintYAML(docs.NewLintContext(docs.NewLintConfig(docs.NewMappedDocsProvider())), node))
+
+	pConf, err := spec.ParsedConfigFromAny(node)
+	require.NoError(t, err)
+
+	a, err := pConf.FieldString("a")
+	require.NoError(t, err)
+	assert.Equal(t, "from env", a)
+
+	b, err := pConf.FieldInt("b")
+	require.NoError(t, err)
+	assert.Equal(t, 42, b)
+
+	c, err := pConf.FieldInt("c")
+	require.NoError(t, err)
+	assert.Equal(t, 5, c)
+
+	d, err := pConf.FieldStringList("d")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo", "bar"}, d)
+
+	sanitConf := docs.NewSanitiseConfig(docs.NewMappedDocsProvider())
+	sanitConf.ResolveEnvDefaults = true
+	require.NoError(t, spec.SanitiseYAML(node, sanitConf))
+
+	out, err := docs.MarshalYAML(*node)
+	require.NoError(t, err)
+	assert.Equal(t, `a: from env # default from env TEST_YAML_ENV_DEFAULT_A
+b: 42 # default from env TEST_YAML_ENV_DEFAULT_B
+c: 5
+d: # default from env TEST_YAML_ENV_DEFAULT_D
+  - foo
+  - bar
+`, string(out))
+
+	t.Setenv("TEST_YAML_ENV_DEFAULT_B", "nope")
+	_, err = spec.ParsedConfigFromAny(map[string]any{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse default from environment variable TEST_YAML_ENV_DEFAULT_B")
+}
+
+func TestYAMLEnvDefaultsRequired(t *testing.T) {
+	spec := docs.FieldSpecs{
+		docs.FieldString("a", "").HasDefaultFromEnv("TEST_YAML_ENV_DEFAULT_REQUIRED"),
+	}
+
+	node, err := docs.UnmarshalYAML([]byte(`{}`))
+	require.NoError(t, err)
+
+	lints := spec.LintYAML(docs.NewLintContext(docs.NewLintConfig(docs.NewMappedDocsProvider())), node)
+	require.Len(t, lints, 1)
+	assert.Contains(t, lints[0].Error(), "field a is required")
+
+	_, err = spec.ParsedConfigFromAny(node)
+	require.Error(t, err)
+
+	t.Setenv("TEST_YAML_ENV_DEFAULT_REQUIRED", "foo")
+	assert.Empty(t, spec.LintYAML(docs.NewLintContext(docs.NewLintConfig(docs.NewMappedDocsProvider())), node))
+
+	pConf, err := spec.ParsedConfigFromAny(node)
+	require.NoError(t, err)
+
+	a, err := pConf.FieldString("a")
+	require.NoError(t, err)
+	assert.Equal(t, "foo", a)
+}
@@ -142,3 +142,24 @@ func superGlobs(f fs.FS, segments []string) ([]string, error) {
 	}
 	return matchSlice, nil
 }
+
+// StaticDir returns the longest leading directory of a path that contains no
+// glob patterns, which is the deepest directory that all matches of the path
+// reside within.
+func StaticDir(path string) string {
+	segments := strings.Split(path, "/")
+	segments = segments[:len(segments)-1]
+	for i, segment := range segments {
+		if hasMeta(segment) {
+			segments = segments[:i]
+			break
+		}
+	}
+	if dir := strings.Join(segments, "/"); dir != "" {
+		return dir
+	}
+	if strings.HasPrefix(path, "/") {
+		return "/"
+	}
+	return "."
+}
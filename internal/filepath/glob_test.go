@@ -188,3 +188,18 @@ func TestGlobsAndSuperPaths(t *testing.T) {
 		})
 	}
 }
+
+func TestStaticDir(t *testing.T) {
+	for path, exp := range map[string]string{
+		"foo.txt":              ".",
+		"*.txt":                ".",
+		"/foo.txt":             "/",
+		"/*/foo.txt":           "/",
+		"./data/*.csv":         "./data",
+		"data/**/*.csv":        "data",
+		"/var/log/app-*/a.log": "/var/log",
+		"/var/log/app.log":     "/var/log",
+	} {
+		assert.Equal(t, exp, StaticDir(path), path)
+	}
+}
@@ -19,6 +19,11 @@ type FS interface {
 	MkdirAll(path string, perm fs.FileMode) error
 }
 
+// Renamer is implemented by FS implementations that are able to rename files.
+type Renamer interface {
+	Rename(oldpath, newpath string) error
+}
+
 // ReadFile opens a file with the RDONLY flag and returns all bytes from it.
 func ReadFile(f fs.FS, name string) ([]byte, error) {
 	var i fs.File
@@ -93,3 +98,7 @@ func (o *osPT) Remove(name string) error {
 func (o *osPT) MkdirAll(path string, perm fs.FileMode) error {
 	return os.MkdirAll(path, perm)
 }
+
+func (o *osPT) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
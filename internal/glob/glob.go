@@ -0,0 +1,176 @@
+// Package glob compiles shell style glob patterns into regular expressions.
+//
+// The following syntax is supported:
+//
+//   - `*` matches any sequence of characters
+//   - `?` matches any single character
+//   - `[abc]`, `[a-z]` matches a single character within a class, where a class
+//     beginning with `!` or `^` is negated
+//   - `{foo,bar}` matches any of the comma separated alternatives, which may
+//     contain patterns themselves
+//   - `\` escapes the character that follows it
+//
+// When compiled as a path pattern `*`, `?` and negated character classes never
+// match the path separator `/`, and a `**` path segment matches zero or more
+// whole path segments.
+package glob
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Compile parses a glob pattern where no characters are treated specially.
+func Compile(pattern string) (*regexp.Regexp, error) {
+	return compile(pattern, false)
+}
+
+// CompilePath parses a glob pattern where `/` is treated as a path separator
+// and `**` segments match any number of path segments.
+func CompilePath(pattern string) (*regexp.Regexp, error) {
+	return compile(pattern, true)
+}
+
+func compile(pattern string, isPath bool) (*regexp.Regexp, error) {
+	anyChar, anySeq := ".", ".*"
+	if isPath {
+		anyChar, anySeq = "[^/]", "[^/]*"
+	}
+
+	var b strings.Builder
+	b.WriteString("(?s)^")
+
+	runes := []rune(pattern)
+	braceDepth := 0
+
+	// Whether the current position is at the beginning of a path segment.
+	segmentStart := func(i int) bool {
+		return i == 0 || runes[i-1] == '/'
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch r {
+		case '\\':
+			if i++; i >= len(runes) {
+				return nil, errors.New("pattern ends with an unterminated escape")
+			}
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '?':
+			b.WriteString(anyChar)
+		case '*':
+			if !isPath || i+1 >= len(runes) || runes[i+1] != '*' {
+				b.WriteString(anySeq)
+				for i+1 < len(runes) && runes[i+1] == '*' {
+					i++
+				}
+				continue
+			}
+			end := i + 2
+			for end < len(runes) && runes[end] == '*' {
+				end++
+			}
+			if !segmentStart(i) || (end < len(runes) && runes[end] != '/') {
+				// A double star that is not a whole segment behaves as a
+				// single star.
+				b.WriteString(anySeq)
+				i = end - 1
+				continue
+			}
+			if end == len(runes) {
+				// A trailing `**` matches everything that remains, including
+				// nothing at all when preceded by a separator.
+				if i > 0 {
+					rebuilt := strings.TrimSuffix(b.String(), "/")
+					b.Reset()
+					b.WriteString(rebuilt)
+					b.WriteString("(?:/.*)?")
+				} else {
+					b.WriteString(".*")
+				}
+				i = end - 1
+				continue
+			}
+			// A leading or intermediate `**/` matches zero or more segments.
+			b.WriteString("(?:.*/)?")
+			i = end
+		case '[':
+			end, class, err := compileClass(runes, i, isPath)
+			if err != nil {
+				return nil, err
+			}
+			b.WriteString(class)
+			i = end
+		case '{':
+			braceDepth++
+			b.WriteString("(?:")
+		case ',':
+			if braceDepth > 0 {
+				b.WriteString("|")
+			} else {
+				b.WriteString(",")
+			}
+		case '}':
+			if braceDepth == 0 {
+				return nil, errors.New("pattern contains an unmatched closing brace")
+			}
+			braceDepth--
+			b.WriteString(")")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	if braceDepth > 0 {
+		return nil, errors.New("pattern contains an unterminated brace")
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// compileClass converts a character class beginning at index start into a
+// regular expression class, returning the index of the closing bracket.
+func compileClass(runes []rune, start int, isPath bool) (int, string, error) {
+	var b strings.Builder
+	b.WriteString("[")
+
+	i := start + 1
+	negated := false
+	if i < len(runes) && (runes[i] == '!' || runes[i] == '^') {
+		negated = true
+		b.WriteString("^")
+		i++
+	}
+	if negated && isPath {
+		b.WriteString("/")
+	}
+
+	first := true
+	for ; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ']' && !first:
+			b.WriteString("]")
+			return i, b.String(), nil
+		case r == '\\':
+			if i++; i >= len(runes) {
+				return 0, "", errors.New("pattern ends with an unterminated escape")
+			}
+			writeClassRune(&b, runes[i])
+		case r == '-' && !first && i+1 < len(runes) && runes[i+1] != ']':
+			b.WriteString("-")
+		default:
+			writeClassRune(&b, r)
+		}
+		first = false
+	}
+	return 0, "", fmt.Errorf("character class at position %v is unterminated", start)
+}
+
+func writeClassRune(b *strings.Builder, r rune) {
+	if strings.ContainsRune(`[]^-\`, r) {
+		b.WriteByte('\\')
+	}
+	b.WriteRune(r)
+}
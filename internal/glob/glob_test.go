@@ -0,0 +1,89 @@
+package glob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		pattern string
+		matches []string
+		misses  []string
+	}{
+		{pattern: "foo", matches: []string{"foo"}, misses: []string{"foobar", "fo", ""}},
+		{pattern: "foo*", matches: []string{"foo", "foobar", "foo/bar"}, misses: []string{"fo"}},
+		{pattern: "*.json", matches: []string{"a.json", "a/b.json", ".json"}, misses: []string{"a.jsonl", "a_json"}},
+		{pattern: "f?o", matches: []string{"foo", "f/o"}, misses: []string{"fo", "fooo"}},
+		{pattern: "[abc]x", matches: []string{"ax", "cx"}, misses: []string{"dx", "x"}},
+		{pattern: "[a-c]x", matches: []string{"bx"}, misses: []string{"-x", "dx"}},
+		{pattern: "[!a-c]x", matches: []string{"dx", "/x"}, misses: []string{"ax"}},
+		{pattern: "[^a]x", matches: []string{"bx"}, misses: []string{"ax"}},
+		{pattern: "[]a]", matches: []string{"]", "a"}, misses: []string{"b"}},
+		{pattern: "[a-]", matches: []string{"a", "-"}, misses: []string{"b"}},
+		{pattern: `[\]]`, matches: []string{"]"}, misses: []string{`\`}},
+		{pattern: "{foo,bar}.txt", matches: []string{"foo.txt", "bar.txt"}, misses: []string{"baz.txt", "{foo,bar}.txt"}},
+		{pattern: "{a,b{c,d}}", matches: []string{"a", "bc", "bd"}, misses: []string{"b"}},
+		{pattern: "a,b", matches: []string{"a,b"}},
+		{pattern: `\*\?\[\{`, matches: []string{"*?[{"}, misses: []string{"a?[{"}},
+		{pattern: "a.b+c(d)", matches: []string{"a.b+c(d)"}, misses: []string{"axb+c(d)"}},
+		{pattern: "multi*line", matches: []string{"multi\nline"}},
+	}
+
+	for _, test := range tests {
+		re, err := Compile(test.pattern)
+		require.NoError(t, err, test.pattern)
+		for _, m := range test.matches {
+			assert.True(t, re.MatchString(m), "%v should match %v", test.pattern, m)
+		}
+		for _, m := range test.misses {
+			assert.False(t, re.MatchString(m), "%v should not match %v", test.pattern, m)
+		}
+	}
+}
+
+func TestCompilePath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		matches []string
+		misses  []string
+	}{
+		{pattern: "*.json", matches: []string{"a.json"}, misses: []string{"a/b.json"}},
+		{pattern: "a/?/c", matches: []string{"a/b/c"}, misses: []string{"a//c", "a/bb/c"}},
+		{pattern: "a/[!b]/c", matches: []string{"a/x/c"}, misses: []string{"a/b/c", "a///c"}},
+		{pattern: "**/*.json", matches: []string{"a.json", "a/b.json", "a/b/c.json"}, misses: []string{"a/b.jsonl"}},
+		{pattern: "logs/**/*.log", matches: []string{"logs/a.log", "logs/2024/01/a.log"}, misses: []string{"logs.log", "other/a.log"}},
+		{pattern: "logs/**", matches: []string{"logs", "logs/", "logs/a", "logs/a/b"}, misses: []string{"logsa", "other/logs"}},
+		{pattern: "**", matches: []string{"", "a", "a/b/c"}},
+		{pattern: "a**b/c", matches: []string{"ab/c", "axxb/c"}, misses: []string{"a/b/c"}},
+		{pattern: "a/**b", matches: []string{"a/b", "a/xb"}, misses: []string{"a/x/b"}},
+		{pattern: "{src,test}/**/*.go", matches: []string{"src/a.go", "test/x/y.go"}, misses: []string{"docs/a.go"}},
+	}
+
+	for _, test := range tests {
+		re, err := CompilePath(test.pattern)
+		require.NoError(t, err, test.pattern)
+		for _, m := range test.matches {
+			assert.True(t, re.MatchString(m), "%v should match %v", test.pattern, m)
+		}
+		for _, m := range test.misses {
+			assert.False(t, re.MatchString(m), "%v should not match %v", test.pattern, m)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for pattern, errStr := range map[string]string{
+		`foo\`: "pattern ends with an unterminated escape",
+		"[abc": "character class at position 0 is unterminated",
+		"a[]":  "character class at position 1 is unterminated",
+		"{a,b": "pattern contains an unterminated brace",
+		"a,b}": "pattern contains an unmatched closing brace",
+		`[a\`:  "pattern ends with an unterminated escape",
+	} {
+		_, err := Compile(pattern)
+		assert.EqualError(t, err, errStr, pattern)
+	}
+}
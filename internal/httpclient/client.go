@@ -105,12 +105,22 @@ func NewClientFromOldConfig(conf OldConfig, mgr *service.Resources, opts ...Requ
 		}
 	}
 
+	if conf.Transport != nil {
+		tr, err := conf.Transport.apply(h.client.Transport)
+		if err != nil {
+			return nil, err
+		}
+		h.client.Transport = newConnMetricsTransport(tr, mgr)
+	}
+
 	h.client.Transport, err = newRequestLog(h.client.Transport, h.log, conf.DumpRequestLogLevel)
 	if err != nil {
 		return nil, fmt.Errorf("failed to config logger for request dump: %v", err)
 	}
 
-	h.client = conf.clientCtor(h.clientCtx, h.client)
+	if conf.clientCtor != nil {
+		h.client = conf.clientCtor(h.clientCtx, h.client)
+	}
 
 	for _, c := range conf.BackoffOn {
 		h.backoffOn[c] = struct{}{}
@@ -305,6 +315,13 @@ var errTimedOut = errors.New("timed out waiting for next request")
 // performs it, and then returns the *http.Response, allowing the raw response
 // to be consumed.
 func (h *Client) SendToResponse(ctx context.Context, sendMsg service.MessageBatch) (res *http.Response, err error) {
+	return h.SendToResponseWithOverrides(ctx, sendMsg, nil)
+}
+
+// SendToResponseWithOverrides performs a request in the same way as
+// SendToResponse, but with parts of the request optionally replaced by
+// explicit overrides.
+func (h *Client) SendToResponseWithOverrides(ctx context.Context, sendMsg service.MessageBatch, overrides *RequestOverrides) (res *http.Response, err error) {
 	var spans []*tracing.Span
 	if sendMsg != nil {
 		sendMsg, spans = tracing.WithChildSpans(h.mgr.OtelTracer(), "http_request", sendMsg)
@@ -324,7 +341,7 @@ func (h *Client) SendToResponse(ctx context.Context, sendMsg service.MessageBatc
 	}
 
 	var req *http.Request
-	if req, err = h.reqCreator.Create(sendMsg); err != nil {
+	if req, err = h.reqCreator.CreateWithOverrides(sendMsg, overrides); err != nil {
 		logErr(err)
 		return nil, err
 	}
@@ -364,7 +381,7 @@ func (h *Client) SendToResponse(ctx context.Context, sendMsg service.MessageBatc
 	i, j := 0, numRetries
 	for i < j && err != nil {
 		logErr(err)
-		if req, err = h.reqCreator.Create(sendMsg); err != nil {
+		if req, err = h.reqCreator.CreateWithOverrides(sendMsg, overrides); err != nil {
 			continue
 		}
 		if rateLimited {
@@ -421,7 +438,7 @@ func unexpectedErr(res *http.Response) error {
 	if err != nil {
 		return err
 	}
-	return ErrUnexpectedHTTPRes{Code: res.StatusCode, S: res.Status, Body: body}
+	return ErrUnexpectedHTTPRes{Code: res.StatusCode, S: res.Status, Body: body, Header: res.Header}
 }
 
 // Send creates an HTTP request from the client config, a provided message to be
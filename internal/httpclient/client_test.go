@@ -665,6 +665,46 @@ oauth2:
 	assert.Equal(t, "HELLO WORLD", string(mBytes))
 }
 
+func TestHTTPClientOAuth2TokenRequestsUseBaseClient(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer footoken", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	var tokenRequests int64
+	tsOAuth2 := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&tokenRequests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"footoken","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer tsOAuth2.Close()
+
+	// The token provider is only reachable with the TLS config of the client.
+	conf := clientConfig(t, `
+url: %v
+oauth2:
+  enabled: true
+  token_url: %v
+  client_key: fookey
+  client_secret: foosecret
+tls:
+  enabled: true
+  skip_cert_verify: true
+`, ts.URL+"/testpost", tsOAuth2.URL)
+
+	h, err := NewClientFromOldConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err := h.Send(context.Background(), service.MessageBatch{
+			service.NewMessage([]byte("hello world")),
+		})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int64(1), atomic.LoadInt64(&tokenRequests))
+}
+
 func TestHTTPClientOAuth2AndTLSConf(t *testing.T) {
 	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "Bearer footoken", r.Header.Get("Authorization"))
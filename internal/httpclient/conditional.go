@@ -0,0 +1,94 @@
+package httpclient
+
+import (
+	"net/http"
+	"sync"
+)
+
+// maxConditionalValidators is the maximum number of request URLs that
+// validators are stored for, which prevents unbounded growth when URLs are
+// dynamic.
+const maxConditionalValidators = 1024
+
+type responseValidators struct {
+	etag         string
+	lastModified string
+}
+
+// ConditionalValidators stores the ETag and Last-Modified validators of
+// responses by the URL of their request, which are then added to subsequent
+// requests of the same URL as If-None-Match and If-Modified-Since headers. This
+// allows a server to respond with 304 Not Modified when the resource hasn't
+// changed since it was last consumed.
+type ConditionalValidators struct {
+	mut   sync.Mutex
+	byURL map[string]responseValidators
+}
+
+// NewConditionalValidators creates an empty store of validators.
+func NewConditionalValidators() *ConditionalValidators {
+	return &ConditionalValidators{
+		byURL: map[string]responseValidators{},
+	}
+}
+
+// WithConditionalValidators modifies the request creator to add the
+// validators of previous responses to each request as conditional headers.
+func WithConditionalValidators(c *ConditionalValidators) RequestOpt {
+	return func(r *RequestCreator) {
+		r.validators = c
+	}
+}
+
+// Update stores the validators of a response, replacing any validators
+// previously stored for the same request URL. Responses that do not carry
+// validators remove those previously stored.
+func (c *ConditionalValidators) Update(res *http.Response) {
+	if res.Request == nil {
+		return
+	}
+
+	// The request of a response is the last of any redirects, and therefore
+	// we walk back to the request as it was created.
+	req := res.Request
+	for req.Response != nil && req.Response.Request != nil {
+		req = req.Response.Request
+	}
+	urlStr := req.URL.String()
+
+	v := responseValidators{
+		etag:         res.Header.Get("ETag"),
+		lastModified: res.Header.Get("Last-Modified"),
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if v.etag == "" && v.lastModified == "" {
+		delete(c.byURL, urlStr)
+		return
+	}
+	if _, exists := c.byURL[urlStr]; !exists && len(c.byURL) >= maxConditionalValidators {
+		for k := range c.byURL {
+			delete(c.byURL, k)
+			break
+		}
+	}
+	c.byURL[urlStr] = v
+}
+
+func (c *ConditionalValidators) apply(req *http.Request) {
+	c.mut.Lock()
+	v, exists := c.byURL[req.URL.String()]
+	c.mut.Unlock()
+	if !exists {
+		return
+	}
+
+	if v.etag != "" {
+		req.Header.Set("If-None-Match", v.etag)
+	}
+	if v.lastModified != "" {
+		req.Header.Set("If-Modified-Since", v.lastModified)
+	}
+}
@@ -0,0 +1,73 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+func TestConditionalValidators(t *testing.T) {
+	var lastIfNoneMatch, lastIfModifiedSince string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastIfNoneMatch = r.Header.Get("If-None-Match")
+		lastIfModifiedSince = r.Header.Get("If-Modified-Since")
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/tagged", http.StatusFound)
+			return
+		case "/tagged":
+			if lastIfNoneMatch == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+		}
+		_, _ = w.Write([]byte("hello world"))
+	}))
+	defer ts.Close()
+
+	conf := clientConfig(t, `
+url: %v${! @path }
+successful_on: [ 304 ]
+`, ts.URL)
+
+	validators := NewConditionalValidators()
+	h, err := NewClientFromOldConfig(conf, service.MockResources(), WithConditionalValidators(validators))
+	require.NoError(t, err)
+	defer h.Close(context.Background())
+
+	send := func(path string) int {
+		t.Helper()
+		msg := service.NewMessage(nil)
+		msg.MetaSetMut("path", path)
+		res, err := h.SendToResponse(context.Background(), service.MessageBatch{msg})
+		require.NoError(t, err)
+		res.Body.Close()
+		validators.Update(res)
+		return res.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, send("/tagged"))
+	assert.Equal(t, "", lastIfNoneMatch)
+
+	assert.Equal(t, http.StatusNotModified, send("/tagged"))
+	assert.Equal(t, `"v1"`, lastIfNoneMatch)
+	assert.Equal(t, "Wed, 21 Oct 2015 07:28:00 GMT", lastIfModifiedSince)
+
+	// Validators are only applied to requests of the same URL.
+	assert.Equal(t, http.StatusOK, send("/untagged"))
+	assert.Equal(t, "", lastIfNoneMatch)
+
+	// Validators of redirected requests are stored against the original URL.
+	assert.Equal(t, http.StatusOK, send("/redirect"))
+	assert.Equal(t, "", lastIfNoneMatch)
+	assert.Equal(t, http.StatusNotModified, send("/redirect"))
+	assert.Equal(t, `"v1"`, lastIfNoneMatch)
+}
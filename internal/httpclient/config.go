@@ -27,6 +27,7 @@ const (
 	hcFieldDumpRequestLogLevel = "dump_request_log_level"
 	hcFieldTLS                 = "tls"
 	hcFieldProxyURL            = "proxy_url"
+	hcFieldTransport           = "transport"
 )
 
 // ConfigField returns a public API config field spec for an HTTP component,
@@ -103,6 +104,10 @@ func ConfigField(defaultVerb string, forOutput bool, extraChildren ...*service.C
 			Optional(),
 	)
 
+	if forOutput {
+		innerFields = append(innerFields, transportField())
+	}
+
 	innerFields = append(innerFields, extraChildren...)
 	return service.NewObjectField("", innerFields...)
 }
@@ -154,6 +159,13 @@ func ConfigFromParsed(pConf *service.ParsedConfig) (conf OldConfig, err error) {
 		return
 	}
 	conf.ProxyURL, _ = pConf.FieldString(hcFieldProxyURL)
+	if pConf.Contains(hcFieldTransport) {
+		var tConf TransportConfig
+		if tConf, err = transportConfigFromParsed(pConf.Namespace(hcFieldTransport)); err != nil {
+			return
+		}
+		conf.Transport = &tConf
+	}
 	if conf.authSigner, err = pConf.HTTPRequestAuthSignerFromParsed(); err != nil {
 		return
 	}
@@ -182,6 +194,7 @@ type OldConfig struct {
 	TLSEnabled          bool
 	TLSConf             *tls.Config
 	ProxyURL            string
+	Transport           *TransportConfig
 	authSigner          func(f fs.FS, req *http.Request) error
 	clientCtor          func(context.Context, *http.Client) *http.Client
 }
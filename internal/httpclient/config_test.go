@@ -2,6 +2,7 @@ package httpclient
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -69,6 +70,22 @@ drop_on: [ 7, 8, 9 ]
 				assert.Equal(t, []int{1, 2, 3}, o.SuccessfulOn)
 				assert.Equal(t, []int{4, 5, 6}, o.BackoffOn)
 				assert.Equal(t, []int{7, 8, 9}, o.DropOn)
+				assert.Nil(t, o.Transport)
+			},
+		},
+		{
+			name:      "transport defaults",
+			forOutput: true,
+			inputYAML: `
+url: example.com/foo4
+transport:
+  max_idle_conns_per_host: 10
+`,
+			validator: func(t *testing.T, o *OldConfig) {
+				require.NotNil(t, o.Transport)
+				assert.Equal(t, 10, o.Transport.MaxIdleConnsPerHost)
+				assert.True(t, o.Transport.HTTP2)
+				assert.Equal(t, time.Second*30, o.Transport.DialTimeout)
 			},
 		},
 	}
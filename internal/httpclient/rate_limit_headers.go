@@ -0,0 +1,64 @@
+package httpclient
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Values of X-RateLimit-Reset above this are treated as a unix timestamp
+// rather than a number of seconds, as different APIs use either.
+const rateLimitResetEpochThreshold = 1_000_000_000
+
+// RateLimitDelay returns the period of time that a server has asked clients to
+// wait before performing another request, and whether such a period was found.
+//
+// A Retry-After header, which is either a number of seconds or an HTTP date,
+// takes precedence. Otherwise, when the response indicates that a rate limit
+// has been exhausted, either by a 429 status code or a remaining count of zero,
+// the reset of the rate limit is read from the X-RateLimit-Reset or
+// RateLimit-Reset headers.
+func RateLimitDelay(code int, header http.Header, now time.Time) (time.Duration, bool) {
+	if v := strings.TrimSpace(header.Get("Retry-After")); v != "" {
+		if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return nonNegative(time.Duration(secs) * time.Second), true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return nonNegative(t.Sub(now)), true
+		}
+	}
+
+	exhausted := code == http.StatusTooManyRequests
+	for _, k := range []string{"X-RateLimit-Remaining", "RateLimit-Remaining"} {
+		if strings.TrimSpace(header.Get(k)) == "0" {
+			exhausted = true
+		}
+	}
+	if !exhausted {
+		return 0, false
+	}
+
+	for _, k := range []string{"X-RateLimit-Reset", "RateLimit-Reset"} {
+		v := strings.TrimSpace(header.Get(k))
+		if v == "" {
+			continue
+		}
+		reset, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			continue
+		}
+		if reset > rateLimitResetEpochThreshold {
+			return nonNegative(time.UnixMilli(int64(reset * 1000)).Sub(now)), true
+		}
+		return nonNegative(time.Duration(reset * float64(time.Second))), true
+	}
+	return 0, false
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,110 @@
+package httpclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitDelay(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		code     int
+		header   map[string]string
+		delay    time.Duration
+		notFound bool
+	}{
+		{
+			name:     "no headers",
+			code:     200,
+			notFound: true,
+		},
+		{
+			name:   "retry after seconds",
+			code:   503,
+			header: map[string]string{"Retry-After": "120"},
+			delay:  time.Minute * 2,
+		},
+		{
+			name:   "retry after date",
+			code:   429,
+			header: map[string]string{"Retry-After": "Mon, 01 Jan 2024 12:00:30 GMT"},
+			delay:  time.Second * 30,
+		},
+		{
+			name:   "retry after date in the past",
+			code:   429,
+			header: map[string]string{"Retry-After": "Mon, 01 Jan 2024 11:00:00 GMT"},
+			delay:  0,
+		},
+		{
+			name: "retry after takes precedence",
+			code: 429,
+			header: map[string]string{
+				"Retry-After":       "5",
+				"X-RateLimit-Reset": "60",
+			},
+			delay: time.Second * 5,
+		},
+		{
+			name: "remaining limit",
+			code: 200,
+			header: map[string]string{
+				"X-RateLimit-Remaining": "10",
+				"X-RateLimit-Reset":     "60",
+			},
+			notFound: true,
+		},
+		{
+			name: "exhausted limit reset seconds",
+			code: 200,
+			header: map[string]string{
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     "60",
+			},
+			delay: time.Minute,
+		},
+		{
+			name: "exhausted limit reset timestamp",
+			code: 200,
+			header: map[string]string{
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     "1704110445",
+			},
+			delay: time.Second * 45,
+		},
+		{
+			name: "too many requests reset",
+			code: 429,
+			header: map[string]string{
+				"RateLimit-Reset": "1.5",
+			},
+			delay: time.Millisecond * 1500,
+		},
+		{
+			name: "invalid reset",
+			code: 429,
+			header: map[string]string{
+				"X-RateLimit-Reset": "nope",
+			},
+			notFound: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range test.header {
+				header.Set(k, v)
+			}
+			delay, found := RateLimitDelay(test.code, header, now)
+			assert.Equal(t, !test.notFound, found)
+			assert.Equal(t, test.delay, delay)
+		})
+	}
+}
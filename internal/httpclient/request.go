@@ -39,6 +39,7 @@ type RequestCreator struct {
 	verb             string
 	headers          map[string]*service.InterpolatedString
 	metaInsertFilter *service.MetadataFilter
+	validators       *ConditionalValidators
 }
 
 // RequestOpt represents a customisation of a request creator.
@@ -208,19 +209,45 @@ func (r *RequestCreator) body(refBatch service.MessageBatch) (body io.Reader, ov
 	return
 }
 
+// RequestOverrides describes parts of a request that should be set explicitly
+// rather than derived from the config of a request creator.
+type RequestOverrides struct {
+	// URL replaces the URL of the request when not empty.
+	URL string
+
+	// Headers are set on the request after the configured headers.
+	Headers map[string]string
+
+	// Body replaces the body of the request when not nil.
+	Body []byte
+}
+
 // Create an *http.Request using a reference message batch to extract the body
 // and headers of the request. It's possible that the creator has been given
 // explicit overrides for the body, in which case the reference batch is only
 // used for general request headers/metadata enrichment.
 func (r *RequestCreator) Create(refBatch service.MessageBatch) (req *http.Request, err error) {
+	return r.CreateWithOverrides(refBatch, nil)
+}
+
+// CreateWithOverrides creates an *http.Request in the same way as Create, but
+// with parts of the request optionally replaced by explicit overrides.
+func (r *RequestCreator) CreateWithOverrides(refBatch service.MessageBatch, overrides *RequestOverrides) (req *http.Request, err error) {
 	var overrideContentType string
 	var body io.Reader
-	if body, overrideContentType, err = r.body(refBatch); err != nil {
+	if overrides != nil && overrides.Body != nil {
+		body = bytes.NewReader(overrides.Body)
+		if _, exists := r.headers["Content-Type"]; !exists {
+			overrideContentType = "application/octet-stream"
+		}
+	} else if body, overrideContentType, err = r.body(refBatch); err != nil {
 		return
 	}
 
 	var urlStr string
-	if urlStr, err = refBatch.TryInterpolatedString(0, r.url); err != nil {
+	if overrides != nil && overrides.URL != "" {
+		urlStr = overrides.URL
+	} else if urlStr, err = refBatch.TryInterpolatedString(0, r.url); err != nil {
 		err = fmt.Errorf("url interpolation error: %w", err)
 		return
 	}
@@ -253,6 +280,18 @@ func (r *RequestCreator) Create(refBatch service.MessageBatch) (req *http.Reques
 		req.Header.Del("Content-Type")
 		req.Header.Add("Content-Type", overrideContentType)
 	}
+	if overrides != nil {
+		for k, v := range overrides.Headers {
+			if strings.EqualFold(k, "host") {
+				req.Host = v
+				continue
+			}
+			req.Header.Set(k, v)
+		}
+	}
+	if r.validators != nil {
+		r.validators.apply(req)
+	}
 
 	err = r.reqSigner(r.fs, req)
 	return
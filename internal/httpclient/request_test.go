@@ -1,6 +1,7 @@
 package httpclient
 
 import (
+	"io"
 	"testing"
 
 	"github.com/redpanda-data/benthos/v4/public/service"
@@ -39,3 +40,46 @@ metadata:
 	assert.Equal(t, []string{"barvalue"}, req.Header.Values("more_bar"))
 	assert.Equal(t, []string(nil), req.Header.Values("ignore_baz"))
 }
+
+func TestRequestOverrides(t *testing.T) {
+	spec := service.NewConfigSpec().Field(ConfigField("POST", false))
+	parsed, err := spec.ParseYAML(`
+url: http://example.com/foo
+headers:
+  "X-Foo": "foo"
+  "X-Bar": "bar"
+`, nil)
+	require.NoError(t, err)
+
+	oldConf, err := ConfigFromParsed(parsed)
+	require.NoError(t, err)
+
+	reqCreator, err := RequestCreatorFromOldConfig(oldConf, service.MockResources())
+	require.NoError(t, err)
+
+	b := service.MessageBatch{service.NewMessage([]byte("hello world"))}
+
+	req, err := reqCreator.CreateWithOverrides(b, &RequestOverrides{
+		URL:     "http://example.com/bar?page=2",
+		Headers: map[string]string{"X-Bar": "baz"},
+		Body:    []byte("overridden"),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://example.com/bar?page=2", req.URL.String())
+	assert.Equal(t, "foo", req.Header.Get("X-Foo"))
+	assert.Equal(t, []string{"baz"}, req.Header.Values("X-Bar"))
+
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "overridden", string(body))
+
+	// Empty overrides are derived from the config.
+	req, err = reqCreator.CreateWithOverrides(b, &RequestOverrides{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://example.com/foo", req.URL.String())
+	body, err = io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(body))
+}
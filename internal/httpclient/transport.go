@@ -0,0 +1,137 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+const (
+	hcTransportFieldMaxIdleConnsPerHost = "max_idle_conns_per_host"
+	hcTransportFieldHTTP2               = "http2"
+	hcTransportFieldDialTimeout         = "dial_timeout"
+	hcTransportFieldTLSHandshakeTimeout = "tls_handshake_timeout"
+)
+
+func transportField() *service.ConfigField {
+	return service.NewObjectField(hcFieldTransport,
+		service.NewIntField(hcTransportFieldMaxIdleConnsPerHost).
+			Description("The maximum number of idle connections to keep open for reuse per host. When set to zero the default of two connections is used, which is often too low for high throughput outputs with a `max_in_flight` greater than two.").
+			Default(0),
+		service.NewBoolField(hcTransportFieldHTTP2).
+			Description("Whether to attempt HTTP/2 for requests to hosts that support it. When disabled all requests are made with HTTP/1.1.").
+			Default(true),
+		service.NewDurationField(hcTransportFieldDialTimeout).
+			Description("The maximum period of time to wait for a TCP connection to be established.").
+			Default("30s"),
+		service.NewDurationField(hcTransportFieldTLSHandshakeTimeout).
+			Description("The maximum period of time to wait for a TLS handshake to complete.").
+			Default("10s"),
+	).
+		Description("Customise the connection pool of the client. Each new connection increments the metric `http_request_conn_new`, and each request that reuses an idle connection increments the metric `http_request_conn_reused`.").
+		Advanced().
+		Optional().
+		Version("4.29.0")
+}
+
+// TransportConfig describes the connection pool of an HTTP client.
+type TransportConfig struct {
+	MaxIdleConnsPerHost int
+	HTTP2               bool
+	DialTimeout         time.Duration
+	TLSHandshakeTimeout time.Duration
+}
+
+func transportConfigFromParsed(pConf *service.ParsedConfig) (conf TransportConfig, err error) {
+	if conf.MaxIdleConnsPerHost, err = pConf.FieldInt(hcTransportFieldMaxIdleConnsPerHost); err != nil {
+		return
+	}
+	if conf.MaxIdleConnsPerHost < 0 {
+		err = fmt.Errorf("field '%v' must not be negative", hcTransportFieldMaxIdleConnsPerHost)
+		return
+	}
+	if conf.HTTP2, err = pConf.FieldBool(hcTransportFieldHTTP2); err != nil {
+		return
+	}
+	if conf.DialTimeout, err = pConf.FieldDuration(hcTransportFieldDialTimeout); err != nil {
+		return
+	}
+	if conf.TLSHandshakeTimeout, err = pConf.FieldDuration(hcTransportFieldTLSHandshakeTimeout); err != nil {
+		return
+	}
+	return
+}
+
+// apply configures an existing transport, or a clone of the default transport
+// when nil, with the connection pool settings.
+func (t TransportConfig) apply(rt http.RoundTripper) (*http.Transport, error) {
+	var tr *http.Transport
+	switch v := rt.(type) {
+	case nil:
+		if c, ok := http.DefaultTransport.(*http.Transport); ok {
+			tr = c.Clone()
+		} else {
+			tr = &http.Transport{}
+		}
+	case *http.Transport:
+		tr = v
+	default:
+		return nil, fmt.Errorf("unable to apply transport settings, unexpected type %T", rt)
+	}
+
+	if t.MaxIdleConnsPerHost > 0 {
+		tr.MaxIdleConnsPerHost = t.MaxIdleConnsPerHost
+		if tr.MaxIdleConns > 0 && tr.MaxIdleConns < t.MaxIdleConnsPerHost {
+			tr.MaxIdleConns = t.MaxIdleConnsPerHost
+		}
+	}
+
+	tr.DialContext = (&net.Dialer{
+		Timeout:   t.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	tr.TLSHandshakeTimeout = t.TLSHandshakeTimeout
+
+	tr.ForceAttemptHTTP2 = t.HTTP2
+	if !t.HTTP2 {
+		// A non-nil empty map disables the automatic upgrade to HTTP/2.
+		tr.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return tr, nil
+}
+
+//------------------------------------------------------------------------------
+
+// connMetricsTransport records whether each request was made over a new or a
+// reused connection.
+type connMetricsTransport struct {
+	next    http.RoundTripper
+	mNew    *service.MetricCounter
+	mReused *service.MetricCounter
+}
+
+func newConnMetricsTransport(next http.RoundTripper, mgr *service.Resources) http.RoundTripper {
+	return &connMetricsTransport{
+		next:    next,
+		mNew:    mgr.Metrics().NewCounter("http_request_conn_new"),
+		mReused: mgr.Metrics().NewCounter("http_request_conn_reused"),
+	}
+}
+
+func (c *connMetricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				c.mReused.Incr(1)
+			} else {
+				c.mNew.Incr(1)
+			}
+		},
+	}
+	return c.next.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+}
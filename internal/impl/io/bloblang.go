@@ -1,7 +1,10 @@
 package io
 
 import (
+	"fmt"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
 	"github.com/redpanda-data/benthos/v4/public/bloblang"
@@ -132,6 +135,56 @@ func init() {
 		panic(err)
 	}
 
+	if err := bloblang.RegisterFunctionV2("file_cached",
+		bloblang.NewPluginSpec().
+			Impure().
+			Category(query.FunctionCategoryEnvironment).
+			Description("Reads a file and returns its contents, where the contents are cached and shared by all invocations of the function within a mapping. The file is read again whenever its modification time or size changes, and also when the cached contents are older than an optional TTL. This makes it suitable for reading lookup tables within mappings that are executed frequently, where the file may be updated during runtime. Relative paths are resolved from the directory of the process executing the mapping.").
+			Version("4.29.0").
+			Param(bloblang.NewStringParam("path").
+				Description("The path of the target file.")).
+			Param(bloblang.NewStringParam("ttl").
+				Description("An optional duration after which the file is read again even when it appears unchanged, such as `5m`. When empty the file is only read again when it changes.").
+				Default("")).
+			Example("", `root.region = file_cached("./regions.json").parse_json().get(this.country)`).
+			Example("", `root.blocked = file_cached(path: "./blocklist.txt", ttl: "1m").string().split("\n").contains(this.user)`).
+			State(
+				func() (any, error) {
+					return &cachedFiles{files: map[string]*cachedFile{}}, nil
+				},
+				nil,
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Function, error) {
+			path, err := args.GetString("path")
+			if err != nil {
+				return nil, err
+			}
+
+			ttlStr, err := args.GetString("ttl")
+			if err != nil {
+				return nil, err
+			}
+			var ttl time.Duration
+			if ttlStr != "" {
+				if ttl, err = time.ParseDuration(ttlStr); err != nil {
+					return nil, fmt.Errorf("failed to parse ttl: %w", err)
+				}
+			}
+
+			state, err := args.State()
+			if err != nil {
+				return nil, err
+			}
+			file := state.(*cachedFiles).get(path)
+
+			return func() (any, error) {
+				return file.read(ttl)
+			}, nil
+		},
+	); err != nil {
+		panic(err)
+	}
+
 	if err := bloblang.RegisterFunctionV2("file_rel",
 		bloblang.NewPluginSpec().
 			Impure().
@@ -184,3 +237,58 @@ func init() {
 		panic(err)
 	}
 }
+
+// cachedFiles holds the files read by a mapping, keyed by their path.
+type cachedFiles struct {
+	mut   sync.Mutex
+	files map[string]*cachedFile
+}
+
+func (f *cachedFiles) get(path string) *cachedFile {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	c, exists := f.files[path]
+	if !exists {
+		c = &cachedFile{path: path}
+		f.files[path] = c
+	}
+	return c
+}
+
+type cachedFile struct {
+	path string
+
+	mut      sync.Mutex
+	contents []byte
+	modTime  time.Time
+	size     int64
+	readAt   time.Time
+}
+
+// read returns the contents of the file, reading it again when it has been
+// modified since it was last read, or when the cached contents have exceeded
+// a TTL.
+func (c *cachedFile) read(ttl time.Duration) ([]byte, error) {
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.contents != nil &&
+		info.ModTime().Equal(c.modTime) &&
+		info.Size() == c.size &&
+		(ttl <= 0 || time.Since(c.readAt) < ttl) {
+		return c.contents, nil
+	}
+
+	contents, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil, err
+	}
+	c.contents, c.modTime, c.size, c.readAt = contents, info.ModTime(), info.Size(), time.Now()
+	return contents, nil
+}
@@ -0,0 +1,214 @@
+package io
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/internal/mmdb"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+// geoipDatabase is an opened database that is reloaded whenever the file it
+// was read from changes.
+type geoipDatabase struct {
+	reader  atomic.Pointer[mmdb.Reader]
+	watcher *fsnotify.Watcher
+}
+
+func openGeoIPDatabase(path string) (*geoipDatabase, error) {
+	reader, err := mmdb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	db := &geoipDatabase{}
+	db.reader.Store(reader)
+
+	// The directory is watched rather than the file itself so that files
+	// replaced by renaming a new version over them are also detected.
+	if db.watcher, err = fsnotify.NewWatcher(); err != nil {
+		return nil, err
+	}
+	if err := db.watcher.Add(filepath.Dir(path)); err != nil {
+		_ = db.watcher.Close()
+		return nil, err
+	}
+
+	go func() {
+		for {
+			select {
+			case event, open := <-db.watcher.Events:
+				if !open {
+					return
+				}
+				if filepath.Clean(event.Name) != path || !event.Has(fsnotify.Write|fsnotify.Create) {
+					continue
+				}
+				// Files that fail to parse, which could be due to a write
+				// being in progress, are ignored and the previous version of
+				// the database remains in use.
+				if r, err := mmdb.Open(path); err == nil {
+					db.reader.Store(r)
+				}
+			case _, open := <-db.watcher.Errors:
+				if !open {
+					return
+				}
+			}
+		}
+	}()
+	return db, nil
+}
+
+// geoipDatabases holds the databases opened by a mapping, keyed by their path.
+type geoipDatabases struct {
+	mut sync.Mutex
+	dbs map[string]*geoipDatabase
+}
+
+func (g *geoipDatabases) get(path string) (*geoipDatabase, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mut.Lock()
+	defer g.mut.Unlock()
+
+	if db, exists := g.dbs[path]; exists {
+		return db, nil
+	}
+	db, err := openGeoIPDatabase(path)
+	if err != nil {
+		return nil, err
+	}
+	g.dbs[path] = db
+	return db, nil
+}
+
+func (g *geoipDatabases) close() error {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+
+	var errs []error
+	for _, db := range g.dbs {
+		errs = append(errs, db.watcher.Close())
+	}
+	g.dbs = map[string]*geoipDatabase{}
+	return errors.Join(errs...)
+}
+
+func geoipEnglishName(v any) (string, bool) {
+	obj, _ := v.(map[string]any)
+	names, _ := obj["names"].(map[string]any)
+	name, ok := names["en"].(string)
+	return name, ok
+}
+
+// geoipResult extracts the country, city, location and ASN details of a
+// record into a consistent structure, omitting any details that are not
+// present.
+func geoipResult(record any) map[string]any {
+	rec, _ := record.(map[string]any)
+	res := map[string]any{}
+
+	country, _ := rec["country"].(map[string]any)
+	if country == nil {
+		country, _ = rec["registered_country"].(map[string]any)
+	}
+	if country != nil {
+		c := map[string]any{}
+		if isoCode, ok := country["iso_code"].(string); ok {
+			c["iso_code"] = isoCode
+		}
+		if name, ok := geoipEnglishName(country); ok {
+			c["name"] = name
+		}
+		res["country"] = c
+	}
+
+	if name, ok := geoipEnglishName(rec["city"]); ok {
+		res["city"] = map[string]any{"name": name}
+	}
+
+	if location, ok := rec["location"].(map[string]any); ok {
+		l := map[string]any{}
+		for _, k := range []string{"latitude", "longitude", "time_zone"} {
+			if v, exists := location[k]; exists {
+				l[k] = v
+			}
+		}
+		res["location"] = l
+	}
+
+	if number, exists := rec["autonomous_system_number"]; exists {
+		asn := map[string]any{"number": number}
+		if org, exists := rec["autonomous_system_organization"]; exists {
+			asn["organization"] = org
+		}
+		res["asn"] = asn
+	}
+	return res
+}
+
+func init() {
+	if err := bloblang.RegisterFunctionV2("geoip",
+		bloblang.NewPluginSpec().
+			Impure().
+			Category(query.FunctionCategoryEnvironment).
+			Description("Looks up an IP address within a https://maxmind.github.io/MaxMind-DB/[MaxMind DB^] format database, such as the GeoIP2 and GeoLite2 databases, and returns an object containing the `country`, `city`, `location` and `asn` details of the address, where details that are not provided by the database are omitted. Returns `null` if the address is not found within the database.\n\nEach database is opened once per mapping, and is reloaded automatically whenever the file is modified or replaced.").
+			Version("4.29.0").
+			Param(bloblang.NewStringParam("ip").Description("The IPv4 or IPv6 address to look up.")).
+			Param(bloblang.NewStringParam("database").Description("The path of the database file.")).
+			Example("", `root.geo = geoip(ip: this.client_ip, database: "./GeoLite2-City.mmdb")`).
+			Example("Multiple databases can be combined in order to obtain both location and ASN details.", `root.geo = geoip(this.client_ip, "./GeoLite2-City.mmdb").merge(geoip(this.client_ip, "./GeoLite2-ASN.mmdb"))`).
+			State(
+				func() (any, error) {
+					return &geoipDatabases{dbs: map[string]*geoipDatabase{}}, nil
+				},
+				func(ctx context.Context, state any) error {
+					return state.(*geoipDatabases).close()
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Function, error) {
+			ipStr, err := args.GetString("ip")
+			if err != nil {
+				return nil, err
+			}
+			addr, err := netip.ParseAddr(ipStr)
+			if err != nil {
+				return nil, err
+			}
+
+			path, err := args.GetString("database")
+			if err != nil {
+				return nil, err
+			}
+			state, err := args.State()
+			if err != nil {
+				return nil, err
+			}
+			db, err := state.(*geoipDatabases).get(path)
+			if err != nil {
+				return nil, err
+			}
+
+			return func() (any, error) {
+				record, found, err := db.reader.Load().Lookup(addr)
+				if err != nil || !found {
+					return nil, err
+				}
+				return geoipResult(record), nil
+			}, nil
+		},
+	); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,141 @@
+package io_test
+
+import (
+	"context"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/mmdb/mmdbtest"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+func writeGeoIPTestDB(t testing.TB, path string, records map[string]any) {
+	t.Helper()
+
+	w, err := mmdbtest.NewWriter(6, 28, "GeoIP2-City")
+	require.NoError(t, err)
+	for prefix, v := range records {
+		require.NoError(t, w.Insert(netip.MustParsePrefix(prefix), v))
+	}
+	b, err := w.Bytes()
+	require.NoError(t, err)
+
+	tmpPath := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmpPath, b, 0o644))
+	require.NoError(t, os.Rename(tmpPath, path))
+}
+
+func TestGeoIPFunction(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "city.mmdb")
+	writeGeoIPTestDB(t, dbPath, map[string]any{
+		"81.2.69.0/24": map[string]any{
+			"city": map[string]any{
+				"names": map[string]any{"en": "London", "de": "London"},
+			},
+			"country": map[string]any{
+				"iso_code": "GB",
+				"names":    map[string]any{"en": "United Kingdom"},
+			},
+			"location": map[string]any{
+				"latitude":  51.5142,
+				"longitude": -0.0931,
+				"time_zone": "Europe/London",
+			},
+		},
+		"2001:db8::/32": map[string]any{
+			"autonomous_system_number":       uint64(64496),
+			"autonomous_system_organization": "Example Networks",
+		},
+	})
+
+	exe, err := bloblang.Parse(`root = geoip(this.ip, "` + dbPath + `")`)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, exe.Close(context.Background()))
+	})
+
+	res, err := exe.Query(map[string]any{"ip": "81.2.69.160"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"city": map[string]any{"name": "London"},
+		"country": map[string]any{
+			"iso_code": "GB",
+			"name":     "United Kingdom",
+		},
+		"location": map[string]any{
+			"latitude":  51.5142,
+			"longitude": -0.0931,
+			"time_zone": "Europe/London",
+		},
+	}, res)
+
+	res, err = exe.Query(map[string]any{"ip": "2001:db8::1"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"asn": map[string]any{
+			"number":       int64(64496),
+			"organization": "Example Networks",
+		},
+	}, res)
+
+	res, err = exe.Query(map[string]any{"ip": "10.0.0.1"})
+	require.NoError(t, err)
+	assert.Nil(t, res)
+
+	_, err = exe.Query(map[string]any{"ip": "not an ip"})
+	require.Error(t, err)
+}
+
+func TestGeoIPFunctionMissingDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "missing.mmdb")
+
+	exe, err := bloblang.Parse(`root = geoip(this.ip, "` + dbPath + `")`)
+	require.NoError(t, err)
+
+	_, err = exe.Query(map[string]any{"ip": "81.2.69.160"})
+	require.Error(t, err)
+}
+
+func TestGeoIPFunctionReload(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "city.mmdb")
+	writeGeoIPTestDB(t, dbPath, map[string]any{
+		"81.2.69.0/24": map[string]any{
+			"country": map[string]any{"iso_code": "GB"},
+		},
+	})
+
+	exe, err := bloblang.Parse(`root = geoip(this.ip, "` + dbPath + `").country.iso_code`)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, exe.Close(context.Background()))
+	})
+
+	res, err := exe.Query(map[string]any{"ip": "81.2.69.160"})
+	require.NoError(t, err)
+	assert.Equal(t, "GB", res)
+
+	writeGeoIPTestDB(t, dbPath, map[string]any{
+		"81.2.69.0/24": map[string]any{
+			"country": map[string]any{"iso_code": "IE"},
+		},
+	})
+
+	assert.Eventually(t, func() bool {
+		res, err := exe.Query(map[string]any{"ip": "81.2.69.160"})
+		return err == nil && res == "IE"
+	}, time.Second*5, time.Millisecond*50)
+
+	// Writing an invalid file keeps the previous database in use.
+	require.NoError(t, os.WriteFile(dbPath, []byte("nope"), 0o644))
+	time.Sleep(time.Millisecond * 100)
+
+	res, err = exe.Query(map[string]any{"ip": "81.2.69.160"})
+	require.NoError(t, err)
+	assert.Equal(t, "IE", res)
+}
@@ -4,12 +4,14 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
 	"github.com/redpanda-data/benthos/v4/internal/value"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
 )
 
 func TestEnvFunctionCaching(t *testing.T) {
@@ -116,3 +118,53 @@ func TestFileRelFunctionCaching(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "hello world 456", value.IToString(res))
 }
+
+func TestFileCachedFunction(t *testing.T) {
+	tmpDir := t.TempDir()
+	fooFile := filepath.Join(tmpDir, "foo.txt")
+
+	require.NoError(t, os.WriteFile(fooFile, []byte("hello world 123"), 0o644))
+	modTime := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(fooFile, modTime, modTime))
+
+	exe, err := bloblang.Parse(`root = file_cached(this.path).string()`)
+	require.NoError(t, err)
+
+	exeTTL, err := bloblang.Parse(`root = file_cached(path: this.path, ttl: "10ms").string()`)
+	require.NoError(t, err)
+
+	readFile := func(exe *bloblang.Executor) any {
+		t.Helper()
+		res, err := exe.Query(map[string]any{"path": fooFile})
+		require.NoError(t, err)
+		return res
+	}
+
+	assert.Equal(t, "hello world 123", readFile(exe))
+	assert.Equal(t, "hello world 123", readFile(exeTTL))
+
+	// Contents are cached while the file appears unchanged.
+	require.NoError(t, os.WriteFile(fooFile, []byte("hello world 456"), 0o644))
+	require.NoError(t, os.Chtimes(fooFile, modTime, modTime))
+
+	assert.Equal(t, "hello world 123", readFile(exe))
+
+	// Unless the TTL has been exceeded.
+	time.Sleep(time.Millisecond * 20)
+	assert.Equal(t, "hello world 456", readFile(exeTTL))
+
+	// Changes to the modification time cause the file to be read again.
+	modTime = modTime.Add(time.Minute)
+	require.NoError(t, os.Chtimes(fooFile, modTime, modTime))
+
+	assert.Equal(t, "hello world 456", readFile(exe))
+
+	require.NoError(t, os.Remove(fooFile))
+	_, err = exe.Query(map[string]any{"path": fooFile})
+	require.Error(t, err)
+}
+
+func TestFileCachedFunctionBadTTL(t *testing.T) {
+	_, err := bloblang.Parse(`root = file_cached(path: "./foo.txt", ttl: "nope")`)
+	require.ErrorContains(t, err, "failed to parse ttl")
+}
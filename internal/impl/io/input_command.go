@@ -0,0 +1,231 @@
+package io
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+const (
+	ciFieldName     = "name"
+	ciFieldArgs     = "args"
+	ciFieldSchedule = "schedule"
+	ciFieldTimeout  = "timeout"
+	ciFieldScanner  = "scanner"
+)
+
+func commandInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("4.29.0").
+		Categories("Utility").
+		Summary("Executes a command on a cron schedule and consumes its stdout as messages.").
+		Description(`
+Each time the schedule triggers the command is executed to completion, and its stdout is broken out into messages according to the specified scanner. The command is not executed again until all messages of the previous execution have been consumed, and any executions that are due in the meantime are skipped.
+
+This input is suited to polling tools that print their results and exit. In order to consume from a command that runs continuously use the xref:components:inputs/subprocess.adoc[`+"`subprocess`"+` input] instead.
+
+The execution environment of the command is the same as the Benthos instance, including environment variables and the current working directory.
+
+== Metadata
+
+This input adds the following metadata fields to each message:
+
+- command_exit_code
+- command_duration
+- command_stderr
+
+The field `+"`command_stderr`"+` is only added when the command writes to stderr. A command that exits with a non-zero status is logged, but the messages of its stdout are consumed all the same, and can therefore be routed according to `+"`command_exit_code`"+`.`).
+		Fields(
+			service.NewStringField(ciFieldName).
+				Description("The command to execute.").
+				Examples("df", "kubectl"),
+			service.NewStringListField(ciFieldArgs).
+				Description("A list of arguments to provide the command.").
+				Default([]any{}),
+			service.NewCronField(ciFieldSchedule).
+				Description("A cron expression that schedules each execution of the command. Expressions can specify a timezone by prefixing the expression with `TZ=<location name>`, where the location name corresponds to a file within the IANA Time Zone database, otherwise UTC is used.").
+				Examples("@every 5m", "0 */10 * * * *", "TZ=Europe/London 30 3 * * *"),
+			service.NewDurationField(ciFieldTimeout).
+				Description("An optional maximum period of time to wait for each execution of the command, after which it is killed and any stdout written so far is consumed.").
+				Advanced().
+				Optional(),
+			service.NewScannerField(ciFieldScanner).
+				Description("The xref:components:scanners/about.adoc[scanner] by which the stdout of each execution will be broken out into individual messages.").
+				Default(map[string]any{"to_the_end": map[string]any{}}),
+			service.NewAutoRetryNacksToggleField(),
+		).
+		Example(
+			"Poll Disk Usage",
+			"Consume the output of `df` every ten minutes as a message per line:",
+			`
+input:
+  command:
+    name: df
+    args: [ "-h" ]
+    schedule: '@every 10m'
+    scanner:
+      lines: {}
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterBatchInput("command", commandInputSpec(), func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+		rdr, err := newCommandInputFromParsed(conf, mgr)
+		if err != nil {
+			return nil, err
+		}
+		return service.AutoRetryNacksBatchedToggled(conf, rdr)
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// commandExecution is the result of an execution of the command, the stdout
+// of which is consumed via a scanner.
+type commandExecution struct {
+	scanner  *service.OwnedScanner
+	exitCode int
+	duration time.Duration
+	stderr   string
+}
+
+type commandInput struct {
+	name        string
+	args        []string
+	schedule    cron.Schedule
+	timeout     time.Duration
+	scannerCtor *service.OwnedScannerCreator
+
+	exec *commandExecution
+
+	log *service.Logger
+}
+
+func newCommandInputFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (c *commandInput, err error) {
+	c = &commandInput{log: mgr.Logger()}
+
+	if c.name, err = conf.FieldString(ciFieldName); err != nil {
+		return
+	}
+	if c.args, err = conf.FieldStringList(ciFieldArgs); err != nil {
+		return
+	}
+	if c.schedule, err = conf.FieldCronSchedule(ciFieldSchedule); err != nil {
+		return
+	}
+	if conf.Contains(ciFieldTimeout) {
+		if c.timeout, err = conf.FieldDuration(ciFieldTimeout); err != nil {
+			return
+		}
+	}
+	if c.scannerCtor, err = conf.FieldScanner(ciFieldScanner); err != nil {
+		return
+	}
+	return c, nil
+}
+
+func (c *commandInput) Connect(ctx context.Context) error {
+	return nil
+}
+
+// execute waits until the next scheduled time and then runs the command to
+// completion.
+func (c *commandInput) execute(ctx context.Context) (*commandExecution, error) {
+	now := time.Now()
+	select {
+	case <-time.After(c.schedule.Next(now).Sub(now)):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	runCtx := ctx
+	if c.timeout > 0 {
+		var done func()
+		runCtx, done = context.WithTimeout(ctx, c.timeout)
+		defer done()
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(runCtx, c.name, c.args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	started := time.Now()
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, err
+	}
+
+	e := &commandExecution{
+		exitCode: cmd.ProcessState.ExitCode(),
+		duration: time.Since(started),
+		stderr:   stderr.String(),
+	}
+	if err != nil {
+		c.log.Warnf("Command exited with a non-zero status: %v: %s", err, stderr.Bytes())
+	}
+
+	if e.scanner, err = c.scannerCtor.Create(io.NopCloser(&stdout), func(context.Context, error) error {
+		return nil
+	}, service.NewScannerSourceDetails()); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (c *commandInput) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	for {
+		if c.exec == nil {
+			e, err := c.execute(ctx)
+			if err != nil {
+				return nil, nil, err
+			}
+			c.exec = e
+		}
+
+		batch, aFn, err := c.exec.scanner.NextBatch(ctx)
+		if err != nil {
+			_ = c.exec.scanner.Close(ctx)
+			c.exec = nil
+			if errors.Is(err, io.EOF) {
+				continue
+			}
+			return nil, nil, err
+		}
+		_ = aFn(ctx, nil)
+
+		for _, msg := range batch {
+			msg.MetaSetMut("command_exit_code", c.exec.exitCode)
+			msg.MetaSetMut("command_duration", c.exec.duration.String())
+			if c.exec.stderr != "" {
+				msg.MetaSetMut("command_stderr", c.exec.stderr)
+			}
+		}
+		return batch, func(context.Context, error) error {
+			return nil
+		}, nil
+	}
+}
+
+func (c *commandInput) Close(ctx context.Context) error {
+	if c.exec != nil {
+		_ = c.exec.scanner.Close(ctx)
+		c.exec = nil
+	}
+	return c.scannerCtor.Close(ctx)
+}
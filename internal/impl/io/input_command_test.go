@@ -0,0 +1,114 @@
+package io
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+func testCommandInput(t *testing.T, conf string) *commandInput {
+	t.Helper()
+
+	pConf, err := commandInputSpec().ParseYAML(conf, nil)
+	require.NoError(t, err)
+
+	c, err := newCommandInputFromParsed(pConf, service.MockResources())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = c.Close(context.Background())
+	})
+	return c
+}
+
+func TestCommandInput(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	c := testCommandInput(t, `
+name: sh
+args: [ "-c", "echo foo && echo bar && echo baz >&2 && exit 3" ]
+schedule: '* * * * * *'
+scanner:
+  lines: {}
+`)
+	require.NoError(t, c.Connect(tCtx))
+
+	for i := 0; i < 2; i++ {
+		for _, exp := range []string{"foo", "bar"} {
+			batch, aFn, err := c.ReadBatch(tCtx)
+			require.NoError(t, err)
+			require.Len(t, batch, 1)
+
+			mBytes, err := batch[0].AsBytes()
+			require.NoError(t, err)
+			assert.Equal(t, exp, string(mBytes))
+
+			exitCode, _ := batch[0].MetaGetMut("command_exit_code")
+			assert.Equal(t, 3, exitCode)
+
+			stderr, _ := batch[0].MetaGet("command_stderr")
+			assert.Equal(t, "baz\n", stderr)
+
+			_, exists := batch[0].MetaGet("command_duration")
+			assert.True(t, exists)
+
+			require.NoError(t, aFn(tCtx, nil))
+		}
+	}
+}
+
+func TestCommandInputTimeout(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	c := testCommandInput(t, `
+name: sh
+args: [ "-c", "echo foo && exec sleep 10" ]
+schedule: '@every 1s'
+timeout: 100ms
+`)
+	require.NoError(t, c.Connect(tCtx))
+
+	start := time.Now()
+	batch, _, err := c.ReadBatch(tCtx)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	assert.Less(t, time.Since(start), time.Second*5)
+
+	mBytes, err := batch[0].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, "foo\n", string(mBytes))
+
+	exitCode, _ := batch[0].MetaGetMut("command_exit_code")
+	assert.Equal(t, -1, exitCode)
+}
+
+func TestCommandInputShutdown(t *testing.T) {
+	c := testCommandInput(t, `
+name: echo
+schedule: '@daily'
+`)
+
+	tCtx, done := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer done()
+
+	_, _, err := c.ReadBatch(tCtx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestCommandInputBadSchedule(t *testing.T) {
+	pConf, err := commandInputSpec().ParseYAML(`
+name: echo
+schedule: 'not a schedule'
+`, nil)
+	require.NoError(t, err)
+
+	_, err = newCommandInputFromParsed(pConf, service.MockResources())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse field 'schedule' as a cron expression")
+}
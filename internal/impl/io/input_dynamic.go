@@ -2,6 +2,7 @@ package io
 
 import (
 	"context"
+	"fmt"
 	"path"
 	"sync"
 
@@ -46,7 +47,18 @@ Stops and removes an input.
 
 === GET `+"`/inputs/\\{id}/uptime`"+`
 
-Returns the uptime of an input as a duration string (of the form "72h3m0.5s"), or "stopped" in the case where the input has gracefully terminated.`).
+Returns the uptime of an input as a duration string (of the form "72h3m0.5s"), or "stopped" in the case where the input has gracefully terminated.
+
+== Persistence
+
+By default inputs that are created, changed or removed via the HTTP interface are forgotten when the process restarts. With the `+"`persistence`"+` field set these changes are stored within either a file or a cache resource and are restored the next time the input is created, where persisted inputs take precedence over statically configured inputs of the same label, and statically configured inputs that were removed remain removed.
+
+`+"```yaml"+`
+input:
+  dynamic:
+    persistence:
+      file: ./dynamic_inputs.yaml
+`+"```"+``).
 		Fields(
 			service.NewInputMapField(diFieldInputs).
 				Description("A map of inputs to statically create.").
@@ -54,6 +66,7 @@ Returns the uptime of an input as a duration string (of the form "72h3m0.5s"), o
 			service.NewStringField(diFieldPrefix).
 				Description("A path prefix for HTTP endpoints that are registered.").
 				Default(""),
+			dynInputPersistenceField(),
 		)
 }
 
@@ -104,6 +117,29 @@ func newDynamicInputFromParsed(conf *service.ParsedConfig, res *service.Resource
 		return nil, err
 	}
 
+	mgr := interop.UnwrapManagement(res)
+
+	persist, err := dynInputPersistenceFromParsed(conf, mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	newInput := func(id string, c []byte) (input.Streamed, input.Config, error) {
+		confNode, err := docs.UnmarshalYAML(c)
+		if err != nil {
+			return nil, input.Config{}, err
+		}
+
+		newConf, err := input.FromAny(bundle.GlobalEnvironment, confNode)
+		if err != nil {
+			return nil, input.Config{}, err
+		}
+
+		iMgr := mgr.IntoPath("dynamic", "inputs", id)
+		i, err := iMgr.NewInput(newConf)
+		return i, newConf, err
+	}
+
 	inputs := map[string]input.Streamed{}
 	for k, v := range inputsMap {
 		inputs[k] = interop.UnwrapOwnedInput(v)
@@ -116,8 +152,36 @@ func newDynamicInputFromParsed(conf *service.ParsedConfig, res *service.Resource
 		inputYAMLConfs[k] = dynInputAnyToYAMLConf(a)
 	}
 
+	if persist != nil {
+		state, err := persist.load(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load persisted inputs: %w", err)
+		}
+		for _, id := range state.Removed {
+			if i, exists := inputs[id]; exists {
+				i.TriggerCloseNow()
+				delete(inputs, id)
+				delete(inputYAMLConfs, id)
+			}
+		}
+		for id, node := range state.Inputs {
+			confBytes, err := yaml.Marshal(&node)
+			if err != nil {
+				return nil, fmt.Errorf("failed to restore persisted input '%v': %w", id, err)
+			}
+			i, newConf, err := newInput(id, confBytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to restore persisted input '%v': %w", id, err)
+			}
+			if existing, exists := inputs[id]; exists {
+				existing.TriggerCloseNow()
+			}
+			inputs[id] = i
+			inputYAMLConfs[id] = dynInputAnyToYAMLConf(newConf)
+		}
+	}
+
 	dynAPI := api.NewDynamic()
-	mgr := interop.UnwrapManagement(res)
 	fanIn, err := newDynamicFanInInput(
 		inputs, mgr.Logger(),
 		func(ctx context.Context, l string) {
@@ -141,18 +205,7 @@ func newDynamicInputFromParsed(conf *service.ParsedConfig, res *service.Resource
 	}
 
 	dynAPI.OnUpdate(func(ctx context.Context, id string, c []byte) error {
-		confNode, err := docs.UnmarshalYAML(c)
-		if err != nil {
-			return err
-		}
-
-		newConf, err := input.FromAny(bundle.GlobalEnvironment, confNode)
-		if err != nil {
-			return err
-		}
-
-		iMgr := mgr.IntoPath("dynamic", "inputs", id)
-		newInput, err := iMgr.NewInput(newConf)
+		newInput, newConf, err := newInput(id, c)
 		if err != nil {
 			return err
 		}
@@ -165,6 +218,12 @@ func newDynamicInputFromParsed(conf *service.ParsedConfig, res *service.Resource
 			inputConfigsMut.Lock()
 			delete(inputYAMLConfs, id)
 			inputConfigsMut.Unlock()
+			return err
+		}
+		if persist != nil {
+			if err = persist.setInput(ctx, id, c); err != nil {
+				mgr.Logger().Error("Failed to persist input '%v': %v", id, err)
+			}
 		}
 		return err
 	})
@@ -173,6 +232,12 @@ func newDynamicInputFromParsed(conf *service.ParsedConfig, res *service.Resource
 		err := fanIn.SetInput(ctx, id, nil)
 		if err != nil {
 			mgr.Logger().Error("Failed to close input '%v': %v", id, err)
+			return err
+		}
+		if persist != nil {
+			if err = persist.removeInput(ctx, id); err != nil {
+				mgr.Logger().Error("Failed to persist removal of input '%v': %v", id, err)
+			}
 		}
 		return err
 	})
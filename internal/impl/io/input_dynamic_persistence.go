@@ -0,0 +1,187 @@
+package io
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/redpanda-data/benthos/v4/internal/bundle"
+	"github.com/redpanda-data/benthos/v4/internal/component"
+	"github.com/redpanda-data/benthos/v4/internal/component/cache"
+	"github.com/redpanda-data/benthos/v4/internal/docs"
+	"github.com/redpanda-data/benthos/v4/internal/filepath/ifs"
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+const (
+	diFieldPersistence      = "persistence"
+	diFieldPersistenceFile  = "file"
+	diFieldPersistenceCache = "cache"
+	diFieldPersistenceKey   = "key"
+)
+
+func dynInputPersistenceField() *service.ConfigField {
+	return service.NewObjectField(diFieldPersistence,
+		service.NewStringField(diFieldPersistenceFile).
+			Description("A path to a file where the set of inputs is persisted.").
+			Optional(),
+		service.NewStringField(diFieldPersistenceCache).
+			Description("The name of a xref:components:caches/about.adoc[cache resource] where the set of inputs is persisted.").
+			Optional(),
+		service.NewStringField(diFieldPersistenceKey).
+			Description("The key under which the set of inputs is stored within the cache resource.").
+			Default("benthos_dynamic_inputs"),
+	).
+		Description("Persist the inputs added or removed via the HTTP interface so that they are restored when the process restarts. Persistence is enabled by specifying either a `file` or a `cache`. Persisted configs are stored as they were submitted, and therefore any secrets that they contain are not scrubbed.").
+		Advanced().
+		Optional().
+		Version("4.29.0")
+}
+
+// dynInputState is the persisted record of changes made to the composition of
+// a dynamic input at runtime, where inputs that are removed are recorded so
+// that statically configured inputs can also be removed.
+type dynInputState struct {
+	Inputs  map[string]yaml.Node `yaml:"inputs,omitempty"`
+	Removed []string             `yaml:"removed,omitempty"`
+}
+
+type dynInputPersistence struct {
+	mgr bundle.NewManagement
+
+	file     string
+	cache    string
+	cacheKey string
+
+	stateMut sync.Mutex
+	state    dynInputState
+}
+
+func dynInputPersistenceFromParsed(conf *service.ParsedConfig, mgr bundle.NewManagement) (*dynInputPersistence, error) {
+	if !conf.Contains(diFieldPersistence) {
+		return nil, nil
+	}
+	conf = conf.Namespace(diFieldPersistence)
+
+	p := &dynInputPersistence{mgr: mgr}
+
+	var err error
+	if conf.Contains(diFieldPersistenceFile) {
+		if p.file, err = conf.FieldString(diFieldPersistenceFile); err != nil {
+			return nil, err
+		}
+	}
+	if conf.Contains(diFieldPersistenceCache) {
+		if p.cache, err = conf.FieldString(diFieldPersistenceCache); err != nil {
+			return nil, err
+		}
+	}
+	if p.cacheKey, err = conf.FieldString(diFieldPersistenceKey); err != nil {
+		return nil, err
+	}
+
+	if p.file == "" && p.cache == "" {
+		return nil, nil
+	}
+	if p.file != "" && p.cache != "" {
+		return nil, errors.New("persistence requires either a file or a cache, not both")
+	}
+	if p.cache != "" && !mgr.ProbeCache(p.cache) {
+		return nil, component.ErrCacheNotFound
+	}
+	return p, nil
+}
+
+// load reads the persisted state, which is empty when nothing has yet been
+// persisted.
+func (p *dynInputPersistence) load(ctx context.Context) (dynInputState, error) {
+	p.stateMut.Lock()
+	defer p.stateMut.Unlock()
+
+	var stateBytes []byte
+	var err error
+	if p.file != "" {
+		if stateBytes, err = ifs.ReadFile(p.mgr.FS(), p.file); errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+	} else {
+		if cerr := p.mgr.AccessCache(ctx, p.cache, func(c cache.V1) {
+			if stateBytes, err = c.Get(ctx, p.cacheKey); errors.Is(err, component.ErrKeyNotFound) {
+				err = nil
+			}
+		}); cerr != nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		return dynInputState{}, err
+	}
+
+	p.state = dynInputState{}
+	if len(stateBytes) > 0 {
+		if err := yaml.Unmarshal(stateBytes, &p.state); err != nil {
+			return dynInputState{}, err
+		}
+	}
+	return p.state, nil
+}
+
+func (p *dynInputPersistence) write(ctx context.Context) error {
+	stateBytes, err := yaml.Marshal(p.state)
+	if err != nil {
+		return err
+	}
+	if p.file != "" {
+		return ifs.WriteFile(p.mgr.FS(), p.file, stateBytes, 0o600)
+	}
+	if cerr := p.mgr.AccessCache(ctx, p.cache, func(c cache.V1) {
+		err = c.Set(ctx, p.cacheKey, stateBytes, nil)
+	}); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+func (p *dynInputPersistence) removedIndex(id string) int {
+	for i, r := range p.state.Removed {
+		if r == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// setInput records an input that has been created or updated.
+func (p *dynInputPersistence) setInput(ctx context.Context, id string, conf []byte) error {
+	node, err := docs.UnmarshalYAML(conf)
+	if err != nil {
+		return err
+	}
+
+	p.stateMut.Lock()
+	defer p.stateMut.Unlock()
+
+	if p.state.Inputs == nil {
+		p.state.Inputs = map[string]yaml.Node{}
+	}
+	p.state.Inputs[id] = *node
+	if i := p.removedIndex(id); i >= 0 {
+		p.state.Removed = append(p.state.Removed[:i], p.state.Removed[i+1:]...)
+	}
+	return p.write(ctx)
+}
+
+// removeInput records an input that has been removed.
+func (p *dynInputPersistence) removeInput(ctx context.Context, id string) error {
+	p.stateMut.Lock()
+	defer p.stateMut.Unlock()
+
+	delete(p.state.Inputs, id)
+	if p.removedIndex(id) == -1 {
+		p.state.Removed = append(p.state.Removed, id)
+	}
+	return p.write(ctx)
+}
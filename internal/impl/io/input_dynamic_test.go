@@ -3,8 +3,10 @@ package io_test
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/redpanda-data/benthos/v4/internal/component/input"
+	"github.com/redpanda-data/benthos/v4/internal/component/testutil"
 	bmock "github.com/redpanda-data/benthos/v4/internal/manager/mock"
 	"github.com/redpanda-data/benthos/v4/public/service"
 
@@ -212,3 +215,139 @@ processors:
 		})
 	}
 }
+
+func TestDynamicInputPersistence(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		persistConf string
+	}{
+		{
+			name:        "file",
+			persistConf: `file: ` + filepath.Join(t.TempDir(), "inputs.yaml"),
+		},
+		{
+			name:        "cache",
+			persistConf: `cache: foocache`,
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+			defer done()
+
+			mgr := bmock.NewManager()
+			mgr.Caches["foocache"] = map[string]bmock.CacheItem{}
+
+			conf, err := testutil.InputFromYAML(fmt.Sprintf(`
+dynamic:
+  inputs:
+    bar:
+      generate:
+        interval: 10ms
+        mapping: 'root.source = "bar"'
+  persistence:
+    %v
+`, test.persistConf))
+			require.NoError(t, err)
+
+			startInput := func() (input.Streamed, *mux.Router) {
+				gMux := mux.NewRouter()
+				mgr.OnRegisterEndpoint = func(path string, h http.HandlerFunc) {
+					gMux.HandleFunc(path, h)
+				}
+				i, err := mgr.NewInput(conf)
+				require.NoError(t, err)
+				return i, gMux
+			}
+
+			readSources := func(i input.Streamed, n int) map[string]int {
+				sources := map[string]int{}
+				for j := 0; j < n; j++ {
+					select {
+					case ts, open := <-i.TransactionChan():
+						require.True(t, open)
+						sources[string(ts.Payload.Get(0).AsBytes())]++
+						require.NoError(t, ts.Ack(ctx, nil))
+					case <-ctx.Done():
+						t.Fatal(ctx.Err())
+					}
+				}
+				return sources
+			}
+
+			// Transactions pending delivery block the shutdown of children and
+			// are therefore drained.
+			stopInput := func(i input.Streamed) {
+				i.TriggerStopConsuming()
+				for ts := range i.TransactionChan() {
+					require.NoError(t, ts.Ack(ctx, nil))
+				}
+				require.NoError(t, i.WaitForClose(ctx))
+			}
+
+			i, gMux := startInput()
+			assert.Equal(t, map[string]int{`{"source":"bar"}`: 1}, readSources(i, 1))
+
+			req := httptest.NewRequest("POST", "/inputs/foo", bytes.NewBufferString(`
+generate:
+  interval: 10ms
+  mapping: 'root.source = "foo"'
+`))
+			res := httptest.NewRecorder()
+			gMux.ServeHTTP(res, req)
+			require.Equal(t, 200, res.Code, res.Body.String())
+
+			req = httptest.NewRequest("DELETE", "/inputs/bar", http.NoBody)
+			res = httptest.NewRecorder()
+			gMux.ServeHTTP(res, req)
+			require.Equal(t, 200, res.Code, res.Body.String())
+
+			stopInput(i)
+
+			// A new input with the same config restores the changes.
+			i, gMux = startInput()
+			assert.Equal(t, map[string]int{`{"source":"foo"}`: 5}, readSources(i, 5))
+
+			req = httptest.NewRequest(http.MethodGet, "/inputs", http.NoBody)
+			res = httptest.NewRecorder()
+			gMux.ServeHTTP(res, req)
+			require.Equal(t, 200, res.Code)
+			assert.Contains(t, res.Body.String(), `"foo"`)
+			assert.NotContains(t, res.Body.String(), `"bar"`)
+
+			stopInput(i)
+		})
+	}
+}
+
+func TestDynamicInputPersistenceBadConfig(t *testing.T) {
+	for _, test := range []struct {
+		conf   string
+		errStr string
+	}{
+		{
+			conf: `
+dynamic:
+  persistence:
+    file: ./foo.yaml
+    cache: foocache
+`,
+			errStr: "either a file or a cache, not both",
+		},
+		{
+			conf: `
+dynamic:
+  persistence:
+    cache: nope
+`,
+			errStr: "cache not found",
+		},
+	} {
+		conf, err := testutil.InputFromYAML(test.conf)
+		require.NoError(t, err)
+
+		_, err = bmock.NewManager().NewInput(conf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), test.errStr)
+	}
+}
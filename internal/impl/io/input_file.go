@@ -3,10 +3,14 @@ package io
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
+
 	"github.com/redpanda-data/benthos/v4/internal/component"
 	"github.com/redpanda-data/benthos/v4/internal/filepath"
 	"github.com/redpanda-data/benthos/v4/public/service"
@@ -16,6 +20,14 @@ import (
 const (
 	fileInputFieldPaths          = "paths"
 	fileInputFieldDeleteOnFinish = "delete_on_finish"
+	fileInputFieldWatch          = "watch"
+	fileInputFieldWatchEnabled   = "enabled"
+	fileInputFieldWatchPoll      = "poll_interval"
+	fileInputFieldWatchCache     = "cache"
+	fileInputFieldCheckpoint     = "checkpoint_cache"
+	fileInputFieldTail           = "tail"
+	fileInputFieldTailEnabled    = "enabled"
+	fileInputFieldTailPoll       = "poll_interval"
 )
 
 func fileInputSpec() *service.ConfigSpec {
@@ -35,7 +47,29 @@ This input adds the following metadata fields to each message:
 `+"```"+`
 
 You can access these metadata fields using
-xref:configuration:interpolation.adoc#bloblang-queries[function interpolation].`).
+xref:configuration:interpolation.adoc#bloblang-queries[function interpolation].
+
+== Watching for Files
+
+By default this input consumes the files that match its paths at the time it starts and then shuts down. When `+"`watch.enabled`"+` is set the input instead runs continuously, consuming files that match the paths as they are created or modified. Changes are detected with filesystem notifications where possible, and the paths are also checked at the interval `+"`watch.poll_interval`"+`, which is the only means of detection when notifications are unavailable or for files within subdirectories matched by a super glob.
+
+A file that is modified after being consumed is consumed again in full, and therefore files should be moved into a watched directory once they are complete rather than written in place.
+
+Files that have been fully consumed are recorded along with their modification time, which is held in memory by default. In order to avoid consuming the same files again after a restart a `+"`watch.cache`"+` can be specified, which is a xref:components:caches/about.adoc[cache resource] that the registry of consumed files is stored in, keyed by their path.
+
+== Checkpointing
+
+When a `+"`checkpoint_cache`"+` is specified the position of consumption within each file is stored in the xref:components:caches/about.adoc[cache resource] as messages are acknowledged, keyed by the path of the file, and consumption of a file resumes from its checkpoint after a restart rather than starting from the beginning.
+
+Since scanners read ahead of the messages that they emit a checkpoint consists of the byte offset at which scanning of the file began plus the number of messages acknowledged after it, and when a file is resumed the messages prior to the checkpoint are scanned again and dropped. Once a file has been consumed in its entirety its checkpoint is moved to the end of the file, and therefore when combined with `+"`watch`"+` files that are appended to, such as logs, are consumed from where they were left rather than again in full. Files that are smaller than their checkpoint offset are assumed to have been truncated and are consumed from the beginning.
+
+== Following Files
+
+When `+"`tail.enabled`"+` is set the input follows each file that matches its paths as it grows, in a similar way to `+"`tail -F`"+`, rather than consuming files sequentially until their end. All matching files are followed concurrently, and the paths are checked for new files at the interval `+"`tail.poll_interval`"+`, which is also the interval at which followed files are checked for new data once their end has been reached.
+
+Followed files are reopened from the beginning when they are rotated, which is detected when a path refers to a different file than the one being read, such as when a log file is renamed and a new one is created in its place, or when a file becomes smaller than the position that has been read to, such as when it is truncated in place (copytruncate). Any data remaining within a renamed file is consumed before the path is reopened.
+
+When combined with a `+"`checkpoint_cache`"+` followed files resume from their checkpoint after a restart. However, since the end of a followed file is never reached a checkpoint is only ever moved when the file is rotated, and therefore resuming a file requires that it is scanned again from the beginning of its checkpoint.`).
 		Example(
 			"Read a Bunch of CSVs",
 			"If we wished to consume a directory of CSV files as structured documents we can use a glob pattern and the `csv` scanner:",
@@ -45,6 +79,25 @@ input:
     paths: [ ./data/*.csv ]
     scanner:
       csv: {}
+`,
+		).
+		Example(
+			"Watch a Directory",
+			"In this example the input consumes each file that is created within a directory, recording the files that it has consumed in a file cache so that they aren't consumed again after a restart:",
+			`
+input:
+  file:
+    paths: [ ./inbox/*.json ]
+    scanner:
+      to_the_end: {}
+    watch:
+      enabled: true
+      cache: consumed_files
+
+cache_resources:
+  - label: consumed_files
+    file:
+      directory: ./consumed
 `,
 		).
 		Fields(
@@ -57,6 +110,36 @@ input:
 				Description("Whether to delete input files from the disk once they are fully consumed.").
 				Advanced().
 				Default(false),
+			service.NewObjectField(fileInputFieldWatch,
+				service.NewBoolField(fileInputFieldWatchEnabled).
+					Description("Whether to continuously watch the paths for new and modified files rather than shutting down once the files that currently match have been consumed.").
+					Default(false),
+				service.NewDurationField(fileInputFieldWatchPoll).
+					Description("The interval at which the paths are checked for new and modified files, in addition to filesystem notifications.").
+					Default("5s"),
+				service.NewStringField(fileInputFieldWatchCache).
+					Description("An optional cache resource to store the registry of consumed files within, allowing it to persist across restarts.").
+					Default(""),
+			).
+				Description("Options for continuously consuming new and modified files.").
+				Version("4.29.0").
+				Advanced(),
+			service.NewStringField(fileInputFieldCheckpoint).
+				Description("An optional cache resource to store the position of consumption within each file, allowing consumption to resume from where it was left after a restart.").
+				Version("4.29.0").
+				Advanced().
+				Default(""),
+			service.NewObjectField(fileInputFieldTail,
+				service.NewBoolField(fileInputFieldTailEnabled).
+					Description("Whether to follow files as they grow, reopening them when they are rotated, rather than consuming each file until its end.").
+					Default(false),
+				service.NewDurationField(fileInputFieldTailPoll).
+					Description("The interval at which the paths are checked for new files and followed files are checked for new data.").
+					Default("1s"),
+			).
+				Description("Options for following files as they grow.").
+				Version("4.29.0").
+				Advanced(),
 			service.NewAutoRetryNacksToggleField(),
 		)
 }
@@ -78,15 +161,22 @@ func init() {
 //------------------------------------------------------------------------------
 
 type scannerInfo struct {
-	scanner     codec.DeprecatedFallbackStream
-	currentPath string
-	modTimeUTC  time.Time
+	scanner      codec.DeprecatedFallbackStream
+	checkpointer *fileCheckpointer
+	currentPath  string
+	modTimeUTC   time.Time
+}
+
+type fileWatchConfig struct {
+	PollInterval time.Duration
+	Cache        string
 }
 
 type fileConsumer struct {
 	log *service.Logger
 	nm  *service.Resources
 
+	patterns    []string
 	paths       []string
 	scannerCtor codec.DeprecatedFallbackCodec
 
@@ -94,8 +184,32 @@ type fileConsumer struct {
 	scannerInfo *scannerInfo
 
 	delete bool
+
+	checkpointCache string
+
+	// Only used when following files.
+	tail        *fileTailConfig
+	tailBatches chan fileTailBatch
+	tailDone    func()
+	tailWG      sync.WaitGroup
+	tailMut     sync.Mutex
+	tailing     map[string]struct{}
+
+	// Only used when watching for files.
+	watch      *fileWatchConfig
+	watcher    *fsnotify.Watcher
+	watchWake  chan struct{}
+	pollTicker *time.Ticker
+
+	registryMut sync.Mutex
+	pending     map[string]time.Time
+	processed   map[string]time.Time
 }
 
+// errNoNewFiles is returned by getReader when watching for files and there are
+// currently no new or modified files to consume.
+var errNoNewFiles = errors.New("no new files")
+
 func fileConsumerFromParsed(conf *service.ParsedConfig, nm *service.Resources) (*fileConsumer, error) {
 	paths, err := conf.FieldStringList(fileInputFieldPaths)
 	if err != nil {
@@ -107,29 +221,258 @@ func fileConsumerFromParsed(conf *service.ParsedConfig, nm *service.Resources) (
 		return nil, err
 	}
 
-	expandedPaths, err := filepath.Globs(nm.FS(), paths)
-	if err != nil {
-		return nil, err
-	}
-
 	ctor, err := codec.DeprecatedCodecFromParsed(conf)
 	if err != nil {
 		return nil, err
 	}
 
-	return &fileConsumer{
+	f := &fileConsumer{
 		nm:          nm,
 		log:         nm.Logger(),
 		scannerCtor: ctor,
-		paths:       expandedPaths,
+		patterns:    paths,
 		delete:      deleteOnFinish,
-	}, nil
+	}
+
+	if f.checkpointCache, err = conf.FieldString(fileInputFieldCheckpoint); err != nil {
+		return nil, err
+	}
+	if f.checkpointCache != "" && !nm.HasCache(f.checkpointCache) {
+		return nil, fmt.Errorf("cache resource '%v' was not found", f.checkpointCache)
+	}
+
+	wConf := conf.Namespace(fileInputFieldWatch)
+	watchEnabled, err := wConf.FieldBool(fileInputFieldWatchEnabled)
+	if err != nil {
+		return nil, err
+	}
+
+	tConf := conf.Namespace(fileInputFieldTail)
+	tailEnabled, err := tConf.FieldBool(fileInputFieldTailEnabled)
+	if err != nil {
+		return nil, err
+	}
+	if tailEnabled {
+		if watchEnabled {
+			return nil, errors.New("tail and watch cannot both be enabled, as followed files already include new files")
+		}
+		if deleteOnFinish {
+			return nil, errors.New("delete_on_finish cannot be used when tail is enabled, as followed files are never finished")
+		}
+		f.tail = &fileTailConfig{}
+		if f.tail.PollInterval, err = tConf.FieldDuration(fileInputFieldTailPoll); err != nil {
+			return nil, err
+		}
+		if f.tail.PollInterval <= 0 {
+			return nil, errors.New("tail poll interval must be greater than zero")
+		}
+		f.tailBatches = make(chan fileTailBatch)
+		f.tailing = map[string]struct{}{}
+		return f, nil
+	}
+
+	if !watchEnabled {
+		if f.paths, err = filepath.Globs(nm.FS(), paths); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+
+	f.watch = &fileWatchConfig{}
+	if f.watch.PollInterval, err = wConf.FieldDuration(fileInputFieldWatchPoll); err != nil {
+		return nil, err
+	}
+	if f.watch.PollInterval <= 0 {
+		return nil, errors.New("watch poll interval must be greater than zero")
+	}
+	if f.watch.Cache, err = wConf.FieldString(fileInputFieldWatchCache); err != nil {
+		return nil, err
+	}
+	if f.watch.Cache != "" && !nm.HasCache(f.watch.Cache) {
+		return nil, fmt.Errorf("cache resource '%v' was not found", f.watch.Cache)
+	}
+	if f.watch.Cache != "" && f.watch.Cache == f.checkpointCache {
+		return nil, errors.New("the watch cache and checkpoint cache must be different resources as both are keyed by file paths")
+	}
+	f.watchWake = make(chan struct{}, 1)
+	f.pending = map[string]time.Time{}
+	f.processed = map[string]time.Time{}
+
+	// The paths are expanded during the first read, where those that have
+	// already been consumed are filtered out.
+	return f, nil
 }
 
 func (f *fileConsumer) Connect(ctx context.Context) error {
+	if f.tail != nil {
+		f.connectTail()
+		return nil
+	}
+	if f.watch == nil {
+		return nil
+	}
+
+	f.scannerMut.Lock()
+	defer f.scannerMut.Unlock()
+
+	if f.pollTicker == nil {
+		f.pollTicker = time.NewTicker(f.watch.PollInterval)
+	}
+	if f.watcher == nil {
+		// Notifications are an optimisation over polling and therefore failing
+		// to set them up isn't fatal.
+		if watcher, err := f.newWatcher(); err != nil {
+			f.log.Warnf("Failed to watch for file notifications, falling back to polling: %v", err)
+		} else {
+			f.watcher = watcher
+		}
+	}
+	return nil
+}
+
+func (f *fileConsumer) newWatcher() (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	seenDirs := map[string]struct{}{}
+	for _, p := range f.patterns {
+		dir := filepath.StaticDir(p)
+		if _, seen := seenDirs[dir]; seen {
+			continue
+		}
+		seenDirs[dir] = struct{}{}
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return nil, fmt.Errorf("failed to watch directory '%v': %w", dir, err)
+		}
+	}
+
+	go func() {
+		for {
+			select {
+			case event, open := <-watcher.Events:
+				if !open {
+					return
+				}
+				if !event.Has(fsnotify.Create | fsnotify.Write | fsnotify.Rename) {
+					continue
+				}
+				select {
+				case f.watchWake <- struct{}{}:
+				default:
+				}
+			case err, open := <-watcher.Errors:
+				if !open {
+					return
+				}
+				f.log.Debugf("File watcher error: %v", err)
+			}
+		}
+	}()
+	return watcher, nil
+}
+
+// isProcessed returns whether a file at a path with a given modification time
+// has already been consumed.
+func (f *fileConsumer) isProcessed(ctx context.Context, path string, modTime time.Time) (bool, error) {
+	if f.watch.Cache == "" {
+		f.registryMut.Lock()
+		defer f.registryMut.Unlock()
+		t, exists := f.processed[path]
+		return exists && t.Equal(modTime), nil
+	}
+
+	var value []byte
+	var err error
+	if cerr := f.nm.AccessCache(ctx, f.watch.Cache, func(c service.Cache) {
+		value, err = c.Get(ctx, path)
+	}); cerr != nil {
+		return false, cerr
+	}
+	if errors.Is(err, service.ErrKeyNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return string(value) == strconv.FormatInt(modTime.UnixNano(), 10), nil
+}
+
+// setProcessed records that a file at a path with a given modification time
+// has been consumed.
+func (f *fileConsumer) setProcessed(ctx context.Context, path string, modTime time.Time) error {
+	if f.watch.Cache == "" {
+		f.registryMut.Lock()
+		f.processed[path] = modTime
+		f.registryMut.Unlock()
+		return nil
+	}
+
+	var err error
+	if cerr := f.nm.AccessCache(ctx, f.watch.Cache, func(c service.Cache) {
+		err = c.Set(ctx, path, []byte(strconv.FormatInt(modTime.UnixNano(), 10)), nil)
+	}); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+// scanForFiles expands the paths and queues any files that are new or have
+// been modified since they were last consumed.
+func (f *fileConsumer) scanForFiles(ctx context.Context) error {
+	expandedPaths, err := filepath.Globs(f.nm.FS(), f.patterns)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range expandedPaths {
+		info, err := f.nm.FS().Stat(p)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		modTime := info.ModTime().UTC()
+
+		f.registryMut.Lock()
+		pendingModTime, isPending := f.pending[p]
+		f.registryMut.Unlock()
+		if isPending && pendingModTime.Equal(modTime) {
+			continue
+		}
+
+		processed, err := f.isProcessed(ctx, p, modTime)
+		if err != nil {
+			return fmt.Errorf("failed to check whether file '%v' has been consumed: %w", p, err)
+		}
+		if processed {
+			continue
+		}
+
+		f.registryMut.Lock()
+		f.pending[p] = modTime
+		f.registryMut.Unlock()
+		f.paths = append(f.paths, p)
+	}
 	return nil
 }
 
+// fileFinished is called once a file has been fully consumed, or when
+// consuming it fails, and clears the file from the pending files so that it is
+// either recorded as consumed or picked up again by the next scan.
+func (f *fileConsumer) fileFinished(ctx context.Context, path string, modTime time.Time, err error) error {
+	f.registryMut.Lock()
+	if t, exists := f.pending[path]; exists && t.Equal(modTime) {
+		delete(f.pending, path)
+	}
+	f.registryMut.Unlock()
+
+	if err != nil || f.delete {
+		return nil
+	}
+	return f.setProcessed(ctx, path, modTime)
+}
+
 func (f *fileConsumer) getReader(ctx context.Context) (scannerInfo, error) {
 	f.scannerMut.Lock()
 	defer f.scannerMut.Unlock()
@@ -138,21 +481,69 @@ func (f *fileConsumer) getReader(ctx context.Context) (scannerInfo, error) {
 		return *f.scannerInfo, nil
 	}
 
+	if len(f.paths) == 0 && f.watch != nil {
+		if err := f.scanForFiles(ctx); err != nil {
+			return scannerInfo{}, err
+		}
+		if len(f.paths) == 0 {
+			return scannerInfo{}, errNoNewFiles
+		}
+	}
+
 	if len(f.paths) == 0 {
 		return scannerInfo{}, component.ErrTypeClosed
 	}
 
 	nextPath := f.paths[0]
 
+	var pendingModTime time.Time
+	if f.watch != nil {
+		f.registryMut.Lock()
+		pendingModTime = f.pending[nextPath]
+		f.registryMut.Unlock()
+	}
+
 	file, err := f.nm.FS().Open(nextPath)
 	if err != nil {
+		if f.watch != nil {
+			// The file may have been removed since it was found, in which case
+			// it is dropped and any later version is found by the next scan.
+			f.paths = f.paths[1:]
+			_ = f.fileFinished(ctx, nextPath, pendingModTime, err)
+		}
 		return scannerInfo{}, err
 	}
 
+	var modTimeUTC time.Time
+	if fInfo, err := file.Stat(); err == nil {
+		modTimeUTC = fInfo.ModTime().UTC()
+	} else {
+		f.log.Errorf("Failed to read metadata from file '%v'", nextPath)
+	}
+
+	var rdr io.ReadCloser = file
+	var checkpointer *fileCheckpointer
+	if f.checkpointCache != "" {
+		if rdr, checkpointer, err = f.openFromCheckpoint(ctx, nextPath, file); err != nil {
+			file.Close()
+			return scannerInfo{}, err
+		}
+	}
+
 	details := service.NewScannerSourceDetails()
 	details.SetName(nextPath)
 
-	scanner, err := f.scannerCtor.Create(file, func(ctx context.Context, err error) error {
+	scanner, err := f.scannerCtor.Create(rdr, func(ctx context.Context, err error) error {
+		if checkpointer != nil && err == nil {
+			if cerr := checkpointer.finish(ctx, f.delete); cerr != nil {
+				return fmt.Errorf("failed to store checkpoint of file '%v': %w", nextPath, cerr)
+			}
+		}
+		if f.watch != nil {
+			if ferr := f.fileFinished(ctx, nextPath, pendingModTime, err); ferr != nil {
+				return ferr
+			}
+		}
 		if err == nil && f.delete {
 			return f.nm.FS().Remove(nextPath)
 		}
@@ -163,17 +554,11 @@ func (f *fileConsumer) getReader(ctx context.Context) (scannerInfo, error) {
 		return scannerInfo{}, err
 	}
 
-	var modTimeUTC time.Time
-	if fInfo, err := file.Stat(); err == nil {
-		modTimeUTC = fInfo.ModTime().UTC()
-	} else {
-		f.log.Errorf("Failed to read metadata from file '%v'", nextPath)
-	}
-
 	f.scannerInfo = &scannerInfo{
-		scanner:     scanner,
-		currentPath: nextPath,
-		modTimeUTC:  modTimeUTC,
+		scanner:      scanner,
+		checkpointer: checkpointer,
+		currentPath:  nextPath,
+		modTimeUTC:   modTimeUTC,
 	}
 
 	f.paths = f.paths[1:]
@@ -183,8 +568,17 @@ func (f *fileConsumer) getReader(ctx context.Context) (scannerInfo, error) {
 }
 
 func (f *fileConsumer) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	if f.tail != nil {
+		return f.readTailBatch(ctx)
+	}
 	for {
 		scannerInfo, err := f.getReader(ctx)
+		if errors.Is(err, errNoNewFiles) {
+			if err = f.awaitChanges(ctx); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
 		if err != nil {
 			return nil, nil, err
 		}
@@ -207,6 +601,12 @@ func (f *fileConsumer) ReadBatch(ctx context.Context) (service.MessageBatch, ser
 			return nil, nil, err
 		}
 
+		if scannerInfo.checkpointer != nil && len(parts) > 0 {
+			if parts, codecAckFn = scannerInfo.checkpointer.track(ctx, parts, codecAckFn); len(parts) == 0 {
+				continue
+			}
+		}
+
 		for _, part := range parts {
 			part.MetaSetMut("path", scannerInfo.currentPath)
 			part.MetaSetMut("mod_time_unix", scannerInfo.modTimeUTC.Unix())
@@ -224,7 +624,32 @@ func (f *fileConsumer) ReadBatch(ctx context.Context) (service.MessageBatch, ser
 	}
 }
 
+// awaitChanges blocks until either a notification is received or the poll
+// interval has passed, after which the paths should be scanned again.
+func (f *fileConsumer) awaitChanges(ctx context.Context) error {
+	f.scannerMut.Lock()
+	pollTicker := f.pollTicker
+	f.scannerMut.Unlock()
+
+	if pollTicker == nil {
+		return component.ErrNotConnected
+	}
+
+	select {
+	case <-f.watchWake:
+	case <-pollTicker.C:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
 func (f *fileConsumer) Close(ctx context.Context) (err error) {
+	if f.tail != nil {
+		f.closeTail()
+		return nil
+	}
+
 	f.scannerMut.Lock()
 	defer f.scannerMut.Unlock()
 
@@ -233,5 +658,13 @@ func (f *fileConsumer) Close(ctx context.Context) (err error) {
 		f.scannerInfo = nil
 		f.paths = nil
 	}
+	if f.watcher != nil {
+		_ = f.watcher.Close()
+		f.watcher = nil
+	}
+	if f.pollTicker != nil {
+		f.pollTicker.Stop()
+		f.pollTicker = nil
+	}
 	return
 }
@@ -0,0 +1,218 @@
+package io
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+	"sync/atomic"
+
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+// fileCheckpoint is the position of consumption within a file. Since scanners
+// read ahead of the messages that they emit the exact byte offset of a message
+// isn't known, and therefore a checkpoint is the byte offset at which scanning
+// began plus the number of messages that have been consumed after it. Once a
+// file has been consumed in its entirety the offset is moved to the end of the
+// file.
+type fileCheckpoint struct {
+	Offset   int64 `json:"offset"`
+	Messages int64 `json:"messages"`
+}
+
+func (f *fileConsumer) getCheckpoint(ctx context.Context, path string) (cp fileCheckpoint, err error) {
+	var value []byte
+	if cerr := f.nm.AccessCache(ctx, f.checkpointCache, func(c service.Cache) {
+		value, err = c.Get(ctx, path)
+	}); cerr != nil {
+		return cp, cerr
+	}
+	if errors.Is(err, service.ErrKeyNotFound) {
+		return cp, nil
+	}
+	if err != nil {
+		return cp, err
+	}
+	if err = json.Unmarshal(value, &cp); err != nil {
+		err = fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	return
+}
+
+func (f *fileConsumer) setCheckpoint(ctx context.Context, path string, cp fileCheckpoint) error {
+	value, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	if cerr := f.nm.AccessCache(ctx, f.checkpointCache, func(c service.Cache) {
+		err = c.Set(ctx, path, value, nil)
+	}); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+// openFromCheckpoint moves a newly opened file to the offset of its last
+// checkpoint, returning a reader that counts the bytes read from the file and a
+// checkpointer for tracking the consumption of the messages scanned from it.
+func (f *fileConsumer) openFromCheckpoint(ctx context.Context, path string, file fs.File) (*countingFileReader, *fileCheckpointer, error) {
+	cp, err := f.getCheckpoint(ctx, path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read checkpoint of file '%v': %w", path, err)
+	}
+
+	// A file that is smaller than the checkpoint offset is assumed to have
+	// been truncated and is consumed from the beginning.
+	if info, err := file.Stat(); err == nil && info.Size() < cp.Offset {
+		f.log.Infof("File '%v' is smaller than its checkpoint offset %v, consuming from the beginning", path, cp.Offset)
+		cp = fileCheckpoint{}
+	}
+
+	if cp.Offset > 0 {
+		if seeker, ok := file.(io.Seeker); ok {
+			_, err = seeker.Seek(cp.Offset, io.SeekStart)
+		} else {
+			_, err = io.CopyN(io.Discard, file, cp.Offset)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to seek file '%v' to checkpoint offset %v: %w", path, cp.Offset, err)
+		}
+	}
+	if cp.Offset > 0 || cp.Messages > 0 {
+		f.log.Debugf("Resuming file '%v' from offset %v after %v messages", path, cp.Offset, cp.Messages)
+	}
+
+	rdr := &countingFileReader{file: file}
+	return rdr, &fileCheckpointer{
+		path:    path,
+		offset:  cp.Offset,
+		rdr:     rdr,
+		set:     f.setCheckpoint,
+		log:     f.log,
+		skip:    cp.Messages,
+		issued:  cp.Messages,
+		acked:   cp.Messages,
+		pending: map[int64]int64{},
+	}, nil
+}
+
+// countingFileReader counts the bytes read from a file.
+type countingFileReader struct {
+	file fs.File
+	read atomic.Int64
+}
+
+func (c *countingFileReader) Read(p []byte) (int, error) {
+	n, err := c.file.Read(p)
+	c.read.Add(int64(n))
+	return n, err
+}
+
+func (c *countingFileReader) Close() error {
+	return c.file.Close()
+}
+
+// fileCheckpointer tracks the messages consumed from a file and stores a
+// checkpoint each time the number of messages consumed in order increases.
+type fileCheckpointer struct {
+	path   string
+	offset int64
+	rdr    *countingFileReader
+	set    func(ctx context.Context, path string, cp fileCheckpoint) error
+	log    *service.Logger
+
+	mut      sync.Mutex
+	skip     int64
+	issued   int64
+	acked    int64
+	pending  map[int64]int64
+	finished bool
+}
+
+// track drops any messages that were consumed prior to the checkpoint the file
+// was resumed from and wraps the ack function of the remaining messages so that
+// checkpoints are stored once they're acknowledged. A nil batch is returned
+// when all messages are dropped.
+func (c *fileCheckpointer) track(ctx context.Context, batch service.MessageBatch, aFn service.AckFunc) (service.MessageBatch, service.AckFunc) {
+	c.mut.Lock()
+	if c.skip > 0 {
+		n := c.skip
+		if n > int64(len(batch)) {
+			n = int64(len(batch))
+		}
+		c.skip -= n
+		batch = batch[n:]
+	}
+	if len(batch) == 0 {
+		c.mut.Unlock()
+		_ = aFn(ctx, nil)
+		return nil, nil
+	}
+	start, size := c.issued, int64(len(batch))
+	c.issued += size
+	c.mut.Unlock()
+
+	return batch, func(ctx context.Context, err error) error {
+		// The checkpoint must be stored before acknowledging the messages as
+		// the final acknowledgement of a file triggers finish.
+		if err == nil {
+			c.ack(ctx, start, size)
+		}
+		return aFn(ctx, err)
+	}
+}
+
+func (c *fileCheckpointer) ack(ctx context.Context, start, size int64) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.finished {
+		return
+	}
+
+	c.pending[start] = size
+	acked := c.acked
+	for {
+		n, exists := c.pending[acked]
+		if !exists {
+			break
+		}
+		delete(c.pending, acked)
+		acked += n
+	}
+	if acked == c.acked {
+		return
+	}
+	c.acked = acked
+
+	if err := c.set(ctx, c.path, fileCheckpoint{Offset: c.offset, Messages: acked}); err != nil {
+		c.log.Errorf("Failed to store checkpoint of file '%v': %v", c.path, err)
+	}
+}
+
+// finish stores a checkpoint at the end of the bytes read from the file, which
+// must only be called once all messages of the file have been consumed. When
+// the file is about to be deleted the checkpoint is reset instead so that a
+// new file at the same path is consumed from the beginning.
+func (c *fileCheckpointer) finish(ctx context.Context, reset bool) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.finished = true
+	if reset {
+		return c.set(ctx, c.path, fileCheckpoint{})
+	}
+	return c.set(ctx, c.path, fileCheckpoint{Offset: c.offset + c.rdr.read.Load()})
+}
+
+// abandon stops any further checkpoints from being stored, which is used when a
+// file is rotated and its path refers to a new file.
+func (c *fileCheckpointer) abandon() {
+	c.mut.Lock()
+	c.finished = true
+	c.mut.Unlock()
+}
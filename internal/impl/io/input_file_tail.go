@@ -0,0 +1,270 @@
+package io
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/redpanda-data/benthos/v4/internal/filepath"
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+type fileTailConfig struct {
+	PollInterval time.Duration
+}
+
+type fileTailBatch struct {
+	batch service.MessageBatch
+	aFn   service.AckFunc
+}
+
+// tailingFileReader reads from a file and, rather than returning io.EOF once
+// the end of the file is reached, waits for more data to be written to it. An
+// io.EOF is only returned once the file has been rotated, which is when the
+// path no longer refers to the open file or the file is smaller than the
+// position that has been read to.
+type tailingFileReader struct {
+	ctx          context.Context
+	fs           *service.FS
+	path         string
+	file         fs.File
+	src          io.ReadCloser
+	pollInterval time.Duration
+	pos          int64
+}
+
+func (t *tailingFileReader) Read(p []byte) (int, error) {
+	for {
+		n, err := t.src.Read(p)
+		t.pos += int64(n)
+		if n > 0 {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			return n, err
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		if t.rotated() {
+			return 0, io.EOF
+		}
+		select {
+		case <-time.After(t.pollInterval):
+		case <-t.ctx.Done():
+			return 0, t.ctx.Err()
+		}
+	}
+}
+
+func (t *tailingFileReader) rotated() bool {
+	pathInfo, err := t.fs.Stat(t.path)
+	if err != nil {
+		return true
+	}
+	fileInfo, err := t.file.Stat()
+	if err != nil {
+		return true
+	}
+	if !os.SameFile(pathInfo, fileInfo) {
+		return true
+	}
+	return fileInfo.Size() < t.pos
+}
+
+func (t *tailingFileReader) Close() error {
+	return t.src.Close()
+}
+
+//------------------------------------------------------------------------------
+
+func (f *fileConsumer) connectTail() {
+	f.scannerMut.Lock()
+	defer f.scannerMut.Unlock()
+
+	if f.tailDone != nil {
+		return
+	}
+
+	var ctx context.Context
+	ctx, f.tailDone = context.WithCancel(context.Background())
+
+	f.tailWG.Add(1)
+	go f.tailLoop(ctx)
+}
+
+// tailLoop periodically expands the paths and begins following any matching
+// files that aren't already being followed.
+func (f *fileConsumer) tailLoop(ctx context.Context) {
+	defer f.tailWG.Done()
+
+	ticker := time.NewTicker(f.tail.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		paths, err := filepath.Globs(f.nm.FS(), f.patterns)
+		if err != nil {
+			f.log.Errorf("Failed to expand paths: %v", err)
+		}
+		for _, p := range paths {
+			if info, err := f.nm.FS().Stat(p); err != nil || info.IsDir() {
+				continue
+			}
+
+			f.tailMut.Lock()
+			_, exists := f.tailing[p]
+			f.tailing[p] = struct{}{}
+			f.tailMut.Unlock()
+			if exists {
+				continue
+			}
+
+			f.tailWG.Add(1)
+			go f.tailFile(ctx, p)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// tailFile follows a file until it is removed, reopening the path each time
+// the file is rotated.
+func (f *fileConsumer) tailFile(ctx context.Context, path string) {
+	defer f.tailWG.Done()
+	defer func() {
+		f.tailMut.Lock()
+		delete(f.tailing, path)
+		f.tailMut.Unlock()
+	}()
+
+	fromStart := false
+	for {
+		if err := f.tailFileOnce(ctx, path, fromStart); err != nil {
+			if ctx.Err() == nil && !errors.Is(err, fs.ErrNotExist) {
+				f.log.Errorf("Failed to follow file '%v': %v", path, err)
+			}
+			return
+		}
+		f.log.Debugf("File '%v' was rotated, reopening", path)
+		fromStart = true
+	}
+}
+
+// tailFileOnce consumes a file until either it is rotated, in which case nil is
+// returned, or an error occurs.
+func (f *fileConsumer) tailFileOnce(ctx context.Context, path string, fromStart bool) error {
+	file, err := f.nm.FS().Open(path)
+	if err != nil {
+		return err
+	}
+
+	var src io.ReadCloser = file
+	var offset int64
+	var checkpointer *fileCheckpointer
+	if f.checkpointCache != "" {
+		// Rotated files are replaced by a new file, and therefore the
+		// checkpoint of the previous file no longer applies.
+		if fromStart {
+			if err := f.setCheckpoint(ctx, path, fileCheckpoint{}); err != nil {
+				file.Close()
+				return err
+			}
+		}
+		if src, checkpointer, err = f.openFromCheckpoint(ctx, path, file); err != nil {
+			file.Close()
+			return err
+		}
+		offset = checkpointer.offset
+	}
+
+	rdr := &tailingFileReader{
+		ctx:          ctx,
+		fs:           f.nm.FS(),
+		path:         path,
+		file:         file,
+		src:          src,
+		pollInterval: f.tail.PollInterval,
+		pos:          offset,
+	}
+
+	details := service.NewScannerSourceDetails()
+	details.SetName(path)
+
+	scanner, err := f.scannerCtor.Create(rdr, func(context.Context, error) error {
+		return nil
+	}, details)
+	if err != nil {
+		rdr.Close()
+		return err
+	}
+	defer scanner.Close(context.Background())
+
+	f.log.Debugf("Following file '%v'", path)
+	for {
+		batch, aFn, err := scanner.NextBatch(ctx)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				if checkpointer != nil {
+					checkpointer.abandon()
+				}
+				return nil
+			}
+			return err
+		}
+
+		if checkpointer != nil && len(batch) > 0 {
+			if batch, aFn = checkpointer.track(ctx, batch, aFn); len(batch) == 0 {
+				continue
+			}
+		}
+		if len(batch) == 0 {
+			_ = aFn(ctx, nil)
+			continue
+		}
+
+		var modTimeUTC time.Time
+		if fInfo, err := file.Stat(); err == nil {
+			modTimeUTC = fInfo.ModTime().UTC()
+		}
+		for _, part := range batch {
+			part.MetaSetMut("path", path)
+			part.MetaSetMut("mod_time_unix", modTimeUTC.Unix())
+			part.MetaSetMut("mod_time", modTimeUTC.Format(time.RFC3339))
+		}
+
+		select {
+		case f.tailBatches <- fileTailBatch{batch: batch, aFn: aFn}:
+		case <-ctx.Done():
+			_ = aFn(context.Background(), ctx.Err())
+			return ctx.Err()
+		}
+	}
+}
+
+func (f *fileConsumer) readTailBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	select {
+	case b := <-f.tailBatches:
+		return b.batch, b.aFn, nil
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+func (f *fileConsumer) closeTail() {
+	f.scannerMut.Lock()
+	tailDone := f.tailDone
+	f.tailDone = nil
+	f.scannerMut.Unlock()
+
+	if tailDone != nil {
+		tailDone()
+		f.tailWG.Wait()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"testing"
@@ -12,6 +13,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/redpanda-data/benthos/v4/internal/component/cache"
+	"github.com/redpanda-data/benthos/v4/internal/component/input"
 	"github.com/redpanda-data/benthos/v4/internal/component/testutil"
 	"github.com/redpanda-data/benthos/v4/internal/manager/mock"
 	"github.com/redpanda-data/benthos/v4/internal/message"
@@ -199,3 +202,336 @@ func assertValidMetaData(t *testing.T, res *message.Part, tmpFile *os.File) {
 func mockTime() time.Time {
 	return time.Date(2015, 8, 25, 23, 23, 0, 0, time.UTC)
 }
+
+func readFileInputTransaction(t *testing.T, i input.Streamed) string {
+	t.Helper()
+	select {
+	case tran, open := <-i.TransactionChan():
+		require.True(t, open)
+		require.NoError(t, tran.Ack(context.Background(), nil))
+		return string(tran.Payload.Get(0).AsBytes())
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+	return ""
+}
+
+func TestFileWatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("foo"), 0o644))
+
+	conf, err := testutil.InputFromYAML(fmt.Sprintf(`
+file:
+  paths: [ "%v/*.txt" ]
+  scanner:
+    to_the_end: {}
+  watch:
+    enabled: true
+    poll_interval: 100ms
+`, tmpDir))
+	require.NoError(t, err)
+
+	i, err := mock.NewManager().NewInput(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+		defer done()
+		i.TriggerStopConsuming()
+		assert.NoError(t, i.WaitForClose(ctx))
+	})
+
+	assert.Equal(t, "foo", readFileInputTransaction(t, i))
+
+	// Files that don't match are ignored.
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "b.json"), []byte("nope"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "b.txt"), []byte("bar"), 0o644))
+	assert.Equal(t, "bar", readFileInputTransaction(t, i))
+
+	// Modified files are consumed again.
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("baz"), 0o644))
+	require.NoError(t, os.Chtimes(filepath.Join(tmpDir, "a.txt"), mockTime(), mockTime()))
+	assert.Equal(t, "baz", readFileInputTransaction(t, i))
+
+	select {
+	case tran := <-i.TransactionChan():
+		t.Fatalf("unexpected message: %s", tran.Payload.Get(0).AsBytes())
+	case <-time.After(time.Millisecond * 500):
+	}
+}
+
+func TestFileWatchCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("foo"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "b.txt"), []byte("bar"), 0o644))
+	require.NoError(t, os.Chtimes(filepath.Join(tmpDir, "b.txt"), mockTime(), mockTime()))
+
+	conf, err := testutil.InputFromYAML(fmt.Sprintf(`
+file:
+  paths: [ "%v/*.txt" ]
+  scanner:
+    to_the_end: {}
+  watch:
+    enabled: true
+    poll_interval: 100ms
+    cache: registry
+`, tmpDir))
+	require.NoError(t, err)
+
+	mgr := mock.NewManager()
+	mgr.Caches["registry"] = map[string]mock.CacheItem{
+		filepath.Join(tmpDir, "b.txt"): {Value: strconv.FormatInt(mockTime().UnixNano(), 10)},
+	}
+
+	i, err := mgr.NewInput(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+		defer done()
+		i.TriggerStopConsuming()
+		assert.NoError(t, i.WaitForClose(ctx))
+	})
+
+	assert.Equal(t, "foo", readFileInputTransaction(t, i))
+
+	select {
+	case tran := <-i.TransactionChan():
+		t.Fatalf("unexpected message: %s", tran.Payload.Get(0).AsBytes())
+	case <-time.After(time.Millisecond * 500):
+	}
+
+	info, err := os.Stat(filepath.Join(tmpDir, "a.txt"))
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		var value []byte
+		require.NoError(t, mgr.AccessCache(context.Background(), "registry", func(c cache.V1) {
+			value, _ = c.Get(context.Background(), filepath.Join(tmpDir, "a.txt"))
+		}))
+		return string(value) == strconv.FormatInt(info.ModTime().UnixNano(), 10)
+	}, time.Second*5, time.Millisecond*50)
+}
+
+func TestFileWatchMissingCache(t *testing.T) {
+	conf, err := testutil.InputFromYAML(`
+file:
+  paths: [ ./*.txt ]
+  watch:
+    enabled: true
+    cache: nope
+`)
+	require.NoError(t, err)
+
+	_, err = mock.NewManager().NewInput(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cache resource 'nope' was not found")
+}
+
+func readAllFileInput(t *testing.T, mgr *mock.Manager, conf string) (msgs []string) {
+	t.Helper()
+
+	iConf, err := testutil.InputFromYAML(conf)
+	require.NoError(t, err)
+
+	i, err := mgr.NewInput(iConf)
+	require.NoError(t, err)
+
+	for {
+		select {
+		case tran, open := <-i.TransactionChan():
+			if !open {
+				return
+			}
+			msgs = append(msgs, string(tran.Payload.Get(0).AsBytes()))
+			require.NoError(t, tran.Ack(context.Background(), nil))
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out")
+		}
+	}
+}
+
+func getFileCheckpoint(t *testing.T, mgr *mock.Manager, path string) string {
+	t.Helper()
+
+	var value []byte
+	require.NoError(t, mgr.AccessCache(context.Background(), "checkpoints", func(c cache.V1) {
+		value, _ = c.Get(context.Background(), path)
+	}))
+	return string(value)
+}
+
+func TestFileCheckpoint(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "a.log")
+	require.NoError(t, os.WriteFile(path, []byte("foo\nbar\nbaz\nbuz\n"), 0o644))
+
+	mgr := mock.NewManager()
+	mgr.Caches["checkpoints"] = map[string]mock.CacheItem{
+		path: {Value: `{"offset":0,"messages":2}`},
+	}
+
+	conf := fmt.Sprintf(`
+file:
+  paths: [ "%v" ]
+  scanner:
+    lines: {}
+  checkpoint_cache: checkpoints
+`, path)
+
+	assert.Equal(t, []string{"baz", "buz"}, readAllFileInput(t, mgr, conf))
+	assert.Equal(t, `{"offset":16,"messages":0}`, getFileCheckpoint(t, mgr, path))
+
+	// Appended data is consumed from the end of the previous read.
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = file.WriteString("qux\nquz\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	assert.Equal(t, []string{"qux", "quz"}, readAllFileInput(t, mgr, conf))
+	assert.Equal(t, `{"offset":24,"messages":0}`, getFileCheckpoint(t, mgr, path))
+
+	assert.Empty(t, readAllFileInput(t, mgr, conf))
+
+	// Truncated files are consumed from the beginning.
+	require.NoError(t, os.WriteFile(path, []byte("new\n"), 0o644))
+
+	assert.Equal(t, []string{"new"}, readAllFileInput(t, mgr, conf))
+	assert.Equal(t, `{"offset":4,"messages":0}`, getFileCheckpoint(t, mgr, path))
+}
+
+func TestFileCheckpointOutOfOrderAcks(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "a.log")
+	require.NoError(t, os.WriteFile(path, []byte("foo\nbar\nbaz\n"), 0o644))
+
+	mgr := mock.NewManager()
+	mgr.Caches["checkpoints"] = map[string]mock.CacheItem{}
+
+	iConf, err := testutil.InputFromYAML(fmt.Sprintf(`
+file:
+  paths: [ "%v" ]
+  scanner:
+    lines: {}
+  checkpoint_cache: checkpoints
+`, path))
+	require.NoError(t, err)
+
+	i, err := mgr.NewInput(iConf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+		defer done()
+		i.TriggerCloseNow()
+		assert.NoError(t, i.WaitForClose(ctx))
+	})
+
+	var trans []message.Transaction
+	for len(trans) < 2 {
+		select {
+		case tran := <-i.TransactionChan():
+			trans = append(trans, tran)
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out")
+		}
+	}
+
+	// The second message is acknowledged first and therefore doesn't move the
+	// checkpoint until the first is also acknowledged.
+	require.NoError(t, trans[1].Ack(context.Background(), nil))
+	assert.Equal(t, "", getFileCheckpoint(t, mgr, path))
+
+	require.NoError(t, trans[0].Ack(context.Background(), nil))
+	assert.Eventually(t, func() bool {
+		return getFileCheckpoint(t, mgr, path) == `{"offset":0,"messages":2}`
+	}, time.Second*5, time.Millisecond*50)
+}
+
+func appendToFile(t *testing.T, path, data string) {
+	t.Helper()
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = file.WriteString(data)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+}
+
+func TestFileTail(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "a.log")
+	appendToFile(t, path, "foo\nbar\n")
+
+	conf, err := testutil.InputFromYAML(fmt.Sprintf(`
+file:
+  paths: [ "%v/*.log" ]
+  scanner:
+    lines: {}
+  tail:
+    enabled: true
+    poll_interval: 50ms
+`, tmpDir))
+	require.NoError(t, err)
+
+	i, err := mock.NewManager().NewInput(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+		defer done()
+		i.TriggerStopConsuming()
+		assert.NoError(t, i.WaitForClose(ctx))
+	})
+
+	assert.Equal(t, "foo", readFileInputTransaction(t, i))
+	assert.Equal(t, "bar", readFileInputTransaction(t, i))
+
+	// Partial lines are not consumed until they're complete.
+	appendToFile(t, path, "ba")
+	select {
+	case tran := <-i.TransactionChan():
+		t.Fatalf("unexpected message: %s", tran.Payload.Get(0).AsBytes())
+	case <-time.After(time.Millisecond * 200):
+	}
+	appendToFile(t, path, "z\n")
+	assert.Equal(t, "baz", readFileInputTransaction(t, i))
+
+	// New files are followed.
+	appendToFile(t, filepath.Join(tmpDir, "b.log"), "from b\n")
+	assert.Equal(t, "from b", readFileInputTransaction(t, i))
+
+	// Renamed files are drained before the path is reopened.
+	appendToFile(t, path, "before rename\n")
+	assert.Equal(t, "before rename", readFileInputTransaction(t, i))
+	require.NoError(t, os.Rename(path, filepath.Join(tmpDir, "a.log.1")))
+	appendToFile(t, filepath.Join(tmpDir, "a.log.1"), "after rename\n")
+	appendToFile(t, path, "new file\n")
+	assert.Equal(t, "after rename", readFileInputTransaction(t, i))
+	assert.Equal(t, "new file", readFileInputTransaction(t, i))
+
+	// Truncated files are reopened from the beginning.
+	require.NoError(t, os.Truncate(path, 0))
+	time.Sleep(time.Millisecond * 200)
+	appendToFile(t, path, "truncated\n")
+	assert.Equal(t, "truncated", readFileInputTransaction(t, i))
+}
+
+func TestFileTailBadConfig(t *testing.T) {
+	for _, test := range []struct {
+		conf string
+		err  string
+	}{
+		{
+			conf: `{ paths: [ ./*.log ], tail: { enabled: true }, watch: { enabled: true } }`,
+			err:  "tail and watch cannot both be enabled",
+		},
+		{
+			conf: `{ paths: [ ./*.log ], tail: { enabled: true }, delete_on_finish: true }`,
+			err:  "delete_on_finish cannot be used when tail is enabled",
+		},
+	} {
+		conf, err := testutil.InputFromYAML(`file: ` + test.conf)
+		require.NoError(t, err)
+
+		_, err = mock.NewManager().NewInput(conf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), test.err)
+	}
+}
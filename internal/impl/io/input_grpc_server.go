@@ -0,0 +1,464 @@
+package io
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/shutdown"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/redpanda-data/benthos/v4/internal/bundle"
+	"github.com/redpanda-data/benthos/v4/internal/component/input"
+	"github.com/redpanda-data/benthos/v4/internal/component/interop"
+	"github.com/redpanda-data/benthos/v4/internal/component/metrics"
+	"github.com/redpanda-data/benthos/v4/internal/filepath/ifs"
+	"github.com/redpanda-data/benthos/v4/internal/log"
+	"github.com/redpanda-data/benthos/v4/internal/message"
+	"github.com/redpanda-data/benthos/v4/internal/tracing"
+	"github.com/redpanda-data/benthos/v4/internal/transaction"
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+const (
+	gsiFieldAddress       = "address"
+	gsiFieldDescriptorSet = "descriptor_set"
+	gsiFieldMethods       = "methods"
+	gsiFieldTimeout       = "timeout"
+	gsiFieldCertFile      = "cert_file"
+	gsiFieldKeyFile       = "key_file"
+)
+
+type gsiConfig struct {
+	Address       string
+	DescriptorSet string
+	Methods       []string
+	Timeout       time.Duration
+	CertFile      string
+	KeyFile       string
+}
+
+func gsiConfigFromParsed(pConf *service.ParsedConfig) (conf gsiConfig, err error) {
+	if conf.Address, err = pConf.FieldString(gsiFieldAddress); err != nil {
+		return
+	}
+	if conf.DescriptorSet, err = pConf.FieldString(gsiFieldDescriptorSet); err != nil {
+		return
+	}
+	if conf.Methods, err = pConf.FieldStringList(gsiFieldMethods); err != nil {
+		return
+	}
+	if conf.Timeout, err = pConf.FieldDuration(gsiFieldTimeout); err != nil {
+		return
+	}
+	if conf.CertFile, err = pConf.FieldString(gsiFieldCertFile); err != nil {
+		return
+	}
+	if conf.KeyFile, err = pConf.FieldString(gsiFieldKeyFile); err != nil {
+		return
+	}
+	return
+}
+
+func gsiSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Network").
+		Version("4.29.0").
+		Summary(`Receive messages as gRPC calls to services described by a protobuf descriptor set.`).
+		Description(`
+The services and methods that are served are defined by a descriptor set file, which can be generated with `+"`protoc --include_imports --descriptor_set_out=protos.binpb ./foo.proto`"+`. Unary and client streaming methods are supported, and the request messages of each call are converted into messages following the https://protobuf.dev/programming-guides/proto3/#json[protobuf JSON mapping^].
+
+For client streaming calls each request message of the stream is consumed as a batch of one message, and the next request message is not received until the previous one has been delivered, which applies backpressure to each stream individually.
+
+== Responses
+
+The response of a call is an empty message of the response type of the method, unless a xref:guides:sync_responses.adoc[synchronous response] is provided, in which case the response is the synchronous response converted from the protobuf JSON mapping into the response type. For client streaming calls the response is the last synchronous response provided during the stream.
+
+Calls fail with the status `+"`UNAVAILABLE`"+` when a message could not be delivered, and `+"`DEADLINE_EXCEEDED`"+` when a message is not delivered within the `+"`timeout`"+`.
+
+== Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- grpc_server_method
+- grpc_server_remote_ip
+- All request metadata (only first values are taken)
+`+"```"+`
+
+You can access these metadata fields using xref:configuration:interpolation.adoc#bloblang-queries[function interpolation].`).
+		Fields(
+			service.NewStringField(gsiFieldAddress).
+				Description("The address to listen from.").
+				Example("0.0.0.0:50051"),
+			service.NewStringField(gsiFieldDescriptorSet).
+				Description("The path of a file containing a binary encoded `FileDescriptorSet`, which describes the services to serve along with all of their dependencies.").
+				Example("./protos.binpb"),
+			service.NewStringListField(gsiFieldMethods).
+				Description("An optional list of fully qualified method names to serve, in the form `package.Service/Method`. When empty all unary and client streaming methods of the descriptor set are served.").
+				Example([]any{"helloworld.Greeter/SayHello"}).
+				Default([]any{}),
+			service.NewDurationField(gsiFieldTimeout).
+				Description("The maximum period of time to wait for a message to be delivered before failing the call.").
+				Default("5s"),
+			service.NewStringField(gsiFieldCertFile).
+				Description("Enable TLS by specifying a certificate and key file.").
+				Advanced().
+				Default(""),
+			service.NewStringField(gsiFieldKeyFile).
+				Description("Enable TLS by specifying a certificate and key file.").
+				Advanced().
+				Default(""),
+		).
+		Example(
+			"Greeter Service",
+			"This example serves the `SayHello` method of a `helloworld.Greeter` service, responding to each call with a greeting:", `
+input:
+  grpc_server:
+    address: 0.0.0.0:50051
+    descriptor_set: ./helloworld.binpb
+  processors:
+    - mapping: 'root.message = "Hello " + this.name'
+    - sync_response: {}
+`)
+}
+
+func init() {
+	err := service.RegisterBatchInput(
+		"grpc_server", gsiSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+			gsiConf, err := gsiConfigFromParsed(conf)
+			if err != nil {
+				return nil, err
+			}
+
+			i, err := newGRPCServerInput(gsiConf, interop.UnwrapManagement(mgr))
+			if err != nil {
+				return nil, err
+			}
+			return interop.NewUnwrapInternalInput(i), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// grpcMethodsFromDescriptorSet returns the methods to serve from a descriptor
+// set keyed by their full name as it appears within requests, which is of the
+// form `/package.Service/Method`.
+func grpcMethodsFromDescriptorSet(descBytes []byte, names []string) (map[string]protoreflect.MethodDescriptor, error) {
+	var fds descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(descBytes, &fds); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set: %w", err)
+	}
+	files, err := protodesc.NewFiles(&fds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set: %w", err)
+	}
+
+	available := map[string]protoreflect.MethodDescriptor{}
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		services := fd.Services()
+		for i := 0; i < services.Len(); i++ {
+			methods := services.Get(i).Methods()
+			for j := 0; j < methods.Len(); j++ {
+				m := methods.Get(j)
+				available[fmt.Sprintf("/%v/%v", m.Parent().FullName(), m.Name())] = m
+			}
+		}
+		return true
+	})
+
+	served := map[string]protoreflect.MethodDescriptor{}
+	if len(names) == 0 {
+		for k, m := range available {
+			if !m.IsStreamingServer() {
+				served[k] = m
+			}
+		}
+		if len(served) == 0 {
+			return nil, errors.New("descriptor set does not contain any unary or client streaming methods")
+		}
+		return served, nil
+	}
+
+	for _, name := range names {
+		key := "/" + strings.TrimPrefix(name, "/")
+		m, exists := available[key]
+		if !exists {
+			return nil, fmt.Errorf("method %v was not found in the descriptor set", name)
+		}
+		if m.IsStreamingServer() {
+			return nil, fmt.Errorf("method %v is server streaming, only unary and client streaming methods are supported", name)
+		}
+		served[key] = m
+	}
+	return served, nil
+}
+
+type grpcServerInput struct {
+	conf gsiConfig
+	log  log.Modular
+	mgr  bundle.NewManagement
+
+	methods  map[string]protoreflect.MethodDescriptor
+	listener net.Listener
+	server   *grpc.Server
+
+	handlerWG    sync.WaitGroup
+	transactions chan message.Transaction
+
+	shutSig *shutdown.Signaller
+
+	mRcvd    metrics.StatCounter
+	mLatency metrics.StatTimer
+}
+
+func newGRPCServerInput(conf gsiConfig, mgr bundle.NewManagement) (input.Streamed, error) {
+	descBytes, err := ifs.ReadFile(mgr.FS(), conf.DescriptorSet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+
+	g := &grpcServerInput{
+		conf:         conf,
+		log:          mgr.Logger(),
+		mgr:          mgr,
+		transactions: make(chan message.Transaction),
+		shutSig:      shutdown.NewSignaller(),
+		mRcvd:        mgr.Metrics().GetCounter("input_received"),
+		mLatency:     mgr.Metrics().GetTimer("input_latency_ns"),
+	}
+	if g.methods, err = grpcMethodsFromDescriptorSet(descBytes, conf.Methods); err != nil {
+		return nil, err
+	}
+
+	opts := []grpc.ServerOption{grpc.UnknownServiceHandler(g.handleStream)}
+	if conf.CertFile != "" || conf.KeyFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(conf.CertFile, conf.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	g.server = grpc.NewServer(opts...)
+
+	if g.listener, err = net.Listen("tcp", conf.Address); err != nil {
+		return nil, err
+	}
+
+	go g.loop()
+	return g, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (g *grpcServerInput) requestMessage(ctx context.Context, fullMethod string, req proto.Message) (message.Batch, error) {
+	jBytes, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := message.QuickBatch([][]byte{jBytes})
+	part := msg.Get(0)
+	part.MetaSetMut("grpc_server_method", fullMethod)
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			part.MetaSetMut("grpc_server_remote_ip", host)
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for k, v := range md {
+		if len(v) > 0 {
+			part.MetaSetMut(k, v[0])
+		}
+	}
+
+	textMapGeneric := map[string]any{}
+	for k, vals := range md {
+		for _, v := range vals {
+			textMapGeneric[k] = v
+		}
+	}
+	_ = tracing.InitSpansFromParentTextMap(g.mgr.Tracer(), "input_grpc_server", textMapGeneric, msg)
+	return msg, nil
+}
+
+// deliver sends a message through the pipeline and returns the synchronous
+// response, if any, once it has been delivered.
+func (g *grpcServerInput) deliver(ctx context.Context, msg message.Batch) (message.Batch, error) {
+	startedAt := time.Now()
+
+	store := transaction.NewResultStore()
+	transaction.AddResultStore(msg, store)
+
+	g.mRcvd.Incr(int64(msg.Len()))
+
+	resChan := make(chan error, 1)
+	select {
+	case g.transactions <- message.NewTransaction(msg, resChan):
+	case <-time.After(g.conf.Timeout):
+		return nil, status.Error(codes.DeadlineExceeded, "timed out waiting for message to be consumed")
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	case <-g.shutSig.SoftStopChan():
+		return nil, status.Error(codes.Unavailable, "server closing")
+	}
+
+	select {
+	case res, open := <-resChan:
+		if !open {
+			return nil, status.Error(codes.Unavailable, "server closing")
+		}
+		if res != nil {
+			return nil, status.Error(codes.Unavailable, res.Error())
+		}
+	case <-time.After(g.conf.Timeout):
+		return nil, status.Error(codes.DeadlineExceeded, "timed out waiting for message to be delivered")
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	case <-g.shutSig.HardStopChan():
+		return nil, status.Error(codes.Unavailable, "server closing")
+	}
+	g.mLatency.Timing(time.Since(startedAt).Nanoseconds())
+
+	var response message.Batch
+	for _, resMsg := range store.Get() {
+		response = append(response, resMsg...)
+	}
+	return response, nil
+}
+
+func (g *grpcServerInput) handleStream(_ any, stream grpc.ServerStream) error {
+	if g.shutSig.IsSoftStopSignalled() {
+		return status.Error(codes.Unavailable, "server closing")
+	}
+
+	g.handlerWG.Add(1)
+	defer g.handlerWG.Done()
+
+	fullMethod, _ := grpc.MethodFromServerStream(stream)
+	method, exists := g.methods[fullMethod]
+	if !exists {
+		return status.Errorf(codes.Unimplemented, "unknown method %v", fullMethod)
+	}
+
+	var response message.Batch
+	for {
+		req := dynamicpb.NewMessage(method.Input())
+		if err := stream.RecvMsg(req); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+
+		msg, err := g.requestMessage(stream.Context(), fullMethod, req)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "failed to convert request message: %v", err)
+		}
+
+		resMsg, err := g.deliver(stream.Context(), msg)
+		tracing.FinishSpans(msg)
+		if err != nil {
+			return err
+		}
+		if len(resMsg) > 0 {
+			response = resMsg
+		}
+
+		if !method.IsStreamingClient() {
+			break
+		}
+	}
+
+	res := dynamicpb.NewMessage(method.Output())
+	if len(response) > 0 {
+		if err := protojson.Unmarshal(response.Get(0).AsBytes(), res); err != nil {
+			g.log.Error("Failed to convert sync response to %v: %v\n", method.Output().FullName(), err)
+			return status.Errorf(codes.Internal, "failed to convert response message: %v", err)
+		}
+	}
+	return stream.SendMsg(res)
+}
+
+//------------------------------------------------------------------------------
+
+func (g *grpcServerInput) loop() {
+	defer func() {
+		stopped := make(chan struct{})
+		go func() {
+			g.server.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-g.shutSig.HardStopChan():
+			g.server.Stop()
+			<-stopped
+		}
+
+		g.handlerWG.Wait()
+
+		close(g.transactions)
+		g.shutSig.TriggerHasStopped()
+	}()
+
+	go func() {
+		g.log.Info("Receiving gRPC messages at: %v\n", g.listener.Addr())
+		if err := g.server.Serve(g.listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			g.log.Error("Server error: %v\n", err)
+		}
+	}()
+
+	<-g.shutSig.SoftStopChan()
+}
+
+// TransactionChan returns a transactions channel for consuming messages from
+// this input.
+func (g *grpcServerInput) TransactionChan() <-chan message.Transaction {
+	return g.transactions
+}
+
+// Connected returns a boolean indicating whether this input is currently
+// connected to its target.
+func (g *grpcServerInput) Connected() bool {
+	return true
+}
+
+func (g *grpcServerInput) TriggerStopConsuming() {
+	g.shutSig.TriggerSoftStop()
+}
+
+func (g *grpcServerInput) TriggerCloseNow() {
+	g.shutSig.TriggerHardStop()
+}
+
+func (g *grpcServerInput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-g.shutSig.HasStoppedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
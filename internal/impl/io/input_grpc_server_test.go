@@ -0,0 +1,297 @@
+package io_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/redpanda-data/benthos/v4/internal/component/input"
+	"github.com/redpanda-data/benthos/v4/internal/filepath/ifs"
+	"github.com/redpanda-data/benthos/v4/internal/manager/mock"
+	"github.com/redpanda-data/benthos/v4/internal/message"
+	"github.com/redpanda-data/benthos/v4/internal/transaction"
+)
+
+func greeterFileDescriptor() *descriptorpb.FileDescriptorProto {
+	stringField := func(name string) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(1),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		}
+	}
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("greeter.proto"),
+		Package: proto.String("testing"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("HelloRequest"), Field: []*descriptorpb.FieldDescriptorProto{stringField("name")}},
+			{Name: proto.String("HelloReply"), Field: []*descriptorpb.FieldDescriptorProto{stringField("message")}},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Greeter"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				{
+					Name:       proto.String("SayHello"),
+					InputType:  proto.String(".testing.HelloRequest"),
+					OutputType: proto.String(".testing.HelloReply"),
+				},
+				{
+					Name:            proto.String("SayHellos"),
+					InputType:       proto.String(".testing.HelloRequest"),
+					OutputType:      proto.String(".testing.HelloReply"),
+					ClientStreaming: proto.Bool(true),
+				},
+				{
+					Name:            proto.String("ListHellos"),
+					InputType:       proto.String(".testing.HelloRequest"),
+					OutputType:      proto.String(".testing.HelloReply"),
+					ServerStreaming: proto.Bool(true),
+				},
+			},
+		}},
+	}
+}
+
+func writeGreeterDescriptorSet(t testing.TB) (string, protoreflect.FileDescriptor) {
+	t.Helper()
+
+	fdProto := greeterFileDescriptor()
+	fd, err := protodesc.NewFile(fdProto, nil)
+	require.NoError(t, err)
+
+	b, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{fdProto},
+	})
+	require.NoError(t, err)
+
+	descPath := filepath.Join(t.TempDir(), "greeter.binpb")
+	require.NoError(t, os.WriteFile(descPath, b, 0o644))
+	return descPath, fd
+}
+
+func startGRPCServerInput(t testing.TB, extraConf string) (input.Streamed, *grpc.ClientConn, protoreflect.FileDescriptor) {
+	t.Helper()
+
+	descPath, fd := writeGreeterDescriptorSet(t)
+	address := fmt.Sprintf("127.0.0.1:%v", getFreePort(t))
+
+	conf := parseYAMLInputConf(t, `
+grpc_server:
+  address: %v
+  descriptor_set: %v
+%v`, address, descPath, extraConf)
+
+	in, err := mock.NewManager().NewInput(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+		defer done()
+		in.TriggerStopConsuming()
+		assert.NoError(t, in.WaitForClose(ctx))
+	})
+
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return in, conn, fd
+}
+
+func greeterMessages(fd protoreflect.FileDescriptor, name string) (req, reply *dynamicpb.Message) {
+	req = dynamicpb.NewMessage(fd.Messages().ByName("HelloRequest"))
+	req.Set(req.Descriptor().Fields().ByName("name"), protoreflect.ValueOfString(name))
+	reply = dynamicpb.NewMessage(fd.Messages().ByName("HelloReply"))
+	return
+}
+
+func replyMessage(reply *dynamicpb.Message) string {
+	return reply.Get(reply.Descriptor().Fields().ByName("message")).String()
+}
+
+func readGRPCTransaction(t testing.TB, in input.Streamed) message.Transaction {
+	t.Helper()
+	select {
+	case tran := <-in.TransactionChan():
+		return tran
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for message")
+	}
+	return message.Transaction{}
+}
+
+func TestGRPCServerUnary(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	in, conn, fd := startGRPCServerInput(t, "")
+
+	go func() {
+		tran := readGRPCTransaction(t, in)
+		part := tran.Payload.Get(0)
+		assert.Equal(t, `{"name":"jeff"}`, string(part.AsBytes()))
+		assert.Equal(t, "/testing.Greeter/SayHello", part.MetaGetStr("grpc_server_method"))
+		assert.Equal(t, "127.0.0.1", part.MetaGetStr("grpc_server_remote_ip"))
+		assert.Equal(t, "bar", part.MetaGetStr("x-foo"))
+
+		part.SetBytes([]byte(`{"message":"hello jeff"}`))
+		assert.NoError(t, transaction.SetAsResponse(tran.Payload))
+		assert.NoError(t, tran.Ack(tCtx, nil))
+	}()
+
+	req, reply := greeterMessages(fd, "jeff")
+	ctx := metadata.AppendToOutgoingContext(tCtx, "x-foo", "bar")
+	require.NoError(t, conn.Invoke(ctx, "/testing.Greeter/SayHello", req, reply))
+	assert.Equal(t, "hello jeff", replyMessage(reply))
+
+	// Without a sync response the reply is empty.
+	go func() {
+		tran := readGRPCTransaction(t, in)
+		assert.NoError(t, tran.Ack(tCtx, nil))
+	}()
+
+	req, reply = greeterMessages(fd, "jeff")
+	require.NoError(t, conn.Invoke(tCtx, "/testing.Greeter/SayHello", req, reply))
+	assert.Equal(t, "", replyMessage(reply))
+
+	// Rejected messages fail the call.
+	go func() {
+		tran := readGRPCTransaction(t, in)
+		assert.NoError(t, tran.Ack(tCtx, errors.New("nope")))
+	}()
+
+	req, reply = greeterMessages(fd, "jeff")
+	err := conn.Invoke(tCtx, "/testing.Greeter/SayHello", req, reply)
+	require.Error(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "nope")
+}
+
+func TestGRPCServerClientStreaming(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	in, conn, fd := startGRPCServerInput(t, "")
+
+	stream, err := conn.NewStream(tCtx, &grpc.StreamDesc{ClientStreams: true}, "/testing.Greeter/SayHellos")
+	require.NoError(t, err)
+
+	for _, name := range []string{"foo", "bar", "baz"} {
+		req, _ := greeterMessages(fd, name)
+		require.NoError(t, stream.SendMsg(req))
+
+		tran := readGRPCTransaction(t, in)
+		part := tran.Payload.Get(0)
+		assert.Equal(t, fmt.Sprintf(`{"name":"%v"}`, name), string(part.AsBytes()))
+		assert.Equal(t, "/testing.Greeter/SayHellos", part.MetaGetStr("grpc_server_method"))
+
+		if name != "baz" {
+			part.SetBytes([]byte(fmt.Sprintf(`{"message":"last was %v"}`, name)))
+			require.NoError(t, transaction.SetAsResponse(tran.Payload))
+		}
+		require.NoError(t, tran.Ack(tCtx, nil))
+	}
+	require.NoError(t, stream.CloseSend())
+
+	_, reply := greeterMessages(fd, "")
+	require.NoError(t, stream.RecvMsg(reply))
+	assert.Equal(t, "last was bar", replyMessage(reply))
+}
+
+func TestGRPCServerUnknownMethods(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	_, conn, fd := startGRPCServerInput(t, `  methods: [ testing.Greeter/SayHellos ]`)
+
+	req, reply := greeterMessages(fd, "jeff")
+	err := conn.Invoke(tCtx, "/testing.Greeter/SayHello", req, reply)
+	require.Error(t, err)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	err = conn.Invoke(tCtx, "/testing.Nope/Nope", req, reply)
+	require.Error(t, err)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestGRPCServerBadMethods(t *testing.T) {
+	descPath, _ := writeGreeterDescriptorSet(t)
+
+	for _, test := range []struct {
+		method string
+		err    string
+	}{
+		{method: "testing.Greeter/Nope", err: "method testing.Greeter/Nope was not found in the descriptor set"},
+		{method: "testing.Greeter/ListHellos", err: "method testing.Greeter/ListHellos is server streaming"},
+	} {
+		conf := parseYAMLInputConf(t, `
+grpc_server:
+  address: 127.0.0.1:0
+  descriptor_set: %v
+  methods: [ %v ]
+`, descPath, test.method)
+
+		_, err := mock.NewManager().NewInput(conf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), test.err)
+	}
+}
+
+type grpcDirFS struct {
+	ifs.FS
+	dir string
+}
+
+func (d grpcDirFS) Open(name string) (fs.File, error) {
+	return d.FS.Open(filepath.Join(d.dir, name))
+}
+
+func (d grpcDirFS) OpenFile(name string, flag int, perm fs.FileMode) (fs.File, error) {
+	return d.FS.OpenFile(filepath.Join(d.dir, name), flag, perm)
+}
+
+func TestGRPCServerDescriptorSetCustomFS(t *testing.T) {
+	descPath, _ := writeGreeterDescriptorSet(t)
+
+	conf := parseYAMLInputConf(t, `
+grpc_server:
+  address: 127.0.0.1:0
+  descriptor_set: %v
+`, filepath.Base(descPath))
+
+	_, err := mock.NewManager().NewInput(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read descriptor set")
+
+	mgr := mock.NewManager()
+	mgr.CustomFS = grpcDirFS{FS: ifs.OS(), dir: filepath.Dir(descPath)}
+
+	in, err := mgr.NewInput(conf)
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+	in.TriggerStopConsuming()
+	require.NoError(t, in.WaitForClose(ctx))
+}
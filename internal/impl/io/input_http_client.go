@@ -3,12 +3,17 @@ package io
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/redpanda-data/benthos/v4/internal/component"
 	"github.com/redpanda-data/benthos/v4/internal/httpclient"
+	"github.com/redpanda-data/benthos/v4/internal/value"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
 	"github.com/redpanda-data/benthos/v4/public/service"
 	"github.com/redpanda-data/benthos/v4/public/service/codec"
 )
@@ -24,6 +29,34 @@ func httpClientInputSpec() *service.ConfigSpec {
 		Description("Allows you to set streaming mode, where requests are kept open and messages are processed line-by-line.").
 		Optional()
 
+	paginationField := service.NewObjectField("pagination",
+		service.NewBloblangField("next").
+			Description("A mapping executed against each response that derives the next request, or `null` once the last page has been consumed. The mapping can result in either a string, which is the URL of the next request, or an object with any of the fields `url`, `headers` and `payload`, which replace the respective parts of the next request.").
+			Examples(
+				`root = if this.next_cursor != null { "https://api.example.com/items?cursor=" + this.next_cursor.escape_url_query() } else { null }`,
+				`root = @link.or("").re_find_object("<(?P<url>[^>]+)>;\\s*rel=\"next\"").url`,
+			),
+		service.NewBoolField("restart").
+			Description("Whether to begin again from the initial request once the last page has been consumed, otherwise the input shuts down.").
+			Default(true),
+	).
+		Description("Allows you to consume paginated APIs by deriving each request from the response of the previous one. If the mapping fails the error is logged and the response is treated as the last page. Pagination is not supported in streaming mode.").
+		Version("4.29.0").
+		Optional()
+
+	rateLimitHeadersField := service.NewObjectField("rate_limit_headers",
+		service.NewBoolField("enabled").
+			Description("Whether to delay the next request according to rate limit headers of responses.").
+			Default(false),
+		service.NewDurationField("max_delay").
+			Description("The maximum period of time to delay the next request by, regardless of the headers of a response.").
+			Default("10m"),
+	).
+		Description("Allows you to delay the next request when a response contains a `Retry-After` header, or when it indicates that a rate limit has been exhausted with either a 429 status code or a remaining count of zero, in which case the reset of the rate limit is read from either the `X-RateLimit-Reset` or `RateLimit-Reset` header. Not supported in streaming mode.").
+		Version("4.29.0").
+		Advanced().
+		Optional()
+
 	return service.NewConfigSpec().
 		Stable().
 		Categories("Network").
@@ -37,7 +70,19 @@ If you enable streaming then Benthos will consume the body of the response as a
 
 == Pagination
 
-This input supports interpolation functions in the `+"`url` and `headers`"+` fields where data from the previous successfully consumed message (if there was one) can be referenced. This can be used in order to support basic levels of pagination. However, in cases where pagination depends on logic it is recommended that you use an `+"xref:components:processors/http.adoc[`http` processor] instead, often combined with a xref:components:inputs/generate.adoc[`generate` input]"+` in order to schedule the processor.`).
+This input supports interpolation functions in the `+"`url` and `headers`"+` fields where data from the previous successfully consumed message (if there was one) can be referenced. This can be used in order to support basic levels of pagination.
+
+For pagination that depends on logic the `+"`pagination.next`"+` field can be used, which is a xref:guides:bloblang/about.adoc[Bloblang mapping] executed against each response that derives the next request, and pages are consumed until the mapping results in `+"`null`"+`. The mapping is executed against the first message of each response, with all of the response headers added as metadata (with lowercase keys) regardless of the `+"`extract_headers`"+` field, along with the metadata fields `+"`http_status_code`"+` and `+"`http_client_request_url`"+`, which is the URL of the request that the response is for. This allows pagination by a cursor within the response body, an offset within the request URL, or a link within a `+"`Link`"+` header.
+
+Once the last page has been consumed the next request is the initial request again, which is performed according to any `+"`rate_limit`"+` specified, unless `+"`pagination.restart`"+` is set to `+"`false`"+`, in which case the input shuts down.
+
+== Conditional Requests
+
+When `+"`conditional_requests`"+` is set to `+"`true`"+` the `+"`ETag`"+` and `+"`Last-Modified`"+` headers of each response are sent as `+"`If-None-Match`"+` and `+"`If-Modified-Since`"+` headers respectively within the next request to the same URL. Servers that support conditional requests then respond with a 304 Not Modified status when the resource hasn't changed, in which case no messages are produced.
+
+== Rate Limit Headers
+
+Many APIs communicate when clients should next perform a request with headers such as `+"`Retry-After`"+` and `+"`X-RateLimit-Reset`"+`, and by enabling `+"`rate_limit_headers`"+` the next request is delayed accordingly. This works alongside the `+"`rate_limit`"+` field, which limits the rate of requests regardless of the responses received.`).
 		Example(
 			"Basic Pagination",
 			"Interpolation functions within the `url` and `headers` fields can be used to reference the previously consumed message, which allows simple pagination.",
@@ -61,12 +106,66 @@ rate_limit_resources:
     local:
       count: 1
       interval: 30s
+`,
+		).
+		Example(
+			"Cursor Pagination",
+			"The `pagination.next` mapping can derive the next request from a cursor within each response, and here we consume every page of items once before shutting down.",
+			`
+input:
+  http_client:
+    url: https://api.example.com/items?limit=100
+    verb: GET
+    pagination:
+      next: |
+        root = if this.next_cursor != null {
+          "https://api.example.com/items?limit=100&cursor=" + this.next_cursor.escape_url_query()
+        } else {
+          null
+        }
+      restart: false
+  processors:
+    - mapping: 'root = this.items'
+    - unarchive:
+        format: json_array
+`,
+		).
+		Example(
+			"Offset Pagination",
+			"The URL of each request is available to the `pagination.next` mapping, which allows an offset to be incremented until a page with fewer items than the limit is received. Once the last page is consumed the pages are polled again from the beginning at a rate of once per minute.",
+			`
+input:
+  http_client:
+    url: https://api.example.com/items?limit=100&offset=0
+    verb: GET
+    rate_limit: poll_items
+    pagination:
+      next: |
+        let offset = @http_client_request_url.parse_url().query.offset.number()
+        root = if this.items.length() == 100 {
+          "https://api.example.com/items?limit=100&offset=" + ($offset + 100).string()
+        } else {
+          null
+        }
+
+rate_limit_resources:
+  - label: poll_items
+    local:
+      count: 1
+      interval: 1m
 `,
 		).
 		Field(httpclient.ConfigField("GET", false,
 			service.NewInterpolatedStringField("payload").Description("An optional payload to deliver for each request.").Optional(),
 			service.NewBoolField("drop_empty_bodies").Description("Whether empty payloads received from the target server should be dropped.").Default(true).Advanced(),
 			streamField,
+			paginationField,
+			service.NewBoolField("conditional_requests").
+				Description("Whether to perform conditional requests using the `ETag` and `Last-Modified` headers of previous responses, where responses with a 304 Not Modified status produce no messages. Not supported in streaming mode or with pagination.").
+				Version("4.29.0").
+				Advanced().
+				Default(false),
+			rateLimitHeadersField,
 		)).
 		Field(service.NewAutoRetryNacksToggleField())
 }
@@ -98,6 +197,18 @@ type httpClientInput struct {
 
 	codecMut sync.Mutex
 	codec    codec.DeprecatedFallbackStream
+
+	pagination        *bloblang.Executor
+	paginationRestart bool
+	paginationDone    bool
+	nextPage          *httpclient.RequestOverrides
+
+	validators        *httpclient.ConditionalValidators
+	rateLimitHeaders  bool
+	rateLimitMaxDelay time.Duration
+	pollAfter         time.Time
+
+	log *service.Logger
 }
 
 func newHTTPClientInputFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (*httpClientInput, error) {
@@ -133,7 +244,55 @@ func newHTTPClientInputFromParsed(conf *service.ParsedConfig, mgr *service.Resou
 		return nil, err
 	}
 
-	client, err := httpclient.NewClientFromOldConfig(oldConf, mgr, httpclient.WithExplicitBody(payloadExpr))
+	var pagination *bloblang.Executor
+	var paginationRestart bool
+	if conf.Contains("pagination") {
+		if streamEnabled {
+			return nil, errors.New("pagination cannot be used in streaming mode")
+		}
+		if pagination, err = conf.FieldBloblang("pagination", "next"); err != nil {
+			return nil, err
+		}
+		if paginationRestart, err = conf.FieldBool("pagination", "restart"); err != nil {
+			return nil, err
+		}
+	}
+
+	opts := []httpclient.RequestOpt{httpclient.WithExplicitBody(payloadExpr)}
+
+	conditional, err := conf.FieldBool("conditional_requests")
+	if err != nil {
+		return nil, err
+	}
+
+	var validators *httpclient.ConditionalValidators
+	if conditional {
+		if streamEnabled {
+			return nil, errors.New("conditional requests cannot be used in streaming mode")
+		}
+		if pagination != nil {
+			return nil, errors.New("conditional requests cannot be used with pagination")
+		}
+		validators = httpclient.NewConditionalValidators()
+		opts = append(opts, httpclient.WithConditionalValidators(validators))
+		oldConf.SuccessfulOn = append(oldConf.SuccessfulOn, http.StatusNotModified)
+	}
+
+	var rateLimitHeaders bool
+	var rateLimitMaxDelay time.Duration
+	if conf.Contains("rate_limit_headers") {
+		if rateLimitHeaders, err = conf.FieldBool("rate_limit_headers", "enabled"); err != nil {
+			return nil, err
+		}
+		if rateLimitHeaders && streamEnabled {
+			return nil, errors.New("rate limit headers cannot be used in streaming mode")
+		}
+		if rateLimitMaxDelay, err = conf.FieldDuration("rate_limit_headers", "max_delay"); err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := httpclient.NewClientFromOldConfig(oldConf, mgr, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -146,6 +305,15 @@ func newHTTPClientInputFromParsed(conf *service.ParsedConfig, mgr *service.Resou
 		reconnectStream: reconnectStream,
 
 		codecCtor: codecCtor,
+
+		pagination:        pagination,
+		paginationRestart: paginationRestart,
+
+		validators:        validators,
+		rateLimitHeaders:  rateLimitHeaders,
+		rateLimitMaxDelay: rateLimitMaxDelay,
+
+		log: mgr.Logger(),
 	}, nil
 }
 
@@ -249,14 +417,142 @@ func (h *httpClientInput) readStreamed(ctx context.Context) (service.MessageBatc
 	}, nil
 }
 
+// pageFromResponse executes the pagination mapping against a response in order
+// to derive the next request, returning nil once the last page is reached.
+func (h *httpClientInput) pageFromResponse(msg service.MessageBatch, res *http.Response) (*httpclient.RequestOverrides, error) {
+	var p *service.Message
+	if len(msg) > 0 {
+		p = msg[0].Copy()
+	} else {
+		p = service.NewMessage(nil)
+	}
+	for k, values := range res.Header {
+		if len(values) > 0 {
+			p.MetaSetMut(strings.ToLower(k), values[0])
+		}
+	}
+	p.MetaSetMut("http_status_code", res.StatusCode)
+	if res.Request != nil {
+		p.MetaSetMut("http_client_request_url", res.Request.URL.String())
+	}
+
+	resMsg, err := p.BloblangQuery(h.pagination)
+	if err != nil {
+		return nil, fmt.Errorf("pagination mapping failed: %w", err)
+	}
+	if resMsg == nil {
+		return nil, nil
+	}
+
+	// String results are stored as raw bytes and are therefore not parsable
+	// as structured values.
+	v, err := resMsg.AsStructured()
+	if err != nil {
+		b, _ := resMsg.AsBytes()
+		return &httpclient.RequestOverrides{URL: string(b)}, nil
+	}
+
+	switch t := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return &httpclient.RequestOverrides{URL: t}, nil
+	case map[string]any:
+		page := &httpclient.RequestOverrides{}
+		for k, fv := range t {
+			switch k {
+			case "url":
+				if page.URL, err = value.IGetString(fv); err != nil {
+					return nil, fmt.Errorf("pagination mapping field url: %w", err)
+				}
+			case "headers":
+				headers, ok := fv.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("pagination mapping field headers: expected object value, got %v", value.ITypeOf(fv))
+				}
+				page.Headers = make(map[string]string, len(headers))
+				for hk, hv := range headers {
+					page.Headers[hk] = value.IToString(hv)
+				}
+			case "payload":
+				page.Body = value.IToBytes(fv)
+			default:
+				return nil, fmt.Errorf("pagination mapping field %v not recognised", k)
+			}
+		}
+		return page, nil
+	}
+	return nil, fmt.Errorf("pagination mapping resulted in unexpected type %v, expected string, object or null", value.ITypeOf(v))
+}
+
+// delayNextPoll schedules the next request according to any rate limit headers
+// of a response.
+func (h *httpClientInput) delayNextPoll(code int, header http.Header) {
+	if !h.rateLimitHeaders {
+		return
+	}
+	delay, found := httpclient.RateLimitDelay(code, header, time.Now())
+	if !found {
+		return
+	}
+	if delay > h.rateLimitMaxDelay {
+		delay = h.rateLimitMaxDelay
+	}
+	h.log.Debugf("Delaying next request by %v due to rate limit headers of response", delay)
+	h.pollAfter = time.Now().Add(delay)
+}
+
 func (h *httpClientInput) readNotStreamed(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
-	msg, err := h.client.Send(ctx, h.prevResponse)
+	if h.paginationDone {
+		return nil, nil, service.ErrEndOfInput
+	}
+
+	if !h.pollAfter.IsZero() {
+		select {
+		case <-time.After(time.Until(h.pollAfter)):
+		case <-ctx.Done():
+			return nil, nil, component.ErrTimeout
+		}
+		h.pollAfter = time.Time{}
+	}
+
+	res, err := h.client.SendToResponseWithOverrides(ctx, h.prevResponse, h.nextPage)
 	if err != nil {
+		var resErr httpclient.ErrUnexpectedHTTPRes
+		if errors.As(err, &resErr) {
+			h.delayNextPoll(resErr.Code, resErr.Header)
+		}
 		if strings.Contains(err.Error(), "(Client.Timeout exceeded while awaiting headers)") {
 			err = component.ErrTimeout
 		}
 		return nil, nil, err
 	}
+	h.delayNextPoll(res.StatusCode, res.Header)
+
+	if h.validators != nil {
+		if res.StatusCode == http.StatusNotModified {
+			res.Body.Close()
+			return nil, nil, component.ErrTimeout
+		}
+		h.validators.Update(res)
+	}
+
+	msg, err := h.client.ResponseToBatch(res)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if h.pagination != nil {
+		// A failed pagination mapping is treated as the last page, as the page
+		// itself was consumed successfully.
+		nextPage, err := h.pageFromResponse(msg, res)
+		if err != nil {
+			h.log.Errorf("Ending pagination: %v", err)
+		}
+		if h.nextPage = nextPage; h.nextPage == nil && !h.paginationRestart {
+			h.paginationDone = true
+		}
+	}
 
 	if len(msg) == 0 {
 		return nil, nil, component.ErrTimeout
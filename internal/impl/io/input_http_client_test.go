@@ -154,6 +154,352 @@ http_client:
 	}
 }
 
+func readHTTPClientPages(t *testing.T, h input.Streamed, n int) (pages []string) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case tr, open := <-h.TransactionChan():
+			require.True(t, open)
+			pages = append(pages, string(tr.Payload.Get(0).AsBytes()))
+			require.NoError(t, tr.Ack(context.Background(), nil))
+		case <-time.After(time.Second * 5):
+			t.Fatal("Action timed out")
+		}
+	}
+	return
+}
+
+func TestHTTPClientPaginationCursor(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch cursor := r.URL.Query().Get("cursor"); cursor {
+		case "":
+			_, _ = w.Write([]byte(`{"page":1,"next_cursor":"b"}`))
+		case "b":
+			_, _ = w.Write([]byte(`{"page":2,"next_cursor":"c"}`))
+		case "c":
+			_, _ = w.Write([]byte(`{"page":3}`))
+		default:
+			t.Errorf("unexpected cursor: %v", cursor)
+		}
+	}))
+	defer ts.Close()
+
+	conf := parseYAMLInputConf(t, `
+http_client:
+  url: %v/items
+  retry_period: 1ms
+  pagination:
+    next: |
+      root = if this.next_cursor != null {
+        @http_client_request_url.parse_url().without("query", "raw_query").format_url() + "?cursor=" + this.next_cursor
+      } else {
+        null
+      }
+    restart: false
+`, ts.URL)
+
+	h, err := mock.NewManager().NewInput(conf)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		`{"page":1,"next_cursor":"b"}`,
+		`{"page":2,"next_cursor":"c"}`,
+		`{"page":3}`,
+	}, readHTTPClientPages(t, h, 3))
+
+	select {
+	case _, open := <-h.TransactionChan():
+		require.False(t, open)
+	case <-time.After(time.Second * 5):
+		t.Fatal("Action timed out")
+	}
+	require.NoError(t, h.WaitForClose(tCtx))
+}
+
+func TestHTTPClientPaginationMappingError(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch cursor := r.URL.Query().Get("cursor"); cursor {
+		case "":
+			_, _ = w.Write([]byte(`{"page":1,"next_cursor":"b"}`))
+		case "b":
+			_, _ = w.Write([]byte(`{"page":2,"next_cursor":"c"}`))
+		default:
+			t.Errorf("unexpected cursor: %v", cursor)
+		}
+	}))
+	defer ts.Close()
+
+	conf := parseYAMLInputConf(t, `
+http_client:
+  url: %v/items
+  retry_period: 1ms
+  pagination:
+    next: |
+      root = if this.page == 2 {
+        throw("no more pages")
+      } else {
+        @http_client_request_url.parse_url().without("query", "raw_query").format_url() + "?cursor=" + this.next_cursor
+      }
+    restart: false
+`, ts.URL)
+
+	h, err := mock.NewManager().NewInput(conf)
+	require.NoError(t, err)
+
+	// The page that the mapping failed on is still consumed, after which
+	// pagination ends.
+	assert.Equal(t, []string{
+		`{"page":1,"next_cursor":"b"}`,
+		`{"page":2,"next_cursor":"c"}`,
+	}, readHTTPClientPages(t, h, 2))
+
+	select {
+	case _, open := <-h.TransactionChan():
+		require.False(t, open)
+	case <-time.After(time.Second * 5):
+		t.Fatal("Action timed out")
+	}
+	require.NoError(t, h.WaitForClose(tCtx))
+}
+
+func TestHTTPClientPaginationLinkHeader(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		if page == "1" {
+			w.Header().Set("Link", fmt.Sprintf(`<%v/items?page=2>; rel="next", <%v/items?page=2>; rel="last"`, ts.URL, ts.URL))
+		}
+		_, _ = w.Write([]byte("page " + page))
+	}))
+	defer ts.Close()
+
+	conf := parseYAMLInputConf(t, `
+http_client:
+  url: %v/items
+  retry_period: 1ms
+  pagination:
+    next: 'root = @link.or("").re_find_object("<(?P<url>[^>]+)>;\\s*rel=\"next\"").url'
+`, ts.URL)
+
+	h, err := mock.NewManager().NewInput(conf)
+	require.NoError(t, err)
+
+	// Once the last page is consumed the pages begin again.
+	assert.Equal(t, []string{"page 1", "page 2", "page 1", "page 2"}, readHTTPClientPages(t, h, 4))
+
+	h.TriggerStopConsuming()
+	require.NoError(t, h.WaitForClose(tCtx))
+}
+
+func TestHTTPClientPaginationObject(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqBytes, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		_, _ = fmt.Fprintf(w, "%v %v %v %v", r.Method, r.URL.Path, r.Header.Get("X-Page"), string(reqBytes))
+	}))
+	defer ts.Close()
+
+	conf := parseYAMLInputConf(t, `
+http_client:
+  url: %v/first
+  verb: POST
+  payload: initial
+  headers:
+    X-Page: "0"
+  retry_period: 1ms
+  pagination:
+    next: |
+      root = if content().has_suffix("initial") {
+        {
+          "url": @http_client_request_url.replace_all("/first", "/second"),
+          "headers": { "X-Page": 1 },
+          "payload": "next"
+        }
+      } else {
+        null
+      }
+`, ts.URL)
+
+	h, err := mock.NewManager().NewInput(conf)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"POST /first 0 initial",
+		"POST /second 1 next",
+		"POST /first 0 initial",
+	}, readHTTPClientPages(t, h, 3))
+
+	h.TriggerStopConsuming()
+	require.NoError(t, h.WaitForClose(tCtx))
+}
+
+func TestHTTPClientPaginationStreamed(t *testing.T) {
+	conf := parseYAMLInputConf(t, `
+http_client:
+  url: http://localhost:1234
+  stream:
+    enabled: true
+  pagination:
+    next: 'root = null'
+`)
+
+	_, err := mock.NewManager().NewInput(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pagination cannot be used in streaming mode")
+}
+
+func TestHTTPClientConditionalRequests(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	var reqCount, notModifiedCount int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"v1"`
+		if atomic.AddInt32(&reqCount, 1) > 3 {
+			etag = `"v2"`
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			atomic.AddInt32(&notModifiedCount, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte("content " + etag))
+	}))
+	defer ts.Close()
+
+	conf := parseYAMLInputConf(t, `
+http_client:
+  url: %v/resource
+  retry_period: 1ms
+  conditional_requests: true
+`, ts.URL)
+
+	h, err := mock.NewManager().NewInput(conf)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{`content "v1"`, `content "v2"`}, readHTTPClientPages(t, h, 2))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&notModifiedCount))
+
+	h.TriggerStopConsuming()
+	require.NoError(t, h.WaitForClose(tCtx))
+}
+
+func TestHTTPClientRateLimitHeaders(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	var reqCount int32
+	reqTimes := make(chan time.Time, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqTimes <- time.Now()
+		switch atomic.AddInt32(&reqCount, 1) {
+		case 1:
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "0.2")
+		case 2:
+			// Capped by the max delay.
+			w.Header().Set("Retry-After", "3600")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte("hello world"))
+	}))
+	defer ts.Close()
+
+	conf := parseYAMLInputConf(t, `
+http_client:
+  url: %v/resource
+  retry_period: 1ms
+  retries: 0
+  backoff_on: []
+  rate_limit_headers:
+    enabled: true
+    max_delay: 300ms
+`, ts.URL)
+
+	h, err := mock.NewManager().NewInput(conf)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"hello world", "hello world"}, readHTTPClientPages(t, h, 2))
+
+	var times []time.Time
+	for i := 0; i < 3; i++ {
+		times = append(times, <-reqTimes)
+	}
+	assert.GreaterOrEqual(t, times[1].Sub(times[0]), time.Millisecond*200)
+	assert.GreaterOrEqual(t, times[2].Sub(times[1]), time.Millisecond*300)
+	assert.Less(t, times[2].Sub(times[1]), time.Second*3)
+
+	h.TriggerStopConsuming()
+	require.NoError(t, h.WaitForClose(tCtx))
+}
+
+func TestHTTPClientPollingBadConfig(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		config      string
+		errContains string
+	}{
+		{
+			name: "conditional streamed",
+			config: `
+http_client:
+  url: http://localhost:1234
+  conditional_requests: true
+  stream:
+    enabled: true
+`,
+			errContains: "conditional requests cannot be used in streaming mode",
+		},
+		{
+			name: "conditional paginated",
+			config: `
+http_client:
+  url: http://localhost:1234
+  conditional_requests: true
+  pagination:
+    next: 'root = null'
+`,
+			errContains: "conditional requests cannot be used with pagination",
+		},
+		{
+			name: "rate limit headers streamed",
+			config: `
+http_client:
+  url: http://localhost:1234
+  rate_limit_headers:
+    enabled: true
+  stream:
+    enabled: true
+`,
+			errContains: "rate limit headers cannot be used in streaming mode",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			_, err := mock.NewManager().NewInput(parseYAMLInputConf(t, test.config))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.errContains)
+		})
+	}
+}
+
 func TestHTTPClientGETError(t *testing.T) {
 	t.Parallel()
 
@@ -1,6 +1,7 @@
 package io
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/tls"
@@ -44,6 +45,8 @@ const (
 	hsiFieldWSPath                  = "ws_path"
 	hsiFieldWSWelcomeMessage        = "ws_welcome_message"
 	hsiFieldWSRateLimitMessage      = "ws_rate_limit_message"
+	hsiFieldSSEPath                 = "sse_path"
+	hsiFieldSSEHeartbeatInterval    = "sse_heartbeat_interval"
 	hsiFieldAllowedVerbs            = "allowed_verbs"
 	hsiFieldTimeout                 = "timeout"
 	hsiFieldRateLimit               = "rate_limit"
@@ -56,6 +59,18 @@ const (
 	hsiFieldResponseStatus          = "status"
 	hsiFieldResponseHeaders         = "headers"
 	hsiFieldResponseExtractMetadata = "metadata_headers"
+	hsiFieldResponseCookies         = "cookies"
+	hsiFieldResponseCookieName      = "name"
+	hsiFieldResponseCookieValue     = "value"
+	hsiFieldResponseCookiePath      = "path"
+	hsiFieldResponseCookieDomain    = "domain"
+	hsiFieldResponseCookieMaxAge    = "max_age"
+	hsiFieldResponseCookieSecure    = "secure"
+	hsiFieldResponseCookieHTTPOnly  = "http_only"
+	hsiFieldResponseCookieSameSite  = "same_site"
+	hsiFieldResponseTrailers        = "trailers"
+	hsiFieldResponseStream          = "stream"
+	hsiFieldStreamChunkSize         = "stream_chunk_size"
 )
 
 type hsiConfig struct {
@@ -64,6 +79,8 @@ type hsiConfig struct {
 	WSPath             string
 	WSWelcomeMessage   string
 	WSRateLimitMessage string
+	SSEPath            string
+	SSEHeartbeat       time.Duration
 	AllowedVerbs       map[string]struct{}
 	Timeout            time.Duration
 	RateLimit          string
@@ -71,12 +88,27 @@ type hsiConfig struct {
 	KeyFile            string
 	CORS               httpserver.CORSConfig
 	Response           hsiResponseConfig
+	StreamChunkSize    int64
 }
 
 type hsiResponseConfig struct {
 	Status          *service.InterpolatedString
 	Headers         map[string]*service.InterpolatedString
 	ExtractMetadata *service.MetadataFilter
+	Cookies         []hsiResponseCookieConfig
+	Trailers        map[string]*service.InterpolatedString
+	Stream          bool
+}
+
+type hsiResponseCookieConfig struct {
+	Name     string
+	Value    *service.InterpolatedString
+	Path     string
+	Domain   string
+	MaxAge   int
+	Secure   bool
+	HTTPOnly bool
+	SameSite http.SameSite
 }
 
 func hsiConfigFromParsed(pConf *service.ParsedConfig) (conf hsiConfig, err error) {
@@ -95,6 +127,12 @@ func hsiConfigFromParsed(pConf *service.ParsedConfig) (conf hsiConfig, err error
 	if conf.WSRateLimitMessage, err = pConf.FieldString(hsiFieldWSRateLimitMessage); err != nil {
 		return
 	}
+	if conf.SSEPath, err = pConf.FieldString(hsiFieldSSEPath); err != nil {
+		return
+	}
+	if conf.SSEHeartbeat, err = pConf.FieldDuration(hsiFieldSSEHeartbeatInterval); err != nil {
+		return
+	}
 	{
 		var verbsList []string
 		if verbsList, err = pConf.FieldStringList(hsiFieldAllowedVerbs); err != nil {
@@ -127,6 +165,15 @@ func hsiConfigFromParsed(pConf *service.ParsedConfig) (conf hsiConfig, err error
 	if conf.Response, err = hsiResponseConfigFromParsed(pConf.Namespace(hsiFieldResponse)); err != nil {
 		return
 	}
+	if pConf.Contains(hsiFieldStreamChunkSize) {
+		if conf.StreamChunkSize, err = pConf.FieldByteSize(hsiFieldStreamChunkSize); err != nil {
+			return
+		}
+		if conf.StreamChunkSize <= 0 {
+			err = errors.New("stream_chunk_size must be greater than zero")
+			return
+		}
+	}
 	return
 }
 
@@ -150,6 +197,63 @@ func hsiResponseConfigFromParsed(pConf *service.ParsedConfig) (conf hsiResponseC
 	if conf.ExtractMetadata, err = pConf.FieldMetadataFilter(hsiFieldResponseExtractMetadata); err != nil {
 		return
 	}
+	var cookieConfs []*service.ParsedConfig
+	if cookieConfs, err = pConf.FieldObjectList(hsiFieldResponseCookies); err != nil {
+		return
+	}
+	for _, cConf := range cookieConfs {
+		var cookie hsiResponseCookieConfig
+		if cookie, err = hsiResponseCookieConfigFromParsed(cConf); err != nil {
+			return
+		}
+		conf.Cookies = append(conf.Cookies, cookie)
+	}
+	if conf.Trailers, err = pConf.FieldInterpolatedStringMap(hsiFieldResponseTrailers); err != nil {
+		return
+	}
+	if conf.Stream, err = pConf.FieldBool(hsiFieldResponseStream); err != nil {
+		return
+	}
+	return
+}
+
+func hsiResponseCookieConfigFromParsed(pConf *service.ParsedConfig) (conf hsiResponseCookieConfig, err error) {
+	if conf.Name, err = pConf.FieldString(hsiFieldResponseCookieName); err != nil {
+		return
+	}
+	if conf.Value, err = pConf.FieldInterpolatedString(hsiFieldResponseCookieValue); err != nil {
+		return
+	}
+	if conf.Path, err = pConf.FieldString(hsiFieldResponseCookiePath); err != nil {
+		return
+	}
+	if conf.Domain, err = pConf.FieldString(hsiFieldResponseCookieDomain); err != nil {
+		return
+	}
+	if conf.MaxAge, err = pConf.FieldInt(hsiFieldResponseCookieMaxAge); err != nil {
+		return
+	}
+	if conf.Secure, err = pConf.FieldBool(hsiFieldResponseCookieSecure); err != nil {
+		return
+	}
+	if conf.HTTPOnly, err = pConf.FieldBool(hsiFieldResponseCookieHTTPOnly); err != nil {
+		return
+	}
+	var sameSite string
+	if sameSite, err = pConf.FieldString(hsiFieldResponseCookieSameSite); err != nil {
+		return
+	}
+	switch sameSite {
+	case "":
+	case "lax":
+		conf.SameSite = http.SameSiteLaxMode
+	case "strict":
+		conf.SameSite = http.SameSiteStrictMode
+	case "none":
+		conf.SameSite = http.SameSiteNoneMode
+	default:
+		err = fmt.Errorf("unrecognised same_site value: %v", sameSite)
+	}
 	return
 }
 
@@ -172,6 +276,8 @@ When the rate limit is breached HTTP requests will have a 429 response returned
 
 It's possible to return a response for each message received using xref:guides:sync_responses.adoc[synchronous responses]. When doing so you can customize headers with the `+"`sync_response` field `headers`"+`, which can also use xref:configuration:interpolation.adoc#bloblang-queries[function interpolation] in the value based on the response message contents.
 
+The status code, cookies and trailers of responses can be set in the same way with the fields `+"`status`, `cookies` and `trailers`"+`, and metadata of the response messages can be returned as headers with `+"`metadata_headers`"+`, which allows pipelines to implement the full semantics of a REST API. Responses of multiple messages are encoded as a multipart body by default, and with `+"`stream`"+` set to `+"`true`"+` the content of each message is instead written and flushed in turn as a chunked body.
+
 == Endpoints
 
 The following fields specify endpoints that are registered for sending messages, and support path parameters of the form `+"`/\\{foo}`"+`, which are added to ingested messages as metadata. A path ending in `+"`/`"+` will match against all extensions of that path:
@@ -180,7 +286,13 @@ The following fields specify endpoints that are registered for sending messages,
 
 This endpoint expects POST requests where the entire request body is consumed as a single message.
 
-If the request contains a multipart `+"`content-type`"+` header as per https://www.w3.org/Protocols/rfc1341/7_2_Multipart.html[rfc1341] then the multiple parts are consumed as a batch of messages, where each body part is a message of the batch.
+If the request contains a multipart `+"`content-type`"+` header as per https://www.w3.org/Protocols/rfc1341/7_2_Multipart.html[rfc1341] then the multiple parts are consumed as a batch of messages, where each body part is a message of the batch. The headers of each part are added to its message as metadata, taking precedence over the headers of the request, and parts of a `+"`multipart/form-data`"+` request, such as file uploads, also have their form field name and file name added as the metadata fields `+"`http_server_form_name` and `http_server_form_filename`"+` respectively.
+
+==== Streaming large bodies
+
+By default the body of a request is read in its entirety before it is consumed, which means large uploads are held in memory. When `+"`stream_chunk_size`"+` is set the body is instead consumed in chunks of that size, where each chunk is a batch of one message, and the next chunk isn't read from the request until the previous one has been delivered. Therefore only one chunk of a request is held in memory at a time regardless of the size of the body. The body parts of multipart requests are each chunked in the same way.
+
+Chunks have the metadata fields `+"`http_server_chunk_index`, `http_server_chunk_offset` and `http_server_chunk_final`"+`, which are the index of the chunk within its body (or body part), its byte offset, and whether it is the final chunk, respectively. A response is returned once all chunks have been delivered, and if a chunk fails to be delivered then an error response is returned and the remainder of the body is not consumed. Synchronous responses are not supported when streaming.
 
 === `+"`ws_path` (defaults to `/post/ws`)"+`
 
@@ -192,6 +304,12 @@ You may specify an optional `+"`ws_welcome_message`"+`, which is a static payloa
 
 It's also possible to specify a `+"`ws_rate_limit_message`"+`, which is a static payload to be sent to clients that have triggered the servers rate limit.
 
+=== `+"`sse_path` (disabled by default)"+`
+
+Accepts POST requests with a body of https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation[Server-Sent Events^] (content type `+"`text/event-stream`"+`), allowing clients to stream events over a single long-lived connection. Each event is passed through the pipeline as a batch of one message containing the event data, and the next event is not read from the connection until the previous one has been delivered, which applies backpressure to each connection individually. Events that fail to be delivered are retried.
+
+The response is itself an event stream, where xref:guides:sync_responses.adoc[synchronous responses] are sent as events, and a comment is sent as a heartbeat every `+"`sse_heartbeat_interval`"+` in order to prevent idle connections from being closed by proxies. Connections that are rate limited are paused until the rate limit allows the next event through.
+
 == Metadata
 
 This input adds the following metadata fields to each message:
@@ -201,6 +319,8 @@ This input adds the following metadata fields to each message:
 - http_server_request_path
 - http_server_verb
 - http_server_remote_ip
+- http_server_form_name (multipart form data only)
+- http_server_form_filename (multipart form data file uploads only)
 - All headers (only first values are taken)
 - All query parameters
 - All path parameters
@@ -214,6 +334,8 @@ If HTTPS is enabled, the following fields are added as well:
 - http_server_tls_cipher_suite
 `+"```"+`
 
+Messages consumed from the `+"`sse_path`"+` endpoint also have the metadata fields `+"`sse_event`"+`, containing the event type (which defaults to `+"`message`"+`), and `+"`sse_id`"+`, containing the last event ID when one has been set.
+
 You can access these metadata fields using xref:configuration:interpolation.adoc#bloblang-queries[function interpolation].`).
 		Fields(
 			service.NewStringField(hsiFieldAddress).
@@ -233,6 +355,16 @@ You can access these metadata fields using xref:configuration:interpolation.adoc
 				Description("An optional message to delivery to websocket connections that are rate limited.").
 				Advanced().
 				Default(""),
+			service.NewStringField(hsiFieldSSEPath).
+				Description("An optional endpoint path to stream Server-Sent Events from.").
+				Version("4.29.0").
+				Example("/post/sse").
+				Default(""),
+			service.NewDurationField(hsiFieldSSEHeartbeatInterval).
+				Description("The period of time between heartbeats sent to Server-Sent Events connections. Set to `0s` in order to disable heartbeats.").
+				Version("4.29.0").
+				Advanced().
+				Default("15s"),
 			service.NewStringListField(hsiFieldAllowedVerbs).
 				Description("An array of verbs that are allowed for the `path` endpoint.").
 				Version("3.33.0").
@@ -264,9 +396,54 @@ You can access these metadata fields using xref:configuration:interpolation.adoc
 					}),
 				service.NewMetadataFilterField(hsiFieldResponseExtractMetadata).
 					Description("Specify criteria for which metadata values are added to the response as headers."),
+				service.NewObjectListField(hsiFieldResponseCookies,
+					service.NewStringField(hsiFieldResponseCookieName).
+						Description("The name of the cookie."),
+					service.NewInterpolatedStringField(hsiFieldResponseCookieValue).
+						Description("The value of the cookie.").
+						Examples(`${! @session_id }`, `${! json("token") }`),
+					service.NewStringField(hsiFieldResponseCookiePath).
+						Description("An optional path that the cookie is scoped to.").
+						Default(""),
+					service.NewStringField(hsiFieldResponseCookieDomain).
+						Description("An optional domain that the cookie is scoped to.").
+						Default(""),
+					service.NewIntField(hsiFieldResponseCookieMaxAge).
+						Description("The maximum age of the cookie in seconds, where zero results in a session cookie and a negative value deletes the cookie.").
+						Default(0),
+					service.NewBoolField(hsiFieldResponseCookieSecure).
+						Description("Whether the cookie should only be sent over HTTPS.").
+						Default(false),
+					service.NewBoolField(hsiFieldResponseCookieHTTPOnly).
+						Description("Whether the cookie should be inaccessible to scripts.").
+						Default(false),
+					service.NewStringEnumField(hsiFieldResponseCookieSameSite, "", "lax", "strict", "none").
+						Description("An optional same site policy of the cookie.").
+						Default(""),
+				).
+					Description("A list of cookies to set with synchronous responses, where values are resolved from the first message of the response.").
+					Default([]any{}).
+					Version("4.29.0"),
+				service.NewInterpolatedStringMapField(hsiFieldResponseTrailers).
+					Description("Specify trailers to return with synchronous responses, which are resolved from the last message of the response once the body has been written.").
+					Example(map[string]any{
+						"X-Message-Count": `${! batch_size() }`,
+					}).
+					Default(map[string]any{}).
+					Version("4.29.0"),
+				service.NewBoolField(hsiFieldResponseStream).
+					Description("Whether synchronous responses of multiple messages should be streamed, where the raw content of each message is written and flushed in turn, rather than being encoded as a multipart body.").
+					Default(false).
+					Version("4.29.0"),
 			).
 				Description("Customize messages returned via xref:guides:sync_responses.adoc[synchronous responses].").
 				Advanced(),
+			service.NewByteSizeField(hsiFieldStreamChunkSize).
+				Description("An optional size of chunks in which the bodies of requests to the `path` endpoint are streamed, rather than reading each body in its entirety before it is consumed. The `timeout` applies to the delivery of each chunk.").
+				Examples("1MiB", "64KB").
+				Advanced().
+				Optional().
+				Version("4.29.0"),
 		).
 		Example(
 			"Path Switching",
@@ -364,6 +541,7 @@ type httpServerInput struct {
 
 	mPostRcvd metrics.StatCounter
 	mWSRcvd   metrics.StatCounter
+	mSSERcvd  metrics.StatCounter
 	mLatency  metrics.StatTimer
 }
 
@@ -392,6 +570,7 @@ func newHTTPServerInput(conf hsiConfig, mgr bundle.NewManagement) (input.Streame
 
 		mLatency:  mgr.Metrics().GetTimer("input_latency_ns"),
 		mWSRcvd:   mRcvd,
+		mSSERcvd:  mRcvd,
 		mPostRcvd: mRcvd,
 	}
 
@@ -404,6 +583,9 @@ func newHTTPServerInput(conf hsiConfig, mgr bundle.NewManagement) (input.Streame
 		if h.conf.WSPath != "" {
 			api.GetMuxRoute(gMux, h.conf.WSPath).Handler(wsHdlr)
 		}
+		if h.conf.SSEPath != "" {
+			api.GetMuxRoute(gMux, h.conf.SSEPath).HandlerFunc(h.sseHandler)
+		}
 	} else {
 		if h.conf.Path != "" {
 			mgr.RegisterEndpoint(
@@ -415,6 +597,11 @@ func newHTTPServerInput(conf hsiConfig, mgr bundle.NewManagement) (input.Streame
 				h.conf.WSPath, "Post messages via websocket into Benthos.", wsHdlr,
 			)
 		}
+		if h.conf.SSEPath != "" {
+			mgr.RegisterEndpoint(
+				h.conf.SSEPath, "Stream messages via Server-Sent Events into Benthos.", h.sseHandler,
+			)
+		}
 	}
 
 	if h.conf.RateLimit != "" {
@@ -429,15 +616,86 @@ func newHTTPServerInput(conf hsiConfig, mgr bundle.NewManagement) (input.Streame
 
 //------------------------------------------------------------------------------
 
-func (h *httpServerInput) extractMessageFromRequest(r *http.Request) (message.Batch, error) {
-	msg := message.QuickBatch(nil)
+// setRequestMetadata adds the details of a request, including its headers,
+// query and path parameters and cookies, to the metadata of a message.
+func setRequestMetadata(r *http.Request, p *message.Part) {
+	p.MetaSetMut("http_server_user_agent", r.UserAgent())
+	p.MetaSetMut("http_server_request_path", r.URL.Path)
+	p.MetaSetMut("http_server_verb", r.Method)
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		p.MetaSetMut("http_server_remote_ip", host)
+	}
+
+	if r.TLS != nil {
+		var tlsVersion string
+		switch r.TLS.Version {
+		case tls.VersionTLS10:
+			tlsVersion = "TLSv1.0"
+		case tls.VersionTLS11:
+			tlsVersion = "TLSv1.1"
+		case tls.VersionTLS12:
+			tlsVersion = "TLSv1.2"
+		case tls.VersionTLS13:
+			tlsVersion = "TLSv1.3"
+		}
+		p.MetaSetMut("http_server_tls_version", tlsVersion)
+		if len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+			p.MetaSetMut("http_server_tls_subject", r.TLS.VerifiedChains[0][0].Subject.String())
+		}
+		p.MetaSetMut("http_server_tls_cipher_suite", tls.CipherSuiteName(r.TLS.CipherSuite))
+	}
+	for k, v := range r.Header {
+		if len(v) > 0 {
+			p.MetaSetMut(k, v[0])
+		}
+	}
+	for k, v := range r.URL.Query() {
+		if len(v) > 0 {
+			p.MetaSetMut(k, v[0])
+		}
+	}
+	for k, v := range mux.Vars(r) {
+		p.MetaSetMut(k, v)
+	}
+	for _, c := range r.Cookies() {
+		p.MetaSetMut(c.Name, c.Value)
+	}
+}
 
+// multipartPartMetadata returns the metadata of a body part of a multipart
+// request.
+func multipartPartMetadata(p *multipart.Part) map[string]string {
+	partMeta := map[string]string{}
+	for k, v := range p.Header {
+		if len(v) > 0 {
+			partMeta[k] = v[0]
+		}
+	}
+	if name := p.FormName(); name != "" {
+		partMeta["http_server_form_name"] = name
+	}
+	if filename := p.FileName(); filename != "" {
+		partMeta["http_server_form_filename"] = filename
+	}
+	return partMeta
+}
+
+func requestMediaType(r *http.Request) (string, map[string]string, error) {
 	contentType := r.Header.Get("Content-Type")
 	if contentType == "" {
 		contentType = "application/octet-stream"
 	}
+	return mime.ParseMediaType(contentType)
+}
 
-	mediaType, params, err := mime.ParseMediaType(contentType)
+func (h *httpServerInput) extractMessageFromRequest(r *http.Request) (message.Batch, error) {
+	msg := message.QuickBatch(nil)
+
+	// The headers of each part of a multipart request, which take precedence
+	// over the headers of the request itself.
+	var partsMetadata []map[string]string
+
+	mediaType, params, err := requestMediaType(r)
 	if err != nil {
 		return nil, err
 	}
@@ -457,6 +715,7 @@ func (h *httpServerInput) extractMessageFromRequest(r *http.Request) (message.Ba
 				return nil, err
 			}
 			msg = append(msg, message.NewPart(msgBytes))
+			partsMetadata = append(partsMetadata, multipartPartMetadata(p))
 		}
 	} else {
 		var msgBytes []byte
@@ -467,59 +726,165 @@ func (h *httpServerInput) extractMessageFromRequest(r *http.Request) (message.Ba
 	}
 
 	_ = msg.Iter(func(i int, p *message.Part) error {
-		p.MetaSetMut("http_server_user_agent", r.UserAgent())
-		p.MetaSetMut("http_server_request_path", r.URL.Path)
-		p.MetaSetMut("http_server_verb", r.Method)
-		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
-			p.MetaSetMut("http_server_remote_ip", host)
-		}
-
-		if r.TLS != nil {
-			var tlsVersion string
-			switch r.TLS.Version {
-			case tls.VersionTLS10:
-				tlsVersion = "TLSv1.0"
-			case tls.VersionTLS11:
-				tlsVersion = "TLSv1.1"
-			case tls.VersionTLS12:
-				tlsVersion = "TLSv1.2"
-			case tls.VersionTLS13:
-				tlsVersion = "TLSv1.3"
-			}
-			p.MetaSetMut("http_server_tls_version", tlsVersion)
-			if len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
-				p.MetaSetMut("http_server_tls_subject", r.TLS.VerifiedChains[0][0].Subject.String())
+		setRequestMetadata(r, p)
+		if i < len(partsMetadata) {
+			for k, v := range partsMetadata[i] {
+				p.MetaSetMut(k, v)
 			}
-			p.MetaSetMut("http_server_tls_cipher_suite", tls.CipherSuiteName(r.TLS.CipherSuite))
-		}
-		for k, v := range r.Header {
-			if len(v) > 0 {
-				p.MetaSetMut(k, v[0])
-			}
-		}
-		for k, v := range r.URL.Query() {
-			if len(v) > 0 {
-				p.MetaSetMut(k, v[0])
-			}
-		}
-		for k, v := range mux.Vars(r) {
-			p.MetaSetMut(k, v)
-		}
-		for _, c := range r.Cookies() {
-			p.MetaSetMut(c.Name, c.Value)
 		}
 		return nil
 	})
 
+	initRequestSpans(h.mgr, r, msg)
+	return msg, nil
+}
+
+func initRequestSpans(mgr bundle.NewManagement, r *http.Request, msg message.Batch) {
 	textMapGeneric := map[string]any{}
 	for k, vals := range r.Header {
 		for _, v := range vals {
 			textMapGeneric[k] = v
 		}
 	}
+	_ = tracing.InitSpansFromParentTextMap(mgr.Tracer(), "input_http_server_post", textMapGeneric, msg)
+}
 
-	_ = tracing.InitSpansFromParentTextMap(h.mgr.Tracer(), "input_http_server_post", textMapGeneric, msg)
-	return msg, nil
+// streamRequest consumes the body of a request in chunks, where each chunk is
+// sent as a message once the previous chunk has been delivered, and therefore
+// only a chunk of the body is held in memory at a time.
+func (h *httpServerInput) streamRequest(w http.ResponseWriter, r *http.Request) {
+	mediaType, params, err := requestMediaType(r)
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		h.log.Warn("Request read failed: %v\n", err)
+		return
+	}
+
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		_ = h.streamBody(w, r, r.Body, nil)
+		return
+	}
+
+	mr := multipart.NewReader(r.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				http.Error(w, "Bad request", http.StatusBadRequest)
+				h.log.Warn("Request read failed: %v\n", err)
+			}
+			return
+		}
+		if !h.streamBody(w, r, p, multipartPartMetadata(p)) {
+			return
+		}
+	}
+}
+
+func (h *httpServerInput) readChunk(body io.Reader) ([]byte, error) {
+	chunk := make([]byte, h.conf.StreamChunkSize)
+	n, err := io.ReadFull(body, chunk)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = nil
+	}
+	return chunk[:n], err
+}
+
+// streamBody sends the chunks of a body, returning false if a response has
+// already been written due to a failure.
+func (h *httpServerInput) streamBody(w http.ResponseWriter, r *http.Request, body io.Reader, partMeta map[string]string) bool {
+	// We peek a byte beyond each full chunk in order to determine whether it's
+	// the final one without reading the next chunk, an empty body results in a
+	// single empty chunk.
+	br := bufio.NewReaderSize(body, 16)
+
+	var index, offset int64
+	var err error
+	for {
+		var chunk []byte
+		if chunk, err = h.readChunk(br); errors.Is(err, io.EOF) {
+			err = nil
+		}
+		final := int64(len(chunk)) < h.conf.StreamChunkSize
+		if err == nil && !final {
+			if _, err = br.Peek(1); errors.Is(err, io.EOF) {
+				final, err = true, nil
+			}
+		}
+		if err != nil {
+			break
+		}
+
+		part := message.NewPart(chunk)
+		setRequestMetadata(r, part)
+		for k, v := range partMeta {
+			part.MetaSetMut(k, v)
+		}
+		part.MetaSetMut("http_server_chunk_index", index)
+		part.MetaSetMut("http_server_chunk_offset", offset)
+		part.MetaSetMut("http_server_chunk_final", final)
+
+		msg := message.Batch{part}
+		initRequestSpans(h.mgr, r, msg)
+		delivered := h.deliverChunk(w, r, msg)
+		tracing.FinishSpans(msg)
+		if !delivered {
+			return false
+		}
+		if final {
+			return true
+		}
+
+		index++
+		offset += int64(len(chunk))
+	}
+
+	http.Error(w, "Bad request", http.StatusBadRequest)
+	h.log.Warn("Request read failed: %v\n", err)
+	return false
+}
+
+func (h *httpServerInput) deliverChunk(w http.ResponseWriter, r *http.Request, msg message.Batch) bool {
+	startedAt := time.Now()
+
+	h.mPostRcvd.Incr(int64(msg.Len()))
+	h.log.Trace("Consumed %v messages from POST to '%v'.\n", msg.Len(), h.conf.Path)
+
+	resChan := make(chan error, 1)
+	select {
+	case h.transactions <- message.NewTransaction(msg, resChan):
+	case <-time.After(h.conf.Timeout):
+		http.Error(w, "Request timed out", http.StatusRequestTimeout)
+		return false
+	case <-r.Context().Done():
+		http.Error(w, "Request timed out", http.StatusRequestTimeout)
+		return false
+	case <-h.shutSig.SoftStopChan():
+		http.Error(w, "Server closing", http.StatusServiceUnavailable)
+		return false
+	}
+
+	select {
+	case res, open := <-resChan:
+		if !open {
+			http.Error(w, "Server closing", http.StatusServiceUnavailable)
+			return false
+		} else if res != nil {
+			http.Error(w, res.Error(), http.StatusBadGateway)
+			return false
+		}
+		h.mLatency.Timing(time.Since(startedAt).Nanoseconds())
+	case <-time.After(h.conf.Timeout):
+		http.Error(w, "Request timed out", http.StatusRequestTimeout)
+		return false
+	case <-r.Context().Done():
+		http.Error(w, "Request timed out", http.StatusRequestTimeout)
+		return false
+	case <-h.shutSig.HardStopChan():
+		http.Error(w, "Server closing", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
 }
 
 func (h *httpServerInput) postHandler(w http.ResponseWriter, r *http.Request) {
@@ -558,6 +923,11 @@ func (h *httpServerInput) postHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if h.conf.StreamChunkSize > 0 {
+		h.streamRequest(w, r)
+		return
+	}
+
 	msg, err := h.extractMessageFromRequest(r)
 	if err != nil {
 		http.Error(w, "Bad request", http.StatusBadRequest)
@@ -625,6 +995,26 @@ func (h *httpServerInput) postHandler(w http.ResponseWriter, r *http.Request) {
 			}
 			w.Header().Set(k, headerStr)
 		}
+		for _, c := range h.conf.Response.Cookies {
+			value, err := svcBatch.TryInterpolatedString(0, c.Value)
+			if err != nil {
+				h.log.Error("Interpolation of response cookie %v error: %v", c.Name, err)
+				continue
+			}
+			http.SetCookie(w, &http.Cookie{
+				Name:     c.Name,
+				Value:    value,
+				Path:     c.Path,
+				Domain:   c.Domain,
+				MaxAge:   c.MaxAge,
+				Secure:   c.Secure,
+				HttpOnly: c.HTTPOnly,
+				SameSite: c.SameSite,
+			})
+		}
+		for k := range h.conf.Response.Trailers {
+			w.Header().Add("Trailer", k)
+		}
 
 		statusCode := 200
 		statusCodeStr, err := svcBatch.TryInterpolatedString(0, h.conf.Response.Status)
@@ -641,7 +1031,9 @@ func (h *httpServerInput) postHandler(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
-		if plen := len(svcBatch); plen == 1 {
+		if plen := len(svcBatch); plen > 1 && h.conf.Response.Stream {
+			h.streamResponse(w, svcBatch, statusCode)
+		} else if plen == 1 {
 			part := svcBatch[0]
 			_ = h.conf.Response.ExtractMetadata.Walk(part, func(k, v string) error {
 				w.Header().Set(k, v)
@@ -705,8 +1097,54 @@ func (h *httpServerInput) postHandler(w http.ResponseWriter, r *http.Request) {
 			} else {
 				h.log.Error("Failed to return sync response: %v\n", merr)
 				w.WriteHeader(http.StatusBadGateway)
+				return
 			}
 		}
+
+		for k, v := range h.conf.Response.Trailers {
+			trailerStr, err := svcBatch.TryInterpolatedString(len(svcBatch)-1, v)
+			if err != nil {
+				h.log.Error("Interpolation of response trailer %v error: %v", k, err)
+				continue
+			}
+			w.Header().Set(k, trailerStr)
+		}
+	}
+}
+
+// streamResponse writes the raw content of each message of a synchronous
+// response as it is, flushing after each message.
+func (h *httpServerInput) streamResponse(w http.ResponseWriter, svcBatch service.MessageBatch, statusCode int) {
+	_ = h.conf.Response.ExtractMetadata.Walk(svcBatch[0], func(k, v string) error {
+		w.Header().Set(k, v)
+		return nil
+	})
+
+	flusher, _ := w.(http.Flusher)
+	wroteHeader := false
+	for _, part := range svcBatch {
+		payload, err := part.AsBytes()
+		if err != nil {
+			h.log.Error("Failed to extract message bytes for sync response: %v\n", err)
+			continue
+		}
+		if !wroteHeader {
+			if w.Header().Get("Content-Type") == "" {
+				w.Header().Set("Content-Type", http.DetectContentType(payload))
+			}
+			w.WriteHeader(statusCode)
+			wroteHeader = true
+		}
+		if _, err := w.Write(payload); err != nil {
+			h.log.Debug("Failed to stream sync response: %v\n", err)
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	if !wroteHeader {
+		w.WriteHeader(statusCode)
 	}
 }
 
@@ -865,6 +1303,11 @@ func (h *httpServerInput) loop() {
 						http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
 					})
 				}
+				if h.conf.SSEPath != "" {
+					h.mgr.RegisterEndpoint(h.conf.SSEPath, "Endpoint disabled.", func(w http.ResponseWriter, r *http.Request) {
+						http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+					})
+				}
 			}()
 		}
 
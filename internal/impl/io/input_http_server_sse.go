@@ -0,0 +1,351 @@
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/redpanda-data/benthos/v4/internal/component/ratelimit"
+	"github.com/redpanda-data/benthos/v4/internal/message"
+	"github.com/redpanda-data/benthos/v4/internal/old/util/throttle"
+	"github.com/redpanda-data/benthos/v4/internal/tracing"
+	"github.com/redpanda-data/benthos/v4/internal/transaction"
+)
+
+// The maximum size of a single line of an event stream.
+const sseMaxLineBytes = 16 * 1024 * 1024
+
+type sseEvent struct {
+	Type string
+	ID   string
+	Data []byte
+}
+
+// sseDecoder reads events from a stream following the interpretation rules of
+// https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation
+type sseDecoder struct {
+	scanner *bufio.Scanner
+	lastID  string
+}
+
+func newSSEDecoder(r io.Reader) *sseDecoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, sseMaxLineBytes)
+	scanner.Split(scanSSELines)
+	return &sseDecoder{scanner: scanner}
+}
+
+// scanSSELines splits lines terminated by either a CRLF, a LF or a CR.
+func scanSSELines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		// A trailing CR could be followed by a LF that has yet to be read.
+		return 0, nil, nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// Next returns the next event of the stream, or io.EOF once the stream has
+// ended. Events that are incomplete at the end of the stream are discarded.
+func (d *sseDecoder) Next() (*sseEvent, error) {
+	var eventType string
+	var data []byte
+	var hasData bool
+
+	for d.scanner.Scan() {
+		line := d.scanner.Bytes()
+		if len(line) == 0 {
+			if !hasData {
+				eventType = ""
+				continue
+			}
+			if eventType == "" {
+				eventType = "message"
+			}
+			return &sseEvent{
+				Type: eventType,
+				ID:   d.lastID,
+				Data: data,
+			}, nil
+		}
+		if line[0] == ':' {
+			continue
+		}
+
+		field, value := line, []byte(nil)
+		if i := bytes.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], line[i+1:]
+			value = bytes.TrimPrefix(value, []byte(" "))
+		}
+
+		switch string(field) {
+		case "event":
+			eventType = string(value)
+		case "data":
+			if hasData {
+				data = append(data, '\n')
+			}
+			data = append(data, value...)
+			hasData = true
+		case "id":
+			if bytes.IndexByte(value, 0) < 0 {
+				d.lastID = string(value)
+			}
+		}
+	}
+	if err := d.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// sseResponseWriter writes events and comments to a response, flushing after
+// each write, and is safe to use from multiple goroutines.
+type sseResponseWriter struct {
+	mut sync.Mutex
+	w   http.ResponseWriter
+	rc  *http.ResponseController
+}
+
+func (s *sseResponseWriter) write(b []byte) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if _, err := s.w.Write(b); err != nil {
+		return err
+	}
+	if err := s.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+func (s *sseResponseWriter) writeEvent(data []byte) error {
+	var buf bytes.Buffer
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		_, _ = buf.WriteString("data: ")
+		_, _ = buf.Write(line)
+		_ = buf.WriteByte('\n')
+	}
+	_ = buf.WriteByte('\n')
+	return s.write(buf.Bytes())
+}
+
+func (s *sseResponseWriter) writeComment(comment string) error {
+	return s.write([]byte(": " + comment + "\n\n"))
+}
+
+// sseAwaitRateLimit blocks until the rate limit, if configured, permits the
+// next event to be consumed, returning false if the connection should be
+// closed.
+func (h *httpServerInput) sseAwaitRateLimit(ctx context.Context, throt *throttle.Type) bool {
+	if h.conf.RateLimit == "" {
+		return true
+	}
+	for {
+		var tUntil time.Duration
+		var err error
+		if rerr := h.mgr.AccessRateLimit(ctx, h.conf.RateLimit, func(rl ratelimit.V1) {
+			tUntil, err = rl.Access(ctx)
+		}); rerr != nil {
+			err = rerr
+		}
+		if err != nil {
+			h.log.Warn("Failed to access rate limit: %v\n", err)
+			if !throt.RetryWithContext(ctx) {
+				return false
+			}
+			continue
+		}
+		throt.Reset()
+		if tUntil <= 0 {
+			return true
+		}
+		select {
+		case <-time.After(tUntil):
+		case <-ctx.Done():
+			return false
+		case <-h.shutSig.SoftStopChan():
+			return false
+		}
+	}
+}
+
+func (h *httpServerInput) sseHandler(w http.ResponseWriter, r *http.Request) {
+	if h.shutSig.IsSoftStopSignalled() {
+		http.Error(w, "Server closing", http.StatusServiceUnavailable)
+		return
+	}
+
+	h.handlerWG.Add(1)
+	defer h.handlerWG.Done()
+	defer r.Body.Close()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Incorrect method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Events are streamed in both directions and therefore the response must
+	// be written whilst the request body is still being read. HTTP/2
+	// connections do not support this option as they are always full duplex.
+	rc := http.NewResponseController(w)
+	if err := rc.EnableFullDuplex(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.log.Warn("Failed to enable full duplex for Server-Sent Events connection: %v\n", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	sw := &sseResponseWriter{w: w, rc: rc}
+	if err := sw.writeComment("connected"); err != nil {
+		h.log.Debug("Failed to write to Server-Sent Events connection: %v\n", err)
+		return
+	}
+
+	// The response must not be written to once the handler has returned, and
+	// therefore the heartbeat goroutine is waited on before returning.
+	ctx, done := context.WithCancel(r.Context())
+	heartbeatExited := make(chan struct{})
+	defer func() {
+		done()
+		<-heartbeatExited
+	}()
+
+	go func() {
+		defer close(heartbeatExited)
+
+		var heartbeatChan <-chan time.Time
+		if h.conf.SSEHeartbeat > 0 {
+			ticker := time.NewTicker(h.conf.SSEHeartbeat)
+			defer ticker.Stop()
+			heartbeatChan = ticker.C
+		}
+		for {
+			select {
+			case <-heartbeatChan:
+				if err := sw.writeComment("heartbeat"); err != nil {
+					h.log.Debug("Failed to write heartbeat to Server-Sent Events connection: %v\n", err)
+					done()
+					return
+				}
+			case <-h.shutSig.SoftStopChan():
+				// Interrupt any pending read of the request body so that the
+				// handler exits rather than blocking shutdown.
+				_ = rc.SetReadDeadline(time.Now())
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	dec := newSSEDecoder(r.Body)
+	throt := throttle.New(throttle.OptCloseChan(h.shutSig.SoftStopChan()))
+	resChan := make(chan error, 1)
+
+	for !h.shutSig.IsSoftStopSignalled() {
+		event, err := dec.Next()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !h.shutSig.IsSoftStopSignalled() {
+				h.log.Warn("Server-Sent Events request read failed: %v\n", err)
+			}
+			return
+		}
+		h.mSSERcvd.Incr(1)
+
+		if !h.sseAwaitRateLimit(ctx, throt) {
+			return
+		}
+
+		if !h.sseDeliverEvent(ctx, r, sw, event, resChan, throt) {
+			return
+		}
+	}
+}
+
+// sseDeliverEvent sends an event through the pipeline, retrying until it is
+// delivered, and writes any synchronous responses to the connection. Returns
+// false if the connection should be closed.
+func (h *httpServerInput) sseDeliverEvent(
+	ctx context.Context,
+	r *http.Request,
+	sw *sseResponseWriter,
+	event *sseEvent,
+	resChan chan error,
+	throt *throttle.Type,
+) bool {
+	msg := message.QuickBatch([][]byte{event.Data})
+	part := msg.Get(0)
+	setRequestMetadata(r, part)
+	part.MetaSetMut("sse_event", event.Type)
+	if event.ID != "" {
+		part.MetaSetMut("sse_id", event.ID)
+	}
+	tracing.InitSpans(h.mgr.Tracer(), "input_http_server_sse", msg)
+	defer tracing.FinishSpans(msg)
+
+	startedAt := time.Now()
+	for {
+		store := transaction.NewResultStore()
+		transaction.AddResultStore(msg, store)
+
+		select {
+		case h.transactions <- message.NewTransaction(msg, resChan):
+		case <-ctx.Done():
+			return false
+		case <-h.shutSig.SoftStopChan():
+			return false
+		}
+
+		select {
+		case res, open := <-resChan:
+			if !open {
+				return false
+			}
+			if res != nil {
+				if !throt.RetryWithContext(ctx) {
+					return false
+				}
+				continue
+			}
+		case <-h.shutSig.HardStopChan():
+			return false
+		}
+
+		h.mLatency.Timing(time.Since(startedAt).Nanoseconds())
+		throt.Reset()
+
+		for _, responseMsg := range store.Get() {
+			if err := responseMsg.Iter(func(i int, part *message.Part) error {
+				return sw.writeEvent(part.AsBytes())
+			}); err != nil {
+				h.log.Error("Failed to send sync response over Server-Sent Events connection: %v\n", err)
+			}
+		}
+		return true
+	}
+}
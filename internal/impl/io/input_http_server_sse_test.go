@@ -0,0 +1,72 @@
+package io
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSEDecoder(t *testing.T) {
+	input := ": a comment\n" +
+		"data: first\n\n" +
+		"event: update\r\n" +
+		"id: 1\r\n" +
+		"data: second\r\n" +
+		"data:  indented\r\n\r\n" +
+		"event: ignored\n\n" +
+		"data\rretry: 100\rdata:third\r\r" +
+		"id\n" +
+		"data: fourth\n\n" +
+		"data: incomplete"
+
+	dec := newSSEDecoder(strings.NewReader(input))
+
+	var events []sseEvent
+	for {
+		event, err := dec.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		events = append(events, *event)
+	}
+
+	assert.Equal(t, []sseEvent{
+		{Type: "message", Data: []byte("first")},
+		{Type: "update", ID: "1", Data: []byte("second\n indented")},
+		{Type: "message", ID: "1", Data: []byte("\nthird")},
+		{Type: "message", Data: []byte("fourth")},
+	}, events)
+}
+
+func TestSSEDecoderLineSplitting(t *testing.T) {
+	// Reads of a single byte ensure that a CR at the end of a read is not
+	// treated as the end of a line before the following LF is read.
+	dec := newSSEDecoder(&oneByteReader{r: strings.NewReader("data: a\r\ndata: b\r\n\r\ndata: c\r\r")})
+
+	event, err := dec.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb", string(event.Data))
+
+	event, err = dec.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "c", string(event.Data))
+
+	_, err = dec.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+type oneByteReader struct {
+	r io.Reader
+}
+
+func (o *oneByteReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return o.r.Read(p[:1])
+}
@@ -1,6 +1,7 @@
 package io_test
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"errors"
@@ -13,6 +14,7 @@ import (
 	"net/http/httptest"
 	"net/textproto"
 	"net/url"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -358,6 +360,352 @@ http_server:
 	assert.Contains(t, "bar", part.MetaGetStr("foo"))
 }
 
+func TestHTTPServerMultipartFormData(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	t.Parallel()
+
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+	mgr, err := manager.New(manager.ResourceConfig{}, manager.OptSetAPIReg(reg))
+	require.NoError(t, err)
+
+	conf := parseYAMLInputConf(t, `
+http_server:
+  path: /upload
+`)
+
+	server, err := mgr.NewInput(conf)
+	require.NoError(t, err)
+
+	defer func() {
+		server.TriggerStopConsuming()
+		assert.NoError(t, server.WaitForClose(tCtx))
+	}()
+
+	testServer := httptest.NewServer(reg.mut)
+	defer testServer.Close()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("title", "holiday photos"))
+
+	fileHeader := textproto.MIMEHeader{}
+	fileHeader.Set("Content-Disposition", `form-data; name="photo"; filename="beach.jpg"`)
+	fileHeader.Set("Content-Type", "image/jpeg")
+	fileHeader.Set("X-Camera", "polaroid")
+	fileWriter, err := writer.CreatePart(fileHeader)
+	require.NoError(t, err)
+	_, err = fileWriter.Write([]byte("not really a jpeg"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	go func() {
+		req, cerr := http.NewRequest(http.MethodPost, testServer.URL+"/upload", body)
+		if !assert.NoError(t, cerr) {
+			return
+		}
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("X-Uploader", "jeff")
+		resp, cerr := http.DefaultClient.Do(req)
+		if assert.NoError(t, cerr) {
+			resp.Body.Close()
+		}
+	}()
+
+	var tran message.Transaction
+	select {
+	case tran = <-server.TransactionChan():
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+	require.NoError(t, tran.Ack(tCtx, nil))
+	require.Equal(t, 2, tran.Payload.Len())
+
+	field := tran.Payload.Get(0)
+	assert.Equal(t, "holiday photos", string(field.AsBytes()))
+	assert.Equal(t, "title", field.MetaGetStr("http_server_form_name"))
+	assert.Equal(t, "", field.MetaGetStr("http_server_form_filename"))
+	assert.Equal(t, `form-data; name="title"`, field.MetaGetStr("Content-Disposition"))
+	assert.Equal(t, "jeff", field.MetaGetStr("X-Uploader"))
+
+	file := tran.Payload.Get(1)
+	assert.Equal(t, "not really a jpeg", string(file.AsBytes()))
+	assert.Equal(t, "photo", file.MetaGetStr("http_server_form_name"))
+	assert.Equal(t, "beach.jpg", file.MetaGetStr("http_server_form_filename"))
+	assert.Equal(t, "image/jpeg", file.MetaGetStr("Content-Type"))
+	assert.Equal(t, "polaroid", file.MetaGetStr("X-Camera"))
+	assert.Equal(t, "jeff", file.MetaGetStr("X-Uploader"))
+	assert.Equal(t, "/upload", file.MetaGetStr("http_server_request_path"))
+}
+
+func TestHTTPServerStreamChunks(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	t.Parallel()
+
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+	mgr, err := manager.New(manager.ResourceConfig{}, manager.OptSetAPIReg(reg))
+	require.NoError(t, err)
+
+	conf := parseYAMLInputConf(t, `
+http_server:
+  path: /upload
+  stream_chunk_size: 4
+`)
+
+	server, err := mgr.NewInput(conf)
+	require.NoError(t, err)
+
+	defer func() {
+		server.TriggerStopConsuming()
+		assert.NoError(t, server.WaitForClose(tCtx))
+	}()
+
+	testServer := httptest.NewServer(reg.mut)
+	defer testServer.Close()
+
+	resChan := make(chan int, 1)
+	go func() {
+		req, cerr := http.NewRequest(http.MethodPost, testServer.URL+"/upload?foo=bar", bytes.NewBufferString("abcdefghij"))
+		if !assert.NoError(t, cerr) {
+			return
+		}
+		resp, cerr := http.DefaultClient.Do(req)
+		if assert.NoError(t, cerr) {
+			resp.Body.Close()
+			resChan <- resp.StatusCode
+		}
+	}()
+
+	for i, exp := range []string{"abcd", "efgh", "ij"} {
+		var tran message.Transaction
+		select {
+		case tran = <-server.TransactionChan():
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out")
+		}
+		require.Equal(t, 1, tran.Payload.Len())
+
+		part := tran.Payload.Get(0)
+		assert.Equal(t, exp, string(part.AsBytes()))
+		assert.Equal(t, "bar", part.MetaGetStr("foo"))
+		assert.Equal(t, strconv.Itoa(i), part.MetaGetStr("http_server_chunk_index"))
+		assert.Equal(t, strconv.Itoa(i*4), part.MetaGetStr("http_server_chunk_offset"))
+		assert.Equal(t, strconv.FormatBool(i == 2), part.MetaGetStr("http_server_chunk_final"))
+
+		// The response isn't returned until every chunk is delivered.
+		select {
+		case <-resChan:
+			t.Fatal("response returned early")
+		default:
+		}
+		require.NoError(t, tran.Ack(tCtx, nil))
+	}
+
+	select {
+	case code := <-resChan:
+		assert.Equal(t, http.StatusOK, code)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+}
+
+func TestHTTPServerStreamChunksExactSize(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	t.Parallel()
+
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+	mgr, err := manager.New(manager.ResourceConfig{}, manager.OptSetAPIReg(reg))
+	require.NoError(t, err)
+
+	conf := parseYAMLInputConf(t, `
+http_server:
+  path: /upload
+  stream_chunk_size: 4
+`)
+
+	server, err := mgr.NewInput(conf)
+	require.NoError(t, err)
+
+	defer func() {
+		server.TriggerStopConsuming()
+		assert.NoError(t, server.WaitForClose(tCtx))
+	}()
+
+	testServer := httptest.NewServer(reg.mut)
+	defer testServer.Close()
+
+	resChan := make(chan int, 1)
+	go func() {
+		req, cerr := http.NewRequest(http.MethodPost, testServer.URL+"/upload", bytes.NewBufferString("abcdefgh"))
+		if !assert.NoError(t, cerr) {
+			return
+		}
+		resp, cerr := http.DefaultClient.Do(req)
+		if assert.NoError(t, cerr) {
+			resp.Body.Close()
+			resChan <- resp.StatusCode
+		}
+	}()
+
+	for i, exp := range []string{"abcd", "efgh"} {
+		var tran message.Transaction
+		select {
+		case tran = <-server.TransactionChan():
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out")
+		}
+		require.Equal(t, 1, tran.Payload.Len())
+
+		part := tran.Payload.Get(0)
+		assert.Equal(t, exp, string(part.AsBytes()))
+		assert.Equal(t, strconv.Itoa(i), part.MetaGetStr("http_server_chunk_index"))
+		assert.Equal(t, strconv.Itoa(i*4), part.MetaGetStr("http_server_chunk_offset"))
+		assert.Equal(t, strconv.FormatBool(i == 1), part.MetaGetStr("http_server_chunk_final"))
+
+		require.NoError(t, tran.Ack(tCtx, nil))
+	}
+
+	select {
+	case code := <-resChan:
+		assert.Equal(t, http.StatusOK, code)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+}
+
+func TestHTTPServerStreamChunksMultipart(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	t.Parallel()
+
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+	mgr, err := manager.New(manager.ResourceConfig{}, manager.OptSetAPIReg(reg))
+	require.NoError(t, err)
+
+	conf := parseYAMLInputConf(t, `
+http_server:
+  path: /upload
+  stream_chunk_size: 8
+`)
+
+	server, err := mgr.NewInput(conf)
+	require.NoError(t, err)
+
+	defer func() {
+		server.TriggerStopConsuming()
+		assert.NoError(t, server.WaitForClose(tCtx))
+	}()
+
+	testServer := httptest.NewServer(reg.mut)
+	defer testServer.Close()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("title", "photos"))
+	fileWriter, err := writer.CreateFormFile("photo", "beach.jpg")
+	require.NoError(t, err)
+	_, err = fileWriter.Write([]byte("not really a jpeg"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	go func() {
+		req, cerr := http.NewRequest(http.MethodPost, testServer.URL+"/upload", body)
+		if !assert.NoError(t, cerr) {
+			return
+		}
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		resp, cerr := http.DefaultClient.Do(req)
+		if assert.NoError(t, cerr) {
+			resp.Body.Close()
+		}
+	}()
+
+	type chunk struct {
+		form, content, index, final string
+	}
+	var chunks []chunk
+	for i := 0; i < 4; i++ {
+		select {
+		case tran := <-server.TransactionChan():
+			part := tran.Payload.Get(0)
+			chunks = append(chunks, chunk{
+				form:    part.MetaGetStr("http_server_form_name"),
+				content: string(part.AsBytes()),
+				index:   part.MetaGetStr("http_server_chunk_index"),
+				final:   part.MetaGetStr("http_server_chunk_final"),
+			})
+			require.NoError(t, tran.Ack(tCtx, nil))
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out")
+		}
+	}
+
+	assert.Equal(t, []chunk{
+		{form: "title", content: "photos", index: "0", final: "true"},
+		{form: "photo", content: "not real", index: "0", final: "false"},
+		{form: "photo", content: "ly a jpe", index: "1", final: "false"},
+		{form: "photo", content: "g", index: "2", final: "true"},
+	}, chunks)
+}
+
+func TestHTTPServerStreamChunksNack(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	t.Parallel()
+
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+	mgr, err := manager.New(manager.ResourceConfig{}, manager.OptSetAPIReg(reg))
+	require.NoError(t, err)
+
+	conf := parseYAMLInputConf(t, `
+http_server:
+  path: /upload
+  stream_chunk_size: 4
+`)
+
+	server, err := mgr.NewInput(conf)
+	require.NoError(t, err)
+
+	defer func() {
+		server.TriggerStopConsuming()
+		assert.NoError(t, server.WaitForClose(tCtx))
+	}()
+
+	testServer := httptest.NewServer(reg.mut)
+	defer testServer.Close()
+
+	resChan := make(chan int, 1)
+	go func() {
+		resp, cerr := http.Post(testServer.URL+"/upload", "application/octet-stream", bytes.NewBufferString("abcdefghij"))
+		if assert.NoError(t, cerr) {
+			resp.Body.Close()
+			resChan <- resp.StatusCode
+		}
+	}()
+
+	select {
+	case tran := <-server.TransactionChan():
+		assert.Equal(t, "abcd", string(tran.Payload.Get(0).AsBytes()))
+		require.NoError(t, tran.Ack(tCtx, errors.New("nope")))
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+
+	select {
+	case code := <-resChan:
+		assert.Equal(t, http.StatusBadGateway, code)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+}
+
 func TestHTTPServerPathParameters(t *testing.T) {
 	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
 	defer done()
@@ -864,6 +1212,158 @@ http_server:
 	}
 }
 
+func TestHTTPServerSSE(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	t.Parallel()
+
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+
+	mgr, err := manager.New(manager.ResourceConfig{}, manager.OptSetAPIReg(reg))
+	require.NoError(t, err)
+
+	conf := parseYAMLInputConf(t, `
+http_server:
+  sse_path: /testsse
+  sse_heartbeat_interval: 10ms
+`)
+
+	h, err := mgr.NewInput(conf)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(reg.mut)
+	defer server.Close()
+
+	bodyReader, bodyWriter := io.Pipe()
+	req, err := http.NewRequestWithContext(tCtx, http.MethodPost, server.URL+"/testsse?foo=bar", bodyReader)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "text/event-stream")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	resLines := make(chan string)
+	go func() {
+		defer close(resLines)
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			resLines <- scanner.Text()
+		}
+	}()
+
+	readResLine := func(t *testing.T, exp string) {
+		t.Helper()
+		for {
+			select {
+			case line, open := <-resLines:
+				require.True(t, open)
+				if line == exp {
+					return
+				}
+			case <-time.After(time.Second * 5):
+				t.Fatalf("timed out waiting for response line: %v", exp)
+			}
+		}
+	}
+	readResLine(t, ": heartbeat")
+
+	_, err = bodyWriter.Write([]byte("event: greeting\nid: 1\ndata: hello\ndata: world\n\n"))
+	require.NoError(t, err)
+
+	var ts message.Transaction
+	select {
+	case ts = <-h.TransactionChan():
+	case <-time.After(time.Second * 5):
+		t.Fatal("Timed out waiting for message")
+	}
+	require.Equal(t, 1, ts.Payload.Len())
+	part := ts.Payload.Get(0)
+	assert.Equal(t, "hello\nworld", string(part.AsBytes()))
+	assert.Equal(t, "greeting", part.MetaGetStr("sse_event"))
+	assert.Equal(t, "1", part.MetaGetStr("sse_id"))
+	assert.Equal(t, "bar", part.MetaGetStr("foo"))
+	assert.Equal(t, "/testsse", part.MetaGetStr("http_server_request_path"))
+
+	// Rejected events are retried.
+	require.NoError(t, ts.Ack(tCtx, errors.New("nope")))
+	select {
+	case ts = <-h.TransactionChan():
+	case <-time.After(time.Second * 5):
+		t.Fatal("Timed out waiting for message")
+	}
+	assert.Equal(t, "hello\nworld", string(ts.Payload.Get(0).AsBytes()))
+
+	ts.Payload.Get(0).SetBytes([]byte("response 1\nresponse 2"))
+	require.NoError(t, transaction.SetAsResponse(ts.Payload))
+	require.NoError(t, ts.Ack(tCtx, nil))
+
+	readResLine(t, "data: response 1")
+	readResLine(t, "data: response 2")
+
+	_, err = bodyWriter.Write([]byte("data: second\n\n"))
+	require.NoError(t, err)
+
+	select {
+	case ts = <-h.TransactionChan():
+	case <-time.After(time.Second * 5):
+		t.Fatal("Timed out waiting for message")
+	}
+	assert.Equal(t, "second", string(ts.Payload.Get(0).AsBytes()))
+	assert.Equal(t, "message", ts.Payload.Get(0).MetaGetStr("sse_event"))
+	assert.Equal(t, "1", ts.Payload.Get(0).MetaGetStr("sse_id"))
+	require.NoError(t, ts.Ack(tCtx, nil))
+
+	require.NoError(t, bodyWriter.Close())
+	for range resLines {
+	}
+
+	h.TriggerStopConsuming()
+	require.NoError(t, h.WaitForClose(tCtx))
+}
+
+func TestHTTPServerSSEShutdown(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	t.Parallel()
+
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+
+	mgr, err := manager.New(manager.ResourceConfig{}, manager.OptSetAPIReg(reg))
+	require.NoError(t, err)
+
+	conf := parseYAMLInputConf(t, `
+http_server:
+  sse_path: /testsse
+`)
+
+	h, err := mgr.NewInput(conf)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(reg.mut)
+	defer server.Close()
+
+	bodyReader, bodyWriter := io.Pipe()
+	defer bodyWriter.Close()
+
+	req, err := http.NewRequestWithContext(tCtx, http.MethodPost, server.URL+"/testsse", bodyReader)
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	// An open connection that is idle must not prevent shutdown.
+	h.TriggerStopConsuming()
+	require.NoError(t, h.WaitForClose(tCtx))
+}
+
 func TestHTTPServerWSRateLimit(t *testing.T) {
 	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
 	defer done()
@@ -1179,6 +1679,97 @@ http_server:
 	wg.Wait()
 }
 
+func TestHTTPSyncResponseStreamCookiesTrailers(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	t.Parallel()
+
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+	mgr, err := manager.New(manager.ResourceConfig{}, manager.OptSetAPIReg(reg))
+	require.NoError(t, err)
+
+	conf := parseYAMLInputConf(t, `
+http_server:
+  path: /testpost
+  sync_response:
+    status: '${! @status }'
+    headers:
+      Content-Type: application/x-ndjson
+    cookies:
+      - name: session
+        value: '${! json("session") }'
+        path: /
+        max_age: 60
+        http_only: true
+        same_site: strict
+    trailers:
+      X-Last-Id: '${! json("id") }'
+    stream: true
+`)
+
+	h, err := mgr.NewInput(conf)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(reg.mut)
+	t.Cleanup(func() {
+		server.Close()
+	})
+
+	input := []string{`{"id":"a"}`, `{"id":"b"}`, `{"id":"c"}`}
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		hdr, body, err := createMultipart(input, "application/octet-stream")
+		require.NoError(t, err)
+
+		res, err := http.Post(server.URL+"/testpost", hdr, bytes.NewReader(body))
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, 201, res.StatusCode)
+		assert.Equal(t, "application/x-ndjson", res.Header.Get("Content-Type"))
+		assert.Equal(t, []string{"chunked"}, res.TransferEncoding)
+
+		require.Len(t, res.Cookies(), 1)
+		cookie := res.Cookies()[0]
+		assert.Equal(t, "session", cookie.Name)
+		assert.Equal(t, "abc", cookie.Value)
+		assert.Equal(t, "/", cookie.Path)
+		assert.Equal(t, 60, cookie.MaxAge)
+		assert.True(t, cookie.HttpOnly)
+		assert.Equal(t, http.SameSiteStrictMode, cookie.SameSite)
+
+		resBytes, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "{\"id\":\"a\",\"session\":\"abc\"}\n{\"id\":\"b\"}\n{\"id\":\"c\"}\n", string(resBytes))
+		assert.Equal(t, "c", res.Trailer.Get("X-Last-Id"))
+	}()
+
+	var ts message.Transaction
+	select {
+	case ts = <-h.TransactionChan():
+		for i, in := range input {
+			assert.Equal(t, in, string(ts.Payload.Get(i).AsBytes()))
+			ts.Payload.Get(i).SetBytes([]byte(in + "\n"))
+		}
+		ts.Payload.Get(0).SetBytes([]byte(`{"id":"a","session":"abc"}` + "\n"))
+		ts.Payload.Get(0).MetaSetMut("status", "201")
+		require.NoError(t, transaction.SetAsResponse(ts.Payload))
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for message")
+	}
+	require.NoError(t, ts.Ack(tCtx, nil))
+
+	wg.Wait()
+
+	h.TriggerStopConsuming()
+	require.NoError(t, h.WaitForClose(tCtx))
+}
+
 func TestHTTPSyncResponseHeadersStatus(t *testing.T) {
 	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
 	defer done()
@@ -35,9 +35,60 @@ const (
 )
 
 func socketServerInputSpec() *service.ConfigSpec {
+	tlsFields := append([]*service.ConfigField{
+		service.NewStringField(issFieldTLSCertFile).
+			Description("PEM encoded certificate for use with TLS.").
+			Optional(),
+		service.NewStringField(issFieldTLSKeyFile).
+			Description("PEM encoded private key for use with TLS.").
+			Optional(),
+		service.NewBoolField(issFieldTLSSelfSigned).
+			Description("Whether to generate self signed certificates.").
+			Default(false),
+	}, socketServerClientTLSFields()...)
+
 	return service.NewConfigSpec().
 		Stable().
 		Summary(`Creates a server that receives a stream of messages over a TCP, UDP or Unix socket.`).
+		Description(`
+== Metadata
+
+Messages received over a connection of a `+"`tcp`, `unix` or `tls`"+` network contain the following metadata fields:
+
+- socket_server_remote_address
+
+With the `+"`tls`"+` network the following metadata fields are also added:
+
+- socket_server_tls_version
+- socket_server_tls_client_subject (when the client presented a certificate)
+
+Messages consumed from a `+"`udp`"+` network with `+"`udp.datagram_per_message`"+` enabled, or with a `+"`udp.preset`"+`, also contain the field `+"`socket_server_remote_address`"+`, which is the address of the sender of the datagram.
+
+You can access these metadata fields using xref:configuration:interpolation.adoc#bloblang-queries[function interpolation].
+
+== Client Verification
+
+The `+"`tls.client_ca_file`"+` field enables mutual TLS, where clients must present a certificate signed by a trusted authority. The revocation status of client certificates can additionally be checked against certificate revocation lists with `+"`tls.crl_files`"+`, and with an OCSP responder with `+"`tls.ocsp`"+`.
+
+== Datagrams
+
+By default the datagrams received over a `+"`udp`"+` network are consumed as a continuous stream of bytes that is broken out into messages by the `+"`scanner`"+`. When `+"`udp.datagram_per_message`"+` is enabled each datagram is instead consumed as an individual message, which is the framing of most protocols built on UDP.
+
+The field `+"`udp.preset`"+` provides the framing of well known protocols. The `+"`syslog`"+` preset consumes a syslog message from each datagram, and the `+"`gelf`"+` preset reassembles chunked GELF messages and decompresses those compressed with gzip or zlib.`).
+		Example(
+			"Receive GELF Messages",
+			"Consume GELF messages from clients such as the Docker logging driver, parsing each into a structured message:",
+			`
+input:
+  socket_server:
+    network: udp
+    address: 0.0.0.0:12201
+    udp:
+      preset: gelf
+  processors:
+    - mapping: 'root = content().parse_json()'
+`,
+		).
 		Categories("Network").
 		Fields(
 			service.NewStringEnumField(issFieldNetwork, "unix", "tcp", "udp", "tls").
@@ -49,19 +100,13 @@ func socketServerInputSpec() *service.ConfigSpec {
 				Description("An optional xref:components:caches/about.adoc[`cache`] within which this input should write it's bound address once known. The key of the cache item containing the address will be the label of the component suffixed with `_address` (e.g. `foo_address`), or `socket_server_address` when a label has not been provided. This is useful in situations where the address is dynamically allocated by the server (`127.0.0.1:0`) and you want to store the allocated address somewhere for reference by other systems and components.").
 				Optional().
 				Version("4.25.0"),
-			service.NewObjectField(issFieldTLS,
-				service.NewStringField(issFieldTLSCertFile).
-					Description("PEM encoded certificate for use with TLS.").
-					Optional(),
-				service.NewStringField(issFieldTLSKeyFile).
-					Description("PEM encoded private key for use with TLS.").
-					Optional(),
-				service.NewBoolField(issFieldTLSSelfSigned).
-					Description("Whether to generate self signed certificates.").
-					Default(false),
-			).
+			service.NewObjectField(issFieldTLS, tlsFields...).
 				Description("TLS specific configuration, valid when the `network` is set to `tls`.").
 				Optional(),
+			service.NewObjectField(issFieldUDP, socketServerUDPFields()...).
+				Description("UDP specific configuration, valid when the `network` is set to `udp`.").
+				Version("4.29.0").
+				Advanced(),
 			service.NewAutoRetryNacksToggleField(),
 		).
 		Fields(codec.DeprecatedCodecFields("lines")...)
@@ -99,6 +144,8 @@ type socketServerInput struct {
 	tlsCert       string
 	tlsKey        string
 	tlsSelfSigned bool
+	tlsClient     socketServerClientTLS
+	udp           socketServerUDP
 	codecCtor     codec.DeprecatedFallbackCodec
 
 	messages chan service.MessageBatch
@@ -125,6 +172,13 @@ func newSocketServerInputFromParsed(conf *service.ParsedConfig, mgr *service.Res
 	t.tlsCert, _ = tlsConf.FieldString(issFieldTLSCertFile)
 	t.tlsKey, _ = tlsConf.FieldString(issFieldTLSKeyFile)
 	t.tlsSelfSigned, _ = tlsConf.FieldBool(issFieldTLSSelfSigned)
+	if t.tlsClient, err = socketServerClientTLSFromParsed(tlsConf); err != nil {
+		return
+	}
+
+	if t.udp, err = socketServerUDPFromParsed(conf.Namespace(issFieldUDP)); err != nil {
+		return
+	}
 
 	if t.codecCtor, err = codec.DeprecatedCodecFromParsed(conf); err != nil {
 		return
@@ -148,9 +202,16 @@ func (t *socketServerInput) Connect(ctx context.Context) error {
 		config := &tls.Config{
 			Certificates: []tls.Certificate{cert},
 		}
+		if err = t.tlsClient.apply(t.mgr.FS(), t.log, config); err != nil {
+			return err
+		}
 		ln, err = tls.Listen("tcp", t.address, config)
 	case "udp":
-		cn, err = net.ListenPacket(t.network, t.address)
+		if cn, err = net.ListenPacket(t.network, t.address); err == nil {
+			if err = t.udp.apply(cn); err != nil {
+				_ = cn.Close()
+			}
+		}
 	default:
 		return fmt.Errorf("socket network '%v' is not supported by this input", t.network)
 	}
@@ -158,9 +219,12 @@ func (t *socketServerInput) Connect(ctx context.Context) error {
 		return err
 	}
 
-	if ln == nil {
+	switch {
+	case ln == nil && t.udp.datagramPerMessage:
+		go t.datagramLoop(cn)
+	case ln == nil:
 		go t.udpLoop(cn)
-	} else {
+	default:
 		go t.loop(ln)
 	}
 
@@ -245,6 +309,12 @@ acceptLoop:
 				wg.Done()
 			}()
 
+			meta, err := t.connMetadata(closeCtx, c)
+			if err != nil {
+				t.log.Errorf("Failed to establish connection from %v: %v", c.RemoteAddr(), err)
+				return
+			}
+
 			codec, err := t.codecCtor.Create(c, func(ctx context.Context, err error) error {
 				return nil
 			}, service.NewScannerSourceDetails())
@@ -266,6 +336,12 @@ acceptLoop:
 				// there's no benefit to aggregating acks.
 				_ = ackFn(closeCtx, nil)
 
+				for _, p := range parts {
+					for k, v := range meta {
+						p.MetaSetMut(k, v)
+					}
+				}
+
 				select {
 				case t.messages <- parts:
 				case <-t.shutSig.SoftStopChan():
@@ -276,6 +352,31 @@ acceptLoop:
 	}
 }
 
+// connMetadata returns the metadata of messages received over a connection,
+// completing the handshake of TLS connections in order to obtain the identity
+// of the client.
+func (t *socketServerInput) connMetadata(ctx context.Context, c net.Conn) (map[string]string, error) {
+	meta := map[string]string{}
+	if addr := c.RemoteAddr(); addr != nil {
+		meta["socket_server_remote_address"] = addr.String()
+	}
+
+	tlsConn, ok := c.(*tls.Conn)
+	if !ok {
+		return meta, nil
+	}
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return nil, err
+	}
+
+	state := tlsConn.ConnectionState()
+	meta["socket_server_tls_version"] = tls.VersionName(state.Version)
+	if len(state.PeerCertificates) > 0 {
+		meta["socket_server_tls_client_subject"] = state.PeerCertificates[0].Subject.String()
+	}
+	return meta, nil
+}
+
 func (t *socketServerInput) udpLoop(conn net.PacketConn) {
 	defer func() {
 		_ = conn.Close()
@@ -320,6 +421,56 @@ func (t *socketServerInput) udpLoop(conn net.PacketConn) {
 	}
 }
 
+// datagramLoop consumes each datagram received by a UDP socket as a message,
+// decoded according to the configured preset.
+func (t *socketServerInput) datagramLoop(conn net.PacketConn) {
+	defer func() {
+		_ = conn.Close()
+		close(t.messages)
+		t.shutSig.TriggerHasStopped()
+	}()
+
+	go func() {
+		<-t.shutSig.SoftStopChan()
+		_ = conn.Close()
+	}()
+
+	decoder := t.udp.decoder()
+	buf := make([]byte, t.udp.readBufferSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				t.log.Errorf("Connection dropped due to: %v", err)
+			}
+			return
+		}
+		if n == len(buf) {
+			t.log.Warnf("Datagram from %v filled the read buffer of %v bytes and may have been truncated", addr, n)
+		}
+
+		data, err := decoder.Decode(append([]byte(nil), buf[:n]...))
+		if err != nil {
+			t.log.Errorf("Failed to decode datagram from %v: %v", addr, err)
+			continue
+		}
+		if data == nil {
+			continue
+		}
+
+		msg := service.NewMessage(data)
+		if addr != nil {
+			msg.MetaSetMut("socket_server_remote_address", addr.String())
+		}
+
+		select {
+		case t.messages <- service.MessageBatch{msg}:
+		case <-t.shutSig.SoftStopChan():
+			return
+		}
+	}
+}
+
 func (t *socketServerInput) Close(ctx context.Context) error {
 	t.shutSig.TriggerSoftStop()
 	select {
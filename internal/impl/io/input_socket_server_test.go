@@ -3,6 +3,7 @@ package io_test
 import (
 	"context"
 	"crypto/tls"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"net"
@@ -20,6 +21,8 @@ import (
 	"github.com/redpanda-data/benthos/v4/internal/component/testutil"
 	"github.com/redpanda-data/benthos/v4/internal/manager/mock"
 	"github.com/redpanda-data/benthos/v4/internal/message"
+
+	_ "github.com/redpanda-data/benthos/v4/internal/impl/pure"
 )
 
 func socketServerInputFromConf(t testing.TB, confStr string, bits ...any) (input.Streamed, string) {
@@ -940,6 +943,45 @@ socket_server:
 	conn.Close()
 }
 
+func TestTCPSocketServerLengthPrefixed(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*20)
+	defer done()
+
+	rdr, addr := socketServerInputFromConf(t, `
+socket_server:
+  network: tcp
+  address: 127.0.0.1:0
+  scanner:
+    length_prefixed:
+      prefix: uint32_be
+`)
+
+	defer func() {
+		rdr.TriggerStopConsuming()
+		assert.NoError(t, rdr.WaitForClose(ctx))
+	}()
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_ = conn.SetWriteDeadline(time.Now().Add(time.Second * 5))
+	for _, m := range []string{"foo\nbar", "\x00binary\x00"} {
+		_, err = conn.Write(append(binary.BigEndian.AppendUint32(nil, uint32(len(m))), m...))
+		require.NoError(t, err)
+	}
+
+	for _, exp := range []string{"foo\nbar", "\x00binary\x00"} {
+		select {
+		case tran := <-rdr.TransactionChan():
+			require.NoError(t, tran.Ack(ctx, nil))
+			assert.Equal(t, [][]byte{[]byte(exp)}, message.GetAllBytes(tran.Payload))
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out")
+		}
+	}
+}
+
 func TestTCPSocketServerReconnect(t *testing.T) {
 	ctx, done := context.WithTimeout(context.Background(), time.Second*20)
 	defer done()
@@ -1270,3 +1312,89 @@ socket_server:
 	wg.Wait()
 	conn.Close()
 }
+
+func TestSocketUDPServerDatagramPerMessage(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*20)
+	defer done()
+
+	rdr, addr := socketServerInputFromConf(t, `
+socket_server:
+  network: udp
+  address: 127.0.0.1:0
+  udp:
+    datagram_per_message: true
+    socket_buffer_size: 1MiB`)
+
+	defer func() {
+		rdr.TriggerStopConsuming()
+		assert.NoError(t, rdr.WaitForClose(ctx))
+	}()
+
+	conn, err := net.Dial("udp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_ = conn.SetWriteDeadline(time.Now().Add(time.Second * 5))
+	_, err = conn.Write([]byte("foo\nbar"))
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("baz\n"))
+	require.NoError(t, err)
+
+	for _, exp := range []string{"foo\nbar", "baz\n"} {
+		select {
+		case tran := <-rdr.TransactionChan():
+			require.Len(t, tran.Payload, 1)
+			assert.Equal(t, exp, string(tran.Payload.Get(0).AsBytes()))
+			assert.Equal(t, conn.LocalAddr().String(), tran.Payload.Get(0).MetaGetStr("socket_server_remote_address"))
+			require.NoError(t, tran.Ack(ctx, nil))
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+}
+
+func TestSocketUDPServerGELFPreset(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*20)
+	defer done()
+
+	rdr, addr := socketServerInputFromConf(t, `
+socket_server:
+  network: udp
+  address: 127.0.0.1:0
+  udp:
+    preset: gelf`)
+
+	defer func() {
+		rdr.TriggerStopConsuming()
+		assert.NoError(t, rdr.WaitForClose(ctx))
+	}()
+
+	conn, err := net.Dial("udp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	chunk := func(seq, count byte, data string) []byte {
+		b := append([]byte{0x1e, 0x0f}, "msgid001"...)
+		b = append(b, seq, count)
+		return append(b, data...)
+	}
+
+	_ = conn.SetWriteDeadline(time.Now().Add(time.Second * 5))
+	_, err = conn.Write(chunk(1, 2, `"hello"}`))
+	require.NoError(t, err)
+	_, err = conn.Write(chunk(0, 2, `{"short_message":`))
+	require.NoError(t, err)
+	_, err = conn.Write([]byte(`{"short_message":"world"}`))
+	require.NoError(t, err)
+
+	for _, exp := range []string{`{"short_message":"hello"}`, `{"short_message":"world"}`} {
+		select {
+		case tran := <-rdr.TransactionChan():
+			require.Len(t, tran.Payload, 1)
+			assert.Equal(t, exp, string(tran.Payload.Get(0).AsBytes()))
+			require.NoError(t, tran.Ack(ctx, nil))
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+}
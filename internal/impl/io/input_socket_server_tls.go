@@ -0,0 +1,349 @@
+package io
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+const (
+	issFieldTLSClientCAFile       = "client_ca_file"
+	issFieldTLSClientAuth         = "client_auth"
+	issFieldTLSCRLFiles           = "crl_files"
+	issFieldTLSOCSP               = "ocsp"
+	issFieldTLSOCSPEnabled        = "enabled"
+	issFieldTLSOCSPResponderURL   = "responder_url"
+	issFieldTLSOCSPTimeout        = "timeout"
+	issFieldTLSOCSPFailOpen       = "fail_open"
+	issClientAuthNone             = "none"
+	issClientAuthRequest          = "request"
+	issClientAuthRequire          = "require"
+	issClientAuthVerifyIfGiven    = "verify_if_given"
+	issClientAuthRequireAndVerify = "require_and_verify"
+)
+
+func socketServerClientTLSFields() []*service.ConfigField {
+	return []*service.ConfigField{
+		service.NewStringField(issFieldTLSClientCAFile).
+			Description("An optional PEM encoded file of certificate authorities used to verify the certificates of clients. When set, clients are required to present a certificate signed by one of these authorities unless `client_auth` specifies otherwise.").
+			Version("4.29.0").
+			Optional(),
+		service.NewStringEnumField(issFieldTLSClientAuth,
+			issClientAuthNone, issClientAuthRequest, issClientAuthRequire, issClientAuthVerifyIfGiven, issClientAuthRequireAndVerify).
+			Description("The policy for client certificates. Defaults to `require_and_verify` when a `client_ca_file` is set, and `none` otherwise. The policies `verify_if_given` and `require_and_verify` require a `client_ca_file`.").
+			Version("4.29.0").
+			Advanced().
+			Optional(),
+		service.NewStringListField(issFieldTLSCRLFiles).
+			Description("A list of PEM or DER encoded certificate revocation lists, each of which must be signed by one of the certificate authorities of the `client_ca_file`. Clients presenting a certificate that has been revoked are rejected.").
+			Version("4.29.0").
+			Advanced().
+			Default([]any{}),
+		service.NewObjectField(issFieldTLSOCSP,
+			service.NewBoolField(issFieldTLSOCSPEnabled).
+				Description("Whether to check the revocation status of client certificates with an OCSP responder.").
+				Default(false),
+			service.NewStringField(issFieldTLSOCSPResponderURL).
+				Description("An optional URL of the OCSP responder to query, otherwise the responder of each client certificate is used.").
+				Optional(),
+			service.NewDurationField(issFieldTLSOCSPTimeout).
+				Description("The maximum period of time to wait for a response from the OCSP responder.").
+				Default("5s"),
+			service.NewBoolField(issFieldTLSOCSPFailOpen).
+				Description("Whether to accept clients when the OCSP responder cannot be reached or does not know the status of a certificate. Revoked certificates are always rejected.").
+				Default(false),
+		).
+			Description("Checks the revocation status of client certificates with the Online Certificate Status Protocol (OCSP), which requires a `client_ca_file`. Responses are cached until their next update.").
+			Version("4.29.0").
+			Advanced(),
+	}
+}
+
+// socketServerClientTLS describes the verification of clients of a TLS socket
+// server.
+type socketServerClientTLS struct {
+	caFile   string
+	auth     string
+	crlFiles []string
+
+	ocspEnabled  bool
+	ocspURL      string
+	ocspTimeout  time.Duration
+	ocspFailOpen bool
+}
+
+func socketServerClientTLSFromParsed(conf *service.ParsedConfig) (c socketServerClientTLS, err error) {
+	c.caFile, _ = conf.FieldString(issFieldTLSClientCAFile)
+	if conf.Contains(issFieldTLSClientAuth) {
+		if c.auth, err = conf.FieldString(issFieldTLSClientAuth); err != nil {
+			return
+		}
+	} else if c.caFile != "" {
+		c.auth = issClientAuthRequireAndVerify
+	} else {
+		c.auth = issClientAuthNone
+	}
+	if conf.Contains(issFieldTLSCRLFiles) {
+		if c.crlFiles, err = conf.FieldStringList(issFieldTLSCRLFiles); err != nil {
+			return
+		}
+	}
+	if conf.Contains(issFieldTLSOCSP) {
+		ocspConf := conf.Namespace(issFieldTLSOCSP)
+		if c.ocspEnabled, err = ocspConf.FieldBool(issFieldTLSOCSPEnabled); err != nil {
+			return
+		}
+		c.ocspURL, _ = ocspConf.FieldString(issFieldTLSOCSPResponderURL)
+		if c.ocspTimeout, err = ocspConf.FieldDuration(issFieldTLSOCSPTimeout); err != nil {
+			return
+		}
+		if c.ocspFailOpen, err = ocspConf.FieldBool(issFieldTLSOCSPFailOpen); err != nil {
+			return
+		}
+	}
+
+	if c.caFile == "" {
+		switch {
+		case c.auth == issClientAuthVerifyIfGiven || c.auth == issClientAuthRequireAndVerify:
+			err = fmt.Errorf("client auth '%v' requires a client_ca_file", c.auth)
+		case len(c.crlFiles) > 0:
+			err = errors.New("crl_files requires a client_ca_file")
+		case c.ocspEnabled:
+			err = errors.New("ocsp requires a client_ca_file")
+		}
+	}
+	return
+}
+
+// apply modifies a server TLS config in order to verify clients.
+func (c socketServerClientTLS) apply(fs *service.FS, log *service.Logger, conf *tls.Config) error {
+	switch c.auth {
+	case issClientAuthRequest:
+		conf.ClientAuth = tls.RequestClientCert
+	case issClientAuthRequire:
+		conf.ClientAuth = tls.RequireAnyClientCert
+	case issClientAuthVerifyIfGiven:
+		conf.ClientAuth = tls.VerifyClientCertIfGiven
+	case issClientAuthRequireAndVerify:
+		conf.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		conf.ClientAuth = tls.NoClientCert
+	}
+	if c.caFile == "" {
+		return nil
+	}
+
+	caBytes, err := service.ReadFile(fs, c.caFile)
+	if err != nil {
+		return fmt.Errorf("failed to read client_ca_file: %w", err)
+	}
+	var cas []*x509.Certificate
+	for block, rest := pem.Decode(caBytes); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("failed to parse client_ca_file: %w", err)
+		}
+		cas = append(cas, cert)
+	}
+	if len(cas) == 0 {
+		return errors.New("client_ca_file does not contain any certificates")
+	}
+
+	conf.ClientCAs = x509.NewCertPool()
+	for _, ca := range cas {
+		conf.ClientCAs.AddCert(ca)
+	}
+
+	v := &clientCertVerifier{
+		log:         log,
+		revoked:     map[string]struct{}{},
+		ocspURL:     c.ocspURL,
+		ocspEnabled: c.ocspEnabled,
+		failOpen:    c.ocspFailOpen,
+		client:      &http.Client{Timeout: c.ocspTimeout},
+		ocspCache:   map[string]cachedOCSPStatus{},
+	}
+	for _, path := range c.crlFiles {
+		if err := v.loadCRL(fs, path, cas); err != nil {
+			return err
+		}
+	}
+	if len(c.crlFiles) > 0 || c.ocspEnabled {
+		conf.VerifyConnection = v.verifyConnection
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+type cachedOCSPStatus struct {
+	status     int
+	nextUpdate time.Time
+}
+
+// clientCertVerifier checks whether the verified certificates of clients have
+// been revoked, either by a certificate revocation list or an OCSP responder.
+type clientCertVerifier struct {
+	log *service.Logger
+
+	// Serial numbers of revoked certificates prefixed by their raw issuer.
+	revoked map[string]struct{}
+
+	ocspEnabled bool
+	ocspURL     string
+	failOpen    bool
+	client      *http.Client
+
+	ocspMut   sync.Mutex
+	ocspCache map[string]cachedOCSPStatus
+}
+
+func revokedKey(rawIssuer []byte, serial *big.Int) string {
+	return string(rawIssuer) + ":" + serial.String()
+}
+
+func (v *clientCertVerifier) loadCRL(fs *service.FS, path string, cas []*x509.Certificate) error {
+	crlBytes, err := service.ReadFile(fs, path)
+	if err != nil {
+		return fmt.Errorf("failed to read crl file '%v': %w", path, err)
+	}
+	if block, _ := pem.Decode(crlBytes); block != nil {
+		crlBytes = block.Bytes
+	}
+	crl, err := x509.ParseRevocationList(crlBytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse crl file '%v': %w", path, err)
+	}
+
+	var signed bool
+	for _, ca := range cas {
+		if crl.CheckSignatureFrom(ca) == nil {
+			signed = true
+			break
+		}
+	}
+	if !signed {
+		return fmt.Errorf("crl file '%v' is not signed by a certificate authority of the client_ca_file", path)
+	}
+	if !crl.NextUpdate.IsZero() && crl.NextUpdate.Before(time.Now()) {
+		v.log.Warnf("Certificate revocation list '%v' expired at %v", path, crl.NextUpdate)
+	}
+
+	for _, entry := range crl.RevokedCertificateEntries {
+		v.revoked[revokedKey(crl.RawIssuer, entry.SerialNumber)] = struct{}{}
+	}
+	return nil
+}
+
+func (v *clientCertVerifier) verifyConnection(cs tls.ConnectionState) error {
+	for _, chain := range cs.VerifiedChains {
+		// The last certificate of a chain is the trusted authority.
+		for _, cert := range chain[:len(chain)-1] {
+			if _, exists := v.revoked[revokedKey(cert.RawIssuer, cert.SerialNumber)]; exists {
+				return fmt.Errorf("client certificate '%v' has been revoked", cert.Subject)
+			}
+		}
+		if v.ocspEnabled && len(chain) > 1 {
+			if err := v.checkOCSP(chain[0], chain[1]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *clientCertVerifier) checkOCSP(cert, issuer *x509.Certificate) error {
+	key := revokedKey(cert.RawIssuer, cert.SerialNumber)
+
+	v.ocspMut.Lock()
+	cached, exists := v.ocspCache[key]
+	v.ocspMut.Unlock()
+
+	status := cached.status
+	if !exists || time.Now().After(cached.nextUpdate) {
+		res, err := v.queryOCSP(cert, issuer)
+		if err != nil {
+			if v.failOpen {
+				v.log.Warnf("Accepting client certificate '%v' as its OCSP status could not be checked: %v", cert.Subject, err)
+				return nil
+			}
+			return fmt.Errorf("failed to check OCSP status of client certificate '%v': %w", cert.Subject, err)
+		}
+		status = res.Status
+
+		// Responses without a next update indicate that newer information is
+		// always available, and are therefore not cached.
+		if !res.NextUpdate.IsZero() {
+			v.ocspMut.Lock()
+			v.ocspCache[key] = cachedOCSPStatus{status: res.Status, nextUpdate: res.NextUpdate}
+			v.ocspMut.Unlock()
+		}
+	}
+
+	switch status {
+	case ocsp.Good:
+		return nil
+	case ocsp.Revoked:
+		return fmt.Errorf("client certificate '%v' has been revoked", cert.Subject)
+	}
+	if v.failOpen {
+		v.log.Warnf("Accepting client certificate '%v' with an unknown OCSP status", cert.Subject)
+		return nil
+	}
+	return fmt.Errorf("client certificate '%v' has an unknown OCSP status", cert.Subject)
+}
+
+func (v *clientCertVerifier) queryOCSP(cert, issuer *x509.Certificate) (*ocsp.Response, error) {
+	url := v.ocspURL
+	if url == "" {
+		if len(cert.OCSPServer) == 0 {
+			return nil, errors.New("certificate does not specify an OCSP responder")
+		}
+		url = cert.OCSPServer[0]
+	}
+
+	reqBytes, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), v.client.Timeout)
+	defer done()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/ocsp-request")
+
+	res, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OCSP responder returned status %v", res.Status)
+	}
+	resBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	return ocsp.ParseResponseForCert(resBytes, cert, issuer)
+}
@@ -0,0 +1,335 @@
+package io_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/redpanda-data/benthos/v4/internal/component/input"
+	"github.com/redpanda-data/benthos/v4/internal/component/testutil"
+	"github.com/redpanda-data/benthos/v4/internal/manager/mock"
+)
+
+type testCA struct {
+	cert   *x509.Certificate
+	key    crypto.Signer
+	serial int64
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, serial: 1}
+}
+
+func (c *testCA) issue(t *testing.T, commonName string, usage x509.ExtKeyUsage, ocspURL string) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	c.serial++
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(c.serial),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Benthos"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if ocspURL != "" {
+		tmpl.OCSPServer = []string{ocspURL}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, c.cert, key.Public(), c.key)
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+
+	p := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o644))
+	return p
+}
+
+func writeKeyPair(t *testing.T, dir, name string, cert tls.Certificate) (certPath, keyPath string) {
+	t.Helper()
+
+	keyDER, err := x509.MarshalECPrivateKey(cert.PrivateKey.(*ecdsa.PrivateKey))
+	require.NoError(t, err)
+	return writePEM(t, dir, name+".crt", "CERTIFICATE", cert.Certificate[0]), writePEM(t, dir, name+".key", "EC PRIVATE KEY", keyDER)
+}
+
+// sendTLSLine writes a line to the server as a client with the provided
+// certificate, and returns an error if the server rejects the client.
+func sendTLSLine(t *testing.T, addr string, ca *testCA, clientCert *tls.Certificate) error {
+	t.Helper()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	conf := &tls.Config{RootCAs: pool}
+	if clientCert != nil {
+		conf.Certificates = []tls.Certificate{*clientCert}
+	}
+
+	conn, err := tls.Dial("tcp", addr, conf)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("hello world\n")); err != nil {
+		return err
+	}
+
+	// Rejections of client certificates are only observed by clients once
+	// they attempt to read from the connection, we therefore expect the read
+	// to time out when the client is accepted.
+	_ = conn.SetReadDeadline(time.Now().Add(time.Millisecond * 500))
+	if _, err := conn.Read(make([]byte, 1)); err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func readSocketServerMsg(t *testing.T, rdr input.Streamed) map[string]any {
+	t.Helper()
+
+	select {
+	case tran := <-rdr.TransactionChan():
+		require.NoError(t, tran.Ack(context.Background(), nil))
+		require.Equal(t, 1, tran.Payload.Len())
+		assert.Equal(t, "hello world", string(tran.Payload.Get(0).AsBytes()))
+
+		meta := map[string]any{}
+		_ = tran.Payload.Get(0).MetaIterMut(func(k string, v any) error {
+			meta[k] = v
+			return nil
+		})
+		return meta
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+	return nil
+}
+
+func TestTCPSocketServerMetadata(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*20)
+	defer done()
+
+	rdr, addr := socketServerInputFromConf(t, `
+socket_server:
+  network: tcp
+  address: 127.0.0.1:0
+`)
+	defer func() {
+		rdr.TriggerStopConsuming()
+		assert.NoError(t, rdr.WaitForClose(tCtx))
+	}()
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("hello world\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"socket_server_remote_address": conn.LocalAddr().String(),
+	}, readSocketServerMsg(t, rdr))
+}
+
+func TestTLSSocketServerClientCRL(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*20)
+	defer done()
+
+	tmpDir := t.TempDir()
+	ca := newTestCA(t)
+
+	serverCertPath, serverKeyPath := writeKeyPair(t, tmpDir, "server", ca.issue(t, "server", x509.ExtKeyUsageServerAuth, ""))
+	caPath := writePEM(t, tmpDir, "ca.crt", "CERTIFICATE", ca.cert.Raw)
+
+	goodClient := ca.issue(t, "good", x509.ExtKeyUsageClientAuth, "")
+	revokedClient := ca.issue(t, "revoked", x509.ExtKeyUsageClientAuth, "")
+	untrustedClient := newTestCA(t).issue(t, "untrusted", x509.ExtKeyUsageClientAuth, "")
+
+	crlDER, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now().Add(-time.Minute),
+		NextUpdate: time.Now().Add(time.Hour),
+		RevokedCertificateEntries: []x509.RevocationListEntry{
+			{SerialNumber: revokedClient.Leaf.SerialNumber, RevocationTime: time.Now().Add(-time.Minute)},
+		},
+	}, ca.cert, ca.key)
+	require.NoError(t, err)
+	crlPath := writePEM(t, tmpDir, "ca.crl", "X509 CRL", crlDER)
+
+	rdr, addr := socketServerInputFromConf(t, `
+socket_server:
+  network: tls
+  address: 127.0.0.1:0
+  tls:
+    cert_file: %v
+    key_file: %v
+    client_ca_file: %v
+    crl_files: [ %v ]
+`, serverCertPath, serverKeyPath, caPath, crlPath)
+	defer func() {
+		rdr.TriggerStopConsuming()
+		assert.NoError(t, rdr.WaitForClose(tCtx))
+	}()
+
+	require.NoError(t, sendTLSLine(t, addr, ca, &goodClient))
+	meta := readSocketServerMsg(t, rdr)
+	assert.Equal(t, "CN=good,O=Benthos", meta["socket_server_tls_client_subject"])
+	assert.Equal(t, "TLS 1.3", meta["socket_server_tls_version"])
+	assert.Contains(t, meta, "socket_server_remote_address")
+
+	assert.Error(t, sendTLSLine(t, addr, ca, &revokedClient))
+	assert.Error(t, sendTLSLine(t, addr, ca, &untrustedClient))
+	assert.Error(t, sendTLSLine(t, addr, ca, nil))
+
+	select {
+	case <-rdr.TransactionChan():
+		t.Fatal("unexpected message from rejected client")
+	case <-time.After(time.Millisecond * 100):
+	}
+}
+
+func TestTLSSocketServerClientOCSP(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*20)
+	defer done()
+
+	tmpDir := t.TempDir()
+	ca := newTestCA(t)
+
+	revokedSerials := map[string]bool{}
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqBytes, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		req, err := ocsp.ParseRequest(reqBytes)
+		require.NoError(t, err)
+
+		tmpl := ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+		}
+		if revokedSerials[req.SerialNumber.String()] {
+			tmpl.Status = ocsp.Revoked
+			tmpl.RevokedAt = time.Now().Add(-time.Minute)
+		}
+		resBytes, err := ocsp.CreateResponse(ca.cert, ca.cert, tmpl, ca.key)
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/ocsp-response")
+		_, _ = w.Write(resBytes)
+	}))
+	defer responder.Close()
+
+	serverCertPath, serverKeyPath := writeKeyPair(t, tmpDir, "server", ca.issue(t, "server", x509.ExtKeyUsageServerAuth, ""))
+	caPath := writePEM(t, tmpDir, "ca.crt", "CERTIFICATE", ca.cert.Raw)
+
+	goodClient := ca.issue(t, "good", x509.ExtKeyUsageClientAuth, responder.URL)
+	revokedClient := ca.issue(t, "revoked", x509.ExtKeyUsageClientAuth, responder.URL)
+	revokedSerials[revokedClient.Leaf.SerialNumber.String()] = true
+
+	rdr, addr := socketServerInputFromConf(t, `
+socket_server:
+  network: tls
+  address: 127.0.0.1:0
+  tls:
+    cert_file: %v
+    key_file: %v
+    client_ca_file: %v
+    ocsp:
+      enabled: true
+`, serverCertPath, serverKeyPath, caPath)
+	defer func() {
+		rdr.TriggerStopConsuming()
+		assert.NoError(t, rdr.WaitForClose(tCtx))
+	}()
+
+	require.NoError(t, sendTLSLine(t, addr, ca, &goodClient))
+	meta := readSocketServerMsg(t, rdr)
+	assert.Equal(t, "CN=good,O=Benthos", meta["socket_server_tls_client_subject"])
+
+	assert.Error(t, sendTLSLine(t, addr, ca, &revokedClient))
+}
+
+func TestTLSSocketServerClientBadConfig(t *testing.T) {
+	for _, conf := range []string{
+		`
+socket_server:
+  network: tls
+  address: 127.0.0.1:0
+  tls:
+    self_signed: true
+    crl_files: [ ./foo.crl ]
+`,
+		`
+socket_server:
+  network: tls
+  address: 127.0.0.1:0
+  tls:
+    self_signed: true
+    client_auth: require_and_verify
+`,
+	} {
+		iConf, err := testutil.InputFromYAML(conf)
+		require.NoError(t, err)
+
+		_, err = mock.NewManager().NewInput(iConf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requires a client_ca_file")
+	}
+}
@@ -0,0 +1,271 @@
+package io
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+const (
+	issFieldUDP                   = "udp"
+	issFieldUDPDatagramPerMessage = "datagram_per_message"
+	issFieldUDPReadBufferSize     = "read_buffer_size"
+	issFieldUDPSocketBufferSize   = "socket_buffer_size"
+	issFieldUDPPreset             = "preset"
+	issFieldUDPGELFChunkTimeout   = "gelf_chunk_timeout"
+	issFieldUDPGELFMaxMessageSize = "gelf_max_message_size"
+	issUDPPresetNone              = "none"
+	issUDPPresetSyslog            = "syslog"
+	issUDPPresetGELF              = "gelf"
+)
+
+func socketServerUDPFields() []*service.ConfigField {
+	return []*service.ConfigField{
+		service.NewBoolField(issFieldUDPDatagramPerMessage).
+			Description("Whether to consume each datagram as an individual message rather than as a stream of bytes broken out by the `scanner`. The codec fields are ignored when enabled.").
+			Default(false),
+		service.NewByteSizeField(issFieldUDPReadBufferSize).
+			Description("The maximum size of a datagram read when `datagram_per_message` or a `preset` is used. Datagrams larger than this are truncated.").
+			Default("64KiB"),
+		service.NewByteSizeField(issFieldUDPSocketBufferSize).
+			Description("An optional size of the receive buffer of the socket within the operating system, increasing this reduces the likelihood of datagrams being dropped during bursts of traffic. The operating system may impose a lower limit.").
+			Advanced().
+			Optional(),
+		service.NewStringAnnotatedEnumField(issFieldUDPPreset, map[string]string{
+			issUDPPresetNone:   "No preset is applied.",
+			issUDPPresetSyslog: "Each datagram is a syslog message as per RFC 5426, trailing newline and null characters are removed.",
+			issUDPPresetGELF:   "Each datagram is a GELF message, chunked messages are reassembled and compressed messages are decompressed with gzip or zlib.",
+		}).
+			Description("A framing preset for well known protocols, which implies `datagram_per_message`.").
+			Default(issUDPPresetNone),
+		service.NewDurationField(issFieldUDPGELFChunkTimeout).
+			Description("The maximum period of time to wait for all chunks of a GELF message to arrive, after which the chunks received so far are discarded.").
+			Advanced().
+			Default("5s"),
+		service.NewByteSizeField(issFieldUDPGELFMaxMessageSize).
+			Description("The maximum size of a GELF message after its chunks are reassembled and it is decompressed. Messages that exceed this size are rejected.").
+			Advanced().
+			Default("8MiB"),
+	}
+}
+
+// socketServerUDP describes how datagrams received by a UDP socket server are
+// read.
+type socketServerUDP struct {
+	datagramPerMessage bool
+	readBufferSize     int
+	socketBufferSize   int
+	preset             string
+	gelfChunkTimeout   time.Duration
+	gelfMaxMessageSize int
+}
+
+func socketServerUDPFromParsed(conf *service.ParsedConfig) (u socketServerUDP, err error) {
+	if u.datagramPerMessage, err = conf.FieldBool(issFieldUDPDatagramPerMessage); err != nil {
+		return
+	}
+	var size int64
+	if size, err = conf.FieldByteSize(issFieldUDPReadBufferSize); err != nil {
+		return
+	}
+	if size <= 0 {
+		err = fmt.Errorf("%v must be greater than zero", issFieldUDPReadBufferSize)
+		return
+	}
+	u.readBufferSize = int(size)
+	if conf.Contains(issFieldUDPSocketBufferSize) {
+		if size, err = conf.FieldByteSize(issFieldUDPSocketBufferSize); err != nil {
+			return
+		}
+		u.socketBufferSize = int(size)
+	}
+	if u.preset, err = conf.FieldString(issFieldUDPPreset); err != nil {
+		return
+	}
+	if u.preset != issUDPPresetNone {
+		u.datagramPerMessage = true
+	}
+	if u.gelfChunkTimeout, err = conf.FieldDuration(issFieldUDPGELFChunkTimeout); err != nil {
+		return
+	}
+	if size, err = conf.FieldByteSize(issFieldUDPGELFMaxMessageSize); err != nil {
+		return
+	}
+	if size <= 0 {
+		err = fmt.Errorf("%v must be greater than zero", issFieldUDPGELFMaxMessageSize)
+		return
+	}
+	u.gelfMaxMessageSize = int(size)
+	return
+}
+
+// apply sets the options of a UDP socket.
+func (u socketServerUDP) apply(conn net.PacketConn) error {
+	if u.socketBufferSize <= 0 {
+		return nil
+	}
+	udpConn, ok := conn.(*net.UDPConn)
+	if !ok {
+		return nil
+	}
+	return udpConn.SetReadBuffer(u.socketBufferSize)
+}
+
+// datagramDecoder converts datagrams into message payloads, returning nil when
+// a datagram does not yet complete a message.
+type datagramDecoder interface {
+	Decode(datagram []byte) ([]byte, error)
+}
+
+func (u socketServerUDP) decoder() datagramDecoder {
+	switch u.preset {
+	case issUDPPresetSyslog:
+		return syslogDatagramDecoder{}
+	case issUDPPresetGELF:
+		return newGELFDatagramDecoder(u.gelfChunkTimeout, u.gelfMaxMessageSize)
+	}
+	return rawDatagramDecoder{}
+}
+
+//------------------------------------------------------------------------------
+
+type rawDatagramDecoder struct{}
+
+func (rawDatagramDecoder) Decode(datagram []byte) ([]byte, error) {
+	return datagram, nil
+}
+
+type syslogDatagramDecoder struct{}
+
+func (syslogDatagramDecoder) Decode(datagram []byte) ([]byte, error) {
+	return bytes.TrimRight(datagram, "\r\n\x00"), nil
+}
+
+//------------------------------------------------------------------------------
+
+const (
+	gelfChunkHeaderSize = 12
+	gelfMaxChunks       = 128
+	gelfMaxPending      = 1024
+)
+
+var gelfChunkMagic = []byte{0x1e, 0x0f}
+
+type gelfChunks struct {
+	parts    [][]byte
+	received int
+	size     int
+	started  time.Time
+}
+
+// gelfDatagramDecoder reassembles chunked GELF messages and decompresses
+// messages compressed with gzip or zlib. At most gelfMaxPending messages are
+// reassembled at a time, beyond which the oldest is discarded.
+type gelfDatagramDecoder struct {
+	timeout time.Duration
+	maxSize int
+	pending map[string]*gelfChunks
+	nowFn   func() time.Time
+}
+
+func newGELFDatagramDecoder(timeout time.Duration, maxSize int) *gelfDatagramDecoder {
+	return &gelfDatagramDecoder{
+		timeout: timeout,
+		maxSize: maxSize,
+		pending: map[string]*gelfChunks{},
+		nowFn:   time.Now,
+	}
+}
+
+func (g *gelfDatagramDecoder) Decode(datagram []byte) ([]byte, error) {
+	if !bytes.HasPrefix(datagram, gelfChunkMagic) {
+		return gelfDecompress(datagram, g.maxSize)
+	}
+	if len(datagram) < gelfChunkHeaderSize {
+		return nil, errors.New("gelf chunk is shorter than its header")
+	}
+
+	id := string(datagram[2:10])
+	seq, count := int(datagram[10]), int(datagram[11])
+	if count == 0 || count > gelfMaxChunks {
+		return nil, fmt.Errorf("gelf chunk count %v is outside of the range 1 to %v", count, gelfMaxChunks)
+	}
+	if seq >= count {
+		return nil, fmt.Errorf("gelf chunk sequence number %v exceeds chunk count %v", seq, count)
+	}
+
+	now := g.nowFn()
+	var oldestID string
+	var oldest *gelfChunks
+	for k, c := range g.pending {
+		if now.Sub(c.started) > g.timeout {
+			delete(g.pending, k)
+			continue
+		}
+		if oldest == nil || c.started.Before(oldest.started) {
+			oldestID, oldest = k, c
+		}
+	}
+
+	c, exists := g.pending[id]
+	if !exists {
+		if len(g.pending) >= gelfMaxPending {
+			delete(g.pending, oldestID)
+		}
+		c = &gelfChunks{parts: make([][]byte, count), started: now}
+		g.pending[id] = c
+	}
+	if len(c.parts) != count {
+		delete(g.pending, id)
+		return nil, errors.New("gelf chunks of the same message disagree on the chunk count")
+	}
+	if c.parts[seq] == nil {
+		c.parts[seq] = datagram[gelfChunkHeaderSize:]
+		c.received++
+		if c.size += len(c.parts[seq]); c.size > g.maxSize {
+			delete(g.pending, id)
+			return nil, fmt.Errorf("gelf message exceeds the maximum size of %v bytes", g.maxSize)
+		}
+	}
+	if c.received < count {
+		return nil, nil
+	}
+
+	delete(g.pending, id)
+	return gelfDecompress(bytes.Join(c.parts, nil), g.maxSize)
+}
+
+// gelfDecompress decompresses a GELF message compressed with gzip or zlib, and
+// returns an error when the decompressed message exceeds maxSize.
+func gelfDecompress(data []byte, maxSize int) ([]byte, error) {
+	var r io.ReadCloser
+	var err error
+	switch {
+	case len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b:
+		r, err = gzip.NewReader(bytes.NewReader(data))
+	case len(data) >= 2 && data[0] == 0x78:
+		r, err = zlib.NewReader(bytes.NewReader(data))
+	default:
+		return data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress gelf message: %w", err)
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress gelf message: %w", err)
+	}
+	if len(out) > maxSize {
+		return nil, fmt.Errorf("decompressed gelf message exceeds the maximum size of %v bytes", maxSize)
+	}
+	return out, nil
+}
@@ -0,0 +1,158 @@
+package io
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gelfChunk(id string, seq, count byte, data string) []byte {
+	b := append([]byte{0x1e, 0x0f}, id...)
+	b = append(b, seq, count)
+	return append(b, data...)
+}
+
+func TestGELFDatagramDecoderChunks(t *testing.T) {
+	d := newGELFDatagramDecoder(time.Second, 1024)
+
+	data, err := d.Decode(gelfChunk("aaaaaaaa", 1, 3, `"hello"`))
+	require.NoError(t, err)
+	assert.Nil(t, data)
+
+	data, err = d.Decode(gelfChunk("bbbbbbbb", 0, 1, `{"short_message":"other"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"short_message":"other"}`, string(data))
+
+	data, err = d.Decode(gelfChunk("aaaaaaaa", 0, 3, `{"short_message":`))
+	require.NoError(t, err)
+	assert.Nil(t, data)
+
+	// Duplicate chunks are ignored
+	data, err = d.Decode(gelfChunk("aaaaaaaa", 0, 3, `{"short_message":`))
+	require.NoError(t, err)
+	assert.Nil(t, data)
+
+	data, err = d.Decode(gelfChunk("aaaaaaaa", 2, 3, `}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"short_message":"hello"}`, string(data))
+	assert.Empty(t, d.pending)
+}
+
+func TestGELFDatagramDecoderTimeout(t *testing.T) {
+	now := time.Unix(1000, 0)
+	d := newGELFDatagramDecoder(time.Second*5, 1024)
+	d.nowFn = func() time.Time { return now }
+
+	data, err := d.Decode(gelfChunk("aaaaaaaa", 0, 2, `{"short_message":`))
+	require.NoError(t, err)
+	assert.Nil(t, data)
+
+	now = now.Add(time.Second * 6)
+
+	data, err = d.Decode(gelfChunk("aaaaaaaa", 1, 2, `"hello"}`))
+	require.NoError(t, err)
+	assert.Nil(t, data)
+	assert.Len(t, d.pending, 1)
+}
+
+func TestGELFDatagramDecoderCompression(t *testing.T) {
+	msg := `{"short_message":"hello"}`
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	_, err := gw.Write([]byte(msg))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	var zlibbed bytes.Buffer
+	zw := zlib.NewWriter(&zlibbed)
+	_, err = zw.Write([]byte(msg))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	d := newGELFDatagramDecoder(time.Second, 1024)
+
+	data, err := d.Decode(gzipped.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, msg, string(data))
+
+	data, err = d.Decode(zlibbed.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, msg, string(data))
+
+	half := gzipped.Len() / 2
+	data, err = d.Decode(gelfChunk("cccccccc", 0, 2, gzipped.String()[:half]))
+	require.NoError(t, err)
+	assert.Nil(t, data)
+
+	data, err = d.Decode(gelfChunk("cccccccc", 1, 2, gzipped.String()[half:]))
+	require.NoError(t, err)
+	assert.Equal(t, msg, string(data))
+}
+
+func TestGELFDatagramDecoderErrors(t *testing.T) {
+	d := newGELFDatagramDecoder(time.Second, 1024)
+
+	_, err := d.Decode([]byte{0x1e, 0x0f, 'a'})
+	require.Error(t, err)
+
+	_, err = d.Decode(gelfChunk("aaaaaaaa", 0, 129, "foo"))
+	require.Error(t, err)
+
+	_, err = d.Decode(gelfChunk("aaaaaaaa", 2, 2, "foo"))
+	require.Error(t, err)
+
+	_, err = d.Decode([]byte{0x1f, 0x8b, 0x00})
+	require.Error(t, err)
+}
+
+func TestGELFDatagramDecoderMaxSize(t *testing.T) {
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	_, err := gw.Write(bytes.Repeat([]byte("a"), 1025))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	d := newGELFDatagramDecoder(time.Second, 1024)
+
+	_, err = d.Decode(gzipped.Bytes())
+	require.Error(t, err)
+
+	_, err = d.Decode(gelfChunk("aaaaaaaa", 0, 2, string(bytes.Repeat([]byte("a"), 600))))
+	require.NoError(t, err)
+
+	_, err = d.Decode(gelfChunk("aaaaaaaa", 1, 2, string(bytes.Repeat([]byte("a"), 600))))
+	require.Error(t, err)
+	assert.Empty(t, d.pending)
+}
+
+func TestGELFDatagramDecoderMaxPending(t *testing.T) {
+	now := time.Unix(1000, 0)
+	d := newGELFDatagramDecoder(time.Second*5, 1024)
+	d.nowFn = func() time.Time {
+		now = now.Add(time.Millisecond)
+		return now
+	}
+
+	for i := 0; i < gelfMaxPending+10; i++ {
+		id := fmt.Sprintf("%08d", i)
+		data, err := d.Decode(gelfChunk(id, 0, 2, "foo"))
+		require.NoError(t, err)
+		assert.Nil(t, data)
+	}
+	assert.Len(t, d.pending, gelfMaxPending)
+	assert.NotContains(t, d.pending, "00000000")
+	assert.Contains(t, d.pending, fmt.Sprintf("%08d", gelfMaxPending+9))
+}
+
+func TestSyslogDatagramDecoder(t *testing.T) {
+	data, err := syslogDatagramDecoder{}.Decode([]byte("<34>1 2003-10-11T22:14:15.003Z mymachine su - ID47 - hello\n\x00"))
+	require.NoError(t, err)
+	assert.Equal(t, "<34>1 2003-10-11T22:14:15.003Z mymachine su - ID47 - hello", string(data))
+}
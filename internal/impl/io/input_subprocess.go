@@ -8,6 +8,9 @@ import (
 	"io"
 	"os/exec"
 	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
 
 	"github.com/redpanda-data/benthos/v4/internal/component"
 	"github.com/redpanda-data/benthos/v4/public/service"
@@ -19,6 +22,15 @@ const (
 	spiFieldCodec         = "codec"
 	spiFieldRestartOnExit = "restart_on_exit"
 	spiFieldMaxBuffer     = "max_buffer"
+
+	spiFieldRestartPolicy              = "restart_policy"
+	spiFieldRestartPolicyMaxRestarts   = "max_restarts"
+	spiFieldRestartPolicyOnFailureOnly = "on_failure_only"
+	spiFieldRestartPolicyBackoff       = "backoff"
+
+	spiFieldReadinessProbe        = "readiness_probe"
+	spiFieldReadinessProbeLine    = "line"
+	spiFieldReadinessProbeTimeout = "timeout"
 )
 
 func subprocInputSpec() *service.ConfigSpec {
@@ -31,7 +43,15 @@ Messages are consumed according to a specified codec. The command is executed on
 
 The field `+"`max_buffer`"+` defines the maximum message size able to be read from the subprocess. This value should be set significantly above the real expected maximum message size.
 
-The execution environment of the subprocess is the same as the Benthos instance, including environment variables and the current working directory.`).
+The execution environment of the subprocess is the same as the Benthos instance, including environment variables and the current working directory.
+
+== Restarts
+
+When `+"`restart_on_exit`"+` is set to `+"`true`"+` the field `+"`restart_policy`"+` determines how the command is supervised. A subprocess that exits after becoming ready is restarted immediately, which is when it writes the line of the `+"`readiness_probe`"+` if one is configured, or otherwise when it writes its first message. Subsequent restarts of a subprocess that fails to become ready are delayed according to `+"`restart_policy.backoff`"+`, and once `+"`restart_policy.max_restarts`"+` consecutive restarts have been made the input shuts down.
+
+== Readiness Probes
+
+Long running commands often perform some setup before they produce data, and by configuring a `+"`readiness_probe`"+` the input waits for the subprocess to write a specific line to stdout before consuming messages from it. Any lines written before it, including the line itself, are discarded. If the line isn't written within the timeout of the probe then the subprocess is killed and treated as having failed.`).
 		Fields(
 			service.NewStringField(spiFieldName).
 				Description("The command to execute as a subprocess.").
@@ -49,12 +69,39 @@ The execution environment of the subprocess is the same as the Benthos instance,
 				Description("The maximum expected size of an individual message.").
 				Advanced().
 				Default(bufio.MaxScanTokenSize),
+			service.NewObjectField(spiFieldRestartPolicy,
+				service.NewIntField(spiFieldRestartPolicyMaxRestarts).
+					Description("The maximum number of consecutive restarts before the input shuts down, where zero means there is no limit.").
+					Default(0),
+				service.NewBoolField(spiFieldRestartPolicyOnFailureOnly).
+					Description("Whether to only restart the subprocess when it exits with a non-zero status, in which case the input shuts down when it exits successfully.").
+					Default(false),
+				service.NewBackOffPolicyField(spiFieldRestartPolicyBackoff, true, &backoff.ExponentialBackOff{
+					InitialInterval: time.Second,
+					MaxInterval:     time.Minute,
+				}).Description("The back off applied between consecutive restarts of a subprocess that fails to become ready."),
+			).
+				Description("Determines how the subprocess is restarted when `restart_on_exit` is `true`.").
+				Version("4.29.0").
+				Advanced(),
+			service.NewObjectField(spiFieldReadinessProbe,
+				service.NewStringField(spiFieldReadinessProbeLine).
+					Description("A line that the subprocess writes to stdout once it is ready.").
+					Examples("READY"),
+				service.NewDurationField(spiFieldReadinessProbeTimeout).
+					Description("The maximum period of time to wait for the subprocess to become ready.").
+					Default("30s"),
+			).
+				Description("An optional probe that delays the consumption of messages until the subprocess indicates that it is ready.").
+				Version("4.29.0").
+				Advanced().
+				Optional(),
 		)
 }
 
 func init() {
 	err := service.RegisterBatchInput("subprocess", subprocInputSpec(), func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
-		return newSubprocessReaderFromParsed(conf)
+		return newSubprocessReaderFromParsed(conf, mgr)
 	})
 	if err != nil {
 		panic(err)
@@ -99,15 +146,31 @@ type subprocessReader struct {
 	maxBuf        int
 	codec         subprocInputCodec
 
-	msgChan chan []byte
-	errChan chan error
+	maxRestarts   int
+	onFailureOnly bool
+	backoff       backoff.BackOff
+	readyLine     string
+	readyTimeout  time.Duration
 
+	proc     *subprocess
+	restarts int
+
+	log   *service.Logger
 	close func()
 	ctx   context.Context
 }
 
-func newSubprocessReaderFromParsed(conf *service.ParsedConfig) (s *subprocessReader, err error) {
-	s = &subprocessReader{}
+// subprocess is a running command and the channels of its output.
+type subprocess struct {
+	msgChan  chan []byte
+	errChan  chan error
+	exitChan chan error
+	ready    bool
+	kill     func()
+}
+
+func newSubprocessReaderFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (s *subprocessReader, err error) {
+	s = &subprocessReader{log: mgr.Logger()}
 	s.ctx, s.close = context.WithCancel(context.Background())
 
 	if s.name, err = conf.FieldString(spiFieldName); err != nil {
@@ -123,6 +186,25 @@ func newSubprocessReaderFromParsed(conf *service.ParsedConfig) (s *subprocessRea
 		return
 	}
 
+	if s.maxRestarts, err = conf.FieldInt(spiFieldRestartPolicy, spiFieldRestartPolicyMaxRestarts); err != nil {
+		return
+	}
+	if s.onFailureOnly, err = conf.FieldBool(spiFieldRestartPolicy, spiFieldRestartPolicyOnFailureOnly); err != nil {
+		return
+	}
+	if s.backoff, err = conf.FieldBackOffPolicy(spiFieldRestartPolicy, spiFieldRestartPolicyBackoff); err != nil {
+		return
+	}
+
+	if conf.Contains(spiFieldReadinessProbe) {
+		if s.readyLine, err = conf.FieldString(spiFieldReadinessProbe, spiFieldReadinessProbeLine); err != nil {
+			return
+		}
+		if s.readyTimeout, err = conf.FieldDuration(spiFieldReadinessProbe, spiFieldReadinessProbeTimeout); err != nil {
+			return
+		}
+	}
+
 	var codecStr string
 	if codecStr, err = conf.FieldString(spiFieldCodec); err != nil {
 		return nil, err
@@ -134,30 +216,43 @@ func newSubprocessReaderFromParsed(conf *service.ParsedConfig) (s *subprocessRea
 	return s, nil
 }
 
-func (s *subprocessReader) Connect(ctx context.Context) error {
-	if s.msgChan != nil {
-		return nil
-	}
-
-	cmd := exec.CommandContext(s.ctx, s.name, s.args...)
+func (s *subprocessReader) start() (*subprocess, error) {
+	procCtx, kill := context.WithCancel(s.ctx)
+	cmd := exec.CommandContext(procCtx, s.name, s.args...)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return err
+		kill()
+		return nil, err
 	}
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		return err
+		kill()
+		return nil, err
 	}
 	if err := cmd.Start(); err != nil {
-		return err
+		kill()
+		return nil, err
 	}
 
-	msgChan := make(chan []byte)
-	errChan := make(chan error)
+	proc := &subprocess{
+		msgChan:  make(chan []byte),
+		errChan:  make(chan error),
+		exitChan: make(chan error, 1),
+		kill:     kill,
+	}
 
 	outScanner, errScanner := s.codec(s.maxBuf, stdout, stderr)
 
+	// Killing the process doesn't kill any of its children, which could hold
+	// the pipes open, and therefore we close them explicitly in order to
+	// unblock the scanners.
+	go func() {
+		<-procCtx.Done()
+		_ = stdout.Close()
+		_ = stderr.Close()
+	}()
+
 	go func() {
 		wg := sync.WaitGroup{}
 		wg.Add(2)
@@ -171,15 +266,15 @@ func (s *subprocessReader) Connect(ctx context.Context) error {
 				copy(dataCopy, data)
 
 				select {
-				case msgChan <- dataCopy:
-				case <-s.ctx.Done():
+				case proc.msgChan <- dataCopy:
+				case <-procCtx.Done():
 				}
 			}
 
 			if err := outScanner.Err(); err != nil {
 				select {
-				case errChan <- err:
-				case <-s.ctx.Done():
+				case proc.errChan <- err:
+				case <-procCtx.Done():
 				}
 			}
 		}()
@@ -189,55 +284,160 @@ func (s *subprocessReader) Connect(ctx context.Context) error {
 
 			for errScanner.Scan() {
 				select {
-				case errChan <- errors.New(errScanner.Text()):
-				case <-s.ctx.Done():
+				case proc.errChan <- errors.New(errScanner.Text()):
+				case <-procCtx.Done():
 				}
 			}
 
 			if err := errScanner.Err(); err != nil {
 				select {
-				case errChan <- err:
-				case <-s.ctx.Done():
+				case proc.errChan <- err:
+				case <-procCtx.Done():
 				}
 			}
 		}()
 
 		wg.Wait()
-		close(msgChan)
-		close(errChan)
+
+		// The exit status is sent before closing the output channels so that
+		// it's available as soon as they're seen as closed.
+		proc.exitChan <- cmd.Wait()
+		close(proc.exitChan)
+		kill()
+
+		close(proc.msgChan)
+		close(proc.errChan)
 	}()
 
-	s.msgChan = msgChan
-	s.errChan = errChan
+	return proc, nil
+}
+
+func (s *subprocessReader) Connect(ctx context.Context) error {
+	if s.proc != nil {
+		return nil
+	}
+
+	// A subprocess that became ready is restarted immediately, otherwise the
+	// restart is delayed.
+	if s.restarts > 1 {
+		wait := s.backoff.NextBackOff()
+		if wait == backoff.Stop {
+			s.log.Error("Maximum elapsed time of subprocess restarts has been met, shutting down")
+			return service.ErrEndOfInput
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	proc, err := s.start()
+	if err != nil {
+		return err
+	}
+	if s.readyLine != "" {
+		if err := s.awaitReady(ctx, proc); err != nil {
+			return err
+		}
+	}
+
+	s.proc = proc
 	return nil
 }
 
+// awaitReady consumes the output of a subprocess until it writes the line of
+// the readiness probe. If the subprocess exits or the probe times out then it
+// is treated as a failure, and an error is returned.
+func (s *subprocessReader) awaitReady(ctx context.Context, proc *subprocess) error {
+	timer := time.NewTimer(s.readyTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case b, open := <-proc.msgChan:
+			if !open {
+				if err := s.exited(<-proc.exitChan); errors.Is(err, service.ErrEndOfInput) {
+					return err
+				}
+				return errors.New("subprocess exited before becoming ready")
+			}
+			if string(b) == s.readyLine {
+				s.markReady(proc)
+				return nil
+			}
+			s.log.Debugf("Discarding line from subprocess before it became ready: %s", b)
+		case err, open := <-proc.errChan:
+			if open {
+				s.log.Warnf("Subprocess: %v", err)
+			}
+		case <-timer.C:
+			proc.kill()
+			for range proc.msgChan {
+			}
+			if err := s.exited(<-proc.exitChan); errors.Is(err, service.ErrEndOfInput) {
+				return err
+			}
+			return fmt.Errorf("subprocess did not become ready within %v", s.readyTimeout)
+		case <-ctx.Done():
+			proc.kill()
+			return ctx.Err()
+		}
+	}
+}
+
+// markReady resets the restart back off and count once a subprocess has
+// become ready.
+func (s *subprocessReader) markReady(proc *subprocess) {
+	if proc.ready {
+		return
+	}
+	proc.ready = true
+	s.restarts = 0
+	s.backoff.Reset()
+}
+
+// exited handles the termination of a subprocess, returning ErrNotConnected
+// when it should be restarted and ErrEndOfInput otherwise.
+func (s *subprocessReader) exited(exitErr error) error {
+	s.proc = nil
+	if s.ctx.Err() != nil {
+		return service.ErrEndOfInput
+	}
+	if exitErr != nil {
+		s.log.Errorf("Subprocess exited: %v", exitErr)
+	}
+	if !s.restartOnExit {
+		return service.ErrEndOfInput
+	}
+	if s.onFailureOnly && exitErr == nil {
+		s.log.Info("Subprocess exited successfully, shutting down")
+		return service.ErrEndOfInput
+	}
+	if s.maxRestarts > 0 && s.restarts >= s.maxRestarts {
+		s.log.Errorf("Subprocess has been restarted %v consecutive times, shutting down", s.restarts)
+		return service.ErrEndOfInput
+	}
+	s.restarts++
+	return service.ErrNotConnected
+}
+
 func (s *subprocessReader) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
-	msgChan, errChan := s.msgChan, s.errChan
-	if msgChan == nil {
+	proc := s.proc
+	if proc == nil {
 		return nil, nil, service.ErrNotConnected
 	}
 
 	select {
-	case b, open := <-msgChan:
+	case b, open := <-proc.msgChan:
 		if !open {
-			if s.restartOnExit {
-				s.msgChan = nil
-				s.errChan = nil
-				return nil, nil, service.ErrNotConnected
-			}
-			return nil, nil, service.ErrEndOfInput
+			return nil, nil, s.exited(<-proc.exitChan)
 		}
+		s.markReady(proc)
 		msg := service.MessageBatch{service.NewMessage(b)}
 		return msg, func(context.Context, error) error { return nil }, nil
-	case err, open := <-errChan:
+	case err, open := <-proc.errChan:
 		if !open {
-			if s.restartOnExit {
-				s.msgChan = nil
-				s.errChan = nil
-				return nil, nil, service.ErrNotConnected
-			}
-			return nil, nil, component.ErrTypeClosed
+			return nil, nil, s.exited(<-proc.exitChan)
 		}
 		return nil, nil, err
 	case <-ctx.Done():
@@ -3,6 +3,8 @@ package io_test
 import (
 	"context"
 	"fmt"
+	"os"
+	"path"
 	"testing"
 	"time"
 
@@ -171,3 +173,171 @@ subprocess:
 	i.TriggerStopConsuming()
 	require.NoError(t, i.WaitForClose(ctx))
 }
+
+func requireInputClosed(t *testing.T, i input.Streamed) {
+	t.Helper()
+
+	select {
+	case _, open := <-i.TransactionChan():
+		require.False(t, open)
+	case <-time.After(time.Second * 20):
+		t.Fatal("timed out")
+	}
+}
+
+const countingTestProgram = `package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Counts each execution within a file and prints the count, exiting with an
+// error until the count reaches the exit threshold.
+func main() {
+	b, _ := os.ReadFile(os.Args[1])
+	count, _ := strconv.Atoi(string(b))
+	count++
+	_ = os.WriteFile(os.Args[1], []byte(strconv.Itoa(count)), 0o666)
+
+	threshold, _ := strconv.Atoi(os.Args[2])
+	if os.Args[3] == "print" {
+		fmt.Printf("run:%v\n", count)
+	}
+	if count < threshold {
+		os.Exit(1)
+	}
+}
+`
+
+func TestSubprocessRestartOnFailureOnly(t *testing.T) {
+	filePath := testProgram(t, countingTestProgram)
+	countPath := path.Join(t.TempDir(), "count")
+
+	i := testInput(t, `
+subprocess:
+  name: go
+  args: [ "run", "%v", "%v", "3", "print" ]
+  restart_on_exit: true
+  restart_policy:
+    on_failure_only: true
+`, filePath, countPath)
+
+	for j := 1; j <= 3; j++ {
+		msg := readMsg(t, i.TransactionChan())
+		assert.Equal(t, fmt.Sprintf("run:%v", j), string(msg.Get(0).AsBytes()))
+	}
+	requireInputClosed(t, i)
+}
+
+func TestSubprocessMaxRestarts(t *testing.T) {
+	filePath := testProgram(t, countingTestProgram)
+	countPath := path.Join(t.TempDir(), "count")
+
+	i := testInput(t, `
+subprocess:
+  name: go
+  args: [ "run", "%v", "%v", "100", "silent" ]
+  restart_on_exit: true
+  restart_policy:
+    max_restarts: 2
+    backoff:
+      initial_interval: 1ms
+      max_interval: 1ms
+`, filePath, countPath)
+
+	requireInputClosed(t, i)
+
+	b, err := os.ReadFile(countPath)
+	require.NoError(t, err)
+	assert.Equal(t, "3", string(b))
+}
+
+func TestSubprocessMaxRestartsLinearBackOff(t *testing.T) {
+	filePath := testProgram(t, countingTestProgram)
+	countPath := path.Join(t.TempDir(), "count")
+
+	i := testInput(t, `
+subprocess:
+  name: go
+  args: [ "run", "%v", "%v", "100", "silent" ]
+  restart_on_exit: true
+  restart_policy:
+    max_restarts: 2
+    backoff:
+      strategy: linear
+      initial_interval: 1ms
+      max_interval: 2ms
+`, filePath, countPath)
+
+	requireInputClosed(t, i)
+
+	b, err := os.ReadFile(countPath)
+	require.NoError(t, err)
+	assert.Equal(t, "3", string(b))
+}
+
+func TestSubprocessReadinessProbe(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*20)
+	defer done()
+
+	filePath := testProgram(t, `package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func main() {
+	fmt.Println("starting up")
+	time.Sleep(time.Millisecond * 100)
+	fmt.Println("READY")
+	fmt.Println("foo")
+	fmt.Println("bar")
+}
+`)
+
+	i := testInput(t, `
+subprocess:
+  name: go
+  args: [ "run", "%v" ]
+  readiness_probe:
+    line: READY
+`, filePath)
+
+	msg := readMsg(t, i.TransactionChan())
+	assert.Equal(t, "foo", string(msg.Get(0).AsBytes()))
+
+	msg = readMsg(t, i.TransactionChan())
+	assert.Equal(t, "bar", string(msg.Get(0).AsBytes()))
+
+	requireInputClosed(t, i)
+	require.NoError(t, i.WaitForClose(ctx))
+}
+
+func TestSubprocessReadinessProbeTimeout(t *testing.T) {
+	filePath := testProgram(t, `package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func main() {
+	fmt.Println("foo")
+	time.Sleep(time.Second * 10)
+}
+`)
+
+	i := testInput(t, `
+subprocess:
+  name: go
+  args: [ "run", "%v" ]
+  readiness_probe:
+    line: READY
+    timeout: 2s
+`, filePath)
+
+	requireInputClosed(t, i)
+}
@@ -0,0 +1,396 @@
+package io
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/shutdown"
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	"github.com/redpanda-data/benthos/v4/internal/bundle"
+	"github.com/redpanda-data/benthos/v4/internal/component/input"
+	"github.com/redpanda-data/benthos/v4/internal/component/interop"
+	"github.com/redpanda-data/benthos/v4/internal/component/metrics"
+	"github.com/redpanda-data/benthos/v4/internal/log"
+	"github.com/redpanda-data/benthos/v4/internal/message"
+	"github.com/redpanda-data/benthos/v4/internal/old/util/throttle"
+	"github.com/redpanda-data/benthos/v4/internal/tracing"
+	"github.com/redpanda-data/benthos/v4/internal/transaction"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+const (
+	wssiFieldPath      = "path"
+	wssiFieldAuthCheck = "auth_check"
+)
+
+type wssiConfig struct {
+	Path      string
+	AuthCheck *bloblang.Executor
+}
+
+func wssiConfigFromParsed(pConf *service.ParsedConfig) (conf wssiConfig, err error) {
+	if conf.Path, err = pConf.FieldString(wssiFieldPath); err != nil {
+		return
+	}
+	if pConf.Contains(wssiFieldAuthCheck) {
+		if conf.AuthCheck, err = pConf.FieldBloblang(wssiFieldAuthCheck); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func wssiSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Network").
+		Version("4.29.0").
+		Summary(`Accepts websocket connections on an endpoint of the xref:components:http/about.adoc[service-wide HTTP server] and receives messages from them.`).
+		Description(`
+Each frame received from a connection is passed through the pipeline as a batch of one message, and the next frame of a connection is not read until the previous one has been delivered. Frames that fail to be delivered are retried.
+
+xref:guides:sync_responses.adoc[Synchronous responses] are sent back to the connection that a message was received from as frames of the same type as the received frame.
+
+== Authentication
+
+An optional `+"`auth_check`"+` can be specified, which is a Bloblang query that is executed against each connection request before it is upgraded to a websocket. The query has access to the metadata of the request, as described below, and must return the boolean `+"`true`"+` in order for the connection to be accepted. Connections that fail the check, including when the query fails, are rejected with a 401 status code.
+
+== Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- websocket_server_connection_id
+- websocket_server_message_type
+- websocket_server_request_path
+- websocket_server_remote_ip
+- websocket_server_user_agent
+- All headers of the connection request (only first values are taken)
+- All query parameters of the connection request
+- All path parameters of the connection request
+- All cookies of the connection request
+`+"```"+`
+
+The field `+"`websocket_server_connection_id`"+` is a unique identifier for each connection, and `+"`websocket_server_message_type`"+` is either `+"`text` or `binary`"+`.
+
+You can access these metadata fields using xref:configuration:interpolation.adoc#bloblang-queries[function interpolation].`).
+		Fields(
+			service.NewStringField(wssiFieldPath).
+				Description("The endpoint path to accept websocket connections from, which supports path parameters of the form `/\\{foo}`.").
+				Example("/ws").
+				Example("/ws/{tenant}"),
+			service.NewBloblangField(wssiFieldAuthCheck).
+				Description("An optional query that is executed against each connection request and must return `true` for the connection to be accepted.").
+				Example(`@Authorization == "Bearer " + env("WS_TOKEN")`).
+				Example(`@tenant.or("") != "" && @websocket_server_remote_ip.has_prefix("10.")`).
+				Optional(),
+		).
+		Example(
+			"Echo Server",
+			"This example accepts websocket connections from authenticated clients and echoes each message back to them in upper case:", `
+input:
+  websocket_server:
+    path: /echo
+    auth_check: '@Authorization == "Bearer " + env("WS_TOKEN")'
+  processors:
+    - mapping: 'root = content().uppercase()'
+    - sync_response: {}
+`)
+}
+
+func init() {
+	err := service.RegisterBatchInput(
+		"websocket_server", wssiSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+			wssiConf, err := wssiConfigFromParsed(conf)
+			if err != nil {
+				return nil, err
+			}
+
+			i, err := newWebsocketServerInput(wssiConf, interop.UnwrapManagement(mgr))
+			if err != nil {
+				return nil, err
+			}
+			return interop.NewUnwrapInternalInput(i), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type websocketServerInput struct {
+	conf wssiConfig
+	log  log.Modular
+	mgr  bundle.NewManagement
+
+	handlerWG    sync.WaitGroup
+	transactions chan message.Transaction
+
+	shutSig *shutdown.Signaller
+
+	mRcvd    metrics.StatCounter
+	mLatency metrics.StatTimer
+}
+
+func newWebsocketServerInput(conf wssiConfig, mgr bundle.NewManagement) (input.Streamed, error) {
+	if conf.Path == "" {
+		return nil, errors.New("a path must be specified")
+	}
+
+	w := &websocketServerInput{
+		conf:         conf,
+		log:          mgr.Logger(),
+		mgr:          mgr,
+		transactions: make(chan message.Transaction),
+		shutSig:      shutdown.NewSignaller(),
+		mRcvd:        mgr.Metrics().GetCounter("input_received"),
+		mLatency:     mgr.Metrics().GetTimer("input_latency_ns"),
+	}
+
+	mgr.RegisterEndpoint(conf.Path, "Send messages via websocket into Benthos.", w.wsHandler)
+
+	go w.loop()
+	return w, nil
+}
+
+//------------------------------------------------------------------------------
+
+// setConnectionMetadata adds the details of a websocket connection request to
+// the metadata of a message.
+func setConnectionMetadata(r *http.Request, connID string, p *message.Part) {
+	p.MetaSetMut("websocket_server_connection_id", connID)
+	p.MetaSetMut("websocket_server_request_path", r.URL.Path)
+	p.MetaSetMut("websocket_server_user_agent", r.UserAgent())
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		p.MetaSetMut("websocket_server_remote_ip", host)
+	}
+	for k, v := range r.Header {
+		if len(v) > 0 {
+			p.MetaSetMut(k, v[0])
+		}
+	}
+	for k, v := range r.URL.Query() {
+		if len(v) > 0 {
+			p.MetaSetMut(k, v[0])
+		}
+	}
+	for k, v := range mux.Vars(r) {
+		p.MetaSetMut(k, v)
+	}
+	for _, c := range r.Cookies() {
+		p.MetaSetMut(c.Name, c.Value)
+	}
+}
+
+func (w *websocketServerInput) authorised(r *http.Request, connID string) (bool, error) {
+	if w.conf.AuthCheck == nil {
+		return true, nil
+	}
+
+	part := message.NewPart(nil)
+	setConnectionMetadata(r, connID, part)
+
+	res, err := service.NewInternalMessage(part).BloblangQuery(w.conf.AuthCheck)
+	if err != nil {
+		return false, err
+	}
+	if res == nil {
+		return false, errors.New("query resulted in a deleted message")
+	}
+	v, err := res.AsStructured()
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected boolean result, got %T", v)
+	}
+	return b, nil
+}
+
+func (w *websocketServerInput) wsHandler(rw http.ResponseWriter, r *http.Request) {
+	if w.shutSig.IsSoftStopSignalled() {
+		http.Error(rw, "Server closing", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.handlerWG.Add(1)
+	defer w.handlerWG.Done()
+
+	connID := uuid.Must(uuid.NewV4()).String()
+
+	authed, err := w.authorised(r, connID)
+	if err != nil {
+		w.log.Debug("Websocket auth check failed: %v\n", err)
+	}
+	if !authed {
+		http.Error(rw, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	upgrader := websocket.Upgrader{}
+
+	ws, err := upgrader.Upgrade(rw, r, nil)
+	if err != nil {
+		w.log.Warn("Websocket request failed: %v\n", err)
+		return
+	}
+	defer ws.Close()
+
+	// Reads of the connection block until a frame arrives, and therefore are
+	// interrupted on shutdown in order to allow the handler to exit.
+	connDone := make(chan struct{})
+	monitorExited := make(chan struct{})
+	defer func() {
+		close(connDone)
+		<-monitorExited
+	}()
+	go func() {
+		defer close(monitorExited)
+		select {
+		case <-w.shutSig.SoftStopChan():
+			_ = ws.SetReadDeadline(time.Now())
+		case <-connDone:
+		}
+	}()
+
+	resChan := make(chan error, 1)
+	throt := throttle.New(throttle.OptCloseChan(w.shutSig.SoftStopChan()))
+
+	for !w.shutSig.IsSoftStopSignalled() {
+		msgType, msgBytes, err := ws.ReadMessage()
+		if err != nil {
+			if !w.shutSig.IsSoftStopSignalled() && !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				w.log.Debug("Websocket connection %v closed: %v\n", connID, err)
+			}
+			return
+		}
+		w.mRcvd.Incr(1)
+
+		msg := message.QuickBatch([][]byte{msgBytes})
+		part := msg.Get(0)
+		setConnectionMetadata(r, connID, part)
+		if msgType == websocket.TextMessage {
+			part.MetaSetMut("websocket_server_message_type", "text")
+		} else {
+			part.MetaSetMut("websocket_server_message_type", "binary")
+		}
+
+		if !w.deliver(ws, msg, msgType, resChan, throt) {
+			return
+		}
+	}
+}
+
+// deliver sends a message through the pipeline, retrying until it is
+// delivered, and writes any synchronous responses to the connection. Returns
+// false if the connection should be closed.
+func (w *websocketServerInput) deliver(ws *websocket.Conn, msg message.Batch, msgType int, resChan chan error, throt *throttle.Type) bool {
+	tracing.InitSpans(w.mgr.Tracer(), "input_websocket_server", msg)
+	defer tracing.FinishSpans(msg)
+
+	startedAt := time.Now()
+	for {
+		store := transaction.NewResultStore()
+		transaction.AddResultStore(msg, store)
+
+		select {
+		case w.transactions <- message.NewTransaction(msg, resChan):
+		case <-w.shutSig.SoftStopChan():
+			return false
+		}
+
+		select {
+		case res, open := <-resChan:
+			if !open {
+				return false
+			}
+			if res != nil {
+				if !throt.Retry() {
+					return false
+				}
+				continue
+			}
+		case <-w.shutSig.HardStopChan():
+			return false
+		}
+
+		w.mLatency.Timing(time.Since(startedAt).Nanoseconds())
+		throt.Reset()
+
+		for _, responseMsg := range store.Get() {
+			if err := responseMsg.Iter(func(i int, part *message.Part) error {
+				return ws.WriteMessage(msgType, part.AsBytes())
+			}); err != nil {
+				w.log.Error("Failed to send sync response over websocket: %v\n", err)
+			}
+		}
+		return true
+	}
+}
+
+//------------------------------------------------------------------------------
+
+func (w *websocketServerInput) loop() {
+	defer func() {
+		// In order to prevent new connections once shut down the endpoint is
+		// replaced, but only once current connections have been closed or
+		// we've been instructed to close immediately.
+		go func() {
+			select {
+			case <-w.shutSig.HasStoppedChan():
+			case <-w.shutSig.HardStopChan():
+			}
+			w.mgr.RegisterEndpoint(w.conf.Path, "Endpoint disabled.", func(rw http.ResponseWriter, r *http.Request) {
+				http.Error(rw, "Service unavailable", http.StatusServiceUnavailable)
+			})
+		}()
+
+		w.handlerWG.Wait()
+
+		close(w.transactions)
+		w.shutSig.TriggerHasStopped()
+	}()
+
+	<-w.shutSig.SoftStopChan()
+}
+
+// TransactionChan returns a transactions channel for consuming messages from
+// this input.
+func (w *websocketServerInput) TransactionChan() <-chan message.Transaction {
+	return w.transactions
+}
+
+// Connected returns a boolean indicating whether this input is currently
+// connected to its target.
+func (w *websocketServerInput) Connected() bool {
+	return true
+}
+
+func (w *websocketServerInput) TriggerStopConsuming() {
+	w.shutSig.TriggerSoftStop()
+}
+
+func (w *websocketServerInput) TriggerCloseNow() {
+	w.shutSig.TriggerHardStop()
+}
+
+func (w *websocketServerInput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-w.shutSig.HasStoppedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
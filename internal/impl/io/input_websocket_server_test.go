@@ -0,0 +1,196 @@
+package io_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/component/input"
+	"github.com/redpanda-data/benthos/v4/internal/manager"
+	"github.com/redpanda-data/benthos/v4/internal/message"
+	"github.com/redpanda-data/benthos/v4/internal/transaction"
+)
+
+func startWebsocketServerInput(t testing.TB, extraConf string) (input.Streamed, *url.URL) {
+	t.Helper()
+
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+
+	mgr, err := manager.New(manager.ResourceConfig{}, manager.OptSetAPIReg(reg))
+	require.NoError(t, err)
+
+	conf := parseYAMLInputConf(t, `
+websocket_server:
+  path: /ws/{tenant}
+%v`, extraConf)
+
+	in, err := mgr.NewInput(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+		defer done()
+		in.TriggerStopConsuming()
+		assert.NoError(t, in.WaitForClose(ctx))
+	})
+
+	server := httptest.NewServer(reg.mut)
+	t.Cleanup(server.Close)
+
+	purl, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	purl.Scheme = "ws"
+	return in, purl
+}
+
+func readWebsocketTransaction(t testing.TB, in input.Streamed) message.Transaction {
+	t.Helper()
+	select {
+	case tran := <-in.TransactionChan():
+		return tran
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for message")
+	}
+	return message.Transaction{}
+}
+
+func TestWebsocketServerInput(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	in, purl := startWebsocketServerInput(t, "")
+
+	client, _, err := websocket.DefaultDialer.Dial(purl.String()+"/ws/acme?foo=bar", http.Header{
+		"X-Baz": []string{"buz"},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte("hello world")))
+
+	tran := readWebsocketTransaction(t, in)
+	part := tran.Payload.Get(0)
+	assert.Equal(t, "hello world", string(part.AsBytes()))
+	assert.Equal(t, "text", part.MetaGetStr("websocket_server_message_type"))
+	assert.Equal(t, "/ws/acme", part.MetaGetStr("websocket_server_request_path"))
+	assert.Equal(t, "127.0.0.1", part.MetaGetStr("websocket_server_remote_ip"))
+	assert.Equal(t, "acme", part.MetaGetStr("tenant"))
+	assert.Equal(t, "bar", part.MetaGetStr("foo"))
+	assert.Equal(t, "buz", part.MetaGetStr("X-Baz"))
+
+	connID := part.MetaGetStr("websocket_server_connection_id")
+	assert.NotEmpty(t, connID)
+
+	part.SetBytes([]byte("hello back"))
+	require.NoError(t, transaction.SetAsResponse(tran.Payload))
+	require.NoError(t, tran.Ack(tCtx, nil))
+
+	msgType, resBytes, err := client.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, websocket.TextMessage, msgType)
+	assert.Equal(t, "hello back", string(resBytes))
+
+	// Rejected messages are redelivered.
+	require.NoError(t, client.WriteMessage(websocket.BinaryMessage, []byte("second")))
+
+	tran = readWebsocketTransaction(t, in)
+	require.NoError(t, tran.Ack(tCtx, errors.New("nope")))
+
+	tran = readWebsocketTransaction(t, in)
+	part = tran.Payload.Get(0)
+	assert.Equal(t, "second", string(part.AsBytes()))
+	assert.Equal(t, "binary", part.MetaGetStr("websocket_server_message_type"))
+	assert.Equal(t, connID, part.MetaGetStr("websocket_server_connection_id"))
+	require.NoError(t, tran.Ack(tCtx, nil))
+
+	// Each connection has a unique identifier.
+	clientTwo, _, err := websocket.DefaultDialer.Dial(purl.String()+"/ws/acme", http.Header{})
+	require.NoError(t, err)
+	defer clientTwo.Close()
+
+	require.NoError(t, clientTwo.WriteMessage(websocket.TextMessage, []byte("third")))
+
+	tran = readWebsocketTransaction(t, in)
+	assert.NotEqual(t, connID, tran.Payload.Get(0).MetaGetStr("websocket_server_connection_id"))
+	require.NoError(t, tran.Ack(tCtx, nil))
+}
+
+func TestWebsocketServerInputAuthCheck(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	in, purl := startWebsocketServerInput(t, `  auth_check: '@Authorization == "Bearer foo" && @tenant == "acme"'`)
+
+	for _, test := range []struct {
+		name   string
+		path   string
+		header http.Header
+	}{
+		{name: "no header", path: "/ws/acme", header: http.Header{}},
+		{name: "wrong token", path: "/ws/acme", header: http.Header{"Authorization": []string{"Bearer bar"}}},
+		{name: "wrong tenant", path: "/ws/other", header: http.Header{"Authorization": []string{"Bearer foo"}}},
+	} {
+		_, res, err := websocket.DefaultDialer.Dial(purl.String()+test.path, test.header)
+		require.Error(t, err, test.name)
+		require.NotNil(t, res, test.name)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode, test.name)
+	}
+
+	client, _, err := websocket.DefaultDialer.Dial(purl.String()+"/ws/acme", http.Header{
+		"Authorization": []string{"Bearer foo"},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte("hello world")))
+
+	tran := readWebsocketTransaction(t, in)
+	assert.Equal(t, "hello world", string(tran.Payload.Get(0).AsBytes()))
+	require.NoError(t, tran.Ack(tCtx, nil))
+}
+
+func TestWebsocketServerInputShutdown(t *testing.T) {
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+
+	mgr, err := manager.New(manager.ResourceConfig{}, manager.OptSetAPIReg(reg))
+	require.NoError(t, err)
+
+	in, err := mgr.NewInput(parseYAMLInputConf(t, `
+websocket_server:
+  path: /ws
+`))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(reg.mut)
+	defer server.Close()
+
+	purl, err := url.Parse(server.URL + "/ws")
+	require.NoError(t, err)
+	purl.Scheme = "ws"
+
+	client, _, err := websocket.DefaultDialer.Dial(purl.String(), http.Header{})
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Wait for the connection to be established on the server side.
+	require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte("hello world")))
+	tran := readWebsocketTransaction(t, in)
+	require.NoError(t, tran.Ack(context.Background(), nil))
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	in.TriggerStopConsuming()
+	require.NoError(t, in.WaitForClose(ctx))
+
+	_, _, err = client.ReadMessage()
+	require.Error(t, err)
+}
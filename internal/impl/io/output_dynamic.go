@@ -17,8 +17,9 @@ import (
 )
 
 const (
-	doFieldPrefix  = "prefix"
-	doFieldOutputs = "outputs"
+	doFieldPrefix       = "prefix"
+	doFieldOutputs      = "outputs"
+	doFieldDrainTimeout = "drain_timeout"
 )
 
 func dynOutputSpec() *service.ConfigSpec {
@@ -46,6 +47,8 @@ Creates or updates an output with a configuration provided in the request body (
 
 Stops and removes an output.
 
+When an output is removed, or replaced by an update, it first stops receiving new messages and is given up to `+"`drain_timeout`"+` to finish sending the messages it has in flight, after which it is forced to shut down and any messages still pending are failed.
+
 === GET `+"`/outputs/\\{id}/uptime`"+`
 
 Returns the uptime of an output as a duration string (of the form "72h3m0.5s").`).
@@ -56,6 +59,11 @@ Returns the uptime of an output as a duration string (of the form "72h3m0.5s").`
 			service.NewStringField(doFieldPrefix).
 				Description("A path prefix for HTTP endpoints that are registered.").
 				Default(""),
+			service.NewDurationField(doFieldDrainTimeout).
+				Description("The maximum period of time to wait for an output that is removed or replaced to finish sending its pending messages before it is forced to shut down. Set to `0s` in order to shut outputs down immediately.").
+				Version("4.29.0").
+				Advanced().
+				Default("10s"),
 		)
 }
 
@@ -85,6 +93,11 @@ func newDynamicOutputFromParsed(conf *service.ParsedConfig, res *service.Resourc
 		return nil, err
 	}
 
+	drainTimeout, err := conf.FieldDuration(doFieldDrainTimeout)
+	if err != nil {
+		return nil, err
+	}
+
 	outputsAnyMap, err := conf.FieldAnyMap(doFieldOutputs)
 	if err != nil {
 		return nil, err
@@ -132,6 +145,7 @@ func newDynamicOutputFromParsed(conf *service.ParsedConfig, res *service.Resourc
 	if err != nil {
 		return nil, err
 	}
+	fanOut.drainTimeout = drainTimeout
 
 	dynAPI.OnUpdate(func(ctx context.Context, id string, c []byte) error {
 		confNode, err := docs.UnmarshalYAML(c)
@@ -36,6 +36,10 @@ type outputWithTSChan struct {
 type dynamicFanOutOutputBroker struct {
 	log log.Modular
 
+	// drainTimeout is the maximum period of time to wait for a removed
+	// output to send its pending messages before it is forced to shut down.
+	drainTimeout time.Duration
+
 	onAdd    func(label string)
 	onRemove func(label string)
 
@@ -56,6 +60,7 @@ func newDynamicFanOutOutputBroker(
 ) (*dynamicFanOutOutputBroker, error) {
 	d := &dynamicFanOutOutputBroker{
 		log:           logger,
+		drainTimeout:  time.Second * 10,
 		transactions:  nil,
 		newOutputChan: make(chan wrappedOutput),
 		outputs:       make(map[string]outputWithTSChan, len(outputs)),
@@ -136,19 +141,44 @@ func (d *dynamicFanOutOutputBroker) addOutput(ident string, output output.Stream
 	return nil
 }
 
-func (d *dynamicFanOutOutputBroker) removeOutput(ctx context.Context, ident string) error {
+// detachOutput removes an output from the broker so that no further messages
+// are sent to it, and must be called whilst holding outputsMut for writing.
+func (d *dynamicFanOutOutputBroker) detachOutput(ident string) (outputWithTSChan, bool) {
 	ow, exists := d.outputs[ident]
 	if !exists {
-		return nil
+		return ow, false
 	}
 
-	ow.output.TriggerCloseNow()
-	err := ow.output.WaitForClose(ctx)
-
-	ow.done()
+	// Closing the transaction channel allows the output to finish sending any
+	// pending messages before shutting down.
 	close(ow.tsChan)
 	delete(d.outputs, ident)
+	return ow, true
+}
+
+// drainOutput waits for a detached output to send its pending messages, after
+// the drain timeout it is forced to shut down and pending messages are failed.
+// This must not be called whilst holding outputsMut as that would block the
+// delivery of messages to all other outputs.
+func (d *dynamicFanOutOutputBroker) drainOutput(ctx context.Context, ident string, ow outputWithTSChan) error {
+	ctx, done := d.shutSig.HardStopCtx(ctx)
+	defer done()
+
+	var err error
+	if d.drainTimeout > 0 {
+		drainCtx, done := context.WithTimeout(ctx, d.drainTimeout)
+		err = ow.output.WaitForClose(drainCtx)
+		done()
+		if err != nil {
+			d.log.Warn("Dynamic output '%v' failed to drain pending messages within %v, forcing it to shut down.\n", ident, d.drainTimeout)
+		}
+	}
+	if d.drainTimeout <= 0 || err != nil {
+		ow.output.TriggerCloseNow()
+		err = ow.output.WaitForClose(ctx)
+	}
 
+	ow.done()
 	return err
 }
 
@@ -199,17 +229,22 @@ func (d *dynamicFanOutOutputBroker) loop() {
 					return
 				}
 				func() {
+					// First, always remove the previous output if it exists. The
+					// lock is only held whilst detaching the output so that
+					// delivery to other outputs continues whilst it drains.
 					d.outputsMut.Lock()
-					defer d.outputsMut.Unlock()
-
-					// First, always remove the previous output if it exists.
-					if _, exists := d.outputs[wrappedOutput.Name]; exists {
-						if err := d.removeOutput(wrappedOutput.Ctx, wrappedOutput.Name); err != nil {
+					ow, exists := d.detachOutput(wrappedOutput.Name)
+					d.outputsMut.Unlock()
+					if exists {
+						if err := d.drainOutput(wrappedOutput.Ctx, wrappedOutput.Name, ow); err != nil {
 							d.log.Error("Failed to stop old copy of dynamic output '%v' in time: %v, the output will continue to shut down in the background.\n", wrappedOutput.Name, err)
 						}
 						d.onRemove(wrappedOutput.Name)
 					}
 
+					d.outputsMut.Lock()
+					defer d.outputsMut.Unlock()
+
 					// Next, attempt to create a new output (if specified).
 					if wrappedOutput.Output == nil {
 						wrappedOutput.ResChan <- nil
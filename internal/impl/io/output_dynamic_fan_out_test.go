@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/redpanda-data/benthos/v4/internal/component/output"
+	"github.com/redpanda-data/benthos/v4/internal/impl/pure"
 	"github.com/redpanda-data/benthos/v4/internal/log"
 	"github.com/redpanda-data/benthos/v4/internal/manager/mock"
 	"github.com/redpanda-data/benthos/v4/internal/message"
@@ -435,3 +436,176 @@ func TestDynamicFanOutShutDownFromSend(t *testing.T) {
 		t.Error("Timed out waiting for msg rcv")
 	}
 }
+
+func TestDynamicFanOutRemoveDrains(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	for _, test := range []struct {
+		name         string
+		drainTimeout time.Duration
+		ack          bool
+	}{
+		{name: "drained", drainTimeout: time.Second * 5, ack: true},
+		{name: "timed out", drainTimeout: time.Millisecond * 50, ack: false},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			mockOne := &mock.OutputChanneled{}
+			retryOne, err := pure.RetryOutputIndefinitely(mock.NewManager(), mockOne)
+			require.NoError(t, err)
+
+			readChan := make(chan message.Transaction)
+			resChan := make(chan error, 1)
+
+			oTM, err := newDynamicFanOutOutputBroker(map[string]output.Streamed{
+				"foo": retryOne,
+			}, log.Noop(), nil, nil)
+			require.NoError(t, err)
+			oTM.drainTimeout = test.drainTimeout
+			require.NoError(t, oTM.Consume(readChan))
+
+			select {
+			case readChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello world")}), resChan):
+			case <-tCtx.Done():
+				t.Fatal("timed out")
+			}
+
+			var ts message.Transaction
+			select {
+			case ts = <-mockOne.TChan:
+			case <-tCtx.Done():
+				t.Fatal("timed out")
+			}
+
+			removeErr := make(chan error, 1)
+			go func() {
+				removeErr <- oTM.SetOutput(tCtx, "foo", nil)
+			}()
+
+			if test.ack {
+				// The output must not be removed until its pending message
+				// is acknowledged.
+				select {
+				case err := <-removeErr:
+					t.Fatalf("Output removed before it was drained: %v", err)
+				case <-time.After(time.Millisecond * 50):
+				}
+
+				require.NoError(t, ts.Ack(tCtx, nil))
+				select {
+				case res := <-resChan:
+					require.NoError(t, res)
+				case <-tCtx.Done():
+					t.Fatal("timed out")
+				}
+			}
+
+			select {
+			case err := <-removeErr:
+				require.NoError(t, err)
+			case <-tCtx.Done():
+				t.Fatal("timed out")
+			}
+
+			oTM.TriggerCloseNow()
+			require.NoError(t, oTM.WaitForClose(tCtx))
+		})
+	}
+}
+
+func TestDynamicFanOutRemoveDrainDoesNotBlock(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	mockOne := &mock.OutputChanneled{}
+	retryOne, err := pure.RetryOutputIndefinitely(mock.NewManager(), mockOne)
+	require.NoError(t, err)
+
+	mockTwo := &mock.OutputChanneled{}
+
+	readChan := make(chan message.Transaction)
+	resChan := make(chan error, 2)
+
+	oTM, err := newDynamicFanOutOutputBroker(map[string]output.Streamed{
+		"foo": retryOne,
+		"bar": mockTwo,
+	}, log.Noop(), nil, nil)
+	require.NoError(t, err)
+	oTM.drainTimeout = time.Second * 5
+	require.NoError(t, oTM.Consume(readChan))
+
+	select {
+	case readChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("first")}), resChan):
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	// The outputs are sent to in any order.
+	var firstTs message.Transaction
+	for i := 0; i < 2; i++ {
+		select {
+		case firstTs = <-mockOne.TChan:
+		case ts := <-mockTwo.TChan:
+			require.NoError(t, ts.Ack(tCtx, nil))
+		case <-tCtx.Done():
+			t.Fatal("timed out")
+		}
+	}
+
+	removeErr := make(chan error, 1)
+	go func() {
+		removeErr <- oTM.SetOutput(tCtx, "foo", nil)
+	}()
+
+	// Whilst the removed output drains the remaining output continues to
+	// receive messages.
+	assert.Eventually(t, func() bool {
+		oTM.outputsMut.RLock()
+		_, exists := oTM.outputs["foo"]
+		oTM.outputsMut.RUnlock()
+		return !exists
+	}, time.Second*5, time.Millisecond*10)
+
+	select {
+	case readChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("second")}), resChan):
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	select {
+	case ts := <-mockTwo.TChan:
+		assert.Equal(t, "second", string(ts.Payload.Get(0).AsBytes()))
+		require.NoError(t, ts.Ack(tCtx, nil))
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	select {
+	case res := <-resChan:
+		require.NoError(t, res)
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	select {
+	case err := <-removeErr:
+		t.Fatalf("Output removed before it was drained: %v", err)
+	default:
+	}
+
+	require.NoError(t, firstTs.Ack(tCtx, nil))
+	select {
+	case res := <-resChan:
+		require.NoError(t, res)
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	select {
+	case err := <-removeErr:
+		require.NoError(t, err)
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	oTM.TriggerCloseNow()
+	require.NoError(t, oTM.WaitForClose(tCtx))
+}
@@ -9,14 +9,17 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/redpanda-data/benthos/v4/internal/codec"
 	"github.com/redpanda-data/benthos/v4/public/service"
 )
 
 const (
-	fileOutputFieldPath  = "path"
-	fileOutputFieldCodec = "codec"
+	fileOutputFieldPath     = "path"
+	fileOutputFieldCodec    = "codec"
+	fileOutputFieldAtomic   = "atomic"
+	fileOutputFieldBatching = "batching"
 )
 
 func fileOutputSpec() *service.ConfigSpec {
@@ -24,7 +27,20 @@ func fileOutputSpec() *service.ConfigSpec {
 		Stable().
 		Categories("Local").
 		Summary(`Writes messages to files on disk based on a chosen codec.`).
-		Description(`Messages can be written to different files by using xref:configuration:interpolation.adoc#bloblang-queries[interpolation functions] in the path field. However, only one file is ever open at a given time, and therefore when the path changes the previously open file is closed.`).
+		Description(`
+Messages can be written to different files by using xref:configuration:interpolation.adoc#bloblang-queries[interpolation functions] in the path field. However, only one file is ever open at a given time, and therefore when the path changes the previously open file is closed.
+
+== Rotation
+
+When `+"`rotation.enabled`"+` is set to `+"`true`"+` each file is written with the time at which it was opened added to its name, before the extension of the path. For example, with the path `+"`/tmp/data.log`"+` messages are written to files such as `+"`/tmp/data.20240102T150405.000.log`"+`. A new file is opened once the current file reaches `+"`rotation.max_size`"+` or `+"`rotation.max_age`"+`, when the path changes, and when the output is restarted.
+
+Once a file is rotated it is optionally compressed, and the rotated files of the path that exceed `+"`rotation.retain_files`"+` or `+"`rotation.retain_period`"+` are deleted. Only files that match the naming of rotated files of the path are considered for deletion.
+
+== Atomic writes
+
+When `+"`atomic`"+` is set to `+"`true`"+` files are written to a temporary file in the same directory, named after the file prefixed with a dot and suffixed with `+"`.tmp`"+`, which is renamed once the file is complete. This ensures that other processes watching the directory never observe a partially written file.
+
+A file is complete once each message has been written with codecs that do not append, such as `+"`all-bytes`"+`, and once each batch has been written with codecs that append, such as `+"`lines`"+`. A completed file replaces any existing file of the same path, and therefore with codecs that append the path should be unique to each batch, which can be achieved with an interpolation such as `+"`${! timestamp_unix_nano() }`"+`. When rotation is enabled files are instead complete once they are rotated.`).
 		Fields(
 			service.NewInterpolatedStringField(fileOutputFieldPath).
 				Description("The file to write to, if the file does not yet exist it will be created.").
@@ -35,12 +51,38 @@ func fileOutputSpec() *service.ConfigSpec {
 				).
 				Version("3.33.0"),
 			service.NewInternalField(codec.NewWriterDocs(fileOutputFieldCodec)).Version("3.33.0").Default("lines"),
+			fileOutputRotationField(),
+			service.NewBoolField(fileOutputFieldAtomic).
+				Description("Whether to write each file to a temporary file that is renamed once the file is complete, so that the file is never observed partially written.").
+				Version("4.29.0").
+				Advanced().
+				Default(false),
+			service.NewBatchPolicyField(fileOutputFieldBatching).
+				Version("4.29.0"),
+		).
+		Example(
+			"Archive Hourly",
+			"Write messages to a file per hour of at most 1GB, compressing each with gzip once rotated and keeping the files of the last week:",
+			`
+output:
+  file:
+    path: /var/archive/events.jsonl
+    codec: lines
+    rotation:
+      enabled: true
+      max_size: 1GB
+      max_age: 1h
+      compression: gzip
+      retain_period: 168h
+`,
 		)
 }
 
 type fileOutputConfig struct {
-	Path  *service.InterpolatedString
-	Codec string
+	Path     *service.InterpolatedString
+	Codec    string
+	Rotation *fileRotationConfig
+	Atomic   bool
 }
 
 func fileOutputConfigFromParsed(pConf *service.ParsedConfig) (conf fileOutputConfig, err error) {
@@ -50,19 +92,32 @@ func fileOutputConfigFromParsed(pConf *service.ParsedConfig) (conf fileOutputCon
 	if conf.Codec, err = pConf.FieldString(fileOutputFieldCodec); err != nil {
 		return
 	}
+	if enabled, _ := pConf.FieldBool(fileOutputFieldRotation, fileOutputFieldRotationEnabled); enabled {
+		var rConf fileRotationConfig
+		if rConf, err = fileRotationConfigFromParsed(pConf.Namespace(fileOutputFieldRotation)); err != nil {
+			return
+		}
+		conf.Rotation = &rConf
+	}
+	if conf.Atomic, err = pConf.FieldBool(fileOutputFieldAtomic); err != nil {
+		return
+	}
 	return
 }
 
 func init() {
-	err := service.RegisterOutput("file", fileOutputSpec(),
-		func(pConf *service.ParsedConfig, res *service.Resources) (out service.Output, mif int, err error) {
+	err := service.RegisterBatchOutput("file", fileOutputSpec(),
+		func(pConf *service.ParsedConfig, res *service.Resources) (out service.BatchOutput, batchPolicy service.BatchPolicy, mif int, err error) {
 			var conf fileOutputConfig
 			if conf, err = fileOutputConfigFromParsed(pConf); err != nil {
 				return
 			}
+			if batchPolicy, err = pConf.FieldBatchPolicy(fileOutputFieldBatching); err != nil {
+				return
+			}
 
 			mif = 1
-			out, err = newFileWriter(conf.Path, conf.Codec, res)
+			out, err = newFileWriter(conf, res)
 			return
 		})
 	if err != nil {
@@ -79,24 +134,42 @@ type fileWriter struct {
 	path       *service.InterpolatedString
 	suffixFn   codec.SuffixFn
 	appendMode bool
+	atomic     bool
+	rotator    *fileRotator
 
-	handleMut  sync.Mutex
-	handlePath string
-	handle     io.WriteCloser
+	handleMut     sync.Mutex
+	handlePath    string
+	handleSegment string
+	handleOpened  time.Time
+	handleWritten int64
+	handle        io.WriteCloser
 }
 
-func newFileWriter(path *service.InterpolatedString, codecStr string, mgr *service.Resources) (*fileWriter, error) {
-	codec, appendMode, err := codec.GetWriter(codecStr)
+func newFileWriter(conf fileOutputConfig, mgr *service.Resources) (*fileWriter, error) {
+	codec, appendMode, err := codec.GetWriter(conf.Codec)
 	if err != nil {
 		return nil, err
 	}
-	return &fileWriter{
+	w := &fileWriter{
 		suffixFn:   codec,
 		appendMode: appendMode,
-		path:       path,
+		atomic:     conf.Atomic,
+		path:       conf.Path,
 		log:        mgr.Logger(),
 		nm:         mgr,
-	}, nil
+	}
+	if conf.Rotation != nil {
+		if !appendMode {
+			return nil, fmt.Errorf("rotation is not supported with the codec '%v' as it does not append", conf.Codec)
+		}
+		w.rotator = &fileRotator{
+			conf:  *conf.Rotation,
+			fs:    mgr.FS(),
+			log:   mgr.Logger(),
+			nowFn: time.Now,
+		}
+	}
+	return w, nil
 }
 
 //------------------------------------------------------------------------------
@@ -105,46 +178,111 @@ func (w *fileWriter) Connect(ctx context.Context) error {
 	return nil
 }
 
-func (w *fileWriter) writeTo(wtr io.Writer, p *service.Message) error {
-	mBytes, err := p.AsBytes()
-	if err != nil {
-		return err
-	}
-
-	suffix, addSuffix := w.suffixFn(mBytes)
-
+func (w *fileWriter) writeTo(wtr io.Writer, mBytes, suffix []byte) error {
 	if _, err := wtr.Write(mBytes); err != nil {
 		return err
 	}
-	if addSuffix {
+	if len(suffix) > 0 {
 		if _, err := wtr.Write(suffix); err != nil {
 			return err
 		}
 	}
+	w.handleWritten += int64(len(mBytes) + len(suffix))
 	return nil
 }
 
-func (w *fileWriter) Write(ctx context.Context, msg *service.Message) error {
+// atomicTempPath returns the path of the temporary file that is written to in
+// place of a path when writes are atomic.
+func atomicTempPath(path string) string {
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+}
+
+// closeHandle closes the open file, which is then renamed when writes are
+// atomic, and rotated when rotation is enabled.
+func (w *fileWriter) closeHandle() error {
+	err := w.handle.Close()
+	w.handle = nil
+	if err == nil && w.atomic {
+		err = w.nm.FS().Rename(atomicTempPath(w.handleSegment), w.handleSegment)
+	}
+	if err == nil && w.rotator != nil {
+		w.rotator.rotated(w.handlePath, w.handleSegment)
+	}
+	return err
+}
+
+// discardHandle closes the open file without completing it, removing the
+// temporary file when writes are atomic.
+func (w *fileWriter) discardHandle() {
+	_ = w.handle.Close()
+	w.handle = nil
+	if w.atomic {
+		_ = w.nm.FS().Remove(atomicTempPath(w.handleSegment))
+	}
+	if w.rotator != nil {
+		w.rotator.released(w.handleSegment)
+	}
+}
+
+func (w *fileWriter) WriteBatch(ctx context.Context, batch service.MessageBatch) error {
+	w.handleMut.Lock()
+	defer w.handleMut.Unlock()
+
+	// With atomic writes the files of codecs that append are completed with
+	// each batch, unless they are completed by rotation instead.
+	batchAtomic := w.atomic && w.appendMode && w.rotator == nil
+
+	for i, msg := range batch {
+		if err := w.write(msg); err != nil {
+			if batchAtomic {
+				if w.handle != nil {
+					w.discardHandle()
+				}
+				return err
+			}
+			bErr := service.NewBatchError(batch, err)
+			for j := i; j < len(batch); j++ {
+				bErr = bErr.Failed(j, err)
+			}
+			return bErr
+		}
+	}
+
+	if batchAtomic && w.handle != nil {
+		return w.closeHandle()
+	}
+	return nil
+}
+
+func (w *fileWriter) write(msg *service.Message) error {
 	path, err := w.path.TryString(msg)
 	if err != nil {
 		return fmt.Errorf("path interpolation error: %w", err)
 	}
 	path = filepath.Clean(path)
 
-	w.handleMut.Lock()
-	defer w.handleMut.Unlock()
+	mBytes, err := msg.AsBytes()
+	if err != nil {
+		return err
+	}
+	suffix, addSuffix := w.suffixFn(mBytes)
+	if !addSuffix {
+		suffix = nil
+	}
 
 	if w.handle != nil && path == w.handlePath {
-		return w.writeTo(w.handle, msg)
+		if w.rotator == nil || !w.rotator.needsRotation(w.handleOpened, w.handleWritten, len(mBytes)+len(suffix)) {
+			return w.writeTo(w.handle, mBytes, suffix)
+		}
 	}
 	if w.handle != nil {
-		if err := w.handle.Close(); err != nil {
+		if err := w.closeHandle(); err != nil {
 			return err
 		}
 	}
 
 	flag := os.O_CREATE | os.O_RDWR
-	if w.appendMode {
+	if w.appendMode && !w.atomic {
 		flag |= os.O_APPEND
 	} else {
 		flag |= os.O_TRUNC
@@ -154,7 +292,18 @@ func (w *fileWriter) Write(ctx context.Context, msg *service.Message) error {
 		return err
 	}
 
-	file, err := w.nm.FS().OpenFile(path, flag, fs.FileMode(0o666))
+	filePath := path
+	if w.rotator != nil {
+		w.handleOpened = w.rotator.nowFn()
+		filePath = w.rotator.segmentPath(path, w.handleOpened)
+	}
+
+	openPath := filePath
+	if w.atomic {
+		openPath = atomicTempPath(filePath)
+	}
+
+	file, err := w.nm.FS().OpenFile(openPath, flag, fs.FileMode(0o666))
 	if err != nil {
 		return err
 	}
@@ -165,16 +314,20 @@ func (w *fileWriter) Write(ctx context.Context, msg *service.Message) error {
 		return errors.New("failed to open file for writing")
 	}
 
+	w.handle = handle
 	w.handlePath = path
-	if err := w.writeTo(handle, msg); err != nil {
-		_ = handle.Close()
+	w.handleSegment = filePath
+	if w.rotator != nil {
+		w.rotator.opened(filePath)
+	}
+	w.handleWritten = 0
+	if err := w.writeTo(handle, mBytes, suffix); err != nil {
+		w.discardHandle()
 		return err
 	}
 
-	if w.appendMode {
-		w.handle = handle
-	} else {
-		_ = handle.Close()
+	if !w.appendMode {
+		return w.closeHandle()
 	}
 	return nil
 }
@@ -185,8 +338,12 @@ func (w *fileWriter) Close(ctx context.Context) error {
 
 	var err error
 	if w.handle != nil {
-		err = w.handle.Close()
-		w.handle = nil
+		err = w.closeHandle()
+	}
+	if w.rotator != nil {
+		if werr := w.rotator.wait(ctx); err == nil {
+			err = werr
+		}
 	}
 	return err
 }
@@ -0,0 +1,252 @@
+package io
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/klauspost/compress/gzip"
+
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+const (
+	faoFieldPath     = "path"
+	faoFieldFormat   = "format"
+	faoFieldFilePath = "file_path"
+	faoFieldBatching = "batching"
+)
+
+func fileArchiveOutputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Local").
+		Version("4.29.0").
+		Summary(`Writes each batch of messages to an archive file on disk, where each message is a file within the archive.`).
+		Description(`
+Each batch of messages is written to a single archive, and therefore the size of archives is determined by the `+"`batching`"+` policy of the output. The path of each archive is resolved from the first message of its batch, and the path of each file within an archive is resolved from its message, both of which can be derived from messages by using xref:configuration:interpolation.adoc#bloblang-queries[interpolation functions].
+
+Archives are written to a temporary file in the same directory, named after the archive prefixed with a dot and suffixed with `+"`.tmp`"+`, which is renamed once the archive is complete. This ensures that other processes watching the directory never observe a partially written archive. A completed archive replaces any existing file of the same path, and therefore the path should be unique to each batch, which can be achieved with an interpolation such as `+"`${! timestamp_unix_nano() }`"+`.`+service.OutputPerformanceDocs(true, true)).
+		Fields(
+			service.NewInterpolatedStringField(faoFieldPath).
+				Description("The path of each archive, if the directory of the archive does not yet exist it will be created.").
+				Examples(
+					`/var/export/${! timestamp_unix_nano() }.tar.gz`,
+					`/var/export/${! @kafka_topic }/${! timestamp("2006-01-02") }-${! uuid_v4() }.zip`,
+				),
+			service.NewStringAnnotatedEnumField(faoFieldFormat, map[string]string{
+				"tar":    "Write archives as unix standard tape archives.",
+				"tar_gz": "Write archives as unix standard tape archives compressed with gzip.",
+				"zip":    "Write archives as zip files, where each file is compressed with deflate.",
+			}).
+				Description("The format of each archive.").
+				Default("tar_gz"),
+			service.NewInterpolatedStringField(faoFieldFilePath).
+				Description("The path of each message within its archive.").
+				Examples(
+					`${! json("id") }.json`,
+					`${! @kafka_key }-${! @kafka_offset }.txt`,
+				).
+				Default(`${! count("files") }-${! timestamp_unix_nano() }.txt`),
+			service.NewOutputMaxInFlightField().Default(1),
+			service.NewBatchPolicyField(faoFieldBatching),
+		).
+		Example(
+			"Hourly Export",
+			"Export messages to a gzipped tar archive of at most ten thousand messages each hour, where each message is a JSON file named after its ID:",
+			`
+output:
+  file_archive:
+    path: /var/export/events-${! timestamp_unix_nano() }.tar.gz
+    format: tar_gz
+    file_path: ${! json("id") }.json
+    batching:
+      count: 10000
+      period: 1h
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterBatchOutput("file_archive", fileArchiveOutputSpec(),
+		func(pConf *service.ParsedConfig, res *service.Resources) (out service.BatchOutput, batchPolicy service.BatchPolicy, mif int, err error) {
+			if mif, err = pConf.FieldMaxInFlight(); err != nil {
+				return
+			}
+			if batchPolicy, err = pConf.FieldBatchPolicy(faoFieldBatching); err != nil {
+				return
+			}
+			out, err = newFileArchiveWriterFromParsed(pConf, res)
+			return
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type fileArchiveWriter struct {
+	fs *service.FS
+
+	path     *service.InterpolatedString
+	filePath *service.InterpolatedString
+	format   string
+	nowFn    func() time.Time
+}
+
+func newFileArchiveWriterFromParsed(pConf *service.ParsedConfig, res *service.Resources) (w *fileArchiveWriter, err error) {
+	w = &fileArchiveWriter{
+		fs:    res.FS(),
+		nowFn: time.Now,
+	}
+	if w.path, err = pConf.FieldInterpolatedString(faoFieldPath); err != nil {
+		return
+	}
+	if w.format, err = pConf.FieldString(faoFieldFormat); err != nil {
+		return
+	}
+	if w.filePath, err = pConf.FieldInterpolatedString(faoFieldFilePath); err != nil {
+		return
+	}
+	return
+}
+
+func (w *fileArchiveWriter) Connect(ctx context.Context) error {
+	return nil
+}
+
+// archiveWriter writes the files of an archive.
+type archiveWriter interface {
+	WriteFile(name string, modTime time.Time, data []byte) error
+	Close() error
+}
+
+type tarArchiveWriter struct {
+	tw *tar.Writer
+	gw *gzip.Writer
+}
+
+func (t *tarArchiveWriter) WriteFile(name string, modTime time.Time, data []byte) error {
+	if err := t.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o644,
+		Size:     int64(len(data)),
+		ModTime:  modTime,
+	}); err != nil {
+		return err
+	}
+	_, err := t.tw.Write(data)
+	return err
+}
+
+func (t *tarArchiveWriter) Close() error {
+	err := t.tw.Close()
+	if t.gw != nil {
+		if gerr := t.gw.Close(); err == nil {
+			err = gerr
+		}
+	}
+	return err
+}
+
+type zipArchiveWriter struct {
+	zw *zip.Writer
+}
+
+func (z *zipArchiveWriter) WriteFile(name string, modTime time.Time, data []byte) error {
+	fw, err := z.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(data)
+	return err
+}
+
+func (z *zipArchiveWriter) Close() error {
+	return z.zw.Close()
+}
+
+func (w *fileArchiveWriter) newArchiveWriter(dst io.Writer) archiveWriter {
+	switch w.format {
+	case "zip":
+		return &zipArchiveWriter{zw: zip.NewWriter(dst)}
+	case "tar_gz":
+		gw := gzip.NewWriter(dst)
+		return &tarArchiveWriter{tw: tar.NewWriter(gw), gw: gw}
+	}
+	return &tarArchiveWriter{tw: tar.NewWriter(dst)}
+}
+
+func (w *fileArchiveWriter) WriteBatch(ctx context.Context, batch service.MessageBatch) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	path, err := batch.TryInterpolatedString(0, w.path)
+	if err != nil {
+		return fmt.Errorf("path interpolation error: %w", err)
+	}
+	path = filepath.Clean(path)
+
+	if err := w.fs.MkdirAll(filepath.Dir(path), fs.FileMode(0o777)); err != nil {
+		return err
+	}
+
+	tmpPath := atomicTempPath(path)
+	file, err := w.fs.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fs.FileMode(0o666))
+	if err != nil {
+		return err
+	}
+	handle, ok := file.(io.WriteCloser)
+	if !ok {
+		_ = file.Close()
+		return errors.New("failed to open file for writing")
+	}
+
+	if err = w.writeArchive(handle, batch); err != nil {
+		_ = handle.Close()
+	} else {
+		err = handle.Close()
+	}
+	if err != nil {
+		_ = w.fs.Remove(tmpPath)
+		return err
+	}
+	return w.fs.Rename(tmpPath, path)
+}
+
+func (w *fileArchiveWriter) writeArchive(dst io.Writer, batch service.MessageBatch) error {
+	aw := w.newArchiveWriter(dst)
+	modTime := w.nowFn()
+	for i, msg := range batch {
+		name, err := batch.TryInterpolatedString(i, w.filePath)
+		if err != nil {
+			return fmt.Errorf("file_path interpolation error: %w", err)
+		}
+		mBytes, err := msg.AsBytes()
+		if err != nil {
+			return err
+		}
+		if err := aw.WriteFile(name, modTime, mBytes); err != nil {
+			return err
+		}
+	}
+	return aw.Close()
+}
+
+func (w *fileArchiveWriter) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,154 @@
+package io
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+func testFileArchiveWriter(t *testing.T, confStr string) *fileArchiveWriter {
+	t.Helper()
+
+	pConf, err := fileArchiveOutputSpec().ParseYAML(confStr, nil)
+	require.NoError(t, err)
+
+	w, err := newFileArchiveWriterFromParsed(pConf, service.MockResources())
+	require.NoError(t, err)
+	return w
+}
+
+func archiveTestBatch() service.MessageBatch {
+	batch := service.MessageBatch{
+		service.NewMessage([]byte(`{"id":"foo"}`)),
+		service.NewMessage([]byte(`{"id":"bar"}`)),
+	}
+	for _, m := range batch {
+		m.MetaSetMut("name", "export")
+	}
+	return batch
+}
+
+func readTarFiles(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+
+	files := map[string]string{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		b, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = string(b)
+	}
+	return files
+}
+
+func TestFileArchiveOutputTarGz(t *testing.T) {
+	dir := t.TempDir()
+
+	w := testFileArchiveWriter(t, `
+path: `+filepath.Join(dir, `${! @name }.tar.gz`)+`
+format: tar_gz
+file_path: '${! json("id") }.json'
+`)
+
+	require.NoError(t, w.WriteBatch(context.Background(), archiveTestBatch()))
+
+	assert.Equal(t, []string{"export.tar.gz"}, func() (names []string) {
+		for k := range listDir(t, dir) {
+			names = append(names, k)
+		}
+		return
+	}())
+
+	b, err := os.ReadFile(filepath.Join(dir, "export.tar.gz"))
+	require.NoError(t, err)
+
+	gr, err := gzip.NewReader(bytes.NewReader(b))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"foo.json": `{"id":"foo"}`,
+		"bar.json": `{"id":"bar"}`,
+	}, readTarFiles(t, gr))
+}
+
+func TestFileArchiveOutputTar(t *testing.T) {
+	dir := t.TempDir()
+
+	w := testFileArchiveWriter(t, `
+path: `+filepath.Join(dir, "nested", "export.tar")+`
+format: tar
+`)
+
+	require.NoError(t, w.WriteBatch(context.Background(), archiveTestBatch()))
+
+	b, err := os.ReadFile(filepath.Join(dir, "nested", "export.tar"))
+	require.NoError(t, err)
+
+	files := readTarFiles(t, bytes.NewReader(b))
+	assert.Len(t, files, 2)
+	for k := range files {
+		assert.Contains(t, k, ".txt")
+	}
+}
+
+func TestFileArchiveOutputZip(t *testing.T) {
+	dir := t.TempDir()
+
+	w := testFileArchiveWriter(t, `
+path: `+filepath.Join(dir, "export.zip")+`
+format: zip
+file_path: 'docs/${! json("id") }.json'
+`)
+
+	require.NoError(t, w.WriteBatch(context.Background(), archiveTestBatch()))
+
+	zr, err := zip.OpenReader(filepath.Join(dir, "export.zip"))
+	require.NoError(t, err)
+	defer zr.Close()
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		_ = rc.Close()
+		files[f.Name] = string(b)
+	}
+	assert.Equal(t, map[string]string{
+		"docs/foo.json": `{"id":"foo"}`,
+		"docs/bar.json": `{"id":"bar"}`,
+	}, files)
+}
+
+func TestFileArchiveOutputFailureRemovesTemp(t *testing.T) {
+	dir := t.TempDir()
+
+	w := testFileArchiveWriter(t, `
+path: `+filepath.Join(dir, "export.tar")+`
+format: tar
+file_path: '${! json("id") }.json'
+`)
+
+	batch := archiveTestBatch()
+	batch[1].SetBytes([]byte("not json"))
+
+	require.Error(t, w.WriteBatch(context.Background(), batch))
+	assert.Empty(t, listDir(t, dir))
+}
@@ -0,0 +1,388 @@
+package io
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+const (
+	fileOutputFieldRotation                = "rotation"
+	fileOutputFieldRotationEnabled         = "enabled"
+	fileOutputFieldRotationMaxSize         = "max_size"
+	fileOutputFieldRotationMaxAge          = "max_age"
+	fileOutputFieldRotationTimestampFormat = "timestamp_format"
+	fileOutputFieldRotationCompression     = "compression"
+	fileOutputFieldRotationRetainFiles     = "retain_files"
+	fileOutputFieldRotationRetainPeriod    = "retain_period"
+)
+
+func fileOutputRotationField() *service.ConfigField {
+	return service.NewObjectField(fileOutputFieldRotation,
+		service.NewBoolField(fileOutputFieldRotationEnabled).
+			Description("Whether to rotate files.").
+			Default(false),
+		service.NewByteSizeField(fileOutputFieldRotationMaxSize).
+			Description("An optional maximum size of each file, once a write would exceed it the file is rotated.").
+			Examples("100MiB", "1GB").
+			Optional(),
+		service.NewDurationField(fileOutputFieldRotationMaxAge).
+			Description("An optional maximum period of time to write to each file, after which the file is rotated.").
+			Examples("1h", "24h").
+			Optional(),
+		service.NewStringField(fileOutputFieldRotationTimestampFormat).
+			Description("The format of the timestamp added to the name of each file, which follows the https://pkg.go.dev/time#pkg-constants[Go time layout]. The timestamp is the time at which the file was opened, in UTC.").
+			Advanced().
+			Default("20060102T150405.000"),
+		service.NewStringAnnotatedEnumField(fileOutputFieldRotationCompression, map[string]string{
+			"none": "Rotated files are not compressed.",
+			"gzip": "Rotated files are compressed with gzip and given the extension `.gz`.",
+			"zstd": "Rotated files are compressed with zstd and given the extension `.zst`.",
+		}).
+			Description("The compression to apply to each file once it is rotated.").
+			Default("none"),
+		service.NewIntField(fileOutputFieldRotationRetainFiles).
+			Description("The maximum number of rotated files of a path to keep, after which the oldest are deleted. Set to zero in order to keep all files.").
+			Default(0),
+		service.NewDurationField(fileOutputFieldRotationRetainPeriod).
+			Description("An optional period of time after which rotated files of a path are deleted.").
+			Example("168h").
+			Optional(),
+	).
+		Description("Rotate files by size and age, where each file is written with a timestamp added to its name, and rotated files can be compressed and deleted after a retention limit. Rotation requires a codec that appends, such as `lines`.").
+		Version("4.29.0").
+		Advanced()
+}
+
+type fileRotationConfig struct {
+	MaxSize         int64
+	MaxAge          time.Duration
+	TimestampFormat string
+	Compression     string
+	RetainFiles     int
+	RetainPeriod    time.Duration
+}
+
+func fileRotationConfigFromParsed(pConf *service.ParsedConfig) (conf fileRotationConfig, err error) {
+	if pConf.Contains(fileOutputFieldRotationMaxSize) {
+		if conf.MaxSize, err = pConf.FieldByteSize(fileOutputFieldRotationMaxSize); err != nil {
+			return
+		}
+	}
+	if pConf.Contains(fileOutputFieldRotationMaxAge) {
+		if conf.MaxAge, err = pConf.FieldDuration(fileOutputFieldRotationMaxAge); err != nil {
+			return
+		}
+	}
+	if conf.TimestampFormat, err = pConf.FieldString(fileOutputFieldRotationTimestampFormat); err != nil {
+		return
+	}
+	if conf.TimestampFormat == "" {
+		err = errors.New("rotation timestamp_format must not be empty")
+		return
+	}
+	if conf.Compression, err = pConf.FieldString(fileOutputFieldRotationCompression); err != nil {
+		return
+	}
+	if conf.RetainFiles, err = pConf.FieldInt(fileOutputFieldRotationRetainFiles); err != nil {
+		return
+	}
+	if pConf.Contains(fileOutputFieldRotationRetainPeriod) {
+		if conf.RetainPeriod, err = pConf.FieldDuration(fileOutputFieldRotationRetainPeriod); err != nil {
+			return
+		}
+	}
+	return
+}
+
+//------------------------------------------------------------------------------
+
+// fileRotator names the files of a rotated path, and compresses and deletes
+// them once rotated.
+type fileRotator struct {
+	conf  fileRotationConfig
+	fs    *service.FS
+	log   *service.Logger
+	nowFn func() time.Time
+
+	// Rotated files are processed in the background one at a time and in the
+	// order that they were rotated, so that writes are not blocked by
+	// compression and retention does not observe files that are part way
+	// through being compressed. The channel is closed once the most recently
+	// rotated file has been processed.
+	lastRotated chan struct{}
+
+	// Files that have been opened and are yet to be processed, which are not
+	// yet considered rotated files of their path.
+	activeMut sync.Mutex
+	active    map[string]struct{}
+}
+
+// opened marks a newly opened file as active until it is rotated or discarded.
+func (r *fileRotator) opened(segment string) {
+	r.activeMut.Lock()
+	if r.active == nil {
+		r.active = map[string]struct{}{}
+	}
+	r.active[segment] = struct{}{}
+	r.activeMut.Unlock()
+}
+
+// released marks a file as no longer active, either once it has been processed
+// after being rotated or when it is discarded.
+func (r *fileRotator) released(segment string) {
+	r.activeMut.Lock()
+	delete(r.active, segment)
+	r.activeMut.Unlock()
+}
+
+func (r *fileRotator) isActive(path string) bool {
+	r.activeMut.Lock()
+	defer r.activeMut.Unlock()
+
+	if _, exists := r.active[path]; exists {
+		return true
+	}
+	if ext := r.compressionExt(); ext != "" {
+		_, exists := r.active[strings.TrimSuffix(path, ext)]
+		return exists
+	}
+	return false
+}
+
+func (r *fileRotator) compressionExt() string {
+	switch r.conf.Compression {
+	case "gzip":
+		return ".gz"
+	case "zstd":
+		return ".zst"
+	}
+	return ""
+}
+
+// splitPath breaks a path into the part before and after the timestamp of its
+// rotated files.
+func splitPath(path string) (prefix, ext string) {
+	ext = filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + ".", ext
+}
+
+// segmentPath returns a path that does not yet exist for a new file of a
+// rotated path, where the timestamp is added before the extension of the path.
+func (r *fileRotator) segmentPath(path string, opened time.Time) string {
+	prefix, ext := splitPath(path)
+	ts := opened.UTC().Format(r.conf.TimestampFormat)
+	for i := 0; ; i++ {
+		candidate := prefix + ts + ext
+		if i > 0 {
+			candidate = prefix + ts + "-" + strconv.Itoa(i) + ext
+		}
+		if !r.exists(candidate) && (r.compressionExt() == "" || !r.exists(candidate+r.compressionExt())) {
+			return candidate
+		}
+	}
+}
+
+func (r *fileRotator) exists(path string) bool {
+	_, err := r.fs.Stat(path)
+	return err == nil
+}
+
+// needsRotation returns whether a write of n bytes to a file must instead be
+// made to a new file.
+func (r *fileRotator) needsRotation(opened time.Time, written int64, n int) bool {
+	if r.conf.MaxSize > 0 && written > 0 && written+int64(n) > r.conf.MaxSize {
+		return true
+	}
+	if r.conf.MaxAge > 0 && r.nowFn().Sub(opened) >= r.conf.MaxAge {
+		return true
+	}
+	return false
+}
+
+// rotated compresses a file that has been closed and then applies the
+// retention limits to the rotated files of its path. This is done in the
+// background, and wait must be called in order to block until it has finished.
+func (r *fileRotator) rotated(path, segment string) {
+	now := r.nowFn()
+	prev, done := r.lastRotated, make(chan struct{})
+	r.lastRotated = done
+	go func() {
+		defer close(done)
+		if prev != nil {
+			<-prev
+		}
+
+		if err := r.compress(segment); err != nil {
+			r.log.Errorf("Failed to compress rotated file %v: %v", segment, err)
+		}
+		r.released(segment)
+		if err := r.applyRetention(path, now); err != nil {
+			r.log.Errorf("Failed to delete rotated files of %v: %v", path, err)
+		}
+	}()
+}
+
+// wait blocks until all rotated files have been processed, or the context is
+// cancelled.
+func (r *fileRotator) wait(ctx context.Context) error {
+	if r.lastRotated == nil {
+		return nil
+	}
+	select {
+	case <-r.lastRotated:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *fileRotator) compress(segment string) error {
+	ext := r.compressionExt()
+	if ext == "" {
+		return nil
+	}
+
+	src, err := r.fs.Open(segment)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	file, err := r.fs.OpenFile(segment+ext, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fs.FileMode(0o666))
+	if err != nil {
+		return err
+	}
+	dst, ok := file.(io.WriteCloser)
+	if !ok {
+		_ = file.Close()
+		return errors.New("failed to open file for writing")
+	}
+
+	var cw io.WriteCloser
+	if r.conf.Compression == "zstd" {
+		if cw, err = zstd.NewWriter(dst); err != nil {
+			_ = dst.Close()
+			return err
+		}
+	} else {
+		cw = gzip.NewWriter(dst)
+	}
+
+	_, err = io.Copy(cw, src)
+	if cerr := cw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = r.fs.Remove(segment + ext)
+		return err
+	}
+	return r.fs.Remove(segment)
+}
+
+type rotatedFile struct {
+	path    string
+	modTime time.Time
+}
+
+// listRotated returns the rotated files of a path from oldest to newest.
+func (r *fileRotator) listRotated(path string) ([]rotatedFile, error) {
+	prefix, ext := splitPath(path)
+
+	dir, err := r.fs.Open(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+
+	rdf, ok := dir.(fs.ReadDirFile)
+	if !ok {
+		return nil, errors.New("directory listings are not supported by the filesystem")
+	}
+	entries, err := rdf.ReadDir(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []rotatedFile
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		p := filepath.Join(filepath.Dir(path), e.Name())
+		if !strings.HasPrefix(p, prefix) || r.isActive(p) {
+			continue
+		}
+		ts := strings.TrimPrefix(p, prefix)
+		if cext := r.compressionExt(); cext != "" {
+			ts = strings.TrimSuffix(ts, cext)
+		}
+		if !strings.HasSuffix(ts, ext) {
+			continue
+		}
+		ts = strings.TrimSuffix(ts, ext)
+		if i := strings.LastIndexByte(ts, '-'); i > 0 {
+			if _, err := strconv.Atoi(ts[i+1:]); err == nil {
+				ts = ts[:i]
+			}
+		}
+		if _, err := time.Parse(r.conf.TimestampFormat, ts); err != nil {
+			continue
+		}
+
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, rotatedFile{path: p, modTime: info.ModTime()})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].modTime.Equal(files[j].modTime) {
+			return files[i].path < files[j].path
+		}
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	return files, nil
+}
+
+func (r *fileRotator) applyRetention(path string, now time.Time) error {
+	if r.conf.RetainFiles <= 0 && r.conf.RetainPeriod <= 0 {
+		return nil
+	}
+
+	files, err := r.listRotated(path)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i, f := range files {
+		expired := r.conf.RetainPeriod > 0 && now.Sub(f.modTime) > r.conf.RetainPeriod
+		excess := r.conf.RetainFiles > 0 && len(files)-i > r.conf.RetainFiles
+		if !expired && !excess {
+			continue
+		}
+		if err := r.fs.Remove(f.path); err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", f.path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,325 @@
+package io
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+func testFileWriter(t *testing.T, confStr string, now *time.Time) *fileWriter {
+	t.Helper()
+
+	pConf, err := fileOutputSpec().ParseYAML(confStr, nil)
+	require.NoError(t, err)
+
+	conf, err := fileOutputConfigFromParsed(pConf)
+	require.NoError(t, err)
+
+	w, err := newFileWriter(conf, service.MockResources())
+	require.NoError(t, err)
+	if w.rotator != nil {
+		w.rotator.nowFn = func() time.Time { return *now }
+	}
+	return w
+}
+
+func listDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	for _, e := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		require.NoError(t, err)
+		files[e.Name()] = string(b)
+	}
+	return files
+}
+
+func TestFileOutputRotationSizeAndAge(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	w := testFileWriter(t, `
+path: `+filepath.Join(dir, "data.log")+`
+codec: lines
+rotation:
+  enabled: true
+  max_size: 8B
+  max_age: 1m
+`, &now)
+
+	ctx := context.Background()
+	for _, s := range []string{"foo", "bar", "baz"} {
+		require.NoError(t, w.WriteBatch(ctx, service.MessageBatch{service.NewMessage([]byte(s))}))
+	}
+
+	now = now.Add(time.Second * 30)
+	require.NoError(t, w.WriteBatch(ctx, service.MessageBatch{service.NewMessage([]byte("buz"))}))
+
+	now = now.Add(time.Second * 30)
+	require.NoError(t, w.WriteBatch(ctx, service.MessageBatch{service.NewMessage([]byte("qux"))}))
+	require.NoError(t, w.Close(ctx))
+
+	assert.Equal(t, map[string]string{
+		"data.20240102T150405.000.log":   "foo\nbar\n",
+		"data.20240102T150405.000-1.log": "baz\nbuz\n",
+		"data.20240102T150505.000.log":   "qux\n",
+	}, listDir(t, dir))
+}
+
+func TestFileOutputRotationPathChange(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	w := testFileWriter(t, `
+path: `+filepath.Join(dir, `${! metadata("name") }.txt`)+`
+rotation:
+  enabled: true
+  timestamp_format: '2006-01-02'
+`, &now)
+
+	ctx := context.Background()
+	for _, name := range []string{"a", "a", "b", "a"} {
+		msg := service.NewMessage([]byte(name))
+		msg.MetaSetMut("name", name)
+		require.NoError(t, w.WriteBatch(ctx, service.MessageBatch{msg}))
+	}
+	require.NoError(t, w.Close(ctx))
+
+	assert.Equal(t, map[string]string{
+		"a.2024-01-02.txt":   "a\na\n",
+		"b.2024-01-02.txt":   "b\n",
+		"a.2024-01-02-1.txt": "a\n",
+	}, listDir(t, dir))
+}
+
+func TestFileOutputRotationCompressionGzip(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	// A file that does not belong to the output is never deleted
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data.other.log"), []byte("keep"), 0o666))
+
+	w := testFileWriter(t, `
+path: `+filepath.Join(dir, "data.log")+`
+rotation:
+  enabled: true
+  max_age: 1s
+  compression: gzip
+  retain_files: 2
+`, &now)
+
+	ctx := context.Background()
+	for _, s := range []string{"foo", "bar", "baz", "buz"} {
+		require.NoError(t, w.WriteBatch(ctx, service.MessageBatch{service.NewMessage([]byte(s))}))
+		now = now.Add(time.Second)
+	}
+	require.NoError(t, w.rotator.wait(ctx))
+
+	files := listDir(t, dir)
+	names := make([]string, 0, len(files))
+	for k := range files {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{
+		"data.20240102T150406.000.log.gz",
+		"data.20240102T150407.000.log.gz",
+		"data.20240102T150408.000.log",
+		"data.other.log",
+	}, names)
+
+	gr, err := gzip.NewReader(bytes.NewReader([]byte(files["data.20240102T150407.000.log.gz"])))
+	require.NoError(t, err)
+	b, err := io.ReadAll(gr)
+	require.NoError(t, err)
+	assert.Equal(t, "baz\n", string(b))
+
+	require.NoError(t, w.Close(ctx))
+	_, err = os.Stat(filepath.Join(dir, "data.20240102T150408.000.log.gz"))
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "data.20240102T150406.000.log.gz"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestFileOutputRotationCompressionZstd(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	w := testFileWriter(t, `
+path: `+filepath.Join(dir, "data.log")+`
+rotation:
+  enabled: true
+  compression: zstd
+`, &now)
+
+	ctx := context.Background()
+	require.NoError(t, w.WriteBatch(ctx, service.MessageBatch{service.NewMessage([]byte("foo"))}))
+	require.NoError(t, w.Close(ctx))
+
+	files := listDir(t, dir)
+	require.Len(t, files, 1)
+
+	zr, err := zstd.NewReader(bytes.NewReader([]byte(files["data.20240102T150405.000.log.zst"])))
+	require.NoError(t, err)
+	defer zr.Close()
+
+	b, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, "foo\n", string(b))
+}
+
+func TestFileOutputRotationRetainPeriod(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	old := filepath.Join(dir, "data.20200101T000000.000.log")
+	require.NoError(t, os.WriteFile(old, []byte("old"), 0o666))
+	require.NoError(t, os.Chtimes(old, now.Add(-time.Hour*48), now.Add(-time.Hour*48)))
+
+	w := testFileWriter(t, `
+path: `+filepath.Join(dir, "data.log")+`
+rotation:
+  enabled: true
+  retain_period: 24h
+`, &now)
+
+	ctx := context.Background()
+	require.NoError(t, w.WriteBatch(ctx, service.MessageBatch{service.NewMessage([]byte("foo"))}))
+	require.NoError(t, w.Close(ctx))
+
+	files := listDir(t, dir)
+	assert.Len(t, files, 1)
+	assert.NotContains(t, files, "data.20200101T000000.000.log")
+}
+
+func TestFileOutputRotationRequiresAppend(t *testing.T) {
+	pConf, err := fileOutputSpec().ParseYAML(`
+path: /tmp/foo.txt
+codec: all-bytes
+rotation:
+  enabled: true
+  max_size: 1MB
+`, nil)
+	require.NoError(t, err)
+
+	conf, err := fileOutputConfigFromParsed(pConf)
+	require.NoError(t, err)
+
+	_, err = newFileWriter(conf, service.MockResources())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not append")
+}
+
+func TestFileOutputAtomicAllBytes(t *testing.T) {
+	dir := t.TempDir()
+
+	w := testFileWriter(t, `
+path: `+filepath.Join(dir, `${! metadata("name") }.txt`)+`
+codec: all-bytes
+atomic: true
+`, nil)
+
+	ctx := context.Background()
+	for _, name := range []string{"a", "b", "a"} {
+		msg := service.NewMessage([]byte("hello " + name))
+		msg.MetaSetMut("name", name)
+		require.NoError(t, w.WriteBatch(ctx, service.MessageBatch{msg}))
+	}
+	assert.Equal(t, map[string]string{
+		"a.txt": "hello a",
+		"b.txt": "hello b",
+	}, listDir(t, dir))
+	require.NoError(t, w.Close(ctx))
+}
+
+func TestFileOutputAtomicBatches(t *testing.T) {
+	dir := t.TempDir()
+
+	w := testFileWriter(t, `
+path: `+filepath.Join(dir, `${! metadata("name") }.txt`)+`
+codec: lines
+atomic: true
+`, nil)
+
+	ctx := context.Background()
+
+	batch := service.MessageBatch{
+		service.NewMessage([]byte("foo")),
+		service.NewMessage([]byte("bar")),
+	}
+	for _, m := range batch {
+		m.MetaSetMut("name", "a")
+	}
+	require.NoError(t, w.WriteBatch(ctx, batch))
+	assert.Equal(t, map[string]string{
+		"a.txt": "foo\nbar\n",
+	}, listDir(t, dir))
+
+	batch = service.MessageBatch{
+		service.NewMessage([]byte("baz")),
+		service.NewMessage([]byte("buz")),
+		service.NewMessage([]byte("qux")),
+	}
+	batch[0].MetaSetMut("name", "a")
+	batch[1].MetaSetMut("name", "b")
+	batch[2].MetaSetMut("name", "b")
+	require.NoError(t, w.WriteBatch(ctx, batch))
+	assert.Equal(t, map[string]string{
+		"a.txt": "baz\n",
+		"b.txt": "buz\nqux\n",
+	}, listDir(t, dir))
+
+	require.NoError(t, w.Close(ctx))
+}
+
+func TestFileOutputAtomicRotation(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	w := testFileWriter(t, `
+path: `+filepath.Join(dir, "data.log")+`
+atomic: true
+rotation:
+  enabled: true
+  max_size: 8B
+`, &now)
+
+	ctx := context.Background()
+	require.NoError(t, w.WriteBatch(ctx, service.MessageBatch{
+		service.NewMessage([]byte("foo")),
+	}))
+	assert.Equal(t, map[string]string{
+		".data.20240102T150405.000.log.tmp": "foo\n",
+	}, listDir(t, dir))
+
+	require.NoError(t, w.WriteBatch(ctx, service.MessageBatch{
+		service.NewMessage([]byte("bar")),
+		service.NewMessage([]byte("baz")),
+	}))
+	assert.Equal(t, map[string]string{
+		"data.20240102T150405.000.log":        "foo\nbar\n",
+		".data.20240102T150405.000-1.log.tmp": "baz\n",
+	}, listDir(t, dir))
+
+	require.NoError(t, w.Close(ctx))
+	assert.Equal(t, map[string]string{
+		"data.20240102T150405.000.log":   "foo\nbar\n",
+		"data.20240102T150405.000-1.log": "baz\n",
+	}, listDir(t, dir))
+}
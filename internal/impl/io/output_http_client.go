@@ -1,9 +1,15 @@
 package io
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 
+	"github.com/redpanda-data/benthos/v4/internal/component/interop"
+	"github.com/redpanda-data/benthos/v4/internal/component/output"
 	"github.com/redpanda-data/benthos/v4/internal/httpclient"
+	"github.com/redpanda-data/benthos/v4/internal/message"
 	"github.com/redpanda-data/benthos/v4/public/service"
 )
 
@@ -19,19 +25,40 @@ The URL and header values of this type can be dynamically set using function int
 
 The body of the HTTP request is the raw contents of the message payload. If the message has multiple parts (is a batch) the request will be sent according to https://www.w3.org/Protocols/rfc1341/7_2_Multipart.html[RFC1341]. This behavior can be disabled by setting the field ` + "<<batch_as_multipart, `batch_as_multipart`>> to `false`" + `.
 
+== Batch aggregation
+
+The field ` + "`batch_as`" + ` determines how the messages of a batch are sent, which makes it possible to send batches to common bulk APIs without an explicit xref:components:processors/archive.adoc[` + "`archive`" + ` processor]. The formats ` + "`ndjson` and `json_array`" + ` require each message to be a valid JSON document, and a batch containing a message that isn't is rejected. When using these formats the ` + "`Content-Type`" + ` header should be set accordingly, e.g. to ` + "`application/x-ndjson` or `application/json`" + `.
+
 == Propagate responses
 
-It's possible to propagate the response from each HTTP request back to the input source by setting ` + "`propagate_response` to `true`" + `. Only inputs that support xref:guides:sync_responses.adoc[synchronous responses] are able to make use of these propagated responses.` + service.OutputPerformanceDocs(true, true)).
+It's possible to propagate the response from each HTTP request back to the input source by setting ` + "`propagate_response` to `true`" + `. Only inputs that support xref:guides:sync_responses.adoc[synchronous responses] are able to make use of these propagated responses.
+
+== Capture responses
+
+Responses can instead be captured by any stream by setting ` + "`response_inproc`" + ` to an xref:components:inputs/inproc.adoc[` + "`inproc`" + `] ID, to which the response of each request is sent. The response messages contain the metadata of the request messages, along with the metadata of the response, which makes it possible to correlate responses with requests. Requests are only acknowledged once their responses have been acknowledged by the consuming stream, and therefore a slow consumer of responses applies back pressure to the output. A response that is rejected by the consuming stream, or that cannot be delivered before the request is cancelled, is logged and dropped rather than causing the request to be sent again.` + service.OutputPerformanceDocs(true, true)).
 		Field(httpclient.ConfigField("POST", true,
 			service.NewBoolField("batch_as_multipart").
 				Description("Send message batches as a single request using https://www.w3.org/Protocols/rfc1341/7_2_Multipart.html[RFC1341]. If disabled messages in batches will be sent as individual requests.").
 				Advanced().Default(false),
+			service.NewStringAnnotatedEnumField("batch_as", map[string]string{
+				"individual": "Send each message of a batch as an individual request.",
+				"multipart":  "Send each batch as a single request using https://www.w3.org/Protocols/rfc1341/7_2_Multipart.html[RFC1341].",
+				"ndjson":     "Send each batch as a single request with a body of newline delimited JSON documents, where each message is a document.",
+				"json_array": "Send each batch as a single request with a body of a JSON array, where each message is an element.",
+			}).
+				Description("How message batches are sent, which overrides the field `batch_as_multipart` when set.").
+				Advanced().Optional().Version("4.29.0"),
 			service.NewBoolField("propagate_response").
 				Description("Whether responses from the server should be xref:guides:sync_responses.adoc[propagated back] to the input.").
 				Advanced().Default(false),
+			service.NewStringField("response_inproc").
+				Description("An optional xref:components:inputs/inproc.adoc[`inproc`] ID to which the response of each request is sent, allowing another stream to consume them.").
+				Example("http_responses").
+				Advanced().Optional().Version("4.29.0"),
 			service.NewIntField("max_in_flight").
 				Description("The maximum number of parallel message batches to have in flight at any given time.").
 				Default(64),
+			service.NewOutputOrderedAcksField(),
 			service.NewBatchPolicyField("batching"),
 			service.NewObjectListField("multipart",
 				service.NewInterpolatedStringField("content_type").
@@ -75,9 +102,12 @@ type httpClientWriter struct {
 	client *httpclient.Client
 	log    *service.Logger
 
-	logURL           string
-	propResponse     bool
-	batchAsMultipart bool
+	responses    output.Streamed
+	responseChan chan message.Transaction
+
+	logURL       string
+	propResponse bool
+	batchAs      string
 }
 
 func newHTTPClientOutputFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (*httpClientWriter, error) {
@@ -122,13 +152,37 @@ func newHTTPClientOutputFromParsed(conf *service.ParsedConfig, mgr *service.Reso
 		return nil, err
 	}
 
-	return &httpClientWriter{
-		client:           client,
-		log:              mgr.Logger(),
-		logURL:           logURL,
-		propResponse:     propResponse,
-		batchAsMultipart: batchAsMultipart,
-	}, nil
+	batchAs := "individual"
+	if batchAsMultipart {
+		batchAs = "multipart"
+	}
+	if conf.Contains("batch_as") {
+		if batchAs, err = conf.FieldString("batch_as"); err != nil {
+			return nil, err
+		}
+	}
+
+	h := &httpClientWriter{
+		client:       client,
+		log:          mgr.Logger(),
+		logURL:       logURL,
+		propResponse: propResponse,
+		batchAs:      batchAs,
+	}
+
+	if inprocID, _ := conf.FieldString("response_inproc"); inprocID != "" {
+		oConf := output.NewConfig()
+		oConf.Type = "inproc"
+		oConf.Plugin = inprocID
+		if h.responses, err = interop.UnwrapManagement(mgr).NewOutput(oConf); err != nil {
+			return nil, fmt.Errorf("failed to create response_inproc output: %w", err)
+		}
+		h.responseChan = make(chan message.Transaction)
+		if err = h.responses.Consume(h.responseChan); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
 }
 
 func (h *httpClientWriter) Connect(ctx context.Context) error {
@@ -136,43 +190,151 @@ func (h *httpClientWriter) Connect(ctx context.Context) error {
 }
 
 func (h *httpClientWriter) WriteBatch(ctx context.Context, msg service.MessageBatch) error {
-	if len(msg) > 1 && !h.batchAsMultipart {
-		for _, v := range msg {
-			if err := h.WriteBatch(ctx, service.MessageBatch{v}); err != nil {
-				return err
+	switch h.batchAs {
+	case "individual":
+		if len(msg) > 1 {
+			for _, v := range msg {
+				if err := h.WriteBatch(ctx, service.MessageBatch{v}); err != nil {
+					return err
+				}
 			}
+			return nil
 		}
-		return nil
+	case "ndjson", "json_array":
+		aggregated, err := aggregateJSONBatch(msg, h.batchAs == "json_array")
+		if err != nil {
+			return err
+		}
+		msg = service.MessageBatch{aggregated}
 	}
 
 	resultMsg, err := h.client.Send(ctx, msg)
-	if err == nil && h.propResponse {
-		parts := make(service.MessageBatch, len(resultMsg))
-		for i, p := range resultMsg {
-			if i < len(msg) {
-				parts[i] = msg[i]
-			} else {
-				parts[i] = msg[0].Copy()
-			}
-
-			mBytes, err := p.AsBytes()
-			if err != nil {
-				return err
-			}
-			parts[i].SetBytes(mBytes)
-
-			_ = p.MetaWalkMut(func(k string, v any) error {
-				parts[i].MetaSetMut(k, v)
-				return nil
-			})
+	if err != nil {
+		return err
+	}
+	if h.responseChan != nil {
+		// Responses are captured from copies of the request messages so that
+		// propagated responses remain unaffected. The request has already
+		// succeeded at this point, and therefore a failure to capture the
+		// response is logged rather than causing the request to be sent again.
+		parts, err := responseBatch(msg.Copy(), resultMsg)
+		if err == nil {
+			err = h.captureResponse(ctx, parts)
+		}
+		if err != nil {
+			h.log.Errorf("Failed to capture response: %v", err)
+		}
+	}
+	if h.propResponse {
+		parts, err := responseBatch(msg, resultMsg)
+		if err != nil {
+			return err
 		}
 		if err := parts.AddSyncResponse(); err != nil {
 			h.log.Warnf("Unable to propagate response to input: %v", err)
 		}
 	}
-	return err
+	return nil
+}
+
+// aggregateJSONBatch joins the messages of a batch into a single message of
+// either newline delimited JSON documents or a JSON array, where the metadata
+// of the first message is retained.
+func aggregateJSONBatch(msg service.MessageBatch, asArray bool) (*service.Message, error) {
+	var buf bytes.Buffer
+	if asArray {
+		buf.WriteByte('[')
+	}
+	for i, m := range msg {
+		mBytes, err := m.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		if asArray && i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := json.Compact(&buf, mBytes); err != nil {
+			return nil, fmt.Errorf("message %v is not a valid JSON document: %w", i, err)
+		}
+		if !asArray {
+			buf.WriteByte('\n')
+		}
+	}
+	if asArray {
+		buf.WriteByte(']')
+	}
+
+	aggregated := msg[0].Copy()
+	aggregated.SetBytes(buf.Bytes())
+	return aggregated, nil
+}
+
+// responseBatch sets the contents and metadata of a response to the messages
+// of the request that produced it, where responses with more parts than the
+// request are given copies of the first request message.
+func responseBatch(msg, resultMsg service.MessageBatch) (service.MessageBatch, error) {
+	parts := make(service.MessageBatch, len(resultMsg))
+	for i, p := range resultMsg {
+		if i < len(msg) {
+			parts[i] = msg[i]
+		} else {
+			parts[i] = msg[0].Copy()
+		}
+
+		mBytes, err := p.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		parts[i].SetBytes(mBytes)
+
+		_ = p.MetaWalkMut(func(k string, v any) error {
+			parts[i].MetaSetMut(k, v)
+			return nil
+		})
+	}
+	return parts, nil
+}
+
+// captureResponse sends a response to the response_inproc ID and waits for it
+// to be acknowledged.
+func (h *httpClientWriter) captureResponse(ctx context.Context, parts service.MessageBatch) error {
+	payload := make(message.Batch, len(parts))
+	for i, p := range parts {
+		mBytes, err := p.AsBytes()
+		if err != nil {
+			return err
+		}
+		payload[i] = message.NewPart(mBytes)
+		_ = p.MetaWalkMut(func(k string, v any) error {
+			payload[i].MetaSetMut(k, v)
+			return nil
+		})
+	}
+
+	resChan := make(chan error, 1)
+	select {
+	case h.responseChan <- message.NewTransaction(payload, resChan):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-resChan:
+		if err != nil {
+			return fmt.Errorf("response was rejected: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (h *httpClientWriter) Close(ctx context.Context) error {
+	if h.responses != nil {
+		h.responses.TriggerCloseNow()
+		if err := h.responses.WaitForClose(ctx); err != nil {
+			return err
+		}
+	}
 	return h.client.Close(ctx)
 }
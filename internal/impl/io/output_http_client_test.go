@@ -3,6 +3,7 @@ package io
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
@@ -17,6 +18,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/redpanda-data/benthos/v4/internal/component/metrics"
 	"github.com/redpanda-data/benthos/v4/internal/component/output"
 	"github.com/redpanda-data/benthos/v4/internal/component/testutil"
 	"github.com/redpanda-data/benthos/v4/internal/manager/mock"
@@ -164,6 +166,88 @@ http_client:
 	require.NoError(t, h.WaitForClose(ctx))
 }
 
+func TestHTTPClientBatchAsJSON(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		batchAs  string
+		input    []string
+		expected string
+		errCont  string
+	}{
+		{
+			name:     "ndjson",
+			batchAs:  "ndjson",
+			input:    []string{`{"id": 1}`, "{\n\"id\": 2\n}", `"three"`},
+			expected: "{\"id\":1}\n{\"id\":2}\n\"three\"\n",
+		},
+		{
+			name:     "json array",
+			batchAs:  "json_array",
+			input:    []string{`{"id": 1}`, "{\n\"id\": 2\n}", `"three"`},
+			expected: `[{"id":1},{"id":2},"three"]`,
+		},
+		{
+			name:     "json array single message",
+			batchAs:  "json_array",
+			input:    []string{`{"id": 1}`},
+			expected: `[{"id":1}]`,
+		},
+		{
+			name:    "invalid json",
+			batchAs: "ndjson",
+			input:   []string{`{"id": 1}`, `nope`},
+			errCont: "message 1 is not a valid JSON document",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+			defer done()
+
+			resultChan := make(chan string, 1)
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				resBytes, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				resultChan <- string(resBytes)
+			}))
+			defer ts.Close()
+
+			conf := parseYAMLOutputConf(t, `
+http_client:
+  url: %v/testpost
+  batch_as: %v
+  headers:
+    Content-Type: application/x-ndjson
+`, ts.URL, test.batchAs)
+
+			h, err := mock.NewManager().NewOutput(conf)
+			require.NoError(t, err)
+
+			var batch message.Batch
+			for _, in := range test.input {
+				batch = append(batch, message.NewPart([]byte(in)))
+			}
+
+			err = writeBatchToStreamed(ctx, t, batch, h)
+			if test.errCont != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.errCont)
+			} else {
+				require.NoError(t, err)
+				select {
+				case resMsg := <-resultChan:
+					assert.Equal(t, test.expected, resMsg)
+				case <-time.After(time.Second):
+					t.Fatal("Action timed out")
+				}
+			}
+
+			h.TriggerCloseNow()
+			require.NoError(t, h.WaitForClose(ctx))
+		})
+	}
+}
+
 func writeBatchToStreamed(ctx context.Context, t *testing.T, batch message.Batch, out output.Streamed) (err error) {
 	t.Helper()
 
@@ -662,3 +746,193 @@ http_client:
 	h.TriggerCloseNow()
 	require.NoError(t, h.WaitForClose(ctx))
 }
+
+func TestHTTPClientResponseInproc(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		w.Header().Add("fooheader", "foovalue")
+		_, _ = w.Write([]byte("echo: "))
+		_, _ = w.Write(b)
+	}))
+	defer ts.Close()
+
+	conf := parseYAMLOutputConf(t, `
+http_client:
+  url: %v/testpost
+  response_inproc: foo
+  extract_headers:
+    include_prefixes: [ "foo" ]
+`, ts.URL)
+
+	mgr := mock.NewManager()
+	h, err := mgr.NewOutput(conf)
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, h.Consume(tChan))
+
+	respChan, err := mgr.GetPipe("foo")
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		testStr := fmt.Sprintf("test%v", i)
+
+		testMsg := message.QuickBatch([][]byte{[]byte(testStr)})
+		testMsg.Get(0).MetaSetMut("request_id", i)
+
+		resChan := make(chan error, 1)
+		select {
+		case tChan <- message.NewTransaction(testMsg, resChan):
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+
+		var resTran message.Transaction
+		select {
+		case resTran = <-respChan:
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+		require.Equal(t, 1, resTran.Payload.Len())
+		assert.Equal(t, "echo: "+testStr, string(resTran.Payload.Get(0).AsBytes()))
+		assert.Equal(t, "foovalue", resTran.Payload.Get(0).MetaGetStr("fooheader"))
+		reqID, _ := resTran.Payload.Get(0).MetaGetMut("request_id")
+		assert.Equal(t, i, reqID)
+
+		// The request is not acknowledged until the response is
+		select {
+		case <-resChan:
+			t.Fatal("request acknowledged before response")
+		case <-time.After(time.Millisecond * 10):
+		}
+
+		require.NoError(t, resTran.Ack(ctx, nil))
+		select {
+		case err := <-resChan:
+			require.NoError(t, err)
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+
+		// The request message itself is unchanged
+		assert.Equal(t, testStr, string(testMsg.Get(0).AsBytes()))
+	}
+
+	h.TriggerCloseNow()
+	require.NoError(t, h.WaitForClose(ctx))
+
+	_, err = mgr.GetPipe("foo")
+	assert.Error(t, err)
+}
+
+func TestHTTPClientResponseInprocNack(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	var reqCount int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&reqCount, 1)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer ts.Close()
+
+	conf := parseYAMLOutputConf(t, `
+http_client:
+  url: %v/testpost
+  response_inproc: foo
+`, ts.URL)
+
+	mgr := mock.NewManager()
+	h, err := mgr.NewOutput(conf)
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, h.Consume(tChan))
+
+	respChan, err := mgr.GetPipe("foo")
+	require.NoError(t, err)
+
+	resChan := make(chan error, 1)
+	select {
+	case tChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("test")}), resChan):
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	var resTran message.Transaction
+	select {
+	case resTran = <-respChan:
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+	require.NoError(t, resTran.Ack(ctx, errors.New("nope")))
+
+	// The request succeeded and is therefore acknowledged without being sent
+	// again, even though its response was rejected.
+	select {
+	case err := <-resChan:
+		require.NoError(t, err)
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+	assert.Equal(t, int64(1), atomic.LoadInt64(&reqCount))
+
+	h.TriggerCloseNow()
+	require.NoError(t, h.WaitForClose(ctx))
+}
+
+func TestHTTPClientTransport(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	var protos []string
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		protos = append(protos, r.Proto)
+		_, _ = io.Copy(io.Discard, r.Body)
+	}))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	conf := parseYAMLOutputConf(t, `
+http_client:
+  url: %v/testpost
+  tls:
+    enabled: true
+    skip_cert_verify: true
+  transport:
+    max_idle_conns_per_host: 10
+    http2: false
+    dial_timeout: 1s
+    tls_handshake_timeout: 1s
+`, ts.URL)
+
+	mgr := mock.NewManager()
+	stats := metrics.NewLocal()
+	mgr.M = stats
+
+	h, err := mgr.NewOutput(conf)
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, h.Consume(tChan))
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, writeBatchToChan(ctx, t, message.QuickBatch([][]byte{[]byte("test")}), tChan))
+	}
+	assert.Equal(t, []string{"HTTP/1.1", "HTTP/1.1", "HTTP/1.1"}, protos)
+
+	counters := stats.GetCounters()
+	assert.Equal(t, int64(1), counters["http_request_conn_new"])
+	assert.Equal(t, int64(2), counters["http_request_conn_reused"])
+
+	h.TriggerCloseNow()
+	require.NoError(t, h.WaitForClose(ctx))
+}
@@ -1,20 +1,33 @@
 package io
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os/exec"
+	"strconv"
 	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
 
 	"github.com/redpanda-data/benthos/v4/internal/component"
 	"github.com/redpanda-data/benthos/v4/public/service"
 )
 
 const (
-	soFieldName  = "name"
-	soFieldArgs  = "args"
-	soFieldCodec = "codec"
+	soFieldName         = "name"
+	soFieldArgs         = "args"
+	soFieldCodec        = "codec"
+	soFieldWriteTimeout = "write_timeout"
+
+	soFieldRestartPolicy            = "restart_policy"
+	soFieldRestartPolicyMaxRestarts = "max_restarts"
+	soFieldRestartPolicyBackoff     = "backoff"
 )
 
 func subprocOutputSpec() *service.ConfigSpec {
@@ -25,18 +38,49 @@ func subprocOutputSpec() *service.ConfigSpec {
 		Description(`
 Messages are written according to a specified codec. The process is expected to terminate gracefully when stdin is closed.
 
-If the subprocess exits unexpectedly then Benthos will log anything printed to stderr and will log the exit code, and will attempt to execute the command again until success.
+If the subprocess exits unexpectedly then Benthos will log what was printed to stderr, up to the first and last 16KB, and will log the exit code, and will attempt to execute the command again according to the <<restarts, restart policy>>.
+
+The execution environment of the subprocess is the same as the Benthos instance, including environment variables and the current working directory.
+
+== Restarts
+
+When the subprocess exits it is restarted according to the field `+"`restart_policy`"+`. A subprocess that exits after a successful write is restarted immediately, whereas subsequent restarts of a subprocess that exits before a successful write are delayed according to `+"`restart_policy.backoff`"+`. Once `+"`restart_policy.max_restarts`"+` consecutive restarts have been made without a successful write the output stops restarting the subprocess and fails to connect.
 
-The execution environment of the subprocess is the same as the Benthos instance, including environment variables and the current working directory.`).
+== Back Pressure
+
+Writes to the subprocess block for as long as it isn't reading from stdin, which applies back pressure upstream. When `+"`write_timeout`"+` is set a subprocess that blocks a write for longer than the timeout is considered stalled, in which case it is killed and restarted, and the messages that were not written are rejected so that they can be reattempted.`).
 		Fields(
 			service.NewStringField(soFieldName).
 				Description("The command to execute as a subprocess."),
 			service.NewStringListField(soFieldArgs).
 				Description("A list of arguments to provide the command.").
 				Default([]any{}),
-			service.NewStringEnumField(soFieldCodec, "lines").
+			service.NewStringAnnotatedEnumField(soFieldCodec, map[string]string{
+				"lines":                     "Messages are written followed by a newline character.",
+				"ndjson":                    "Messages must be valid JSON documents, which are compacted onto a single line and written followed by a newline character.",
+				"length_prefixed_uint32_be": "Messages are written prefixed by their length as a big endian unsigned 32 bit integer.",
+				"netstring":                 "Messages are written as https://cr.yp.to/proto/netstrings.txt[netstrings].",
+			}).
 				Description("The way in which messages should be written to the subprocess.").
 				Default("lines"),
+			service.NewDurationField(soFieldWriteTimeout).
+				Description("An optional maximum period of time to wait for a write to the subprocess, after which the subprocess is considered stalled and is restarted. When not set writes block indefinitely.").
+				Examples("5s", "1m").
+				Version("4.29.0").
+				Advanced().
+				Optional(),
+			service.NewObjectField(soFieldRestartPolicy,
+				service.NewIntField(soFieldRestartPolicyMaxRestarts).
+					Description("The maximum number of consecutive restarts without a successful write before the output stops restarting the subprocess, where zero means there is no limit.").
+					Default(0),
+				service.NewBackOffPolicyField(soFieldRestartPolicyBackoff, true, &backoff.ExponentialBackOff{
+					InitialInterval: time.Second,
+					MaxInterval:     time.Minute,
+				}).Description("The back off applied between consecutive restarts of a subprocess that exits before a successful write."),
+			).
+				Description("Determines how the subprocess is restarted when it exits.").
+				Version("4.29.0").
+				Advanced(),
 		)
 }
 
@@ -60,27 +104,65 @@ func subprocOutputLinesCodec(w io.Writer, b []byte) error {
 	return err
 }
 
+func subprocOutputNDJSONCodec(w io.Writer, b []byte) error {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return fmt.Errorf("failed to parse message as JSON: %w", err)
+	}
+	_ = buf.WriteByte('\n')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func subprocOutputLengthPrefixedCodec(w io.Writer, b []byte) error {
+	buf := make([]byte, 4, 4+len(b))
+	binary.BigEndian.PutUint32(buf, uint32(len(b)))
+	_, err := w.Write(append(buf, b...))
+	return err
+}
+
+func subprocOutputNetstringCodec(w io.Writer, b []byte) error {
+	buf := append(strconv.AppendInt(nil, int64(len(b)), 10), ':')
+	buf = append(append(buf, b...), ',')
+	_, err := w.Write(buf)
+	return err
+}
+
 type subprocOutputCodec func(io.Writer, []byte) error
 
 func subprocOutputCodecFromStr(codec string) (subprocOutputCodec, error) {
-	// TODO: Flesh this out with more options based on s.conf.Codec.
-	if codec == "lines" {
+	switch codec {
+	case "lines":
 		return subprocOutputLinesCodec, nil
+	case "ndjson":
+		return subprocOutputNDJSONCodec, nil
+	case "length_prefixed_uint32_be":
+		return subprocOutputLengthPrefixedCodec, nil
+	case "netstring":
+		return subprocOutputNetstringCodec, nil
 	}
 	return nil, fmt.Errorf("codec not recognised: %v", codec)
 }
 
 //------------------------------------------------------------------------------
 
+var errSubprocStalled = errors.New("subprocess stalled")
+
 type subprocessWriter struct {
 	log  *service.Logger
 	name string
 	args []string
 
-	codec subprocOutputCodec
+	codec        subprocOutputCodec
+	writeTimeout time.Duration
+	maxRestarts  int
+	backoff      backoff.BackOff
 
-	cmdMut sync.Mutex
-	stdin  io.WriteCloser
+	cmdMut   sync.Mutex
+	stdin    io.WriteCloser
+	kill     func()
+	started  bool
+	restarts int
 }
 
 func newSubprocessWriterFromParsed(conf *service.ParsedConfig, log *service.Logger) (s *subprocessWriter, err error) {
@@ -99,6 +181,18 @@ func newSubprocessWriterFromParsed(conf *service.ParsedConfig, log *service.Logg
 	if s.codec, err = subprocOutputCodecFromStr(codecStr); err != nil {
 		return nil, err
 	}
+
+	if conf.Contains(soFieldWriteTimeout) {
+		if s.writeTimeout, err = conf.FieldDuration(soFieldWriteTimeout); err != nil {
+			return
+		}
+	}
+	if s.maxRestarts, err = conf.FieldInt(soFieldRestartPolicy, soFieldRestartPolicyMaxRestarts); err != nil {
+		return
+	}
+	if s.backoff, err = conf.FieldBackOffPolicy(soFieldRestartPolicy, soFieldRestartPolicyBackoff); err != nil {
+		return
+	}
 	return s, nil
 }
 
@@ -110,23 +204,53 @@ func (s *subprocessWriter) Connect(ctx context.Context) error {
 		return nil
 	}
 
-	cmd := exec.Command(s.name, s.args...)
+	if s.started {
+		if s.maxRestarts > 0 && s.restarts >= s.maxRestarts {
+			return fmt.Errorf("subprocess has been restarted %v times without a successful write", s.restarts)
+		}
+
+		// A subprocess that exited after a successful write is restarted
+		// immediately, otherwise the restart is delayed.
+		if s.restarts > 0 {
+			wait := s.backoff.NextBackOff()
+			if wait == backoff.Stop {
+				return errors.New("maximum elapsed time of subprocess restarts has been met")
+			}
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		s.restarts++
+	}
+
+	procCtx, kill := context.WithCancel(context.Background())
+	cmd := exec.CommandContext(procCtx, s.name, s.args...)
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
+		kill()
+		return err
+	}
+
+	// Stdout is discarded and only the beginning and end of stderr are kept
+	// in order to bound the memory used by a long running subprocess.
+	stderr := &prefixSuffixBuffer{n: subprocessStderrLimit / 2}
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		kill()
 		return err
 	}
+	s.started = true
 
 	go func() {
-		stdout, err := cmd.Output()
-		if len(stdout) > 0 {
-			s.log.Debugf("Process exited with: %s\n", stdout)
-		} else {
-			s.log.Debug("Process exited")
-		}
+		err := cmd.Wait()
+		kill()
+		s.log.Debug("Process exited")
 		if err != nil {
 			if exitErr, ok := err.(*exec.ExitError); ok {
-				if len(exitErr.Stderr) > 0 {
-					s.log.Errorf("Process exited with error: %s\n", exitErr.Stderr)
+				if stderr.Len() > 0 {
+					s.log.Errorf("Process exited with error: %s\n", stderr.Bytes())
 				} else if !exitErr.Success() {
 					s.log.Errorf("Process exited with code %v: %v\n", exitErr.ExitCode(), exitErr.String())
 				}
@@ -135,7 +259,7 @@ func (s *subprocessWriter) Connect(ctx context.Context) error {
 			}
 		}
 		s.cmdMut.Lock()
-		if s.stdin != nil {
+		if s.stdin == stdin {
 			s.stdin.Close()
 			s.stdin = nil
 		}
@@ -143,9 +267,39 @@ func (s *subprocessWriter) Connect(ctx context.Context) error {
 	}()
 
 	s.stdin = stdin
+	s.kill = kill
 	return nil
 }
 
+// write encodes a message to the subprocess, killing the subprocess if the
+// write is blocked for longer than the write timeout.
+func (s *subprocessWriter) write(mBytes []byte) error {
+	if s.writeTimeout <= 0 {
+		return s.codec(s.stdin, mBytes)
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- s.codec(s.stdin, mBytes)
+	}()
+
+	timer := time.NewTimer(s.writeTimeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-timer.C:
+	}
+
+	s.log.Errorf("Subprocess did not read a message within %v, killing it\n", s.writeTimeout)
+	s.kill()
+	_ = s.stdin.Close()
+	<-errChan
+	s.stdin = nil
+	return errSubprocStalled
+}
+
 func (s *subprocessWriter) WriteBatch(ctx context.Context, b service.MessageBatch) error {
 	s.cmdMut.Lock()
 	defer s.cmdMut.Unlock()
@@ -153,13 +307,21 @@ func (s *subprocessWriter) WriteBatch(ctx context.Context, b service.MessageBatc
 		return component.ErrNotConnected
 	}
 
-	return b.WalkWithBatchedErrors(func(i int, m *service.Message) error {
+	err := b.WalkWithBatchedErrors(func(i int, m *service.Message) error {
+		if s.stdin == nil {
+			return component.ErrNotConnected
+		}
 		mBytes, err := m.AsBytes()
 		if err != nil {
 			return err
 		}
-		return s.codec(s.stdin, mBytes)
+		return s.write(mBytes)
 	})
+	if err == nil {
+		s.restarts = 0
+		s.backoff.Reset()
+	}
+	return err
 }
 
 func (s *subprocessWriter) Close(ctx context.Context) error {
@@ -173,3 +335,60 @@ func (s *subprocessWriter) Close(ctx context.Context) error {
 	}
 	return err
 }
+
+//------------------------------------------------------------------------------
+
+// subprocessStderrLimit is the maximum number of bytes of stderr that are kept
+// for logging when a subprocess exits.
+const subprocessStderrLimit = 32 << 10
+
+// prefixSuffixBuffer is an io.Writer that keeps the first and last n bytes
+// written to it, discarding anything in between.
+type prefixSuffixBuffer struct {
+	n       int
+	prefix  []byte
+	suffix  []byte
+	skipped int64
+}
+
+func (b *prefixSuffixBuffer) Write(p []byte) (int, error) {
+	written := len(p)
+	if remaining := b.n - len(b.prefix); remaining > 0 {
+		take := min(remaining, len(p))
+		b.prefix = append(b.prefix, p[:take]...)
+		p = p[take:]
+	}
+	b.suffix = append(b.suffix, p...)
+
+	// Trimming is deferred until the suffix is twice its limit in order to
+	// avoid copying on every write.
+	if len(b.suffix) > 2*b.n {
+		b.trim()
+	}
+	return written, nil
+}
+
+func (b *prefixSuffixBuffer) trim() {
+	if drop := len(b.suffix) - b.n; drop > 0 {
+		b.skipped += int64(drop)
+		b.suffix = append(b.suffix[:0], b.suffix[drop:]...)
+	}
+}
+
+// Len returns the number of bytes retained.
+func (b *prefixSuffixBuffer) Len() int {
+	return len(b.prefix) + len(b.suffix)
+}
+
+// Bytes returns the retained bytes, noting where bytes were discarded.
+func (b *prefixSuffixBuffer) Bytes() []byte {
+	b.trim()
+	if b.skipped == 0 {
+		return append(append([]byte{}, b.prefix...), b.suffix...)
+	}
+	var buf bytes.Buffer
+	buf.Write(b.prefix)
+	fmt.Fprintf(&buf, "\n... omitting %d bytes ...\n", b.skipped)
+	buf.Write(b.suffix)
+	return buf.Bytes()
+}
@@ -0,0 +1,63 @@
+package io
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+func TestSubprocessOutputMaxRestarts(t *testing.T) {
+	pConf, err := subprocOutputSpec().ParseYAML(`
+name: sh
+args: [ "-c", "exit 1" ]
+restart_policy:
+  max_restarts: 2
+  backoff:
+    initial_interval: 1ms
+    max_interval: 1ms
+`, nil)
+	require.NoError(t, err)
+
+	w, err := newSubprocessWriterFromParsed(pConf, service.MockResources().Logger())
+	require.NoError(t, err)
+
+	exited := func() bool {
+		w.cmdMut.Lock()
+		defer w.cmdMut.Unlock()
+		return w.stdin == nil
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, w.Connect(ctx))
+		require.Eventually(t, exited, time.Second*5, time.Millisecond*10)
+	}
+
+	err = w.Connect(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "restarted 2 times")
+
+	require.NoError(t, w.Close(ctx))
+}
+
+func TestSubprocessOutputPrefixSuffixBuffer(t *testing.T) {
+	b := &prefixSuffixBuffer{n: 4}
+
+	_, _ = b.Write([]byte("ab"))
+	assert.Equal(t, "ab", string(b.Bytes()))
+
+	_, _ = b.Write([]byte("cdef"))
+	assert.Equal(t, "abcdef", string(b.Bytes()))
+
+	for i := 0; i < 10; i++ {
+		_, _ = b.Write([]byte("xxxxxxxx"))
+	}
+	_, _ = b.Write([]byte("wxyz"))
+	assert.Equal(t, "abcd\n... omitting 82 bytes ...\nwxyz", string(b.Bytes()))
+	assert.Equal(t, 8, b.Len())
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strings"
 	"testing"
 	"time"
 
@@ -193,3 +194,162 @@ func main() {
 	require.NoError(t, err)
 	assert.Equal(t, "BAZ\n", string(resBytes))
 }
+
+func TestSubprocessOutputCodecs(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	for _, test := range []struct {
+		codec    string
+		input    []string
+		expected string
+	}{
+		{codec: "lines", input: []string{"foo", "bar"}, expected: "foo\nbar\n"},
+		{codec: "ndjson", input: []string{`{ "a": 1 }`, "{\n\"b\":\n2}"}, expected: "{\"a\":1}\n{\"b\":2}\n"},
+		{codec: "length_prefixed_uint32_be", input: []string{"foo", "hello"}, expected: "\x00\x00\x00\x03foo\x00\x00\x00\x05hello"},
+		{codec: "netstring", input: []string{"foo", "hello"}, expected: "3:foo,5:hello,"},
+	} {
+		test := test
+		t.Run(test.codec, func(t *testing.T) {
+			outPath := path.Join(t.TempDir(), "out")
+
+			conf := output.NewConfig()
+			conf.Type = "subprocess"
+			conf.Plugin = map[string]any{
+				"name":  "sh",
+				"args":  []any{"-c", "cat > " + outPath},
+				"codec": test.codec,
+			}
+
+			o, err := mock.NewManager().NewOutput(conf)
+			require.NoError(t, err)
+
+			tranChan := make(chan message.Transaction)
+			require.NoError(t, o.Consume(tranChan))
+
+			for _, in := range test.input {
+				sendMsg(t, in, tranChan)
+			}
+
+			o.TriggerCloseNow()
+			require.NoError(t, o.WaitForClose(ctx))
+
+			assert.Eventually(t, func() bool {
+				resBytes, err := os.ReadFile(outPath)
+				return err == nil && string(resBytes) == test.expected
+			}, time.Second*5, time.Millisecond*50)
+		})
+	}
+}
+
+func TestSubprocessOutputLinearBackOff(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	outPath := path.Join(t.TempDir(), "out")
+
+	conf := output.NewConfig()
+	conf.Type = "subprocess"
+	conf.Plugin = map[string]any{
+		"name": "sh",
+		"args": []any{"-c", "head -n 1 >> " + outPath},
+		"restart_policy": map[string]any{
+			"backoff": map[string]any{
+				"strategy":         "linear",
+				"initial_interval": "1ms",
+				"max_interval":     "2ms",
+			},
+		},
+	}
+
+	o, err := mock.NewManager().NewOutput(conf)
+	require.NoError(t, err)
+
+	tranChan := make(chan message.Transaction)
+	require.NoError(t, o.Consume(tranChan))
+
+	sendMsg(t, "foo", tranChan)
+	assert.Eventually(t, func() bool {
+		sendMsg(t, "bar", tranChan)
+		resBytes, err := os.ReadFile(outPath)
+		return err == nil && strings.Contains(string(resBytes), "bar\n")
+	}, time.Second*5, time.Millisecond*100)
+
+	o.TriggerCloseNow()
+	require.NoError(t, o.WaitForClose(ctx))
+}
+
+func TestSubprocessOutputNDJSONInvalid(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	conf := output.NewConfig()
+	conf.Type = "subprocess"
+	conf.Plugin = map[string]any{
+		"name":  "sh",
+		"args":  []any{"-c", "cat > /dev/null"},
+		"codec": "ndjson",
+	}
+
+	o, err := mock.NewManager().NewOutput(conf)
+	require.NoError(t, err)
+
+	tranChan := make(chan message.Transaction)
+	require.NoError(t, o.Consume(tranChan))
+
+	resChan := make(chan error)
+	select {
+	case tranChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("not json")}), resChan):
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	select {
+	case res := <-resChan:
+		require.Error(t, res)
+		assert.Contains(t, res.Error(), "JSON")
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	o.TriggerCloseNow()
+	require.NoError(t, o.WaitForClose(ctx))
+}
+
+func TestSubprocessOutputWriteTimeout(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	conf := output.NewConfig()
+	conf.Type = "subprocess"
+	conf.Plugin = map[string]any{
+		"name":          "sh",
+		"args":          []any{"-c", "exec sleep 30"},
+		"write_timeout": "100ms",
+	}
+
+	o, err := mock.NewManager().NewOutput(conf)
+	require.NoError(t, err)
+
+	tranChan := make(chan message.Transaction)
+	require.NoError(t, o.Consume(tranChan))
+
+	// The subprocess never reads stdin and therefore a message larger than
+	// the pipe buffer stalls.
+	resChan := make(chan error)
+	select {
+	case tranChan <- message.NewTransaction(message.QuickBatch([][]byte{make([]byte, 1<<20)}), resChan):
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	select {
+	case res := <-resChan:
+		require.Error(t, res)
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	o.TriggerCloseNow()
+	require.NoError(t, o.WaitForClose(ctx))
+}
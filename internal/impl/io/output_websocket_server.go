@@ -0,0 +1,335 @@
+package io
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/shutdown"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	"github.com/redpanda-data/benthos/v4/internal/bundle"
+	"github.com/redpanda-data/benthos/v4/internal/component/interop"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+const (
+	wssoFieldPath         = "path"
+	wssoFieldFilter       = "filter"
+	wssoFieldMessageType  = "message_type"
+	wssoFieldWriteTimeout = "write_timeout"
+)
+
+type wssoConfig struct {
+	Path         string
+	Filter       *bloblang.Executor
+	MessageType  int
+	WriteTimeout time.Duration
+}
+
+func wssoConfigFromParsed(pConf *service.ParsedConfig) (conf wssoConfig, err error) {
+	if conf.Path, err = pConf.FieldString(wssoFieldPath); err != nil {
+		return
+	}
+	if pConf.Contains(wssoFieldFilter) {
+		if conf.Filter, err = pConf.FieldBloblang(wssoFieldFilter); err != nil {
+			return
+		}
+	}
+	var msgType string
+	if msgType, err = pConf.FieldString(wssoFieldMessageType); err != nil {
+		return
+	}
+	switch msgType {
+	case "binary":
+		conf.MessageType = websocket.BinaryMessage
+	case "text":
+		conf.MessageType = websocket.TextMessage
+	default:
+		err = fmt.Errorf("unrecognised message type: %v", msgType)
+		return
+	}
+	if conf.WriteTimeout, err = pConf.FieldDuration(wssoFieldWriteTimeout); err != nil {
+		return
+	}
+	return
+}
+
+func wssoSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Network").
+		Version("4.29.0").
+		Summary(`Accepts websocket connections on an endpoint of the xref:components:http/about.adoc[service-wide HTTP server] and pushes messages to them.`).
+		Description(`
+Each message is written as a frame to every client connected at the time that it is sent, and messages sent whilst no clients are connected are dropped. Messages are considered delivered as soon as they have been written to all connected clients, and clients that fail to receive a message within the `+"`write_timeout`"+` are disconnected. There is no concept of at least once delivery on this output.
+
+== Filtering
+
+Clients subscribe to messages by connecting to the endpoint, and the query and path parameters of the connection request are the subscription parameters of the client. An optional `+"`filter`"+` can be specified, which is a Bloblang query that is executed against each message for each client, with the subscription parameters of the client added to the metadata of the message. Subscription parameters take precedence over message metadata of the same key. The query must return the boolean `+"`true`"+` in order for the message to be sent to the client, and messages where the query fails are not sent.`).
+		Fields(
+			service.NewStringField(wssoFieldPath).
+				Description("The endpoint path to accept websocket connections from, which supports path parameters of the form `/\\{foo}`.").
+				Example("/ws").
+				Example("/ws/{topic}"),
+			service.NewBloblangField(wssoFieldFilter).
+				Description("An optional query that is executed against each message for each client and must return `true` for the message to be sent to the client.").
+				Example(`@topic == this.topic`).
+				Example(`@user_id.or("") == meta("owner")`).
+				Optional(),
+			service.NewStringAnnotatedEnumField(wssoFieldMessageType, map[string]string{
+				"binary": "Messages are written as binary frames.",
+				"text":   "Messages are written as text frames.",
+			}).
+				Description("The type of frame that messages are written as.").
+				Default("binary"),
+			service.NewDurationField(wssoFieldWriteTimeout).
+				Description("The maximum period of time to wait for a message to be written to a client before the client is disconnected.").
+				Default("5s").
+				Advanced(),
+		).
+		Example(
+			"Topic Subscriptions",
+			"This example pushes events to websocket clients that have subscribed to the topic of the event with a query parameter, e.g. `/events?topic=orders`:", `
+output:
+  websocket_server:
+    path: /events
+    message_type: text
+    filter: '@topic.or("") == this.topic'
+`)
+}
+
+func init() {
+	err := service.RegisterBatchOutput(
+		"websocket_server", wssoSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (out service.BatchOutput, pol service.BatchPolicy, mif int, err error) {
+			var wssoConf wssoConfig
+			if wssoConf, err = wssoConfigFromParsed(conf); err != nil {
+				return
+			}
+			mif = 1
+			out, err = newWebsocketServerOutput(wssoConf, mgr)
+			return
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type websocketServerClient struct {
+	conn   *websocket.Conn
+	params map[string]string
+}
+
+// subscriptionParams returns the query and path parameters of a connection
+// request, where only the first value of each query parameter is taken.
+func subscriptionParams(r *http.Request) map[string]string {
+	params := map[string]string{}
+	for k, v := range r.URL.Query() {
+		if len(v) > 0 {
+			params[k] = v[0]
+		}
+	}
+	for k, v := range mux.Vars(r) {
+		params[k] = v
+	}
+	return params
+}
+
+type websocketServerOutput struct {
+	conf wssoConfig
+	log  *service.Logger
+	mgr  bundle.NewManagement
+
+	clientsMut sync.RWMutex
+	clients    map[*websocketServerClient]struct{}
+
+	handlerWG sync.WaitGroup
+	shutSig   *shutdown.Signaller
+}
+
+func newWebsocketServerOutput(conf wssoConfig, res *service.Resources) (*websocketServerOutput, error) {
+	if conf.Path == "" {
+		return nil, errors.New("a path must be specified")
+	}
+
+	w := &websocketServerOutput{
+		conf:    conf,
+		log:     res.Logger(),
+		mgr:     interop.UnwrapManagement(res),
+		clients: map[*websocketServerClient]struct{}{},
+		shutSig: shutdown.NewSignaller(),
+	}
+
+	w.mgr.RegisterEndpoint(conf.Path, "Read messages from Benthos via websockets.", w.wsHandler)
+	return w, nil
+}
+
+func (w *websocketServerOutput) wsHandler(rw http.ResponseWriter, r *http.Request) {
+	if w.shutSig.IsSoftStopSignalled() {
+		http.Error(rw, "Server closing", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.handlerWG.Add(1)
+	defer w.handlerWG.Done()
+
+	upgrader := websocket.Upgrader{}
+
+	ws, err := upgrader.Upgrade(rw, r, nil)
+	if err != nil {
+		w.log.Warnf("Websocket request failed: %v", err)
+		return
+	}
+	defer ws.Close()
+
+	client := &websocketServerClient{
+		conn:   ws,
+		params: subscriptionParams(r),
+	}
+
+	w.clientsMut.Lock()
+	w.clients[client] = struct{}{}
+	w.clientsMut.Unlock()
+	defer func() {
+		w.clientsMut.Lock()
+		delete(w.clients, client)
+		w.clientsMut.Unlock()
+	}()
+
+	// Reads of the connection block until a frame arrives, and therefore are
+	// interrupted on shutdown in order to allow the handler to exit.
+	connDone := make(chan struct{})
+	monitorExited := make(chan struct{})
+	defer func() {
+		close(connDone)
+		<-monitorExited
+	}()
+	go func() {
+		defer close(monitorExited)
+		select {
+		case <-w.shutSig.SoftStopChan():
+			_ = ws.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, ""),
+				time.Now().Add(time.Second),
+			)
+			_ = ws.SetReadDeadline(time.Now())
+		case <-connDone:
+		}
+	}()
+
+	// Frames sent by clients are discarded, but the connection must be read
+	// in order for control frames to be processed and closures detected.
+	for {
+		if _, _, err := ws.NextReader(); err != nil {
+			return
+		}
+	}
+}
+
+func (w *websocketServerOutput) Connect(ctx context.Context) error {
+	return nil
+}
+
+// matches returns whether a message should be sent to a client.
+func (w *websocketServerOutput) matches(client *websocketServerClient, msg *service.Message) bool {
+	if w.conf.Filter == nil {
+		return true
+	}
+
+	msg = msg.Copy()
+	for k, v := range client.params {
+		msg.MetaSetMut(k, v)
+	}
+
+	res, err := msg.BloblangQuery(w.conf.Filter)
+	if err != nil {
+		w.log.Debugf("Websocket filter failed: %v", err)
+		return false
+	}
+	if res == nil {
+		return false
+	}
+	v, err := res.AsStructured()
+	if err != nil {
+		w.log.Debugf("Websocket filter failed: %v", err)
+		return false
+	}
+	b, ok := v.(bool)
+	return ok && b
+}
+
+func (w *websocketServerOutput) writeToClient(client *websocketServerClient, batch service.MessageBatch) error {
+	for _, msg := range batch {
+		if !w.matches(client, msg) {
+			continue
+		}
+		mBytes, err := msg.AsBytes()
+		if err != nil {
+			return err
+		}
+		if err := client.conn.SetWriteDeadline(time.Now().Add(w.conf.WriteTimeout)); err != nil {
+			return err
+		}
+		if err := client.conn.WriteMessage(w.conf.MessageType, mBytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *websocketServerOutput) WriteBatch(ctx context.Context, batch service.MessageBatch) error {
+	w.clientsMut.RLock()
+	clients := make([]*websocketServerClient, 0, len(w.clients))
+	for c := range w.clients {
+		clients = append(clients, c)
+	}
+	w.clientsMut.RUnlock()
+
+	// Clients are written to in parallel so that a slow client only delays
+	// the batch by at most the write timeout.
+	var wg sync.WaitGroup
+	wg.Add(len(clients))
+	for _, c := range clients {
+		go func(c *websocketServerClient) {
+			defer wg.Done()
+			if err := w.writeToClient(c, batch); err != nil {
+				w.log.Debugf("Disconnecting websocket client due to failed write: %v", err)
+				_ = c.conn.Close()
+			}
+		}(c)
+	}
+	wg.Wait()
+	return nil
+}
+
+func (w *websocketServerOutput) Close(ctx context.Context) error {
+	w.shutSig.TriggerSoftStop()
+
+	// In order to prevent new connections once closed the endpoint is
+	// replaced.
+	w.mgr.RegisterEndpoint(w.conf.Path, "Endpoint disabled.", func(rw http.ResponseWriter, r *http.Request) {
+		http.Error(rw, "Service unavailable", http.StatusServiceUnavailable)
+	})
+
+	handlersDone := make(chan struct{})
+	go func() {
+		w.handlerWG.Wait()
+		close(handlersDone)
+	}()
+
+	select {
+	case <-handlersDone:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package io_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/manager"
+	"github.com/redpanda-data/benthos/v4/internal/message"
+)
+
+func startWebsocketServerOutput(t testing.TB, extraConf string) (chan<- message.Transaction, *url.URL) {
+	t.Helper()
+
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+
+	mgr, err := manager.New(manager.ResourceConfig{}, manager.OptSetAPIReg(reg))
+	require.NoError(t, err)
+
+	conf := parseYAMLOutputConf(t, `
+websocket_server:
+  path: /ws/{topic}
+%v`, extraConf)
+
+	out, err := mgr.NewOutput(conf)
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, out.Consume(tChan))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+		defer done()
+		close(tChan)
+		assert.NoError(t, out.WaitForClose(ctx))
+	})
+
+	server := httptest.NewServer(reg.mut)
+	t.Cleanup(server.Close)
+
+	purl, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	purl.Scheme = "ws"
+	return tChan, purl
+}
+
+func sendWebsocketServerOutput(t testing.TB, tChan chan<- message.Transaction, msg message.Batch) {
+	t.Helper()
+
+	resChan := make(chan error, 1)
+	select {
+	case tChan <- message.NewTransaction(msg, resChan):
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out sending message")
+	}
+	select {
+	case err := <-resChan:
+		require.NoError(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for ack")
+	}
+}
+
+type websocketFrame struct {
+	msgType int
+	data    string
+}
+
+// dialWebsocketServerOutput connects a client and sends sync messages until
+// the client has received one, which indicates that it has been registered.
+// Frames received by the client other than sync messages are returned on a
+// channel.
+func dialWebsocketServerOutput(t testing.TB, tChan chan<- message.Transaction, purl *url.URL, path string) <-chan websocketFrame {
+	t.Helper()
+
+	client, _, err := websocket.DefaultDialer.Dial(purl.String()+path, http.Header{})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	syncChan := make(chan struct{}, 1)
+	frameChan := make(chan websocketFrame, 10)
+	go func() {
+		for {
+			msgType, data, err := client.ReadMessage()
+			if err != nil {
+				return
+			}
+			if string(data) == "sync" {
+				select {
+				case syncChan <- struct{}{}:
+				default:
+				}
+				continue
+			}
+			frameChan <- websocketFrame{msgType: msgType, data: string(data)}
+		}
+	}()
+
+	syncMsg := message.QuickBatch([][]byte{[]byte("sync")})
+	syncMsg.Get(0).MetaSetMut("sync", "true")
+	for {
+		sendWebsocketServerOutput(t, tChan, syncMsg)
+		select {
+		case <-syncChan:
+			return frameChan
+		case <-time.After(time.Millisecond * 50):
+		}
+	}
+}
+
+func readWebsocketFrame(t testing.TB, frameChan <-chan websocketFrame) (int, string) {
+	t.Helper()
+
+	select {
+	case f := <-frameChan:
+		return f.msgType, f.data
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for frame")
+	}
+	return 0, ""
+}
+
+func TestWebsocketServerOutputBroadcast(t *testing.T) {
+	tChan, purl := startWebsocketServerOutput(t, `  message_type: text`)
+
+	// Messages sent without clients are dropped.
+	sendWebsocketServerOutput(t, tChan, message.QuickBatch([][]byte{[]byte("dropped")}))
+
+	clientOne := dialWebsocketServerOutput(t, tChan, purl, "/ws/foo")
+	clientTwo := dialWebsocketServerOutput(t, tChan, purl, "/ws/bar")
+
+	sendWebsocketServerOutput(t, tChan, message.QuickBatch([][]byte{
+		[]byte("hello"), []byte("world"),
+	}))
+
+	for _, client := range []<-chan websocketFrame{clientOne, clientTwo} {
+		msgType, data := readWebsocketFrame(t, client)
+		assert.Equal(t, websocket.TextMessage, msgType)
+		assert.Equal(t, "hello", data)
+
+		_, data = readWebsocketFrame(t, client)
+		assert.Equal(t, "world", data)
+	}
+}
+
+func TestWebsocketServerOutputFilter(t *testing.T) {
+	tChan, purl := startWebsocketServerOutput(t, `  filter: '@sync == "true" || (this.topic == @topic && this.tier.or("") == @tier.or(""))'`)
+
+	fooClient := dialWebsocketServerOutput(t, tChan, purl, "/ws/foo")
+	goldClient := dialWebsocketServerOutput(t, tChan, purl, "/ws/foo?tier=gold")
+	barClient := dialWebsocketServerOutput(t, tChan, purl, "/ws/bar")
+
+	sendWebsocketServerOutput(t, tChan, message.QuickBatch([][]byte{
+		[]byte(`{"topic":"foo","id":1}`),
+		[]byte(`{"topic":"bar","id":2}`),
+		[]byte(`not json`),
+		[]byte(`{"topic":"foo","tier":"gold","id":3}`),
+		[]byte(`{"topic":"foo","id":4}`),
+	}))
+
+	msgType, data := readWebsocketFrame(t, fooClient)
+	assert.Equal(t, websocket.BinaryMessage, msgType)
+	assert.Equal(t, `{"topic":"foo","id":1}`, data)
+	_, data = readWebsocketFrame(t, fooClient)
+	assert.Equal(t, `{"topic":"foo","id":4}`, data)
+
+	_, data = readWebsocketFrame(t, goldClient)
+	assert.Equal(t, `{"topic":"foo","tier":"gold","id":3}`, data)
+
+	_, data = readWebsocketFrame(t, barClient)
+	assert.Equal(t, `{"topic":"bar","id":2}`, data)
+}
@@ -0,0 +1,235 @@
+package pure
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	goavro "github.com/linkedin/goavro/v2"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+const (
+	avroEncodingBinary       = "binary"
+	avroEncodingSingleObject = "single_object"
+	avroEncodingOCF          = "ocf"
+)
+
+func avroParamsSpec(spec *bloblang.PluginSpec) *bloblang.PluginSpec {
+	return spec.
+		Param(bloblang.NewStringParam("schema").
+			Description("An Avro schema in JSON format.").
+			Optional()).
+		Param(bloblang.NewStringParam("schema_path").
+			Description("The path of a file containing an Avro schema in JSON format, used when `schema` is not set. Relative paths are resolved from the directory of the mapping.").
+			Optional()).
+		Param(bloblang.NewStringParam("encoding").
+			Description("The encoding of the Avro payload, one of `binary` (a single datum without framing), `single_object` (the single-object encoding with a schema fingerprint header) or `ocf` (an object container file).").
+			Default(avroEncodingBinary))
+}
+
+func init() {
+	if err := bloblang.RegisterMethodV2("parse_avro",
+		avroParamsSpec(bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description("Attempts to parse a string or byte array as Avro and returns the result as a structured value following the standard JSON mapping of Avro. When the `encoding` is `ocf` the schema is read from the container file when not provided and the result is an array of all records within the file.").
+			Version("4.29.0")).
+			ExampleNotTested("", `root = content().parse_avro(schema_path: "./schemas/user.avsc", encoding: "single_object")`).
+			ExampleNotTested("", `root.records = content().parse_avro(encoding: "ocf")`),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			encoding, err := avroEncodingFromParams(args)
+			if err != nil {
+				return nil, err
+			}
+			codec, err := avroCodecFromParams(args, encoding != avroEncodingOCF)
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.BytesMethod(func(data []byte) (any, error) {
+				if encoding == avroEncodingOCF {
+					return avroParseOCF(data)
+				}
+
+				var native any
+				var err error
+				if encoding == avroEncodingSingleObject {
+					native, _, err = codec.NativeFromSingle(data)
+				} else {
+					native, _, err = codec.NativeFromBinary(data)
+				}
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse value as Avro: %w", err)
+				}
+				return avroNativeToStructured(codec, native)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("format_avro",
+		avroParamsSpec(bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description("Serializes a structured value following the standard JSON mapping of Avro into an Avro payload. When the `encoding` is `ocf` the value can be an array, in which case each element is written as a record of the container file.").
+			Version("4.29.0")).
+			ExampleNotTested("", `root = this.format_avro(schema: file("./schemas/user.avsc").string(), encoding: "single_object")`),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			encoding, err := avroEncodingFromParams(args)
+			if err != nil {
+				return nil, err
+			}
+			codec, err := avroCodecFromParams(args, true)
+			if err != nil {
+				return nil, err
+			}
+			return func(v any) (any, error) {
+				if encoding == avroEncodingOCF {
+					return avroFormatOCF(codec, v)
+				}
+
+				native, err := avroStructuredToNative(codec, v)
+				if err != nil {
+					return nil, err
+				}
+				if encoding == avroEncodingSingleObject {
+					return codec.SingleFromNative(nil, native)
+				}
+				return codec.BinaryFromNative(nil, native)
+			}, nil
+		}); err != nil {
+		panic(err)
+	}
+}
+
+func avroEncodingFromParams(args *bloblang.ParsedParams) (string, error) {
+	encoding, err := args.GetString("encoding")
+	if err != nil {
+		return "", err
+	}
+	switch encoding {
+	case avroEncodingBinary, avroEncodingSingleObject, avroEncodingOCF:
+		return encoding, nil
+	}
+	return "", fmt.Errorf("unrecognised encoding: %v", encoding)
+}
+
+func avroCodecFromParams(args *bloblang.ParsedParams, required bool) (*goavro.Codec, error) {
+	schema, err := args.GetOptionalString("schema")
+	if err != nil {
+		return nil, err
+	}
+	schemaPath, err := args.GetOptionalString("schema_path")
+	if err != nil {
+		return nil, err
+	}
+
+	var schemaStr string
+	switch {
+	case schema != nil:
+		schemaStr = *schema
+	case schemaPath != nil:
+		schemaBytes, err := args.ImportFile(*schemaPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema: %w", err)
+		}
+		schemaStr = string(schemaBytes)
+	case required:
+		return nil, errors.New("either a schema or schema_path must be provided")
+	default:
+		return nil, nil
+	}
+
+	codec, err := goavro.NewCodecForStandardJSONFull(schemaStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	return codec, nil
+}
+
+func avroNativeToStructured(codec *goavro.Codec, native any) (any, error) {
+	jBytes, err := codec.TextualFromNative(nil, native)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	decoder := json.NewDecoder(bytes.NewReader(jBytes))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func avroStructuredToNative(codec *goavro.Codec, v any) (any, error) {
+	jBytes, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	native, _, err := codec.NativeFromTextual(jBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert value to Avro: %w", err)
+	}
+	return native, nil
+}
+
+func avroParseOCF(data []byte) (any, error) {
+	ocf, err := goavro.NewOCFReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse value as Avro OCF: %w", err)
+	}
+
+	// The codec of the container file doesn't support the standard JSON
+	// mapping, so a new codec is derived from its schema.
+	codec, err := goavro.NewCodecForStandardJSONFull(ocf.Codec().Schema())
+	if err != nil {
+		return nil, err
+	}
+
+	records := []any{}
+	for ocf.Scan() {
+		native, err := ocf.Read()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse value as Avro OCF: %w", err)
+		}
+		record, err := avroNativeToStructured(codec, native)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	if err := ocf.Err(); err != nil {
+		return nil, fmt.Errorf("failed to parse value as Avro OCF: %w", err)
+	}
+	return records, nil
+}
+
+func avroFormatOCF(codec *goavro.Codec, v any) (any, error) {
+	values, isArray := v.([]any)
+	if !isArray {
+		values = []any{v}
+	}
+
+	natives := make([]any, 0, len(values))
+	for _, value := range values {
+		native, err := avroStructuredToNative(codec, value)
+		if err != nil {
+			return nil, err
+		}
+		natives = append(natives, native)
+	}
+
+	var buf bytes.Buffer
+	ocf, err := goavro.NewOCFWriter(goavro.OCFConfig{
+		W:     &buf,
+		Codec: codec,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := ocf.Append(natives); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
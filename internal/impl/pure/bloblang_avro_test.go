@@ -0,0 +1,114 @@
+package pure
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+const testAvroSchema = `{
+  "type": "record",
+  "name": "User",
+  "namespace": "testing",
+  "fields": [
+    { "name": "name", "type": "string" },
+    { "name": "age", "type": "int" },
+    { "name": "email", "type": ["null", "string"], "default": null }
+  ]
+}`
+
+func TestAvroParseFormat(t *testing.T) {
+	schemaPath := filepath.Join(t.TempDir(), "user.avsc")
+	require.NoError(t, os.WriteFile(schemaPath, []byte(testAvroSchema), 0o644))
+
+	input := map[string]any{
+		"name":  "Ash",
+		"age":   32,
+		"email": "ash@example.com",
+	}
+	exp := map[string]any{
+		"name":  "Ash",
+		"age":   json.Number("32"),
+		"email": "ash@example.com",
+	}
+
+	for _, encoding := range []string{"binary", "single_object", "ocf"} {
+		encoding := encoding
+		t.Run(encoding, func(t *testing.T) {
+			format, err := bloblang.Parse(`root = this.format_avro(schema_path: "` + schemaPath + `", encoding: "` + encoding + `")`)
+			require.NoError(t, err)
+
+			parse, err := bloblang.Parse(`root = this.parse_avro(schema_path: "` + schemaPath + `", encoding: "` + encoding + `")`)
+			require.NoError(t, err)
+
+			encoded, err := format.Query(input)
+			require.NoError(t, err)
+			require.IsType(t, []byte(nil), encoded)
+
+			decoded, err := parse.Query(encoded)
+			require.NoError(t, err)
+			if encoding == "ocf" {
+				assert.Equal(t, []any{exp}, decoded)
+			} else {
+				assert.Equal(t, exp, decoded)
+			}
+		})
+	}
+}
+
+func TestAvroInlineSchema(t *testing.T) {
+	mapping := `root = this.format_avro(schema: """` + testAvroSchema + `""").parse_avro(schema: """` + testAvroSchema + `""")`
+	exec, err := bloblang.Parse(mapping)
+	require.NoError(t, err)
+
+	res, err := exec.Query(map[string]any{"name": "Ash", "age": 32})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"name":  "Ash",
+		"age":   json.Number("32"),
+		"email": nil,
+	}, res)
+
+	_, err = exec.Query(map[string]any{"name": "Ash"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to convert value to Avro")
+}
+
+func TestAvroOCFMultipleRecords(t *testing.T) {
+	format, err := bloblang.Parse(`root = this.format_avro(schema: """` + testAvroSchema + `""", encoding: "ocf")`)
+	require.NoError(t, err)
+
+	encoded, err := format.Query([]any{
+		map[string]any{"name": "Ash", "age": 32},
+		map[string]any{"name": "Blake", "age": 40, "email": "blake@example.com"},
+	})
+	require.NoError(t, err)
+
+	// The schema is read from the container file itself.
+	parse, err := bloblang.Parse(`root = this.parse_avro(encoding: "ocf").map_each(r -> r.name)`)
+	require.NoError(t, err)
+
+	res, err := parse.Query(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"Ash", "Blake"}, res)
+}
+
+func TestAvroBadParams(t *testing.T) {
+	_, err := bloblang.Parse(`root = this.parse_avro()`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "either a schema or schema_path must be provided")
+
+	_, err = bloblang.Parse(`root = this.format_avro(schema: """` + testAvroSchema + `""", encoding: "nope")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unrecognised encoding: nope")
+
+	_, err = bloblang.Parse(`root = this.format_avro(schema: "not a schema")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse schema")
+}
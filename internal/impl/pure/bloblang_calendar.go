@@ -0,0 +1,253 @@
+package pure
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/internal/value"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+func businessCalendarParams(spec *bloblang.PluginSpec) *bloblang.PluginSpec {
+	return spec.
+		Param(bloblang.NewAnyParam("holidays").
+			Description("An array of dates that are not business days, either as strings in the format `2006-01-02` or as timestamps, in which case the date of the timestamp within its own timezone is used. Holiday calendars can be loaded from a file with the `file` function, e.g. `file(\"./holidays.json\").parse_json()`.").
+			Default([]any{})).
+		Param(bloblang.NewAnyParam("weekend").
+			Description("An array of the names of the days of the week that are not business days.").
+			Default([]any{"saturday", "sunday"}))
+}
+
+// businessCalendar determines which dates are business days. Dates are
+// evaluated within the timezone of the timestamp being checked.
+type businessCalendar struct {
+	weekend  [7]bool
+	holidays map[string]struct{}
+
+	// The number of business days within a week, and the sorted dates of the
+	// holidays that fall on a weekday that isn't part of the weekend.
+	businessDaysPerWeek int64
+	weekdayHolidays     []string
+}
+
+func businessCalendarFromParams(args *bloblang.ParsedParams) (*businessCalendar, error) {
+	var cal businessCalendar
+
+	rawWeekend, err := args.Get("weekend")
+	if err != nil {
+		return nil, err
+	}
+	weekend, ok := rawWeekend.([]any)
+	if !ok {
+		return nil, fmt.Errorf("weekend: %w", value.NewTypeError(rawWeekend, value.TArray))
+	}
+	for i, d := range weekend {
+		dStr, ok := d.(string)
+		if !ok {
+			return nil, fmt.Errorf("weekend: index %v: %w", i, value.NewTypeError(d, value.TString))
+		}
+		day, exists := weekdaysByName[strings.ToLower(dStr)]
+		if !exists {
+			return nil, fmt.Errorf("weekend: index %v: unrecognised day of the week %q", i, dStr)
+		}
+		cal.weekend[day] = true
+	}
+	if len(weekend) > 0 && !slices.Contains(cal.weekend[:], false) {
+		return nil, errors.New("weekend must not contain every day of the week")
+	}
+
+	rawHolidays, err := args.Get("holidays")
+	if err != nil {
+		return nil, err
+	}
+	holidays, ok := rawHolidays.([]any)
+	if !ok {
+		return nil, fmt.Errorf("holidays: %w", value.NewTypeError(rawHolidays, value.TArray))
+	}
+	cal.holidays = make(map[string]struct{}, len(holidays))
+	for i, h := range holidays {
+		if hStr, ok := h.(string); ok {
+			if t, err := time.Parse(time.DateOnly, hStr); err == nil {
+				cal.holidays[t.Format(time.DateOnly)] = struct{}{}
+				continue
+			}
+		}
+		t, err := value.IGetTimestamp(h)
+		if err != nil {
+			return nil, fmt.Errorf("holidays: index %v: %w", i, err)
+		}
+		cal.holidays[t.Format(time.DateOnly)] = struct{}{}
+	}
+
+	for _, isWeekend := range cal.weekend {
+		if !isWeekend {
+			cal.businessDaysPerWeek++
+		}
+	}
+	for h := range cal.holidays {
+		t, _ := time.Parse(time.DateOnly, h)
+		if !cal.weekend[t.Weekday()] {
+			cal.weekdayHolidays = append(cal.weekdayHolidays, h)
+		}
+	}
+	slices.Sort(cal.weekdayHolidays)
+	return &cal, nil
+}
+
+var weekdaysByName = func() map[string]time.Weekday {
+	m := map[string]time.Weekday{}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		m[strings.ToLower(d.String())] = d
+	}
+	return m
+}()
+
+func (c *businessCalendar) isBusinessDay(t time.Time) bool {
+	if c.weekend[t.Weekday()] {
+		return false
+	}
+	_, isHoliday := c.holidays[t.Format(time.DateOnly)]
+	return !isHoliday
+}
+
+// holidaysBetween returns the number of weekday holidays after the date of
+// from up to and including the date of to, where to may be before from.
+func (c *businessCalendar) holidaysBetween(from, to time.Time) int64 {
+	fromDate, toDate := from.Format(time.DateOnly), to.Format(time.DateOnly)
+	if toDate < fromDate {
+		// Moving backwards the range is from the date of to up to but
+		// excluding the date of from.
+		lo, _ := slices.BinarySearch(c.weekdayHolidays, toDate)
+		hi, _ := slices.BinarySearch(c.weekdayHolidays, fromDate)
+		return int64(hi - lo)
+	}
+	lo, found := slices.BinarySearch(c.weekdayHolidays, fromDate)
+	if found {
+		lo++
+	}
+	hi, found := slices.BinarySearch(c.weekdayHolidays, toDate)
+	if found {
+		hi++
+	}
+	return int64(hi - lo)
+}
+
+// maxBusinessDays is the largest number of business days that can be added to
+// or subtracted from a timestamp.
+const maxBusinessDays = 1_000_000
+
+// addBusinessDays moves a timestamp forward (or backward when negative) by a
+// number of business days whilst preserving the time of day. Whole weeks are
+// skipped at once and only the remainder is walked a day at a time.
+func (c *businessCalendar) addBusinessDays(t time.Time, days int64) (time.Time, error) {
+	if days > maxBusinessDays || days < -maxBusinessDays {
+		return time.Time{}, fmt.Errorf("days must be within the range -%v to %v, got %v", maxBusinessDays, maxBusinessDays, days)
+	}
+
+	step := 1
+	if days < 0 {
+		step, days = -1, -days
+	}
+	for days > 0 {
+		// Every week contains the same number of business days minus any
+		// holidays, and so whole weeks are skipped whilst at least one
+		// business day would remain.
+		if weeks := (days - 1) / c.businessDaysPerWeek; weeks > 0 {
+			next := t.AddDate(0, 0, step*7*int(weeks))
+			days -= weeks*c.businessDaysPerWeek - c.holidaysBetween(t, next)
+			t = next
+			continue
+		}
+		t = t.AddDate(0, 0, step)
+		if c.isBusinessDay(t) {
+			days--
+		}
+	}
+	if y := t.Year(); y < 0 || y > 9999 {
+		return time.Time{}, fmt.Errorf("resulting timestamp is outside of the range of years 0 to 9999: %v", y)
+	}
+	return t, nil
+}
+
+func init() {
+	isBusinessDaySpec := bloblang.NewPluginSpec().
+		Category(query.MethodCategoryTime).
+		Description("Returns whether the date of a timestamp is a business day, which is any day that is neither a weekend day nor a holiday. The date is evaluated within the timezone of the timestamp, and so the <<ts_tz, `ts_tz`>> method can be used beforehand in order to check the date in a different timezone.").
+		Version("4.29.0")
+	isBusinessDaySpec = businessCalendarParams(isBusinessDaySpec).
+		Example("",
+			`root.business_day = this.created_at.is_business_day(holidays: ["2024-12-25", "2024-12-26"])`,
+			[2]string{
+				`{"created_at":"2024-12-24T10:00:00Z"}`,
+				`{"business_day":true}`,
+			},
+			[2]string{
+				`{"created_at":"2024-12-25T10:00:00Z"}`,
+				`{"business_day":false}`,
+			},
+			[2]string{
+				`{"created_at":"2024-12-28T10:00:00Z"}`,
+				`{"business_day":false}`,
+			},
+		).
+		Example("The days of the weekend can be customised.",
+			`root.business_day = this.created_at.is_business_day(weekend: ["friday", "saturday"])`,
+			[2]string{
+				`{"created_at":"2024-12-29T10:00:00Z"}`,
+				`{"business_day":true}`,
+			},
+		)
+
+	if err := bloblang.RegisterMethodV2("is_business_day", isBusinessDaySpec, func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+		cal, err := businessCalendarFromParams(args)
+		if err != nil {
+			return nil, err
+		}
+		return bloblang.TimestampMethod(func(t time.Time) (any, error) {
+			return cal.isBusinessDay(t), nil
+		}), nil
+	}); err != nil {
+		panic(err)
+	}
+
+	addBusinessDaysSpec := bloblang.NewPluginSpec().
+		Category(query.MethodCategoryTime).
+		Description("Adds a number of business days to a timestamp, skipping any weekend days and holidays, whilst preserving the time of day. A negative number of days moves the timestamp backwards, and zero days returns the timestamp unchanged even when it is not a business day. Dates are evaluated within the timezone of the timestamp.").
+		Version("4.29.0").
+		Param(bloblang.NewInt64Param("days").Description("The number of business days to add, which must be within the range -1000000 to 1000000."))
+	addBusinessDaysSpec = businessCalendarParams(addBusinessDaysSpec).
+		Example("",
+			`root.due_at = this.created_at.add_business_days(days: 3, holidays: ["2024-12-25", "2024-12-26"])`,
+			[2]string{
+				`{"created_at":"2024-12-23T10:00:00Z"}`,
+				`{"due_at":"2024-12-30T10:00:00Z"}`,
+			},
+		).
+		Example("",
+			`root.started_at = this.due_at.add_business_days(-1)`,
+			[2]string{
+				`{"due_at":"2024-03-04T17:30:00+01:00"}`,
+				`{"started_at":"2024-03-01T17:30:00+01:00"}`,
+			},
+		)
+
+	if err := bloblang.RegisterMethodV2("add_business_days", addBusinessDaysSpec, func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+		days, err := args.GetInt64("days")
+		if err != nil {
+			return nil, err
+		}
+		cal, err := businessCalendarFromParams(args)
+		if err != nil {
+			return nil, err
+		}
+		return bloblang.TimestampMethod(func(t time.Time) (any, error) {
+			return cal.addBusinessDays(t, days)
+		}), nil
+	}); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,187 @@
+package pure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+func TestBusinessCalendarMethods(t *testing.T) {
+	tests := []struct {
+		name               string
+		mapping            string
+		input              any
+		output             any
+		parseErrorContains string
+		execErrorContains  string
+	}{
+		{
+			name:    "is_business_day weekday",
+			mapping: `root = this.is_business_day()`,
+			input:   "2024-03-01T23:00:00Z",
+			output:  true,
+		},
+		{
+			name:    "is_business_day weekend",
+			mapping: `root = this.is_business_day()`,
+			input:   "2024-03-02T00:00:00Z",
+			output:  false,
+		},
+		{
+			name:    "is_business_day uses timestamp timezone",
+			mapping: `root = this.is_business_day()`,
+			input:   "2024-03-02T01:00:00+02:00",
+			output:  false,
+		},
+		{
+			name:    "is_business_day converted timezone",
+			mapping: `root = this.ts_tz("UTC").is_business_day()`,
+			input:   "2024-03-02T01:00:00+02:00",
+			output:  true,
+		},
+		{
+			name:    "is_business_day timestamp holiday",
+			mapping: `root = this.is_business_day(holidays: ["2024-03-01T12:00:00Z"])`,
+			input:   "2024-03-01T08:00:00Z",
+			output:  false,
+		},
+		{
+			name:    "is_business_day no weekend",
+			mapping: `root = this.is_business_day(weekend: [])`,
+			input:   "2024-03-02T00:00:00Z",
+			output:  true,
+		},
+		{
+			name:    "add_business_days over weekend",
+			mapping: `root = this.add_business_days(1).string()`,
+			input:   "2024-03-01T09:30:00Z",
+			output:  "2024-03-04T09:30:00Z",
+		},
+		{
+			name:    "add_business_days from weekend",
+			mapping: `root = this.add_business_days(1).string()`,
+			input:   "2024-03-02T09:30:00Z",
+			output:  "2024-03-04T09:30:00Z",
+		},
+		{
+			name:    "add_business_days zero",
+			mapping: `root = this.add_business_days(0).string()`,
+			input:   "2024-03-02T09:30:00Z",
+			output:  "2024-03-02T09:30:00Z",
+		},
+		{
+			name:    "add_business_days backwards with holidays",
+			mapping: `root = this.add_business_days(-2, ["2024-03-01"]).string()`,
+			input:   "2024-03-04T09:30:00Z",
+			output:  "2024-02-28T09:30:00Z",
+		},
+		{
+			name:    "add_business_days dynamic calendar",
+			mapping: `root = this.ts.add_business_days(days: 5, holidays: this.holidays, weekend: this.weekend).string()`,
+			input: map[string]any{
+				"ts":       "2024-03-07T00:00:00Z",
+				"holidays": []any{"2024-03-10"},
+				"weekend":  []any{"Friday", "Saturday"},
+			},
+			output: "2024-03-17T00:00:00Z",
+		},
+		{
+			name:    "add_business_days many weeks with holidays",
+			mapping: `root = this.add_business_days(days: 12, holidays: ["2024-03-05", "2024-03-09", "2024-03-14"]).string()`,
+			input:   "2024-03-01T09:30:00Z",
+			output:  "2024-03-21T09:30:00Z",
+		},
+		{
+			name:              "add_business_days too many days",
+			mapping:           `root = this.add_business_days(1000000000)`,
+			input:             "2024-03-01T09:30:00Z",
+			execErrorContains: "days must be within the range",
+		},
+		{
+			name:              "add_business_days out of range",
+			mapping:           `root = this.add_business_days(1000000)`,
+			input:             "9000-03-01T09:30:00Z",
+			execErrorContains: "outside of the range of years",
+		},
+		{
+			name:               "bad weekend day",
+			mapping:            `root = this.is_business_day(weekend: ["caturday"])`,
+			parseErrorContains: `weekend: index 0: unrecognised day of the week "caturday"`,
+		},
+		{
+			name:               "every day is weekend",
+			mapping:            `root = this.is_business_day(weekend: ["monday","tuesday","wednesday","thursday","friday","saturday","sunday"])`,
+			parseErrorContains: "weekend must not contain every day of the week",
+		},
+		{
+			name:               "bad holiday",
+			mapping:            `root = this.is_business_day(holidays: ["nope"])`,
+			parseErrorContains: "holidays: index 0:",
+		},
+		{
+			name:              "bad holidays type",
+			mapping:           `root = this.ts.is_business_day(holidays: this.holidays)`,
+			input:             map[string]any{"ts": "2024-03-07T00:00:00Z", "holidays": "2024-03-10"},
+			execErrorContains: "holidays: expected array value, got string",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			m, err := bloblang.Parse(test.mapping)
+			if test.parseErrorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.parseErrorContains)
+			} else {
+				require.NoError(t, err)
+				v, err := m.Query(test.input)
+				if test.execErrorContains != "" {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), test.execErrorContains)
+				} else {
+					require.NoError(t, err)
+					assert.Equal(t, test.output, v)
+				}
+			}
+		})
+	}
+}
+
+func TestBusinessCalendarAddDaysMatchesWalk(t *testing.T) {
+	cal := &businessCalendar{holidays: map[string]struct{}{}}
+	cal.weekend[time.Friday] = true
+	cal.weekend[time.Sunday] = true
+	cal.businessDaysPerWeek = 5
+	for _, h := range []string{"2024-01-01", "2024-01-05", "2024-02-14", "2024-02-15", "2024-06-30", "2024-12-25"} {
+		cal.holidays[h] = struct{}{}
+		if d, _ := time.Parse(time.DateOnly, h); !cal.weekend[d.Weekday()] {
+			cal.weekdayHolidays = append(cal.weekdayHolidays, h)
+		}
+	}
+
+	walk := func(ts time.Time, days int64) time.Time {
+		step := 1
+		if days < 0 {
+			step, days = -1, -days
+		}
+		for days > 0 {
+			ts = ts.AddDate(0, 0, step)
+			if cal.isBusinessDay(ts) {
+				days--
+			}
+		}
+		return ts
+	}
+
+	start := time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC)
+	for days := int64(-300); days <= 300; days++ {
+		res, err := cal.addBusinessDays(start, days)
+		require.NoError(t, err)
+		assert.Equal(t, walk(start, days), res, "days: %v", days)
+	}
+}
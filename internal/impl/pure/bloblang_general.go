@@ -1,6 +1,7 @@
 package pure
 
 import (
+	"encoding/json"
 	"fmt"
 	"sync"
 
@@ -9,6 +10,47 @@ import (
 	"github.com/redpanda-data/benthos/v4/public/bloblang"
 )
 
+// counterState holds the counts of each instantiation of counter within a
+// mapping, in the order that they were instantiated, so that they can be
+// persisted and restored.
+type counterState struct {
+	mut    sync.Mutex
+	counts []*int64
+	added  int
+}
+
+func (c *counterState) add() int {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.added == len(c.counts) {
+		c.counts = append(c.counts, nil)
+	}
+	c.added++
+	return c.added - 1
+}
+
+func (c *counterState) MarshalState() ([]byte, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return json.Marshal(c.counts)
+}
+
+func (c *counterState) UnmarshalState(b []byte) error {
+	var counts []*int64
+	if err := json.Unmarshal(b, &counts); err != nil {
+		return err
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	for len(counts) < c.added {
+		counts = append(counts, nil)
+	}
+	c.counts = counts
+	return nil
+}
+
 func init() {
 	maxUint := ^uint64(0)
 	maxInt := maxUint >> 1
@@ -17,7 +59,10 @@ func init() {
 		bloblang.NewPluginSpec().
 			Category(query.FunctionCategoryGeneral).
 			Experimental().
-			Description("Returns a non-negative integer that increments each time it is resolved, yielding the minimum (`1` by default) as the first value. Each instantiation of `counter` has its own independent count. Once the maximum integer (or `max` argument) is reached the counter resets back to the minimum.").
+			Description("Returns a non-negative integer that increments each time it is resolved, yielding the minimum (`1` by default) as the first value. Each instantiation of `counter` has its own independent count. The counts are persisted across restarts when used within a `generate` input with a `state_cache`. Once the maximum integer (or `max` argument) is reached the counter resets back to the minimum.").
+			State(func() (any, error) {
+				return &counterState{}, nil
+			}, nil).
 			Param(bloblang.NewQueryParam("min", true).
 				Default(1).
 				Description("The minimum value of the counter, this is the first value that will be yielded. If this parameter is dynamic it will be resolved only once during the lifetime of the mapping.")).
@@ -99,8 +144,15 @@ root.woof_id = null.apply("foos")
 				return nil, err
 			}
 
+			state, err := args.State()
+			if err != nil {
+				return nil, err
+			}
+			counters := state.(*counterState)
+			index := counters.add()
+
 			var min, max int64
-			var i *int64
+			var resolved bool
 
 			var mut sync.Mutex
 
@@ -108,7 +160,7 @@ root.woof_id = null.apply("foos")
 				mut.Lock()
 				defer mut.Unlock()
 
-				if i == nil {
+				if !resolved {
 					var err error
 					if min, err = ctx.ExecToInt64(minFunc); err != nil {
 						return nil, fmt.Errorf("failed to resolve min argument: %w", err)
@@ -123,15 +175,31 @@ root.woof_id = null.apply("foos")
 						return nil, fmt.Errorf("max argument must be >0 and >min, got %v", max)
 					}
 
-					iV := min - 1
-					i = &iV
+					resolved = true
 				}
 
+				var setV any
 				if setFunc != nil {
-					setV, err := ctx.Exec(setFunc)
-					if err != nil {
+					var err error
+					if setV, err = ctx.Exec(setFunc); err != nil {
 						return nil, fmt.Errorf("failed to resolve set argument: %w", err)
 					}
+				}
+
+				// The counts are shared with the other instantiations of counter
+				// within the mapping, and are therefore only locked once the
+				// arguments are resolved.
+				counters.mut.Lock()
+				defer counters.mut.Unlock()
+
+				i := counters.counts[index]
+				if i == nil {
+					iV := min - 1
+					i = &iV
+					counters.counts[index] = i
+				}
+
+				if setFunc != nil {
 					if setV == nil {
 						return *i, nil
 					}
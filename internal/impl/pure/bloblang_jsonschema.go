@@ -0,0 +1,110 @@
+package pure
+
+import (
+	"fmt"
+	"strings"
+
+	jsonschema "github.com/xeipuuv/gojsonschema"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+func init() {
+	if err := bloblang.RegisterMethodV2("validate_json_schema",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Description("Validates a value against a https://json-schema.org/[JSON Schema^] and returns an array of validation errors, which is empty when the value is valid. Each error is an object containing the `path` of the invalid field in dot notation (empty for the root of the value), the schema `keyword` that failed and a human readable `message`. Unlike the `json_schema` processor a value that fails validation does not result in an error, which allows mappings to branch on the result.").
+			Version("4.29.0").
+			Param(bloblang.NewAnyParam("schema").Description("The schema to validate against, either as a JSON string or a structured value.")).
+			Example("", `let schema = """{"type":"object","properties":{"age":{"type":"integer","minimum":0}},"required":["name"]}"""
+root.errors = this.validate_json_schema($schema)
+root.valid = root.errors.length() == 0`,
+				[2]string{
+					`{"name":"foo","age":10}`,
+					`{"errors":[],"valid":true}`,
+				},
+				[2]string{
+					`{"age":-1}`,
+					`{"errors":[{"keyword":"required","message":"name is required","path":""},{"keyword":"minimum","message":"Must be greater than or equal to 0","path":"age"}],"valid":false}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			rawSchema, err := args.Get("schema")
+			if err != nil {
+				return nil, err
+			}
+			var loader jsonschema.JSONLoader
+			switch t := rawSchema.(type) {
+			case string:
+				loader = jsonschema.NewStringLoader(t)
+			case []byte:
+				loader = jsonschema.NewBytesLoader(t)
+			default:
+				loader = jsonschema.NewGoLoader(t)
+			}
+			schema, err := jsonschema.NewSchema(loader)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compile schema: %w", err)
+			}
+			return func(v any) (any, error) {
+				result, err := schema.Validate(jsonschema.NewGoLoader(v))
+				if err != nil {
+					return nil, err
+				}
+				errs := make([]any, 0, len(result.Errors()))
+				for _, e := range result.Errors() {
+					errs = append(errs, map[string]any{
+						"path":    jsonSchemaErrPath(e),
+						"keyword": jsonSchemaErrKeyword(e),
+						"message": e.Description(),
+					})
+				}
+				return errs, nil
+			}, nil
+		}); err != nil {
+		panic(err)
+	}
+}
+
+func jsonSchemaErrPath(e jsonschema.ResultError) string {
+	path := e.Context().String()
+	path = strings.TrimPrefix(path, jsonschema.STRING_CONTEXT_ROOT)
+	return strings.TrimPrefix(path, ".")
+}
+
+// jsonSchemaKeywords maps the error types of the validator to the keywords of
+// the schema that they originate from.
+var jsonSchemaKeywords = map[string]string{
+	"invalid_type":                    "type",
+	"number_any_of":                   "anyOf",
+	"number_one_of":                   "oneOf",
+	"number_all_of":                   "allOf",
+	"number_not":                      "not",
+	"missing_dependency":              "dependencies",
+	"array_no_additional_items":       "additionalItems",
+	"array_min_items":                 "minItems",
+	"array_max_items":                 "maxItems",
+	"unique":                          "uniqueItems",
+	"array_min_properties":            "minProperties",
+	"array_max_properties":            "maxProperties",
+	"additional_property_not_allowed": "additionalProperties",
+	"invalid_property_pattern":        "patternProperties",
+	"invalid_property_name":           "propertyNames",
+	"string_gte":                      "minLength",
+	"string_lte":                      "maxLength",
+	"multiple_of":                     "multipleOf",
+	"number_gte":                      "minimum",
+	"number_gt":                       "exclusiveMinimum",
+	"number_lte":                      "maximum",
+	"number_lt":                       "exclusiveMaximum",
+	"condition_then":                  "then",
+	"condition_else":                  "else",
+}
+
+func jsonSchemaErrKeyword(e jsonschema.ResultError) string {
+	if k, exists := jsonSchemaKeywords[e.Type()]; exists {
+		return k
+	}
+	return e.Type()
+}
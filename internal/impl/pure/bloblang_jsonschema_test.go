@@ -0,0 +1,101 @@
+package pure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+func TestValidateJSONSchema(t *testing.T) {
+	schema := `{
+  "type": "object",
+  "properties": {
+    "id": { "type": "string", "pattern": "^[a-z]+$" },
+    "tags": { "type": "array", "items": { "type": "string" }, "maxItems": 2 }
+  },
+  "required": ["id"],
+  "additionalProperties": false
+}`
+
+	exec, err := bloblang.Parse(`root = this.validate_json_schema("""` + schema + `""")`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		input  any
+		output []any
+	}{
+		{
+			name:   "valid",
+			input:  map[string]any{"id": "foo", "tags": []any{"a"}},
+			output: []any{},
+		},
+		{
+			name:  "missing required",
+			input: map[string]any{},
+			output: []any{
+				map[string]any{"path": "", "keyword": "required", "message": "id is required"},
+			},
+		},
+		{
+			name:  "nested type",
+			input: map[string]any{"id": "foo", "tags": []any{"a", int64(5)}},
+			output: []any{
+				map[string]any{"path": "tags.1", "keyword": "type", "message": "Invalid type. Expected: string, given: integer"},
+			},
+		},
+		{
+			name:  "pattern",
+			input: map[string]any{"id": "FOO"},
+			output: []any{
+				map[string]any{"path": "id", "keyword": "pattern", "message": `Does not match pattern '^[a-z]+$'`},
+			},
+		},
+		{
+			name:  "additional properties",
+			input: map[string]any{"id": "foo", "bar": true},
+			output: []any{
+				map[string]any{"path": "", "keyword": "additionalProperties", "message": "Additional property bar is not allowed"},
+			},
+		},
+		{
+			name:  "max items",
+			input: map[string]any{"id": "foo", "tags": []any{"a", "b", "c"}},
+			output: []any{
+				map[string]any{"path": "tags", "keyword": "maxItems", "message": "Array must have at most 2 items"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			res, err := exec.Query(test.input)
+			require.NoError(t, err)
+			assert.Equal(t, test.output, res)
+		})
+	}
+}
+
+func TestValidateJSONSchemaStructured(t *testing.T) {
+	exec, err := bloblang.Parse(`root = this.value.validate_json_schema(this.schema)`)
+	require.NoError(t, err)
+
+	res, err := exec.Query(map[string]any{
+		"schema": map[string]any{"type": "number", "maximum": 10},
+		"value":  int64(11),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		map[string]any{"path": "", "keyword": "maximum", "message": "Must be less than or equal to 10"},
+	}, res)
+}
+
+func TestValidateJSONSchemaBadSchema(t *testing.T) {
+	_, err := bloblang.Parse(`root = this.validate_json_schema("""{"type":"nope"}""")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to compile schema")
+}
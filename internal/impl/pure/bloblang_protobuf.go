@@ -0,0 +1,121 @@
+package pure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+func protobufParamsSpec(spec *bloblang.PluginSpec) *bloblang.PluginSpec {
+	return spec.
+		Param(bloblang.NewStringParam("message").
+			Description("The fully qualified name of the protobuf message type, e.g. `foo.bar.Baz`.")).
+		Param(bloblang.NewStringParam("descriptor_set").
+			Description("The path of a file containing a binary encoded `FileDescriptorSet`, as generated with `protoc --include_imports --descriptor_set_out`. Relative paths are resolved from the directory of the mapping."))
+}
+
+func init() {
+	if err := bloblang.RegisterMethodV2("parse_protobuf",
+		protobufParamsSpec(bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description(`Attempts to parse a string or byte array as a protobuf message of a type described within a descriptor set file, and returns the result as a structured value following the protobuf JSON mapping.`).
+			Version("4.29.0")).
+			ExampleNotTested("", `root = content().parse_protobuf(message: "testing.Person", descriptor_set: "./protos.binpb")`),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			msgType, err := protobufMessageTypeFromParams(args)
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.BytesMethod(func(data []byte) (any, error) {
+				msg := dynamicpb.NewMessage(msgType)
+				if err := proto.Unmarshal(data, msg); err != nil {
+					return nil, fmt.Errorf("failed to parse value as protobuf: %w", err)
+				}
+				jBytes, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+				if err != nil {
+					return nil, err
+				}
+				var v any
+				decoder := json.NewDecoder(bytes.NewReader(jBytes))
+				decoder.UseNumber()
+				if err := decoder.Decode(&v); err != nil {
+					return nil, err
+				}
+				return v, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("format_protobuf",
+		protobufParamsSpec(bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description(`Serializes a structured value into a protobuf message of a type described within a descriptor set file, the value must follow the protobuf JSON mapping of the message.`).
+			Version("4.29.0")).
+			ExampleNotTested("", `root = this.format_protobuf(message: "testing.Person", descriptor_set: "./protos.binpb")`),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			msgType, err := protobufMessageTypeFromParams(args)
+			if err != nil {
+				return nil, err
+			}
+			return func(v any) (any, error) {
+				jBytes, err := json.Marshal(v)
+				if err != nil {
+					return nil, err
+				}
+				msg := dynamicpb.NewMessage(msgType)
+				if err := protojson.Unmarshal(jBytes, msg); err != nil {
+					return nil, fmt.Errorf("failed to convert value to protobuf message: %w", err)
+				}
+				return proto.Marshal(msg)
+			}, nil
+		}); err != nil {
+		panic(err)
+	}
+}
+
+func protobufMessageTypeFromParams(args *bloblang.ParsedParams) (protoreflect.MessageDescriptor, error) {
+	msgName, err := args.GetString("message")
+	if err != nil {
+		return nil, err
+	}
+	descPath, err := args.GetString("descriptor_set")
+	if err != nil {
+		return nil, err
+	}
+	descBytes, err := args.ImportFile(descPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+	return protobufMessageType(descBytes, msgName)
+}
+
+func protobufMessageType(descBytes []byte, msgName string) (protoreflect.MessageDescriptor, error) {
+	var fds descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(descBytes, &fds); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set: %w", err)
+	}
+	files, err := protodesc.NewFiles(&fds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set: %w", err)
+	}
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(msgName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find message %v in descriptor set: %w", msgName, err)
+	}
+	msgDesc, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("descriptor %v is not a message", msgName)
+	}
+	return msgDesc, nil
+}
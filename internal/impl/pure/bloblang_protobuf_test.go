@@ -0,0 +1,110 @@
+package pure
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+func writeTestDescriptorSet(t testing.TB) string {
+	t.Helper()
+
+	fds := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			{
+				Name:    proto.String("person.proto"),
+				Package: proto.String("testing"),
+				Syntax:  proto.String("proto3"),
+				MessageType: []*descriptorpb.DescriptorProto{
+					{
+						Name: proto.String("Person"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							{
+								Name:     proto.String("first_name"),
+								JsonName: proto.String("firstName"),
+								Number:   proto.Int32(1),
+								Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+								Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+							},
+							{
+								Name:     proto.String("age"),
+								JsonName: proto.String("age"),
+								Number:   proto.Int32(2),
+								Type:     descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+								Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+							},
+							{
+								Name:     proto.String("tags"),
+								JsonName: proto.String("tags"),
+								Number:   proto.Int32(3),
+								Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+								Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	fdsBytes, err := proto.Marshal(fds)
+	require.NoError(t, err)
+
+	descPath := filepath.Join(t.TempDir(), "protos.binpb")
+	require.NoError(t, os.WriteFile(descPath, fdsBytes, 0o644))
+	return descPath
+}
+
+func TestProtobufParseFormat(t *testing.T) {
+	descPath := writeTestDescriptorSet(t)
+
+	format, err := bloblang.Parse(`root = this.format_protobuf(message: "testing.Person", descriptor_set: "` + descPath + `")`)
+	require.NoError(t, err)
+
+	parse, err := bloblang.Parse(`root = this.parse_protobuf(message: "testing.Person", descriptor_set: "` + descPath + `")`)
+	require.NoError(t, err)
+
+	encoded, err := format.Query(map[string]any{
+		"first_name": "Ash",
+		"age":        32,
+		"tags":       []any{"foo", "bar"},
+	})
+	require.NoError(t, err)
+	require.IsType(t, []byte(nil), encoded)
+
+	decoded, err := parse.Query(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"first_name": "Ash",
+		"age":        json.Number("32"),
+		"tags":       []any{"foo", "bar"},
+	}, decoded)
+
+	_, err = format.Query(map[string]any{"nope": "nah"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to convert value to protobuf message")
+
+	_, err = parse.Query([]byte{0xff})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse value as protobuf")
+}
+
+func TestProtobufBadParams(t *testing.T) {
+	descPath := writeTestDescriptorSet(t)
+
+	_, err := bloblang.Parse(`root = this.parse_protobuf(message: "testing.Nope", descriptor_set: "` + descPath + `")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to find message testing.Nope in descriptor set")
+
+	_, err = bloblang.Parse(`root = this.parse_protobuf(message: "testing.Person", descriptor_set: "/does/not/exist.binpb")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read descriptor set")
+}
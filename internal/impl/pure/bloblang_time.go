@@ -2,6 +2,10 @@ package pure
 
 import (
 	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/itchyny/timefmt-go"
@@ -18,6 +22,68 @@ func asDeprecated(s *bloblang.PluginSpec) *bloblang.PluginSpec {
 	return newSpec
 }
 
+var durationComponentRegexp = regexp.MustCompile(`([0-9]*\.?[0-9]+)(ns|us|µs|μs|ms|s|m|h|d|w)`)
+
+// parseDuration parses a duration string as time.ParseDuration does, but also
+// accepts the units "d" (24 hours) and "w" (7 days).
+func parseDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err == nil || !strings.ContainsAny(s, "dw") {
+		return d, err
+	}
+	err = fmt.Errorf("invalid duration %q", s)
+
+	str, neg := s, false
+	if str != "" && (str[0] == '-' || str[0] == '+') {
+		neg = str[0] == '-'
+		str = str[1:]
+	}
+
+	var total time.Duration
+	var remaining strings.Builder
+	consumed := 0
+	for _, match := range durationComponentRegexp.FindAllStringSubmatchIndex(str, -1) {
+		if match[0] != consumed {
+			return 0, err
+		}
+		consumed = match[1]
+
+		var unit time.Duration
+		switch str[match[4]:match[5]] {
+		case "d":
+			unit = 24 * time.Hour
+		case "w":
+			unit = 7 * 24 * time.Hour
+		default:
+			_, _ = remaining.WriteString(str[match[0]:match[1]])
+			continue
+		}
+		n, nErr := strconv.ParseFloat(str[match[2]:match[3]], 64)
+		if nErr != nil {
+			return 0, err
+		}
+		f := n * float64(unit)
+		if f >= math.MaxInt64 || time.Duration(f) > math.MaxInt64-total {
+			return 0, err
+		}
+		total += time.Duration(f)
+	}
+	if consumed != len(str) {
+		return 0, err
+	}
+	if remaining.Len() > 0 {
+		rest, rErr := time.ParseDuration(remaining.String())
+		if rErr != nil || rest > math.MaxInt64-total {
+			return 0, err
+		}
+		total += rest
+	}
+	if neg {
+		total = -total
+	}
+	return total, nil
+}
+
 func init() {
 	// Note: The examples are run and tested from within
 	// ./internal/bloblang/query/parsed_test.go
@@ -134,7 +200,7 @@ func init() {
 	parseDurSpec := bloblang.NewPluginSpec().
 		Static().
 		Category(query.MethodCategoryTime).
-		Description(`Attempts to parse a string as a duration and returns an integer of nanoseconds. A duration string is a possibly signed sequence of decimal numbers, each with an optional fraction and a unit suffix, such as "300ms", "-1.5h" or "2h45m". Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".`).
+		Description(`Attempts to parse a string as a duration and returns an integer of nanoseconds. A duration string is a possibly signed sequence of decimal numbers, each with an optional fraction and a unit suffix, such as "300ms", "-1.5h" or "2h45m". Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h", "d" (24 hours) and "w" (7 days).`).
 		Example("",
 			`root.delay_for_ns = this.delay_for.parse_duration()`,
 			[2]string{
@@ -148,11 +214,18 @@ func init() {
 				`{"delay_for":"2h"}`,
 				`{"delay_for_s":7200}`,
 			},
+		).
+		Example("Days and weeks are also supported, and are always exactly 24 hours and 7 days long respectively.",
+			`root.retention_h = this.retention.parse_duration() / 3600000000000`,
+			[2]string{
+				`{"retention":"1w2d12h"}`,
+				`{"retention_h":228}`,
+			},
 		)
 
 	parseDurCtor := func(args *bloblang.ParsedParams) (bloblang.Method, error) {
 		return bloblang.StringMethod(func(s string) (any, error) {
-			d, err := time.ParseDuration(s)
+			d, err := parseDuration(s)
 			if err != nil {
 				return nil, err
 			}
@@ -210,6 +283,38 @@ func init() {
 
 	//--------------------------------------------------------------------------
 
+	formatDurSpec := bloblang.NewPluginSpec().
+		Static().
+		Category(query.MethodCategoryTime).
+		Version("4.29.0").
+		Description("Formats an integer number of nanoseconds as a duration string, such as \"1h30m0s\", which can be parsed again with <<parse_duration, `parse_duration`>>. Decimal places of the input are truncated.").
+		Example("",
+			`root.delay_for = this.delay_for_ns.format_duration()`,
+			[2]string{
+				`{"delay_for_ns":5400000000000}`,
+				`{"delay_for":"1h30m0s"}`,
+			},
+		).
+		Example("",
+			`root.delay_for = (this.delay_for_s * 1000000000).format_duration()`,
+			[2]string{
+				`{"delay_for_s":2.5}`,
+				`{"delay_for":"2.5s"}`,
+			},
+		)
+
+	formatDurCtor := func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+		return bloblang.Float64Method(func(f float64) (any, error) {
+			return time.Duration(f).String(), nil
+		}), nil
+	}
+
+	if err := bloblang.RegisterMethodV2("format_duration", formatDurSpec, formatDurCtor); err != nil {
+		panic(err)
+	}
+
+	//--------------------------------------------------------------------------
+
 	parseTSSpec := bloblang.NewPluginSpec().
 		Category(query.MethodCategoryTime).
 		Beta().
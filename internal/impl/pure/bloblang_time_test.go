@@ -2,6 +2,7 @@ package pure
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -167,6 +168,46 @@ func TestTimestampMethods(t *testing.T) {
 			mapping:            `root = "gibberish".parse_duration_iso8601()`,
 			parseErrorContains: "gibberish: expected 'P' period mark at the start",
 		},
+		{
+			name:    "check parse duration days and weeks",
+			mapping: `root = "1w2d12h30m".parse_duration()`,
+			output:  int64((9*24*time.Hour + 12*time.Hour + 30*time.Minute).Nanoseconds()),
+		},
+		{
+			name:    "check parse duration negative fractional days",
+			mapping: `root = "-1.5d".parse_duration()`,
+			output:  int64(-36 * time.Hour),
+		},
+		{
+			name:               "check parse duration days with bogus unit",
+			mapping:            `root = "1d3x".parse_duration()`,
+			parseErrorContains: `invalid duration "1d3x"`,
+		},
+		{
+			name:               "check parse duration weeks overflow",
+			mapping:            `root = "99999999999w".parse_duration()`,
+			parseErrorContains: `invalid duration "99999999999w"`,
+		},
+		{
+			name:               "check parse duration summed overflow",
+			mapping:            `root = "15000w15000w".parse_duration()`,
+			parseErrorContains: `invalid duration "15000w15000w"`,
+		},
+		{
+			name:               "check parse duration days with hours overflow",
+			mapping:            `root = "106751d2562047h".parse_duration()`,
+			parseErrorContains: `invalid duration "106751d2562047h"`,
+		},
+		{
+			name:    "check format duration",
+			mapping: `root = 5400000000000.format_duration()`,
+			output:  "1h30m0s",
+		},
+		{
+			name:    "check format duration round trip",
+			mapping: `root = "1w2d".parse_duration().format_duration()`,
+			output:  "216h0m0s",
+		},
 		{
 			name:    "check ts_add_iso8601",
 			mapping: `root = 1677097265.ts_add_iso8601("P1Y").ts_unix()`,
@@ -0,0 +1,198 @@
+package pure
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/internal/bytesize"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+// measurementUnit describes a unit by how a value of it is converted into the
+// base unit of its dimension, which is value*factor + offset.
+type measurementUnit struct {
+	dimension string
+	factor    float64
+	offset    float64
+}
+
+var measurementUnits = map[string]measurementUnit{
+	"mm":  {dimension: "length", factor: 1e-3},
+	"cm":  {dimension: "length", factor: 1e-2},
+	"m":   {dimension: "length", factor: 1},
+	"km":  {dimension: "length", factor: 1e3},
+	"in":  {dimension: "length", factor: 0.0254},
+	"ft":  {dimension: "length", factor: 0.3048},
+	"yd":  {dimension: "length", factor: 0.9144},
+	"mi":  {dimension: "length", factor: 1609.344},
+	"nmi": {dimension: "length", factor: 1852},
+
+	"mg": {dimension: "mass", factor: 1e-6},
+	"g":  {dimension: "mass", factor: 1e-3},
+	"kg": {dimension: "mass", factor: 1},
+	"t":  {dimension: "mass", factor: 1e3},
+	"oz": {dimension: "mass", factor: 0.028349523125},
+	"lb": {dimension: "mass", factor: 0.45359237},
+	"st": {dimension: "mass", factor: 6.35029318},
+
+	"c": {dimension: "temperature", factor: 1, offset: 273.15},
+	"f": {dimension: "temperature", factor: 5.0 / 9.0, offset: 459.67 * 5.0 / 9.0},
+	"k": {dimension: "temperature", factor: 1},
+
+	"ns":  {dimension: "time", factor: 1e-9},
+	"us":  {dimension: "time", factor: 1e-6},
+	"ms":  {dimension: "time", factor: 1e-3},
+	"s":   {dimension: "time", factor: 1},
+	"min": {dimension: "time", factor: 60},
+	"h":   {dimension: "time", factor: 3600},
+	"d":   {dimension: "time", factor: 86400},
+	"w":   {dimension: "time", factor: 604800},
+
+	"b":   {dimension: "data", factor: 1},
+	"kb":  {dimension: "data", factor: 1e3},
+	"mb":  {dimension: "data", factor: 1e6},
+	"gb":  {dimension: "data", factor: 1e9},
+	"tb":  {dimension: "data", factor: 1e12},
+	"pb":  {dimension: "data", factor: 1e15},
+	"kib": {dimension: "data", factor: 1 << 10},
+	"mib": {dimension: "data", factor: 1 << 20},
+	"gib": {dimension: "data", factor: 1 << 30},
+	"tib": {dimension: "data", factor: 1 << 40},
+	"pib": {dimension: "data", factor: 1 << 50},
+}
+
+func getMeasurementUnit(name string) (measurementUnit, error) {
+	u, exists := measurementUnits[strings.ToLower(name)]
+	if !exists {
+		return u, fmt.Errorf("unrecognised unit %q", name)
+	}
+	return u, nil
+}
+
+func measurementUnitsDocs() string {
+	byDimension := map[string][]string{}
+	for name, u := range measurementUnits {
+		byDimension[u.dimension] = append(byDimension[u.dimension], "`"+name+"`")
+	}
+	dimensions := make([]string, 0, len(byDimension))
+	for d := range byDimension {
+		dimensions = append(dimensions, d)
+	}
+	sort.Strings(dimensions)
+
+	var sb strings.Builder
+	for _, d := range dimensions {
+		names := byDimension[d]
+		sort.Strings(names)
+		fmt.Fprintf(&sb, "\n- %v: %v", d, strings.Join(names, ", "))
+	}
+	return sb.String()
+}
+
+func init() {
+	if err := bloblang.RegisterMethodV2("parse_bytes",
+		bloblang.NewPluginSpec().
+			Static().
+			Category(query.MethodCategoryNumbers).
+			Description("Attempts to parse a string as a human readable quantity of bytes, such as `512KB` or `10MiB`, and returns an integer number of bytes. Units are case insensitive, where SI units (KB, MB, GB, etc) are powers of 1000 and IEC units (KiB, MiB, GiB, etc) are powers of 1024. A number without a unit is a quantity of bytes.").
+			Version("4.29.0").
+			Example("",
+				`root.limit_bytes = this.limit.parse_bytes()`,
+				[2]string{`{"limit":"10MiB"}`, `{"limit_bytes":10485760}`},
+				[2]string{`{"limit":"1.5 GB"}`, `{"limit_bytes":1500000000}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return bytesize.Parse(s)
+			}), nil
+		},
+	); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("format_bytes",
+		bloblang.NewPluginSpec().
+			Static().
+			Category(query.MethodCategoryNumbers).
+			Description("Formats a number of bytes as a human readable string in the largest unit that the number is at least one of, which can be parsed again with <<parse_bytes, `parse_bytes`>>.").
+			Version("4.29.0").
+			Param(bloblang.NewBoolParam("si").Description("Whether to use SI units (KB, MB, etc), which are powers of 1000, rather than IEC units (KiB, MiB, etc), which are powers of 1024.").Default(false)).
+			Param(bloblang.NewInt64Param("precision").Description("The maximum number of decimal places.").Default(2)).
+			Example("",
+				`root.size = this.size_bytes.format_bytes()`,
+				[2]string{`{"size_bytes":10485760}`, `{"size":"10MiB"}`},
+				[2]string{`{"size_bytes":1536}`, `{"size":"1.5KiB"}`},
+			).
+			Example("",
+				`root.size = this.size_bytes.format_bytes(si: true, precision: 1)`,
+				[2]string{`{"size_bytes":1234567}`, `{"size":"1.2MB"}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			si, err := args.GetBool("si")
+			if err != nil {
+				return nil, err
+			}
+			precision, err := args.GetInt64("precision")
+			if err != nil {
+				return nil, err
+			}
+			if precision < 0 {
+				return nil, fmt.Errorf("precision must not be negative, got %v", precision)
+			}
+			return bloblang.Float64Method(func(f float64) (any, error) {
+				return bytesize.Format(f, si, int(precision)), nil
+			}), nil
+		},
+	); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("convert_unit",
+		bloblang.NewPluginSpec().
+			Static().
+			Category(query.MethodCategoryNumbers).
+			Description("Converts a number from one unit of measurement to another of the same dimension, returning a floating point number. Units are case insensitive, and results are rounded to 12 significant figures in order to avoid floating point artifacts. The following units are supported:\n"+measurementUnitsDocs()).
+			Version("4.29.0").
+			Param(bloblang.NewStringParam("from").Description("The unit of the number.")).
+			Param(bloblang.NewStringParam("to").Description("The unit to convert the number to.")).
+			Example("",
+				`root.temp_c = this.temp_f.convert_unit("f", "c")`,
+				[2]string{`{"temp_f":212}`, `{"temp_c":100}`},
+				[2]string{`{"temp_f":-40}`, `{"temp_c":-40}`},
+			).
+			Example("",
+				`root.distance_km = this.distance_mi.convert_unit(from: "mi", to: "km")`,
+				[2]string{`{"distance_mi":26.2}`, `{"distance_km":42.1648128}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			fromStr, err := args.GetString("from")
+			if err != nil {
+				return nil, err
+			}
+			toStr, err := args.GetString("to")
+			if err != nil {
+				return nil, err
+			}
+			from, err := getMeasurementUnit(fromStr)
+			if err != nil {
+				return nil, err
+			}
+			to, err := getMeasurementUnit(toStr)
+			if err != nil {
+				return nil, err
+			}
+			if from.dimension != to.dimension {
+				return nil, fmt.Errorf("cannot convert %v (%v) to %v (%v)", fromStr, from.dimension, toStr, to.dimension)
+			}
+			return bloblang.Float64Method(func(f float64) (any, error) {
+				res := (f*from.factor + (from.offset - to.offset)) / to.factor
+				return strconv.ParseFloat(strconv.FormatFloat(res, 'g', 12, 64), 64)
+			}), nil
+		},
+	); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,154 @@
+package pure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+func TestUnitMethods(t *testing.T) {
+	tests := []struct {
+		name               string
+		mapping            string
+		input              any
+		output             any
+		parseErrorContains string
+		execErrorContains  string
+	}{
+		{
+			name:    "parse_bytes iec",
+			mapping: `root = this.parse_bytes()`,
+			input:   "10MiB",
+			output:  int64(10 * 1024 * 1024),
+		},
+		{
+			name:    "parse_bytes si",
+			mapping: `root = this.parse_bytes()`,
+			input:   "512kb",
+			output:  int64(512000),
+		},
+		{
+			name:    "parse_bytes no unit",
+			mapping: `root = this.parse_bytes()`,
+			input:   "100",
+			output:  int64(100),
+		},
+		{
+			name:              "parse_bytes bad unit",
+			mapping:           `root = this.parse_bytes()`,
+			input:             "10XB",
+			execErrorContains: "byte size '10XB' has an unrecognised unit",
+		},
+		{
+			name:              "parse_bytes not a string",
+			mapping:           `root = this.parse_bytes()`,
+			input:             int64(10),
+			execErrorContains: "expected string value, got number",
+		},
+		{
+			name:    "format_bytes iec",
+			mapping: `root = this.format_bytes()`,
+			input:   int64(10 * 1024 * 1024),
+			output:  "10MiB",
+		},
+		{
+			name:    "format_bytes si precision",
+			mapping: `root = this.format_bytes(si: true, precision: 3)`,
+			input:   int64(1234567),
+			output:  "1.235MB",
+		},
+		{
+			name:    "format_bytes small",
+			mapping: `root = this.format_bytes()`,
+			input:   int64(512),
+			output:  "512B",
+		},
+		{
+			name:    "format_bytes round trip",
+			mapping: `root = this.format_bytes().parse_bytes()`,
+			input:   int64(1536),
+			output:  int64(1536),
+		},
+		{
+			name:               "format_bytes negative precision",
+			mapping:            `root = this.format_bytes(precision: -1)`,
+			parseErrorContains: "precision must not be negative, got -1",
+		},
+		{
+			name:    "convert_unit celsius to fahrenheit",
+			mapping: `root = this.convert_unit("C", "F")`,
+			input:   37.5,
+			output:  99.5,
+		},
+		{
+			name:    "convert_unit kelvin to celsius",
+			mapping: `root = this.convert_unit("k", "c")`,
+			input:   int64(0),
+			output:  -273.15,
+		},
+		{
+			name:    "convert_unit inches to centimetres",
+			mapping: `root = this.convert_unit("in", "cm")`,
+			input:   int64(1),
+			output:  2.54,
+		},
+		{
+			name:    "convert_unit pounds to kilograms",
+			mapping: `root = this.convert_unit("lb", "kg")`,
+			input:   int64(10),
+			output:  4.5359237,
+		},
+		{
+			name:    "convert_unit days to hours",
+			mapping: `root = this.convert_unit("d", "h")`,
+			input:   1.5,
+			output:  float64(36),
+		},
+		{
+			name:    "convert_unit gibibytes to megabytes",
+			mapping: `root = this.convert_unit("GiB", "MB")`,
+			input:   int64(1),
+			output:  1073.741824,
+		},
+		{
+			name:               "convert_unit mismatched dimensions",
+			mapping:            `root = this.convert_unit("m", "kg")`,
+			parseErrorContains: "cannot convert m (length) to kg (mass)",
+		},
+		{
+			name:               "convert_unit unknown unit",
+			mapping:            `root = this.convert_unit("m", "furlong")`,
+			parseErrorContains: `unrecognised unit "furlong"`,
+		},
+		{
+			name:              "convert_unit not a number",
+			mapping:           `root = this.convert_unit("m", "km")`,
+			input:             "nope",
+			execErrorContains: "expected number value, got string",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			m, err := bloblang.Parse(test.mapping)
+			if test.parseErrorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.parseErrorContains)
+			} else {
+				require.NoError(t, err)
+				v, err := m.Query(test.input)
+				if test.execErrorContains != "" {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), test.execErrorContains)
+				} else {
+					require.NoError(t, err)
+					assert.Equal(t, test.output, v)
+				}
+			}
+		})
+	}
+}
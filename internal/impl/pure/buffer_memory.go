@@ -129,16 +129,14 @@ func (m *memoryBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, ser
 		m.cond.Broadcast()
 	}()
 
-	var batchReady, timedBatch bool
-
-	triggerTimed := func() {
-		batchReady = false
-		timedBatch = false
+	var batchReady, timedBatch, timerArmed bool
 
+	armTimer := func() {
 		timedDur, exists := m.batcher.UntilNext()
 		if !exists {
 			return
 		}
+		timerArmed = true
 
 		timer := time.NewTimer(timedDur)
 		go func() {
@@ -154,6 +152,13 @@ func (m *memoryBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, ser
 			}
 		}()
 	}
+
+	triggerTimed := func() {
+		batchReady = false
+		timedBatch = false
+		timerArmed = false
+		armTimer()
+	}
 	triggerTimed()
 
 	m.cond.L.Lock()
@@ -188,6 +193,12 @@ func (m *memoryBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, ser
 			m.batches = m.batches[1:]
 		}
 
+		// The batch period is only active once the minimum count of the batch
+		// policy is reached, which might not have been the case initially.
+		if !batchReady && !timerArmed {
+			armTimer()
+		}
+
 		if batchReady || m.endOfInput {
 			var err error
 			if outBatch, err = m.batcher.Flush(ctx); err != nil {
@@ -1,18 +1,34 @@
 package pure
 
 import (
+	"errors"
+
+	"github.com/redpanda-data/benthos/v4/internal/component/input/batcher"
 	"github.com/redpanda-data/benthos/v4/internal/component/interop"
 	"github.com/redpanda-data/benthos/v4/public/service"
 )
 
+const (
+	biFieldChild       = "child"
+	biFieldPolicy      = "policy"
+	biFieldMaxByteSize = "max_byte_size"
+)
+
 func batchedInputConfig() *service.ConfigSpec {
 	spec := service.NewConfigSpec().
 		Stable().
 		Categories("Utility").
 		Summary("Consumes data from a child input and applies a batching policy to the stream.").
-		Description(`Batching at the input level is sometimes useful for processing across micro-batches, and can also sometimes be a useful performance trick. However, most inputs are fine without it so unless you have a specific plan for batching this component is not worth using.`).
-		Field(service.NewInputField("child").Description("The child input.")).
-		Field(service.NewBatchPolicyField("policy")).
+		Description(`Batching at the input level is sometimes useful for processing across micro-batches, and can also sometimes be a useful performance trick. However, most inputs are fine without it so unless you have a specific plan for batching this component is not worth using.
+
+When the input is shut down gracefully any partial batch is flushed immediately rather than waiting for the child input to close.`).
+		Field(service.NewInputField(biFieldChild).Description("The child input.")).
+		Field(service.NewBatchPolicyField(biFieldPolicy)).
+		Field(service.NewIntField(biFieldMaxByteSize).
+			Description("An optional maximum size in bytes of each batch. When consuming a message would cause the pending batch to exceed this size the pending batch is flushed before the message is added to a new one. Unlike the `byte_size` of the policy, which flushes a batch once it reaches the size, this guarantees that batches remain within the limit, with the exception of individual messages that exceed it, which are batched alone. Set to zero in order to disable.").
+			Advanced().
+			Default(0).
+			Version("4.29.0")).
 		Version("4.11.0")
 	return spec
 }
@@ -21,23 +37,35 @@ func init() {
 	err := service.RegisterBatchInput(
 		"batched", batchedInputConfig(),
 		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
-			child, err := conf.FieldInput("child")
+			child, err := conf.FieldInput(biFieldChild)
 			if err != nil {
 				return nil, err
 			}
 
-			batcherPol, err := conf.FieldBatchPolicy("policy")
+			batcherPol, err := conf.FieldBatchPolicy(biFieldPolicy)
 			if err != nil {
 				return nil, err
 			}
 
-			batcher, err := batcherPol.NewBatcher(mgr)
+			maxByteSize, err := conf.FieldInt(biFieldMaxByteSize)
+			if err != nil {
+				return nil, err
+			}
+			if maxByteSize < 0 {
+				return nil, errors.New("max_byte_size must not be negative")
+			}
+
+			pBatcher, err := batcherPol.NewBatcher(mgr)
 			if err != nil {
 				return nil, err
 			}
 
-			child = child.BatchedWith(batcher)
-			sChild := interop.UnwrapOwnedInput(child)
+			sChild := batcher.New(
+				interop.UnwrapBatcher(pBatcher),
+				interop.UnwrapOwnedInput(child),
+				interop.UnwrapManagement(mgr).Logger(),
+				batcher.OptMaxByteSize(maxByteSize),
+			)
 			return interop.NewUnwrapInternalInput(sChild), nil
 		})
 	if err != nil {
@@ -119,3 +119,37 @@ processors:
 		`{"ID":10,"x":5} and this`,
 	}, outBatches[1])
 }
+
+func TestBatchedInputMaxByteSize(t *testing.T) {
+	builder := service.NewStreamBuilder()
+	require.NoError(t, builder.AddInputYAML(`
+batched:
+  child:
+    generate:
+      mapping: 'root.id = count("TEST_BATCHED_INPUT_MAX_BYTE_SIZE")'
+      count: 5
+      interval: ""
+  policy:
+    count: 5
+    period: 100ms
+  max_byte_size: 20
+`))
+
+	var outBatches []service.MessageBatch
+	require.NoError(t, builder.AddBatchConsumerFunc(func(ctx context.Context, mb service.MessageBatch) error {
+		outBatches = append(outBatches, mb.DeepCopy())
+		return nil
+	}))
+
+	strm, err := builder.Build()
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+	require.NoError(t, strm.Run(ctx))
+
+	require.Len(t, outBatches, 3)
+	batchEquals(t, []string{`{"id":1}`, `{"id":2}`}, outBatches[0])
+	batchEquals(t, []string{`{"id":3}`, `{"id":4}`}, outBatches[1])
+	batchEquals(t, []string{`{"id":5}`}, outBatches[2])
+}
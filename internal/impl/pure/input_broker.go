@@ -2,6 +2,7 @@ package pure
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/redpanda-data/benthos/v4/internal/component/input"
 	"github.com/redpanda-data/benthos/v4/internal/component/input/batcher"
@@ -13,9 +14,11 @@ import (
 var ErrBrokerNoInputs = errors.New("attempting to create broker input type with no inputs")
 
 const (
-	ibFieldCopies   = "copies"
-	ibFieldInputs   = "inputs"
-	ibFieldBatching = "batching"
+	ibFieldCopies            = "copies"
+	ibFieldPattern           = "pattern"
+	ibFieldStarvationTimeout = "starvation_timeout"
+	ibFieldInputs            = "inputs"
+	ibFieldBatching          = "batching"
 )
 
 func brokerInputSpec() *service.ConfigSpec {
@@ -56,6 +59,36 @@ input:
 
 If the number of copies is greater than zero the list will be copied that number of times. For example, if your inputs were of type foo and bar, with 'copies' set to '2', you would end up with two 'foo' inputs and two 'bar' inputs.
 
+== Patterns
+
+The broker pattern determines the way in which messages from child inputs are combined and can be chosen from the following:
+
+=== `+"`fan_in`"+`
+
+With the fan in pattern all inputs are read in parallel and messages are consumed in the order that they arrive, regardless of which input they came from.
+
+=== `+"`priority`"+`
+
+With the priority pattern all inputs are read in parallel, but when more than one input has a message ready the input listed first within `+"`inputs`"+` is consumed before those that follow it. This is useful for draining a backlog from one source before another, such as replaying a topic whilst also consuming live data:
+
+`+"```yaml"+`
+input:
+  broker:
+    pattern: priority
+    inputs:
+      - kafka:
+          addresses: [ localhost:9092 ]
+          consumer_group: benthos_replay
+          topics: [ events_replay ]
+
+      - kafka:
+          addresses: [ localhost:9092 ]
+          consumer_group: benthos_live
+          topics: [ events ]
+`+"```"+`
+
+In order to prevent lower priority inputs from being starved entirely, a message that has been waiting for longer than the `+"`starvation_timeout`"+` is consumed ahead of any others. When `+"`copies`"+` is greater than one each copy of an input shares the priority of the original.
+
 == Batching
 
 It's possible to configure a xref:configuration:batching.adoc#batch-policy[batch policy] with a broker using the `+"`batching`"+` fields. When doing this the feeds from all child inputs are combined. Some inputs do not support broker based batching and specify this in their documentation.
@@ -68,9 +101,19 @@ It is possible to configure xref:components:processors/about.adoc[processors] at
 				Description("Whatever is specified within `inputs` will be created this many times.").
 				Advanced().
 				Default(1),
+			service.NewStringEnumField(ibFieldPattern, "fan_in", "priority").
+				Description("The brokering <<patterns, pattern>> to use.").
+				Advanced().
+				Version("4.29.0").
+				Default("fan_in"),
+			service.NewDurationField(ibFieldStarvationTimeout).
+				Description("When using the `priority` pattern, the maximum period of time that a message from a lower priority input waits before it is consumed ahead of higher priority inputs. Set to `0s` in order to disable starvation protection.").
+				Advanced().
+				Version("4.29.0").
+				Default("10s"),
 			service.NewInputListField(ibFieldInputs).
 				Description("A list of inputs to create."),
-			service.NewBatchPolicyField("batching"),
+			service.NewBatchPolicyField(ibFieldBatching),
 		)
 }
 
@@ -103,6 +146,11 @@ func newBrokerInputFromParsed(conf *service.ParsedConfig, mgr *service.Resources
 		return nil, ErrBrokerNoInputs
 	}
 
+	pattern, err := conf.FieldString(ibFieldPattern)
+	if err != nil {
+		return nil, err
+	}
+
 	var b input.Streamed
 	if len(children) == 1 && copies == 1 {
 		b = interop.UnwrapOwnedInput(children[0])
@@ -120,8 +168,25 @@ func newBrokerInputFromParsed(conf *service.ParsedConfig, mgr *service.Resources
 				inputs = append(inputs, interop.UnwrapOwnedInput(v))
 			}
 		}
-		if b, err = newFanInInputBroker(inputs); err != nil {
-			return nil, err
+		switch pattern {
+		case "fan_in":
+			if b, err = newFanInInputBroker(inputs); err != nil {
+				return nil, err
+			}
+		case "priority":
+			starvationTimeout, err := conf.FieldDuration(ibFieldStarvationTimeout)
+			if err != nil {
+				return nil, err
+			}
+			priorities := make([]int, len(inputs))
+			for j := range priorities {
+				priorities[j] = j % len(children)
+			}
+			if b, err = newPriorityInputBroker(inputs, priorities, starvationTimeout); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("broker pattern was not recognised: %v", pattern)
 		}
 	}
 
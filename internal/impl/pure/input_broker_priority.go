@@ -0,0 +1,263 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/shutdown"
+
+	"github.com/redpanda-data/benthos/v4/internal/component/input"
+	"github.com/redpanda-data/benthos/v4/internal/message"
+)
+
+type priorityArrival struct {
+	index  int
+	tran   message.Transaction
+	at     time.Time
+	closed bool
+}
+
+// priorityInputBroker consumes from multiple inputs in parallel, but when more
+// than one input has a transaction ready the one of the highest priority is
+// delivered first. In order to prevent lower priority inputs from starving, a
+// transaction that has waited longer than the starvation timeout is delivered
+// before any others.
+type priorityInputBroker struct {
+	transactions chan message.Transaction
+
+	closables         []input.Streamed
+	priorities        []int
+	starvationTimeout time.Duration
+
+	arrivals chan priorityArrival
+	release  []chan struct{}
+
+	remainingMap    map[int]struct{}
+	remainingMapMut sync.Mutex
+
+	shutSig *shutdown.Signaller
+}
+
+// newPriorityInputBroker creates a broker where the priority of each input is
+// provided as a number, where lower numbers are a higher priority.
+func newPriorityInputBroker(inputs []input.Streamed, priorities []int, starvationTimeout time.Duration) (*priorityInputBroker, error) {
+	if len(inputs) == 0 {
+		return nil, errors.New("priority broker requires at least one input")
+	}
+	if len(inputs) != len(priorities) {
+		return nil, errors.New("priority broker requires a priority for each input")
+	}
+
+	i := &priorityInputBroker{
+		transactions: make(chan message.Transaction),
+
+		closables:         inputs,
+		priorities:        priorities,
+		starvationTimeout: starvationTimeout,
+
+		arrivals:     make(chan priorityArrival),
+		remainingMap: make(map[int]struct{}),
+
+		shutSig: shutdown.NewSignaller(),
+	}
+
+	for n := range inputs {
+		i.remainingMap[n] = struct{}{}
+		i.release = append(i.release, make(chan struct{}, 1))
+		go i.consume(n)
+	}
+
+	go i.loop()
+	return i, nil
+}
+
+// consume reads transactions from an input and offers them to the main loop
+// one at a time, waiting for each to be delivered before reading the next.
+func (i *priorityInputBroker) consume(index int) {
+	defer func() {
+		select {
+		case i.arrivals <- priorityArrival{index: index, closed: true}:
+		case <-i.shutSig.HardStopChan():
+		}
+	}()
+
+	for {
+		var tran message.Transaction
+		var open bool
+		select {
+		case tran, open = <-i.closables[index].TransactionChan():
+			if !open {
+				return
+			}
+		case <-i.shutSig.HardStopChan():
+			return
+		}
+		select {
+		case i.arrivals <- priorityArrival{index: index, tran: tran, at: time.Now()}:
+		case <-i.shutSig.HardStopChan():
+			return
+		}
+		select {
+		case <-i.release[index]:
+		case <-i.shutSig.HardStopChan():
+			return
+		}
+	}
+}
+
+func (i *priorityInputBroker) TransactionChan() <-chan message.Transaction {
+	return i.transactions
+}
+
+func (i *priorityInputBroker) Connected() bool {
+	i.remainingMapMut.Lock()
+	defer i.remainingMapMut.Unlock()
+
+	if len(i.remainingMap) == 0 {
+		return false
+	}
+
+	for index := range i.remainingMap {
+		if !i.closables[index].Connected() {
+			return false
+		}
+	}
+	return true
+}
+
+// choose returns the index of the held transaction that should be delivered
+// next, or -1 if there are none, along with the period of time until a
+// transaction that isn't chosen would be starved.
+func (i *priorityInputBroker) choose(held []*priorityArrival, now time.Time) (chosen int, untilStarved time.Duration) {
+	chosen, untilStarved = -1, -1
+
+	// The longest waiting transaction beyond the starvation timeout is chosen
+	// regardless of priority.
+	if i.starvationTimeout > 0 {
+		for n, h := range held {
+			if h == nil || now.Sub(h.at) < i.starvationTimeout {
+				continue
+			}
+			if chosen == -1 || h.at.Before(held[chosen].at) {
+				chosen = n
+			}
+		}
+		if chosen != -1 {
+			return
+		}
+	}
+
+	for n, h := range held {
+		if h == nil {
+			continue
+		}
+		if chosen == -1 ||
+			i.priorities[n] < i.priorities[chosen] ||
+			(i.priorities[n] == i.priorities[chosen] && h.at.Before(held[chosen].at)) {
+			chosen = n
+		}
+	}
+
+	if i.starvationTimeout > 0 {
+		for n, h := range held {
+			if h == nil || n == chosen {
+				continue
+			}
+			if until := i.starvationTimeout - now.Sub(h.at); until > 0 && (untilStarved == -1 || until < untilStarved) {
+				untilStarved = until
+			}
+		}
+	}
+	return
+}
+
+func (i *priorityInputBroker) loop() {
+	defer func() {
+		close(i.transactions)
+		i.shutSig.TriggerHasStopped()
+	}()
+
+	starveTimer := time.NewTimer(time.Hour)
+	starveTimer.Stop()
+	defer starveTimer.Stop()
+
+	held := make([]*priorityArrival, len(i.closables))
+	remaining := len(i.closables)
+
+	onArrival := func(a priorityArrival) {
+		if !a.closed {
+			held[a.index] = &a
+			return
+		}
+		i.remainingMapMut.Lock()
+		delete(i.remainingMap, a.index)
+		i.remainingMapMut.Unlock()
+		remaining--
+	}
+
+	for {
+		chosen, untilStarved := i.choose(held, time.Now())
+		if chosen == -1 {
+			if remaining == 0 {
+				return
+			}
+			select {
+			case a := <-i.arrivals:
+				onArrival(a)
+			case <-i.shutSig.HardStopChan():
+				return
+			}
+			continue
+		}
+
+		// Transactions that are already starved are delivered in turn, and
+		// therefore we only wait for those that are yet to be.
+		var starvedChan <-chan time.Time
+		if untilStarved > 0 {
+			starveTimer.Reset(untilStarved)
+			starvedChan = starveTimer.C
+		}
+
+		select {
+		case i.transactions <- held[chosen].tran:
+			held[chosen] = nil
+			i.release[chosen] <- struct{}{}
+		case a := <-i.arrivals:
+			onArrival(a)
+		case <-starvedChan:
+		case <-i.shutSig.HardStopChan():
+			return
+		}
+
+		if starvedChan != nil && !starveTimer.Stop() {
+			select {
+			case <-starveTimer.C:
+			default:
+			}
+		}
+	}
+}
+
+func (i *priorityInputBroker) TriggerStopConsuming() {
+	for _, closable := range i.closables {
+		closable.TriggerStopConsuming()
+	}
+}
+
+func (i *priorityInputBroker) TriggerCloseNow() {
+	for _, closable := range i.closables {
+		closable.TriggerCloseNow()
+	}
+	i.shutSig.TriggerHardStop()
+}
+
+func (i *priorityInputBroker) WaitForClose(ctx context.Context) error {
+	select {
+	case <-i.shutSig.HasStoppedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
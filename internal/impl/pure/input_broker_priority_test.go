@@ -0,0 +1,120 @@
+package pure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/component/input"
+	"github.com/redpanda-data/benthos/v4/internal/manager/mock"
+	"github.com/redpanda-data/benthos/v4/internal/message"
+)
+
+var _ input.Streamed = &priorityInputBroker{}
+
+func sendPriorityTestTran(t *testing.T, in *mock.Input, content string) {
+	t.Helper()
+
+	select {
+	case in.TChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte(content)}), make(chan error, 1)):
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for broker to consume")
+	}
+}
+
+func readPriorityTestTran(t *testing.T, b input.Streamed) string {
+	t.Helper()
+
+	select {
+	case tran, open := <-b.TransactionChan():
+		require.True(t, open)
+		return string(tran.Payload.Get(0).AsBytes())
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for broker transaction")
+	}
+	return ""
+}
+
+func TestPriorityInputBrokerOrdering(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	low := &mock.Input{TChan: make(chan message.Transaction)}
+	high := &mock.Input{TChan: make(chan message.Transaction)}
+
+	b, err := newPriorityInputBroker([]input.Streamed{low, high}, []int{1, 0}, 0)
+	require.NoError(t, err)
+
+	// Allow both inputs to have a transaction held by the broker before
+	// consuming.
+	sendPriorityTestTran(t, low, "low 1")
+	sendPriorityTestTran(t, high, "high 1")
+	time.Sleep(time.Millisecond * 50)
+
+	assert.Equal(t, "high 1", readPriorityTestTran(t, b))
+
+	sendPriorityTestTran(t, high, "high 2")
+	time.Sleep(time.Millisecond * 50)
+
+	assert.Equal(t, "high 2", readPriorityTestTran(t, b))
+	assert.Equal(t, "low 1", readPriorityTestTran(t, b))
+
+	low.TriggerStopConsuming()
+	high.TriggerStopConsuming()
+
+	select {
+	case _, open := <-b.TransactionChan():
+		assert.False(t, open)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for broker to close")
+	}
+	require.NoError(t, b.WaitForClose(tCtx))
+}
+
+func TestPriorityInputBrokerStarvation(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	low := &mock.Input{TChan: make(chan message.Transaction)}
+	high := &mock.Input{TChan: make(chan message.Transaction)}
+
+	b, err := newPriorityInputBroker([]input.Streamed{high, low}, []int{0, 1}, time.Millisecond*100)
+	require.NoError(t, err)
+
+	start := time.Now()
+	sendPriorityTestTran(t, low, "low")
+
+	// Keep the high priority input busy for longer than the starvation
+	// timeout, the low priority transaction must be delivered part way.
+	stopFeeding, feederDone := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(feederDone)
+		for {
+			select {
+			case high.TChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("high")}), make(chan error, 1)):
+			case <-stopFeeding:
+				return
+			}
+		}
+	}()
+
+	time.Sleep(time.Millisecond * 20)
+	for {
+		content := readPriorityTestTran(t, b)
+		if content == "low" {
+			break
+		}
+		require.Less(t, time.Since(start), time.Second*5)
+		time.Sleep(time.Millisecond)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*100)
+
+	close(stopFeeding)
+	<-feederDone
+
+	b.TriggerCloseNow()
+	require.NoError(t, b.WaitForClose(tCtx))
+}
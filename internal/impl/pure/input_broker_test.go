@@ -56,6 +56,27 @@ broker:
 				"hello world 2": 2,
 			},
 		},
+		{
+			name: "priority pattern with copies",
+			config: `
+broker:
+  pattern: priority
+  copies: 2
+  inputs:
+    - generate:
+        count: 2
+        interval: ""
+        mapping: 'root = "hello world 1"'
+    - generate:
+        count: 1
+        interval: ""
+        mapping: 'root = "hello world 2"'
+`,
+			output: map[string]int{
+				"hello world 1": 4,
+				"hello world 2": 2,
+			},
+		},
 		{
 			name: "input processors",
 			config: `
@@ -2,14 +2,19 @@ package pure
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/redpanda-data/benthos/v4/internal/component"
 	"github.com/redpanda-data/benthos/v4/internal/manager/mock"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+	"github.com/redpanda-data/benthos/v4/public/service"
 )
 
 func testGenReader(t testing.TB, confStr string, args ...any) *generateReader {
@@ -221,3 +226,228 @@ interval: ""
 
 	require.NoError(t, b.Close(context.Background()))
 }
+
+func TestBloblangPersistedState(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	mgr := mock.NewManager()
+	mgr.Caches["state"] = map[string]mock.CacheItem{}
+
+	newReader := func() *generateReader {
+		pConf, err := genInputSpec().ParseYAML(`
+mapping: |
+  root.id = counter()
+  root.cursor = counter(min: 10) * 10
+interval: ""
+count: 5
+batch_size: 2
+state_cache: state
+`, nil)
+		require.NoError(t, err)
+
+		r, err := newGenerateReaderFromParsed(pConf, mgr)
+		require.NoError(t, err)
+		require.NoError(t, r.Connect(ctx))
+		return r
+	}
+
+	b := newReader()
+
+	m, aFn, err := b.ReadBatch(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, m.Len())
+	assert.Equal(t, `{"cursor":100,"id":1}`, string(m.Get(0).AsBytes()))
+	assert.Equal(t, `{"cursor":110,"id":2}`, string(m.Get(1).AsBytes()))
+
+	m2, aFn2, err := b.ReadBatch(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, m2.Len())
+	assert.Equal(t, `{"cursor":130,"id":4}`, string(m2.Get(1).AsBytes()))
+
+	// Only the first batch is acknowledged, and therefore the second batch is
+	// generated again after a restart.
+	require.NoError(t, aFn(ctx, nil))
+	require.NoError(t, aFn2(ctx, errors.New("nope")))
+	require.NoError(t, b.Close(ctx))
+
+	b = newReader()
+
+	m, aFn, err = b.ReadBatch(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, m.Len())
+	assert.Equal(t, `{"cursor":120,"id":3}`, string(m.Get(0).AsBytes()))
+	assert.Equal(t, `{"cursor":130,"id":4}`, string(m.Get(1).AsBytes()))
+	require.NoError(t, aFn(ctx, nil))
+
+	// Messages generated before the restart count towards the total.
+	m, aFn, err = b.ReadBatch(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, m.Len())
+	assert.Equal(t, `{"cursor":140,"id":5}`, string(m.Get(0).AsBytes()))
+	require.NoError(t, aFn(ctx, nil))
+
+	_, _, err = b.ReadBatch(ctx)
+	assert.ErrorIs(t, err, component.ErrTypeClosed)
+	require.NoError(t, b.Close(ctx))
+
+	// Once the count is reached restarts generate nothing further.
+	b = newReader()
+	_, _, err = b.ReadBatch(ctx)
+	assert.ErrorIs(t, err, component.ErrTypeClosed)
+}
+
+func TestBloblangClosesMappingState(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	var closedMut sync.Mutex
+	var closed int
+
+	bEnv := bloblang.NewEnvironment()
+	require.NoError(t, bEnv.RegisterFunctionV2("stateful", bloblang.NewPluginSpec().
+		State(func() (any, error) {
+			return "state", nil
+		}, func(context.Context, any) error {
+			closedMut.Lock()
+			closed++
+			closedMut.Unlock()
+			return nil
+		}),
+		func(args *bloblang.ParsedParams) (bloblang.Function, error) {
+			state, err := args.State()
+			if err != nil {
+				return nil, err
+			}
+			return func() (any, error) { return state, nil }, nil
+		}))
+
+	env := service.NewEnvironment()
+	env.UseBloblangEnvironment(bEnv)
+
+	builder := env.NewStreamBuilder()
+	require.NoError(t, builder.SetYAML(`
+input:
+  generate:
+    count: 1
+    interval: ""
+    mapping: 'root = stateful()'
+output:
+  drop: {}
+logger:
+  level: none
+`))
+
+	strm, err := builder.Build()
+	require.NoError(t, err)
+	require.NoError(t, strm.Run(ctx))
+
+	closedMut.Lock()
+	assert.Equal(t, 1, closed)
+	closedMut.Unlock()
+}
+
+func TestBloblangPersistedStateMissingCache(t *testing.T) {
+	pConf, err := genInputSpec().ParseYAML(`
+mapping: 'root = "hello world"'
+state_cache: nope
+`, nil)
+	require.NoError(t, err)
+
+	_, err = newGenerateReaderFromParsed(pConf, mock.NewManager())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cache resource 'nope' was not found")
+}
+
+func TestBloblangRampUp(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	b := testGenReader(t, `
+mapping: 'root = "hello world"'
+interval: 10ms
+ramp_up: 200ms
+`)
+	require.NoError(t, b.Connect(ctx))
+	defer b.Close(ctx)
+
+	// The first batch is immediate, and the rate then increases linearly such
+	// that the eleventh batch is due once the ramp up has completed.
+	start := time.Now()
+	_, _, err := b.ReadBatch(ctx)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Millisecond*30)
+
+	_, _, err = b.ReadBatch(ctx)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*60)
+
+	for i := 0; i < 9; i++ {
+		_, _, err = b.ReadBatch(ctx)
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*200)
+
+	// After the ramp up batches are generated at the interval.
+	rampedAt := time.Now()
+	for i := 0; i < 5; i++ {
+		_, _, err = b.ReadBatch(ctx)
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(rampedAt), time.Millisecond*40)
+	assert.Less(t, time.Since(rampedAt), time.Millisecond*150)
+}
+
+func TestBloblangWindow(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	b := testGenReader(t, `
+mapping: 'root = "hello world"'
+interval: 20ms
+window:
+  schedule: '* * * * * *'
+  duration: 100ms
+`)
+	require.NoError(t, b.Connect(ctx))
+	defer b.Close(ctx)
+
+	// Windows begin each second and therefore messages should only be
+	// generated within the first 100ms of a second.
+	for i := 0; i < 10; i++ {
+		_, _, err := b.ReadBatch(ctx)
+		require.NoError(t, err)
+		assert.Less(t, time.Now().Nanosecond(), int(time.Millisecond*150))
+	}
+}
+
+func TestBloblangBadLoadConfig(t *testing.T) {
+	for _, test := range []struct {
+		conf string
+		err  string
+	}{
+		{
+			conf: `{ mapping: 'root = {}', interval: '@every 1s', ramp_up: 1m }`,
+			err:  "ramp_up requires an interval that is a duration",
+		},
+		{
+			conf: `{ mapping: 'root = {}', interval: '', ramp_up: 1m }`,
+			err:  "ramp_up requires an interval that is a duration",
+		},
+		{
+			conf: `{ mapping: 'root = {}', window: { schedule: 'nope' } }`,
+			err:  "failed to parse window schedule",
+		},
+		{
+			conf: `{ mapping: 'root = {}', window: { schedule: '0 * * * *', duration: 0s } }`,
+			err:  "window duration must be greater than zero",
+		},
+	} {
+		pConf, err := genInputSpec().ParseYAML(test.conf, nil)
+		require.NoError(t, err)
+
+		_, err = newGenerateReaderFromParsed(pConf, mock.NewManager())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), test.err)
+	}
+}
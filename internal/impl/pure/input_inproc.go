@@ -2,18 +2,27 @@ package pure
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/Jeffail/shutdown"
 
 	"github.com/redpanda-data/benthos/v4/internal/bundle"
+	"github.com/redpanda-data/benthos/v4/internal/component"
 	"github.com/redpanda-data/benthos/v4/internal/component/interop"
 	"github.com/redpanda-data/benthos/v4/internal/component/metrics"
 	"github.com/redpanda-data/benthos/v4/internal/log"
 	"github.com/redpanda-data/benthos/v4/internal/message"
+	"github.com/redpanda-data/benthos/v4/internal/value"
 	"github.com/redpanda-data/benthos/v4/public/service"
 )
 
+const (
+	iiFieldID           = "id"
+	iiFieldSubscription = "subscription"
+	iiFieldBuffer       = "buffer"
+)
+
 func inprocInputSpec() *service.ConfigSpec {
 	return service.NewConfigSpec().
 		Stable().
@@ -21,25 +30,45 @@ func inprocInputSpec() *service.ConfigSpec {
 		Description(`
 Directly connect to an output within a Benthos process by referencing it by a chosen ID. This allows you to hook up isolated streams whilst running Benthos in ` + "xref:guides:streams_mode/about.adoc[streams mode]" + `, it is NOT recommended that you connect the inputs of a stream with an output of the same stream, as feedback loops can lead to deadlocks in your message flow.
 
-It is possible to connect multiple inputs to the same inproc ID, resulting in messages dispatching in a round-robin fashion to connected inputs. However, only one output can assume an inproc ID, and will replace existing outputs if a collision occurs.`).
-		Field(service.NewStringField("").Default(""))
+It is possible to connect multiple inputs to the same inproc ID, resulting in messages dispatching in a round-robin fashion to connected inputs. However, only one output can assume an inproc ID, and will replace existing outputs if a collision occurs.
+
+== Subscriptions
+
+The config of this input is either the inproc ID as a string, or an object with the following fields:
+
+` + "```yaml" + `
+input:
+  inproc:
+    id: foo # The inproc ID to connect to
+    subscription: bar # The name of a subscription, defaults to an empty string
+    buffer: 0 # The number of messages to buffer for the subscription
+` + "```" + `
+
+Each subscription of an inproc ID receives every message sent to it, and inputs that share a subscription, including those configured with a string, receive messages in a round-robin fashion. This makes it possible to broadcast messages from one stream to many. A message is only acknowledged by the output once all subscriptions have acknowledged it, and therefore a slow subscription applies back pressure to the output once its buffer is full.`).
+		Field(service.NewAnyField("").Default("").
+			LintRule(`root = if this.type() == "object" {
+  this.keys().filter(k -> !["id", "subscription", "buffer"].contains(k)).map_each(k -> "field %v is not recognised".format(k))
+}`))
 }
 
 func init() {
 	err := service.RegisterBatchInput("inproc", inprocInputSpec(), func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
-		name, err := conf.FieldString()
+		v, err := conf.FieldAny()
 		if err != nil {
 			return nil, err
 		}
 		nm := interop.UnwrapManagement(mgr)
 		inprocRdr := &inprocInput{
-			pipe:         name,
 			mgr:          nm,
 			log:          nm.Logger(),
 			stats:        nm.Metrics(),
 			transactions: make(chan message.Transaction),
 			shutSig:      shutdown.NewSignaller(),
 		}
+		if err := inprocRdr.parseConfig(v); err != nil {
+			return nil, err
+		}
+		inprocRdr.subChan = nm.SubscribePipe(inprocRdr.pipe, inprocRdr.subscription, inprocRdr.buffer)
 		go inprocRdr.loop()
 		return interop.NewUnwrapInternalInput(inprocRdr), nil
 	})
@@ -51,7 +80,11 @@ func init() {
 //------------------------------------------------------------------------------
 
 type inprocInput struct {
-	pipe  string
+	pipe         string
+	subscription string
+	buffer       int
+	subChan      <-chan message.Transaction
+
 	mgr   bundle.NewManagement
 	stats metrics.Type
 	log   log.Modular
@@ -61,44 +94,82 @@ type inprocInput struct {
 	shutSig *shutdown.Signaller
 }
 
+func (i *inprocInput) parseConfig(v any) error {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case string:
+		i.pipe = t
+		return nil
+	case map[string]any:
+	default:
+		return fmt.Errorf("expected either a string or an object, got %T", v)
+	}
+
+	var err error
+	obj := v.(map[string]any)
+	if i.pipe, err = value.IGetString(obj[iiFieldID]); err != nil {
+		return fmt.Errorf("field %v: %w", iiFieldID, err)
+	}
+	if s, exists := obj[iiFieldSubscription]; exists {
+		if i.subscription, err = value.IGetString(s); err != nil {
+			return fmt.Errorf("field %v: %w", iiFieldSubscription, err)
+		}
+	}
+	if b, exists := obj[iiFieldBuffer]; exists {
+		buffer, err := value.IGetInt(b)
+		if err != nil {
+			return fmt.Errorf("field %v: %w", iiFieldBuffer, err)
+		}
+		if buffer < 0 {
+			return fmt.Errorf("field %v: must not be negative", iiFieldBuffer)
+		}
+		i.buffer = int(buffer)
+	}
+	return nil
+}
+
 func (i *inprocInput) loop() {
 	defer func() {
+		i.mgr.UnsubscribePipe(i.pipe, i.subscription)
 		close(i.transactions)
 		i.shutSig.TriggerHasStopped()
 	}()
 
 	var inprocChan <-chan message.Transaction
 
-messageLoop:
-	for !i.shutSig.IsSoftStopSignalled() {
-		if inprocChan == nil {
-			for {
-				var err error
-				if inprocChan, err = i.mgr.GetPipe(i.pipe); err != nil {
-					i.log.Error("Failed to connect to inproc output '%v': %v\n", i.pipe, err)
-					select {
-					case <-time.After(time.Second):
-					case <-i.shutSig.SoftStopChan():
-						return
-					}
-				} else {
-					i.log.Info("Receiving inproc messages from ID: %s\n", i.pipe)
-					break
-				}
-			}
-		}
+	// Outputs deliver transactions to subscriptions, but the pipe itself is
+	// also consumed as other components (such as stream builder producers) write
+	// directly to it.
+	pipeRetry := time.After(0)
+
+	for {
+		var t message.Transaction
+		var open bool
 		select {
-		case t, open := <-inprocChan:
+		case t = <-i.subChan:
+		case t, open = <-inprocChan:
 			if !open {
 				inprocChan = nil
-				continue messageLoop
+				pipeRetry = time.After(time.Second)
+				continue
 			}
-			select {
-			case i.transactions <- t:
-			case <-i.shutSig.SoftStopChan():
-				return
+		case <-pipeRetry:
+			pipeRetry = nil
+			var err error
+			if inprocChan, err = i.mgr.GetPipe(i.pipe); err != nil {
+				pipeRetry = time.After(time.Second)
+			} else {
+				i.log.Info("Receiving inproc messages from ID: %s\n", i.pipe)
 			}
+			continue
+		case <-i.shutSig.SoftStopChan():
+			return
+		}
+		select {
+		case i.transactions <- t:
 		case <-i.shutSig.SoftStopChan():
+			_ = t.Ack(context.Background(), component.ErrTypeClosed)
 			return
 		}
 	}
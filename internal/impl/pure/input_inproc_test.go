@@ -5,9 +5,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/redpanda-data/benthos/v4/internal/component/input"
+	"github.com/redpanda-data/benthos/v4/internal/component/testutil"
 	"github.com/redpanda-data/benthos/v4/internal/manager"
+	"github.com/redpanda-data/benthos/v4/internal/manager/mock"
 	"github.com/redpanda-data/benthos/v4/internal/message"
 )
 
@@ -51,3 +55,97 @@ inproc: foo
 	ip.TriggerStopConsuming()
 	require.NoError(t, ip.WaitForClose(ctx))
 }
+
+func TestInprocBroadcast(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	mgr := mock.NewManager()
+
+	newInproc := func(conf string) input.Streamed {
+		iConf, err := testutil.InputFromYAML(conf)
+		require.NoError(t, err)
+
+		i, err := mgr.NewInput(iConf)
+		require.NoError(t, err)
+		return i
+	}
+
+	// Two inputs share the default subscription, and two have their own.
+	sharedA, sharedB := newInproc(`inproc: foo`), newInproc(`inproc: { id: foo }`)
+	subA := newInproc(`inproc: { id: foo, subscription: a }`)
+	subB := newInproc(`inproc: { id: foo, subscription: b, buffer: 10 }`)
+
+	oConf, err := testutil.OutputFromYAML(`inproc: foo`)
+	require.NoError(t, err)
+
+	out, err := mgr.NewOutput(oConf)
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, out.Consume(tChan))
+
+	resChan := make(chan error, 1)
+	select {
+	case tChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello world")}), resChan):
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+
+	readAndAck := func(i input.Streamed) {
+		t.Helper()
+		select {
+		case tran := <-i.TransactionChan():
+			assert.Equal(t, "hello world", string(tran.Payload.Get(0).AsBytes()))
+			require.NoError(t, tran.Ack(ctx, nil))
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+		}
+	}
+
+	readAndAck(subB)
+	readAndAck(subA)
+
+	// Only one of the inputs sharing a subscription receives the message.
+	select {
+	case tran := <-sharedA.TransactionChan():
+		require.NoError(t, tran.Ack(ctx, nil))
+	case tran := <-sharedB.TransactionChan():
+		require.NoError(t, tran.Ack(ctx, nil))
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+
+	select {
+	case err := <-resChan:
+		require.NoError(t, err)
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+
+	close(tChan)
+	require.NoError(t, out.WaitForClose(ctx))
+
+	for _, i := range []input.Streamed{sharedA, sharedB, subA, subB} {
+		i.TriggerStopConsuming()
+		require.NoError(t, i.WaitForClose(ctx))
+	}
+}
+
+func TestInprocBadConfig(t *testing.T) {
+	for _, test := range []struct {
+		conf   string
+		errStr string
+	}{
+		{conf: `inproc: { subscription: a }`, errStr: "field id"},
+		{conf: `inproc: { id: foo, buffer: -1 }`, errStr: "must not be negative"},
+		{conf: `inproc: [ foo ]`, errStr: "expected either a string or an object"},
+	} {
+		iConf, err := testutil.InputFromYAML(test.conf)
+		require.NoError(t, err)
+
+		_, err = mock.NewManager().NewInput(iConf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), test.errStr)
+	}
+}
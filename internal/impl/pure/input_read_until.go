@@ -24,18 +24,21 @@ const (
 	ruiFieldRestart     = "restart_input"
 	ruiFieldCheck       = "check"
 	ruiFieldIdleTimeout = "idle_timeout"
+	ruiFieldMaxCount    = "max_count"
 )
 
 func readUntilInputSpec() *service.ConfigSpec {
 	return service.NewConfigSpec().
 		Stable().
 		Categories("Utility").
-		Summary("Reads messages from a child input until a consumed message passes a xref:guides:bloblang/about.adoc[Bloblang query], at which point the input closes. It is also possible to configure a timeout after which the input is closed if no new messages arrive in that period.").
+		Summary("Reads messages from a child input until a consumed message passes a xref:guides:bloblang/about.adoc[Bloblang query], at which point the input closes. It is also possible to configure a timeout after which the input is closed if no new messages arrive in that period, and a maximum number of messages to consume.").
 		Description(`
 Messages are read continuously while the query check returns false, when the query returns true the message that triggered the check is sent out and the input is closed. Use this to define inputs where the stream should end once a certain message appears.
 
 If the idle timeout is configured, the input will be closed if no new messages arrive after that period of time. Use this field if you want to empty out and close an input that doesn't have a logical end.
 
+If the max count is configured, the input will be closed once that number of messages has been consumed, where the final message is treated the same as a message that passes the query check. For inputs that produce batches each batch is counted as a single message. When more than one of these conditions is configured the input closes as soon as any of them is met.
+
 Sometimes inputs close themselves. For example, when the `+"`file`"+` input type reaches the end of a file it will shut down. By default this type will also shut down. If you wish for the input type to be restarted every time it shuts down until the query check is met then set `+"`restart_input` to `true`."+`
 
 == Metadata
@@ -43,12 +46,12 @@ Sometimes inputs close themselves. For example, when the `+"`file`"+` input type
 A metadata key `+"`benthos_read_until` containing the value `final`"+` is added to the first part of the message that triggers the input to stop.`).
 		Example(
 			"Consume N Messages",
-			"A common reason to use this input is to consume only N messages from an input and then stop. This can easily be done with the xref:guides:bloblang/functions.adoc#count[`count` function]:",
+			"A common reason to use this input is to consume only N messages from an input and then stop. This can easily be done with the `max_count` field:",
 			`
 # Only read 100 messages, and then exit.
 input:
   read_until:
-    check: count("messages") >= 100
+    max_count: 100
     input:
       kafka:
         addresses: [ TODO ]
@@ -84,6 +87,11 @@ input:
 			Description("The maximum amount of time without receiving new messages after which the input is closed.").
 			Example("5s").
 			Optional(),
+		service.NewIntField(ruiFieldMaxCount).
+			Description("The maximum number of messages to consume, after which the input is closed.").
+			Example(100).
+			Optional().
+			Version("4.29.0"),
 		service.NewBoolField(ruiFieldRestart).
 			Description("Whether the input should be reopened if it closes itself before the condition has resolved to true.").
 			Default(false),
@@ -110,6 +118,7 @@ type readUntilInput struct {
 	wrappedInputLocked *atomic.Pointer[input.Streamed]
 	check              *mapping.Executor
 	idleTimeout        time.Duration
+	maxCount           int
 
 	wrappedCtor func() (input.Streamed, error)
 
@@ -155,8 +164,18 @@ func newReadUntilInputFromParsed(conf *service.ParsedConfig, res *service.Resour
 		}
 	}
 
-	if check == nil && idleTimeout < 0 {
-		return nil, errors.New("it is required to set either check or idle_timeout")
+	var maxCount int
+	if conf.Contains(ruiFieldMaxCount) {
+		if maxCount, err = conf.FieldInt(ruiFieldMaxCount); err != nil {
+			return nil, err
+		}
+		if maxCount <= 0 {
+			return nil, errors.New("max_count must be greater than zero")
+		}
+	}
+
+	if check == nil && idleTimeout < 0 && maxCount == 0 {
+		return nil, errors.New("it is required to set either check, idle_timeout or max_count")
 	}
 
 	wInputLocked := &atomic.Pointer[input.Streamed]{}
@@ -170,6 +189,7 @@ func newReadUntilInputFromParsed(conf *service.ParsedConfig, res *service.Resour
 		log:          mgr.Logger(),
 		check:        check,
 		idleTimeout:  idleTimeout,
+		maxCount:     maxCount,
 		transactions: make(chan message.Transaction),
 
 		shutSig: shutdown.NewSignaller(),
@@ -200,6 +220,7 @@ func (r *readUntilInput) loop() {
 	restartBackoff.MaxElapsedTime = 0
 
 	var open bool
+	var count int
 
 	closeCtx, done := r.shutSig.SoftStopCtx(context.Background())
 	defer done()
@@ -249,9 +270,11 @@ runLoop:
 			}
 		}
 
+		count++
+
 		var err error
-		check := false
-		if r.check != nil {
+		check := r.maxCount > 0 && count >= r.maxCount
+		if !check && r.check != nil {
 			check, err = r.check.QueryPart(0, tran.Payload)
 			if err != nil {
 				check = false
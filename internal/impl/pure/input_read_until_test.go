@@ -27,7 +27,7 @@ read_until:
 	require.NoError(t, err)
 
 	_, err = bmock.NewManager().NewInput(conf)
-	assert.EqualError(t, err, "failed to init input <no label>: it is required to set either check or idle_timeout")
+	assert.EqualError(t, err, "failed to init input <no label>: it is required to set either check, idle_timeout or max_count")
 }
 
 func TestReadUntilInput(t *testing.T) {
@@ -294,3 +294,58 @@ read_until:
 	_, open = <-strm.TransactionChan()
 	require.False(t, open)
 }
+
+func TestReadUntilMaxCount(t *testing.T) {
+	conf, err := testutil.InputFromYAML(`
+read_until:
+  max_count: 3
+  input:
+    generate:
+      interval: 1ms
+      mapping: 'root.id = counter()'
+`)
+	require.NoError(t, err)
+
+	strm, err := bmock.NewManager().NewInput(conf)
+	require.NoError(t, err)
+
+	for i := 1; i <= 3; i++ {
+		tran, open := <-strm.TransactionChan()
+		require.True(t, open)
+		require.Len(t, tran.Payload, 1)
+		assert.Equal(t, fmt.Sprintf(`{"id":%v}`, i), string(tran.Payload[0].AsBytes()))
+
+		_, isFinal := tran.Payload[0].MetaGetMut("benthos_read_until")
+		assert.Equal(t, i == 3, isFinal)
+		require.NoError(t, tran.Ack(context.Background(), nil))
+	}
+
+	_, open := <-strm.TransactionChan()
+	require.False(t, open)
+}
+
+func TestReadUntilMaxCountWithCheck(t *testing.T) {
+	conf, err := testutil.InputFromYAML(`
+read_until:
+  max_count: 10
+  check: this.id == 2
+  input:
+    generate:
+      interval: 1ms
+      mapping: 'root.id = counter()'
+`)
+	require.NoError(t, err)
+
+	strm, err := bmock.NewManager().NewInput(conf)
+	require.NoError(t, err)
+
+	for i := 1; i <= 2; i++ {
+		tran, open := <-strm.TransactionChan()
+		require.True(t, open)
+		assert.Equal(t, fmt.Sprintf(`{"id":%v}`, i), string(tran.Payload[0].AsBytes()))
+		require.NoError(t, tran.Ack(context.Background(), nil))
+	}
+
+	_, open := <-strm.TransactionChan()
+	require.False(t, open)
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/Jeffail/shutdown"
 
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/mapping"
 	"github.com/redpanda-data/benthos/v4/internal/component/input"
 	"github.com/redpanda-data/benthos/v4/internal/component/interop"
 	"github.com/redpanda-data/benthos/v4/internal/message"
@@ -25,6 +26,8 @@ const (
 	siFieldShardedJoinMergeStrategy = "merge_strategy"
 	siFieldShardedJoin              = "sharded_join"
 	siFieldInputs                   = "inputs"
+	siFieldStrategy                 = "strategy"
+	siFieldMergeKey                 = "merge_key"
 )
 
 func sequenceInputSpec() *service.ConfigSpec {
@@ -32,7 +35,23 @@ func sequenceInputSpec() *service.ConfigSpec {
 		Stable().
 		Categories("Utility").
 		Summary("Reads messages from a sequence of child inputs, starting with the first and once that input gracefully terminates starts consuming from the next, and so on.").
-		Description("This input is useful for consuming from inputs that have an explicit end but must not be consumed in parallel.").
+		Description(`This input is useful for consuming from inputs that have an explicit end but must not be consumed in parallel.
+
+== Strategies
+
+By default inputs are consumed strictly in order, but the field `+"`strategy`"+` can be used in order to consume from all inputs at once:
+
+=== `+"`sequential`"+`
+
+Inputs are consumed one at a time, and each input is only created once the input before it has gracefully terminated.
+
+=== `+"`interleave`"+`
+
+All inputs are created at once and a message is taken from each input in turn, where inputs that have terminated are skipped. An input that is slow to yield a message blocks the others until it does.
+
+=== `+"`merge`"+`
+
+All inputs are created at once and, of the messages that are next in each input, the one with the lowest `+"`merge_key`"+` is consumed first. When each input yields messages in the order of the key, such as historical data sorted by time, the result is a single stream in the order of the key. Since a message must be waiting from every input in order to choose the lowest, an input that is slow to yield a message blocks the others until it does. Messages where the key cannot be determined are consumed immediately.`).
 		Fields(
 			service.NewObjectField(siFieldShardedJoin,
 				// TODO: V5 Remove "full-outter" and "outter"
@@ -58,6 +77,21 @@ Each message must be structured (JSON or otherwise processed into a structured f
 				Advanced(),
 			service.NewInputListField(siFieldInputs).
 				Description("An array of inputs to read from sequentially."),
+			service.NewStringAnnotatedEnumField(siFieldStrategy, map[string]string{
+				"sequential": "Consume inputs one at a time in the order that they are listed.",
+				"interleave": "Consume from all inputs at once, taking a message from each input in turn.",
+				"merge":      "Consume from all inputs at once, taking the message with the lowest `merge_key` from those that are next in each input.",
+			}).
+				Description("The strategy to use for consuming from the inputs, the `sharded_join` field can only be used with the `sequential` strategy.").
+				Version("4.29.0").
+				Advanced().
+				Default("sequential"),
+			service.NewBloblangField(siFieldMergeKey).
+				Description("A xref:guides:bloblang/about.adoc[Bloblang query] executed on the first message of each batch that returns a key by which messages are ordered when using the `merge` strategy. The key must be a number, timestamp or string.").
+				Examples(`this.timestamp.ts_parse("2006-01-02T15:04:05Z07:00")`, `this.sequence_id`).
+				Version("4.29.0").
+				Advanced().
+				Optional(),
 		).
 		Example(
 			"End of Stream Message",
@@ -73,6 +107,25 @@ input:
       - generate:
           count: 1
           mapping: 'root = {"status":"finished"}'
+`,
+		).
+		Example(
+			"Merging Historical Data",
+			"Historical data split across multiple sources, where each source is ordered by time, can be merged into a single stream that is also ordered by time by using the `merge` strategy:",
+			`
+input:
+  sequence:
+    strategy: merge
+    merge_key: this.timestamp.ts_parse("2006-01-02T15:04:05Z07:00")
+    inputs:
+      - file:
+          paths: [ ./eu_events.jsonl ]
+          scanner:
+            lines: {}
+      - file:
+          paths: [ ./us_events.jsonl ]
+          scanner:
+            lines: {}
 `,
 		).
 		Example(
@@ -356,6 +409,14 @@ func newSequenceInputFromParsed(conf *service.ParsedConfig, res *service.Resourc
 		return nil, errors.New("requires at least one child input")
 	}
 
+	strategy, err := conf.FieldString(siFieldStrategy)
+	if err != nil {
+		return nil, err
+	}
+	if strategy != "sequential" {
+		return newSequenceInterleaveInputFromParsed(conf, strategy, res)
+	}
+
 	targets := make([]sequenceTarget, 0, len(pInputConfs))
 	for i, c := range pInputConfs {
 		c := c
@@ -386,6 +447,46 @@ func newSequenceInputFromParsed(conf *service.ParsedConfig, res *service.Resourc
 	return rdr, nil
 }
 
+func newSequenceInterleaveInputFromParsed(conf *service.ParsedConfig, strategy string, res *service.Resources) (input.Streamed, error) {
+	if joiner, err := shardedConfigFromParsed(conf.Namespace(siFieldShardedJoin)); err != nil {
+		return nil, fmt.Errorf("invalid sharded join config: %w", err)
+	} else if joiner != nil {
+		return nil, fmt.Errorf("sharded joins cannot be used with the %v strategy", strategy)
+	}
+
+	var mergeKey *mapping.Executor
+	switch strategy {
+	case "interleave":
+		if conf.Contains(siFieldMergeKey) {
+			return nil, errors.New("a merge_key can only be used with the merge strategy")
+		}
+	case "merge":
+		if !conf.Contains(siFieldMergeKey) {
+			return nil, errors.New("the merge strategy requires a merge_key")
+		}
+		mergeKeyStr, err := conf.FieldString(siFieldMergeKey)
+		if err != nil {
+			return nil, err
+		}
+		if mergeKey, err = interop.UnwrapManagement(res).BloblEnvironment().NewMapping(mergeKeyStr); err != nil {
+			return nil, fmt.Errorf("failed to parse merge_key: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("strategy '%v' was not recognised", strategy)
+	}
+
+	children, err := conf.FieldInputList(siFieldInputs)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]input.Streamed, 0, len(children))
+	for _, c := range children {
+		inputs = append(inputs, interop.UnwrapOwnedInput(c))
+	}
+	return newSequenceInterleaveInput(inputs, mergeKey, res.Logger()), nil
+}
+
 func shardedConfigFromParsed(conf *service.ParsedConfig) (*messageJoiner, error) {
 	typeStr, err := conf.FieldString(siFieldShardedJoinType)
 	if err != nil {
@@ -0,0 +1,237 @@
+package pure
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jeffail/shutdown"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/mapping"
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/internal/component/input"
+	"github.com/redpanda-data/benthos/v4/internal/message"
+	"github.com/redpanda-data/benthos/v4/internal/value"
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+// sequenceInterleaveInput consumes from all inputs of a sequence at once,
+// either by taking a message from each input in turn, or when a merge key is
+// provided by always taking the message with the lowest key from those that
+// are next in each input.
+type sequenceInterleaveInput struct {
+	inputs   []input.Streamed
+	mergeKey *mapping.Executor
+
+	log *service.Logger
+
+	transactions chan message.Transaction
+
+	shutSig *shutdown.Signaller
+}
+
+func newSequenceInterleaveInput(inputs []input.Streamed, mergeKey *mapping.Executor, log *service.Logger) *sequenceInterleaveInput {
+	r := &sequenceInterleaveInput{
+		inputs:       inputs,
+		mergeKey:     mergeKey,
+		log:          log,
+		transactions: make(chan message.Transaction),
+		shutSig:      shutdown.NewSignaller(),
+	}
+	if mergeKey != nil {
+		go r.mergeLoop()
+	} else {
+		go r.roundRobinLoop()
+	}
+	return r
+}
+
+func (r *sequenceInterleaveInput) closeInputs() {
+	shutNowCtx, done := r.shutSig.HardStopCtx(context.Background())
+	defer done()
+
+	for _, in := range r.inputs {
+		in.TriggerStopConsuming()
+	}
+	for _, in := range r.inputs {
+		_ = in.WaitForClose(shutNowCtx)
+		in.TriggerCloseNow()
+	}
+	close(r.transactions)
+	r.shutSig.TriggerHasStopped()
+}
+
+func (r *sequenceInterleaveInput) roundRobinLoop() {
+	defer r.closeInputs()
+
+	active := append([]input.Streamed{}, r.inputs...)
+	for i := 0; len(active) > 0; {
+		var tran message.Transaction
+		var open bool
+		select {
+		case tran, open = <-active[i].TransactionChan():
+		case <-r.shutSig.SoftStopChan():
+			return
+		}
+		if !open {
+			active = append(active[:i], active[i+1:]...)
+			if i >= len(active) {
+				i = 0
+			}
+			continue
+		}
+
+		select {
+		case r.transactions <- tran:
+		case <-r.shutSig.HardStopChan():
+			return
+		}
+		i = (i + 1) % len(active)
+	}
+	r.log.Info("Exhausted all sequence inputs, shutting down.")
+}
+
+type sequenceMergeHead struct {
+	tran message.Transaction
+	key  any
+}
+
+func (r *sequenceInterleaveInput) queryMergeKey(batch message.Batch) (any, error) {
+	return r.mergeKey.Exec(query.FunctionContext{
+		Maps:     r.mergeKey.Maps(),
+		Vars:     map[string]any{},
+		MsgBatch: batch,
+		NewMeta:  batch.Get(0),
+	}.WithValueFunc(func() *any {
+		if jObj, err := batch.Get(0).AsStructured(); err == nil {
+			return &jObj
+		}
+		return nil
+	}))
+}
+
+// sequenceMergeKeyLess returns whether the left key is ordered before the
+// right, where keys must both be numbers, timestamps or strings.
+func sequenceMergeKeyLess(left, right any) (bool, error) {
+	switch l := left.(type) {
+	case time.Time:
+		r, err := value.IGetTimestamp(right)
+		if err != nil {
+			return false, err
+		}
+		return l.Before(r), nil
+	case string, []byte:
+		ls, _ := value.IGetString(l)
+		rs, err := value.IGetString(right)
+		if err != nil {
+			return false, err
+		}
+		return ls < rs, nil
+	}
+	if value.ITypeOf(left) != value.TNumber {
+		return false, value.NewTypeError(left, value.TNumber, value.TTimestamp, value.TString)
+	}
+	l, err := value.IGetNumber(left)
+	if err != nil {
+		return false, err
+	}
+	rn, err := value.IGetNumber(right)
+	if err != nil {
+		return false, err
+	}
+	return l < rn, nil
+}
+
+func (r *sequenceInterleaveInput) mergeLoop() {
+	defer r.closeInputs()
+
+	heads := make([]*sequenceMergeHead, len(r.inputs))
+	closed := make([]bool, len(r.inputs))
+
+	for {
+		// Every input that remains open must have a message ready in order to
+		// determine which has the lowest key.
+		for i, in := range r.inputs {
+			if closed[i] || heads[i] != nil {
+				continue
+			}
+			select {
+			case tran, open := <-in.TransactionChan():
+				if !open {
+					closed[i] = true
+					continue
+				}
+				key, err := r.queryMergeKey(tran.Payload)
+				if err != nil {
+					r.log.Errorf("Failed to execute merge key query, the message will be delivered without ordering: %v", err)
+				}
+				heads[i] = &sequenceMergeHead{tran: tran, key: key}
+			case <-r.shutSig.SoftStopChan():
+				return
+			}
+		}
+
+		next := -1
+		for i, h := range heads {
+			if h == nil {
+				continue
+			}
+			// Messages without a key are delivered as soon as possible.
+			if h.key == nil {
+				next = i
+				break
+			}
+			if next == -1 {
+				next = i
+				continue
+			}
+			less, err := sequenceMergeKeyLess(h.key, heads[next].key)
+			if err != nil {
+				r.log.Errorf("Failed to compare merge keys: %v", err)
+			}
+			if less {
+				next = i
+			}
+		}
+		if next == -1 {
+			r.log.Info("Exhausted all sequence inputs, shutting down.")
+			return
+		}
+
+		select {
+		case r.transactions <- heads[next].tran:
+		case <-r.shutSig.HardStopChan():
+			return
+		}
+		heads[next] = nil
+	}
+}
+
+func (r *sequenceInterleaveInput) TransactionChan() <-chan message.Transaction {
+	return r.transactions
+}
+
+func (r *sequenceInterleaveInput) Connected() bool {
+	for _, in := range r.inputs {
+		if !in.Connected() {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *sequenceInterleaveInput) TriggerStopConsuming() {
+	r.shutSig.TriggerSoftStop()
+}
+
+func (r *sequenceInterleaveInput) TriggerCloseNow() {
+	r.shutSig.TriggerHardStop()
+}
+
+func (r *sequenceInterleaveInput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-r.shutSig.HasStoppedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
@@ -490,3 +490,121 @@ sequence:
 	rdr.TriggerCloseNow()
 	assert.NoError(t, rdr.WaitForClose(ctx))
 }
+
+func TestSequenceStrategies(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeFiles(t, tmpDir, map[string]string{
+		"f1": `{"id":"a","ts":"2024-01-01T00:00:01Z"}
+{"id":"b","ts":"2024-01-01T00:00:04Z"}
+{"id":"c","ts":"2024-01-01T00:00:05Z"}`,
+		"f2": `{"id":"d","ts":"2024-01-01T00:00:02Z"}
+{"id":"e","ts":"2024-01-01T00:00:03Z"}
+{"id":"f","ts":"2024-01-01T00:00:06Z"}
+{"id":"g","ts":"2024-01-01T00:00:07Z"}`,
+	})
+
+	for _, test := range []struct {
+		name   string
+		config string
+		exp    []string
+	}{
+		{
+			name:   "interleave",
+			config: `strategy: interleave`,
+			exp:    []string{"a", "d", "b", "e", "c", "f", "g"},
+		},
+		{
+			name: "merge timestamps",
+			config: `strategy: merge
+  merge_key: this.ts.ts_parse("2006-01-02T15:04:05Z07:00")`,
+			exp: []string{"a", "d", "e", "b", "c", "f", "g"},
+		},
+		{
+			name: "merge strings",
+			config: `strategy: merge
+  merge_key: this.ts`,
+			exp: []string{"a", "d", "e", "b", "c", "f", "g"},
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+			defer done()
+
+			rdr := testInput(t, `
+sequence:
+  %v
+  inputs:
+    - file:
+        paths: [ "%v" ]
+    - file:
+        paths: [ "%v" ]
+`, test.config, filepath.Join(tmpDir, "f1"), filepath.Join(tmpDir, "f2"))
+
+			var act []string
+		consumeLoop:
+			for {
+				select {
+				case tran, open := <-rdr.TransactionChan():
+					if !open {
+						break consumeLoop
+					}
+					v, err := tran.Payload.Get(0).AsStructured()
+					require.NoError(t, err)
+					act = append(act, v.(map[string]any)["id"].(string))
+					require.NoError(t, tran.Ack(ctx, nil))
+				case <-ctx.Done():
+					t.Fatalf("Failed to consume message after: %v", act)
+				}
+			}
+			assert.Equal(t, test.exp, act)
+
+			rdr.TriggerStopConsuming()
+			require.NoError(t, rdr.WaitForClose(ctx))
+		})
+	}
+}
+
+func TestSequenceStrategiesBadConfig(t *testing.T) {
+	for _, test := range []struct {
+		config string
+		errStr string
+	}{
+		{
+			config: `
+sequence:
+  strategy: merge
+  inputs: [ { generate: { mapping: 'root = "foo"' } } ]
+`,
+			errStr: "the merge strategy requires a merge_key",
+		},
+		{
+			config: `
+sequence:
+  strategy: interleave
+  merge_key: this.id
+  inputs: [ { generate: { mapping: 'root = "foo"' } } ]
+`,
+			errStr: "a merge_key can only be used with the merge strategy",
+		},
+		{
+			config: `
+sequence:
+  strategy: interleave
+  sharded_join:
+    type: full-outer
+    id_path: id
+  inputs: [ { generate: { mapping: 'root = "foo"' } } ]
+`,
+			errStr: "sharded joins cannot be used with the interleave strategy",
+		},
+	} {
+		iConf, err := testutil.InputFromYAML(test.config)
+		require.NoError(t, err)
+
+		_, err = mock.NewManager().NewInput(iConf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), test.errStr)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/redpanda-data/benthos/v4/internal/batch/policy"
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/field"
 	"github.com/redpanda-data/benthos/v4/internal/component/interop"
 	"github.com/redpanda-data/benthos/v4/internal/component/output"
 	"github.com/redpanda-data/benthos/v4/internal/component/output/batcher"
@@ -16,6 +17,7 @@ const (
 	boFieldPattern  = "pattern"
 	boFieldOutputs  = "outputs"
 	boFieldBatching = "batching"
+	boFieldKey      = "key"
 )
 
 func brokerOutputSpec() *service.ConfigSpec {
@@ -74,16 +76,27 @@ With the round robin pattern each message will be assigned a single output follo
 
 === `+"`greedy`"+`
 
-The greedy pattern results in higher output throughput at the cost of potentially disproportionate message allocations to those outputs. Each message is sent to a single output, which is determined by allowing outputs to claim messages as soon as they are able to process them. This results in certain faster outputs potentially processing more messages at the cost of slower outputs.`).
+The greedy pattern results in higher output throughput at the cost of potentially disproportionate message allocations to those outputs. Each message is sent to a single output, which is determined by allowing outputs to claim messages as soon as they are able to process them. This results in certain faster outputs potentially processing more messages at the cost of slower outputs.
+
+=== `+"`by_key`"+`
+
+With the by key pattern each message is sent to a single output, which is determined by a hash of the `+"`key`"+` of the message. Messages of the same key are therefore always sent to the same output in the order in which they were consumed, which allows the order of messages of each key to be preserved whilst writing to multiple outputs in parallel. Messages of a batch are split across outputs by their key, and the batch is acknowledged once each output has acknowledged its messages.
+
+In order to strictly preserve the order of messages of each key the child outputs should not send multiple messages in parallel, which usually means setting their `+"`max_in_flight`"+` to `+"`1`"+`. If an output applies back pressure it will block all subsequent messages, and if an output fails to send a message then the failure is propagated upstream.`).
 		Fields(
 			service.NewIntField(boFieldCopies).
 				Description("The number of copies of each configured output to spawn.").
 				Advanced().
 				Default(1),
 			service.NewStringEnumField(boFieldPattern,
-				"fan_out", "fan_out_fail_fast", "fan_out_sequential", "fan_out_sequential_fail_fast", "round_robin", "greedy").
+				"fan_out", "fan_out_fail_fast", "fan_out_sequential", "fan_out_sequential_fail_fast", "round_robin", "greedy", "by_key").
 				Description("The brokering pattern to use.").
 				Default("fan_out"),
+			service.NewInterpolatedStringField(boFieldKey).
+				Description("An interpolated string that resolves the key of each message, which is required by the `by_key` pattern.").
+				Examples(`${! @kafka_key }`, `${! this.user.id }`).
+				Version("4.29.0").
+				Optional(),
 			service.NewOutputListField(boFieldOutputs).
 				Description("A list of child outputs to broker."),
 			service.NewBatchPolicyField(boFieldBatching),
@@ -125,6 +138,20 @@ func brokerOutputFromParsed(conf *service.ParsedConfig, res *service.Resources)
 		return nil, err
 	}
 
+	var key *field.Expression
+	if pattern == "by_key" {
+		if !conf.Contains(boFieldKey) {
+			return nil, fmt.Errorf("field '%v' must be set when the pattern is 'by_key'", boFieldKey)
+		}
+		keyStr, err := conf.FieldString(boFieldKey)
+		if err != nil {
+			return nil, err
+		}
+		if key, err = mgr.BloblEnvironment().NewField(keyStr); err != nil {
+			return nil, fmt.Errorf("failed to parse field '%v': %w", boFieldKey, err)
+		}
+	}
+
 	var batchPol *policy.Batcher
 	{
 		batchConf, err := conf.FieldBatchPolicy(boFieldBatching)
@@ -200,6 +227,8 @@ func brokerOutputFromParsed(conf *service.ParsedConfig, res *service.Resources)
 		b, err = newRoundRobinOutputBroker(outputs)
 	case "greedy":
 		b, err = newGreedyOutputBroker(outputs)
+	case "by_key":
+		b, err = newByKeyOutputBroker(key, outputs, mgr.Logger())
 	default:
 		return nil, fmt.Errorf("broker pattern was not recognised: %v", pattern)
 	}
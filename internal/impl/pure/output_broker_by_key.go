@@ -0,0 +1,210 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Jeffail/shutdown"
+	"github.com/OneOfOne/xxhash"
+
+	"github.com/redpanda-data/benthos/v4/internal/batch"
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/field"
+	"github.com/redpanda-data/benthos/v4/internal/component"
+	"github.com/redpanda-data/benthos/v4/internal/component/output"
+	"github.com/redpanda-data/benthos/v4/internal/log"
+	"github.com/redpanda-data/benthos/v4/internal/message"
+)
+
+// byKeyOutputBroker routes each message to a single output determined by a
+// hash of its key, and therefore messages of the same key are always written
+// to the same output in the order they were consumed.
+type byKeyOutputBroker struct {
+	log          log.Modular
+	transactions <-chan message.Transaction
+
+	key           *field.Expression
+	outputTSChans []chan message.Transaction
+	outputs       []output.Streamed
+
+	shutSig *shutdown.Signaller
+}
+
+func newByKeyOutputBroker(key *field.Expression, outputs []output.Streamed, logger log.Modular) (*byKeyOutputBroker, error) {
+	o := &byKeyOutputBroker{
+		log:     logger,
+		key:     key,
+		outputs: outputs,
+		shutSig: shutdown.NewSignaller(),
+	}
+	o.outputTSChans = make([]chan message.Transaction, len(o.outputs))
+	for i := range o.outputTSChans {
+		o.outputTSChans[i] = make(chan message.Transaction)
+		if err := o.outputs[i].Consume(o.outputTSChans[i]); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+func (o *byKeyOutputBroker) Consume(ts <-chan message.Transaction) error {
+	if o.transactions != nil {
+		return component.ErrAlreadyStarted
+	}
+	o.transactions = ts
+
+	go o.loop()
+	return nil
+}
+
+func (o *byKeyOutputBroker) Connected() bool {
+	for _, out := range o.outputs {
+		if !out.Connected() {
+			return false
+		}
+	}
+	return true
+}
+
+// outputIndex returns the index of the output that a key is routed to.
+func (o *byKeyOutputBroker) outputIndex(key string) int {
+	return int(xxhash.ChecksumString64(key) % uint64(len(o.outputs)))
+}
+
+// dispatch sends the messages of a batch to their outputs, where ackFn is
+// called once each output has acknowledged its messages.
+func (o *byKeyOutputBroker) dispatch(
+	group *message.SortGroup,
+	sourceMessage message.Batch,
+	outputTargets [][]*message.Part,
+	ackFn func(context.Context, error) error,
+) {
+	var errLock sync.Mutex
+	var batchErr *batch.Error
+	setErrForPart := func(part *message.Part, err error) {
+		errLock.Lock()
+		defer errLock.Unlock()
+
+		if batchErr == nil {
+			batchErr = batch.NewError(sourceMessage, err)
+		}
+		if index := group.GetIndex(part); index >= 0 {
+			batchErr.Failed(index, err)
+		}
+	}
+	getErr := func() error {
+		errLock.Lock()
+		defer errLock.Unlock()
+		if batchErr != nil {
+			return batchErr
+		}
+		return nil
+	}
+
+	var pendingResponses int64
+	for _, parts := range outputTargets {
+		if len(parts) > 0 {
+			pendingResponses++
+		}
+	}
+	if pendingResponses == 0 {
+		ctx, done := o.shutSig.HardStopCtx(context.Background())
+		defer done()
+		_ = ackFn(ctx, nil)
+		return
+	}
+
+	for target, parts := range outputTargets {
+		if len(parts) == 0 {
+			continue
+		}
+
+		parts := parts
+		select {
+		case o.outputTSChans[target] <- message.NewTransactionFunc(parts, func(ctx context.Context, err error) error {
+			if err != nil {
+				var bErr *batch.Error
+				if errors.As(err, &bErr) {
+					bErr.WalkPartsBySource(group, sourceMessage, func(i int, p *message.Part, e error) bool {
+						if e != nil {
+							setErrForPart(p, e)
+						}
+						return true
+					})
+				} else {
+					for _, p := range parts {
+						setErrForPart(p, err)
+					}
+				}
+			}
+			if atomic.AddInt64(&pendingResponses, -1) <= 0 {
+				return ackFn(ctx, getErr())
+			}
+			return nil
+		}):
+		case <-o.shutSig.HardStopChan():
+			return
+		}
+	}
+}
+
+func (o *byKeyOutputBroker) loop() {
+	defer func() {
+		for _, c := range o.outputTSChans {
+			close(c)
+		}
+		_ = closeAllOutputs(context.Background(), o.outputs)
+		o.shutSig.TriggerHasStopped()
+	}()
+
+	shutCtx, done := o.shutSig.HardStopCtx(context.Background())
+	defer done()
+
+	for {
+		var ts message.Transaction
+		var open bool
+		select {
+		case ts, open = <-o.transactions:
+			if !open {
+				return
+			}
+		case <-o.shutSig.HardStopChan():
+			return
+		}
+
+		group, trackedMsg := message.NewSortGroup(ts.Payload)
+
+		outputTargets := make([][]*message.Part, len(o.outputs))
+		if keyErr := trackedMsg.Iter(func(i int, p *message.Part) error {
+			key, err := o.key.String(i, trackedMsg)
+			if err != nil {
+				o.log.Error("Failed to resolve broker key: %v\n", err)
+				return err
+			}
+			j := o.outputIndex(key)
+			outputTargets[j] = append(outputTargets[j], p.ShallowCopy())
+			return nil
+		}); keyErr != nil {
+			if err := ts.Ack(shutCtx, keyErr); err != nil && shutCtx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		o.dispatch(group, trackedMsg, outputTargets, ts.Ack)
+	}
+}
+
+func (o *byKeyOutputBroker) TriggerCloseNow() {
+	o.shutSig.TriggerHardStop()
+}
+
+func (o *byKeyOutputBroker) WaitForClose(ctx context.Context) error {
+	select {
+	case <-o.shutSig.HasStoppedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/batch"
+	"github.com/redpanda-data/benthos/v4/internal/bloblang"
+	"github.com/redpanda-data/benthos/v4/internal/bundle"
+	"github.com/redpanda-data/benthos/v4/internal/component/output"
+	"github.com/redpanda-data/benthos/v4/internal/log"
+	"github.com/redpanda-data/benthos/v4/internal/manager/mock"
+	"github.com/redpanda-data/benthos/v4/internal/message"
+)
+
+var _ output.Streamed = &byKeyOutputBroker{}
+
+func testByKeyBroker(t *testing.T, nOutputs int) (*byKeyOutputBroker, []*mock.OutputChanneled, chan message.Transaction) {
+	t.Helper()
+
+	key, err := bloblang.GlobalEnvironment().NewField(`${! meta("key") }`)
+	require.NoError(t, err)
+
+	outputs := []output.Streamed{}
+	mockOutputs := []*mock.OutputChanneled{}
+	for i := 0; i < nOutputs; i++ {
+		mockOutputs = append(mockOutputs, &mock.OutputChanneled{})
+		outputs = append(outputs, mockOutputs[i])
+	}
+
+	oTM, err := newByKeyOutputBroker(key, outputs, log.Noop())
+	require.NoError(t, err)
+
+	readChan := make(chan message.Transaction)
+	require.NoError(t, oTM.Consume(readChan))
+	return oTM, mockOutputs, readChan
+}
+
+func keyedBatch(keys ...string) message.Batch {
+	b := make(message.Batch, len(keys))
+	for i, k := range keys {
+		b[i] = message.NewPart([]byte(fmt.Sprintf("%v-%v", k, i)))
+		b[i].MetaSetMut("key", k)
+	}
+	return b
+}
+
+func TestByKeyOrdering(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	oTM, mockOutputs, readChan := testByKeyBroker(t, 3)
+
+	keys := []string{"foo", "bar", "baz", "buz", "qux"}
+	resChan := make(chan error, 1)
+
+	for i := 0; i < 100; i++ {
+		k := keys[i%len(keys)]
+		content := fmt.Sprintf("%v %v", k, i)
+
+		msg := message.QuickBatch([][]byte{[]byte(content)})
+		msg.Get(0).MetaSetMut("key", k)
+
+		select {
+		case readChan <- message.NewTransaction(msg, resChan):
+		case <-tCtx.Done():
+			t.Fatal("timed out")
+		}
+
+		var ts message.Transaction
+		select {
+		case ts = <-mockOutputs[oTM.outputIndex(k)].TChan:
+		case <-tCtx.Done():
+			t.Fatal("timed out")
+		}
+		assert.Equal(t, content, string(ts.Payload.Get(0).AsBytes()))
+		require.NoError(t, ts.Ack(tCtx, nil))
+
+		select {
+		case res := <-resChan:
+			require.NoError(t, res)
+		case <-tCtx.Done():
+			t.Fatal("timed out")
+		}
+	}
+
+	oTM.TriggerCloseNow()
+	require.NoError(t, oTM.WaitForClose(tCtx))
+}
+
+func TestByKeySplitBatch(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	oTM, mockOutputs, readChan := testByKeyBroker(t, 2)
+
+	// Find two keys that are routed to different outputs
+	keyA, keyB := "a", ""
+	for i := 0; keyB == ""; i++ {
+		if k := fmt.Sprintf("b%v", i); oTM.outputIndex(k) != oTM.outputIndex(keyA) {
+			keyB = k
+		}
+	}
+
+	resChan := make(chan error, 1)
+	select {
+	case readChan <- message.NewTransaction(keyedBatch(keyA, keyB, keyA), resChan):
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	var tsA, tsB message.Transaction
+	for i := 0; i < 2; i++ {
+		select {
+		case tsA = <-mockOutputs[oTM.outputIndex(keyA)].TChan:
+		case tsB = <-mockOutputs[oTM.outputIndex(keyB)].TChan:
+		case <-tCtx.Done():
+			t.Fatal("timed out")
+		}
+	}
+
+	require.Len(t, tsA.Payload, 2)
+	assert.Equal(t, keyA+"-0", string(tsA.Payload.Get(0).AsBytes()))
+	assert.Equal(t, keyA+"-2", string(tsA.Payload.Get(1).AsBytes()))
+	require.Len(t, tsB.Payload, 1)
+	assert.Equal(t, keyB+"-1", string(tsB.Payload.Get(0).AsBytes()))
+
+	require.NoError(t, tsA.Ack(tCtx, nil))
+	require.NoError(t, tsB.Ack(tCtx, errors.New("nope")))
+
+	select {
+	case res := <-resChan:
+		var bErr *batch.Error
+		require.ErrorAs(t, res, &bErr)
+		assert.Equal(t, 1, bErr.IndexedErrors())
+
+		failed := map[int]bool{}
+		bErr.WalkPartsNaively(func(i int, p *message.Part, err error) bool {
+			failed[i] = err != nil
+			return true
+		})
+		assert.Equal(t, map[int]bool{0: false, 1: true, 2: false}, failed)
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	oTM.TriggerCloseNow()
+	require.NoError(t, oTM.WaitForClose(tCtx))
+}
+
+func TestByKeyConfig(t *testing.T) {
+	conf := parseYAMLOutputConf(t, `
+broker:
+  pattern: by_key
+  outputs:
+    - drop: {}
+    - drop: {}
+`)
+
+	_, err := bundle.AllOutputs.Init(conf, mock.NewManager())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be set when the pattern is 'by_key'")
+
+	conf = parseYAMLOutputConf(t, `
+broker:
+  pattern: by_key
+  key: '${! @key }'
+  outputs:
+    - drop: {}
+    - drop: {}
+`)
+
+	out, err := bundle.AllOutputs.Init(conf, mock.NewManager())
+	require.NoError(t, err)
+	require.NoError(t, out.Consume(make(chan message.Transaction)))
+
+	out.TriggerCloseNow()
+	require.NoError(t, out.WaitForClose(context.Background()))
+}
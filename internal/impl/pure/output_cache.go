@@ -10,15 +10,17 @@ import (
 	"github.com/redpanda-data/benthos/v4/internal/component/cache"
 	"github.com/redpanda-data/benthos/v4/internal/component/interop"
 	"github.com/redpanda-data/benthos/v4/internal/component/output"
+	"github.com/redpanda-data/benthos/v4/internal/component/output/batcher"
 	"github.com/redpanda-data/benthos/v4/internal/log"
 	"github.com/redpanda-data/benthos/v4/internal/message"
 	"github.com/redpanda-data/benthos/v4/public/service"
 )
 
 const (
-	coFieldTarget = "target"
-	coFieldKey    = "key"
-	coFieldTTL    = "ttl"
+	coFieldTarget   = "target"
+	coFieldKey      = "key"
+	coFieldTTL      = "ttl"
+	coFieldBatching = "batching"
 )
 
 func CacheOutputSpec() *service.ConfigSpec {
@@ -44,7 +46,11 @@ cache_resources:
       default_ttl: 60s
 `+"```"+`
 
-In order to create a unique `+"`key`"+` value per item you should use function interpolations described in xref:configuration:interpolation.adoc#bloblang-queries[Bloblang queries].`+service.OutputPerformanceDocs(true, false)).
+In order to create a unique `+"`key`"+` value per item you should use function interpolations described in xref:configuration:interpolation.adoc#bloblang-queries[Bloblang queries].
+
+== Batches
+
+Messages of a batch are written to the cache with a single multi-set request for caches that support them, which can dramatically reduce the number of round trips made to caches that support pipelining. Caches that do not support multi-set requests are written to with an individual request per message. If multiple messages of a batch share the same key then only the last of them is stored.`+service.OutputPerformanceDocs(true, true)).
 		Fields(
 			service.NewStringField(coFieldTarget).
 				Description("The target cache to store messages in."),
@@ -63,6 +69,9 @@ In order to create a unique `+"`key`"+` value per item you should use function i
 				Advanced().
 				Optional(),
 			service.NewOutputMaxInFlightField(),
+			service.NewOutputOrderedAcksField(),
+			service.NewBatchPolicyField(coFieldBatching).
+				Version("4.29.0"),
 		)
 }
 
@@ -85,6 +94,19 @@ func init() {
 			if s, err = output.NewAsyncWriter("cache", maxInFlight, ca, mgr); err != nil {
 				return
 			}
+
+			var batchConf service.BatchPolicy
+			if batchConf, err = conf.FieldBatchPolicy(coFieldBatching); err != nil {
+				return
+			}
+			if !batchConf.IsNoop() {
+				var iBatcher *service.Batcher
+				if iBatcher, err = batchConf.NewBatcher(res); err != nil {
+					return
+				}
+				s = batcher.New(interop.UnwrapBatcher(iBatcher), s, mgr)
+			}
+
 			out = interop.NewUnwrapInternalOutput(s)
 			return
 		})
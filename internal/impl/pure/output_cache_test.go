@@ -16,6 +16,7 @@ import (
 	"github.com/redpanda-data/benthos/v4/internal/manager"
 	"github.com/redpanda-data/benthos/v4/internal/manager/mock"
 	"github.com/redpanda-data/benthos/v4/internal/message"
+	"github.com/redpanda-data/benthos/v4/public/service"
 
 	_ "github.com/redpanda-data/benthos/v4/public/components/pure"
 )
@@ -100,6 +101,57 @@ ttl: 2s
 	}, c)
 }
 
+func TestCacheBatchingPolicy(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{}
+
+	conf, err := testutil.OutputFromYAML(`
+cache:
+  key: ${!json("id")}
+  target: foocache
+  batching:
+    count: 3
+`)
+	require.NoError(t, err)
+
+	out, err := bundle.AllOutputs.Init(conf, mgr)
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, out.Consume(tChan))
+
+	resChan := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		msg := message.QuickBatch([][]byte{[]byte(fmt.Sprintf(`{"id":"%v"}`, i))})
+		select {
+		case tChan <- message.NewTransaction(msg, resChan):
+		case <-tCtx.Done():
+			t.Fatal("timed out")
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-resChan:
+			require.NoError(t, err)
+		case <-tCtx.Done():
+			t.Fatal("timed out")
+		}
+	}
+
+	assert.Equal(t, map[string]mock.CacheItem{
+		"0": {Value: `{"id":"0"}`},
+		"1": {Value: `{"id":"1"}`},
+		"2": {Value: `{"id":"2"}`},
+	}, mgr.Caches["foocache"])
+
+	out.TriggerCloseNow()
+	require.NoError(t, out.WaitForClose(tCtx))
+}
+
 func TestCacheBatchTTL(t *testing.T) {
 	c := map[string]mock.CacheItem{}
 
@@ -236,3 +288,121 @@ target: foo
 		}
 	}
 }
+
+type blockingFirstCache struct {
+	entered chan struct{}
+	release chan struct{}
+	written chan struct{}
+}
+
+func (c *blockingFirstCache) Get(ctx context.Context, key string) ([]byte, error) {
+	return nil, service.ErrKeyNotFound
+}
+
+func (c *blockingFirstCache) Set(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
+	if key == "first" {
+		close(c.entered)
+		select {
+		case <-c.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
+	}
+	close(c.written)
+	return nil
+}
+
+func (c *blockingFirstCache) Add(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
+	return c.Set(ctx, key, value, ttl)
+}
+
+func (c *blockingFirstCache) Delete(ctx context.Context, key string) error {
+	return nil
+}
+
+func (c *blockingFirstCache) Close(ctx context.Context) error {
+	return nil
+}
+
+func TestCacheOrderedAcks(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	cache := &blockingFirstCache{
+		entered: make(chan struct{}),
+		release: make(chan struct{}),
+		written: make(chan struct{}),
+	}
+
+	env := service.NewEnvironment()
+	require.NoError(t, env.RegisterCache("blocking_first", service.NewConfigSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Cache, error) {
+			return cache, nil
+		}))
+
+	builder := env.NewStreamBuilder()
+	require.NoError(t, builder.AddCacheYAML(`
+label: foocache
+blocking_first: {}
+`))
+	require.NoError(t, builder.AddOutputYAML(`
+cache:
+  target: foocache
+  key: ${! content() }
+  max_in_flight: 2
+  ordered_acks: true
+`))
+	produce, err := builder.AddProducerFunc()
+	require.NoError(t, err)
+
+	strm, err := builder.Build()
+	require.NoError(t, err)
+	go func() {
+		_ = strm.Run(tCtx)
+	}()
+
+	// Each message is sent once the previous has reached the cache, which
+	// guarantees the order in which they are received by the output.
+	acks := make(chan string, 2)
+	for _, w := range []struct {
+		content string
+		reached chan struct{}
+	}{
+		{content: "first", reached: cache.entered},
+		{content: "second", reached: cache.written},
+	} {
+		go func(content string) {
+			if err := produce(tCtx, service.NewMessage([]byte(content))); err == nil {
+				acks <- content
+			}
+		}(w.content)
+		select {
+		case <-w.reached:
+		case <-tCtx.Done():
+			t.Fatal("timed out")
+		}
+	}
+
+	// The second message is written whilst the first is blocked, but must not
+	// be acknowledged until the first is.
+	select {
+	case a := <-acks:
+		t.Fatalf("unexpected ack: %v", a)
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	close(cache.release)
+	var acked []string
+	for len(acked) < 2 {
+		select {
+		case a := <-acks:
+			acked = append(acked, a)
+		case <-tCtx.Done():
+			t.Fatal("timed out")
+		}
+	}
+	assert.ElementsMatch(t, []string{"first", "second"}, acked)
+
+	require.NoError(t, strm.Stop(tCtx))
+}
@@ -2,17 +2,21 @@ package pure
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"time"
 
 	"github.com/Jeffail/shutdown"
 
+	"github.com/redpanda-data/benthos/v4/internal/batch"
 	"github.com/redpanda-data/benthos/v4/internal/component"
 	"github.com/redpanda-data/benthos/v4/internal/component/interop"
 	"github.com/redpanda-data/benthos/v4/internal/component/output"
 	"github.com/redpanda-data/benthos/v4/internal/log"
 	"github.com/redpanda-data/benthos/v4/internal/message"
+	"github.com/redpanda-data/benthos/v4/internal/value"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
 	"github.com/redpanda-data/benthos/v4/public/service"
 )
 
@@ -20,6 +24,9 @@ const (
 	dooFieldError         = "error"
 	dooFieldErrorPatterns = "error_patterns"
 	dooFieldBackPressure  = "back_pressure"
+	dooFieldMaxSize       = "max_size"
+	dooFieldMaxAge        = "max_age"
+	dooFieldTSMapping     = "timestamp_mapping"
 	dooFieldOutput        = "output"
 )
 
@@ -28,7 +35,11 @@ func dropOnOutputSpec() *service.ConfigSpec {
 		Stable().
 		Categories("Utility").
 		Summary(`Attempts to write messages to a child output and if the write fails for one of a list of configurable reasons the message is dropped (acked) instead of being reattempted (or nacked).`).
-		Description(`Regular Benthos outputs will apply back pressure when downstream services aren't accessible, and Benthos retries (or nacks) all messages that fail to be delivered. However, in some circumstances, or for certain output types, we instead might want to relax these mechanisms, which is when this output becomes useful.`).
+		Description(`Regular Benthos outputs will apply back pressure when downstream services aren't accessible, and Benthos retries (or nacks) all messages that fail to be delivered. However, in some circumstances, or for certain output types, we instead might want to relax these mechanisms, which is when this output becomes useful.
+
+== Dropping by size and age
+
+Messages can also be dropped before they reach the child output when their payload exceeds the size `+"`max_size`"+`, or when they are older than `+"`max_age`"+`, where the age of each message is determined by the `+"`timestamp_mapping`"+`. This makes it possible to shed known bad or stale traffic at the output without a dedicated filter mapping. Dropped messages are acknowledged, and the remaining messages of a batch are written to the child output as a smaller batch.`).
 		Example(
 			"Dropping failed HTTP requests",
 			"In this example we have a fan_out broker, where we guarantee delivery to our Kafka output, but drop messages if they fail our secondary HTTP client output.",
@@ -46,6 +57,21 @@ output:
             http_client:
               url: http://example.com/foo/messages
               verb: POST
+`,
+		).
+		Example(
+			"Dropping stale and oversized messages",
+			"In this example messages that are larger than one megabyte, or that were produced to Kafka over an hour ago, are dropped rather than being sent to an HTTP API.",
+			`
+output:
+  drop_on:
+    max_size: 1MB
+    max_age: 1h
+    timestamp_mapping: 'root = meta("kafka_timestamp_unix").number()'
+    output:
+      http_client:
+        url: http://example.com/foo/messages
+        verb: POST
 `,
 		).
 		Example(
@@ -62,7 +88,7 @@ output:
 		).
 		Fields(
 			service.NewBoolField(dooFieldError).
-				Description("Whether messages should be dropped when the child output returns an error of any type. For example, this could be when an `http_client` output gets a 4XX response code. In order to instead drop only on specific error patterns use the `error_matches` field instead.").
+				Description("Whether messages should be dropped when the child output returns an error of any type. For example, this could be when an `http_client` output gets a 4XX response code. In order to instead drop only on specific error patterns use the `error_patterns` field instead.").
 				Default(false),
 			service.NewStringListField(dooFieldErrorPatterns).
 				Description("A list of regular expressions (re2) where if the child output returns an error that matches any part of any of these patterns the message will be dropped.").
@@ -77,6 +103,21 @@ output:
 				Description("An optional duration string that determines the maximum length of time to wait for a given message to be accepted by the child output before the message should be dropped instead. The most common reason for an output to block is when waiting for a lost connection to be re-established. Once a message has been dropped due to back pressure all subsequent messages are dropped immediately until the output is ready to process them again. Note that if `error` is set to `false` and this field is specified then messages dropped due to back pressure will return an error response (are nacked or reattempted).").
 				Examples("30s", "1m").
 				Optional(),
+			service.NewByteSizeField(dooFieldMaxSize).
+				Description("An optional maximum size of message payloads, where messages that exceed it are dropped without being written to the child output.").
+				Examples("1MB", "512KiB").
+				Optional().
+				Version("4.29.0"),
+			service.NewDurationField(dooFieldMaxAge).
+				Description("An optional maximum age of messages, where messages that are older are dropped without being written to the child output. The age of each message is determined by the `timestamp_mapping`, which must be set when this field is.").
+				Examples("1h", "30m").
+				Optional().
+				Version("4.29.0"),
+			service.NewBloblangField(dooFieldTSMapping).
+				Description("A xref:guides:bloblang/about.adoc[Bloblang mapping] that provides the timestamp of each message for determining its age. The timestamp value assigned to `root` must either be a numerical unix time in seconds (with up to nanosecond precision via decimals), or a string in ISO 8601 format. Messages where the mapping fails or provides an invalid result are not dropped due to their age.").
+				Examples(`root = this.created_at`, `root = meta("kafka_timestamp_unix").number()`).
+				Optional().
+				Version("4.29.0"),
 			service.NewOutputField(dooFieldOutput).
 				Description("A child output to wrap with this drop mechanism."),
 		)
@@ -107,6 +148,9 @@ type dropOnWriter struct {
 	onError        bool
 	onErrorMatches []*regexp.Regexp
 	onBackpressure time.Duration
+	maxSize        int64
+	maxAge         time.Duration
+	tsMapping      *bloblang.Executor
 	wrapped        output.Streamed
 
 	transactionsIn  <-chan message.Transaction
@@ -143,6 +187,27 @@ func newDropOnWriter(conf *service.ParsedConfig, log log.Modular) (*dropOnWriter
 		}
 	}
 
+	var maxSize int64
+	if conf.Contains(dooFieldMaxSize) {
+		if maxSize, err = conf.FieldByteSize(dooFieldMaxSize); err != nil {
+			return nil, err
+		}
+	}
+
+	var maxAge time.Duration
+	var tsMapping *bloblang.Executor
+	if conf.Contains(dooFieldMaxAge) {
+		if maxAge, err = conf.FieldDuration(dooFieldMaxAge); err != nil {
+			return nil, err
+		}
+		if !conf.Contains(dooFieldTSMapping) {
+			return nil, fmt.Errorf("field '%v' must be set when '%v' is set", dooFieldTSMapping, dooFieldMaxAge)
+		}
+		if tsMapping, err = conf.FieldBloblang(dooFieldTSMapping); err != nil {
+			return nil, err
+		}
+	}
+
 	pOut, err := conf.FieldOutput(dooFieldOutput)
 	if err != nil {
 		return nil, err
@@ -156,11 +221,109 @@ func newDropOnWriter(conf *service.ParsedConfig, log log.Modular) (*dropOnWriter
 		onError:        onError,
 		onErrorMatches: onErrMatchesPatterns,
 		onBackpressure: backPressure,
+		maxSize:        maxSize,
+		maxAge:         maxAge,
+		tsMapping:      tsMapping,
 
 		shutSig: shutdown.NewSignaller(),
 	}, nil
 }
 
+// messageAge returns the age of a message according to the timestamp
+// mapping.
+func (d *dropOnWriter) messageAge(p *message.Part) (time.Duration, error) {
+	res, err := service.NewInternalMessage(p).BloblangQuery(d.tsMapping)
+	if err != nil {
+		return 0, err
+	}
+	if res == nil {
+		return 0, errors.New("mapping resulted in a deleted message")
+	}
+
+	var tsValue any
+	if tsValue, err = res.AsStructured(); err != nil {
+		tsBytes, _ := res.AsBytes()
+		if len(tsBytes) == 0 {
+			return 0, err
+		}
+		tsValue = string(tsBytes)
+	}
+
+	ts, err := value.IGetTimestamp(tsValue)
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(ts), nil
+}
+
+// shouldDrop returns whether a message should be dropped without being
+// written to the child output.
+func (d *dropOnWriter) shouldDrop(p *message.Part) bool {
+	if d.maxSize > 0 {
+		if size := int64(len(p.AsBytes())); size > d.maxSize {
+			d.log.Debug("Message dropped due to its size of %v bytes exceeding %v bytes", size, d.maxSize)
+			return true
+		}
+	}
+	if d.maxAge > 0 {
+		age, err := d.messageAge(p)
+		if err != nil {
+			d.log.Debug("Failed to determine the age of a message: %v", err)
+			return false
+		}
+		if age > d.maxAge {
+			d.log.Debug("Message dropped due to its age of %v exceeding %v", age, d.maxAge)
+			return true
+		}
+	}
+	return false
+}
+
+// filterDropped returns the messages of a batch that should be written to the
+// child output, along with a sort group of the source batch when messages
+// have been dropped.
+func (d *dropOnWriter) filterDropped(b message.Batch) (*message.SortGroup, message.Batch) {
+	if d.maxSize <= 0 && d.maxAge <= 0 {
+		return nil, b
+	}
+
+	group, tracked := message.NewSortGroup(b)
+	filtered := make(message.Batch, 0, len(tracked))
+	for _, p := range tracked {
+		if !d.shouldDrop(p) {
+			filtered = append(filtered, p)
+		}
+	}
+	if len(filtered) == len(b) {
+		return nil, b
+	}
+	return group, filtered
+}
+
+// sourceErr converts an error from writing a filtered batch into an error
+// of the source batch.
+func sourceErr(group *message.SortGroup, source, filtered message.Batch, err error) error {
+	sErr := batch.NewError(source, err)
+
+	var bErr *batch.Error
+	if errors.As(err, &bErr) {
+		bErr.WalkPartsBySource(group, source, func(i int, p *message.Part, e error) bool {
+			if e != nil {
+				sErr.Failed(i, e)
+			}
+			return true
+		})
+		return sErr
+	}
+
+	for _, p := range filtered {
+		if i := group.GetIndex(p); i >= 0 {
+			sErr.Failed(i, err)
+		}
+	}
+	return sErr
+}
+
 func (d *dropOnWriter) loop() {
 	cnCtx, cnDone := d.shutSig.HardStopCtx(context.Background())
 	defer func() {
@@ -188,6 +351,14 @@ func (d *dropOnWriter) loop() {
 			return
 		}
 
+		group, payload := d.filterDropped(ts.Payload)
+		if len(payload) == 0 {
+			if err := ts.Ack(cnCtx, nil); err != nil && cnCtx.Err() != nil {
+				return
+			}
+			continue
+		}
+
 		var res error
 		if d.onBackpressure > 0 {
 			if !func() bool {
@@ -197,13 +368,13 @@ func (d *dropOnWriter) loop() {
 
 				if gotBackPressure {
 					select {
-					case d.transactionsOut <- message.NewTransaction(ts.Payload, resChan):
+					case d.transactionsOut <- message.NewTransaction(payload, resChan):
 						gotBackPressure = false
 					default:
 					}
 				} else {
 					select {
-					case d.transactionsOut <- message.NewTransaction(ts.Payload, resChan):
+					case d.transactionsOut <- message.NewTransaction(payload, resChan):
 					case <-ticker.C:
 						gotBackPressure = true
 					case <-d.shutSig.HardStopChan():
@@ -240,7 +411,7 @@ func (d *dropOnWriter) loop() {
 			// Push data as usual, if the output blocks due to a disconnect then
 			// we wait as long as it takes.
 			select {
-			case d.transactionsOut <- message.NewTransaction(ts.Payload, resChan):
+			case d.transactionsOut <- message.NewTransaction(payload, resChan):
 			case <-d.shutSig.HardStopChan():
 				return
 			}
@@ -267,6 +438,10 @@ func (d *dropOnWriter) loop() {
 			}
 		}
 
+		if res != nil && group != nil {
+			res = sourceErr(group, ts.Payload, payload, res)
+		}
+
 		if err := ts.Ack(cnCtx, res); err != nil && cnCtx.Err() != nil {
 			return
 		}
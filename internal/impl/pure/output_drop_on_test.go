@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -15,6 +16,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/redpanda-data/benthos/v4/internal/batch"
 	"github.com/redpanda-data/benthos/v4/internal/component/output"
 	"github.com/redpanda-data/benthos/v4/internal/component/testutil"
 	bmock "github.com/redpanda-data/benthos/v4/internal/manager/mock"
@@ -372,3 +374,123 @@ drop_on:
 	}
 	assert.NoError(t, res)
 }
+
+func TestDropOnSizeAndAge(t *testing.T) {
+	var reqMut sync.Mutex
+	var reqBodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msgBody, _ := io.ReadAll(r.Body)
+		if string(msgBody) == "bad" {
+			http.Error(w, "nope", http.StatusForbidden)
+			return
+		}
+		reqMut.Lock()
+		reqBodies = append(reqBodies, string(msgBody))
+		reqMut.Unlock()
+	}))
+	t.Cleanup(func() {
+		ts.Close()
+	})
+
+	dropConf := parseYAMLOutputConf(t, `
+drop_on:
+  max_size: 10B
+  max_age: 1h
+  timestamp_mapping: 'root = meta("ts").number()'
+  output:
+    http_client:
+      url: %v
+      drop_on: [ %v ]
+`, ts.URL, http.StatusForbidden)
+
+	d, err := bmock.NewManager().NewOutput(dropConf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		d.TriggerCloseNow()
+		assert.NoError(t, d.WaitForClose(ctx))
+		done()
+	})
+
+	tChan := make(chan message.Transaction)
+	rChan := make(chan error)
+
+	require.NoError(t, d.Consume(tChan))
+
+	newBatch := func(contents []string, ages []time.Duration) message.Batch {
+		b := message.QuickBatch(nil)
+		for i, c := range contents {
+			p := message.NewPart([]byte(c))
+			if ages[i] >= 0 {
+				p.MetaSetMut("ts", strconv.FormatInt(time.Now().Add(-ages[i]).Unix(), 10))
+			}
+			b = append(b, p)
+		}
+		return b
+	}
+
+	sendBatch := func(b message.Batch) error {
+		t.Helper()
+		select {
+		case tChan <- message.NewTransaction(b, rChan):
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out")
+		}
+		select {
+		case res := <-rChan:
+			return res
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out")
+		}
+		return nil
+	}
+
+	// Messages without a timestamp are not dropped due to their age.
+	require.NoError(t, sendBatch(newBatch(
+		[]string{"fresh", "far too big", "stale", "unknown"},
+		[]time.Duration{0, 0, time.Hour * 2, -1},
+	)))
+
+	reqMut.Lock()
+	assert.Equal(t, []string{"fresh", "unknown"}, reqBodies)
+	reqMut.Unlock()
+
+	// Batches of only dropped messages are acknowledged without a write.
+	require.NoError(t, sendBatch(newBatch(
+		[]string{"stale", "far too big"},
+		[]time.Duration{time.Hour * 2, 0},
+	)))
+
+	// Errors of the remaining messages are returned for the source batch.
+	err = sendBatch(newBatch(
+		[]string{"stale", "bad"},
+		[]time.Duration{time.Hour * 2, 0},
+	))
+	require.Error(t, err)
+
+	var bErr *batch.Error
+	require.ErrorAs(t, err, &bErr)
+	failed := map[int]bool{}
+	bErr.WalkPartsNaively(func(i int, p *message.Part, err error) bool {
+		failed[i] = err != nil
+		return true
+	})
+	assert.Equal(t, map[int]bool{0: false, 1: true}, failed)
+
+	reqMut.Lock()
+	assert.Equal(t, []string{"fresh", "unknown"}, reqBodies)
+	reqMut.Unlock()
+}
+
+func TestDropOnMaxAgeRequiresMapping(t *testing.T) {
+	dropConf := parseYAMLOutputConf(t, `
+drop_on:
+  max_age: 1h
+  output:
+    drop: {}
+`)
+
+	_, err := bmock.NewManager().NewOutput(dropConf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "field 'timestamp_mapping' must be set when 'max_age' is set")
+}
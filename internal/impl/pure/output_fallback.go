@@ -45,6 +45,48 @@ output:
 
 When a given output fails the message routed to the following output will have a metadata value named `+"`fallback_error`"+` containing a string error message outlining the cause of the failure. The content of this string will depend on the particular output and can be used to enrich the message or provide information used to broker the data to an appropriate output using something like a `+"`switch`"+` output.
 
+Messages routed to a following output also have the following metadata fields, which can be used in order to audit the delivery of messages:
+
+- `+"`fallback_tier`"+`: The index of the output that the message is being sent to, starting from zero. A message is therefore delivered by the tier of its `+"`fallback_tier`"+`, and messages delivered by the first output do not have this field.
+- `+"`fallback_errors`"+`: An array of objects, one for each output that has failed to send the message, in order, each containing the index of the output as `+"`tier`"+` and the cause of the failure as `+"`error`"+`.
+
+For example, the following mapping within the processors of the last tier adds an audit trail of the delivery to each message:
+
+`+"```coffee"+`
+root = this
+root.delivery.tier = @fallback_tier
+root.delivery.failures = @fallback_errors.map_each(f -> "tier %v: %v".format(f.tier, f.error))
+`+"```"+`
+
+== Retrying tiers
+
+Each tier is attempted once before the message is routed to the next tier. In order to give a tier its own retry policy wrap it within a xref:components:outputs/retry.adoc[`+"`retry`"+` output], where bounding the number of retries with `+"`max_retries`"+` or `+"`backoff.max_elapsed_time`"+` allows the message to fall through once the retries are exhausted:
+
+`+"```yaml"+`
+output:
+  fallback:
+    - retry:
+        max_retries: 5
+        backoff:
+          initial_interval: 100ms
+          max_interval: 1s
+        output:
+          http_client:
+            url: http://foo:4195/post/might/become/unreachable
+    - retry:
+        max_retries: 20
+        backoff:
+          initial_interval: 1s
+          max_interval: 30s
+        output:
+          http_client:
+            url: http://bar:4196/somewhere/else
+    - file:
+        path: /usr/local/benthos/everything_failed.jsonl
+`+"```"+`
+
+The error of a tier that has exhausted its retries includes the cause of the last attempt, which is added to `+"`fallback_error`"+` and `+"`fallback_errors`"+`.
+
 == Batching
 
 When an output within a fallback sequence uses batching, like so:
@@ -183,14 +225,15 @@ func (t *fallbackBroker) loop() {
 		}
 
 		outSorter, outBatch := message.NewSortGroup(tran.Payload)
-		nextBatchFromErr := func(err error) message.Batch {
+		nextBatchFromErr := func(tier int, err error) message.Batch {
 			var bErr *batch.Error
 			if len(outBatch) <= 1 || !errors.As(err, &bErr) {
 				tmpBatch := outBatch.ShallowCopy()
 				for _, m := range tmpBatch {
-					m.MetaSetMut("fallback_error", err.Error())
+					setFallbackMetadata(m, tier, err)
 				}
-				return tmpBatch
+				outSorter, outBatch = message.NewSortGroup(tmpBatch)
+				return outBatch.ShallowCopy()
 			}
 
 			var onlyErrs message.Batch
@@ -202,7 +245,7 @@ func (t *fallbackBroker) loop() {
 					}
 					seenIndexes[i] = struct{}{}
 					tmp := p.ShallowCopy()
-					tmp.MetaSetMut("fallback_error", err.Error())
+					setFallbackMetadata(tmp, tier, err)
 					onlyErrs = append(onlyErrs, tmp)
 				}
 				return true
@@ -214,9 +257,10 @@ func (t *fallbackBroker) loop() {
 			if len(onlyErrs) == 0 {
 				tmpBatch := outBatch.ShallowCopy()
 				for _, m := range tmpBatch {
-					m.MetaSetMut("fallback_error", err.Error())
+					setFallbackMetadata(m, tier, err)
 				}
-				return tmpBatch
+				outSorter, outBatch = message.NewSortGroup(tmpBatch)
+				return outBatch.ShallowCopy()
 			}
 
 			outSorter, outBatch = message.NewSortGroup(onlyErrs)
@@ -232,7 +276,7 @@ func (t *fallbackBroker) loop() {
 			}
 
 			select {
-			case t.outputTSChans[i] <- message.NewTransactionFunc(nextBatchFromErr(err), ackFn):
+			case t.outputTSChans[i] <- message.NewTransactionFunc(nextBatchFromErr(i-1, err), ackFn):
 			case <-ctx.Done():
 				return ctx.Err()
 			}
@@ -247,6 +291,25 @@ func (t *fallbackBroker) loop() {
 	}
 }
 
+// setFallbackMetadata records the failure of a tier to send a message, which is
+// then routed to the following tier.
+func setFallbackMetadata(p *message.Part, failedTier int, err error) {
+	var failures []any
+	if v, exists := p.MetaGetMut("fallback_errors"); exists {
+		if prev, ok := v.([]any); ok {
+			failures = append(failures, prev...)
+		}
+	}
+	failures = append(failures, map[string]any{
+		"tier":  failedTier,
+		"error": err.Error(),
+	})
+
+	p.MetaSetMut("fallback_error", err.Error())
+	p.MetaSetMut("fallback_errors", failures)
+	p.MetaSetMut("fallback_tier", failedTier+1)
+}
+
 func (t *fallbackBroker) TriggerCloseNow() {
 	t.shutSig.TriggerHardStop()
 }
@@ -438,3 +438,83 @@ func TestFallbackAllFailParallel(t *testing.T) {
 	close(readChan)
 	require.NoError(t, oTM.WaitForClose(tCtx))
 }
+
+func TestFallbackFailureMetadata(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	mockOutputs := []*mock.OutputChanneled{{}, {}, {}}
+	outputs := []output.Streamed{}
+	for _, o := range mockOutputs {
+		outputs = append(outputs, o)
+	}
+
+	readChan := make(chan message.Transaction)
+	resChan := make(chan error)
+
+	oTM, err := newFallbackBroker(outputs)
+	require.NoError(t, err)
+	require.NoError(t, oTM.Consume(readChan))
+
+	select {
+	case readChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello")}), resChan):
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	var ts message.Transaction
+	select {
+	case ts = <-mockOutputs[0].TChan:
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	_, exists := ts.Payload.Get(0).MetaGetMut("fallback_tier")
+	assert.False(t, exists)
+	go func() {
+		require.NoError(t, ts.Ack(tCtx, errors.New("first err")))
+	}()
+
+	select {
+	case ts = <-mockOutputs[1].TChan:
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	tier, _ := ts.Payload.Get(0).MetaGetMut("fallback_tier")
+	assert.Equal(t, 1, tier)
+	go func() {
+		require.NoError(t, ts.Ack(tCtx, errors.New("second err")))
+	}()
+
+	select {
+	case ts = <-mockOutputs[2].TChan:
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	p := ts.Payload.Get(0)
+	assert.Equal(t, "hello", string(p.AsBytes()))
+	assert.Equal(t, "second err", p.MetaGetStr("fallback_error"))
+
+	tier, _ = p.MetaGetMut("fallback_tier")
+	assert.Equal(t, 2, tier)
+
+	failures, _ := p.MetaGetMut("fallback_errors")
+	assert.Equal(t, []any{
+		map[string]any{"tier": 0, "error": "first err"},
+		map[string]any{"tier": 1, "error": "second err"},
+	}, failures)
+
+	go func() {
+		require.NoError(t, ts.Ack(tCtx, nil))
+	}()
+
+	select {
+	case res := <-resChan:
+		require.NoError(t, res)
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	close(readChan)
+	require.NoError(t, oTM.WaitForClose(tCtx))
+}
@@ -2,18 +2,51 @@ package pure
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 
 	"github.com/Jeffail/shutdown"
 
 	"github.com/redpanda-data/benthos/v4/internal/bundle"
 	"github.com/redpanda-data/benthos/v4/internal/component"
 	"github.com/redpanda-data/benthos/v4/internal/component/interop"
+	"github.com/redpanda-data/benthos/v4/internal/component/metrics"
 	"github.com/redpanda-data/benthos/v4/internal/component/output"
 	"github.com/redpanda-data/benthos/v4/internal/log"
 	"github.com/redpanda-data/benthos/v4/internal/message"
+	"github.com/redpanda-data/benthos/v4/internal/value"
 	"github.com/redpanda-data/benthos/v4/public/service"
 )
 
+const (
+	ioFieldID        = "id"
+	ioFieldQueueSize = "queue_size"
+	ioFieldOverflow  = "overflow"
+)
+
+const (
+	inprocOverflowBlock      = "block"
+	inprocOverflowDropOldest = "drop_oldest"
+	inprocOverflowError      = "error"
+)
+
+var errInprocQueueFull = errors.New("inproc queue is full")
+
+// Lints the object form of the config, which is not otherwise checked as the
+// field accepts any value.
+const inprocOutputLintRule = `root = if this.type() == "object" {
+  this.keys().filter(k -> !["id", "queue_size", "overflow"].contains(k)).map_each(k -> "field %v is not recognised".format(k)).concat(
+    if !this.exists("id") { ["field id is required"] } else { [] },
+    if this.queue_size.or(0).type() != "number" || this.queue_size.or(0) < 0 {
+      ["field queue_size must be a number that is not negative"]
+    } else { [] },
+    if !["block", "drop_oldest", "error"].contains(this.overflow.or("block")) {
+      ["field overflow must be one of block, drop_oldest or error"]
+    } else { [] }
+  )
+}`
+
 func init() {
 	err := service.RegisterBatchOutput(
 		"inproc", service.NewConfigSpec().
@@ -22,18 +55,46 @@ func init() {
 			Description(`
 Sends data directly to Benthos inputs by connecting to a unique ID. This allows you to hook up isolated streams whilst running Benthos in `+"xref:guides:streams_mode/about.adoc[streams mode]"+`, it is NOT recommended that you connect the inputs of a stream with an output of the same stream, as feedback loops can lead to deadlocks in your message flow.
 
-It is possible to connect multiple inputs to the same inproc ID, resulting in messages dispatching in a round-robin fashion to connected inputs. However, only one output can assume an inproc ID, and will replace existing outputs if a collision occurs.`).
-			Field(service.NewStringField("").Default("")),
+It is possible to connect multiple inputs to the same inproc ID, resulting in messages dispatching in a round-robin fashion to connected inputs, or to every input when inputs use distinct subscriptions. However, only one output can assume an inproc ID, and will replace existing outputs if a collision occurs.
+
+== Queueing
+
+The config of this output is either the inproc ID as a string, or an object with the following fields:
+
+`+"```yaml"+`
+output:
+  inproc:
+    id: foo # The inproc ID to send to
+    queue_size: 0 # The maximum number of messages to queue for inputs
+    overflow: block # What to do with messages when the queue is full
+`+"```"+`
+
+By default messages are handed directly to connected inputs. When a `+"`queue_size`"+` greater than zero is set messages are queued until connected inputs are ready to receive them, and once the queue is full the `+"`overflow`"+` policy is applied to new messages:
+
+- `+"`block`"+`: Wait for space in the queue, applying back pressure to the output.
+- `+"`drop_oldest`"+`: Drop the oldest message of the queue, which is acknowledged, in order to make space.
+- `+"`error`"+`: Reject the new message with an error.
+
+== Metrics
+
+When a queue is configured the gauge `+"`inproc_queue_depth`"+` reports the number of queued messages, and the counter `+"`inproc_queue_overflow`"+` is incremented for each message that is dropped or rejected due to a full queue. Both metrics are labelled with the `+"`id`"+` of the output.`).
+			Field(service.NewAnyField("").Default("").
+				LintRule(inprocOutputLintRule)),
 		func(conf *service.ParsedConfig, res *service.Resources) (out service.BatchOutput, batchPolicy service.BatchPolicy, maxInFlight int, err error) {
 			nm := interop.UnwrapManagement(res)
 
-			var id string
-			if id, err = conf.FieldString(); err != nil {
+			var v any
+			if v, err = conf.FieldAny(); err != nil {
+				return
+			}
+
+			var iConf inprocOutputConfig
+			if iConf, err = inprocOutputConfigFromAny(v); err != nil {
 				return
 			}
 
 			var o output.Streamed
-			if o, err = newInprocOutput(id, nm); err != nil {
+			if o, err = newInprocOutput(iConf, nm); err != nil {
 				return
 			}
 			out = interop.NewUnwrapInternalOutput(o)
@@ -44,6 +105,65 @@ It is possible to connect multiple inputs to the same inproc ID, resulting in me
 	}
 }
 
+type inprocOutputConfig struct {
+	ID        string
+	QueueSize int
+	Overflow  string
+}
+
+func inprocOutputConfigFromAny(v any) (conf inprocOutputConfig, err error) {
+	conf.Overflow = inprocOverflowBlock
+
+	var obj map[string]any
+	switch t := v.(type) {
+	case nil:
+		return
+	case string:
+		conf.ID = t
+		return
+	case map[string]any:
+		obj = t
+	default:
+		err = fmt.Errorf("expected either a string or an object, got %T", v)
+		return
+	}
+
+	id, exists := obj[ioFieldID]
+	if !exists {
+		err = fmt.Errorf("field '%v' is required", ioFieldID)
+		return
+	}
+	if conf.ID, err = value.IGetString(id); err != nil {
+		err = fmt.Errorf("field %v: %w", ioFieldID, err)
+		return
+	}
+	if q, exists := obj[ioFieldQueueSize]; exists {
+		var queueSize int64
+		if queueSize, err = value.IGetInt(q); err != nil {
+			err = fmt.Errorf("field %v: %w", ioFieldQueueSize, err)
+			return
+		}
+		if queueSize < 0 {
+			err = fmt.Errorf("field %v: must not be negative", ioFieldQueueSize)
+			return
+		}
+		conf.QueueSize = int(queueSize)
+	}
+	if o, exists := obj[ioFieldOverflow]; exists {
+		if conf.Overflow, err = value.IGetString(o); err != nil {
+			err = fmt.Errorf("field %v: %w", ioFieldOverflow, err)
+			return
+		}
+		switch conf.Overflow {
+		case inprocOverflowBlock, inprocOverflowDropOldest, inprocOverflowError:
+		default:
+			err = fmt.Errorf("field %v: unrecognised policy: %v", ioFieldOverflow, conf.Overflow)
+			return
+		}
+	}
+	return
+}
+
 type inprocOutput struct {
 	pipe string
 	mgr  bundle.NewManagement
@@ -52,17 +172,28 @@ type inprocOutput struct {
 	transactionsOut chan message.Transaction
 	transactionsIn  <-chan message.Transaction
 
+	queue     chan message.Transaction
+	overflow  string
+	mDepth    metrics.StatGauge
+	mOverflow metrics.StatCounter
+
 	shutSig *shutdown.Signaller
 }
 
-func newInprocOutput(id string, mgr bundle.NewManagement) (output.Streamed, error) {
+func newInprocOutput(conf inprocOutputConfig, mgr bundle.NewManagement) (output.Streamed, error) {
 	i := &inprocOutput{
-		pipe:            id,
+		pipe:            conf.ID,
 		mgr:             mgr,
 		log:             mgr.Logger(),
 		transactionsOut: make(chan message.Transaction),
+		overflow:        conf.Overflow,
 		shutSig:         shutdown.NewSignaller(),
 	}
+	if conf.QueueSize > 0 {
+		i.queue = make(chan message.Transaction, conf.QueueSize)
+		i.mDepth = mgr.Metrics().GetGaugeVec("inproc_queue_depth", "id").With(conf.ID)
+		i.mOverflow = mgr.Metrics().GetCounterVec("inproc_queue_overflow", "id").With(conf.ID)
+	}
 	mgr.SetPipe(i.pipe, i.transactionsOut)
 	return i, nil
 }
@@ -76,24 +207,144 @@ func (i *inprocOutput) loop() {
 
 	i.log.Info("Sending inproc messages to ID: %s\n", i.pipe)
 
+	tsChan := i.transactionsIn
+	if i.queue != nil {
+		go i.queueLoop()
+		tsChan = i.queue
+	}
+
 	var open bool
 	for {
 		var ts message.Transaction
 		select {
-		case ts, open = <-i.transactionsIn:
+		case ts, open = <-tsChan:
+			if !open {
+				return
+			}
+		case <-i.shutSig.HardStopChan():
+			return
+		}
+		if i.queue != nil {
+			i.mDepth.Set(int64(len(i.queue)))
+		}
+
+		if !i.deliver(ts) {
+			return
+		}
+	}
+}
+
+// queueLoop moves transactions from the output into the queue, applying the
+// overflow policy when the queue is full.
+func (i *inprocOutput) queueLoop() {
+	defer close(i.queue)
+
+	for {
+		select {
+		case ts, open := <-i.transactionsIn:
 			if !open {
 				return
 			}
+			if !i.enqueue(ts) {
+				return
+			}
+			i.mDepth.Set(int64(len(i.queue)))
 		case <-i.shutSig.HardStopChan():
 			return
 		}
+	}
+}
 
+// enqueue adds a transaction to the queue. Returns false if the output was
+// closed before the transaction could be queued.
+func (i *inprocOutput) enqueue(ts message.Transaction) bool {
+	if i.overflow == inprocOverflowBlock {
+		select {
+		case i.queue <- ts:
+			return true
+		case <-i.shutSig.HardStopChan():
+			return false
+		}
+	}
+
+	for {
+		select {
+		case i.queue <- ts:
+			return true
+		default:
+		}
+
+		if i.overflow == inprocOverflowError {
+			i.mOverflow.Incr(1)
+			_ = ts.Ack(context.Background(), errInprocQueueFull)
+			return true
+		}
+
+		// The queue may have been drained since the send was attempted, in
+		// which case nothing is dropped and the send is retried.
+		select {
+		case dropped := <-i.queue:
+			i.mOverflow.Incr(1)
+			i.log.Debug("Dropping oldest message of inproc queue %v\n", i.pipe)
+			_ = dropped.Ack(context.Background(), nil)
+		default:
+		}
+	}
+}
+
+// deliver sends a transaction to every subscription of the pipe, or when there
+// are no subscriptions to whichever reader of the pipe is first. Returns false
+// if the output was closed before delivery.
+func (i *inprocOutput) deliver(ts message.Transaction) bool {
+	for {
+		subs, changed := i.mgr.GetPipeSubscriptions(i.pipe)
+		if len(subs) > 0 {
+			return i.broadcast(ts, subs)
+		}
 		select {
 		case i.transactionsOut <- ts:
+			return true
+		case <-changed:
 		case <-i.shutSig.HardStopChan():
-			return
+			return false
+		}
+	}
+}
+
+// broadcast sends a copy of a transaction to each subscription, where the
+// transaction is acknowledged once all copies are acknowledged, or with the
+// first error.
+func (i *inprocOutput) broadcast(ts message.Transaction, subs []bundle.PipeSubscription) bool {
+	var ackMut sync.Mutex
+	var ackErr error
+	pending := len(subs)
+
+	ackFn := func(ctx context.Context, err error) error {
+		ackMut.Lock()
+		if err != nil && ackErr == nil {
+			ackErr = err
+		}
+		pending--
+		done := pending == 0
+		ackMut.Unlock()
+		if !done {
+			return nil
 		}
+		return ts.Ack(ctx, ackErr)
 	}
+
+	for _, sub := range subs {
+		select {
+		case sub.Transactions <- message.NewTransactionFunc(ts.Payload.ShallowCopy(), ackFn):
+		case <-sub.Closed:
+			// Subscriptions that have gone are treated as having received the
+			// transaction.
+			_ = ackFn(context.Background(), nil)
+		case <-i.shutSig.HardStopChan():
+			return false
+		}
+	}
+	return true
 }
 
 func (i *inprocOutput) Consume(ts <-chan message.Transaction) error {
@@ -2,6 +2,7 @@ package pure_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -9,9 +10,11 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/redpanda-data/benthos/v4/internal/component"
+	"github.com/redpanda-data/benthos/v4/internal/component/metrics"
 	"github.com/redpanda-data/benthos/v4/internal/component/output"
 	"github.com/redpanda-data/benthos/v4/internal/manager"
 	"github.com/redpanda-data/benthos/v4/internal/message"
+	"github.com/redpanda-data/benthos/v4/public/service"
 
 	_ "github.com/redpanda-data/benthos/v4/public/components/pure"
 )
@@ -65,3 +68,206 @@ func TestInproc(t *testing.T) {
 	_, err = mgr.GetPipe("foo")
 	assert.Equal(t, err, component.ErrPipeNotFound)
 }
+
+func TestInprocQueueOverflow(t *testing.T) {
+	depthKey := `inproc_queue_depth{id="foo",label=""}`
+	overflowKey := `inproc_queue_overflow{id="foo",label=""}`
+
+	for _, test := range []struct {
+		policy        string
+		expAcks       []error
+		expDelivered  []string
+		expOverflowed int64
+	}{
+		{
+			policy:        "block",
+			expAcks:       []error{nil, nil, nil, nil},
+			expDelivered:  []string{"a", "b", "c", "d"},
+			expOverflowed: 0,
+		},
+		{
+			policy:        "drop_oldest",
+			expAcks:       []error{nil, nil, nil, nil},
+			expDelivered:  []string{"a", "c", "d"},
+			expOverflowed: 1,
+		},
+		{
+			policy:        "error",
+			expAcks:       []error{nil, nil, nil, errors.New("inproc queue is full")},
+			expDelivered:  []string{"a", "b", "c"},
+			expOverflowed: 1,
+		},
+	} {
+		test := test
+		t.Run(test.policy, func(t *testing.T) {
+			tCtx, done := context.WithTimeout(context.Background(), time.Second*10)
+			defer done()
+
+			stats := metrics.NewLocal()
+			mgr, err := manager.New(manager.NewResourceConfig(), manager.OptSetMetrics(metrics.NewNamespaced(stats)))
+			require.NoError(t, err)
+
+			conf := parseYAMLOutputConf(t, `
+inproc:
+  id: foo
+  queue_size: 2
+  overflow: %v
+`, test.policy)
+
+			ip, err := mgr.NewOutput(conf)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+				defer done()
+				ip.TriggerCloseNow()
+				require.NoError(t, ip.WaitForClose(ctx))
+			})
+
+			tinchan := make(chan message.Transaction)
+			require.NoError(t, ip.Consume(tinchan))
+
+			resChans := make([]chan error, len(test.expAcks))
+			for i, c := range []string{"a", "b", "c", "d"} {
+				resChans[i] = make(chan error, 1)
+				select {
+				case tinchan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte(c)}), resChans[i]):
+				case <-tCtx.Done():
+					t.Fatal("Timed out")
+				}
+				if i == 0 {
+					// Give the first message time to be taken from the queue for
+					// delivery so that the queue is empty.
+					time.Sleep(time.Millisecond * 50)
+				}
+			}
+
+			// Wait for the overflow policy to be applied to the last message
+			// before the queue is drained.
+			require.Eventually(t, func() bool {
+				return stats.GetCounters()[overflowKey] == test.expOverflowed
+			}, time.Second, time.Millisecond*10)
+			assert.Equal(t, int64(2), stats.GetCounters()[depthKey])
+
+			toutchan, err := mgr.GetPipe("foo")
+			require.NoError(t, err)
+
+			for _, exp := range test.expDelivered {
+				select {
+				case ts := <-toutchan:
+					assert.Equal(t, exp, string(ts.Payload.Get(0).AsBytes()))
+					require.NoError(t, ts.Ack(tCtx, nil))
+				case <-tCtx.Done():
+					t.Fatal("Timed out")
+				}
+			}
+
+			for i, exp := range test.expAcks {
+				select {
+				case err := <-resChans[i]:
+					assert.Equal(t, exp, err, i)
+				case <-tCtx.Done():
+					t.Fatal("Timed out")
+				}
+			}
+
+		})
+	}
+}
+
+func TestInprocOutputBadConfig(t *testing.T) {
+	mgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		config string
+		err    string
+	}{
+		{
+			config: `
+inproc:
+  id: foo
+  overflow: nope
+`,
+			err: "unrecognised policy: nope",
+		},
+		{
+			config: `
+inproc:
+  id: foo
+  queue_size: -1
+`,
+			err: "must not be negative",
+		},
+		{
+			config: `
+inproc:
+  queue_size: 10
+`,
+			err: "field 'id' is required",
+		},
+	} {
+		_, err := mgr.NewOutput(parseYAMLOutputConf(t, test.config))
+		require.Error(t, err, test.config)
+		assert.Contains(t, err.Error(), test.err, test.config)
+	}
+}
+
+func TestInprocOutputLint(t *testing.T) {
+	for _, test := range []struct {
+		config string
+		err    string
+	}{
+		{
+			config: `
+inproc: foo
+`,
+		},
+		{
+			config: `
+inproc:
+  id: foo
+  queue_size: 10
+  overflow: drop_oldest
+`,
+		},
+		{
+			config: `
+inproc:
+  id: foo
+  nope: bar
+`,
+			err: "field nope is not recognised",
+		},
+		{
+			config: `
+inproc:
+  queue_size: 10
+`,
+			err: "field id is required",
+		},
+		{
+			config: `
+inproc:
+  id: foo
+  queue_size: -1
+`,
+			err: "field queue_size must be a number that is not negative",
+		},
+		{
+			config: `
+inproc:
+  id: foo
+  overflow: nope
+`,
+			err: "field overflow must be one of block, drop_oldest or error",
+		},
+	} {
+		err := service.NewStreamBuilder().AddOutputYAML(test.config)
+		if test.err == "" {
+			assert.NoError(t, err, test.config)
+		} else {
+			require.Error(t, err, test.config)
+			assert.Contains(t, err.Error(), test.err, test.config)
+		}
+	}
+}
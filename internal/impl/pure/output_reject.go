@@ -4,16 +4,25 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 
+	"github.com/redpanda-data/benthos/v4/internal/batch"
 	"github.com/redpanda-data/benthos/v4/internal/bloblang/field"
 	"github.com/redpanda-data/benthos/v4/internal/bundle"
 	"github.com/redpanda-data/benthos/v4/internal/component/interop"
+	"github.com/redpanda-data/benthos/v4/internal/component/metrics"
 	"github.com/redpanda-data/benthos/v4/internal/component/output"
 	"github.com/redpanda-data/benthos/v4/internal/log"
 	"github.com/redpanda-data/benthos/v4/internal/message"
+	"github.com/redpanda-data/benthos/v4/internal/value"
 	"github.com/redpanda-data/benthos/v4/public/service"
 )
 
+const (
+	rejFieldReason       = "reason"
+	rejFieldMetricLabels = "metric_labels"
+)
+
 func init() {
 	err := service.RegisterBatchOutput(
 		"reject", service.NewConfigSpec().
@@ -23,7 +32,25 @@ func init() {
 			Description(`
 The routing of messages after this output depends on the type of input it came from. For inputs that support propagating nacks upstream such as AMQP or NATS the message will be nacked. However, for inputs that are sequential such as files or Kafka the messages will simply be reprocessed from scratch.
 
-To learn when this output could be useful, see [the <<examples>>.`).
+To learn when this output could be useful, see [the <<examples>>.
+
+== Rejection reasons
+
+The config of this output is either the rejection reason as a string, or an object with the following fields:
+
+`+"```yaml"+`
+output:
+  reject:
+    reason: 'failed: ${! error() }' # The reason for rejecting each message
+    metric_labels: # Labels to add to the rejection metric
+      topic: ${! @kafka_topic }
+`+"```"+`
+
+The reason is resolved for each message of a batch, and therefore each message is rejected with its own reason, which is the error propagated to the input of the message.
+
+== Metrics
+
+Each rejected message increments the counter `+"`output_rejected`"+`, which is given a label for each key of `+"`metric_labels`"+`, where the label values are resolved for each message.`).
 			Example(
 				"Rejecting Failed Messages",
 				`
@@ -43,17 +70,38 @@ output:
           reject: "processing failed due to: ${! error() }"
 `,
 			).
-			Field(service.NewStringField("").Default("")),
+			Example(
+				"Rejecting With Metrics",
+				`
+Rejected messages can be counted by a label in order to monitor the reasons for rejections:`,
+				`
+output:
+  reject:
+    reason: 'invalid order: ${! error() }'
+    metric_labels:
+      customer: ${! this.customer.tier.or("unknown") }
+`,
+			).
+			Field(service.NewAnyField("").Default("").
+				LintRule(`root = if this.type() == "object" {
+  this.keys().filter(k -> !["reason", "metric_labels"].contains(k)).map_each(k -> "field %v is not recognised".format(k))
+}`)),
 		func(conf *service.ParsedConfig, res *service.Resources) (out service.BatchOutput, batchPolicy service.BatchPolicy, maxInFlight int, err error) {
+			var v any
+			if v, err = conf.FieldAny(); err != nil {
+				return
+			}
+
 			var rejMsg string
-			if rejMsg, err = conf.FieldString(); err != nil {
+			var labels map[string]string
+			if rejMsg, labels, err = rejectConfigFromAny(v); err != nil {
 				return
 			}
 
 			mgr := interop.UnwrapManagement(res)
 
 			var w *rejectWriter
-			if w, err = newRejectWriter(mgr, rejMsg); err != nil {
+			if w, err = newRejectWriter(mgr, rejMsg, labels); err != nil {
 				return
 			}
 
@@ -69,12 +117,52 @@ output:
 	}
 }
 
+func rejectConfigFromAny(v any) (reason string, labels map[string]string, err error) {
+	switch t := v.(type) {
+	case nil:
+		return
+	case string:
+		reason = t
+		return
+	case map[string]any:
+	default:
+		err = fmt.Errorf("expected either a string or an object, got %T", v)
+		return
+	}
+
+	obj := v.(map[string]any)
+	if r, exists := obj[rejFieldReason]; exists {
+		if reason, err = value.IGetString(r); err != nil {
+			err = fmt.Errorf("field %v: %w", rejFieldReason, err)
+			return
+		}
+	}
+	if l, exists := obj[rejFieldMetricLabels]; exists {
+		lObj, ok := l.(map[string]any)
+		if !ok {
+			err = fmt.Errorf("field %v: expected an object, got %T", rejFieldMetricLabels, l)
+			return
+		}
+		labels = make(map[string]string, len(lObj))
+		for k, lv := range lObj {
+			if labels[k], err = value.IGetString(lv); err != nil {
+				err = fmt.Errorf("field %v.%v: %w", rejFieldMetricLabels, k, err)
+				return
+			}
+		}
+	}
+	return
+}
+
 type rejectWriter struct {
 	errExpr *field.Expression
 	log     log.Modular
+
+	labelExprs []*field.Expression
+	mRejected  metrics.StatCounterVec
 }
 
-func newRejectWriter(mgr bundle.NewManagement, errorString string) (*rejectWriter, error) {
+func newRejectWriter(mgr bundle.NewManagement, errorString string, labels map[string]string) (*rejectWriter, error) {
 	if errorString == "" {
 		return nil, errors.New("an error message must be provided in order to provide context for the rejection")
 	}
@@ -82,7 +170,26 @@ func newRejectWriter(mgr bundle.NewManagement, errorString string) (*rejectWrite
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse error expression: %w", err)
 	}
-	return &rejectWriter{errExpr: errExpr, log: mgr.Logger()}, nil
+
+	labelNames := make([]string, 0, len(labels))
+	for k := range labels {
+		labelNames = append(labelNames, k)
+	}
+	sort.Strings(labelNames)
+
+	labelExprs := make([]*field.Expression, len(labelNames))
+	for i, k := range labelNames {
+		if labelExprs[i], err = mgr.BloblEnvironment().NewField(labels[k]); err != nil {
+			return nil, fmt.Errorf("failed to parse metric label %v expression: %w", k, err)
+		}
+	}
+
+	return &rejectWriter{
+		errExpr:    errExpr,
+		log:        mgr.Logger(),
+		labelExprs: labelExprs,
+		mRejected:  mgr.Metrics().GetCounterVec("output_rejected", labelNames...),
+	}, nil
 }
 
 func (w *rejectWriter) Connect(ctx context.Context) error {
@@ -90,13 +197,35 @@ func (w *rejectWriter) Connect(ctx context.Context) error {
 }
 
 func (w *rejectWriter) WriteBatch(ctx context.Context, msg message.Batch) error {
-	errStr, err := w.errExpr.String(0, msg)
-	if err != nil {
-		// Wow this would be awkward
-		w.log.Error("Reject message interpolation error: %v", err)
-		return fmt.Errorf("reject message interpolation error: %w", err)
+	var bErr *batch.Error
+	for i := range msg {
+		errStr, err := w.errExpr.String(i, msg)
+		if err != nil {
+			// Wow this would be awkward
+			w.log.Error("Reject message interpolation error: %v", err)
+			return fmt.Errorf("reject message interpolation error: %w", err)
+		}
+
+		labelValues := make([]string, len(w.labelExprs))
+		for j, e := range w.labelExprs {
+			if labelValues[j], err = e.String(i, msg); err != nil {
+				w.log.Error("Reject metric label interpolation error: %v", err)
+			}
+		}
+		w.mRejected.With(labelValues...).Incr(1)
+
+		if len(msg) == 1 {
+			return errors.New(errStr)
+		}
+		if bErr == nil {
+			bErr = batch.NewError(msg, errors.New(errStr))
+		}
+		bErr.Failed(i, errors.New(errStr))
+	}
+	if bErr == nil {
+		return errors.New("rejected an empty batch")
 	}
-	return errors.New(errStr)
+	return bErr
 }
 
 func (w *rejectWriter) Close(context.Context) error {
@@ -0,0 +1,100 @@
+package pure_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/batch"
+	"github.com/redpanda-data/benthos/v4/internal/bundle"
+	"github.com/redpanda-data/benthos/v4/internal/component/metrics"
+	"github.com/redpanda-data/benthos/v4/internal/manager/mock"
+	"github.com/redpanda-data/benthos/v4/internal/message"
+)
+
+func sendRejectBatch(t *testing.T, conf string, stats *metrics.Local, msg message.Batch) error {
+	t.Helper()
+
+	mgr := mock.NewManager()
+	mgr.M = stats
+
+	s, err := bundle.AllOutputs.Init(parseYAMLOutputConf(t, conf), mgr)
+	require.NoError(t, err)
+
+	sendChan := make(chan message.Transaction)
+	resChan := make(chan error)
+	require.NoError(t, s.Consume(sendChan))
+
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		s.TriggerCloseNow()
+		require.NoError(t, s.WaitForClose(ctx))
+		done()
+	})
+
+	select {
+	case sendChan <- message.NewTransaction(msg, resChan):
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out sending batch")
+	}
+
+	select {
+	case err = <-resChan:
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for response")
+	}
+	return err
+}
+
+func TestRejectString(t *testing.T) {
+	stats := metrics.NewLocal()
+
+	msg := message.QuickBatch([][]byte{[]byte("hello")})
+	msg.Get(0).MetaSetMut("code", "404")
+
+	err := sendRejectBatch(t, `
+reject: 'failed with ${! @code }'
+`, stats, msg)
+	require.Error(t, err)
+	assert.Equal(t, "failed with 404", err.Error())
+
+	assert.Equal(t, int64(1), stats.GetCounters()["output_rejected"])
+}
+
+func TestRejectBatchReasonsAndLabels(t *testing.T) {
+	stats := metrics.NewLocal()
+
+	msg := message.QuickBatch([][]byte{
+		[]byte(`{"id":"a","tier":"gold"}`),
+		[]byte(`{"id":"b","tier":"silver"}`),
+		[]byte(`{"id":"c","tier":"gold"}`),
+	})
+
+	err := sendRejectBatch(t, `
+reject:
+  reason: 'rejected ${! this.id }'
+  metric_labels:
+    tier: ${! this.tier }
+`, stats, msg)
+	require.Error(t, err)
+
+	var bErr *batch.Error
+	require.True(t, errors.As(err, &bErr))
+	assert.Equal(t, 3, bErr.IndexedErrors())
+
+	var reasons []string
+	bErr.WalkPartsNaively(func(i int, p *message.Part, err error) bool {
+		require.Error(t, err)
+		reasons = append(reasons, err.Error())
+		return true
+	})
+	assert.Equal(t, []string{"rejected a", "rejected b", "rejected c"}, reasons)
+
+	counters := stats.GetCounters()
+	assert.Equal(t, int64(2), counters[`output_rejected{tier="gold"}`])
+	assert.Equal(t, int64(1), counters[`output_rejected{tier="silver"}`])
+}
@@ -2,7 +2,7 @@ package pure
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -21,7 +21,10 @@ import (
 	"github.com/redpanda-data/benthos/v4/public/service"
 )
 
-const roFieldOutput = "output"
+const (
+	roFieldOutput     = "output"
+	roFieldDeadLetter = "dead_letter"
+)
 
 func retryOutputSpec() *service.ConfigSpec {
 	return service.NewConfigSpec().
@@ -33,11 +36,44 @@ All messages in Benthos are always retried on an output error, but this would us
 
 This output type is useful whenever we wish to avoid reprocessing a message on the event of a failed send. We might, for example, have a deduplication processor that we want to avoid reapplying to the same message more than once in the pipeline.
 
-Rather than retrying the same output you may wish to retry the send using a different output target (a dead letter queue). In which case you should instead use the ` + "xref:components:outputs/fallback.adoc[`fallback`]" + ` output type.`).
+Rather than retrying the same output you may wish to retry the send using a different output target (a dead letter queue). In which case you should instead use the `+"xref:components:outputs/fallback.adoc[`fallback`]"+` output type.
+
+== Dead letters
+
+When the retries or max elapsed time are exhausted the error is propagated upstream, where the message is nacked. Alternatively, a `+"`dead_letter`"+` output can be set to which the messages are routed instead, in which case the messages are acknowledged upstream once the dead letter output has sent them. Messages routed to the dead letter output contain the following metadata fields:
+
+- `+"`retry_error`"+`: The error of the last attempt to send the message.
+- `+"`retry_attempts`"+`: The number of attempts made to send the message.`).
+		Example(
+			"Dead Letter Queue",
+			"Retry sending messages to an HTTP endpoint for up to a minute, after which they are written to a file:",
+			`
+output:
+  retry:
+    backoff:
+      max_elapsed_time: 1m
+    output:
+      http_client:
+        url: http://example.com/post
+    dead_letter:
+      file:
+        path: /var/benthos/dead_letters.jsonl
+        codec: lines
+      processors:
+        - mapping: |
+            root.content = content().string()
+            root.error = @retry_error
+`,
+		).
 		Fields(retries.CommonRetryBackOffFields(0, "500ms", "3s", "0s")...).
 		Fields(
 			service.NewOutputField(roFieldOutput).
 				Description("A child output."),
+			service.NewOutputField(roFieldDeadLetter).
+				Description("An optional output to which messages are routed once the retries or max elapsed time are exhausted, rather than propagating the error upstream. This field has no effect unless `max_retries` or `backoff.max_elapsed_time` are set.").
+				Version("4.29.0").
+				Advanced().
+				Optional(),
 		)
 }
 
@@ -79,7 +115,20 @@ func retryOutputFromConfig(conf *service.ParsedConfig, mgr bundle.NewManagement)
 		return nil, err
 	}
 
-	return newIndefiniteRetry(mgr, boffCtor, interop.UnwrapOwnedOutput(pOut))
+	r, err := newIndefiniteRetry(mgr, boffCtor, interop.UnwrapOwnedOutput(pOut))
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.Contains(roFieldDeadLetter) {
+		pDead, err := conf.FieldOutput(roFieldDeadLetter)
+		if err != nil {
+			return nil, err
+		}
+		r.deadLetter = interop.UnwrapOwnedOutput(pDead)
+		r.deadLetterChan = make(chan message.Transaction)
+	}
+	return r, nil
 }
 
 func newIndefiniteRetry(mgr bundle.NewManagement, backoffCtor func() backoff.BackOff, wrapped output.Streamed) (*indefiniteRetry, error) {
@@ -108,6 +157,9 @@ type indefiniteRetry struct {
 	wrapped     output.Streamed
 	backoffCtor func() backoff.BackOff
 
+	deadLetter     output.Streamed
+	deadLetterChan chan message.Transaction
+
 	log log.Modular
 
 	transactionsIn  <-chan message.Transaction
@@ -119,17 +171,24 @@ type indefiniteRetry struct {
 func (r *indefiniteRetry) loop() {
 	wg := sync.WaitGroup{}
 
+	// The context used to acknowledge transactions remains open until pending
+	// transactions are resolved, allowing them to drain once our input closes.
+	cnCtx, cnDone := r.shutSig.HardStopCtx(context.Background())
+
 	defer func() {
 		wg.Wait()
+		cnDone()
 		close(r.transactionsOut)
 		r.wrapped.TriggerCloseNow()
 		_ = r.wrapped.WaitForClose(context.Background())
+		if r.deadLetter != nil {
+			close(r.deadLetterChan)
+			r.deadLetter.TriggerCloseNow()
+			_ = r.deadLetter.WaitForClose(context.Background())
+		}
 		r.shutSig.TriggerHasStopped()
 	}()
 
-	cnCtx, cnDone := r.shutSig.HardStopCtx(context.Background())
-	defer cnDone()
-
 	errInterruptChan := make(chan struct{})
 	var errLooped int64
 
@@ -169,6 +228,7 @@ func (r *indefiniteRetry) loop() {
 			var backOff backoff.BackOff
 			var resOut error
 			var inErrLoop bool
+			attempts := 0
 
 			defer func() {
 				wg.Done()
@@ -191,6 +251,7 @@ func (r *indefiniteRetry) loop() {
 				case <-r.shutSig.HardStopChan():
 					return
 				}
+				attempts++
 
 				if res != nil {
 					if !inErrLoop {
@@ -205,7 +266,13 @@ func (r *indefiniteRetry) loop() {
 					nextBackoff := backOff.NextBackOff()
 					if nextBackoff == backoff.Stop {
 						r.log.Error("Failed to send message: %v\n", res)
-						resOut = errors.New("message failed to reach a target destination")
+						resOut = fmt.Errorf("%w: %v", component.ErrFailedSend, res)
+						if r.deadLetter != nil {
+							var open bool
+							if resOut, open = r.sendDeadLetter(ts.Payload, res, attempts); !open {
+								return
+							}
+						}
 						break
 					}
 
@@ -235,6 +302,34 @@ func (r *indefiniteRetry) loop() {
 	}
 }
 
+// sendDeadLetter routes a payload that has exhausted its retries to the dead
+// letter output and returns the result. Returns false if the output was closed
+// before a result was obtained.
+func (r *indefiniteRetry) sendDeadLetter(payload message.Batch, cause error, attempts int) (error, bool) {
+	deadBatch := payload.ShallowCopy()
+	for _, p := range deadBatch {
+		p.MetaSetMut("retry_error", cause.Error())
+		p.MetaSetMut("retry_attempts", attempts)
+	}
+
+	resChan := make(chan error)
+	select {
+	case r.deadLetterChan <- message.NewTransaction(deadBatch, resChan):
+	case <-r.shutSig.HardStopChan():
+		return nil, false
+	}
+
+	select {
+	case err := <-resChan:
+		if err != nil {
+			r.log.Error("Failed to send message to dead letter output: %v\n", err)
+		}
+		return err, true
+	case <-r.shutSig.HardStopChan():
+		return nil, false
+	}
+}
+
 // Consume assigns a messages channel for the output to read.
 func (r *indefiniteRetry) Consume(ts <-chan message.Transaction) error {
 	if r.transactionsIn != nil {
@@ -243,6 +338,11 @@ func (r *indefiniteRetry) Consume(ts <-chan message.Transaction) error {
 	if err := r.wrapped.Consume(r.transactionsOut); err != nil {
 		return err
 	}
+	if r.deadLetter != nil {
+		if err := r.deadLetter.Consume(r.deadLetterChan); err != nil {
+			return err
+		}
+	}
 	r.transactionsIn = ts
 	go r.loop()
 	return nil
@@ -251,6 +351,9 @@ func (r *indefiniteRetry) Consume(ts <-chan message.Transaction) error {
 // Connected returns a boolean indicating whether this output is currently
 // connected to its target.
 func (r *indefiniteRetry) Connected() bool {
+	if r.deadLetter != nil && !r.deadLetter.Connected() {
+		return false
+	}
 	return r.wrapped.Connected()
 }
 
@@ -112,6 +112,75 @@ retry:
 	require.NoError(t, output.WaitForClose(ctx))
 }
 
+func TestRetryDrainsAfterInputCloses(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	conf := parseYAMLOutputConf(t, `
+retry:
+  output:
+    drop: {}
+  backoff:
+    initial_interval: 10ms
+`)
+
+	output, err := bundle.AllOutputs.Init(conf, mock.NewManager())
+	require.NoError(t, err)
+
+	ret, ok := output.(*indefiniteRetry)
+	require.True(t, ok)
+
+	mOut := &mock.OutputChanneled{}
+	ret.wrapped = mOut
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, ret.Consume(tChan))
+
+	type ackResult struct {
+		err, ctxErr error
+	}
+	ackChan := make(chan ackResult, 1)
+
+	select {
+	case tChan <- message.NewTransactionFunc(message.QuickBatch([][]byte{[]byte("hello")}), func(ctx context.Context, err error) error {
+		ackChan <- ackResult{err: err, ctxErr: ctx.Err()}
+		return nil
+	}):
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	var tran message.Transaction
+	select {
+	case tran = <-mOut.TChan:
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+	require.NoError(t, tran.Ack(ctx, errors.New("test err")))
+
+	// Closing our input whilst a message is pending must not prevent it from
+	// being retried and acknowledged upstream, which is relied upon by outputs
+	// such as dynamic outputs in order to drain pending messages.
+	close(tChan)
+
+	select {
+	case tran = <-mOut.TChan:
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+	require.NoError(t, tran.Ack(ctx, nil))
+
+	select {
+	case res := <-ackChan:
+		require.NoError(t, res.err)
+		require.NoError(t, res.ctxErr)
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	require.NoError(t, output.WaitForClose(ctx))
+}
+
 func TestRetrySadPath(t *testing.T) {
 	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
 	defer done()
@@ -408,3 +477,104 @@ retry:
 		"moo":   "quack",
 	}, inStruct)
 }
+
+func TestRetryExhaustedIncludesCause(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	conf := parseYAMLOutputConf(t, `
+retry:
+  max_retries: 2
+  backoff:
+    initial_interval: 1ms
+    max_interval: 1ms
+  output:
+    reject: 'nope'
+`)
+
+	output, err := bundle.AllOutputs.Init(conf, mock.NewManager())
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	resChan := make(chan error)
+	require.NoError(t, output.Consume(tChan))
+
+	select {
+	case tChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("foo")}), resChan):
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	select {
+	case res := <-resChan:
+		require.Error(t, res)
+		assert.ErrorIs(t, res, component.ErrFailedSend)
+		assert.Contains(t, res.Error(), "nope")
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	output.TriggerCloseNow()
+	require.NoError(t, output.WaitForClose(ctx))
+}
+
+func TestRetryDeadLetter(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	conf := parseYAMLOutputConf(t, `
+retry:
+  max_retries: 2
+  backoff:
+    initial_interval: 1ms
+    max_interval: 1ms
+  output:
+    reject: 'nope'
+  dead_letter:
+    drop: {}
+`)
+
+	output, err := bundle.AllOutputs.Init(conf, mock.NewManager())
+	require.NoError(t, err)
+
+	ret, ok := output.(*indefiniteRetry)
+	require.True(t, ok)
+
+	mDead := &mock.OutputChanneled{}
+	ret.deadLetter = mDead
+
+	tChan := make(chan message.Transaction)
+	resChan := make(chan error)
+	require.NoError(t, output.Consume(tChan))
+
+	select {
+	case tChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("foo")}), resChan):
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	var tran message.Transaction
+	select {
+	case tran = <-mDead.TChan:
+	case <-resChan:
+		t.Fatal("Received response before dead letter")
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	require.Len(t, tran.Payload, 1)
+	assert.Equal(t, "foo", string(tran.Payload[0].AsBytes()))
+	assert.Equal(t, "nope", tran.Payload[0].MetaGetStr("retry_error"))
+	assert.Equal(t, "3", tran.Payload[0].MetaGetStr("retry_attempts"))
+	require.NoError(t, tran.Ack(ctx, nil))
+
+	select {
+	case res := <-resChan:
+		require.NoError(t, res)
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	output.TriggerCloseNow()
+	require.NoError(t, output.WaitForClose(ctx))
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/redpanda-data/benthos/v4/internal/bundle"
 	"github.com/redpanda-data/benthos/v4/internal/component"
 	"github.com/redpanda-data/benthos/v4/internal/component/interop"
+	"github.com/redpanda-data/benthos/v4/internal/component/metrics"
 	"github.com/redpanda-data/benthos/v4/internal/component/output"
 	"github.com/redpanda-data/benthos/v4/internal/log"
 	"github.com/redpanda-data/benthos/v4/internal/message"
@@ -29,6 +30,7 @@ const (
 	soFieldCasesCheck        = "check"
 	soFieldCasesContinue     = "continue"
 	soFieldCasesOutput       = "output"
+	soFieldUnmatched         = "unmatched"
 )
 
 func switchOutputSpec() *service.ConfigSpec {
@@ -36,7 +38,12 @@ func switchOutputSpec() *service.ConfigSpec {
 		Categories("Utility").
 		Stable().
 		Summary(`The switch output type allows you to route messages to different outputs based on their contents.`).
-		Description(`Messages that do not pass the check of a single output case are effectively dropped. In order to prevent this outcome set the field `+"<<strict_mode, `strict_mode`>> to `true`"+`, in which case messages that do not pass at least one case are considered failed and will be nacked and/or reprocessed depending on your input.`).
+		Description(`
+Messages that do not pass the check of a single output case are effectively dropped. In order to prevent this outcome either set the field `+"<<unmatched, `unmatched`>>"+` to an output that receives these messages, such as a dead letter queue, or set the field `+"<<strict_mode, `strict_mode`>> to `true`"+`, in which case messages that do not pass at least one case are considered failed and will be nacked and/or reprocessed depending on your input.
+
+== Metrics
+
+The number of messages routed to each case is counted by the metric `+"`switch_output_routed`"+`, labelled with the index of the case as `+"`case`"+`, or `+"`unmatched`"+` for messages routed to the `+"`unmatched`"+` output. Messages that pass no case and are dropped are counted by the metric `+"`switch_output_dropped`"+`.`).
 		Example(
 			"Basic Multiplexing",
 			`
@@ -109,6 +116,11 @@ If a message can be routed to >1 outputs it is usually best to set this to true
 				Description(`This field determines whether an error should be reported if no condition is met. If set to true, an error is propagated back to the input level. The default behavior is false, which will drop the message.`).
 				Advanced().
 				Default(false),
+			service.NewOutputField(soFieldUnmatched).
+				Description("An optional xref:components:outputs/about.adoc[output] to which messages that do not pass the check of any case are routed, rather than being dropped. This field cannot be combined with `strict_mode`.").
+				Version("4.29.0").
+				Advanced().
+				Optional(),
 			service.NewObjectListField(soFieldCases,
 				service.NewBloblangField(soFieldCasesCheck).
 					Description("A xref:guides:bloblang/about.adoc[Bloblang query] that should return a boolean value indicating whether a message should be routed to the case output. If left empty the case always passes.").
@@ -190,6 +202,13 @@ type switchOutput struct {
 	continues     []bool
 	fallthroughs  []bool
 
+	// The index of the unmatched output within outputs, or -1 when there isn't
+	// one.
+	unmatched int
+
+	mRouted  []metrics.StatCounter
+	mDropped metrics.StatCounter
+
 	shutSig *shutdown.Signaller
 }
 
@@ -213,6 +232,7 @@ func switchOutputFromParsed(conf *service.ParsedConfig, mgr bundle.NewManagement
 		logger:       mgr.Logger(),
 		transactions: nil,
 		strictMode:   strictMode,
+		unmatched:    -1,
 		shutSig:      shutdown.NewSignaller(),
 	}
 
@@ -251,6 +271,35 @@ func switchOutputFromParsed(conf *service.ParsedConfig, mgr bundle.NewManagement
 		}
 	}
 
+	if conf.Contains(soFieldUnmatched) {
+		if strictMode {
+			return nil, fmt.Errorf("field '%v' cannot be set when '%v' is enabled", soFieldUnmatched, soFieldStrictMode)
+		}
+		w, err := conf.FieldOutput(soFieldUnmatched)
+		if err != nil {
+			return nil, err
+		}
+		unmatched := interop.UnwrapOwnedOutput(w)
+		if retryUntilSuccess {
+			if unmatched, err = RetryOutputIndefinitely(mgr.IntoPath("switch", soFieldUnmatched), unmatched); err != nil {
+				return nil, fmt.Errorf("failed to create unmatched output: %v", err)
+			}
+		}
+		o.unmatched = len(o.outputs)
+		o.outputs = append(o.outputs, unmatched)
+	}
+
+	mRouted := mgr.Metrics().GetCounterVec("switch_output_routed", "case")
+	o.mRouted = make([]metrics.StatCounter, len(o.outputs))
+	for i := range o.mRouted {
+		if i == o.unmatched {
+			o.mRouted[i] = mRouted.With(soFieldUnmatched)
+		} else {
+			o.mRouted[i] = mRouted.With(strconv.Itoa(i))
+		}
+	}
+	o.mDropped = mgr.Metrics().GetCounter("switch_output_dropped")
+
 	o.outputTSChans = make([]chan message.Transaction, len(o.outputs))
 	for i := range o.outputTSChans {
 		o.outputTSChans[i] = make(chan message.Transaction)
@@ -424,7 +473,7 @@ func (o *switchOutput) loop() {
 
 		group, trackedMsg := message.NewSortGroup(ts.Payload)
 
-		outputTargets := make([][]*message.Part, len(o.checks))
+		outputTargets := make([][]*message.Part, len(o.outputs))
 		if checksErr := trackedMsg.Iter(func(i int, p *message.Part) error {
 			routedAtLeastOnce := false
 			for j, exe := range o.checks {
@@ -439,15 +488,25 @@ func (o *switchOutput) loop() {
 				if test {
 					routedAtLeastOnce = true
 					outputTargets[j] = append(outputTargets[j], p.ShallowCopy())
+					o.mRouted[j].Incr(1)
 					if !o.continues[j] {
 						return nil
 					}
 				}
 			}
-			if !routedAtLeastOnce && o.strictMode {
+			if routedAtLeastOnce {
+				return nil
+			}
+			if o.unmatched >= 0 {
+				outputTargets[o.unmatched] = append(outputTargets[o.unmatched], p.ShallowCopy())
+				o.mRouted[o.unmatched].Incr(1)
+				return nil
+			}
+			if o.strictMode {
 				o.logger.Error("Message failed to match against at least one output check with strict mode enabled, it will be nacked and/or re-processed")
 				return ErrSwitchNoConditionMet
 			}
+			o.mDropped.Incr(1)
 			return nil
 		}); checksErr != nil {
 			if err := ts.Ack(shutCtx, checksErr); err != nil && shutCtx.Err() != nil {
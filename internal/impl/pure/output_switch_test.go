@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/redpanda-data/benthos/v4/internal/batch"
+	"github.com/redpanda-data/benthos/v4/internal/component/metrics"
 	"github.com/redpanda-data/benthos/v4/internal/manager/mock"
 	"github.com/redpanda-data/benthos/v4/internal/message"
 )
@@ -1014,3 +1015,109 @@ bpLoop:
 	close(doneChan)
 	wg.Wait()
 }
+
+func TestSwitchUnmatched(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	mgr := mock.NewManager()
+	stats := metrics.NewLocal()
+	mgr.M = stats
+
+	pConf, err := switchOutputSpec().ParseYAML(`
+cases:
+  - output:
+      drop: {}
+    check: 'this.foo == "bar"'
+  - output:
+      drop: {}
+    check: 'this.foo == "baz"'
+unmatched:
+  drop: {}
+`, nil)
+	require.NoError(t, err)
+
+	s, err := switchOutputFromParsed(pConf, mgr)
+	require.NoError(t, err)
+	require.Equal(t, 2, s.unmatched)
+
+	mockOutputs := []*mock.OutputChanneled{{}, {}, {}}
+	for i := 0; i < len(mockOutputs); i++ {
+		close(s.outputTSChans[i])
+		s.outputs[i] = mockOutputs[i]
+		s.outputTSChans[i] = make(chan message.Transaction)
+		_ = mockOutputs[i].Consume(s.outputTSChans[i])
+	}
+
+	readChan := make(chan message.Transaction)
+	resChan := make(chan error, 1)
+	require.NoError(t, s.Consume(readChan))
+
+	msg := message.QuickBatch([][]byte{
+		[]byte(`{"foo":"bar"}`),
+		[]byte(`{"foo":"qux"}`),
+		[]byte(`{"foo":"quz"}`),
+	})
+	select {
+	case readChan <- message.NewTransaction(msg, resChan):
+	case <-ctx.Done():
+		t.Fatal("Timed out waiting for output send")
+	}
+
+	for _, exp := range []struct {
+		output   int
+		contents []string
+	}{
+		{output: 0, contents: []string{`{"foo":"bar"}`}},
+		{output: 2, contents: []string{`{"foo":"qux"}`, `{"foo":"quz"}`}},
+	} {
+		select {
+		case ts := <-mockOutputs[exp.output].TChan:
+			var contents []string
+			for _, b := range message.GetAllBytes(ts.Payload) {
+				contents = append(contents, string(b))
+			}
+			assert.Equal(t, exp.contents, contents)
+			require.NoError(t, ts.Ack(ctx, nil))
+		case <-mockOutputs[1].TChan:
+			t.Fatal("Received message on unexpected output")
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for output")
+		}
+	}
+
+	select {
+	case res := <-resChan:
+		require.NoError(t, res)
+	case <-ctx.Done():
+		t.Fatal("Timed out responding to output")
+	}
+
+	assert.Equal(t, map[string]int64{
+		`switch_output_routed{case="0"}`:         1,
+		`switch_output_routed{case="1"}`:         0,
+		`switch_output_routed{case="unmatched"}`: 2,
+		`switch_output_dropped`:                  0,
+	}, stats.GetCounters())
+
+	s.TriggerCloseNow()
+	require.NoError(t, s.WaitForClose(ctx))
+}
+
+func TestSwitchUnmatchedStrict(t *testing.T) {
+	pConf, err := switchOutputSpec().ParseYAML(`
+strict_mode: true
+cases:
+  - output:
+      drop: {}
+  - output:
+      drop: {}
+unmatched:
+  drop: {}
+`, nil)
+	require.NoError(t, err)
+
+	_, err = switchOutputFromParsed(pConf, mock.NewManager())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be set when 'strict_mode' is enabled")
+}
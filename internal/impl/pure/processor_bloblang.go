@@ -161,6 +161,6 @@ func (b *bloblangProc) ProcessBatch(ctx *processor.BatchProcContext, msg message
 	return []message.Batch{newParts}, nil
 }
 
-func (b *bloblangProc) Close(context.Context) error {
-	return nil
+func (b *bloblangProc) Close(ctx context.Context) error {
+	return b.exec.Close(ctx)
 }
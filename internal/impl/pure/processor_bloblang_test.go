@@ -216,3 +216,40 @@ func TestBloblangJSONError(t *testing.T) {
 	require.Error(t, resPart.ErrorGet())
 	assert.Equal(t, `failed assignment (line 2): invalid character 'h' in literal true (expecting 'r')`, resPart.ErrorGet().Error())
 }
+
+func TestBloblangThrowErrorCode(t *testing.T) {
+	msg := message.QuickBatch([][]byte{
+		[]byte(`{"amount":-5}`),
+		[]byte(`{"amount":5}`),
+	})
+
+	throwConf := processor.NewConfig()
+	throwConf.Type = "bloblang"
+	throwConf.Plugin = `
+root = if this.amount < 0 {
+  throw({"code": "E_NEGATIVE", "message": "amount is negative", "metadata": {"amount": this.amount}})
+} else { this }
+`
+	throwProc, err := mock.NewManager().NewProcessor(throwConf)
+	require.NoError(t, err)
+
+	codeConf := processor.NewConfig()
+	codeConf.Type = "bloblang"
+	codeConf.Plugin = `
+root.code = error_code()
+root.meta = error_metadata()
+`
+	codeProc, err := mock.NewManager().NewProcessor(codeConf)
+	require.NoError(t, err)
+
+	outMsgs, res := throwProc.ProcessBatch(context.Background(), msg)
+	require.NoError(t, res)
+	require.Len(t, outMsgs, 1)
+	assert.EqualError(t, outMsgs[0].Get(0).ErrorGet(), "failed assignment (line 2): amount is negative")
+
+	outMsgs, res = codeProc.ProcessBatch(context.Background(), outMsgs[0])
+	require.NoError(t, res)
+	require.Len(t, outMsgs, 1)
+	assert.Equal(t, `{"code":"E_NEGATIVE","meta":{"amount":-5}}`, string(outMsgs[0].Get(0).AsBytes()))
+	assert.Equal(t, `{"code":null,"meta":null}`, string(outMsgs[0].Get(1).AsBytes()))
+}
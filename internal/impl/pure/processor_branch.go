@@ -517,5 +517,13 @@ func (b *Branch) Close(ctx context.Context) error {
 			return err
 		}
 	}
+	for _, m := range []*mapping.Executor{b.requestMap, b.resultMap} {
+		if m == nil {
+			continue
+		}
+		if err := m.Close(ctx); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,6 +14,8 @@ import (
 	"github.com/redpanda-data/benthos/v4/internal/component/testutil"
 	"github.com/redpanda-data/benthos/v4/internal/manager/mock"
 	"github.com/redpanda-data/benthos/v4/internal/message"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+	"github.com/redpanda-data/benthos/v4/public/service"
 
 	_ "github.com/redpanda-data/benthos/v4/internal/impl/pure"
 )
@@ -273,3 +276,60 @@ branch:
 		})
 	}
 }
+
+func TestBranchClosesMappings(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	var closedMut sync.Mutex
+	var closed int
+
+	bEnv := bloblang.NewEnvironment()
+	require.NoError(t, bEnv.RegisterFunctionV2("stateful", bloblang.NewPluginSpec().
+		State(func() (any, error) {
+			return "state", nil
+		}, func(context.Context, any) error {
+			closedMut.Lock()
+			closed++
+			closedMut.Unlock()
+			return nil
+		}),
+		func(args *bloblang.ParsedParams) (bloblang.Function, error) {
+			state, err := args.State()
+			if err != nil {
+				return nil, err
+			}
+			return func() (any, error) { return state, nil }, nil
+		}))
+
+	env := service.NewEnvironment()
+	env.UseBloblangEnvironment(bEnv)
+
+	builder := env.NewStreamBuilder()
+	require.NoError(t, builder.SetYAML(`
+input:
+  generate:
+    count: 1
+    interval: ""
+    mapping: 'root = {}'
+pipeline:
+  processors:
+    - branch:
+        request_map: 'root = stateful()'
+        processors:
+          - noop: {}
+        result_map: 'root.a = stateful()'
+output:
+  drop: {}
+logger:
+  level: none
+`))
+
+	strm, err := builder.Build()
+	require.NoError(t, err)
+	require.NoError(t, strm.Run(ctx))
+
+	closedMut.Lock()
+	assert.Equal(t, 2, closed)
+	closedMut.Unlock()
+}
@@ -163,6 +163,6 @@ func (m *mappingProc) ProcessBatch(ctx *processor.BatchProcContext, b message.Ba
 	return []message.Batch{newBatch}, nil
 }
 
-func (m *mappingProc) Close(context.Context) error {
-	return nil
+func (m *mappingProc) Close(ctx context.Context) error {
+	return m.exec.Close(ctx)
 }
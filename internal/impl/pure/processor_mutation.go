@@ -167,6 +167,6 @@ func (m *mutationProc) ProcessBatch(ctx *processor.BatchProcContext, b message.B
 	return []message.Batch{newBatch}, nil
 }
 
-func (m *mutationProc) Close(context.Context) error {
-	return nil
+func (m *mutationProc) Close(ctx context.Context) error {
+	return m.exec.Close(ctx)
 }
@@ -93,7 +93,7 @@ output:
 `,
 		).
 		Fields(
-			service.NewBackOffField(rpFieldBackoff, true, nil),
+			service.NewBackOffPolicyField(rpFieldBackoff, true, nil),
 			service.NewProcessorListField(rpFieldProcessors).
 				Description("A list of xref:components:processors/about.adoc[processors] to execute on each message."),
 			service.NewBoolField(rpFieldParallel).
@@ -122,7 +122,9 @@ func init() {
 				p.children = append(p.children, interop.UnwrapOwnedProcessor(tmp))
 			}
 
-			if p.boff, err = conf.FieldBackOff(rpFieldBackoff); err != nil {
+			// NOTE: Back offs are stateful and so we create a fresh one for
+			// each message.
+			if p.boffCtor, err = conf.FieldBackOffPolicyCtor(rpFieldBackoff); err != nil {
 				return nil, err
 			}
 
@@ -139,7 +141,7 @@ func init() {
 
 type retryProc struct {
 	children []processor.V1
-	boff     *backoff.ExponentialBackOff
+	boffCtor func() backoff.BackOff
 	parallel bool
 	log      log.Modular
 }
@@ -187,9 +189,7 @@ func (r *retryProc) ProcessBatch(ctx *processor.BatchProcContext, msgs message.B
 }
 
 func (r *retryProc) dispatchMessage(ctx context.Context, p *message.Part) ([]message.Batch, error) {
-	// NOTE: We always ensure we start off with a copy of the reference backoff.
-	boff := *r.boff
-	boff.Reset()
+	boff := r.boffCtor()
 
 	// Ensure we do not start off with an error.
 	p.ErrorSet(nil)
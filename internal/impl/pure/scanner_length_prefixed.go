@@ -0,0 +1,170 @@
+package pure
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+const (
+	slpFieldPrefix  = "prefix"
+	slpFieldMaxSize = "max_size"
+)
+
+func lengthPrefixedScannerSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("4.29.0").
+		Summary("Split an input stream into messages that are each prefixed with their length in bytes.").
+		Description(`
+Binary protocols often frame messages by prefixing each one with its length rather than terminating them with a delimiter, which allows messages to contain any bytes. The prefix is removed from each message, and a stream that ends part way through a message results in an error.`).
+		Fields(
+			service.NewStringAnnotatedEnumField(slpFieldPrefix, map[string]string{
+				"uvarint":   "An unsigned variable length integer, as used by length delimited protocol buffers.",
+				"uint16_be": "A fixed 2 byte big endian unsigned integer.",
+				"uint16_le": "A fixed 2 byte little endian unsigned integer.",
+				"uint32_be": "A fixed 4 byte big endian unsigned integer.",
+				"uint32_le": "A fixed 4 byte little endian unsigned integer.",
+			}).
+				Description("The encoding of the length prefix of each message.").
+				Default("uint32_be"),
+			service.NewIntField(slpFieldMaxSize).
+				Description("The maximum size of a message in bytes, messages with a length prefix that exceeds this result in an error.").
+				Default(10_000_000),
+		)
+}
+
+func init() {
+	err := service.RegisterBatchScannerCreator("length_prefixed", lengthPrefixedScannerSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchScannerCreator, error) {
+			return lengthPrefixedScannerFromParsed(conf)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type lengthPrefixReader func(r *bufio.Reader) (uint64, error)
+
+func fixedLengthPrefixReader(size int, decode func([]byte) uint64) lengthPrefixReader {
+	return func(r *bufio.Reader) (uint64, error) {
+		prefix := make([]byte, size)
+		if _, err := io.ReadFull(r, prefix); err != nil {
+			return 0, err
+		}
+		return decode(prefix), nil
+	}
+}
+
+func lengthPrefixedScannerFromParsed(conf *service.ParsedConfig) (l *lengthPrefixedScannerCreator, err error) {
+	l = &lengthPrefixedScannerCreator{}
+
+	var prefix string
+	if prefix, err = conf.FieldString(slpFieldPrefix); err != nil {
+		return
+	}
+	switch prefix {
+	case "uvarint":
+		l.readPrefix = func(r *bufio.Reader) (uint64, error) {
+			return binary.ReadUvarint(r)
+		}
+	case "uint16_be":
+		l.readPrefix = fixedLengthPrefixReader(2, func(b []byte) uint64 {
+			return uint64(binary.BigEndian.Uint16(b))
+		})
+	case "uint16_le":
+		l.readPrefix = fixedLengthPrefixReader(2, func(b []byte) uint64 {
+			return uint64(binary.LittleEndian.Uint16(b))
+		})
+	case "uint32_be":
+		l.readPrefix = fixedLengthPrefixReader(4, func(b []byte) uint64 {
+			return uint64(binary.BigEndian.Uint32(b))
+		})
+	case "uint32_le":
+		l.readPrefix = fixedLengthPrefixReader(4, func(b []byte) uint64 {
+			return uint64(binary.LittleEndian.Uint32(b))
+		})
+	default:
+		return nil, fmt.Errorf("length prefix '%v' was not recognised", prefix)
+	}
+
+	var maxSize int
+	if maxSize, err = conf.FieldInt(slpFieldMaxSize); err != nil {
+		return
+	}
+	if maxSize <= 0 {
+		return nil, errors.New("max_size must be greater than zero")
+	}
+	l.maxSize = uint64(maxSize)
+	return
+}
+
+type lengthPrefixedScannerCreator struct {
+	readPrefix lengthPrefixReader
+	maxSize    uint64
+}
+
+func (c *lengthPrefixedScannerCreator) Create(rdr io.ReadCloser, aFn service.AckFunc, details *service.ScannerSourceDetails) (service.BatchScanner, error) {
+	return service.AutoAggregateBatchScannerAcks(&lengthPrefixedScanner{
+		r:          rdr,
+		buf:        bufio.NewReader(rdr),
+		readPrefix: c.readPrefix,
+		maxSize:    c.maxSize,
+	}, aFn), nil
+}
+
+func (c *lengthPrefixedScannerCreator) Close(context.Context) error {
+	return nil
+}
+
+type lengthPrefixedScanner struct {
+	r          io.ReadCloser
+	buf        *bufio.Reader
+	readPrefix lengthPrefixReader
+	maxSize    uint64
+}
+
+func (l *lengthPrefixedScanner) NextBatch(ctx context.Context) (service.MessageBatch, error) {
+	if l.r == nil {
+		return nil, io.EOF
+	}
+
+	size, err := l.readPrefix(l.buf)
+	if err != nil {
+		// Only a stream that ends before any of a prefix has been read ends
+		// cleanly.
+		if errors.Is(err, io.EOF) {
+			_ = l.r.Close()
+			l.r = nil
+			return nil, io.EOF
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, errors.New("stream ended part way through a length prefix")
+		}
+		return nil, err
+	}
+	if size > l.maxSize {
+		return nil, fmt.Errorf("message length %v exceeds the max_size %v", size, l.maxSize)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(l.buf, data); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("stream ended part way through a message of length %v", size)
+		}
+		return nil, err
+	}
+	return service.MessageBatch{service.NewMessage(data)}, nil
+}
+
+func (l *lengthPrefixedScanner) Close(ctx context.Context) error {
+	if l.r == nil {
+		return nil
+	}
+	return l.r.Close()
+}
@@ -0,0 +1,117 @@
+package pure_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/component/scanner/testutil"
+	"github.com/redpanda-data/benthos/v4/public/service"
+)
+
+func lengthPrefixedScannerFromYAML(t *testing.T, conf string) *service.OwnedScannerCreator {
+	t.Helper()
+
+	confSpec := service.NewConfigSpec().Field(service.NewScannerField("test"))
+	pConf, err := confSpec.ParseYAML(conf, nil)
+	require.NoError(t, err)
+
+	rdr, err := pConf.FieldScanner("test")
+	require.NoError(t, err)
+	return rdr
+}
+
+func TestLengthPrefixedScannerSuite(t *testing.T) {
+	messages := []string{"foo", "", "hello world", string(bytes.Repeat([]byte("x"), 300))}
+
+	for prefix, encode := range map[string]func(n int) []byte{
+		"uvarint": func(n int) []byte {
+			return binary.AppendUvarint(nil, uint64(n))
+		},
+		"uint16_be": func(n int) []byte {
+			return binary.BigEndian.AppendUint16(nil, uint16(n))
+		},
+		"uint16_le": func(n int) []byte {
+			return binary.LittleEndian.AppendUint16(nil, uint16(n))
+		},
+		"uint32_be": func(n int) []byte {
+			return binary.BigEndian.AppendUint32(nil, uint32(n))
+		},
+		"uint32_le": func(n int) []byte {
+			return binary.LittleEndian.AppendUint32(nil, uint32(n))
+		},
+	} {
+		encode := encode
+		t.Run(prefix, func(t *testing.T) {
+			var data []byte
+			for _, m := range messages {
+				data = append(data, encode(len(m))...)
+				data = append(data, m...)
+			}
+
+			rdr := lengthPrefixedScannerFromYAML(t, `
+test:
+  length_prefixed:
+    prefix: `+prefix+`
+`)
+			testutil.ScannerTestSuite(t, rdr, nil, data, messages...)
+		})
+	}
+}
+
+func TestLengthPrefixedScannerErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		errStr string
+	}{
+		{
+			name:   "truncated prefix",
+			data:   append(binary.BigEndian.AppendUint32(nil, 3), "foo\x00\x00"...),
+			errStr: "stream ended part way through a length prefix",
+		},
+		{
+			name:   "truncated message",
+			data:   append(binary.BigEndian.AppendUint32(nil, 3), "foo\x00\x00\x00\x05bar"...),
+			errStr: "stream ended part way through a message of length 5",
+		},
+		{
+			name:   "exceeds max size",
+			data:   append(binary.BigEndian.AppendUint32(nil, 3), "foo\x00\x00\x00\x0bhello world"...),
+			errStr: "message length 11 exceeds the max_size 10",
+		},
+	}
+
+	rdr := lengthPrefixedScannerFromYAML(t, `
+test:
+  length_prefixed:
+    max_size: 10
+`)
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			strm, err := rdr.Create(io.NopCloser(bytes.NewReader(test.data)), func(ctx context.Context, err error) error {
+				return nil
+			}, service.NewScannerSourceDetails())
+			require.NoError(t, err)
+
+			m, _, err := strm.NextBatch(context.Background())
+			require.NoError(t, err)
+			require.Len(t, m, 1)
+			mBytes, err := m[0].AsBytes()
+			require.NoError(t, err)
+			assert.Equal(t, "foo", string(mBytes))
+
+			_, _, err = strm.NextBatch(context.Background())
+			require.EqualError(t, err, test.errStr)
+
+			require.NoError(t, strm.Close(context.Background()))
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package jsonpatch
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/redpanda-data/benthos/v4/internal/value"
+)
+
+// Diff computes a JSON Patch that transforms one document into another. The
+// patch consists of `add`, `remove` and `replace` operations only, where
+// objects are compared key by key and arrays are compared index by index.
+func Diff(from, to any) []any {
+	ops := []any{}
+	return diff(ops, nil, from, to)
+}
+
+func pathWith(path []string, token string) []string {
+	return append(path[:len(path):len(path)], token)
+}
+
+func diff(ops []any, path []string, from, to any) []any {
+	switch f := from.(type) {
+	case map[string]any:
+		t, ok := to.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(f))
+		for k := range f {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if tv, exists := t[k]; exists {
+				ops = diff(ops, pathWith(path, k), f[k], tv)
+			} else {
+				ops = append(ops, map[string]any{
+					"op":   "remove",
+					"path": FormatPointer(pathWith(path, k)),
+				})
+			}
+		}
+		keys = keys[:0]
+		for k := range t {
+			if _, exists := f[k]; !exists {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ops = append(ops, map[string]any{
+				"op":    "add",
+				"path":  FormatPointer(pathWith(path, k)),
+				"value": value.IClone(t[k]),
+			})
+		}
+		return ops
+	case []any:
+		t, ok := to.([]any)
+		if !ok {
+			break
+		}
+		common := min(len(f), len(t))
+		for i := 0; i < common; i++ {
+			ops = diff(ops, pathWith(path, strconv.Itoa(i)), f[i], t[i])
+		}
+		// Removals are made from the end of the array so that the indexes of
+		// the remaining elements are unaffected.
+		for i := len(f) - 1; i >= common; i-- {
+			ops = append(ops, map[string]any{
+				"op":   "remove",
+				"path": FormatPointer(pathWith(path, strconv.Itoa(i))),
+			})
+		}
+		for i := common; i < len(t); i++ {
+			ops = append(ops, map[string]any{
+				"op":    "add",
+				"path":  FormatPointer(pathWith(path, strconv.Itoa(i))),
+				"value": value.IClone(t[i]),
+			})
+		}
+		return ops
+	}
+	if value.ITypeOf(from) == value.ITypeOf(to) && value.ICompare(from, to) {
+		return ops
+	}
+	return append(ops, map[string]any{
+		"op":    "replace",
+		"path":  FormatPointer(path),
+		"value": value.IClone(to),
+	})
+}
+
+// MergePatch applies a JSON Merge Patch to a document and returns the result.
+// Objects within the patch are merged recursively into the document, where
+// null values remove the corresponding fields, and any other value replaces
+// the target entirely. The document provided is not modified.
+func MergePatch(doc, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return value.IClone(patch)
+	}
+	d, ok := doc.(map[string]any)
+	if ok {
+		d = value.IClone(d).(map[string]any)
+	} else {
+		d = map[string]any{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(d, k)
+			continue
+		}
+		d[k] = MergePatch(d[k], v)
+	}
+	return d
+}
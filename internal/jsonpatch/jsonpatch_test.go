@@ -0,0 +1,250 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseJSON(t testing.TB, s string) any {
+	t.Helper()
+	var v any
+	require.NoError(t, json.Unmarshal([]byte(s), &v))
+	return v
+}
+
+func mustMarshal(t testing.TB, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestPointer(t *testing.T) {
+	tokens, err := ParsePointer("/a~1b/m~0n/0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a/b", "m~n", "0"}, tokens)
+	assert.Equal(t, "/a~1b/m~0n/0", FormatPointer(tokens))
+
+	tokens, err = ParsePointer("")
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+
+	_, err = ParsePointer("foo")
+	require.EqualError(t, err, `json pointer "foo" must begin with a slash`)
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		doc    string
+		patch  string
+		output string
+		err    string
+	}{
+		{
+			name:   "add object field",
+			doc:    `{"foo":"bar"}`,
+			patch:  `[{"op":"add","path":"/baz","value":"qux"}]`,
+			output: `{"baz":"qux","foo":"bar"}`,
+		},
+		{
+			name:   "add array element",
+			doc:    `{"foo":["bar","baz"]}`,
+			patch:  `[{"op":"add","path":"/foo/1","value":"qux"},{"op":"add","path":"/foo/-","value":"end"}]`,
+			output: `{"foo":["bar","qux","baz","end"]}`,
+		},
+		{
+			name:   "remove",
+			doc:    `{"baz":"qux","foo":["a","b","c"]}`,
+			patch:  `[{"op":"remove","path":"/baz"},{"op":"remove","path":"/foo/1"}]`,
+			output: `{"foo":["a","c"]}`,
+		},
+		{
+			name:   "replace",
+			doc:    `{"baz":"qux","foo":"bar"}`,
+			patch:  `[{"op":"replace","path":"/baz","value":"boo"}]`,
+			output: `{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			name:   "replace root",
+			doc:    `{"foo":"bar"}`,
+			patch:  `[{"op":"replace","path":"","value":[1]}]`,
+			output: `[1]`,
+		},
+		{
+			name:   "move",
+			doc:    `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			patch:  `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			output: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			name:   "move array element",
+			doc:    `{"foo":["all","grass","cows","eat"]}`,
+			patch:  `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			output: `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			name:   "copy",
+			doc:    `{"foo":{"bar":[1]}}`,
+			patch:  `[{"op":"copy","from":"/foo","path":"/baz"},{"op":"add","path":"/baz/bar/-","value":2}]`,
+			output: `{"baz":{"bar":[1,2]},"foo":{"bar":[1]}}`,
+		},
+		{
+			name:   "test success",
+			doc:    `{"baz":"qux","foo":["a",2,"c"]}`,
+			patch:  `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			output: `{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{
+			name:  "test failure",
+			doc:   `{"baz":"qux"}`,
+			patch: `[{"op":"test","path":"/baz","value":"bar"}]`,
+			err:   "operation 0: test failed, value at /baz does not match",
+		},
+		{
+			name:  "add to missing parent",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+			err:   "operation 0: path /baz does not exist",
+		},
+		{
+			name:  "remove missing",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"remove","path":"/baz"}]`,
+			err:   "operation 0: path /baz does not exist",
+		},
+		{
+			name:  "index out of bounds",
+			doc:   `{"foo":["bar"]}`,
+			patch: `[{"op":"add","path":"/foo/2","value":"qux"}]`,
+			err:   "operation 0: path /foo/2: array index 2 is out of bounds",
+		},
+		{
+			name:  "leading zero index",
+			doc:   `{"foo":["bar","baz"]}`,
+			patch: `[{"op":"remove","path":"/foo/01"}]`,
+			err:   `operation 0: path /foo/01: invalid array index "01"`,
+		},
+		{
+			name:  "move into child",
+			doc:   `{"foo":{"bar":"baz"}}`,
+			patch: `[{"op":"move","from":"/foo","path":"/foo/bar/child"}]`,
+			err:   "operation 0: a value cannot be moved into one of its children",
+		},
+		{
+			name:  "unknown op",
+			doc:   `{}`,
+			patch: `[{"op":"nope","path":"/foo"}]`,
+			err:   `operation 0: unrecognised operation "nope"`,
+		},
+		{
+			name:  "missing value",
+			doc:   `{}`,
+			patch: `[{"op":"add","path":"/foo"}]`,
+			err:   "operation 0: missing field value",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			doc := parseJSON(t, test.doc)
+			res, err := Apply(doc, parseJSON(t, test.patch).([]any))
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, parseJSON(t, test.output), res)
+			assert.Equal(t, parseJSON(t, test.doc), doc, "original document modified")
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		to     string
+		output string
+	}{
+		{
+			name:   "equal",
+			from:   `{"a":[1,{"b":true}]}`,
+			to:     `{"a":[1,{"b":true}]}`,
+			output: `[]`,
+		},
+		{
+			name:   "objects",
+			from:   `{"a":1,"b":{"c":"d","e":"f"},"g/h":1}`,
+			to:     `{"a":2,"b":{"c":"d","x":"y"},"i":null}`,
+			output: `[{"op":"replace","path":"/a","value":2},{"op":"remove","path":"/b/e"},{"op":"add","path":"/b/x","value":"y"},{"op":"remove","path":"/g~1h"},{"op":"add","path":"/i","value":null}]`,
+		},
+		{
+			name:   "arrays",
+			from:   `{"a":[1,2,3,4],"b":[1]}`,
+			to:     `{"a":[1,5],"b":[1,2,3]}`,
+			output: `[{"op":"replace","path":"/a/1","value":5},{"op":"remove","path":"/a/3"},{"op":"remove","path":"/a/2"},{"op":"add","path":"/b/1","value":2},{"op":"add","path":"/b/2","value":3}]`,
+		},
+		{
+			name:   "type change",
+			from:   `{"a":"1"}`,
+			to:     `{"a":1}`,
+			output: `[{"op":"replace","path":"/a","value":1}]`,
+		},
+		{
+			name:   "root",
+			from:   `{"a":1}`,
+			to:     `[1]`,
+			output: `[{"op":"replace","path":"","value":[1]}]`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			from, to := parseJSON(t, test.from), parseJSON(t, test.to)
+			patch := Diff(from, to)
+			assert.Equal(t, parseJSON(t, test.output), parseJSON(t, mustMarshal(t, patch)))
+
+			res, err := Apply(from, patch)
+			require.NoError(t, err)
+			assert.Equal(t, to, res)
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// Test cases from RFC 7386 Appendix A.
+	tests := []struct {
+		doc    string
+		patch  string
+		output string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for i, test := range tests {
+		doc := parseJSON(t, test.doc)
+		res := MergePatch(doc, parseJSON(t, test.patch))
+		assert.Equal(t, parseJSON(t, test.output), res, i)
+		assert.Equal(t, parseJSON(t, test.doc), doc, i)
+	}
+}
@@ -0,0 +1,234 @@
+package jsonpatch
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/redpanda-data/benthos/v4/internal/value"
+)
+
+// Apply applies a JSON Patch, which is an array of operation objects, to a
+// document and returns the result. The document provided is not modified.
+//
+// Operations are applied in order and if any of them fails, including a `test`
+// operation, an error is returned.
+func Apply(doc any, patch []any) (any, error) {
+	doc = value.IClone(doc)
+	for i, rawOp := range patch {
+		var err error
+		if doc, err = applyOp(doc, rawOp); err != nil {
+			return nil, fmt.Errorf("operation %v: %w", i, err)
+		}
+	}
+	return doc, nil
+}
+
+func opString(op map[string]any, field string) (string, error) {
+	v, exists := op[field]
+	if !exists {
+		return "", fmt.Errorf("missing field %v", field)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("field %v: %w", field, value.NewTypeError(v, value.TString))
+	}
+	return s, nil
+}
+
+func opPointer(op map[string]any, field string) ([]string, error) {
+	s, err := opString(op, field)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePointer(s)
+}
+
+func applyOp(doc, rawOp any) (any, error) {
+	op, ok := rawOp.(map[string]any)
+	if !ok {
+		return nil, value.NewTypeError(rawOp, value.TObject)
+	}
+
+	name, err := opString(op, "op")
+	if err != nil {
+		return nil, err
+	}
+	path, err := opPointer(op, "path")
+	if err != nil {
+		return nil, err
+	}
+
+	opValue := func() (any, error) {
+		v, exists := op["value"]
+		if !exists {
+			return nil, errors.New("missing field value")
+		}
+		return value.IClone(v), nil
+	}
+
+	switch name {
+	case "add":
+		v, err := opValue()
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, v)
+	case "remove":
+		doc, _, err = remove(doc, path)
+		return doc, err
+	case "replace":
+		v, err := opValue()
+		if err != nil {
+			return nil, err
+		}
+		if doc, _, err = remove(doc, path); err != nil {
+			return nil, err
+		}
+		return add(doc, path, v)
+	case "move":
+		from, err := opPointer(op, "from")
+		if err != nil {
+			return nil, err
+		}
+		if len(from) < len(path) && isPrefix(from, path) {
+			return nil, errors.New("a value cannot be moved into one of its children")
+		}
+		var v any
+		if doc, v, err = remove(doc, from); err != nil {
+			return nil, err
+		}
+		return add(doc, path, v)
+	case "copy":
+		from, err := opPointer(op, "from")
+		if err != nil {
+			return nil, err
+		}
+		v, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value.IClone(v))
+	case "test":
+		expected, err := opValue()
+		if err != nil {
+			return nil, err
+		}
+		v, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !value.ICompare(v, expected) {
+			return nil, fmt.Errorf("test failed, value at %v does not match", FormatPointer(path))
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unrecognised operation %q", name)
+}
+
+func isPrefix(prefix, path []string) bool {
+	for i, t := range prefix {
+		if path[i] != t {
+			return false
+		}
+	}
+	return true
+}
+
+func get(doc any, path []string) (any, error) {
+	for i, t := range path {
+		switch c := doc.(type) {
+		case map[string]any:
+			v, exists := c[t]
+			if !exists {
+				return nil, fmt.Errorf("path %v does not exist", FormatPointer(path[:i+1]))
+			}
+			doc = v
+		case []any:
+			idx, err := arrayIndex(t, len(c), false)
+			if err != nil {
+				return nil, fmt.Errorf("path %v: %w", FormatPointer(path[:i+1]), err)
+			}
+			doc = c[idx]
+		default:
+			return nil, fmt.Errorf("path %v does not exist", FormatPointer(path[:i+1]))
+		}
+	}
+	return doc, nil
+}
+
+// add sets a value at a path, where the parent of the path must exist, and
+// returns the resulting document.
+func add(doc any, path []string, v any) (any, error) {
+	if len(path) == 0 {
+		return v, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+	switch c := parent.(type) {
+	case map[string]any:
+		c[last] = v
+		return doc, nil
+	case []any:
+		idx, err := arrayIndex(last, len(c), true)
+		if err != nil {
+			return nil, fmt.Errorf("path %v: %w", FormatPointer(path), err)
+		}
+		c = append(c, nil)
+		copy(c[idx+1:], c[idx:])
+		c[idx] = v
+		return set(doc, path[:len(path)-1], c), nil
+	}
+	return nil, fmt.Errorf("path %v does not exist", FormatPointer(path))
+}
+
+// remove deletes the value at a path, returning the resulting document and the
+// removed value.
+func remove(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	last := path[len(path)-1]
+	switch c := parent.(type) {
+	case map[string]any:
+		v, exists := c[last]
+		if !exists {
+			return nil, nil, fmt.Errorf("path %v does not exist", FormatPointer(path))
+		}
+		delete(c, last)
+		return doc, v, nil
+	case []any:
+		idx, err := arrayIndex(last, len(c), false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("path %v: %w", FormatPointer(path), err)
+		}
+		v := c[idx]
+		c = append(c[:idx:idx], c[idx+1:]...)
+		return set(doc, path[:len(path)-1], c), v, nil
+	}
+	return nil, nil, fmt.Errorf("path %v does not exist", FormatPointer(path))
+}
+
+// set replaces the value at an existing path, which is required when an array
+// is resized.
+func set(doc any, path []string, v any) any {
+	if len(path) == 0 {
+		return v
+	}
+	parent, _ := get(doc, path[:len(path)-1])
+	last := path[len(path)-1]
+	switch c := parent.(type) {
+	case map[string]any:
+		c[last] = v
+	case []any:
+		idx, _ := arrayIndex(last, len(c), false)
+		c[idx] = v
+	}
+	return doc
+}
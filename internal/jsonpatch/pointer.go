@@ -0,0 +1,62 @@
+// Package jsonpatch implements the computation and application of JSON Patch
+// documents as described in RFC 6902, along with JSON Merge Patch as described
+// in RFC 7386, on generic structured values.
+package jsonpatch
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParsePointer parses a JSON Pointer as described in RFC 6901 into its
+// unescaped reference tokens.
+func ParsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if p[0] != '/' {
+		return nil, fmt.Errorf("json pointer %q must begin with a slash", p)
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// FormatPointer formats a slice of reference tokens as a JSON Pointer.
+func FormatPointer(tokens []string) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteByte('/')
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(t, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+var errEndOfArray = errors.New("the end of array token can only be used when adding a value")
+
+// arrayIndex parses a reference token as an index of an array of a given
+// length. When allowEnd is true the index may equal the length of the array,
+// which is also the result of the token `-`.
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if token == "-" {
+		if !allowEnd {
+			return 0, errEndOfArray
+		}
+		return length, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i > length || (i == length && !allowEnd) {
+		return 0, fmt.Errorf("array index %v is out of bounds", i)
+	}
+	return i, nil
+}
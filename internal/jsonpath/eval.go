@@ -0,0 +1,469 @@
+package jsonpath
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/redpanda-data/benthos/v4/internal/value"
+)
+
+// Query executes the path against a structured value and returns the list of
+// matched values in the order they were found. Object members are visited in
+// lexicographical order of their keys when selected with wildcards or
+// descendant segments in order to keep results deterministic.
+func (p *Path) Query(root any) []any {
+	return querySegments(p.segments, root, root)
+}
+
+func querySegments(segments []segment, root, current any) []any {
+	nodes := []any{current}
+	for _, seg := range segments {
+		var next []any
+		for _, n := range nodes {
+			next = seg.apply(root, n, next)
+		}
+		nodes = next
+	}
+	if nodes == nil {
+		nodes = []any{}
+	}
+	return nodes
+}
+
+type segment struct {
+	descendant bool
+	selectors  []selector
+}
+
+func (s segment) apply(root, v any, out []any) []any {
+	if !s.descendant {
+		for _, sel := range s.selectors {
+			out = sel.apply(root, v, out)
+		}
+		return out
+	}
+	for _, d := range descendants(v, nil) {
+		for _, sel := range s.selectors {
+			out = sel.apply(root, d, out)
+		}
+	}
+	return out
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// descendants returns a value and all of its descendants in document order.
+func descendants(v any, out []any) []any {
+	out = append(out, v)
+	switch t := v.(type) {
+	case map[string]any:
+		for _, k := range sortedKeys(t) {
+			out = descendants(t[k], out)
+		}
+	case []any:
+		for _, e := range t {
+			out = descendants(e, out)
+		}
+	}
+	return out
+}
+
+//------------------------------------------------------------------------------
+
+type selector interface {
+	apply(root, v any, out []any) []any
+}
+
+type nameSelector string
+
+func (s nameSelector) apply(_, v any, out []any) []any {
+	if m, ok := v.(map[string]any); ok {
+		if e, exists := m[string(s)]; exists {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+type wildcardSelector struct{}
+
+func (wildcardSelector) apply(_, v any, out []any) []any {
+	switch t := v.(type) {
+	case map[string]any:
+		for _, k := range sortedKeys(t) {
+			out = append(out, t[k])
+		}
+	case []any:
+		out = append(out, t...)
+	}
+	return out
+}
+
+type indexSelector int
+
+func (s indexSelector) apply(_, v any, out []any) []any {
+	arr, ok := v.([]any)
+	if !ok {
+		return out
+	}
+	i := int(s)
+	if i < 0 {
+		i += len(arr)
+	}
+	if i >= 0 && i < len(arr) {
+		out = append(out, arr[i])
+	}
+	return out
+}
+
+type sliceSelector struct {
+	start, end *int
+	step       int
+}
+
+func normalizeIndex(i, length int) int {
+	if i < 0 {
+		return length + i
+	}
+	return i
+}
+
+func clamp(i, lower, upper int) int {
+	if i < lower {
+		return lower
+	}
+	if i > upper {
+		return upper
+	}
+	return i
+}
+
+func (s sliceSelector) apply(_, v any, out []any) []any {
+	arr, ok := v.([]any)
+	if !ok || s.step == 0 {
+		return out
+	}
+
+	n := len(arr)
+	if s.step > 0 {
+		lower, upper := 0, n
+		if s.start != nil {
+			lower = clamp(normalizeIndex(*s.start, n), 0, n)
+		}
+		if s.end != nil {
+			upper = clamp(normalizeIndex(*s.end, n), 0, n)
+		}
+		for i := lower; i < upper; i += s.step {
+			out = append(out, arr[i])
+		}
+		return out
+	}
+
+	upper, lower := n-1, -1
+	if s.start != nil {
+		upper = clamp(normalizeIndex(*s.start, n), -1, n-1)
+	}
+	if s.end != nil {
+		lower = clamp(normalizeIndex(*s.end, n), -1, n-1)
+	}
+	for i := upper; i > lower; i += s.step {
+		out = append(out, arr[i])
+	}
+	return out
+}
+
+type filterSelector struct {
+	expr filterExpr
+}
+
+func (s filterSelector) apply(root, v any, out []any) []any {
+	switch t := v.(type) {
+	case map[string]any:
+		for _, k := range sortedKeys(t) {
+			if s.expr.test(root, t[k]) {
+				out = append(out, t[k])
+			}
+		}
+	case []any:
+		for _, e := range t {
+			if s.expr.test(root, e) {
+				out = append(out, e)
+			}
+		}
+	}
+	return out
+}
+
+//------------------------------------------------------------------------------
+
+type filterExpr interface {
+	test(root, current any) bool
+}
+
+type orExpr struct{ l, r filterExpr }
+
+func (e orExpr) test(root, current any) bool {
+	return e.l.test(root, current) || e.r.test(root, current)
+}
+
+type andExpr struct{ l, r filterExpr }
+
+func (e andExpr) test(root, current any) bool {
+	return e.l.test(root, current) && e.r.test(root, current)
+}
+
+type notExpr struct{ e filterExpr }
+
+func (e notExpr) test(root, current any) bool {
+	return !e.e.test(root, current)
+}
+
+type existsExpr struct{ q queryOperand }
+
+func (e existsExpr) test(root, current any) bool {
+	return len(e.q.nodes(root, current)) > 0
+}
+
+type functionTestExpr struct{ f *functionOperand }
+
+func (e functionTestExpr) test(root, current any) bool {
+	v, _ := e.f.value(root, current)
+	b, _ := v.(bool)
+	return b
+}
+
+type comparisonExpr struct {
+	op   string
+	l, r operand
+}
+
+func (e comparisonExpr) test(root, current any) bool {
+	l, lOk := e.l.value(root, current)
+	r, rOk := e.r.value(root, current)
+
+	switch e.op {
+	case "==":
+		return equal(l, lOk, r, rOk)
+	case "!=":
+		return !equal(l, lOk, r, rOk)
+	case "<":
+		return less(l, lOk, r, rOk)
+	case ">":
+		return less(r, rOk, l, lOk)
+	case "<=":
+		return less(l, lOk, r, rOk) || equal(l, lOk, r, rOk)
+	}
+	return less(r, rOk, l, lOk) || equal(l, lOk, r, rOk)
+}
+
+// equal compares two values where a false ok indicates the absence of a
+// value, which is only equal to another absent value.
+func equal(l any, lOk bool, r any, rOk bool) bool {
+	if !lOk || !rOk {
+		return lOk == rOk
+	}
+	return value.ICompare(l, r)
+}
+
+func less(l any, lOk bool, r any, rOk bool) bool {
+	if !lOk || !rOk {
+		return false
+	}
+	if ln, err := value.IGetNumber(l); err == nil {
+		rn, err := value.IGetNumber(r)
+		return err == nil && ln < rn
+	}
+	if ls, isStr := l.(string); isStr {
+		rs, isStr := r.(string)
+		return isStr && ls < rs
+	}
+	return false
+}
+
+//------------------------------------------------------------------------------
+
+type operand interface {
+	// comparable returns an error if the operand cannot be used within a
+	// comparison.
+	comparable() error
+
+	// value returns the value of the operand, or false if there isn't one.
+	value(root, current any) (any, bool)
+}
+
+type literalOperand struct {
+	v any
+}
+
+func (literalOperand) comparable() error {
+	return nil
+}
+
+func (l literalOperand) value(_, _ any) (any, bool) {
+	return l.v, true
+}
+
+type queryOperand struct {
+	relative bool
+	segments []segment
+}
+
+func (queryOperand) comparable() error {
+	return nil
+}
+
+func (q queryOperand) nodes(root, current any) []any {
+	if !q.relative {
+		current = root
+	}
+	return querySegments(q.segments, root, current)
+}
+
+// value returns the value of a query that results in exactly one node.
+func (q queryOperand) value(root, current any) (any, bool) {
+	nodes := q.nodes(root, current)
+	if len(nodes) != 1 {
+		return nil, false
+	}
+	return nodes[0], true
+}
+
+//------------------------------------------------------------------------------
+
+type paramKind int
+
+const (
+	paramValue paramKind = iota
+	paramNodes
+)
+
+type resultKind int
+
+const (
+	resultValue resultKind = iota
+	resultLogical
+)
+
+type functionSpec struct {
+	params []paramKind
+	result resultKind
+	regexp bool
+
+	// fn is called with a []any for node-set parameters, and for value
+	// parameters either the value or nothing{} when absent.
+	fn func(f *functionOperand, args []any) (any, bool)
+}
+
+type nothing struct{}
+
+var functions = map[string]functionSpec{
+	"length": {
+		params: []paramKind{paramValue},
+		result: resultValue,
+		fn: func(_ *functionOperand, args []any) (any, bool) {
+			switch t := args[0].(type) {
+			case string:
+				return float64(utf8.RuneCountInString(t)), true
+			case []any:
+				return float64(len(t)), true
+			case map[string]any:
+				return float64(len(t)), true
+			}
+			return nil, false
+		},
+	},
+	"count": {
+		params: []paramKind{paramNodes},
+		result: resultValue,
+		fn: func(_ *functionOperand, args []any) (any, bool) {
+			return float64(len(args[0].([]any))), true
+		},
+	},
+	"value": {
+		params: []paramKind{paramNodes},
+		result: resultValue,
+		fn: func(_ *functionOperand, args []any) (any, bool) {
+			nodes := args[0].([]any)
+			if len(nodes) != 1 {
+				return nil, false
+			}
+			return nodes[0], true
+		},
+	},
+	"match":  regexpFunction(),
+	"search": regexpFunction(),
+}
+
+func regexpFunction() functionSpec {
+	return functionSpec{
+		params: []paramKind{paramValue, paramValue},
+		result: resultLogical,
+		regexp: true,
+		fn: func(f *functionOperand, args []any) (any, bool) {
+			s, isStr := args[0].(string)
+			pattern, isPatternStr := args[1].(string)
+			if !isStr || !isPatternStr {
+				return false, true
+			}
+			re := f.re
+			if re == nil {
+				var err error
+				if re, err = compileFunctionRegexp(f.name, pattern); err != nil {
+					return false, true
+				}
+			}
+			return re.MatchString(s), true
+		},
+	}
+}
+
+func checkParam(kind paramKind, arg operand) error {
+	switch kind {
+	case paramNodes:
+		if _, isQuery := arg.(queryOperand); !isQuery {
+			return errors.New("expected a query")
+		}
+	case paramValue:
+		return arg.comparable()
+	}
+	return nil
+}
+
+type functionOperand struct {
+	name string
+	spec functionSpec
+	args []operand
+	re   *regexp.Regexp
+}
+
+func (f *functionOperand) comparable() error {
+	if f.spec.result != resultValue {
+		return errors.New("result of function '" + f.name + "' cannot be compared")
+	}
+	return nil
+}
+
+func (f *functionOperand) value(root, current any) (any, bool) {
+	args := make([]any, len(f.args))
+	for i, a := range f.args {
+		if f.spec.params[i] == paramNodes {
+			args[i] = a.(queryOperand).nodes(root, current)
+			continue
+		}
+		v, ok := a.value(root, current)
+		if !ok {
+			v = nothing{}
+		}
+		args[i] = v
+	}
+	return f.spec.fn(f, args)
+}
@@ -0,0 +1,110 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testStore = `{
+  "store": {
+    "book": [
+      { "category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95 },
+      { "category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99 },
+      { "category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99 },
+      { "category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99 }
+    ],
+    "bicycle": { "color": "red", "price": 399 }
+  },
+  "expensive": 10
+}`
+
+func TestQuery(t *testing.T) {
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(testStore), &doc))
+
+	tests := map[string][]any{
+		`$.store.book[*].author`:                     {"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"},
+		`$..author`:                                  {"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"},
+		`$.store.*.color`:                            {"red"},
+		`$.store..price`:                             {399.0, 8.95, 12.99, 8.99, 22.99},
+		`$..book[2].title`:                           {"Moby Dick"},
+		`$..book[-1].title`:                          {"The Lord of the Rings"},
+		`$..book[0,1].title`:                         {"Sayings of the Century", "Sword of Honour"},
+		`$..book[:2].title`:                          {"Sayings of the Century", "Sword of Honour"},
+		`$..book[1:3].title`:                         {"Sword of Honour", "Moby Dick"},
+		`$..book[::-2].title`:                        {"The Lord of the Rings", "Sword of Honour"},
+		`$..book[-2:].price`:                         {8.99, 22.99},
+		`$..book[?(@.isbn)].title`:                   {"Moby Dick", "The Lord of the Rings"},
+		`$.store.book[?(@.price<10)].title`:          {"Sayings of the Century", "Moby Dick"},
+		`$.store.book[?@.price > $.expensive].title`: {"Sword of Honour", "The Lord of the Rings"},
+		`$..book[?(@.category == 'fiction' && @.price < 10)].title`:             {"Moby Dick"},
+		`$..book[?(@.author == "Nigel Rees" || !@.isbn && @.price > 12)].price`: {8.95, 12.99},
+		`$..book[?(!(@.category == 'fiction'))].author`:                         {"Nigel Rees"},
+		`$..book[?(@.price != 8.95)]..isbn`:                                     {"0-553-21311-3", "0-395-19395-8"},
+		`$..book[?match(@.author, 'J.*')].title`:                                {"The Lord of the Rings"},
+		`$..book[?search(@.title, 'of')].price`:                                 {8.95, 12.99, 22.99},
+		`$..book[?length(@.title) > 20].price`:                                  {8.95, 22.99},
+		`$.store[?count(@.*) == 2].color`:                                       {"red"},
+		`$.store.bicycle['color', 'price']`:                                     {"red", 399.0},
+		`$["store"]['bicycle'].color`:                                           {"red"},
+		`$.store.book[4]`:                                                       {},
+		`$.nope`:                                                                {},
+		`$..book[?@.nope == @.alsonope].price`:                                  {8.95, 12.99, 8.99, 22.99},
+		`$..book[?@.price >= 22.99 || @.price <= 8.95].price`:                   {8.95, 22.99},
+		`$..[?@.color].price`:                                                   {399.0},
+		`$.expensive`:                                                           {10.0},
+	}
+
+	for expr, exp := range tests {
+		expr, exp := expr, exp
+		t.Run(expr, func(t *testing.T) {
+			p, err := Compile(expr)
+			require.NoError(t, err)
+			assert.Equal(t, exp, p.Query(doc))
+		})
+	}
+}
+
+func TestQueryRoot(t *testing.T) {
+	p, err := Compile(`$`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"foo"}, p.Query("foo"))
+}
+
+func TestQueryMixedNumbers(t *testing.T) {
+	p, err := Compile(`$[?@.n == 2 || @.n > 9].id`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{"a", "c"}, p.Query([]any{
+		map[string]any{"id": "a", "n": int64(2)},
+		map[string]any{"id": "b", "n": json.Number("3")},
+		map[string]any{"id": "c", "n": uint64(10)},
+	}))
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := map[string]string{
+		`store`:                       "char 0: expected path to begin with '$'",
+		`$.`:                          "char 2: expected a member name",
+		`$[`:                          "char 2: unexpected end of expression",
+		`$['foo`:                      "char 2: unterminated string literal",
+		`$[1 2]`:                      "char 4: expected ',' but found '2'",
+		`$[?@.a == ]`:                 "char 10: unexpected character ']'",
+		`$[?nope(@.a)]`:               "char 3: unsupported function 'nope'",
+		`$[?length(@.a)]`:             "char 3: result of function 'length' must be compared",
+		`$[?match(@.a, 'b') == true]`: "char 3: result of function 'match' cannot be compared",
+		`$[?count(1) == 1]`:           "char 3: function 'count' argument 1: expected a query",
+		`$[?'foo']`:                   "char 3: literals must be compared",
+		`$[?match(@.a, '(')]`:         "char 3: error parsing regexp: missing closing ): `^(?:()$`",
+		`$.foo bar`:                   "char 6: unexpected character 'b'",
+	}
+
+	for expr, exp := range tests {
+		_, err := Compile(expr)
+		require.Error(t, err, expr)
+		assert.EqualError(t, err, exp, expr)
+	}
+}
@@ -0,0 +1,552 @@
+// Package jsonpath implements JSONPath queries (RFC 9535) against the generic
+// structured values used throughout Benthos.
+package jsonpath
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Path is a compiled JSONPath expression.
+type Path struct {
+	segments []segment
+}
+
+// Compile parses a JSONPath expression.
+//
+// The supported syntax follows RFC 9535, including dot and bracket notation
+// child segments, descendant segments, wildcards, indexes, array slices and
+// filter expressions with comparisons, logical operators and the functions
+// length, count, match, search and value. Filters may optionally be written
+// within parentheses, as is common with older implementations, e.g.
+// `$.books[?(@.price < 10)]`.
+func Compile(expr string) (*Path, error) {
+	p := &parser{src: expr}
+	p.skipSpace()
+	if !p.consume("$") {
+		return nil, p.errorf("expected path to begin with '$'")
+	}
+	segments, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.done() {
+		return nil, p.errorf("unexpected character '%c'", p.peek())
+	}
+	return &Path{segments: segments}, nil
+}
+
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("char %v: %v", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() rune {
+	if p.done() {
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+	return r
+}
+
+func (p *parser) hasPrefix(s string) bool {
+	return strings.HasPrefix(p.src[p.pos:], s)
+}
+
+func (p *parser) consume(s string) bool {
+	if p.hasPrefix(s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *parser) skipSpace() {
+	for !p.done() {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) expect(s string) error {
+	p.skipSpace()
+	if !p.consume(s) {
+		if p.done() {
+			return p.errorf("expected '%v' but reached end of expression", s)
+		}
+		return p.errorf("expected '%v' but found '%c'", s, p.peek())
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+func isNameFirst(r rune) bool {
+	return r == '_' || r >= 0x80 || unicode.IsLetter(r)
+}
+
+func isNameChar(r rune) bool {
+	return isNameFirst(r) || r == '-' || unicode.IsDigit(r)
+}
+
+func (p *parser) parseName() (string, error) {
+	start := p.pos
+	if !isNameFirst(p.peek()) {
+		return "", p.errorf("expected a member name")
+	}
+	for !p.done() && isNameChar(p.peek()) {
+		p.pos += utf8.RuneLen(p.peek())
+	}
+	return p.src[start:p.pos], nil
+}
+
+// parseSegments parses segments until one can no longer be found, allowing
+// whitespace between segments but not within dot notation.
+func (p *parser) parseSegments() ([]segment, error) {
+	var segments []segment
+	for {
+		save := p.pos
+		p.skipSpace()
+
+		var seg segment
+		switch {
+		case p.consume(".."):
+			seg.descendant = true
+			switch {
+			case p.consume("*"):
+				seg.selectors = []selector{wildcardSelector{}}
+			case p.hasPrefix("["):
+				sels, err := p.parseBracket()
+				if err != nil {
+					return nil, err
+				}
+				seg.selectors = sels
+			default:
+				name, err := p.parseName()
+				if err != nil {
+					return nil, err
+				}
+				seg.selectors = []selector{nameSelector(name)}
+			}
+		case p.consume("."):
+			if p.consume("*") {
+				seg.selectors = []selector{wildcardSelector{}}
+			} else {
+				name, err := p.parseName()
+				if err != nil {
+					return nil, err
+				}
+				seg.selectors = []selector{nameSelector(name)}
+			}
+		case p.hasPrefix("["):
+			sels, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			seg.selectors = sels
+		default:
+			p.pos = save
+			return segments, nil
+		}
+		segments = append(segments, seg)
+	}
+}
+
+func (p *parser) parseBracket() ([]selector, error) {
+	p.consume("[")
+	var sels []selector
+	for {
+		p.skipSpace()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+
+		p.skipSpace()
+		if p.consume("]") {
+			return sels, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseSelector() (selector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return nameSelector(s), nil
+	case c == '*':
+		p.pos++
+		return wildcardSelector{}, nil
+	case c == '?':
+		p.pos++
+		e, err := p.parseLogicalOr()
+		if err != nil {
+			return nil, err
+		}
+		return filterSelector{expr: e}, nil
+	case c == ':' || c == '-' || (c >= '0' && c <= '9'):
+		return p.parseIndexOrSlice()
+	case p.done():
+		return nil, p.errorf("unexpected end of expression")
+	}
+	return nil, p.errorf("unexpected character '%c'", p.peek())
+}
+
+func (p *parser) parseInt() (int, bool, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for !p.done() && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		return 0, false, nil
+	}
+	i, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return 0, false, p.errorf("invalid integer: %v", p.src[start:p.pos])
+	}
+	return i, true, nil
+}
+
+func (p *parser) parseIndexOrSlice() (selector, error) {
+	start, hasStart, err := p.parseInt()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.peek() != ':' {
+		if !hasStart {
+			return nil, p.errorf("expected an index")
+		}
+		return indexSelector(start), nil
+	}
+
+	s := sliceSelector{step: 1}
+	if hasStart {
+		s.start = &start
+	}
+	p.pos++
+	p.skipSpace()
+	end, hasEnd, err := p.parseInt()
+	if err != nil {
+		return nil, err
+	}
+	if hasEnd {
+		s.end = &end
+	}
+	p.skipSpace()
+	if p.consume(":") {
+		p.skipSpace()
+		step, hasStep, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		if hasStep {
+			s.step = step
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) parseString() (string, error) {
+	quote := p.src[p.pos]
+	start := p.pos
+	p.pos++
+
+	var buf strings.Builder
+	for {
+		if p.done() {
+			p.pos = start
+			return "", p.errorf("unterminated string literal")
+		}
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return buf.String(), nil
+		case c == '\\':
+			if p.pos+1 >= len(p.src) {
+				p.pos = start
+				return "", p.errorf("unterminated string literal")
+			}
+			esc := p.src[p.pos+1]
+			p.pos += 2
+			switch esc {
+			case 'b':
+				buf.WriteByte('\b')
+			case 'f':
+				buf.WriteByte('\f')
+			case 'n':
+				buf.WriteByte('\n')
+			case 'r':
+				buf.WriteByte('\r')
+			case 't':
+				buf.WriteByte('\t')
+			case '/', '\\', '\'', '"':
+				buf.WriteByte(esc)
+			case 'u':
+				if p.pos+4 > len(p.src) {
+					return "", p.errorf("invalid unicode escape")
+				}
+				r, err := strconv.ParseUint(p.src[p.pos:p.pos+4], 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid unicode escape")
+				}
+				p.pos += 4
+				buf.WriteRune(rune(r))
+			default:
+				return "", p.errorf("invalid escape character '%c'", esc)
+			}
+		default:
+			buf.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+//------------------------------------------------------------------------------
+
+func (p *parser) parseLogicalOr() (filterExpr, error) {
+	l, err := p.parseLogicalAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !p.consume("||") {
+			return l, nil
+		}
+		r, err := p.parseLogicalAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = orExpr{l, r}
+	}
+}
+
+func (p *parser) parseLogicalAnd() (filterExpr, error) {
+	l, err := p.parseBasic()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !p.consume("&&") {
+			return l, nil
+		}
+		r, err := p.parseBasic()
+		if err != nil {
+			return nil, err
+		}
+		l = andExpr{l, r}
+	}
+}
+
+var comparisonOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func (p *parser) parseBasic() (filterExpr, error) {
+	p.skipSpace()
+	if p.consume("!") {
+		p.skipSpace()
+		if p.hasPrefix("=") {
+			return nil, p.errorf("unexpected character '='")
+		}
+		e, err := p.parseBasic()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{e}, nil
+	}
+	if p.consume("(") {
+		e, err := p.parseLogicalOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+
+	opStart := p.pos
+	l, err := p.parseComparable()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	for _, op := range comparisonOps {
+		if !p.consume(op) {
+			continue
+		}
+		if err := l.comparable(); err != nil {
+			p.pos = opStart
+			return nil, p.errorf("%v", err)
+		}
+		p.skipSpace()
+		rStart := p.pos
+		r, err := p.parseComparable()
+		if err != nil {
+			return nil, err
+		}
+		if err := r.comparable(); err != nil {
+			p.pos = rStart
+			return nil, p.errorf("%v", err)
+		}
+		return comparisonExpr{op: op, l: l, r: r}, nil
+	}
+
+	switch t := l.(type) {
+	case queryOperand:
+		return existsExpr{q: t}, nil
+	case *functionOperand:
+		if t.spec.result != resultLogical {
+			p.pos = opStart
+			return nil, p.errorf("result of function '%v' must be compared", t.name)
+		}
+		return functionTestExpr{f: t}, nil
+	}
+	p.pos = opStart
+	return nil, p.errorf("literals must be compared")
+}
+
+func (p *parser) parseComparable() (operand, error) {
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		segments, err := p.parseSegments()
+		if err != nil {
+			return nil, err
+		}
+		return queryOperand{relative: c == '@', segments: segments}, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return literalOperand{v: s}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	case p.consume("true"):
+		return literalOperand{v: true}, nil
+	case p.consume("false"):
+		return literalOperand{v: false}, nil
+	case p.consume("null"):
+		return literalOperand{v: nil}, nil
+	case c >= 'a' && c <= 'z':
+		return p.parseFunction()
+	case p.done():
+		return nil, p.errorf("unexpected end of expression")
+	}
+	return nil, p.errorf("unexpected character '%c'", p.peek())
+}
+
+func (p *parser) parseNumber() (operand, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for !p.done() && strings.ContainsRune("0123456789.eE+-", p.peek()) {
+		p.pos++
+	}
+	f, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("invalid number")
+	}
+	return literalOperand{v: f}, nil
+}
+
+func (p *parser) parseFunction() (operand, error) {
+	start := p.pos
+	for !p.done() && (unicode.IsLower(p.peek()) || unicode.IsDigit(p.peek()) || p.peek() == '_') {
+		p.pos++
+	}
+	name := p.src[start:p.pos]
+	spec, exists := functions[name]
+	if !exists {
+		p.pos = start
+		return nil, p.errorf("unsupported function '%v'", name)
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	f := &functionOperand{name: name, spec: spec}
+	for {
+		p.skipSpace()
+		if p.consume(")") {
+			break
+		}
+		if len(f.args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+			p.skipSpace()
+		}
+		arg, err := p.parseComparable()
+		if err != nil {
+			return nil, err
+		}
+		f.args = append(f.args, arg)
+	}
+
+	if len(f.args) != len(spec.params) {
+		p.pos = start
+		return nil, p.errorf("function '%v' expects %v arguments", name, len(spec.params))
+	}
+	for i, param := range spec.params {
+		if err := checkParam(param, f.args[i]); err != nil {
+			p.pos = start
+			return nil, p.errorf("function '%v' argument %v: %v", name, i+1, err)
+		}
+	}
+
+	// Regular expressions are compiled once when they're literals.
+	if spec.regexp {
+		if lit, isLit := f.args[1].(literalOperand); isLit {
+			pattern, _ := lit.v.(string)
+			re, err := compileFunctionRegexp(name, pattern)
+			if err != nil {
+				p.pos = start
+				return nil, p.errorf("%v", err)
+			}
+			f.re = re
+		}
+	}
+	return f, nil
+}
+
+func compileFunctionRegexp(name, pattern string) (*regexp.Regexp, error) {
+	if name == "match" {
+		pattern = "^(?:" + pattern + ")$"
+	}
+	return regexp.Compile(pattern)
+}
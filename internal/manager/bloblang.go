@@ -0,0 +1,106 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang"
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/internal/component"
+	"github.com/redpanda-data/benthos/v4/internal/component/cache"
+)
+
+var cacheGetFunctionSpec = query.NewFunctionSpec(
+	query.FunctionCategoryEnvironment, "cache_get",
+	"Obtains the value of a key from a xref:components:caches/about.adoc[cache resource] as a byte array, allowing enrichments to be performed within a mapping without the need for a `branch` and `cache` processor. If the key does not exist then the default value is returned when provided, otherwise an error is returned. This function can only be used within a config, as cache resources are otherwise unavailable.",
+	query.NewExampleSpec("",
+		`root = this
+root.user = cache_get("users", this.user_id).parse_json()`,
+	),
+	query.NewExampleSpec("A default value can be provided for keys that do not exist, which is only evaluated when needed.",
+		`root = this
+root.region = cache_get("regions", this.host, "unknown").string()`,
+	),
+).Param(query.ParamString("resource", "The name of the cache resource.")).
+	Param(query.ParamString("key", "The key to obtain.")).
+	Param(query.ParamQuery("default", "An optional value to return when the key does not exist.", true).Optional()).
+	Param(query.ParamString("timeout", "The maximum period of time to wait for the cache to return the key, after which an error is returned.").Default("5s")).
+	AtVersion("4.29.0").
+	MarkImpure()
+
+func init() {
+	if err := bloblang.GlobalEnvironment().RegisterFunction(cacheGetFunctionSpec, func(*query.ParsedParams) (query.Function, error) {
+		return nil, errors.New("cache resources are not available to this mapping")
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// withResourceFunctions returns a copy of a Bloblang environment where
+// functions that access resources are bound to the manager. Environments that
+// do not contain these functions are returned unchanged.
+func withResourceFunctions(env *bloblang.Environment, mgr *Type) *bloblang.Environment {
+	hasCacheGet := false
+	env.WalkFunctions(func(name string, _ query.FunctionSpec) {
+		if name == cacheGetFunctionSpec.Name {
+			hasCacheGet = true
+		}
+	})
+	if !hasCacheGet {
+		return env
+	}
+
+	env = env.WithoutFunctions()
+	if err := env.RegisterFunction(cacheGetFunctionSpec, func(args *query.ParsedParams) (query.Function, error) {
+		return cacheGetFunction(mgr, args)
+	}); err != nil {
+		panic(err)
+	}
+	return env
+}
+
+func cacheGetFunction(mgr *Type, args *query.ParsedParams) (query.Function, error) {
+	resource, err := args.FieldString("resource")
+	if err != nil {
+		return nil, err
+	}
+	key, err := args.FieldString("key")
+	if err != nil {
+		return nil, err
+	}
+	defaultFn, err := args.FieldOptionalQuery("default")
+	if err != nil {
+		return nil, err
+	}
+	timeoutStr, err := args.FieldString("timeout")
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse timeout: %w", err)
+	}
+	if timeout <= 0 {
+		return nil, fmt.Errorf("timeout must be greater than zero, got %v", timeout)
+	}
+	return query.ClosureFunction("function cache_get", func(ctx query.FunctionContext) (any, error) {
+		var value []byte
+		var getErr error
+		getCtx, done := context.WithTimeout(context.Background(), timeout)
+		defer done()
+		if err := mgr.AccessCache(getCtx, resource, func(c cache.V1) {
+			value, getErr = c.Get(getCtx, key)
+		}); err != nil {
+			return nil, err
+		}
+		if getErr != nil {
+			if errors.Is(getErr, component.ErrKeyNotFound) && defaultFn != nil {
+				return defaultFn.Exec(ctx)
+			}
+			return nil, fmt.Errorf("failed to get key %v from cache %v: %w", key, resource, getErr)
+		}
+		return value, nil
+	}, nil), nil
+}
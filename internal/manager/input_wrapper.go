@@ -18,23 +18,36 @@ var _ input.Streamed = &InputWrapper{}
 type inputCtrl struct {
 	input         input.Streamed
 	closedForSwap *int32
+
+	// swapped is closed once the input has been replaced.
+	swapped chan struct{}
+}
+
+func newInputCtrl(i input.Streamed) *inputCtrl {
+	var s int32
+	return &inputCtrl{
+		input:         i,
+		closedForSwap: &s,
+		swapped:       make(chan struct{}),
+	}
 }
 
 type InputWrapper struct {
 	ctrl      *inputCtrl
 	inputLock sync.Mutex
 
+	// Inputs that have been hot swapped out but are yet to finish closing.
+	draining   map[input.Streamed]struct{}
+	drainingWG sync.WaitGroup
+
 	tranChan chan message.Transaction
 	shutSig  *shutdown.Signaller
 }
 
 func WrapInput(i input.Streamed) *InputWrapper {
-	var s int32
 	w := &InputWrapper{
-		ctrl: &inputCtrl{
-			input:         i,
-			closedForSwap: &s,
-		},
+		ctrl:     newInputCtrl(i),
+		draining: map[input.Streamed]struct{}{},
 		tranChan: make(chan message.Transaction),
 		shutSig:  shutdown.NewSignaller(),
 	}
@@ -61,13 +74,47 @@ func (w *InputWrapper) CloseExistingInput(ctx context.Context, forSwap bool) err
 }
 
 func (w *InputWrapper) SwapInput(i input.Streamed) {
-	var s int32
 	w.inputLock.Lock()
-	w.ctrl = &inputCtrl{
-		input:         i,
-		closedForSwap: &s,
+	prev := w.ctrl
+	w.ctrl = newInputCtrl(i)
+	close(prev.swapped)
+	w.inputLock.Unlock()
+}
+
+// HotSwapInput replaces the input with a new one before the previous input is
+// closed, and therefore consumption continues from the new input whilst the
+// previous input finishes. The previous input is instructed to stop consuming
+// but is not forced to close, which allows transactions that it has already
+// sent to be acknowledged. The returned chan is closed once the previous input
+// has closed.
+func (w *InputWrapper) HotSwapInput(i input.Streamed) <-chan struct{} {
+	closed := make(chan struct{})
+
+	w.inputLock.Lock()
+	prev := w.ctrl
+	atomic.StoreInt32(prev.closedForSwap, 1)
+	w.ctrl = newInputCtrl(i)
+	close(prev.swapped)
+	if prev.input == nil {
+		w.inputLock.Unlock()
+		close(closed)
+		return closed
 	}
+	w.draining[prev.input] = struct{}{}
+	w.drainingWG.Add(1)
 	w.inputLock.Unlock()
+
+	prev.input.TriggerStopConsuming()
+	go func() {
+		defer w.drainingWG.Done()
+		_ = prev.input.WaitForClose(context.Background())
+
+		w.inputLock.Lock()
+		delete(w.draining, prev.input)
+		w.inputLock.Unlock()
+		close(closed)
+	}()
+	return closed
 }
 
 func (w *InputWrapper) TransactionChan() <-chan message.Transaction {
@@ -92,6 +139,24 @@ func (w *InputWrapper) loop() {
 			_ = tmpInput.WaitForClose(context.Background())
 		}
 
+		// Inputs that were swapped out might still be waiting on acks, and
+		// are forced to close if the wrapper is.
+		drained := make(chan struct{})
+		go func() {
+			w.drainingWG.Wait()
+			close(drained)
+		}()
+		select {
+		case <-drained:
+		case <-w.shutSig.HardStopChan():
+			w.inputLock.Lock()
+			for d := range w.draining {
+				d.TriggerCloseNow()
+			}
+			w.inputLock.Unlock()
+			<-drained
+		}
+
 		close(w.tranChan)
 		w.shutSig.TriggerHasStopped()
 	}()
@@ -101,6 +166,7 @@ func (w *InputWrapper) loop() {
 		var closedForSwap *int32
 
 		w.inputLock.Lock()
+		swapped := w.ctrl.swapped
 		if w.ctrl.input != nil {
 			tChan = w.ctrl.input.TransactionChan()
 			closedForSwap = w.ctrl.closedForSwap
@@ -118,6 +184,8 @@ func (w *InputWrapper) loop() {
 				if !open && atomic.LoadInt32(closedForSwap) == 0 {
 					return
 				}
+			case <-swapped:
+				continue
 			case <-w.shutSig.SoftStopChan():
 				return
 			}
@@ -126,6 +194,7 @@ func (w *InputWrapper) loop() {
 		if !open {
 			select {
 			case <-time.After(time.Millisecond * 100):
+			case <-swapped:
 			case <-w.shutSig.SoftStopChan():
 				return
 			}
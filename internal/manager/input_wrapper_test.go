@@ -2,6 +2,7 @@ package manager_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/redpanda-data/benthos/v4/internal/component/testutil"
 	"github.com/redpanda-data/benthos/v4/internal/manager"
 	bmock "github.com/redpanda-data/benthos/v4/internal/manager/mock"
+	"github.com/redpanda-data/benthos/v4/internal/message"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -78,3 +80,105 @@ generate:
 	iWrapper.TriggerStopConsuming()
 	require.NoError(t, iWrapper.WaitForClose(ctx))
 }
+
+// drainingInput does not finish closing until the closed chan is closed,
+// similar to an input waiting for its transactions to be acknowledged.
+type drainingInput struct {
+	*bmock.Input
+	closed chan struct{}
+}
+
+func (d *drainingInput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-d.closed:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func TestInputWrapperHotSwap(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	oldInput := &drainingInput{
+		Input:  &bmock.Input{TChan: make(chan message.Transaction)},
+		closed: make(chan struct{}),
+	}
+	iWrapper := manager.WrapInput(oldInput)
+
+	oldResChan := make(chan error, 1)
+	select {
+	case oldInput.TChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("old")}), oldResChan):
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+
+	var oldTran message.Transaction
+	select {
+	case oldTran = <-iWrapper.TransactionChan():
+		assert.Equal(t, "old", string(oldTran.Payload.Get(0).AsBytes()))
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+
+	newInput := &bmock.Input{TChan: make(chan message.Transaction)}
+	prevClosed := iWrapper.HotSwapInput(newInput)
+
+	// The new input is consumed from whilst the old one is still closing.
+	newResChan := make(chan error, 1)
+	select {
+	case newInput.TChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("new")}), newResChan):
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+	select {
+	case tran := <-iWrapper.TransactionChan():
+		assert.Equal(t, "new", string(tran.Payload.Get(0).AsBytes()))
+		require.NoError(t, tran.Ack(ctx, nil))
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+	require.NoError(t, <-newResChan)
+
+	// The transaction of the old input can still be acknowledged.
+	require.NoError(t, oldTran.Ack(ctx, errors.New("nope")))
+	require.EqualError(t, <-oldResChan, "nope")
+
+	select {
+	case <-prevClosed:
+		t.Fatal("Old input reported as closed early")
+	case <-time.After(time.Millisecond * 50):
+	}
+	close(oldInput.closed)
+	select {
+	case <-prevClosed:
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+
+	iWrapper.TriggerStopConsuming()
+	require.NoError(t, iWrapper.WaitForClose(ctx))
+}
+
+func TestInputWrapperHotSwapDraining(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	oldInput := &drainingInput{
+		Input:  &bmock.Input{TChan: make(chan message.Transaction)},
+		closed: make(chan struct{}),
+	}
+	iWrapper := manager.WrapInput(oldInput)
+	_ = iWrapper.HotSwapInput(&bmock.Input{TChan: make(chan message.Transaction)})
+
+	// The wrapper does not finish closing until the old input has.
+	iWrapper.TriggerStopConsuming()
+
+	waitCtx, waitDone := context.WithTimeout(ctx, time.Millisecond*50)
+	defer waitDone()
+	require.Error(t, iWrapper.WaitForClose(waitCtx))
+
+	close(oldInput.closed)
+	require.NoError(t, iWrapper.WaitForClose(ctx))
+}
@@ -35,6 +35,7 @@ type Manager struct {
 	Outputs    map[string]OutputWriter
 	Processors map[string]Processor
 	Pipes      map[string]<-chan message.Transaction
+	PipeSubs   *bundle.PipeSubscriptions
 	lock       sync.Mutex
 
 	// OnRegisterEndpoint can be set in order to intercept endpoints registered
@@ -57,6 +58,7 @@ func NewManager() *Manager {
 		Outputs:    map[string]OutputWriter{},
 		Processors: map[string]Processor{},
 		Pipes:      map[string]<-chan message.Transaction{},
+		PipeSubs:   bundle.NewPipeSubscriptions(),
 		CustomFS:   ifs.OS(),
 		M:          metrics.Noop(),
 		L:          log.Noop(),
@@ -354,6 +356,8 @@ func (m *Manager) RemoveOutput(ctx context.Context, name string) error {
 
 // GetPipe attempts to find a service wide transaction chan by its name.
 func (m *Manager) GetPipe(name string) (<-chan message.Transaction, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	if p, ok := m.Pipes[name]; ok {
 		return p, nil
 	}
@@ -362,10 +366,29 @@ func (m *Manager) GetPipe(name string) (<-chan message.Transaction, error) {
 
 // SetPipe registers a transaction chan under a name.
 func (m *Manager) SetPipe(name string, t <-chan message.Transaction) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	m.Pipes[name] = t
 }
 
 // UnsetPipe removes a named transaction chan.
 func (m *Manager) UnsetPipe(name string, t <-chan message.Transaction) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	delete(m.Pipes, name)
 }
+
+// SubscribePipe adds a subscriber to a subscription of a named pipe.
+func (m *Manager) SubscribePipe(name, subscription string, buffer int) <-chan message.Transaction {
+	return m.PipeSubs.Subscribe(name, subscription, buffer)
+}
+
+// UnsubscribePipe removes a subscriber from a subscription of a named pipe.
+func (m *Manager) UnsubscribePipe(name, subscription string) {
+	m.PipeSubs.Unsubscribe(name, subscription)
+}
+
+// GetPipeSubscriptions returns the subscriptions of a named pipe.
+func (m *Manager) GetPipeSubscriptions(name string) ([]bundle.PipeSubscription, <-chan struct{}) {
+	return m.PipeSubs.Get(name)
+}
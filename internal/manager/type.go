@@ -92,6 +92,7 @@ type Type struct {
 
 	pipes    map[string]<-chan message.Transaction
 	pipeLock *sync.RWMutex
+	pipeSubs *bundle.PipeSubscriptions
 }
 
 // OptFunc is an opt setting for a manager type.
@@ -203,12 +204,14 @@ func New(conf ResourceConfig, opts ...OptFunc) (*Type, error) {
 		fs: ifs.OS(),
 
 		pipes:    map[string]<-chan message.Transaction{},
+		pipeSubs: bundle.NewPipeSubscriptions(),
 		pipeLock: &sync.RWMutex{},
 	}
 
 	for _, opt := range opts {
 		opt(t)
 	}
+	t.bloblEnv = withResourceFunctions(t.bloblEnv, t)
 
 	seen := map[string]struct{}{}
 
@@ -414,6 +417,25 @@ func (t *Type) UnsetPipe(name string, tran <-chan message.Transaction) {
 	t.pipeLock.Unlock()
 }
 
+// SubscribePipe adds a subscriber to a subscription of a named pipe, and
+// returns a transaction chan that receives the transactions of the
+// subscription. Each subscription of a pipe receives every transaction sent to
+// it, and subscribers sharing a subscription receive a portion of them.
+func (t *Type) SubscribePipe(name, subscription string, buffer int) <-chan message.Transaction {
+	return t.pipeSubs.Subscribe(name, subscription, buffer)
+}
+
+// UnsubscribePipe removes a subscriber from a subscription of a named pipe.
+func (t *Type) UnsubscribePipe(name, subscription string) {
+	t.pipeSubs.Unsubscribe(name, subscription)
+}
+
+// GetPipeSubscriptions returns the subscriptions of a named pipe, along with a
+// chan that is closed the next time they change.
+func (t *Type) GetPipeSubscriptions(name string) ([]bundle.PipeSubscription, <-chan struct{}) {
+	return t.pipeSubs.Get(name)
+}
+
 //------------------------------------------------------------------------------
 
 // WithMetricsMapping returns a manager with the stored metrics exporter wrapped
@@ -610,6 +632,53 @@ func (t *Type) StoreInput(ctx context.Context, name string, conf input.Config) e
 	return initErr
 }
 
+// HotSwapInput attempts to store a new input resource. Unlike StoreInput, if
+// an existing resource has the same name then it is hot swapped, where the new
+// input is initialized and begins serving consumers of the resource before the
+// existing one is closed. The existing input is given until the context is
+// cancelled to close, allowing transactions already in flight to be
+// acknowledged, and if the new input fails to initialize then the existing one
+// remains in place.
+//
+// Since both inputs exist at the same time this is not suitable for inputs that
+// bind to an address that the existing input also binds to.
+func (t *Type) HotSwapInput(ctx context.Context, name string, conf input.Config) error {
+	var initErr error
+	var prevClosed <-chan struct{}
+	if err := t.inputs.Access(name, true, func(i **InputWrapper, set func(**InputWrapper)) {
+		if conf.Label != "" && conf.Label != name {
+			initErr = fmt.Errorf("label '%v' must be empty or match the resource name '%v'", conf.Label, name)
+			return
+		}
+
+		var newInput input.Streamed
+		if newInput, initErr = t.intoPath("input_resources").NewInput(conf); initErr != nil {
+			return
+		}
+
+		if i != nil {
+			prevClosed = (*i).HotSwapInput(newInput)
+		} else {
+			ni := WrapInput(newInput)
+			set(&ni)
+		}
+	}); err != nil {
+		return err
+	}
+	if initErr != nil || prevClosed == nil {
+		return initErr
+	}
+
+	// The resource is released whilst waiting for the previous input to close
+	// so that it remains accessible.
+	select {
+	case <-prevClosed:
+	case <-ctx.Done():
+		return fmt.Errorf("previous input of resource '%v' did not close in time and will continue to close in the background: %w", name, ctx.Err())
+	}
+	return nil
+}
+
 // RemoveInput attempts to close and remove an existing input resource.
 func (t *Type) RemoveInput(ctx context.Context, name string) error {
 	var closeErr error
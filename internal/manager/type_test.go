@@ -2,12 +2,17 @@ package manager_test
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/redpanda-data/benthos/v4/internal/bloblang"
 	"github.com/redpanda-data/benthos/v4/internal/bundle"
 	"github.com/redpanda-data/benthos/v4/internal/component"
 	"github.com/redpanda-data/benthos/v4/internal/component/cache"
@@ -18,8 +23,10 @@ import (
 	"github.com/redpanda-data/benthos/v4/internal/component/testutil"
 	"github.com/redpanda-data/benthos/v4/internal/docs"
 	"github.com/redpanda-data/benthos/v4/internal/manager"
+	"github.com/redpanda-data/benthos/v4/internal/manager/mock"
 	"github.com/redpanda-data/benthos/v4/internal/message"
 
+	_ "github.com/redpanda-data/benthos/v4/internal/impl/io"
 	_ "github.com/redpanda-data/benthos/v4/public/components/pure"
 )
 
@@ -89,6 +96,220 @@ func TestManagerCache(t *testing.T) {
 	require.False(t, mgr.ProbeCache("baz"))
 }
 
+func TestManagerBloblangCacheGet(t *testing.T) {
+	conf := manager.NewResourceConfig()
+
+	fooCache := cache.NewConfig()
+	fooCache.Label = "foo"
+	conf.ResourceCaches = append(conf.ResourceCaches, fooCache)
+
+	mgr, err := manager.New(conf)
+	require.NoError(t, err)
+
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	require.NoError(t, mgr.AccessCache(tCtx, "foo", func(c cache.V1) {
+		require.NoError(t, c.Set(tCtx, "a", []byte(`{"name":"first"}`), nil))
+	}))
+
+	tests := []struct {
+		mapping string
+		output  string
+		err     string
+	}{
+		{
+			mapping: `root = cache_get("foo", this.key).parse_json()`,
+			output:  `{"name":"first"}`,
+		},
+		{
+			mapping: `root = cache_get("foo", "b", {"name":"default"})`,
+			output:  `{"name":"default"}`,
+		},
+		{
+			mapping: `root = cache_get("foo", "b")`,
+			err:     "failed assignment (line 1): failed to get key b from cache foo: key does not exist",
+		},
+		{
+			mapping: `root = cache_get("bar", "a", "default")`,
+			err:     "failed assignment (line 1): unable to locate resource: bar",
+		},
+	}
+
+	for _, test := range tests {
+		exec, err := mgr.BloblEnvironment().NewMapping(test.mapping)
+		require.NoError(t, err, test.mapping)
+
+		res, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{"key":"a"}`)}))
+		if test.err != "" {
+			require.EqualError(t, err, test.err, test.mapping)
+			continue
+		}
+		require.NoError(t, err, test.mapping)
+		assert.Equal(t, test.output, string(res.AsBytes()), test.mapping)
+	}
+
+	_, err = bloblang.GlobalEnvironment().NewMapping(`root = cache_get("foo", "a")`)
+	require.EqualError(t, err, "cache resources are not available to this mapping")
+}
+
+type blockingCache struct {
+	mock.Cache
+}
+
+func (b *blockingCache) Get(ctx context.Context, key string) ([]byte, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestManagerBloblangCacheGetTimeout(t *testing.T) {
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.CacheAdd(func(c cache.Config, mgr bundle.NewManagement) (cache.V1, error) {
+		return &blockingCache{}, nil
+	}, docs.ComponentSpec{
+		Name: "blocking",
+	}))
+
+	conf := manager.NewResourceConfig()
+	fooCache := cache.NewConfig()
+	fooCache.Label = "foo"
+	fooCache.Type = "blocking"
+	conf.ResourceCaches = append(conf.ResourceCaches, fooCache)
+
+	mgr, err := manager.New(conf, manager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	exec, err := mgr.BloblEnvironment().NewMapping(`root = cache_get(resource: "foo", key: "a", timeout: "10ms")`)
+	require.NoError(t, err)
+
+	_, err = exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{}`)}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "context deadline exceeded")
+
+	_, err = mgr.BloblEnvironment().NewMapping(`root = cache_get(resource: "foo", key: "a", timeout: "nope")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse timeout")
+}
+
+func TestManagerInputHotSwap(t *testing.T) {
+	mgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	inConf, err := testutil.InputFromYAML(`
+generate:
+  interval: 1ms
+  mapping: 'root = "first"'
+`)
+	require.NoError(t, err)
+	require.NoError(t, mgr.HotSwapInput(tCtx, "foo", inConf))
+
+	readNext := func() string {
+		t.Helper()
+		var str string
+		require.NoError(t, mgr.AccessInput(tCtx, "foo", func(i input.Streamed) {
+			select {
+			case tran := <-i.TransactionChan():
+				str = string(tran.Payload.Get(0).AsBytes())
+				require.NoError(t, tran.Ack(tCtx, nil))
+			case <-tCtx.Done():
+				t.Fatal(tCtx.Err())
+			}
+		}))
+		return str
+	}
+	assert.Equal(t, "first", readNext())
+
+	// A config that fails to initialize leaves the existing input in place.
+	badConf, err := testutil.InputFromYAML(`
+generate:
+  interval: nope
+  mapping: 'root = "bad"'
+`)
+	require.NoError(t, err)
+	require.Error(t, mgr.HotSwapInput(tCtx, "foo", badConf))
+	assert.Equal(t, "first", readNext())
+
+	newConf, err := testutil.InputFromYAML(`
+generate:
+  interval: 1ms
+  mapping: 'root = "second"'
+`)
+	require.NoError(t, err)
+
+	swapErr := make(chan error, 1)
+	go func() {
+		swapErr <- mgr.HotSwapInput(tCtx, "foo", newConf)
+	}()
+
+	for readNext() != "second" {
+	}
+	require.NoError(t, <-swapErr)
+}
+
+func TestManagerInputReplacePortBinding(t *testing.T) {
+	mgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	inConf, err := testutil.InputFromYAML(fmt.Sprintf(`
+http_server:
+  address: %v
+  path: /post
+`, addr))
+	require.NoError(t, err)
+
+	postAndRead := func(payload string) {
+		t.Helper()
+
+		postErr := make(chan error, 1)
+		go func() {
+			for {
+				res, err := http.Post("http://"+addr+"/post", "text/plain", strings.NewReader(payload))
+				if err == nil {
+					_ = res.Body.Close()
+					postErr <- nil
+					return
+				}
+				select {
+				case <-time.After(time.Millisecond * 50):
+				case <-tCtx.Done():
+					postErr <- err
+					return
+				}
+			}
+		}()
+
+		require.NoError(t, mgr.AccessInput(tCtx, "foo", func(i input.Streamed) {
+			select {
+			case tran := <-i.TransactionChan():
+				assert.Equal(t, payload, string(tran.Payload.Get(0).AsBytes()))
+				require.NoError(t, tran.Ack(tCtx, nil))
+			case <-tCtx.Done():
+				t.Fatal(tCtx.Err())
+			}
+		}))
+		require.NoError(t, <-postErr)
+	}
+
+	require.NoError(t, mgr.StoreInput(tCtx, "foo", inConf))
+	postAndRead("first")
+
+	// The previous input must release the address before the new input binds
+	// to it.
+	require.NoError(t, mgr.StoreInput(tCtx, "foo", inConf))
+	postAndRead("second")
+}
+
 func TestManagerResourceCRUD(t *testing.T) {
 	conf := manager.NewResourceConfig()
 
@@ -0,0 +1,214 @@
+package mmdb
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+const (
+	typeExtended  = 0
+	typePointer   = 1
+	typeString    = 2
+	typeDouble    = 3
+	typeBytes     = 4
+	typeUint16    = 5
+	typeUint32    = 6
+	typeMap       = 7
+	typeInt32     = 8
+	typeUint64    = 9
+	typeUint128   = 10
+	typeArray     = 11
+	typeContainer = 12
+	typeEndMarker = 13
+	typeBool      = 14
+	typeFloat     = 15
+)
+
+// Structures are limited in depth in order to protect against corrupted
+// databases that contain cycles.
+const maxDecodeDepth = 512
+
+var errUnexpectedEnd = errors.New("invalid database: unexpected end of data section")
+
+type decoder struct {
+	buf   []byte
+	depth int
+}
+
+func (d *decoder) bytesAt(offset, size uint64) ([]byte, error) {
+	if offset+size > uint64(len(d.buf)) || offset+size < offset {
+		return nil, errUnexpectedEnd
+	}
+	return d.buf[offset : offset+size], nil
+}
+
+func bigEndian(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// uintValue returns unsigned integers as signed integers where they fit, which
+// is the more common numerical type within mappings.
+func uintValue(u uint64) any {
+	if u <= math.MaxInt64 {
+		return int64(u)
+	}
+	return u
+}
+
+// decodeControl reads the type and size of the field at an offset, returning
+// the offset of the field payload.
+func (d *decoder) decodeControl(offset uint64) (typeNum int, size, next uint64, err error) {
+	ctrl, err := d.bytesAt(offset, 1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	offset++
+
+	typeNum = int(ctrl[0] >> 5)
+	if typeNum == typePointer {
+		return typeNum, uint64(ctrl[0]), offset, nil
+	}
+	if typeNum == typeExtended {
+		ext, err := d.bytesAt(offset, 1)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		typeNum = 7 + int(ext[0])
+		offset++
+	}
+
+	size = uint64(ctrl[0] & 0x1f)
+	if size >= 29 {
+		extraBytes := size - 28
+		extra, err := d.bytesAt(offset, extraBytes)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		offset += extraBytes
+		switch extraBytes {
+		case 1:
+			size = 29 + bigEndian(extra)
+		case 2:
+			size = 285 + bigEndian(extra)
+		default:
+			size = 65821 + bigEndian(extra)
+		}
+	}
+	return typeNum, size, offset, nil
+}
+
+// decode reads the value at an offset, returning the value and the offset of
+// the field that follows it.
+func (d *decoder) decode(offset uint64) (any, uint64, error) {
+	if d.depth++; d.depth > maxDecodeDepth {
+		return nil, 0, errors.New("invalid database: maximum data structure depth exceeded")
+	}
+	defer func() { d.depth-- }()
+
+	typeNum, size, offset, err := d.decodeControl(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch typeNum {
+	case typePointer:
+		ctrl := byte(size)
+		pointerSize := uint64((ctrl>>3)&0x3) + 1
+		b, err := d.bytesAt(offset, pointerSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		var target uint64
+		switch pointerSize {
+		case 1:
+			target = uint64(ctrl&0x7)<<8 | bigEndian(b)
+		case 2:
+			target = (uint64(ctrl&0x7)<<16 | bigEndian(b)) + 2048
+		case 3:
+			target = (uint64(ctrl&0x7)<<24 | bigEndian(b)) + 526336
+		default:
+			target = bigEndian(b)
+		}
+		v, _, err := d.decode(target)
+		return v, offset + pointerSize, err
+	case typeMap:
+		m := make(map[string]any, size)
+		for i := uint64(0); i < size; i++ {
+			var k, v any
+			if k, offset, err = d.decode(offset); err != nil {
+				return nil, 0, err
+			}
+			kStr, ok := k.(string)
+			if !ok {
+				return nil, 0, fmt.Errorf("invalid database: map key of type %T", k)
+			}
+			if v, offset, err = d.decode(offset); err != nil {
+				return nil, 0, err
+			}
+			m[kStr] = v
+		}
+		return m, offset, nil
+	case typeArray:
+		arr := make([]any, 0, min(size, 1024))
+		for i := uint64(0); i < size; i++ {
+			var v any
+			if v, offset, err = d.decode(offset); err != nil {
+				return nil, 0, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	}
+
+	b, err := d.bytesAt(offset, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	offset += size
+
+	switch typeNum {
+	case typeString:
+		return string(b), offset, nil
+	case typeBytes:
+		return append([]byte(nil), b...), offset, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("invalid database: double of size %v", size)
+		}
+		return math.Float64frombits(bigEndian(b)), offset, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("invalid database: float of size %v", size)
+		}
+		return float64(math.Float32frombits(uint32(bigEndian(b)))), offset, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("invalid database: unsigned integer of size %v", size)
+		}
+		return uintValue(bigEndian(b)), offset, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, fmt.Errorf("invalid database: signed integer of size %v", size)
+		}
+		return int64(int32(uint32(bigEndian(b)))), offset, nil
+	case typeUint128:
+		if size > 16 {
+			return nil, 0, fmt.Errorf("invalid database: unsigned integer of size %v", size)
+		}
+		if size <= 8 {
+			return uintValue(bigEndian(b)), offset, nil
+		}
+		return new(big.Int).SetBytes(b).String(), offset, nil
+	case typeContainer, typeEndMarker:
+		return nil, offset, nil
+	}
+	return nil, 0, fmt.Errorf("invalid database: unknown data type %v", typeNum)
+}
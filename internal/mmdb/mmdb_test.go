@@ -0,0 +1,140 @@
+package mmdb
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/mmdb/mmdbtest"
+)
+
+func TestReaderRoundTrip(t *testing.T) {
+	for _, recordSize := range []int{24, 28, 32} {
+		for _, ipVersion := range []int{4, 6} {
+			w, err := mmdbtest.NewWriter(ipVersion, recordSize, "Test-DB")
+			require.NoError(t, err)
+
+			require.NoError(t, w.Insert(netip.MustParsePrefix("10.0.0.0/8"), map[string]any{
+				"name": "ten",
+				"nested": map[string]any{
+					"values": []any{int64(1), int64(-5), 1.5, true, false, []byte("raw")},
+				},
+			}))
+			require.NoError(t, w.Insert(netip.MustParsePrefix("10.1.0.0/16"), "ten one"))
+			require.NoError(t, w.Insert(netip.MustParsePrefix("192.168.1.0/24"), uint64(1<<40)))
+			if ipVersion == 6 {
+				require.NoError(t, w.Insert(netip.MustParsePrefix("2001:db8::/32"), "documentation"))
+			} else {
+				require.Error(t, w.Insert(netip.MustParsePrefix("2001:db8::/32"), "documentation"))
+			}
+
+			b, err := w.Bytes()
+			require.NoError(t, err)
+
+			r, err := FromBytes(b)
+			require.NoError(t, err)
+
+			assert.Equal(t, uint64(recordSize), r.Metadata.RecordSize)
+			assert.Equal(t, uint64(ipVersion), r.Metadata.IPVersion)
+			assert.Equal(t, "Test-DB", r.Metadata.DatabaseType)
+
+			lookup := func(ip string) (any, bool) {
+				t.Helper()
+				v, found, err := r.Lookup(netip.MustParseAddr(ip))
+				require.NoError(t, err)
+				return v, found
+			}
+
+			v, found := lookup("10.200.0.1")
+			require.True(t, found)
+			assert.Equal(t, map[string]any{
+				"name": "ten",
+				"nested": map[string]any{
+					"values": []any{int64(1), int64(-5), 1.5, true, false, []byte("raw")},
+				},
+			}, v)
+
+			v, found = lookup("10.1.2.3")
+			require.True(t, found)
+			assert.Equal(t, "ten one", v)
+
+			v, found = lookup("::ffff:192.168.1.7")
+			require.True(t, found)
+			assert.Equal(t, int64(1<<40), v)
+
+			_, found = lookup("192.168.2.1")
+			assert.False(t, found)
+
+			if ipVersion == 6 {
+				v, found = lookup("2001:db8::1")
+				require.True(t, found)
+				assert.Equal(t, "documentation", v)
+
+				_, found = lookup("2001:db9::1")
+				assert.False(t, found)
+			} else {
+				_, _, err := r.Lookup(netip.MustParseAddr("2001:db8::1"))
+				require.Error(t, err)
+			}
+		}
+	}
+}
+
+func TestReaderLargeValues(t *testing.T) {
+	w, err := mmdbtest.NewWriter(6, 24, "Test-DB")
+	require.NoError(t, err)
+
+	long := make([]byte, 70000)
+	for i := range long {
+		long[i] = 'a'
+	}
+	arr := make([]any, 300)
+	for i := range arr {
+		arr[i] = int64(i)
+	}
+	require.NoError(t, w.Insert(netip.MustParsePrefix("1.0.0.0/8"), map[string]any{
+		"long":  string(long),
+		"array": arr,
+	}))
+
+	b, err := w.Bytes()
+	require.NoError(t, err)
+
+	r, err := FromBytes(b)
+	require.NoError(t, err)
+
+	v, found, err := r.Lookup(netip.MustParseAddr("1.2.3.4"))
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, map[string]any{"long": string(long), "array": arr}, v)
+}
+
+func TestDecoderPointers(t *testing.T) {
+	// A map of {"a":"foo","b":"foo"} where the second value is a pointer to
+	// the first.
+	buf := []byte{
+		0xe2,      // map of size 2
+		0x41, 'a', // key "a"
+		0x43, 'f', 'o', 'o', // value "foo" at offset 3
+		0x41, 'b', // key "b"
+		0x20, 0x03, // pointer to offset 3
+	}
+	v, next, err := (&decoder{buf: buf}).decode(0)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": "foo", "b": "foo"}, v)
+	assert.Equal(t, uint64(len(buf)), next)
+}
+
+func TestDecoderErrors(t *testing.T) {
+	_, _, err := (&decoder{buf: []byte{0x45, 'a'}}).decode(0)
+	require.EqualError(t, err, "invalid database: unexpected end of data section")
+
+	// A pointer to itself.
+	_, _, err = (&decoder{buf: []byte{0x20, 0x00}}).decode(0)
+	require.EqualError(t, err, "invalid database: maximum data structure depth exceeded")
+
+	_, err = FromBytes([]byte("not a database"))
+	require.EqualError(t, err, "invalid database: metadata section not found")
+}
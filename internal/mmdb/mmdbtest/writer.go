@@ -0,0 +1,301 @@
+// Package mmdbtest provides a writer of databases in the MaxMind DB format for
+// testing the mmdb package and its dependents.
+package mmdbtest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"sort"
+)
+
+// These mirror the data section types and metadata marker of the mmdb package.
+const (
+	typeString = 2
+	typeDouble = 3
+	typeBytes  = 4
+	typeUint32 = 6
+	typeMap    = 7
+	typeInt32  = 8
+	typeUint64 = 9
+	typeArray  = 11
+	typeBool   = 14
+	typeFloat  = 15
+)
+
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+type recordKind int
+
+const (
+	recordEmpty recordKind = iota
+	recordNode
+	recordData
+)
+
+type record struct {
+	kind  recordKind
+	value uint64
+}
+
+// Writer builds a database in memory. It supports a subset of the format that
+// is sufficient for generating small databases, where values are not
+// deduplicated.
+type Writer struct {
+	ipVersion    int
+	recordSize   int
+	databaseType string
+
+	nodes [][2]record
+	data  bytes.Buffer
+}
+
+// NewWriter creates a writer for a database of a given IP version (4 or 6),
+// record size (24, 28 or 32) and type.
+func NewWriter(ipVersion, recordSize int, databaseType string) (*Writer, error) {
+	if ipVersion != 4 && ipVersion != 6 {
+		return nil, fmt.Errorf("unsupported ip version %v", ipVersion)
+	}
+	if recordSize != 24 && recordSize != 28 && recordSize != 32 {
+		return nil, fmt.Errorf("unsupported record size %v", recordSize)
+	}
+	return &Writer{
+		ipVersion:    ipVersion,
+		recordSize:   recordSize,
+		databaseType: databaseType,
+		nodes:        [][2]record{{}},
+	}, nil
+}
+
+// Insert adds a network to the database with a record value, which can be
+// composed of maps, arrays, strings, byte slices, booleans and numbers. IPv4
+// networks within an IPv6 database are inserted within ::/96.
+func (w *Writer) Insert(prefix netip.Prefix, v any) error {
+	addr, bits := prefix.Addr(), prefix.Bits()
+	var ipBytes []byte
+	if addr.Is4() && w.ipVersion == 6 {
+		b := netip.AddrFrom16([16]byte{}).As16()
+		v4 := addr.As4()
+		copy(b[12:], v4[:])
+		ipBytes, bits = b[:], bits+96
+	} else if addr.Is4() {
+		b := addr.As4()
+		ipBytes = b[:]
+	} else if w.ipVersion == 6 {
+		b := addr.As16()
+		ipBytes = b[:]
+	} else {
+		return errors.New("cannot insert an IPv6 network into an IPv4 database")
+	}
+
+	offset := uint64(w.data.Len())
+	if err := encodeValue(&w.data, v); err != nil {
+		return err
+	}
+
+	node := 0
+	for i := 0; i < bits; i++ {
+		bit := (ipBytes[i/8] >> (7 - uint(i%8))) & 1
+		if i == bits-1 {
+			w.nodes[node][bit] = record{kind: recordData, value: offset}
+			break
+		}
+		rec := w.nodes[node][bit]
+		switch rec.kind {
+		case recordNode:
+			node = int(rec.value)
+			continue
+		case recordEmpty, recordData:
+			// Existing data records are pushed down into both children of the
+			// new node so that the wider network remains intact.
+			w.nodes = append(w.nodes, [2]record{rec, rec})
+			w.nodes[node][bit] = record{kind: recordNode, value: uint64(len(w.nodes) - 1)}
+			node = len(w.nodes) - 1
+		}
+	}
+	return nil
+}
+
+// Bytes serialises the database.
+func (w *Writer) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	nodeCount := uint64(len(w.nodes))
+	maxRecord := uint64(1)<<w.recordSize - 1
+
+	recordValue := func(r record) (uint64, error) {
+		var v uint64
+		switch r.kind {
+		case recordEmpty:
+			v = nodeCount
+		case recordNode:
+			v = r.value
+		default:
+			v = nodeCount + 16 + r.value
+		}
+		if v > maxRecord {
+			return 0, errors.New("database exceeds the capacity of the record size")
+		}
+		return v, nil
+	}
+
+	for _, n := range w.nodes {
+		left, err := recordValue(n[0])
+		if err != nil {
+			return nil, err
+		}
+		right, err := recordValue(n[1])
+		if err != nil {
+			return nil, err
+		}
+		switch w.recordSize {
+		case 24:
+			buf.Write([]byte{byte(left >> 16), byte(left >> 8), byte(left), byte(right >> 16), byte(right >> 8), byte(right)})
+		case 28:
+			buf.Write([]byte{
+				byte(left >> 16), byte(left >> 8), byte(left),
+				byte((left>>20)&0xf0) | byte((right>>24)&0x0f),
+				byte(right >> 16), byte(right >> 8), byte(right),
+			})
+		default:
+			var b [8]byte
+			binary.BigEndian.PutUint32(b[:4], uint32(left))
+			binary.BigEndian.PutUint32(b[4:], uint32(right))
+			buf.Write(b[:])
+		}
+	}
+
+	buf.Write(make([]byte, 16))
+	buf.Write(w.data.Bytes())
+	buf.Write(metadataMarker)
+	if err := encodeValue(&buf, map[string]any{
+		"node_count":                  nodeCount,
+		"record_size":                 uint64(w.recordSize),
+		"ip_version":                  uint64(w.ipVersion),
+		"database_type":               w.databaseType,
+		"binary_format_major_version": uint64(2),
+		"binary_format_minor_version": uint64(0),
+		"build_epoch":                 uint64(0),
+		"languages":                   []any{},
+		"description":                 map[string]any{},
+	}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeControl(buf *bytes.Buffer, typeNum int, size uint64) {
+	var ctrl byte
+	var ext []byte
+	if typeNum > 7 {
+		ext = append(ext, byte(typeNum-7))
+	} else {
+		ctrl = byte(typeNum) << 5
+	}
+
+	var sizeBytes []byte
+	switch {
+	case size < 29:
+		ctrl |= byte(size)
+	case size < 285:
+		ctrl |= 29
+		sizeBytes = []byte{byte(size - 29)}
+	case size < 65821:
+		ctrl |= 30
+		s := size - 285
+		sizeBytes = []byte{byte(s >> 8), byte(s)}
+	default:
+		ctrl |= 31
+		s := size - 65821
+		sizeBytes = []byte{byte(s >> 16), byte(s >> 8), byte(s)}
+	}
+	buf.WriteByte(ctrl)
+	buf.Write(ext)
+	buf.Write(sizeBytes)
+}
+
+func minimalBytes(u uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], u)
+	i := 0
+	for i < 8 && b[i] == 0 {
+		i++
+	}
+	return b[i:]
+}
+
+func encodeValue(buf *bytes.Buffer, v any) error {
+	switch t := v.(type) {
+	case string:
+		writeControl(buf, typeString, uint64(len(t)))
+		buf.WriteString(t)
+	case []byte:
+		writeControl(buf, typeBytes, uint64(len(t)))
+		buf.Write(t)
+	case bool:
+		size := uint64(0)
+		if t {
+			size = 1
+		}
+		writeControl(buf, typeBool, size)
+	case float64:
+		writeControl(buf, typeDouble, 8)
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], math.Float64bits(t))
+		buf.Write(b[:])
+	case float32:
+		writeControl(buf, typeFloat, 4)
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], math.Float32bits(t))
+		buf.Write(b[:])
+	case int:
+		return encodeValue(buf, int64(t))
+	case int64:
+		if t < 0 {
+			if t < math.MinInt32 {
+				return fmt.Errorf("value %v exceeds the range of a signed 32-bit integer", t)
+			}
+			writeControl(buf, typeInt32, 4)
+			var b [4]byte
+			binary.BigEndian.PutUint32(b[:], uint32(int32(t)))
+			buf.Write(b[:])
+			return nil
+		}
+		return encodeValue(buf, uint64(t))
+	case uint64:
+		b := minimalBytes(t)
+		if t <= math.MaxUint32 {
+			writeControl(buf, typeUint32, uint64(len(b)))
+		} else {
+			writeControl(buf, typeUint64, uint64(len(b)))
+		}
+		buf.Write(b)
+	case []any:
+		writeControl(buf, typeArray, uint64(len(t)))
+		for _, e := range t {
+			if err := encodeValue(buf, e); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		writeControl(buf, typeMap, uint64(len(t)))
+		for _, k := range keys {
+			if err := encodeValue(buf, k); err != nil {
+				return err
+			}
+			if err := encodeValue(buf, t[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported value type %T", v)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+// Package mmdb implements a reader for databases in the MaxMind DB format,
+// which is used by GeoIP databases, as described at
+// https://maxmind.github.io/MaxMind-DB/.
+package mmdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+)
+
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// The metadata section is guaranteed to be within this many bytes from the
+// end of the database.
+const metadataMaxSize = 128 * 1024
+
+// Metadata describes a database.
+type Metadata struct {
+	NodeCount    uint64
+	RecordSize   uint64
+	IPVersion    uint64
+	DatabaseType string
+	BuildEpoch   uint64
+}
+
+// Reader performs lookups against a database held in memory.
+type Reader struct {
+	Metadata Metadata
+
+	tree      []byte
+	data      []byte
+	ipv4Start uint64
+}
+
+// Open reads a database from a file.
+func Open(path string) (*Reader, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(b)
+}
+
+// FromBytes parses a database from its raw bytes.
+func FromBytes(b []byte) (*Reader, error) {
+	searchFrom := max(0, len(b)-metadataMaxSize)
+	markerIdx := bytes.LastIndex(b[searchFrom:], metadataMarker)
+	if markerIdx < 0 {
+		return nil, errors.New("invalid database: metadata section not found")
+	}
+	metaStart := searchFrom + markerIdx + len(metadataMarker)
+
+	rawMeta, _, err := (&decoder{buf: b[metaStart:]}).decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database metadata: %w", err)
+	}
+	metaMap, ok := rawMeta.(map[string]any)
+	if !ok {
+		return nil, errors.New("invalid database metadata: expected a map")
+	}
+
+	var r Reader
+	for k, dst := range map[string]*uint64{
+		"node_count":  &r.Metadata.NodeCount,
+		"record_size": &r.Metadata.RecordSize,
+		"ip_version":  &r.Metadata.IPVersion,
+		"build_epoch": &r.Metadata.BuildEpoch,
+	} {
+		if *dst, ok = asUint(metaMap[k]); !ok && k != "build_epoch" {
+			return nil, fmt.Errorf("invalid database metadata: missing field %v", k)
+		}
+	}
+	r.Metadata.DatabaseType, _ = metaMap["database_type"].(string)
+
+	switch r.Metadata.RecordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("invalid database metadata: unsupported record size %v", r.Metadata.RecordSize)
+	}
+	if r.Metadata.IPVersion != 4 && r.Metadata.IPVersion != 6 {
+		return nil, fmt.Errorf("invalid database metadata: unsupported ip version %v", r.Metadata.IPVersion)
+	}
+
+	treeSize := r.Metadata.NodeCount * r.Metadata.RecordSize / 4
+	dataStart := treeSize + 16
+	if dataStart > uint64(searchFrom+markerIdx) {
+		return nil, errors.New("invalid database: search tree exceeds the size of the database")
+	}
+	r.tree = b[:treeSize]
+	r.data = b[dataStart : searchFrom+markerIdx]
+
+	if r.Metadata.IPVersion == 6 {
+		// IPv4 addresses are stored within the subtree of ::/96.
+		node := uint64(0)
+		for i := 0; i < 96 && node < r.Metadata.NodeCount; i++ {
+			node = r.readNode(node, 0)
+		}
+		r.ipv4Start = node
+	}
+	return &r, nil
+}
+
+func asUint(v any) (uint64, bool) {
+	switch t := v.(type) {
+	case uint64:
+		return t, true
+	case int64:
+		return uint64(t), t >= 0
+	}
+	return 0, false
+}
+
+// readNode returns the value of the left (bit 0) or right (bit 1) record of a
+// node.
+func (r *Reader) readNode(node uint64, bit uint) uint64 {
+	switch r.Metadata.RecordSize {
+	case 24:
+		b := r.tree[node*6:]
+		if bit == 1 {
+			b = b[3:]
+		}
+		return uint64(b[0])<<16 | uint64(b[1])<<8 | uint64(b[2])
+	case 28:
+		b := r.tree[node*7:]
+		if bit == 0 {
+			return uint64(b[3]&0xf0)<<20 | uint64(b[0])<<16 | uint64(b[1])<<8 | uint64(b[2])
+		}
+		return uint64(b[3]&0x0f)<<24 | uint64(b[4])<<16 | uint64(b[5])<<8 | uint64(b[6])
+	default:
+		b := r.tree[node*8:]
+		if bit == 1 {
+			b = b[4:]
+		}
+		return uint64(b[0])<<24 | uint64(b[1])<<16 | uint64(b[2])<<8 | uint64(b[3])
+	}
+}
+
+// Lookup finds the record of the network containing an IP address, returning
+// false when the address is not within any network of the database.
+func (r *Reader) Lookup(addr netip.Addr) (any, bool, error) {
+	addr = addr.Unmap()
+
+	var ipBytes []byte
+	node := uint64(0)
+	if addr.Is4() {
+		b := addr.As4()
+		ipBytes = b[:]
+		node = r.ipv4Start
+	} else {
+		if r.Metadata.IPVersion == 4 {
+			return nil, false, errors.New("cannot look up an IPv6 address in an IPv4 database")
+		}
+		b := addr.As16()
+		ipBytes = b[:]
+	}
+
+	nodeCount := r.Metadata.NodeCount
+	for i := 0; i < len(ipBytes)*8 && node < nodeCount; i++ {
+		bit := uint(ipBytes[i/8]>>(7-uint(i%8))) & 1
+		node = r.readNode(node, bit)
+	}
+	if node == nodeCount {
+		return nil, false, nil
+	}
+	if node < nodeCount {
+		return nil, false, errors.New("invalid database: search tree is deeper than the address")
+	}
+
+	offset := node - nodeCount - 16
+	if offset >= uint64(len(r.data)) {
+		return nil, false, errors.New("invalid database: record points outside of the data section")
+	}
+	v, _, err := (&decoder{buf: r.data}).decode(offset)
+	if err != nil {
+		return nil, false, err
+	}
+	return v, true, nil
+}
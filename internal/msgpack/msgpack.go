@@ -0,0 +1,449 @@
+// Package msgpack implements encoding and decoding of MessagePack documents to
+// and from the generic structured values used throughout Benthos.
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	extTimestamp = -1
+
+	maxDepth = 1000
+)
+
+var errUnexpectedEnd = errors.New("unexpected end of data")
+
+// Unmarshal decodes a single MessagePack object into a structured value,
+// where maps are returned as map[string]any, arrays as []any, integers as
+// int64 (or uint64 when too large), floats as float64, strings as string and
+// binary data as []byte.
+//
+// Map keys that are not strings are converted into their string
+// representations. Timestamp extensions are decoded as time.Time values and
+// the data of all other extension types is returned as []byte.
+func Unmarshal(data []byte) (any, error) {
+	d := &decoder{data: data}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.off != len(d.data) {
+		return nil, fmt.Errorf("unexpected %v bytes of data after the end of the object", len(d.data)-d.off)
+	}
+	return v, nil
+}
+
+type decoder struct {
+	data []byte
+	off  int
+}
+
+func (d *decoder) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, errUnexpectedEnd
+	}
+	b := d.data[d.off : d.off+int(n)]
+	d.off += int(n)
+	return b, nil
+}
+
+// uint reads a big endian unsigned integer of size n bytes.
+func (d *decoder) uint(n int) (uint64, error) {
+	b, err := d.bytes(uint64(n))
+	if err != nil {
+		return 0, err
+	}
+	switch n {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+func (d *decoder) decode(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errors.New("maximum nesting depth exceeded")
+	}
+
+	b, err := d.bytes(1)
+	if err != nil {
+		return nil, err
+	}
+	format := b[0]
+
+	switch {
+	case format <= 0x7f:
+		return int64(format), nil
+	case format >= 0xe0:
+		return int64(int8(format)), nil
+	case format&0xf0 == 0x80:
+		return d.decodeMap(uint64(format&0x0f), depth)
+	case format&0xf0 == 0x90:
+		return d.decodeArray(uint64(format&0x0f), depth)
+	case format&0xe0 == 0xa0:
+		return d.decodeStr(uint64(format & 0x1f))
+	}
+
+	switch format {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (format - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.bytes(n)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, b...), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uint(1 << (format - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeExt(n)
+	case 0xca:
+		n, err := d.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(uint32(n))), nil
+	case 0xcb:
+		n, err := d.uint(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(n), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := d.uint(1 << (format - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+	case 0xd0:
+		n, err := d.uint(1)
+		return int64(int8(n)), err
+	case 0xd1:
+		n, err := d.uint(2)
+		return int64(int16(n)), err
+	case 0xd2:
+		n, err := d.uint(4)
+		return int64(int32(n)), err
+	case 0xd3:
+		n, err := d.uint(8)
+		return int64(n), err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.decodeExt(1 << (format - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (format - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeStr(n)
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (format - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(n, depth)
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (format - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(n, depth)
+	}
+	return nil, fmt.Errorf("unsupported format 0x%02x at offset %v", format, d.off-1)
+}
+
+func (d *decoder) decodeStr(n uint64) (any, error) {
+	b, err := d.bytes(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *decoder) decodeArray(n uint64, depth int) (any, error) {
+	// Each element takes at least one byte, which protects against huge
+	// allocations from malicious lengths.
+	if n > uint64(len(d.data)-d.off) {
+		return nil, errUnexpectedEnd
+	}
+	arr := make([]any, 0, int(n))
+	for i := uint64(0); i < n; i++ {
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+	}
+	return arr, nil
+}
+
+func (d *decoder) decodeMap(n uint64, depth int) (any, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, errUnexpectedEnd
+	}
+	obj := make(map[string]any, int(n))
+	for i := uint64(0); i < n; i++ {
+		k, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		switch t := k.(type) {
+		case string:
+			obj[t] = v
+		case []byte:
+			obj[string(t)] = v
+		default:
+			obj[fmt.Sprintf("%v", t)] = v
+		}
+	}
+	return obj, nil
+}
+
+func (d *decoder) decodeExt(n uint64) (any, error) {
+	typ, err := d.uint(1)
+	if err != nil {
+		return nil, err
+	}
+	b, err := d.bytes(n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(typ) != extTimestamp {
+		return append([]byte{}, b...), nil
+	}
+
+	switch len(b) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(b)), 0).UTC(), nil
+	case 8:
+		v := binary.BigEndian.Uint64(b)
+		return time.Unix(int64(v&0x3ffffffff), int64(v>>34)).UTC(), nil
+	case 12:
+		nsec := binary.BigEndian.Uint32(b[:4])
+		sec := int64(binary.BigEndian.Uint64(b[4:]))
+		return time.Unix(sec, int64(nsec)).UTC(), nil
+	}
+	return nil, fmt.Errorf("invalid timestamp extension length %v", len(b))
+}
+
+//------------------------------------------------------------------------------
+
+// Marshal encodes a structured value as a MessagePack object. Integers are
+// written with the smallest format able to represent them, map keys are
+// written in lexicographical order and timestamps are written with the
+// timestamp extension type.
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, v, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeUint(buf *bytes.Buffer, u uint64) {
+	switch {
+	case u <= 0x7f:
+		buf.WriteByte(byte(u))
+	case u <= math.MaxUint8:
+		buf.WriteByte(0xcc)
+		buf.WriteByte(byte(u))
+	case u <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(u)))
+	case u <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(u)))
+	default:
+		buf.WriteByte(0xcf)
+		buf.Write(binary.BigEndian.AppendUint64(nil, u))
+	}
+}
+
+func writeInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0:
+		writeUint(buf, uint64(i))
+	case i >= -32:
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(int16(i))))
+	case i >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(int32(i))))
+	default:
+		buf.WriteByte(0xd3)
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(i)))
+	}
+}
+
+func writeFloat(buf *bytes.Buffer, f float64) {
+	buf.WriteByte(0xcb)
+	buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+}
+
+// writeHead writes the format and length of a string, binary, array or map,
+// where formats contains the fixed format (or zero if not supported) followed
+// by the 8, 16 and 32 bit length formats (or zero if not supported).
+func writeHead(buf *bytes.Buffer, n int, fixMax int, formats [4]byte) error {
+	switch {
+	case formats[0] != 0 && n <= fixMax:
+		buf.WriteByte(formats[0] | byte(n))
+	case formats[1] != 0 && n <= math.MaxUint8:
+		buf.WriteByte(formats[1])
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(formats[2])
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case uint64(n) <= math.MaxUint32:
+		buf.WriteByte(formats[3])
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	default:
+		return fmt.Errorf("length %v exceeds the maximum supported by MessagePack", n)
+	}
+	return nil
+}
+
+var (
+	strFormats   = [4]byte{0xa0, 0xd9, 0xda, 0xdb}
+	binFormats   = [4]byte{0, 0xc4, 0xc5, 0xc6}
+	arrayFormats = [4]byte{0x90, 0, 0xdc, 0xdd}
+	mapFormats   = [4]byte{0x80, 0, 0xde, 0xdf}
+)
+
+func writeTimestamp(buf *bytes.Buffer, t time.Time) {
+	sec, nsec := t.Unix(), uint64(t.Nanosecond())
+	switch {
+	case sec >= 0 && sec <= math.MaxUint32 && nsec == 0:
+		buf.Write([]byte{0xd6, 0xff})
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(sec)))
+	case sec >= 0 && sec < 1<<34:
+		buf.Write([]byte{0xd7, 0xff})
+		buf.Write(binary.BigEndian.AppendUint64(nil, nsec<<34|uint64(sec)))
+	default:
+		buf.Write([]byte{0xc7, 12, 0xff})
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(nsec)))
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(sec)))
+	}
+}
+
+func encode(buf *bytes.Buffer, v any, depth int) error {
+	if depth > maxDepth {
+		return errors.New("maximum nesting depth exceeded")
+	}
+
+	switch t := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if t {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int:
+		writeInt(buf, int64(t))
+	case int8:
+		writeInt(buf, int64(t))
+	case int16:
+		writeInt(buf, int64(t))
+	case int32:
+		writeInt(buf, int64(t))
+	case int64:
+		writeInt(buf, t)
+	case uint:
+		writeUint(buf, uint64(t))
+	case uint8:
+		writeUint(buf, uint64(t))
+	case uint16:
+		writeUint(buf, uint64(t))
+	case uint32:
+		writeUint(buf, uint64(t))
+	case uint64:
+		writeUint(buf, t)
+	case float32:
+		writeFloat(buf, float64(t))
+	case float64:
+		writeFloat(buf, t)
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			writeInt(buf, i)
+		} else if f, err := t.Float64(); err == nil {
+			writeFloat(buf, f)
+		} else {
+			return fmt.Errorf("invalid number: %v", t)
+		}
+	case string:
+		if err := writeHead(buf, len(t), 31, strFormats); err != nil {
+			return err
+		}
+		buf.WriteString(t)
+	case []byte:
+		if err := writeHead(buf, len(t), 0, binFormats); err != nil {
+			return err
+		}
+		buf.Write(t)
+	case time.Time:
+		writeTimestamp(buf, t)
+	case []any:
+		if err := writeHead(buf, len(t), 15, arrayFormats); err != nil {
+			return err
+		}
+		for _, e := range t {
+			if err := encode(buf, e, depth+1); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		if err := writeHead(buf, len(t), 15, mapFormats); err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := encode(buf, k, depth+1); err != nil {
+				return err
+			}
+			if err := encode(buf, t[k], depth+1); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported type %T", v)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package msgpack
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshal(t *testing.T) {
+	tests := map[string]any{
+		"00":                             int64(0),
+		"7f":                             int64(127),
+		"ff":                             int64(-1),
+		"e0":                             int64(-32),
+		"cc80":                           int64(128),
+		"cd0100":                         int64(256),
+		"ce00010000":                     int64(65536),
+		"cfffffffffffffffff":             uint64(math.MaxUint64),
+		"d080":                           int64(-128),
+		"d1ff00":                         int64(-256),
+		"d2ffff0000":                     int64(-65536),
+		"d38000000000000000":             int64(math.MinInt64),
+		"ca3fc00000":                     1.5,
+		"cb3ff199999999999a":             1.1,
+		"c0":                             nil,
+		"c2":                             false,
+		"c3":                             true,
+		"a3666f6f":                       "foo",
+		"d903666f6f":                     "foo",
+		"da0003666f6f":                   "foo",
+		"c403010203":                     []byte{1, 2, 3},
+		"c50000":                         []byte{},
+		"93010203":                       []any{int64(1), int64(2), int64(3)},
+		"dc0002c0c3":                     []any{nil, true},
+		"82a16101a162c2":                 map[string]any{"a": int64(1), "b": false},
+		"de000101a3666f6f":               map[string]any{"1": "foo"},
+		"d4010a":                         []byte{0x0a},
+		"d6ff514b67b0":                   time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC),
+		"d7ff77359400514b67b0":           time.Date(2013, 3, 21, 20, 4, 0, 500000000, time.UTC),
+		"c70cff00000001ffffffffffffffff": time.Date(1969, 12, 31, 23, 59, 59, 1, time.UTC),
+	}
+
+	for input, exp := range tests {
+		input, exp := input, exp
+		t.Run(input, func(t *testing.T) {
+			b, err := hex.DecodeString(input)
+			require.NoError(t, err)
+
+			v, err := Unmarshal(b)
+			require.NoError(t, err)
+			if et, ok := exp.(time.Time); ok {
+				require.IsType(t, time.Time{}, v)
+				assert.True(t, et.Equal(v.(time.Time)), "%v != %v", et, v)
+			} else {
+				assert.Equal(t, exp, v)
+			}
+		})
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := map[string]string{
+		"":           "unexpected end of data",
+		"cd01":       "unexpected end of data",
+		"a3666f":     "unexpected end of data",
+		"0001":       "unexpected 1 bytes of data after the end of the object",
+		"c1":         "unsupported format 0xc1 at offset 0",
+		"92c0":       "unexpected end of data",
+		"ddffffffff": "unexpected end of data",
+		"d5ff0000":   "invalid timestamp extension length 2",
+	}
+
+	for input, exp := range tests {
+		b, err := hex.DecodeString(input)
+		require.NoError(t, err, input)
+
+		_, err = Unmarshal(b)
+		require.Error(t, err, input)
+		assert.EqualError(t, err, exp, input)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		input any
+		exp   string
+	}{
+		{input: nil, exp: "c0"},
+		{input: false, exp: "c2"},
+		{input: 5, exp: "05"},
+		{input: int64(-5), exp: "fb"},
+		{input: int64(200), exp: "ccc8"},
+		{input: int64(-200), exp: "d1ff38"},
+		{input: int64(70000), exp: "ce00011170"},
+		{input: int64(-70000), exp: "d2fffeee90"},
+		{input: int64(math.MinInt64), exp: "d38000000000000000"},
+		{input: uint64(math.MaxUint64), exp: "cfffffffffffffffff"},
+		{input: 1.1, exp: "cb3ff199999999999a"},
+		{input: json.Number("300"), exp: "cd012c"},
+		{input: "foo", exp: "a3666f6f"},
+		{input: strings.Repeat("a", 32), exp: "d920" + strings.Repeat("61", 32)},
+		{input: []byte{1, 2}, exp: "c4020102"},
+		{input: []any{1, "a"}, exp: "9201a161"},
+		{input: map[string]any{"b": 2, "a": 1}, exp: "82a16101a16202"},
+		{input: time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC), exp: "d6ff514b67b0"},
+		{input: time.Date(2013, 3, 21, 20, 4, 0, 500000000, time.UTC), exp: "d7ff77359400514b67b0"},
+		{input: time.Date(1969, 12, 31, 23, 59, 59, 1, time.UTC), exp: "c70cff00000001ffffffffffffffff"},
+	}
+
+	for _, test := range tests {
+		b, err := Marshal(test.input)
+		require.NoError(t, err, test.input)
+		assert.Equal(t, test.exp, hex.EncodeToString(b), test.input)
+	}
+
+	_, err := Marshal(struct{}{})
+	require.Error(t, err)
+}
+
+func TestRoundTrip(t *testing.T) {
+	input := map[string]any{
+		"id":      int64(-42),
+		"name":    "sensor",
+		"active":  true,
+		"reading": 21.5,
+		"raw":     []byte("hello"),
+		"tags":    []any{"a", "b", nil},
+		"nested":  map[string]any{"big": uint64(math.MaxUint64), "long": strings.Repeat("x", 70000)},
+	}
+
+	b, err := Marshal(input)
+	require.NoError(t, err)
+
+	v, err := Unmarshal(b)
+	require.NoError(t, err)
+	assert.Equal(t, input, v)
+}
@@ -0,0 +1,279 @@
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Constraint is a parsed version range that versions can be checked against.
+//
+// A constraint consists of one or more ranges separated by `||`, where a
+// version satisfies the constraint when it satisfies any of the ranges. A range
+// is a list of comparators separated by whitespace or commas, all of which must
+// be satisfied. Supported comparators are:
+//
+//   - `1.2.3` or `=1.2.3`: an exact version
+//   - `!=1.2.3`: anything other than a version
+//   - `>1.2.3`, `>=1.2.3`, `<1.2.3`, `<=1.2.3`: an inequality
+//   - `~1.2.3`: patch updates, equivalent to `>=1.2.3 <1.3.0`
+//   - `^1.2.3`: updates that do not modify the left-most non-zero component,
+//     equivalent to `>=1.2.3 <2.0.0`
+//   - `1.2.3 - 2.3.4`: an inclusive range, equivalent to `>=1.2.3 <=2.3.4`
+//
+// Versions within comparators may be partial or contain the wildcards `x`, `X`
+// or `*`, in which case the missing components match any value, e.g. `1.2`,
+// `1.2.x` and `1.2.*` are all equivalent to `>=1.2.0 <1.3.0`.
+type Constraint struct {
+	ranges [][]comparator
+}
+
+// comparator matches versions within an interval, where a nil bound is
+// unbounded.
+type comparator struct {
+	lower, upper         *Version
+	lowerIncl, upperIncl bool
+	negate               bool
+}
+
+func (c comparator) check(v *Version) bool {
+	matched := true
+	if c.lower != nil {
+		cmp := v.Compare(c.lower)
+		matched = cmp > 0 || (cmp == 0 && c.lowerIncl)
+	}
+	if matched && c.upper != nil {
+		cmp := v.Compare(c.upper)
+		matched = cmp < 0 || (cmp == 0 && c.upperIncl)
+	}
+	return matched != c.negate
+}
+
+// Check returns whether a version satisfies the constraint.
+func (c *Constraint) Check(v *Version) bool {
+	for _, r := range c.ranges {
+		matched := true
+		for _, comp := range r {
+			if !comp.check(v) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseConstraint parses a version range expression.
+func ParseConstraint(s string) (*Constraint, error) {
+	var c Constraint
+	for _, rStr := range strings.Split(s, "||") {
+		r, err := parseRange(rStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid constraint %q: %w", strings.TrimSpace(rStr), err)
+		}
+		c.ranges = append(c.ranges, r)
+	}
+	return &c, nil
+}
+
+func parseRange(s string) ([]comparator, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ','
+	})
+	if len(fields) == 0 {
+		return nil, errors.New("empty range")
+	}
+
+	// Operators may be separated from their version by whitespace, in which
+	// case they're joined back together.
+	var tokens []string
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if strings.Trim(f, "=!<>~^") == "" && f != "-" && i+1 < len(fields) {
+			f += fields[i+1]
+			i++
+		}
+		tokens = append(tokens, f)
+	}
+
+	var comps []comparator
+	for i := 0; i < len(tokens); i++ {
+		if i+2 < len(tokens) && tokens[i+1] == "-" {
+			c, err := parseHyphenRange(tokens[i], tokens[i+2])
+			if err != nil {
+				return nil, err
+			}
+			comps = append(comps, c)
+			i += 2
+			continue
+		}
+		c, err := parseComparator(tokens[i])
+		if err != nil {
+			return nil, err
+		}
+		comps = append(comps, c)
+	}
+	return comps, nil
+}
+
+// partial is a version where any number of trailing components may be
+// omitted.
+type partial struct {
+	parts      []uint64
+	prerelease []string
+}
+
+func parsePartial(s string) (p partial, err error) {
+	str := strings.TrimPrefix(s, "v")
+	if i := strings.IndexByte(str, '+'); i >= 0 {
+		str = str[:i]
+	}
+	if i := strings.IndexByte(str, '-'); i >= 0 {
+		if p.prerelease, err = parseIdentifiers(str[i+1:], true); err != nil {
+			return p, fmt.Errorf("invalid prerelease: %w", err)
+		}
+		str = str[:i]
+	}
+
+	components := strings.Split(str, ".")
+	if len(components) > 3 {
+		return p, fmt.Errorf("expected version %q to have at most three components", s)
+	}
+	wildcard := false
+	for _, c := range components {
+		if c == "x" || c == "X" || c == "*" {
+			wildcard = true
+			continue
+		}
+		if wildcard {
+			return p, fmt.Errorf("version %q contains components after a wildcard", s)
+		}
+		n, err := parseNumber(c)
+		if err != nil {
+			return p, err
+		}
+		p.parts = append(p.parts, n)
+	}
+	if p.prerelease != nil && len(p.parts) != 3 {
+		return p, fmt.Errorf("version %q has a prerelease but is missing components", s)
+	}
+	return p, nil
+}
+
+// floor returns the lowest version that matches the partial version.
+func (p partial) floor() *Version {
+	v := &Version{Prerelease: p.prerelease}
+	for i, dst := range []*uint64{&v.Major, &v.Minor, &v.Patch} {
+		if i < len(p.parts) {
+			*dst = p.parts[i]
+		}
+	}
+	return v
+}
+
+// ceil returns the lowest version that is higher than all versions matching
+// the partial version by incrementing the component at index i, which is
+// given the lowest possible prerelease so that prereleases of it are also
+// excluded.
+func (p partial) ceil(i int) *Version {
+	v := p.floor()
+	v.Prerelease = []string{"0"}
+	switch i {
+	case 0:
+		v.Major, v.Minor, v.Patch = v.Major+1, 0, 0
+	case 1:
+		v.Minor, v.Patch = v.Minor+1, 0
+	default:
+		v.Patch++
+	}
+	return v
+}
+
+// interval returns the comparator matching all versions of the partial.
+func (p partial) interval() comparator {
+	if len(p.parts) == 0 {
+		return comparator{}
+	}
+	if len(p.parts) == 3 {
+		return comparator{lower: p.floor(), lowerIncl: true, upper: p.floor(), upperIncl: true}
+	}
+	return comparator{lower: p.floor(), lowerIncl: true, upper: p.ceil(len(p.parts) - 1)}
+}
+
+func parseComparator(s string) (comparator, error) {
+	opEnd := strings.IndexFunc(s, func(r rune) bool {
+		return !strings.ContainsRune("=!<>~^", r)
+	})
+	if opEnd == -1 {
+		return comparator{}, fmt.Errorf("expected a version after operator %q", s)
+	}
+	op, vStr := s[:opEnd], s[opEnd:]
+
+	p, err := parsePartial(vStr)
+	if err != nil {
+		return comparator{}, err
+	}
+
+	all := p.interval()
+	switch op {
+	case "", "=", "==":
+		return all, nil
+	case "!=":
+		all.negate = true
+		return all, nil
+	case ">":
+		if all.upper == nil {
+			// Nothing is greater than everything.
+			return comparator{negate: true}, nil
+		}
+		return comparator{lower: all.upper, lowerIncl: len(p.parts) < 3}, nil
+	case ">=":
+		return comparator{lower: all.lower, lowerIncl: true}, nil
+	case "<":
+		if all.lower == nil {
+			return comparator{negate: true}, nil
+		}
+		if len(p.parts) < 3 {
+			// Prereleases of a partial version are also excluded.
+			all.lower.Prerelease = []string{"0"}
+		}
+		return comparator{upper: all.lower}, nil
+	case "<=":
+		return comparator{upper: all.upper, upperIncl: len(p.parts) == 3}, nil
+	case "~":
+		if len(p.parts) < 2 {
+			return all, nil
+		}
+		return comparator{lower: p.floor(), lowerIncl: true, upper: p.ceil(1)}, nil
+	case "^":
+		if len(p.parts) == 0 {
+			return all, nil
+		}
+		// Increment the left-most non-zero component, or the right-most
+		// specified component when all are zero.
+		i := 0
+		for i < len(p.parts)-1 && p.parts[i] == 0 {
+			i++
+		}
+		return comparator{lower: p.floor(), lowerIncl: true, upper: p.ceil(i)}, nil
+	}
+	return comparator{}, fmt.Errorf("unrecognised operator %q", op)
+}
+
+func parseHyphenRange(lowerStr, upperStr string) (comparator, error) {
+	lower, err := parsePartial(lowerStr)
+	if err != nil {
+		return comparator{}, err
+	}
+	upper, err := parsePartial(upperStr)
+	if err != nil {
+		return comparator{}, err
+	}
+	c := lower.interval()
+	u := upper.interval()
+	c.upper, c.upperIncl = u.upper, u.upperIncl
+	return c, nil
+}
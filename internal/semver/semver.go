@@ -0,0 +1,164 @@
+// Package semver implements parsing, comparison and range matching of semantic
+// versions as described at https://semver.org.
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version.
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string
+	Build      []string
+}
+
+// Parse a semantic version string of the form
+// MAJOR.MINOR.PATCH[-PRERELEASE][+BUILD]. A leading "v" is permitted and
+// ignored.
+func Parse(s string) (*Version, error) {
+	str := strings.TrimPrefix(s, "v")
+
+	var v Version
+	var err error
+	if i := strings.IndexByte(str, '+'); i >= 0 {
+		if v.Build, err = parseIdentifiers(str[i+1:], false); err != nil {
+			return nil, fmt.Errorf("invalid build metadata: %w", err)
+		}
+		str = str[:i]
+	}
+	if i := strings.IndexByte(str, '-'); i >= 0 {
+		if v.Prerelease, err = parseIdentifiers(str[i+1:], true); err != nil {
+			return nil, fmt.Errorf("invalid prerelease: %w", err)
+		}
+		str = str[:i]
+	}
+
+	parts := strings.Split(str, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("expected version %q to have the format MAJOR.MINOR.PATCH", s)
+	}
+	for i, dst := range []*uint64{&v.Major, &v.Minor, &v.Patch} {
+		if *dst, err = parseNumber(parts[i]); err != nil {
+			return nil, err
+		}
+	}
+	return &v, nil
+}
+
+func parseNumber(s string) (uint64, error) {
+	if s == "" {
+		return 0, errors.New("empty version number")
+	}
+	if len(s) > 1 && s[0] == '0' {
+		return 0, fmt.Errorf("version number %q must not contain leading zeroes", s)
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid version number %q", s)
+	}
+	return n, nil
+}
+
+func isNumeric(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func parseIdentifiers(s string, noLeadingZeroes bool) ([]string, error) {
+	ids := strings.Split(s, ".")
+	for _, id := range ids {
+		if id == "" {
+			return nil, errors.New("empty identifier")
+		}
+		for _, c := range id {
+			if !(c >= '0' && c <= '9') && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && c != '-' {
+				return nil, fmt.Errorf("identifier %q contains invalid character %q", id, c)
+			}
+		}
+		if noLeadingZeroes && len(id) > 1 && id[0] == '0' && isNumeric(id) {
+			return nil, fmt.Errorf("numeric identifier %q must not contain leading zeroes", id)
+		}
+	}
+	return ids, nil
+}
+
+// String returns the canonical form of the version without a "v" prefix.
+func (v *Version) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		b.WriteByte('-')
+		b.WriteString(strings.Join(v.Prerelease, "."))
+	}
+	if len(v.Build) > 0 {
+		b.WriteByte('+')
+		b.WriteString(strings.Join(v.Build, "."))
+	}
+	return b.String()
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Compare returns -1, 0 or 1 when the version has a lower, equal or higher
+// precedence than another respectively. Build metadata is ignored.
+func (v *Version) Compare(o *Version) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+
+	// A version without a prerelease has a higher precedence than one with.
+	switch {
+	case len(v.Prerelease) == 0 && len(o.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(o.Prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.Prerelease) && i < len(o.Prerelease); i++ {
+		if c := compareIdentifier(v.Prerelease[i], o.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(v.Prerelease)), uint64(len(o.Prerelease)))
+}
+
+func compareIdentifier(a, b string) int {
+	aNum, bNum := isNumeric(a), isNumeric(b)
+	switch {
+	case aNum && bNum:
+		an, _ := strconv.ParseUint(a, 10, 64)
+		bn, _ := strconv.ParseUint(b, 10, 64)
+		return compareUint(an, bn)
+	case aNum:
+		return -1
+	case bNum:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
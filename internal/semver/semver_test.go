@@ -0,0 +1,219 @@
+package semver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	v, err := Parse("v1.2.3-beta.11+build.5")
+	require.NoError(t, err)
+	assert.Equal(t, &Version{
+		Major:      1,
+		Minor:      2,
+		Patch:      3,
+		Prerelease: []string{"beta", "11"},
+		Build:      []string{"build", "5"},
+	}, v)
+	assert.Equal(t, "1.2.3-beta.11+build.5", v.String())
+
+	v, err = Parse("10.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, &Version{Major: 10, Patch: 1}, v)
+	assert.Equal(t, "10.0.1", v.String())
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"1.2":         `expected version "1.2" to have the format MAJOR.MINOR.PATCH`,
+		"1.2.3.4":     `expected version "1.2.3.4" to have the format MAJOR.MINOR.PATCH`,
+		"01.2.3":      `version number "01" must not contain leading zeroes`,
+		"1.a.3":       `invalid version number "a"`,
+		"1..3":        `empty version number`,
+		"1.2.3-":      `invalid prerelease: empty identifier`,
+		"1.2.3-01":    `invalid prerelease: numeric identifier "01" must not contain leading zeroes`,
+		"1.2.3+foo_1": `invalid build metadata: identifier "foo_1" contains invalid character '_'`,
+	}
+	for input, exp := range tests {
+		_, err := Parse(input)
+		assert.EqualError(t, err, exp, input)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// In order of increasing precedence as per the spec.
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+		"10.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, err := Parse(ordered[i])
+			require.NoError(t, err)
+			b, err := Parse(ordered[j])
+			require.NoError(t, err)
+
+			exp := 0
+			if i < j {
+				exp = -1
+			} else if i > j {
+				exp = 1
+			}
+			assert.Equal(t, exp, a.Compare(b), "%v vs %v", ordered[i], ordered[j])
+		}
+	}
+
+	a, err := Parse("1.0.0+foo")
+	require.NoError(t, err)
+	b, err := Parse("1.0.0+bar")
+	require.NoError(t, err)
+	assert.Equal(t, 0, a.Compare(b))
+}
+
+func TestConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		matches    []string
+		misses     []string
+	}{
+		{
+			constraint: "1.2.3",
+			matches:    []string{"1.2.3", "1.2.3+build"},
+			misses:     []string{"1.2.4", "1.2.3-beta"},
+		},
+		{
+			constraint: "1.2",
+			matches:    []string{"1.2.0", "1.2.99"},
+			misses:     []string{"1.1.9", "1.3.0", "1.3.0-beta", "1.2.0-beta"},
+		},
+		{
+			constraint: "!=1.2.x",
+			matches:    []string{"1.1.9", "1.3.0"},
+			misses:     []string{"1.2.0", "1.2.5"},
+		},
+		{
+			constraint: ">1.2.3",
+			matches:    []string{"1.2.4", "2.0.0"},
+			misses:     []string{"1.2.3", "1.0.0"},
+		},
+		{
+			constraint: ">1.2",
+			matches:    []string{"1.3.0", "2.0.0"},
+			misses:     []string{"1.2.9"},
+		},
+		{
+			constraint: ">= 1.2.3, < 2",
+			matches:    []string{"1.2.3", "1.9.9"},
+			misses:     []string{"1.2.2", "2.0.0", "2.0.0-rc.1"},
+		},
+		{
+			constraint: "<=1.2",
+			matches:    []string{"1.2.9", "0.1.0"},
+			misses:     []string{"1.3.0"},
+		},
+		{
+			constraint: "<=1.2.3",
+			matches:    []string{"1.2.3", "1.2.3-beta"},
+			misses:     []string{"1.2.4"},
+		},
+		{
+			constraint: "~1.2.3",
+			matches:    []string{"1.2.3", "1.2.9"},
+			misses:     []string{"1.2.2", "1.3.0"},
+		},
+		{
+			constraint: "~1",
+			matches:    []string{"1.0.0", "1.9.0"},
+			misses:     []string{"2.0.0", "0.9.0"},
+		},
+		{
+			constraint: "^1.2.3",
+			matches:    []string{"1.2.3", "1.9.0"},
+			misses:     []string{"1.2.2", "2.0.0"},
+		},
+		{
+			constraint: "^0.2.3",
+			matches:    []string{"0.2.3", "0.2.9"},
+			misses:     []string{"0.3.0", "0.2.2"},
+		},
+		{
+			constraint: "^0.0.3",
+			matches:    []string{"0.0.3"},
+			misses:     []string{"0.0.4"},
+		},
+		{
+			constraint: "^0.0",
+			matches:    []string{"0.0.9"},
+			misses:     []string{"0.1.0"},
+		},
+		{
+			constraint: "1.2 - 2.3.4",
+			matches:    []string{"1.2.0", "2.3.4"},
+			misses:     []string{"1.1.9", "2.3.5"},
+		},
+		{
+			constraint: "1.2.3 - 2",
+			matches:    []string{"1.2.3", "2.9.9"},
+			misses:     []string{"1.2.2", "3.0.0"},
+		},
+		{
+			constraint: "<1.0.0 || >=2.1 <3 || 4.x",
+			matches:    []string{"0.5.0", "2.1.0", "2.9.0", "4.5.6"},
+			misses:     []string{"1.0.0", "2.0.9", "3.0.0", "5.0.0"},
+		},
+		{
+			constraint: "*",
+			matches:    []string{"0.0.0", "1.2.3-beta"},
+		},
+		{
+			constraint: "<*",
+			misses:     []string{"0.0.0"},
+		},
+	}
+
+	for _, test := range tests {
+		c, err := ParseConstraint(test.constraint)
+		require.NoError(t, err, test.constraint)
+
+		for _, vStr := range test.matches {
+			v, err := Parse(vStr)
+			require.NoError(t, err)
+			assert.True(t, c.Check(v), "%v should match %v", test.constraint, vStr)
+		}
+		for _, vStr := range test.misses {
+			v, err := Parse(vStr)
+			require.NoError(t, err)
+			assert.False(t, c.Check(v), "%v should not match %v", test.constraint, vStr)
+		}
+	}
+}
+
+func TestConstraintErrors(t *testing.T) {
+	tests := map[string]string{
+		"":              `invalid constraint "": empty range`,
+		"1.0.0 ||":      `invalid constraint "": empty range`,
+		">=":            `invalid constraint ">=": expected a version after operator ">="`,
+		"=>1.0.0":       `invalid constraint "=>1.0.0": unrecognised operator "=>"`,
+		"1.x.2":         `invalid constraint "1.x.2": version "1.x.2" contains components after a wildcard`,
+		"1.2-beta":      `invalid constraint "1.2-beta": version "1.2-beta" has a prerelease but is missing components`,
+		"1.2.3.4":       `invalid constraint "1.2.3.4": expected version "1.2.3.4" to have at most three components`,
+		"1.0.0 - nope":  `invalid constraint "1.0.0 - nope": invalid version number "nope"`,
+		"^1.0.0 || foo": `invalid constraint "foo": invalid version number "foo"`,
+	}
+	for input, exp := range tests {
+		_, err := ParseConstraint(input)
+		assert.EqualError(t, err, exp, input)
+	}
+}
@@ -1,6 +1,7 @@
 package manager
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -37,7 +38,7 @@ func (m *Type) registerEndpoints(enableCrud bool) {
 	}
 	m.manager.RegisterEndpoint(
 		"/resources/{type}/{id}",
-		"POST: Create or replace a given resource configuration of a specified type. Types supported are `cache`, `input`, `output`, `processor` and `rate_limit`.",
+		"POST: Create or replace a given resource configuration of a specified type. Types supported are `cache`, `input`, `output`, `processor` and `rate_limit`. Replaced inputs are closed before the new input is created, unless the query parameter `hot_swap=true` is set, in which case the new input serves the streams that consume it immediately and the previous input is closed once its in-flight messages are acknowledged.",
 		m.HandleResourceCRUD,
 	)
 	m.manager.RegisterEndpoint(
@@ -437,6 +438,12 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// inputHotSwapper is implemented by managers that are able to replace an input
+// resource before the existing one is closed.
+type inputHotSwapper interface {
+	HotSwapInput(ctx context.Context, name string, conf input.Config) error
+}
+
 // HandleResourceCRUD is an http.HandleFunc for performing CRUD operations on
 // resource components.
 func (m *Type) HandleResourceCRUD(w http.ResponseWriter, r *http.Request) {
@@ -488,6 +495,10 @@ func (m *Type) HandleResourceCRUD(w http.ResponseWriter, r *http.Request) {
 			if inputConf, requestErr = input.FromAny(m.manager.Environment(), n); requestErr != nil {
 				return
 			}
+			if hs, ok := m.manager.(inputHotSwapper); ok && r.URL.Query().Get("hot_swap") == "true" {
+				serverErr = hs.HotSwapInput(ctx, id, inputConf)
+				return
+			}
 			serverErr = m.manager.StoreInput(ctx, id, inputConf)
 		}
 	case docs.TypeOutput:
@@ -48,5 +48,9 @@ func FieldSpec() docs.FieldSpec {
 			docs.FieldString("key_file", "The path of a certificate key to use.").HasDefault(""),
 			docs.FieldString("password", "A plain text password for when the private key is password encrypted in PKCS#1 or PKCS#8 format. The obsolete `pbeWithMD5AndDES-CBC` algorithm is not supported for the PKCS#8 format. Warning: Since it does not authenticate the ciphertext, it is vulnerable to padding oracle attacks that can let an attacker recover the plaintext.", "foo", "${KEY_PASSWORD}").HasDefault("").Secret(),
 		).HasDefault([]any{}),
+
+		docs.FieldString(
+			"reload_interval", "An optional interval at which the files referenced by `client_certs` are checked for modifications, where modified certificates are reloaded without restarting the component. Checks are performed lazily as connections are established, and therefore no files are read when the component is idle. When empty certificates are loaded once.", "1m", "30s",
+		).HasDefault("").Advanced(),
 	).Advanced()
 }
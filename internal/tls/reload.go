@@ -0,0 +1,126 @@
+package tls
+
+import (
+	"crypto/tls"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/redpanda-data/benthos/v4/internal/filepath/ifs"
+)
+
+func hasCertFiles(confs []ClientCertConfig) bool {
+	for _, c := range confs {
+		if c.CertFile != "" || c.KeyFile != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// certReloader provides certificates to a *tls.Config via its GetCertificate
+// and GetClientCertificate callbacks, and reloads certificates from files when
+// they are modified. Modifications are checked lazily, at most once per
+// interval, as handshakes are performed.
+type certReloader struct {
+	fs       ifs.FS
+	interval time.Duration
+	confs    []ClientCertConfig
+
+	mut       sync.Mutex
+	certs     []tls.Certificate
+	modTimes  []time.Time
+	lastCheck time.Time
+	nowFn     func() time.Time
+}
+
+func newCertReloader(f ifs.FS, interval time.Duration, confs []ClientCertConfig) (*certReloader, error) {
+	r := &certReloader{
+		fs:       f,
+		interval: interval,
+		confs:    confs,
+		certs:    make([]tls.Certificate, len(confs)),
+		modTimes: make([]time.Time, len(confs)),
+		nowFn:    time.Now,
+	}
+	for i, conf := range confs {
+		cert, err := conf.Load(f)
+		if err != nil {
+			return nil, err
+		}
+		r.certs[i] = cert
+		if conf.CertFile != "" {
+			if r.modTimes[i], err = r.modTime(conf); err != nil {
+				return nil, err
+			}
+		}
+	}
+	r.lastCheck = r.nowFn()
+	return r, nil
+}
+
+func (r *certReloader) modTime(conf ClientCertConfig) (time.Time, error) {
+	var latest time.Time
+	for _, path := range []string{conf.CertFile, conf.KeyFile} {
+		info, err := fs.Stat(r.fs, path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if t := info.ModTime(); t.After(latest) {
+			latest = t
+		}
+	}
+	return latest, nil
+}
+
+func (r *certReloader) current() []tls.Certificate {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if now := r.nowFn(); now.Sub(r.lastCheck) >= r.interval {
+		r.lastCheck = now
+		for i, conf := range r.confs {
+			if conf.CertFile == "" {
+				continue
+			}
+			// Errors are ignored here as the files may be mid-rotation, in
+			// which case we continue to use the previous certificate and try
+			// again after the next interval.
+			t, err := r.modTime(conf)
+			if err != nil || !t.After(r.modTimes[i]) {
+				continue
+			}
+			cert, err := conf.Load(r.fs)
+			if err != nil {
+				continue
+			}
+			r.certs[i] = cert
+			r.modTimes[i] = t
+		}
+	}
+
+	certs := make([]tls.Certificate, len(r.certs))
+	copy(certs, r.certs)
+	return certs
+}
+
+func (r *certReloader) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	certs := r.current()
+	for i := range certs {
+		if hello.SupportsCertificate(&certs[i]) == nil {
+			return &certs[i], nil
+		}
+	}
+	return &certs[0], nil
+}
+
+func (r *certReloader) getClientCertificate(req *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	certs := r.current()
+	for i := range certs {
+		if req.SupportsCertificate(&certs[i]) == nil {
+			return &certs[i], nil
+		}
+	}
+	// No acceptable certificate found, therefore don't send one.
+	return new(tls.Certificate), nil
+}
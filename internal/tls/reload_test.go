@@ -0,0 +1,102 @@
+package tls
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/filepath/ifs"
+)
+
+func TestCertReloader(t *testing.T) {
+	tmpDir := t.TempDir()
+	certPath, keyPath := filepath.Join(tmpDir, "cert.pem"), filepath.Join(tmpDir, "key.pem")
+
+	writeCerts := func(modTime time.Time) []byte {
+		cert, key := createCertificates()
+		require.NoError(t, os.WriteFile(certPath, cert, 0o600))
+		require.NoError(t, os.WriteFile(keyPath, key, 0o600))
+		require.NoError(t, os.Chtimes(certPath, modTime, modTime))
+		require.NoError(t, os.Chtimes(keyPath, modTime, modTime))
+
+		tlsCert, err := tls.X509KeyPair(cert, key)
+		require.NoError(t, err)
+		return tlsCert.Certificate[0]
+	}
+
+	start := time.Now().Add(-time.Hour)
+	firstCert := writeCerts(start)
+
+	r, err := newCertReloader(ifs.OS(), time.Minute, []ClientCertConfig{
+		{CertFile: certPath, KeyFile: keyPath},
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	r.nowFn = func() time.Time { return now }
+
+	getCert := func() []byte {
+		c, err := r.getClientCertificate(&tls.CertificateRequestInfo{
+			Version:          tls.VersionTLS13,
+			SignatureSchemes: []tls.SignatureScheme{tls.PSSWithSHA256},
+		})
+		require.NoError(t, err)
+		return c.Certificate[0]
+	}
+
+	assert.Equal(t, firstCert, getCert())
+
+	secondCert := writeCerts(start.Add(time.Minute))
+	assert.Equal(t, firstCert, getCert(), "reloaded before the interval elapsed")
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, secondCert, getCert())
+
+	// A mismatched cert and key pair mid-rotation results in the previous
+	// certificate being kept.
+	cert, _ := createCertificates()
+	require.NoError(t, os.WriteFile(certPath, cert, 0o600))
+	require.NoError(t, os.Chtimes(certPath, start.Add(time.Minute*2), start.Add(time.Minute*2)))
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, secondCert, getCert())
+
+	serverCert, err := r.getCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, secondCert, serverCert.Certificate[0])
+}
+
+func TestConfigReloadInterval(t *testing.T) {
+	tmpDir := t.TempDir()
+	certPath, keyPath := filepath.Join(tmpDir, "cert.pem"), filepath.Join(tmpDir, "key.pem")
+
+	cert, key := createCertificates()
+	require.NoError(t, os.WriteFile(certPath, cert, 0o600))
+	require.NoError(t, os.WriteFile(keyPath, key, 0o600))
+
+	conf := NewConfig()
+	conf.ClientCertificates = []ClientCertConfig{
+		{CertFile: certPath, KeyFile: keyPath},
+	}
+
+	tConf, err := conf.GetNonToggled(ifs.OS())
+	require.NoError(t, err)
+	assert.Len(t, tConf.Certificates, 1)
+	assert.Nil(t, tConf.GetClientCertificate)
+
+	conf.ReloadInterval = "1m"
+	tConf, err = conf.GetNonToggled(ifs.OS())
+	require.NoError(t, err)
+	assert.Empty(t, tConf.Certificates)
+	assert.NotNil(t, tConf.GetClientCertificate)
+	assert.NotNil(t, tConf.GetCertificate)
+
+	conf.ReloadInterval = "nope"
+	_, err = conf.GetNonToggled(ifs.OS())
+	require.Error(t, err)
+}
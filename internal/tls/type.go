@@ -7,6 +7,7 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/youmark/pkcs8"
 
@@ -30,6 +31,7 @@ type Config struct {
 	InsecureSkipVerify  bool               `json:"skip_cert_verify" yaml:"skip_cert_verify"`
 	ClientCertificates  []ClientCertConfig `json:"client_certs" yaml:"client_certs"`
 	EnableRenegotiation bool               `json:"enable_renegotiation" yaml:"enable_renegotiation"`
+	ReloadInterval      string             `json:"reload_interval" yaml:"reload_interval"`
 }
 
 // NewConfig creates a new Config with default values.
@@ -41,6 +43,7 @@ func NewConfig() Config {
 		InsecureSkipVerify:  false,
 		ClientCertificates:  []ClientCertConfig{},
 		EnableRenegotiation: false,
+		ReloadInterval:      "",
 	}
 }
 
@@ -84,13 +87,31 @@ func (c *Config) GetNonToggled(f ifs.FS) (*tls.Config, error) {
 		tlsConf.RootCAs.AppendCertsFromPEM([]byte(c.RootCAs))
 	}
 
-	for _, conf := range c.ClientCertificates {
-		cert, err := conf.Load(f)
+	var reloadInterval time.Duration
+	if c.ReloadInterval != "" {
+		var err error
+		if reloadInterval, err = time.ParseDuration(c.ReloadInterval); err != nil {
+			return nil, fmt.Errorf("failed to parse reload_interval: %w", err)
+		}
+	}
+
+	if reloadInterval > 0 && hasCertFiles(c.ClientCertificates) {
+		r, err := newCertReloader(f, reloadInterval, c.ClientCertificates)
 		if err != nil {
 			return nil, err
 		}
 		initConf()
-		tlsConf.Certificates = append(tlsConf.Certificates, cert)
+		tlsConf.GetCertificate = r.getCertificate
+		tlsConf.GetClientCertificate = r.getClientCertificate
+	} else {
+		for _, conf := range c.ClientCertificates {
+			cert, err := conf.Load(f)
+			if err != nil {
+				return nil, err
+			}
+			initConf()
+			tlsConf.Certificates = append(tlsConf.Certificates, cert)
+		}
 	}
 
 	if c.EnableRenegotiation {
@@ -48,6 +48,13 @@ func GetTraceID(p *message.Part) string {
 	return span.SpanContext().TraceID().String()
 }
 
+// GetSpanContext returns the context of a span attached to a message part,
+// which is invalid if the part doesn't have a span attached.
+func GetSpanContext(p *message.Part) trace.SpanContext {
+	ctx := message.GetContext(p)
+	return trace.SpanFromContext(ctx).SpanContext()
+}
+
 // WithChildSpan takes a message, extracts a span, creates a new child span,
 // and returns a new message with that span embedded. The original message is
 // unchanged.
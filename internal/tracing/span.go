@@ -2,6 +2,7 @@ package tracing
 
 import (
 	"context"
+	"fmt"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -51,6 +52,28 @@ func (s *Span) SetTag(key, value string) {
 	s.w.SetAttributes(attribute.String(key, value))
 }
 
+// SetAttribute sets an attribute of the span, where values that are not
+// booleans, 64-bit integers, 64-bit floats or strings are formatted as strings.
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	var kv attribute.KeyValue
+	switch t := value.(type) {
+	case bool:
+		kv = attribute.Bool(key, t)
+	case int64:
+		kv = attribute.Int64(key, t)
+	case float64:
+		kv = attribute.Float64(key, t)
+	case string:
+		kv = attribute.String(key, t)
+	default:
+		kv = attribute.String(key, fmt.Sprint(t))
+	}
+	s.w.SetAttributes(kv)
+}
+
 // Finish the span.
 func (s *Span) Finish() {
 	if s == nil {
@@ -0,0 +1,215 @@
+package value
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/cockroachdb/apd/v3"
+)
+
+// DecimalDivisionPrecision is the number of significant digits that the result
+// of a decimal division or modulo is rounded to, which matches the precision of
+// the IEEE 754 decimal128 format.
+const DecimalDivisionPrecision = 34
+
+var (
+	decimalExactCtx = apd.BaseContext
+	decimalDivCtx   = apd.BaseContext.WithPrecision(DecimalDivisionPrecision)
+)
+
+// DecimalRoundingModes lists the supported modes of rounding a decimal.
+var DecimalRoundingModes = []string{
+	string(apd.RoundHalfUp),
+	string(apd.RoundHalfEven),
+	string(apd.RoundHalfDown),
+	string(apd.RoundUp),
+	string(apd.RoundDown),
+	string(apd.RoundCeiling),
+	string(apd.RoundFloor),
+}
+
+// Decimal is an arbitrary-precision decimal number. Addition, subtraction and
+// multiplication of decimals are exact, and division is rounded to
+// DecimalDivisionPrecision significant digits.
+//
+// When serialised as JSON a decimal is written as a number literal with all of
+// its digits.
+type Decimal struct {
+	d apd.Decimal
+}
+
+// ParseDecimal attempts to parse a string as a decimal number.
+func ParseDecimal(s string) (*Decimal, error) {
+	var dec Decimal
+	if _, _, err := dec.d.SetString(s); err != nil {
+		return nil, fmt.Errorf("failed to parse %q as a decimal", s)
+	}
+	if dec.d.Form != apd.Finite {
+		return nil, fmt.Errorf("failed to parse %q as a decimal: value must be finite", s)
+	}
+	return &dec, nil
+}
+
+// DecimalFromInt returns a decimal holding an integer value.
+func DecimalFromInt(i int64) *Decimal {
+	var dec Decimal
+	dec.d.SetInt64(i)
+	return &dec
+}
+
+// IToDecimal takes a boxed value and attempts to convert it into a decimal.
+// Floating point values are converted using the shortest representation that
+// round trips, and so 0.1 results in a decimal of exactly 0.1.
+func IToDecimal(v any) (*Decimal, error) {
+	switch t := v.(type) {
+	case *Decimal:
+		return t, nil
+	case float32:
+		return decimalFromFloat(float64(t), 32)
+	case float64:
+		return decimalFromFloat(t, 64)
+	case json.Number:
+		return ParseDecimal(t.String())
+	case string:
+		return ParseDecimal(t)
+	case []byte:
+		return ParseDecimal(string(t))
+	case uint, uint64:
+		u, _ := IGetUInt(t)
+		return ParseDecimal(strconv.FormatUint(u, 10))
+	case int, int8, int16, int32, int64, uint8, uint16, uint32:
+		i, _ := IGetInt(t)
+		return DecimalFromInt(i), nil
+	}
+	return nil, NewTypeError(v, TNumber, TString)
+}
+
+func decimalFromFloat(f float64, bitSize int) (*Decimal, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, fmt.Errorf("cannot convert %v to a decimal", f)
+	}
+	return ParseDecimal(strconv.FormatFloat(f, 'f', -1, bitSize))
+}
+
+// String returns the decimal in plain notation without an exponent.
+func (d *Decimal) String() string {
+	if d.d.IsZero() && d.d.Exponent > 0 {
+		// Plain formatting of zero with a positive exponent pads zeroes.
+		return "0"
+	}
+	return d.d.Text('f')
+}
+
+// MarshalJSON writes the decimal as a JSON number literal.
+func (d *Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Float64 returns the closest float64 to the decimal.
+func (d *Decimal) Float64() (float64, error) {
+	return d.d.Float64()
+}
+
+// Int64 returns the decimal as an int64, or an error if the decimal contains a
+// fractional part or does not fit within an int64.
+func (d *Decimal) Int64() (int64, error) {
+	var integ, frac apd.Decimal
+	d.d.Modf(&integ, &frac)
+	if !frac.IsZero() {
+		return 0, errors.New("decimal value contains decimals and therefore cannot be cast as a signed integer, if you intend to round the value then call `.round_decimal()` explicitly before this cast")
+	}
+	i, err := d.d.Int64()
+	if err != nil {
+		return 0, errors.New("decimal value is too large to be cast as a signed integer")
+	}
+	return i, nil
+}
+
+// IsZero returns true if the decimal is zero.
+func (d *Decimal) IsZero() bool {
+	return d.d.IsZero()
+}
+
+// Cmp compares two decimals and returns -1, 0 or 1 when the decimal is less
+// than, equal to or greater than another respectively.
+func (d *Decimal) Cmp(o *Decimal) int {
+	return d.d.Cmp(&o.d)
+}
+
+func decimalOp(ctx *apd.Context, fn func(ctx *apd.Context, res, x, y *apd.Decimal) (apd.Condition, error), x, y *Decimal) (*Decimal, error) {
+	var res Decimal
+	if _, err := fn(ctx, &res.d, &x.d, &y.d); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Add returns the sum of two decimals.
+func (d *Decimal) Add(o *Decimal) (*Decimal, error) {
+	return decimalOp(&decimalExactCtx, (*apd.Context).Add, d, o)
+}
+
+// Sub returns the difference of two decimals.
+func (d *Decimal) Sub(o *Decimal) (*Decimal, error) {
+	return decimalOp(&decimalExactCtx, (*apd.Context).Sub, d, o)
+}
+
+// Mul returns the product of two decimals.
+func (d *Decimal) Mul(o *Decimal) (*Decimal, error) {
+	return decimalOp(&decimalExactCtx, (*apd.Context).Mul, d, o)
+}
+
+// Quo returns the quotient of two decimals rounded to
+// DecimalDivisionPrecision significant digits, with trailing zeroes removed.
+func (d *Decimal) Quo(o *Decimal) (*Decimal, error) {
+	res, err := decimalOp(decimalDivCtx, (*apd.Context).Quo, d, o)
+	if err != nil {
+		return nil, err
+	}
+	res.d.Reduce(&res.d)
+	return res, nil
+}
+
+// Rem returns the remainder of dividing two decimals, where the result has the
+// sign of the dividend.
+func (d *Decimal) Rem(o *Decimal) (*Decimal, error) {
+	return decimalOp(decimalDivCtx, (*apd.Context).Rem, d, o)
+}
+
+// Round returns the decimal rounded to a number of decimal places using a
+// rounding mode from DecimalRoundingModes. A negative number of places rounds
+// to the left of the decimal point.
+func (d *Decimal) Round(places int32, mode string) (*Decimal, error) {
+	var rounder apd.Rounder
+	for _, m := range DecimalRoundingModes {
+		if m == mode {
+			rounder = apd.Rounder(m)
+		}
+	}
+	if rounder == "" {
+		return nil, fmt.Errorf("unrecognised rounding mode %q, expected one of %v", mode, DecimalRoundingModes)
+	}
+
+	// Quantizing requires enough precision to hold every digit of the result.
+	exp := -places
+	precision := d.d.NumDigits() + int64(math.Abs(float64(d.d.Exponent))) + int64(math.Abs(float64(exp))) + 1
+
+	ctx := decimalExactCtx.WithPrecision(uint32(precision))
+	ctx.Rounding = rounder
+
+	var res Decimal
+	if _, err := ctx.Quantize(&res.d, &d.d, exp); err != nil {
+		return nil, err
+	}
+	if exp > 0 {
+		// Bring the exponent back to zero so that the result is formatted as a
+		// plain integer.
+		if _, err := ctx.Quantize(&res.d, &res.d, 0); err != nil {
+			return nil, err
+		}
+	}
+	return &res, nil
+}
@@ -0,0 +1,148 @@
+package value
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustDecimal(t testing.TB, s string) *Decimal {
+	t.Helper()
+	d, err := ParseDecimal(s)
+	require.NoError(t, err)
+	return d
+}
+
+func TestDecimalParse(t *testing.T) {
+	for in, exp := range map[string]string{
+		"1.50":                           "1.50",
+		"-0.001":                         "-0.001",
+		"1e3":                            "1000",
+		"0e3":                            "0",
+		"1.23e-2":                        "0.0123",
+		"12345678901234567890.123456789": "12345678901234567890.123456789",
+	} {
+		d, err := ParseDecimal(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, exp, d.String(), in)
+	}
+
+	_, err := ParseDecimal("nope")
+	assert.EqualError(t, err, `failed to parse "nope" as a decimal`)
+
+	_, err = ParseDecimal("NaN")
+	assert.EqualError(t, err, `failed to parse "NaN" as a decimal: value must be finite`)
+}
+
+func TestIToDecimal(t *testing.T) {
+	for _, test := range []struct {
+		in  any
+		out string
+	}{
+		{in: 0.1, out: "0.1"},
+		{in: float32(0.1), out: "0.1"},
+		{in: int64(-5), out: "-5"},
+		{in: uint64(18446744073709551615), out: "18446744073709551615"},
+		{in: json.Number("3.14"), out: "3.14"},
+		{in: []byte("2.5"), out: "2.5"},
+	} {
+		d, err := IToDecimal(test.in)
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.out, d.String(), test.in)
+	}
+
+	_, err := IToDecimal(true)
+	require.Error(t, err)
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	a, b := mustDecimal(t, "0.1"), mustDecimal(t, "0.2")
+
+	res, err := a.Add(b)
+	require.NoError(t, err)
+	assert.Equal(t, "0.3", res.String())
+
+	res, err = a.Sub(b)
+	require.NoError(t, err)
+	assert.Equal(t, "-0.1", res.String())
+
+	res, err = a.Mul(b)
+	require.NoError(t, err)
+	assert.Equal(t, "0.02", res.String())
+
+	res, err = b.Quo(a)
+	require.NoError(t, err)
+	assert.Equal(t, "2", res.String())
+
+	res, err = mustDecimal(t, "2").Quo(mustDecimal(t, "3"))
+	require.NoError(t, err)
+	assert.Equal(t, "0.6666666666666666666666666666666667", res.String())
+
+	res, err = mustDecimal(t, "-7.5").Rem(mustDecimal(t, "2"))
+	require.NoError(t, err)
+	assert.Equal(t, "-1.5", res.String())
+
+	assert.Equal(t, -1, a.Cmp(b))
+	assert.Equal(t, 0, a.Cmp(mustDecimal(t, "0.10")))
+}
+
+func TestDecimalRound(t *testing.T) {
+	for _, test := range []struct {
+		in     string
+		places int32
+		mode   string
+		out    string
+	}{
+		{in: "2.345", places: 2, mode: "half_up", out: "2.35"},
+		{in: "2.345", places: 2, mode: "half_even", out: "2.34"},
+		{in: "2.355", places: 2, mode: "half_even", out: "2.36"},
+		{in: "2.345", places: 2, mode: "half_down", out: "2.34"},
+		{in: "2.341", places: 2, mode: "up", out: "2.35"},
+		{in: "2.349", places: 2, mode: "down", out: "2.34"},
+		{in: "-2.341", places: 2, mode: "ceiling", out: "-2.34"},
+		{in: "-2.341", places: 2, mode: "floor", out: "-2.35"},
+		{in: "-0.5", places: 0, mode: "half_up", out: "-1"},
+		{in: "2.5", places: 3, mode: "half_up", out: "2.500"},
+		{in: "1234.5", places: -2, mode: "half_up", out: "1200"},
+		{in: "1", places: -1, mode: "half_up", out: "0"},
+	} {
+		res, err := mustDecimal(t, test.in).Round(test.places, test.mode)
+		require.NoError(t, err)
+		assert.Equal(t, test.out, res.String(), "%v %v %v", test.in, test.places, test.mode)
+	}
+
+	_, err := mustDecimal(t, "1").Round(0, "nope")
+	assert.EqualError(t, err, `unrecognised rounding mode "nope", expected one of [half_up half_even half_down up down ceiling floor]`)
+}
+
+func TestDecimalConversions(t *testing.T) {
+	d := mustDecimal(t, "12.50")
+
+	b, err := json.Marshal(map[string]any{"v": d})
+	require.NoError(t, err)
+	assert.Equal(t, `{"v":12.50}`, string(b))
+
+	assert.Equal(t, TNumber, ITypeOf(d))
+	assert.Equal(t, "12.50", IToString(d))
+
+	f, err := IGetNumber(d)
+	require.NoError(t, err)
+	assert.Equal(t, 12.5, f)
+
+	_, err = IToInt(d)
+	require.Error(t, err)
+
+	i, err := IToInt(mustDecimal(t, "12.00"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), i)
+
+	_, err = IToUint(mustDecimal(t, "-1"))
+	require.Error(t, err)
+
+	assert.True(t, ICompare(d, 12.5))
+	assert.True(t, ICompare(12.5, d))
+	assert.True(t, ICompare(json.Number("12.5"), d))
+	assert.False(t, ICompare(d, "12.50"))
+}
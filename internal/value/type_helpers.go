@@ -42,7 +42,7 @@ func ITypeOf(i any) Type {
 		return TString
 	case []byte:
 		return TBytes
-	case int, int8, int16, int32, int64, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+	case int, int8, int16, int32, int64, uint8, uint16, uint32, uint64, float32, float64, json.Number, *Decimal:
 		return TNumber
 	case bool:
 		return TBool
@@ -107,6 +107,8 @@ func IGetNumber(v any) (float64, error) {
 		return t, nil
 	case json.Number:
 		return t.Float64()
+	case *Decimal:
+		return t.Float64()
 	}
 	return 0, NewTypeError(v, TNumber)
 }
@@ -142,6 +144,9 @@ func IGetFloat32(v any) (float32, error) {
 	case json.Number:
 		v, e := t.Float64()
 		return float32(v), e
+	case *Decimal:
+		v, e := t.Float64()
+		return float32(v), e
 	}
 	return 0, NewTypeError(v, TNumber)
 }
@@ -183,6 +188,12 @@ func IGetInt(v any) (int64, error) {
 			return int64(f), nil
 		}
 		return 0, err
+	case *Decimal:
+		if i, err := t.Int64(); err == nil {
+			return i, nil
+		}
+		f, err := t.Float64()
+		return int64(f), err
 	}
 	return 0, NewTypeError(v, TNumber)
 }
@@ -191,7 +202,7 @@ func IGetInt(v any) (int64, error) {
 // (uint64) from it.
 func IGetUInt(v any) (uint64, error) {
 	switch v.(type) {
-	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number, *Decimal:
 		// We're passing through here because it handles out of bounds issues.
 		return IToUint(v)
 	}
@@ -229,6 +240,8 @@ func IGetBool(v any) (bool, error) {
 		return t != 0, nil
 	case json.Number:
 		return t.String() != "0", nil
+	case *Decimal:
+		return !t.IsZero(), nil
 	}
 	return false, NewTypeError(v, TBool)
 }
@@ -327,10 +340,10 @@ func RestrictForComparison(v any) any {
 
 // ISanitize takes a boxed value of any type and attempts to convert it into one
 // of the following types: string, []byte, int64, uint64, float64, bool,
-// []interface{}, map[string]interface{}, Delete, Nothing.
+// []interface{}, map[string]interface{}, Delete, Nothing, *Decimal.
 func ISanitize(i any) any {
 	switch t := i.(type) {
-	case string, []byte, int64, uint64, float64, bool, []any, map[string]any, Delete, Nothing:
+	case string, []byte, int64, uint64, float64, bool, []any, map[string]any, Delete, Nothing, *Decimal:
 		return i
 	case json.RawMessage:
 		return []byte(t)
@@ -377,6 +390,8 @@ func IToBytes(i any) []byte {
 		return t
 	case json.Number:
 		return []byte(t.String())
+	case *Decimal:
+		return []byte(t.String())
 	case int64:
 		return strconv.AppendInt(nil, t, 10)
 	case uint64:
@@ -413,6 +428,8 @@ func IToString(i any) string {
 		return strconv.FormatFloat(t, 'g', -1, 64)
 	case json.Number:
 		return t.String()
+	case *Decimal:
+		return t.String()
 	case bool:
 		if t {
 			return "true"
@@ -463,6 +480,8 @@ func IToFloat64(v any) (float64, error) {
 		return float64(t), nil
 	case json.Number:
 		return t.Float64()
+	case *Decimal:
+		return t.Float64()
 	case []byte:
 		return strconv.ParseFloat(string(t), 64)
 	case string:
@@ -503,6 +522,12 @@ func IToFloat32(v any) (float32, error) {
 			return 0, err
 		}
 		return float32(f64), nil
+	case *Decimal:
+		f64, err := t.Float64()
+		if err != nil {
+			return 0, err
+		}
+		return float32(f64), nil
 	case []byte:
 		f64, err := strconv.ParseFloat(string(t), 32)
 		if err != nil {
@@ -582,6 +607,8 @@ func IToInt(v any) (int64, error) {
 		return int64(t), nil
 	case json.Number:
 		return t.Int64()
+	case *Decimal:
+		return t.Int64()
 	case []byte:
 		return strconv.ParseInt(string(t), 0, 64)
 	case string:
@@ -702,6 +729,15 @@ func IToUint(v any) (uint64, error) {
 			return 0, errors.New("signed integer value is negative and cannot be cast as an unsigned integer")
 		}
 		return uint64(i), nil
+	case *Decimal:
+		if t.d.Negative && !t.IsZero() {
+			return 0, errors.New("decimal value is negative and cannot be cast as an unsigned integer")
+		}
+		i, err := t.Int64()
+		if err != nil {
+			return 0, err
+		}
+		return uint64(i), nil
 	case []byte:
 		return strconv.ParseUint(string(t), 0, 64)
 	case string:
@@ -790,6 +826,8 @@ func IToBool(v any) (bool, error) {
 		return t != 0, nil
 	case json.Number:
 		return t.String() != "0", nil
+	case *Decimal:
+		return !t.IsZero(), nil
 	case []byte:
 		if v, err := strconv.ParseBool(string(t)); err == nil {
 			return v, nil
@@ -840,11 +878,23 @@ func ICompare(left, right any) bool {
 		}
 		return lhs == rhs
 	case float64:
+		if rDec, isDec := right.(*Decimal); isDec {
+			return ICompare(rDec, left)
+		}
 		rhs, err := IGetNumber(right)
 		if err != nil {
 			return false
 		}
 		return lhs == rhs
+	case *Decimal:
+		if ITypeOf(right) != TNumber {
+			return false
+		}
+		rhs, err := IToDecimal(right)
+		if err != nil {
+			return false
+		}
+		return lhs.Cmp(rhs) == 0
 	case bool:
 		rhs, err := IGetBool(right)
 		if err != nil {
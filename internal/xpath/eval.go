@@ -0,0 +1,500 @@
+package xpath
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Evaluate executes the expression against a document, returning either a
+// []*Node when the result is a node-set, or a string, float64 or bool.
+func (e *Expression) Evaluate(doc *Node) (any, error) {
+	return e.root.eval(evalCtx{node: doc, pos: 1, size: 1})
+}
+
+type evalCtx struct {
+	node *Node
+	pos  int
+	size int
+}
+
+type expr interface {
+	eval(ctx evalCtx) (any, error)
+}
+
+//------------------------------------------------------------------------------
+
+const (
+	axisChild            = "child"
+	axisDescendant       = "descendant"
+	axisDescendantOrSelf = "descendant-or-self"
+	axisSelf             = "self"
+	axisParent           = "parent"
+	axisAncestor         = "ancestor"
+	axisAncestorOrSelf   = "ancestor-or-self"
+	axisAttribute        = "attribute"
+	axisFollowingSibling = "following-sibling"
+	axisPrecedingSibling = "preceding-sibling"
+)
+
+func validAxis(name string) bool {
+	switch name {
+	case axisChild, axisDescendant, axisDescendantOrSelf, axisSelf, axisParent,
+		axisAncestor, axisAncestorOrSelf, axisAttribute, axisFollowingSibling,
+		axisPrecedingSibling:
+		return true
+	}
+	return false
+}
+
+// axisNodes returns the nodes of an axis relative to a node, where the nodes
+// of reverse axes (such as ancestor) are ordered nearest first, which is the
+// order that positions within predicates count from.
+func axisNodes(axis string, n *Node) []*Node {
+	var nodes []*Node
+	var descend func(c *Node)
+	descend = func(c *Node) {
+		for _, cc := range c.Children {
+			nodes = append(nodes, cc)
+			descend(cc)
+		}
+	}
+
+	switch axis {
+	case axisChild:
+		nodes = append(nodes, n.Children...)
+	case axisDescendant:
+		descend(n)
+	case axisDescendantOrSelf:
+		nodes = append(nodes, n)
+		descend(n)
+	case axisSelf:
+		nodes = append(nodes, n)
+	case axisParent:
+		if n.Parent != nil {
+			nodes = append(nodes, n.Parent)
+		}
+	case axisAncestorOrSelf:
+		nodes = append(nodes, n)
+		fallthrough
+	case axisAncestor:
+		for p := n.Parent; p != nil; p = p.Parent {
+			nodes = append(nodes, p)
+		}
+	case axisAttribute:
+		nodes = append(nodes, n.Attributes...)
+	case axisFollowingSibling, axisPrecedingSibling:
+		if n.Parent == nil || n.Type == AttributeNode {
+			return nil
+		}
+		siblings := n.Parent.Children
+		for i, s := range siblings {
+			if s != n {
+				continue
+			}
+			if axis == axisFollowingSibling {
+				nodes = append(nodes, siblings[i+1:]...)
+			} else {
+				for j := i - 1; j >= 0; j-- {
+					nodes = append(nodes, siblings[j])
+				}
+			}
+			break
+		}
+	}
+	return nodes
+}
+
+//------------------------------------------------------------------------------
+
+type nodeTestKind int
+
+const (
+	testName nodeTestKind = iota
+	testWildcard
+	testNamespaceWildcard
+	testText
+	testNode
+)
+
+type nodeTest struct {
+	kind  nodeTestKind
+	space string
+	local string
+}
+
+func cutQName(name string) (prefix, local string, hasPrefix bool) {
+	if prefix, local, hasPrefix = strings.Cut(name, ":"); !hasPrefix {
+		local = name
+	}
+	return
+}
+
+func (t nodeTest) matches(axis string, n *Node) bool {
+	// The principal node type of the attribute axis is attributes, and for
+	// all other axes it's elements.
+	principal := ElementNode
+	if axis == axisAttribute {
+		principal = AttributeNode
+	}
+	switch t.kind {
+	case testNode:
+		return true
+	case testText:
+		return n.Type == TextNode
+	case testWildcard:
+		return n.Type == principal
+	case testNamespaceWildcard:
+		return n.Type == principal && n.Space == t.space
+	}
+	return n.Type == principal && n.Space == t.space && n.Local == t.local
+}
+
+type step struct {
+	axis  string
+	test  nodeTest
+	preds []expr
+}
+
+func (s step) eval(n *Node) ([]*Node, error) {
+	var matched []*Node
+	for _, c := range axisNodes(s.axis, n) {
+		if s.test.matches(s.axis, c) {
+			matched = append(matched, c)
+		}
+	}
+	for _, pred := range s.preds {
+		var err error
+		if matched, err = filterNodes(matched, pred); err != nil {
+			return nil, err
+		}
+	}
+	return matched, nil
+}
+
+func filterNodes(nodes []*Node, pred expr) ([]*Node, error) {
+	var filtered []*Node
+	for i, n := range nodes {
+		v, err := pred.eval(evalCtx{node: n, pos: i + 1, size: len(nodes)})
+		if err != nil {
+			return nil, err
+		}
+		if f, isNum := v.(float64); isNum {
+			if f == float64(i+1) {
+				filtered = append(filtered, n)
+			}
+		} else if toBool(v) {
+			filtered = append(filtered, n)
+		}
+	}
+	return filtered, nil
+}
+
+func sortDocOrder(nodes []*Node) []*Node {
+	seen := make(map[*Node]struct{}, len(nodes))
+	unique := nodes[:0:0]
+	for _, n := range nodes {
+		if _, exists := seen[n]; !exists {
+			seen[n] = struct{}{}
+			unique = append(unique, n)
+		}
+	}
+	sort.SliceStable(unique, func(i, j int) bool {
+		return unique[i].order < unique[j].order
+	})
+	return unique
+}
+
+//------------------------------------------------------------------------------
+
+type pathExpr struct {
+	filter   expr
+	absolute bool
+	steps    []step
+}
+
+func (p *pathExpr) eval(ctx evalCtx) (any, error) {
+	var current []*Node
+	switch {
+	case p.filter != nil:
+		v, err := p.filter.eval(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var isNodes bool
+		if current, isNodes = v.([]*Node); !isNodes {
+			return nil, errors.New("expected a node-set as the start of a path")
+		}
+	case p.absolute:
+		root := ctx.node
+		for root.Parent != nil {
+			root = root.Parent
+		}
+		current = []*Node{root}
+	default:
+		current = []*Node{ctx.node}
+	}
+
+	for _, s := range p.steps {
+		var next []*Node
+		for _, n := range current {
+			matched, err := s.eval(n)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, matched...)
+		}
+		current = sortDocOrder(next)
+	}
+	if current == nil {
+		current = []*Node{}
+	}
+	return current, nil
+}
+
+type filterExpr struct {
+	primary expr
+	preds   []expr
+}
+
+func (f *filterExpr) eval(ctx evalCtx) (any, error) {
+	v, err := f.primary.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes, isNodes := v.([]*Node)
+	if !isNodes {
+		return nil, errors.New("predicates can only be applied to node-sets")
+	}
+	for _, pred := range f.preds {
+		if nodes, err = filterNodes(nodes, pred); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+type literalExpr struct {
+	v any
+}
+
+func (l *literalExpr) eval(evalCtx) (any, error) {
+	return l.v, nil
+}
+
+type negExpr struct {
+	e expr
+}
+
+func (n *negExpr) eval(ctx evalCtx) (any, error) {
+	v, err := n.e.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return -toNumber(v), nil
+}
+
+type binaryExpr struct {
+	op   string
+	l, r expr
+}
+
+func (b *binaryExpr) eval(ctx evalCtx) (any, error) {
+	l, err := b.l.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Boolean operators short circuit.
+	switch b.op {
+	case "and":
+		if !toBool(l) {
+			return false, nil
+		}
+	case "or":
+		if toBool(l) {
+			return true, nil
+		}
+	}
+
+	r, err := b.r.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch b.op {
+	case "and", "or":
+		return toBool(r), nil
+	case "|":
+		ln, lok := l.([]*Node)
+		rn, rok := r.([]*Node)
+		if !lok || !rok {
+			return nil, errors.New("the union operator can only be applied to node-sets")
+		}
+		return sortDocOrder(append(append([]*Node{}, ln...), rn...)), nil
+	case "=", "!=", "<", "<=", ">", ">=":
+		return compare(b.op, l, r), nil
+	}
+
+	ln, rn := toNumber(l), toNumber(r)
+	switch b.op {
+	case "+":
+		return ln + rn, nil
+	case "-":
+		return ln - rn, nil
+	case "*":
+		return ln * rn, nil
+	case "div":
+		return ln / rn, nil
+	case "mod":
+		return math.Mod(ln, rn), nil
+	}
+	return nil, fmt.Errorf("unsupported operator '%v'", b.op)
+}
+
+//------------------------------------------------------------------------------
+
+func toString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case bool:
+		if t {
+			return "true"
+		}
+		return "false"
+	case float64:
+		return numberToString(t)
+	case []*Node:
+		if len(t) == 0 {
+			return ""
+		}
+		return t[0].StringValue()
+	}
+	return ""
+}
+
+func numberToString(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func stringToNumber(s string) float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return f
+}
+
+func toNumber(v any) float64 {
+	switch t := v.(type) {
+	case float64:
+		return t
+	case bool:
+		if t {
+			return 1
+		}
+		return 0
+	}
+	return stringToNumber(toString(v))
+}
+
+func toBool(v any) bool {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case float64:
+		return t != 0 && !math.IsNaN(t)
+	case string:
+		return t != ""
+	case []*Node:
+		return len(t) > 0
+	}
+	return false
+}
+
+func compareAtoms(op string, l, r any) bool {
+	if op == "=" || op == "!=" {
+		var eq bool
+		switch {
+		case isBool(l) || isBool(r):
+			eq = toBool(l) == toBool(r)
+		case isNumber(l) || isNumber(r):
+			eq = toNumber(l) == toNumber(r)
+		default:
+			eq = toString(l) == toString(r)
+		}
+		return eq == (op == "=")
+	}
+	ln, rn := toNumber(l), toNumber(r)
+	switch op {
+	case "<":
+		return ln < rn
+	case "<=":
+		return ln <= rn
+	case ">":
+		return ln > rn
+	}
+	return ln >= rn
+}
+
+func isBool(v any) bool {
+	_, ok := v.(bool)
+	return ok
+}
+
+func isNumber(v any) bool {
+	_, ok := v.(float64)
+	return ok
+}
+
+// compare implements comparisons as defined by XPath, where a comparison
+// involving a node-set is true if it holds for any of its nodes.
+func compare(op string, l, r any) bool {
+	ln, lIsNodes := l.([]*Node)
+	rn, rIsNodes := r.([]*Node)
+
+	switch {
+	case lIsNodes && rIsNodes:
+		for _, a := range ln {
+			for _, b := range rn {
+				if compareAtoms(op, a.StringValue(), b.StringValue()) {
+					return true
+				}
+			}
+		}
+		return false
+	case lIsNodes:
+		if isBool(r) {
+			return compareAtoms(op, toBool(l), r)
+		}
+		for _, a := range ln {
+			if compareAtoms(op, a.StringValue(), r) {
+				return true
+			}
+		}
+		return false
+	case rIsNodes:
+		if isBool(l) {
+			return compareAtoms(op, l, toBool(r))
+		}
+		for _, b := range rn {
+			if compareAtoms(op, l, b.StringValue()) {
+				return true
+			}
+		}
+		return false
+	}
+	return compareAtoms(op, l, r)
+}
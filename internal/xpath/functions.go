@@ -0,0 +1,193 @@
+package xpath
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+type funcSpec struct {
+	minArgs, maxArgs int
+	fn               func(ctx evalCtx, args []any) (any, error)
+}
+
+// A maxArgs of -1 indicates a variadic function.
+var functions = map[string]funcSpec{
+	"last": {0, 0, func(ctx evalCtx, _ []any) (any, error) {
+		return float64(ctx.size), nil
+	}},
+	"position": {0, 0, func(ctx evalCtx, _ []any) (any, error) {
+		return float64(ctx.pos), nil
+	}},
+	"count": {1, 1, func(_ evalCtx, args []any) (any, error) {
+		nodes, err := argNodes(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return float64(len(nodes)), nil
+	}},
+	"sum": {1, 1, func(_ evalCtx, args []any) (any, error) {
+		nodes, err := argNodes(args[0])
+		if err != nil {
+			return nil, err
+		}
+		var total float64
+		for _, n := range nodes {
+			total += stringToNumber(n.StringValue())
+		}
+		return total, nil
+	}},
+	"name":          {0, 1, nameFunc(func(n *Node) string { return n.Local })},
+	"local-name":    {0, 1, nameFunc(func(n *Node) string { return n.Local })},
+	"namespace-uri": {0, 1, nameFunc(func(n *Node) string { return n.Space })},
+	"string": {0, 1, func(ctx evalCtx, args []any) (any, error) {
+		return toString(argOrContext(ctx, args)), nil
+	}},
+	"concat": {2, -1, func(_ evalCtx, args []any) (any, error) {
+		var buf strings.Builder
+		for _, a := range args {
+			buf.WriteString(toString(a))
+		}
+		return buf.String(), nil
+	}},
+	"contains": {2, 2, func(_ evalCtx, args []any) (any, error) {
+		return strings.Contains(toString(args[0]), toString(args[1])), nil
+	}},
+	"starts-with": {2, 2, func(_ evalCtx, args []any) (any, error) {
+		return strings.HasPrefix(toString(args[0]), toString(args[1])), nil
+	}},
+	"substring-before": {2, 2, func(_ evalCtx, args []any) (any, error) {
+		before, _, found := strings.Cut(toString(args[0]), toString(args[1]))
+		if !found {
+			return "", nil
+		}
+		return before, nil
+	}},
+	"substring-after": {2, 2, func(_ evalCtx, args []any) (any, error) {
+		_, after, found := strings.Cut(toString(args[0]), toString(args[1]))
+		if !found {
+			return "", nil
+		}
+		return after, nil
+	}},
+	"substring": {2, 3, func(_ evalCtx, args []any) (any, error) {
+		runes := []rune(toString(args[0]))
+		start := math.Round(toNumber(args[1]))
+		end := math.Inf(1)
+		if len(args) > 2 {
+			end = start + math.Round(toNumber(args[2]))
+		}
+		var buf strings.Builder
+		for i, r := range runes {
+			if p := float64(i + 1); p >= start && p < end {
+				buf.WriteRune(r)
+			}
+		}
+		return buf.String(), nil
+	}},
+	"string-length": {0, 1, func(ctx evalCtx, args []any) (any, error) {
+		return float64(utf8.RuneCountInString(toString(argOrContext(ctx, args)))), nil
+	}},
+	"normalize-space": {0, 1, func(ctx evalCtx, args []any) (any, error) {
+		return strings.Join(strings.Fields(toString(argOrContext(ctx, args))), " "), nil
+	}},
+	"translate": {3, 3, func(_ evalCtx, args []any) (any, error) {
+		from, to := []rune(toString(args[1])), []rune(toString(args[2]))
+		return strings.Map(func(r rune) rune {
+			for i, f := range from {
+				if f == r {
+					if i < len(to) {
+						return to[i]
+					}
+					return -1
+				}
+			}
+			return r
+		}, toString(args[0])), nil
+	}},
+	"boolean": {1, 1, func(_ evalCtx, args []any) (any, error) {
+		return toBool(args[0]), nil
+	}},
+	"not": {1, 1, func(_ evalCtx, args []any) (any, error) {
+		return !toBool(args[0]), nil
+	}},
+	"true": {0, 0, func(evalCtx, []any) (any, error) {
+		return true, nil
+	}},
+	"false": {0, 0, func(evalCtx, []any) (any, error) {
+		return false, nil
+	}},
+	"number": {0, 1, func(ctx evalCtx, args []any) (any, error) {
+		return toNumber(argOrContext(ctx, args)), nil
+	}},
+	"floor": {1, 1, func(_ evalCtx, args []any) (any, error) {
+		return math.Floor(toNumber(args[0])), nil
+	}},
+	"ceiling": {1, 1, func(_ evalCtx, args []any) (any, error) {
+		return math.Ceil(toNumber(args[0])), nil
+	}},
+	"round": {1, 1, func(_ evalCtx, args []any) (any, error) {
+		return math.Floor(toNumber(args[0]) + 0.5), nil
+	}},
+}
+
+func argNodes(v any) ([]*Node, error) {
+	nodes, ok := v.([]*Node)
+	if !ok {
+		return nil, errors.New("expected a node-set argument")
+	}
+	return nodes, nil
+}
+
+func argOrContext(ctx evalCtx, args []any) any {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return []*Node{ctx.node}
+}
+
+func nameFunc(fn func(n *Node) string) func(ctx evalCtx, args []any) (any, error) {
+	return func(ctx evalCtx, args []any) (any, error) {
+		nodes, err := argNodes(argOrContext(ctx, args))
+		if err != nil {
+			return nil, err
+		}
+		if len(nodes) == 0 {
+			return "", nil
+		}
+		return fn(nodes[0]), nil
+	}
+}
+
+type funcExpr struct {
+	name string
+	args []expr
+}
+
+func (f *funcExpr) validate() error {
+	spec, exists := functions[f.name]
+	if !exists {
+		return fmt.Errorf("unsupported function '%v'", f.name)
+	}
+	if len(f.args) < spec.minArgs || (spec.maxArgs >= 0 && len(f.args) > spec.maxArgs) {
+		return fmt.Errorf("wrong number of arguments to function '%v'", f.name)
+	}
+	return nil
+}
+
+func (f *funcExpr) eval(ctx evalCtx) (any, error) {
+	args := make([]any, len(f.args))
+	for i, a := range f.args {
+		var err error
+		if args[i], err = a.eval(ctx); err != nil {
+			return nil, err
+		}
+	}
+	res, err := functions[f.name].fn(ctx, args)
+	if err != nil {
+		return nil, fmt.Errorf("function '%v': %w", f.name, err)
+	}
+	return res, nil
+}
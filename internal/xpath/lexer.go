@@ -0,0 +1,153 @@
+package xpath
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokName
+	tokNumber
+	tokLiteral
+	tokOperator
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+func isNameStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isNameChar(r rune) bool {
+	return isNameStart(r) || r == '-' || r == '.' || unicode.IsDigit(r)
+}
+
+// lex splits an expression into tokens, disambiguating names and `*` that are
+// operators from those that are name tests following the rules of the XPath
+// specification.
+func lex(expr string) ([]token, error) {
+	var toks []token
+	runes := []rune(expr)
+
+	// An operator is expected when the preceding token is one that can end an
+	// operand.
+	operatorExpected := func() bool {
+		if len(toks) == 0 {
+			return false
+		}
+		prev := toks[len(toks)-1]
+		switch prev.kind {
+		case tokOperator:
+			return false
+		case tokPunct:
+			switch prev.text {
+			case "@", "::", "(", "[", ",", "/", "//":
+				return false
+			}
+		}
+		return true
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("char %v: unterminated string literal", start)
+			}
+			toks = append(toks, token{kind: tokLiteral, text: string(runes[i+1 : end]), pos: start})
+			i = end + 1
+			continue
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			end := i
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			f, err := strconv.ParseFloat(string(runes[i:end]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("char %v: invalid number: %w", start, err)
+			}
+			toks = append(toks, token{kind: tokNumber, num: f, text: string(runes[i:end]), pos: start})
+			i = end
+			continue
+		case isNameStart(r):
+			end := i
+			for end < len(runes) && isNameChar(runes[end]) {
+				end++
+			}
+			// A qualified name or a prefixed wildcard, but not an axis.
+			if end+1 < len(runes) && runes[end] == ':' && runes[end+1] != ':' {
+				if runes[end+1] == '*' {
+					end += 2
+				} else if isNameStart(runes[end+1]) {
+					end++
+					for end < len(runes) && isNameChar(runes[end]) {
+						end++
+					}
+				}
+			}
+			name := string(runes[i:end])
+			kind := tokName
+			if operatorExpected() {
+				switch name {
+				case "and", "or", "div", "mod":
+					kind = tokOperator
+				}
+			}
+			toks = append(toks, token{kind: kind, text: name, pos: start})
+			i = end
+			continue
+		}
+
+		two := ""
+		if i+1 < len(runes) {
+			two = string(runes[i : i+2])
+		}
+		switch two {
+		case "//", "..", "::":
+			toks = append(toks, token{kind: tokPunct, text: two, pos: start})
+			i += 2
+			continue
+		case "!=", "<=", ">=":
+			toks = append(toks, token{kind: tokOperator, text: two, pos: start})
+			i += 2
+			continue
+		}
+
+		switch r {
+		case '*':
+			kind := tokPunct
+			if operatorExpected() {
+				kind = tokOperator
+			}
+			toks = append(toks, token{kind: kind, text: "*", pos: start})
+		case '/', '(', ')', '[', ']', '.', '@', ',':
+			toks = append(toks, token{kind: tokPunct, text: string(r), pos: start})
+		case '|', '+', '-', '=', '<', '>':
+			toks = append(toks, token{kind: tokOperator, text: string(r), pos: start})
+		default:
+			return nil, fmt.Errorf("char %v: unexpected character '%c'", start, r)
+		}
+		i++
+	}
+	toks = append(toks, token{kind: tokEOF, pos: len(runes)})
+	return toks, nil
+}
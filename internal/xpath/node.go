@@ -0,0 +1,245 @@
+package xpath
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NodeType describes the type of a node within a document.
+type NodeType int
+
+// Node types.
+const (
+	RootNode NodeType = iota
+	ElementNode
+	AttributeNode
+	TextNode
+)
+
+// Node is a node within an XML document tree that expressions are evaluated
+// against.
+type Node struct {
+	Type NodeType
+
+	// Space is the namespace URI of an element or attribute.
+	Space string
+
+	// Local is the local name of an element or attribute.
+	Local string
+
+	// Value is the value of an attribute or text node.
+	Value string
+
+	Parent     *Node
+	Children   []*Node
+	Attributes []*Node
+
+	order int
+	qname string
+}
+
+// StringValue returns the string value of the node as defined by XPath, which
+// for elements is the concatenation of all descendant text.
+func (n *Node) StringValue() string {
+	switch n.Type {
+	case AttributeNode, TextNode:
+		return n.Value
+	}
+	var buf strings.Builder
+	var walk func(c *Node)
+	walk = func(c *Node) {
+		for _, cc := range c.Children {
+			if cc.Type == TextNode {
+				buf.WriteString(cc.Value)
+			} else {
+				walk(cc)
+			}
+		}
+	}
+	walk(n)
+	return buf.String()
+}
+
+func (n *Node) appendChild(c *Node) {
+	c.Parent = n
+	n.Children = append(n.Children, c)
+}
+
+func (n *Node) appendAttr(a *Node) {
+	a.Parent = n
+	n.Attributes = append(n.Attributes, a)
+}
+
+// assignOrder numbers each node of a tree in document order, which is the
+// order that node-sets are returned in.
+func (n *Node) assignOrder() {
+	i := 0
+	var walk func(c *Node)
+	walk = func(c *Node) {
+		c.order = i
+		i++
+		for _, a := range c.Attributes {
+			a.order = i
+			i++
+		}
+		for _, cc := range c.Children {
+			walk(cc)
+		}
+	}
+	walk(n)
+}
+
+// ParseXML parses a raw XML document into a tree of nodes. Comments,
+// processing instructions and whitespace-only text are discarded.
+func ParseXML(b []byte) (*Node, error) {
+	root := &Node{Type: RootNode}
+	current := root
+
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			e := &Node{Type: ElementNode, Space: t.Name.Space, Local: t.Name.Local}
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns") {
+					continue
+				}
+				e.appendAttr(&Node{Type: AttributeNode, Space: a.Name.Space, Local: a.Name.Local, Value: a.Value})
+			}
+			current.appendChild(e)
+			current = e
+		case xml.EndElement:
+			if current.Parent != nil {
+				current = current.Parent
+			}
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+			if l := len(current.Children); l > 0 && current.Children[l-1].Type == TextNode {
+				current.Children[l-1].Value += string(t)
+				continue
+			}
+			current.appendChild(&Node{Type: TextNode, Value: string(t)})
+		}
+	}
+	if len(root.Children) == 0 {
+		return nil, errors.New("document contains no elements")
+	}
+
+	root.assignOrder()
+	return root, nil
+}
+
+// FromStructured converts a structured value, of the format produced by
+// parsing an XML document into an object where attributes are keys prefixed
+// with a hyphen and text content is the key `#text`, into a tree of nodes.
+// Namespace prefixes are resolved from any `-xmlns` attributes present.
+func FromStructured(v any) (*Node, error) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected an object, got %T", v)
+	}
+	root := &Node{Type: RootNode}
+	if err := structuredChildren(root, obj, map[string]string{}); err != nil {
+		return nil, err
+	}
+	root.assignOrder()
+	return root, nil
+}
+
+func structuredScalar(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []byte:
+		return string(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case nil:
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func splitStructuredName(name string, namespaces map[string]string) (space, local string) {
+	if prefix, l, ok := strings.Cut(name, ":"); ok {
+		return namespaces[prefix], l
+	}
+	return namespaces[""], name
+}
+
+func structuredChildren(parent *Node, obj map[string]any, namespaces map[string]string) error {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// Namespace declarations are scoped to the element they're declared on
+	// and must be resolved before any of its names.
+	scoped, copied := namespaces, false
+	for _, k := range keys {
+		if k == "-xmlns" || strings.HasPrefix(k, "-xmlns:") {
+			if !copied {
+				scoped, copied = make(map[string]string, len(namespaces)+1), true
+				for nk, nv := range namespaces {
+					scoped[nk] = nv
+				}
+			}
+			scoped[strings.TrimPrefix(strings.TrimPrefix(k, "-xmlns"), ":")] = structuredScalar(obj[k])
+		}
+	}
+	if parent.Type == ElementNode {
+		parent.Space, parent.Local = splitStructuredName(parent.qname, scoped)
+	}
+
+	for _, k := range keys {
+		v := obj[k]
+		switch {
+		case k == "-xmlns" || strings.HasPrefix(k, "-xmlns:"):
+		case strings.HasPrefix(k, "-"):
+			if parent.Type != ElementNode {
+				return fmt.Errorf("attribute %v cannot be set on the document root", k)
+			}
+			a := &Node{Type: AttributeNode, Local: k[1:], Value: structuredScalar(v)}
+			if prefix, l, ok := strings.Cut(a.Local, ":"); ok {
+				a.Space, a.Local = scoped[prefix], l
+			}
+			parent.appendAttr(a)
+		case k == "#text":
+			parent.appendChild(&Node{Type: TextNode, Value: structuredScalar(v)})
+		default:
+			values, isArray := v.([]any)
+			if !isArray {
+				values = []any{v}
+			}
+			for _, ev := range values {
+				e := &Node{Type: ElementNode, qname: k}
+				e.Space, e.Local = splitStructuredName(k, scoped)
+				parent.appendChild(e)
+				if eObj, isObj := ev.(map[string]any); isObj {
+					if err := structuredChildren(e, eObj, scoped); err != nil {
+						return err
+					}
+				} else if s := structuredScalar(ev); s != "" {
+					e.appendChild(&Node{Type: TextNode, Value: s})
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,369 @@
+package xpath
+
+import (
+	"fmt"
+)
+
+// Expression is a compiled XPath 1.0 expression.
+type Expression struct {
+	root expr
+}
+
+// Compile parses an XPath expression, where namespace prefixes used within
+// the expression are resolved from the provided map of prefixes to URIs.
+//
+// The supported syntax is the core of XPath 1.0, including all axes other
+// than namespace and preceding/following, predicates, operators and the
+// string, number, boolean and node-set functions of the specification.
+func Compile(expression string, namespaces map[string]string) (*Expression, error) {
+	toks, err := lex(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks, namespaces: namespaces}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.unexpected(t)
+	}
+	return &Expression{root: e}, nil
+}
+
+type parser struct {
+	toks       []token
+	i          int
+	namespaces map[string]string
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.i]
+}
+
+func (p *parser) peekN(n int) token {
+	if p.i+n >= len(p.toks) {
+		return p.toks[len(p.toks)-1]
+	}
+	return p.toks[p.i+n]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) is(kind tokenKind, text string) bool {
+	t := p.peek()
+	return t.kind == kind && t.text == text
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	if !p.is(kind, text) {
+		return p.unexpected(p.peek())
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) unexpected(t token) error {
+	if t.kind == tokEOF {
+		return fmt.Errorf("char %v: unexpected end of expression", t.pos)
+	}
+	return fmt.Errorf("char %v: unexpected token '%v'", t.pos, t.text)
+}
+
+func (p *parser) parseBinary(next func() (expr, error), ops ...string) (expr, error) {
+	l, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		matched := false
+		if t.kind == tokOperator {
+			for _, op := range ops {
+				if t.text == op {
+					matched = true
+					break
+				}
+			}
+		}
+		if !matched {
+			return l, nil
+		}
+		p.next()
+		r, err := next()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryExpr{op: t.text, l: l, r: r}
+	}
+}
+
+func (p *parser) parseOr() (expr, error) {
+	return p.parseBinary(p.parseAnd, "or")
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	return p.parseBinary(p.parseEquality, "and")
+}
+
+func (p *parser) parseEquality() (expr, error) {
+	return p.parseBinary(p.parseRelational, "=", "!=")
+}
+
+func (p *parser) parseRelational() (expr, error) {
+	return p.parseBinary(p.parseAdditive, "<", "<=", ">", ">=")
+}
+
+func (p *parser) parseAdditive() (expr, error) {
+	return p.parseBinary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *parser) parseMultiplicative() (expr, error) {
+	return p.parseBinary(p.parseUnary, "*", "div", "mod")
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if p.is(tokOperator, "-") {
+		p.next()
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &negExpr{e: e}, nil
+	}
+	return p.parseBinary(p.parsePath, "|")
+}
+
+func (p *parser) isStepStart() bool {
+	t := p.peek()
+	switch t.kind {
+	case tokName:
+		// Function calls are primary expressions unless they're node type
+		// tests.
+		if p.peekN(1).kind == tokPunct && p.peekN(1).text == "(" {
+			switch t.text {
+			case "text", "node":
+				return true
+			}
+			return false
+		}
+		return true
+	case tokPunct:
+		switch t.text {
+		case ".", "..", "@", "*":
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parsePath() (expr, error) {
+	if p.is(tokPunct, "/") || p.is(tokPunct, "//") {
+		path := &pathExpr{absolute: true}
+		if p.next().text == "//" {
+			path.steps = append(path.steps, descendantOrSelfStep())
+		} else if !p.isStepStart() {
+			return path, nil
+		}
+		if err := p.parseRelativeSteps(path); err != nil {
+			return nil, err
+		}
+		return path, nil
+	}
+
+	if p.isStepStart() {
+		path := &pathExpr{}
+		if err := p.parseRelativeSteps(path); err != nil {
+			return nil, err
+		}
+		return path, nil
+	}
+
+	e, err := p.parseFilter()
+	if err != nil {
+		return nil, err
+	}
+	if !p.is(tokPunct, "/") && !p.is(tokPunct, "//") {
+		return e, nil
+	}
+	path := &pathExpr{filter: e}
+	if p.next().text == "//" {
+		path.steps = append(path.steps, descendantOrSelfStep())
+	}
+	if err := p.parseRelativeSteps(path); err != nil {
+		return nil, err
+	}
+	return path, nil
+}
+
+func descendantOrSelfStep() step {
+	return step{axis: axisDescendantOrSelf, test: nodeTest{kind: testNode}}
+}
+
+func (p *parser) parseRelativeSteps(path *pathExpr) error {
+	for {
+		s, err := p.parseStep()
+		if err != nil {
+			return err
+		}
+		path.steps = append(path.steps, s)
+
+		if p.is(tokPunct, "/") {
+			p.next()
+		} else if p.is(tokPunct, "//") {
+			p.next()
+			path.steps = append(path.steps, descendantOrSelfStep())
+		} else {
+			return nil
+		}
+	}
+}
+
+func (p *parser) parseStep() (step, error) {
+	if p.is(tokPunct, ".") {
+		p.next()
+		return step{axis: axisSelf, test: nodeTest{kind: testNode}}, nil
+	}
+	if p.is(tokPunct, "..") {
+		p.next()
+		return step{axis: axisParent, test: nodeTest{kind: testNode}}, nil
+	}
+
+	s := step{axis: axisChild}
+	if p.is(tokPunct, "@") {
+		p.next()
+		s.axis = axisAttribute
+	} else if t := p.peek(); t.kind == tokName && p.peekN(1).kind == tokPunct && p.peekN(1).text == "::" {
+		if !validAxis(t.text) {
+			return s, fmt.Errorf("char %v: unsupported axis '%v'", t.pos, t.text)
+		}
+		s.axis = t.text
+		p.next()
+		p.next()
+	}
+
+	var err error
+	if s.test, err = p.parseNodeTest(); err != nil {
+		return s, err
+	}
+
+	for p.is(tokPunct, "[") {
+		p.next()
+		pred, err := p.parseOr()
+		if err != nil {
+			return s, err
+		}
+		if err := p.expect(tokPunct, "]"); err != nil {
+			return s, err
+		}
+		s.preds = append(s.preds, pred)
+	}
+	return s, nil
+}
+
+func (p *parser) parseNodeTest() (nodeTest, error) {
+	t := p.next()
+	if t.kind == tokPunct && t.text == "*" {
+		return nodeTest{kind: testWildcard}, nil
+	}
+	if t.kind != tokName {
+		return nodeTest{}, p.unexpected(t)
+	}
+
+	if p.is(tokPunct, "(") {
+		p.next()
+		if err := p.expect(tokPunct, ")"); err != nil {
+			return nodeTest{}, err
+		}
+		if t.text == "text" {
+			return nodeTest{kind: testText}, nil
+		}
+		return nodeTest{kind: testNode}, nil
+	}
+
+	prefix, local, hasPrefix := cutQName(t.text)
+	if !hasPrefix {
+		return nodeTest{kind: testName, local: local}, nil
+	}
+	space, exists := p.namespaces[prefix]
+	if !exists {
+		return nodeTest{}, fmt.Errorf("char %v: namespace prefix '%v' has not been declared", t.pos, prefix)
+	}
+	if local == "*" {
+		return nodeTest{kind: testNamespaceWildcard, space: space}, nil
+	}
+	return nodeTest{kind: testName, space: space, local: local}, nil
+}
+
+func (p *parser) parseFilter() (expr, error) {
+	e, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	var preds []expr
+	for p.is(tokPunct, "[") {
+		p.next()
+		pred, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokPunct, "]"); err != nil {
+			return nil, err
+		}
+		preds = append(preds, pred)
+	}
+	if len(preds) == 0 {
+		return e, nil
+	}
+	return &filterExpr{primary: e, preds: preds}, nil
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLiteral:
+		return &literalExpr{v: t.text}, nil
+	case tokNumber:
+		return &literalExpr{v: t.num}, nil
+	case tokPunct:
+		if t.text == "(" {
+			e, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(tokPunct, ")"); err != nil {
+				return nil, err
+			}
+			return e, nil
+		}
+	case tokName:
+		if p.is(tokPunct, "(") {
+			p.next()
+			f := &funcExpr{name: t.text}
+			for !p.is(tokPunct, ")") {
+				if len(f.args) > 0 {
+					if err := p.expect(tokPunct, ","); err != nil {
+						return nil, err
+					}
+				}
+				arg, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				f.args = append(f.args, arg)
+			}
+			p.next()
+			if err := f.validate(); err != nil {
+				return nil, fmt.Errorf("char %v: %w", t.pos, err)
+			}
+			return f, nil
+		}
+	}
+	return nil, p.unexpected(t)
+}
@@ -0,0 +1,181 @@
+package xpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDoc = `<?xml version="1.0"?>
+<library xmlns:b="http://example.com/books">
+  <b:book id="1" lang="en">
+    <title>Dune</title>
+    <price>9.99</price>
+    <tags><tag>scifi</tag><tag>classic</tag></tags>
+  </b:book>
+  <b:book id="2" lang="fr">
+    <title>Vingt mille lieues sous les mers</title>
+    <price>12.50</price>
+    <tags><tag>adventure</tag></tags>
+  </b:book>
+  <magazine id="3"><title>Wired</title></magazine>
+</library>`
+
+func nodeStrings(t testing.TB, v any) []string {
+	t.Helper()
+	nodes, ok := v.([]*Node)
+	require.True(t, ok, "expected node-set, got %T", v)
+	strs := []string{}
+	for _, n := range nodes {
+		strs = append(strs, n.StringValue())
+	}
+	return strs
+}
+
+func TestXPathNodeSets(t *testing.T) {
+	doc, err := ParseXML([]byte(testDoc))
+	require.NoError(t, err)
+
+	namespaces := map[string]string{"bk": "http://example.com/books"}
+
+	tests := map[string][]string{
+		`/library/bk:book/title`:                       {"Dune", "Vingt mille lieues sous les mers"},
+		`//title`:                                      {"Dune", "Vingt mille lieues sous les mers", "Wired"},
+		`//bk:book[@lang='fr']/title`:                  {"Vingt mille lieues sous les mers"},
+		`//bk:book[price > 10]/@id`:                    {"2"},
+		`//bk:book[1]/tags/tag[last()]`:                {"classic"},
+		`//tag[. = 'adventure']/ancestor::bk:book/@id`: {"2"},
+		`//bk:*/@id | //magazine/@id`:                  {"1", "2", "3"},
+		`/library/*[not(self::bk:book)]/title/text()`:  {"Wired"},
+		`//title[starts-with(., 'Du')]/../price`:       {"9.99"},
+		`//bk:book[position() = 2]/tags/tag`:           {"adventure"},
+		`(//tag)[2]`:                                   {"classic"},
+		`//bk:book[1]/following-sibling::*/@id`:        {"2", "3"},
+		`//magazine/preceding-sibling::bk:book[1]/@id`: {"2"},
+		`//nope`: {},
+		`//bk:book[tags/tag = 'classic' and @lang]/@id`: {"1"},
+	}
+
+	for expr, exp := range tests {
+		expr, exp := expr, exp
+		t.Run(expr, func(t *testing.T) {
+			e, err := Compile(expr, namespaces)
+			require.NoError(t, err)
+
+			res, err := e.Evaluate(doc)
+			require.NoError(t, err)
+			assert.Equal(t, exp, nodeStrings(t, res))
+		})
+	}
+}
+
+func TestXPathScalars(t *testing.T) {
+	doc, err := ParseXML([]byte(testDoc))
+	require.NoError(t, err)
+
+	namespaces := map[string]string{"bk": "http://example.com/books"}
+
+	tests := map[string]any{
+		`count(//bk:book)`:                         float64(2),
+		`sum(//price)`:                             22.49,
+		`string(//bk:book[2]/@lang)`:               "fr",
+		`concat(//title, ' - ', //price)`:          "Dune - 9.99",
+		`//price > 10`:                             true,
+		`count(//tag) * 2 div 3 mod 5`:             float64(2),
+		`-count(//tag) + 1`:                        float64(-2),
+		`normalize-space('  a   b ')`:              "a b",
+		`substring('12345', 2, 3)`:                 "234",
+		`translate('abc', 'ab', 'A')`:              "Ac",
+		`local-name(//bk:book)`:                    "book",
+		`namespace-uri(//bk:book)`:                 "http://example.com/books",
+		`boolean(//magazine)`:                      true,
+		`substring-after('foo=bar', '=')`:          "bar",
+		`round(2.5) + floor(1.9) + ceiling(1.1)`:   float64(6),
+		`string-length(//magazine/title)`:          float64(5),
+		`contains(//bk:book[1]/title, 'un') = 1`:   true,
+		`number(//bk:book[1]/price) = 9.99`:        true,
+		`not(//bk:book[@id = '3'])`:                true,
+		`//bk:book/@id = //magazine/@id`:           false,
+		`//bk:book[@id = 1]/title != 'Dune'`:       false,
+		`true() or false()`:                        true,
+		`//bk:book[last()]/@id = 2 and false()`:    false,
+		`count(/)`:                                 float64(1),
+		`count(//bk:book[1]/descendant::*)`:        float64(5),
+		`count(//tag/ancestor-or-self::*)`:         float64(8),
+		`name(//bk:book[1]/tags/tag[1]/parent::*)`: "tags",
+	}
+
+	for expr, exp := range tests {
+		expr, exp := expr, exp
+		t.Run(expr, func(t *testing.T) {
+			e, err := Compile(expr, namespaces)
+			require.NoError(t, err)
+
+			res, err := e.Evaluate(doc)
+			require.NoError(t, err)
+			if f, ok := exp.(float64); ok {
+				assert.InDelta(t, f, res, 0.0001)
+			} else {
+				assert.Equal(t, exp, res)
+			}
+		})
+	}
+}
+
+func TestXPathCompileErrors(t *testing.T) {
+	tests := map[string]string{
+		`//foo[`:         "char 6: unexpected end of expression",
+		`//x:foo`:        "char 2: namespace prefix 'x' has not been declared",
+		`nope()`:         "char 0: unsupported function 'nope'",
+		`count()`:        "char 0: wrong number of arguments to function 'count'",
+		`//foo/'bar`:     "char 6: unterminated string literal",
+		`following::foo`: "char 0: unsupported axis 'following'",
+		`//foo ]`:        "char 6: unexpected token ']'",
+	}
+
+	for expr, exp := range tests {
+		_, err := Compile(expr, nil)
+		require.Error(t, err, expr)
+		assert.EqualError(t, err, exp, expr)
+	}
+}
+
+func TestXPathStructured(t *testing.T) {
+	doc, err := FromStructured(map[string]any{
+		"root": map[string]any{
+			"-xmlns:b": "http://example.com/books",
+			"b:book": []any{
+				map[string]any{"-id": "1", "title": "Dune"},
+				map[string]any{"-id": "2", "title": "Emma", "#text": "extra"},
+			},
+			"count": float64(2),
+		},
+	})
+	require.NoError(t, err)
+
+	e, err := Compile(`//bk:book[@id = '2']/title`, map[string]string{"bk": "http://example.com/books"})
+	require.NoError(t, err)
+
+	res, err := e.Evaluate(doc)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Emma"}, nodeStrings(t, res))
+
+	e, err = Compile(`/root/count + 1`, nil)
+	require.NoError(t, err)
+
+	res, err = e.Evaluate(doc)
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), res)
+
+	_, err = FromStructured("nope")
+	require.Error(t, err)
+}
+
+func TestXPathParseErrors(t *testing.T) {
+	_, err := ParseXML([]byte(`<foo><bar></foo>`))
+	require.Error(t, err)
+
+	_, err = ParseXML([]byte(`   `))
+	require.Error(t, err)
+}
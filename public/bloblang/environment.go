@@ -146,7 +146,7 @@ func methodSpecFromPublic(name string, spec *PluginSpec) query.MethodSpec {
 // (snake case).
 func (e *Environment) RegisterMethodV2(name string, spec *PluginSpec, ctor MethodConstructorV2) error {
 	return e.env.RegisterMethod(methodSpecFromPublic(name, spec), func(target query.Function, args *query.ParsedParams) (query.Function, error) {
-		parsedParams := newParsedParams(args, e)
+		parsedParams := newParsedParams(args, e).withState("method "+name, spec)
 
 		fn, err := ctor(parsedParams)
 		if err != nil {
@@ -181,7 +181,7 @@ func (e *Environment) RegisterMethodV2(name string, spec *PluginSpec, ctor Metho
 // (snake case).
 func (e *Environment) RegisterAdvancedMethod(name string, spec *PluginSpec, ctor AdvancedMethodConstructor) error {
 	return e.env.RegisterMethod(methodSpecFromPublic(name, spec), func(target query.Function, args *query.ParsedParams) (query.Function, error) {
-		parsedParams := newParsedParams(args, e)
+		parsedParams := newParsedParams(args, e).withState("method "+name, spec)
 
 		fn, err := ctor(parsedParams)
 		if err != nil {
@@ -250,7 +250,7 @@ func functionSpecFromPublic(name string, spec *PluginSpec) query.FunctionSpec {
 // (snake case).
 func (e *Environment) RegisterFunctionV2(name string, spec *PluginSpec, ctor FunctionConstructorV2) error {
 	return e.env.RegisterFunction(functionSpecFromPublic(name, spec), func(args *query.ParsedParams) (query.Function, error) {
-		parsedParams := newParsedParams(args, e)
+		parsedParams := newParsedParams(args, e).withState("function "+name, spec)
 
 		fn, err := ctor(parsedParams)
 		if err != nil {
@@ -279,7 +279,7 @@ func (e *Environment) RegisterFunctionV2(name string, spec *PluginSpec, ctor Fun
 // (snake case).
 func (e *Environment) RegisterAdvancedFunction(name string, spec *PluginSpec, ctor AdvancedFunctionConstructor) error {
 	return e.env.RegisterFunction(functionSpecFromPublic(name, spec), func(args *query.ParsedParams) (query.Function, error) {
-		parsedParams := newParsedParams(args, e)
+		parsedParams := newParsedParams(args, e).withState("function "+name, spec)
 
 		fn, err := ctor(parsedParams)
 		if err != nil {
@@ -1,6 +1,8 @@
 package bloblang
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -79,6 +81,93 @@ func TestEnvironmentV2(t *testing.T) {
 	assert.Equal(t, "bar", v)
 }
 
+func TestEnvironmentPluginState(t *testing.T) {
+	env := NewEnvironment()
+
+	var inits int
+	var closed []any
+	spec := NewPluginSpec().
+		Param(NewStringParam("prefix")).
+		State(func() (any, error) {
+			inits++
+			return map[string]int{}, nil
+		}, func(_ context.Context, state any) error {
+			closed = append(closed, state)
+			return nil
+		})
+
+	require.NoError(t, env.RegisterMethodV2("counted", spec, func(args *ParsedParams) (Method, error) {
+		prefix, err := args.GetString("prefix")
+		if err != nil {
+			return nil, err
+		}
+		state, err := args.State()
+		if err != nil {
+			return nil, err
+		}
+		counts := state.(map[string]int)
+		return StringMethod(func(s string) (any, error) {
+			counts[prefix]++
+			return prefix + s, nil
+		}), nil
+	}))
+
+	exe, err := env.Parse(`
+root.a = this.a.counted("a")
+root.b = this.b.counted(this.prefix)
+root.c = this.a.counted("a")
+`)
+	require.NoError(t, err)
+	assert.Equal(t, 1, inits)
+
+	v, err := exe.Query(map[string]any{"a": "x", "b": "y", "prefix": "b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": "ax", "b": "by", "c": "ax"}, v)
+	assert.Equal(t, 1, inits)
+
+	require.NoError(t, exe.Close(context.Background()))
+	assert.Equal(t, []any{map[string]int{"a": 2, "b": 1}}, closed)
+
+	// Subsequent closes are no-ops.
+	require.NoError(t, exe.Close(context.Background()))
+	assert.Len(t, closed, 1)
+
+	// Each mapping has its own state.
+	exe, err = env.Parse(`root = this.counted("a")`)
+	require.NoError(t, err)
+	assert.Equal(t, 2, inits)
+	require.NoError(t, exe.Close(context.Background()))
+	assert.Len(t, closed, 2)
+}
+
+func TestEnvironmentPluginStateErrors(t *testing.T) {
+	env := NewEnvironment()
+
+	require.NoError(t, env.RegisterFunctionV2("stateless", NewPluginSpec(), func(args *ParsedParams) (Function, error) {
+		if _, err := args.State(); err != nil {
+			return nil, err
+		}
+		return func() (any, error) { return nil, nil }, nil
+	}))
+
+	require.NoError(t, env.RegisterFunctionV2("broken", NewPluginSpec().State(func() (any, error) {
+		return nil, errors.New("nope")
+	}, nil), func(args *ParsedParams) (Function, error) {
+		if _, err := args.State(); err != nil {
+			return nil, err
+		}
+		return func() (any, error) { return nil, nil }, nil
+	}))
+
+	_, err := env.Parse(`root = stateless()`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the plugin does not declare any state")
+
+	_, err = env.Parse(`root = broken()`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nope")
+}
+
 func TestEmptyEnvironment(t *testing.T) {
 	env := NewEmptyEnvironment()
 
@@ -1,6 +1,7 @@
 package bloblang
 
 import (
+	"context"
 	"errors"
 
 	"github.com/redpanda-data/benthos/v4/internal/bloblang/mapping"
@@ -22,6 +23,13 @@ func newExecutor(exec *mapping.Executor) *Executor {
 	}
 }
 
+// Close cleans up any state initialised by plugins used within the mapping, see
+// PluginSpec.State for more information. The executor should not be used after
+// it has been closed.
+func (e *Executor) Close(ctx context.Context) error {
+	return e.exec.Close(ctx)
+}
+
 // ErrRootDeleted is returned by a Bloblang query when the mapping results in
 // the root being deleted. It might be considered correct to do this in
 // situations where filtering is allowed or expected.
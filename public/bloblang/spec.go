@@ -1,7 +1,9 @@
 package bloblang
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
@@ -119,6 +121,8 @@ type PluginSpec struct {
 	params      query.Params
 	examples    []pluginExample
 	version     string
+	stateInit   func() (any, error)
+	stateClose  func(ctx context.Context, state any) error
 }
 
 type pluginExample struct {
@@ -267,6 +271,45 @@ func (p *PluginSpec) StaticWithFunc(fn func(params *ParsedParams) bool) *PluginS
 	return p
 }
 
+// PersistentState is an optional interface implemented by plugin states
+// declared with PluginSpec.State that can be stored and later restored.
+type PersistentState interface {
+	MarshalState() ([]byte, error)
+	UnmarshalState(b []byte) error
+}
+
+// State declares that the plugin has state that is shared by all of its
+// instantiations within a mapping, such as a cache of compiled expressions or a
+// lookup table, which avoids the need for package level globals.
+//
+// The state is created by calling init the first time it is requested via
+// ParsedParams.State within a mapping, and close is called with the state when
+// the Executor of that mapping is closed. The close function is optional and
+// can be nil.
+//
+// Closing states is best-effort, as not every owner of a mapping closes it.
+// The bloblang, mapping, mutation and branch processors and the generate input
+// close their mappings when they shut down, but mappings of other components
+// and those parsed by plugins that do not call Executor.Close are never
+// closed, and therefore close must not be relied upon for correctness.
+//
+// States are scoped to a mapping rather than a stream, as a mapping can be
+// parsed independently of any stream, and therefore two mappings of the same
+// stream that use the same plugin each have their own state.
+//
+// When a plugin is instantiated outside of a mapping, such as within an
+// interpolation function, the state is initialised for each instantiation and
+// is never closed.
+//
+// States that implement PersistentState can be persisted by components that
+// support it, such as the generate input, in which case the state is restored
+// after a restart.
+func (p *PluginSpec) State(init func() (any, error), close func(ctx context.Context, state any) error) *PluginSpec {
+	p.stateInit = init
+	p.stateClose = close
+	return p
+}
+
 // EncodeJSON attempts to parse a JSON object as a byte slice and uses it to
 // populate the configuration spec. The schema of this method is undocumented
 // and is not intended for general use.
@@ -293,6 +336,9 @@ func (p *PluginSpec) EncodeJSON(v []byte) error {
 type ParsedParams struct {
 	par *query.ParsedParams
 	e   *Environment
+
+	stateKey string
+	spec     *PluginSpec
 }
 
 func newParsedParams(p *query.ParsedParams, e *Environment) *ParsedParams {
@@ -302,6 +348,35 @@ func newParsedParams(p *query.ParsedParams, e *Environment) *ParsedParams {
 	}
 }
 
+func (p *ParsedParams) withState(key string, spec *PluginSpec) *ParsedParams {
+	p.stateKey = key
+	p.spec = spec
+	return p
+}
+
+// State returns the state of the plugin declared with PluginSpec.State, which
+// is initialised if this is the first time it has been requested within the
+// mapping being parsed or executed. An error is returned if the plugin has not
+// declared any state.
+func (p *ParsedParams) State() (any, error) {
+	if p.spec == nil || p.spec.stateInit == nil {
+		return nil, errors.New("the plugin does not declare any state")
+	}
+	spec := p.spec
+	return p.par.PluginStates().GetOrInit(p.stateKey, func() (any, query.PluginStateCloser, error) {
+		v, err := spec.stateInit()
+		if err != nil {
+			return nil, nil, err
+		}
+		if spec.stateClose == nil {
+			return v, nil, nil
+		}
+		return v, func(ctx context.Context) error {
+			return spec.stateClose(ctx, v)
+		}, nil
+	})
+}
+
 // AsSlice returns a slice of raw argument values.
 func (p *ParsedParams) AsSlice() []any {
 	return p.par.Raw()
@@ -46,10 +46,15 @@ type CacheItem struct {
 	TTL   *time.Duration
 }
 
-// batchedCache represents a cache where the underlying implementation is able
-// to benefit from batched set requests. This interface is optional for caches
-// and when implemented will automatically be utilised where possible.
-type batchedCache interface {
+// BatchedCache represents a cache where the underlying implementation is able
+// to benefit from batched set requests, such as caches that support pipelining.
+// This interface is optional for caches and when implemented will
+// automatically be utilised where possible, such as when the cache output
+// writes a batch of messages.
+//
+// Caches obtained via Resources.AccessCache also implement this interface,
+// falling back to individual set requests when the underlying cache does not.
+type BatchedCache interface {
 	// SetMulti attempts to set multiple cache items in as few requests as
 	// possible.
 	SetMulti(ctx context.Context, keyValues ...CacheItem) error
@@ -60,12 +65,12 @@ type batchedCache interface {
 // Implements types.Cache.
 type airGapCache struct {
 	c  Cache
-	cm batchedCache
+	cm BatchedCache
 }
 
 func newAirGapCache(c Cache, stats metrics.Type) cache.V1 {
 	ag := &airGapCache{c: c, cm: nil}
-	ag.cm, _ = c.(batchedCache)
+	ag.cm, _ = c.(BatchedCache)
 	return cache.MetricsForCache(ag, stats)
 }
 
@@ -140,6 +145,17 @@ func (r *reverseAirGapCache) Set(ctx context.Context, key string, value []byte,
 	return r.c.Set(ctx, key, value, ttl)
 }
 
+func (r *reverseAirGapCache) SetMulti(ctx context.Context, keyValues ...CacheItem) error {
+	items := make(map[string]cache.TTLItem, len(keyValues))
+	for _, kv := range keyValues {
+		items[kv.Key] = cache.TTLItem{
+			Value: kv.Value,
+			TTL:   kv.TTL,
+		}
+	}
+	return r.c.SetMulti(ctx, items)
+}
+
 func (r *reverseAirGapCache) Add(ctx context.Context, key string, value []byte, ttl *time.Duration) (err error) {
 	if err = r.c.Add(ctx, key, value, ttl); errors.Is(err, component.ErrKeyAlreadyExists) {
 		err = ErrKeyAlreadyExists
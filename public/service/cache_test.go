@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"errors"
 	"testing"
 	"time"
 
@@ -327,7 +326,15 @@ func (c *closableCacheType) Set(ctx context.Context, key string, value []byte, t
 }
 
 func (c *closableCacheType) SetMulti(ctx context.Context, items map[string]cache.TTLItem) error {
-	return errors.New("not implemented")
+	if c.err != nil {
+		return c.err
+	}
+	for k, v := range items {
+		c.m[k] = testCacheItem{
+			b: v.Value, ttl: v.TTL,
+		}
+	}
+	return nil
 }
 
 func (c *closableCacheType) Add(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
@@ -409,6 +416,30 @@ func TestCacheReverseAirGapSet(t *testing.T) {
 	}, rl.m)
 }
 
+func TestCacheReverseAirGapSetMulti(t *testing.T) {
+	rl := &closableCacheType{
+		m: map[string]testCacheItem{},
+	}
+	var agrl BatchedCache = newReverseAirGapCache(rl)
+
+	ttl := time.Second
+	err := agrl.SetMulti(context.Background(),
+		CacheItem{Key: "foo", Value: []byte("bar")},
+		CacheItem{Key: "baz", Value: []byte("buz"), TTL: &ttl},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]testCacheItem{
+		"foo": {
+			b:   []byte("bar"),
+			ttl: nil,
+		},
+		"baz": {
+			b:   []byte("buz"),
+			ttl: &ttl,
+		},
+	}, rl.m)
+}
+
 func TestCacheReverseAirGapSetWithTTL(t *testing.T) {
 	rl := &closableCacheType{
 		m: map[string]testCacheItem{},
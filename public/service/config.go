@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/redpanda-data/benthos/v4/internal/bundle"
@@ -15,6 +16,13 @@ import (
 // to a ConfigSpec.
 type ConfigField struct {
 	field docs.FieldSpec
+
+	lintRule         string
+	lintMin, lintMax any
+
+	// lintBase holds the linter of the field before any linting rules were
+	// added, which is typically installed by the constructor of the field.
+	lintBase *docs.FieldSpec
 }
 
 // NewAnyField describes a new config field that can assume any value type
@@ -231,6 +239,19 @@ func (c *ConfigField) Default(v any) *ConfigField {
 	return c
 }
 
+// DefaultFromEnv specifies an environment variable that provides the default
+// value of this field when it is omitted from a provided config. The variable
+// is read at parse time and its contents are parsed according to the type of
+// the field. If the variable is not set then the value specified with Default
+// applies, and in the absence of both the field is considered mandatory.
+//
+// When a config is echoed back with the `echo` subcommand, fields resolved in
+// this way are annotated with the name of the variable they were sourced from.
+func (c *ConfigField) DefaultFromEnv(name string) *ConfigField {
+	c.field = c.field.HasDefaultFromEnv(name)
+	return c
+}
+
 // Optional specifies that a field is optional even when a default value has not
 // been specified. When a field is marked as optional you can test its presence
 // within a parsed config with the method Contains.
@@ -261,6 +282,31 @@ func (c *ConfigField) Examples(e ...any) *ConfigField {
 	return c
 }
 
+// RenamedFrom specifies a former name of the field, which is still accepted
+// when parsing configs. Configs that use the former name are migrated to the
+// current name when echoed or fixed with `lint --fix`, and are flagged when
+// linting with deprecated fields rejected.
+func (c *ConfigField) RenamedFrom(name string) *ConfigField {
+	c.field = c.field.RenamedFrom(name)
+	return c
+}
+
+// Requires specifies a list of sibling fields that must also be set within a
+// config when this field is set, which is enforced when linting. This is
+// useful for fields that are only optional in the absence of this field.
+func (c *ConfigField) Requires(names ...string) *ConfigField {
+	c.field = c.field.Requires(names...)
+	return c
+}
+
+// ConflictsWith specifies a list of sibling fields that are mutually exclusive
+// with this field, and therefore must not be set within a config when this
+// field is set, which is enforced when linting.
+func (c *ConfigField) ConflictsWith(names ...string) *ConfigField {
+	c.field = c.field.ConflictsWith(names...)
+	return c
+}
+
 // Version specifies the specific version at which this field was added to the
 // component.
 func (c *ConfigField) Version(v string) *ConfigField {
@@ -279,11 +325,160 @@ func (c *ConfigField) Version(v string) *ConfigField {
 // linting rule:
 //
 // `root = if this.lowercase() != this { [ "field must be lowercase" ] }`.
+//
+// When added to an object field the mapping is provided the entire object,
+// including the default values of any omitted child fields, and can therefore
+// be used to express rules that span multiple child fields:
+//
+// `root = if this.min > this.max { [ "min must not exceed max" ] }`.
 func (c *ConfigField) LintRule(blobl string) *ConfigField {
-	c.field = c.field.LinterBlobl(blobl)
+	c.lintRule = blobl
+	c.applyLintRules()
+	return c
+}
+
+// LintMin adds a linting rule to the field that rejects values below a minimum.
+// For fields defined with NewIntField or NewFloatField the minimum should be
+// an integer or float, and for fields defined with NewDurationField it should
+// be a time.Duration.
+//
+// This is applied in addition to any linting rule added with LintRule, and can
+// be combined with LintMax in order to express a range.
+//
+// This method panics if the minimum is not an integer, float or
+// time.Duration, or if it is a duration and a maximum that is not has been
+// set, or vice versa.
+func (c *ConfigField) LintMin(v any) *ConfigField {
+	c.lintMin = v
+	checkLintBounds(c.lintMin, c.lintMax)
+	c.applyLintRules()
+	return c
+}
+
+// LintMax adds a linting rule to the field that rejects values above a maximum.
+// For fields defined with NewIntField or NewFloatField the maximum should be
+// an integer or float, and for fields defined with NewDurationField it should
+// be a time.Duration.
+//
+// This is applied in addition to any linting rule added with LintRule, and can
+// be combined with LintMin in order to express a range.
+//
+// This method panics if the maximum is not an integer, float or
+// time.Duration, or if it is a duration and a minimum that is not has been
+// set, or vice versa.
+func (c *ConfigField) LintMax(v any) *ConfigField {
+	c.lintMax = v
+	checkLintBounds(c.lintMin, c.lintMax)
+	c.applyLintRules()
 	return c
 }
 
+type lintBound struct {
+	isDuration   bool
+	value, label string
+}
+
+// lintBoundFrom converts a bound added with LintMin or LintMax into the value
+// it is compared against, panicking if the type of the bound is not supported.
+func lintBoundFrom(v any) lintBound {
+	var b lintBound
+	switch t := v.(type) {
+	case time.Duration:
+		b.isDuration = true
+		b.value, b.label = strconv.FormatInt(int64(t), 10), t.String()
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		b.value = fmt.Sprintf("%d", t)
+	case float32:
+		b.value = strconv.FormatFloat(float64(t), 'f', -1, 32)
+	case float64:
+		b.value = strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		panic(fmt.Sprintf("unsupported lint bound %v of type %T, expected an integer, float or time.Duration", v, v))
+	}
+	if b.label == "" {
+		b.label = b.value
+	}
+	return b
+}
+
+// checkLintBounds panics if either bound is of an unsupported type, or if one
+// bound is a duration and the other is not.
+func checkLintBounds(minV, maxV any) {
+	var isDuration []bool
+	for _, v := range []any{minV, maxV} {
+		if v != nil {
+			isDuration = append(isDuration, lintBoundFrom(v).isDuration)
+		}
+	}
+	if len(isDuration) == 2 && isDuration[0] != isDuration[1] {
+		panic(fmt.Sprintf("mismatched lint bounds %v (%T) and %v (%T), both must either be durations or numbers", minV, minV, maxV, maxV))
+	}
+}
+
+// applyLintRules sets the linter of the field to the linter installed by its
+// constructor, if any, combined with the rule added with LintRule and the
+// bounds added with LintMin and LintMax, regardless of the order in which they
+// were added.
+func (c *ConfigField) applyLintRules() {
+	if c.lintBase == nil {
+		base := c.field
+		c.lintBase = &base
+	}
+	c.field = c.field.LinterFrom(*c.lintBase)
+	if c.lintRule != "" {
+		c.field = c.field.LinterBloblAppend(c.lintRule)
+	}
+	if c.lintMin != nil || c.lintMax != nil {
+		c.field = c.field.LinterBloblAppend(boundsLintRule(c.lintMin, c.lintMax))
+	}
+}
+
+// boundsLintRule returns a bloblang linting rule that checks a numerical or
+// duration value against optional minimum and maximum bounds.
+func boundsLintRule(minV, maxV any) string {
+	type bound struct {
+		lintBound
+		cmp, desc string
+	}
+
+	var isDuration bool
+	var bounds []bound
+	for _, b := range []bound{
+		{cmp: "<", desc: "less than the minimum"},
+		{cmp: ">", desc: "greater than the maximum"},
+	} {
+		v := minV
+		if b.cmp == ">" {
+			v = maxV
+		}
+		if v == nil {
+			continue
+		}
+		b.lintBound = lintBoundFrom(v)
+		isDuration = b.isDuration
+		bounds = append(bounds, b)
+	}
+	if len(bounds) == 0 {
+		return ""
+	}
+
+	subject, guard := "this", `this.type() == "number"`
+	rule := ""
+	if isDuration {
+		subject, guard = "$d", "$d != null"
+		rule = "let d = this.string().parse_duration().catch(null)\n"
+	}
+
+	rule += "root = "
+	for i, b := range bounds {
+		if i > 0 {
+			rule += " else "
+		}
+		rule += fmt.Sprintf(`if %v && %v %v %v { [ "value %%v is %v of %v".format(this) ] }`, guard, subject, b.cmp, b.value, b.desc, b.label)
+	}
+	return rule
+}
+
 //------------------------------------------------------------------------------
 
 // ConfigSpec describes the configuration specification for a plugin
@@ -294,7 +489,7 @@ type ConfigSpec struct {
 }
 
 func (c *ConfigSpec) configFromAny(mgr bundle.NewManagement, v any) (pConf *ParsedConfig, err error) {
-	pConf = &ParsedConfig{mgr: mgr}
+	pConf = &ParsedConfig{mgr: mgr, spec: &c.component.Config}
 	pConf.i, err = c.component.Config.ParsedConfigFromAny(v)
 	return
 }
@@ -551,6 +746,29 @@ func (c *ConfigView) RenderDocs() ([]byte, error) {
 type ParsedConfig struct {
 	i   *docs.ParsedConfig
 	mgr bundle.NewManagement
+
+	// The spec of the config, which is nil when unknown.
+	spec *docs.FieldSpec
+}
+
+// fieldSpec returns the spec of a field of the parsed config, or nil if it is
+// unknown.
+func (p *ParsedConfig) fieldSpec(path ...string) *docs.FieldSpec {
+	spec := p.spec
+	for _, name := range path {
+		if spec == nil {
+			return nil
+		}
+		var child *docs.FieldSpec
+		for i := range spec.Children {
+			if spec.Children[i].Name == name {
+				child = &spec.Children[i]
+				break
+			}
+		}
+		spec = child
+	}
+	return spec
 }
 
 // EngineVersion returns the version stamp associated with the underlying
@@ -564,8 +782,9 @@ func (p *ParsedConfig) EngineVersion() string {
 // This is useful for extracting multiple fields under the same grouping.
 func (p *ParsedConfig) Namespace(path ...string) *ParsedConfig {
 	return &ParsedConfig{
-		i:   p.i.Namespace(path...),
-		mgr: p.mgr.IntoPath(path...),
+		i:    p.i.Namespace(path...),
+		mgr:  p.mgr.IntoPath(path...),
+		spec: p.fieldSpec(path...),
 	}
 }
 
@@ -713,11 +932,14 @@ func (p *ParsedConfig) FieldObjectList(path ...string) ([]*ParsedConfig, error)
 		return nil, err
 	}
 
+	spec := p.fieldSpec(path...)
+
 	pl := make([]*ParsedConfig, len(il))
 	for i, v := range il {
 		pl[i] = &ParsedConfig{
-			i:   v,
-			mgr: p.mgr,
+			i:    v,
+			mgr:  p.mgr,
+			spec: spec,
 		}
 	}
 	return pl, nil
@@ -733,11 +955,14 @@ func (p *ParsedConfig) FieldObjectMap(path ...string) (map[string]*ParsedConfig,
 		return nil, err
 	}
 
+	spec := p.fieldSpec(path...)
+
 	pl := make(map[string]*ParsedConfig, len(im))
 	for k, v := range im {
 		pl[k] = &ParsedConfig{
-			i:   v,
-			mgr: p.mgr,
+			i:    v,
+			mgr:  p.mgr,
+			spec: spec,
 		}
 	}
 	return pl, nil
@@ -1,32 +1,38 @@
 package service
 
 import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
 	"github.com/cenkalti/backoff/v4"
 )
 
-// NewBackOffField defines a new object type config field that describes an
-// exponential back off policy, often used for timing retry attempts. It is then
-// possible to extract a *backoff.ExponentialBackOff from the resulting parsed
-// config with the method FieldBackOff.
-//
-// It is possible to configure a back off policy that has no upper bound (no
-// maximum elapsed time set). In cases where this would be problematic the field
-// allowUnbounded should be set `false` in order to add linting rules that
-// ensure an upper bound is set.
-//
-// The defaults struct is optional, and if provided will be used to establish
-// default values for time interval fields. Otherwise the chosen defaults result
-// in one minute of retry attempts, starting at 500ms intervals.
-func NewBackOffField(name string, allowUnbounded bool, defaults *backoff.ExponentialBackOff) *ConfigField {
+const (
+	backOffStrategyExponential = "exponential"
+	backOffStrategyConstant    = "constant"
+	backOffStrategyLinear      = "linear"
+)
+
+func backOffFields(allowUnbounded, withStrategy bool, defaults *backoff.ExponentialBackOff) []*ConfigField {
 	var (
 		initDefault       = "500ms"
 		maxDefault        = "10s"
 		maxElapsedDefault = "1m"
+		randFactorDefault = backoff.DefaultRandomizationFactor
+		multiplierDefault = backoff.DefaultMultiplier
 	)
 	if defaults != nil {
 		initDefault = defaults.InitialInterval.String()
 		maxDefault = defaults.MaxInterval.String()
 		maxElapsedDefault = defaults.MaxElapsedTime.String()
+		if defaults.RandomizationFactor > 0 {
+			randFactorDefault = defaults.RandomizationFactor
+		}
+		if defaults.Multiplier >= 1 {
+			multiplierDefault = defaults.Multiplier
+		}
 	}
 
 	maxElapsedTime := NewDurationField("max_elapsed_time").
@@ -36,8 +42,19 @@ func NewBackOffField(name string, allowUnbounded bool, defaults *backoff.Exponen
 		maxElapsedTime.field.Description += " Setting this value to a zeroed duration (such as `0s`) will result in unbounded retries."
 	}
 
-	// TODO: Add linting rule to ensure we aren't unbounded if necessary.
-	return NewObjectField(name,
+	var fields []*ConfigField
+	if withStrategy {
+		fields = append(fields, NewStringAnnotatedEnumField("strategy", map[string]string{
+			backOffStrategyExponential: "The period between retry attempts starts at `initial_interval` and grows by `multiplier` after each attempt, up to `max_interval`.",
+			backOffStrategyConstant:    "The period between retry attempts is always `initial_interval`.",
+			backOffStrategyLinear:      "The period between retry attempts starts at `initial_interval` and grows by `initial_interval` after each attempt, up to `max_interval`.",
+		}).
+			Description("The strategy used for calculating the period between retry attempts.").
+			Default(backOffStrategyExponential).
+			Advanced())
+	}
+
+	return append(fields,
 		NewDurationField("initial_interval").
 			Description("The initial period to wait between retry attempts.").
 			Default(initDefault).Example("50ms").Example("1s"),
@@ -45,13 +62,66 @@ func NewBackOffField(name string, allowUnbounded bool, defaults *backoff.Exponen
 			Description("The maximum period to wait between retry attempts").
 			Default(maxDefault).Example("5s").Example("1m"),
 		maxElapsedTime,
-	).Description("Determine time intervals and cut offs for retry attempts.")
+		NewFloatField("randomization_factor").
+			Description("The degree of jitter applied to each retry interval, where a value of `0.5` allows intervals to vary by up to 50% either side of the calculated interval. Setting this to `0` disables jitter entirely.").
+			Default(randFactorDefault).
+			LintRule(`root = if this < 0 || this > 1 { [ "randomization_factor must be between 0 and 1" ] }`).
+			Advanced(),
+		NewFloatField("multiplier").
+			Description("The factor by which the retry interval grows after each attempt when using the `exponential` strategy, up to the `max_interval`.").
+			Default(multiplierDefault).
+			LintRule(`root = if this < 1 { [ "multiplier must be greater than or equal to 1" ] }`).
+			Advanced(),
+	)
 }
 
-// FieldBackOff accesses a field from a parsed config that was defined with
-// NewBackoffField and returns a *backoff.ExponentialBackOff, or an error if the
-// configuration was invalid.
-func (p *ParsedConfig) FieldBackOff(path ...string) (*backoff.ExponentialBackOff, error) {
+// backOffLintRule returns a lint rule to be applied to the back off object
+// field, which is provided all child fields (including defaults) and can
+// therefore perform checks across them.
+func backOffLintRule(allowUnbounded bool) string {
+	if allowUnbounded {
+		return ""
+	}
+	return `root = if this.enabled.or(true) && this.max_elapsed_time.or("").parse_duration().catch(-1) == 0 { [ "max_elapsed_time must be non-zero as unbounded retries are not permitted for this field" ] }`
+}
+
+// NewBackOffField defines a new object type config field that describes an
+// exponential back off policy, often used for timing retry attempts. It is then
+// possible to extract a *backoff.ExponentialBackOff from the resulting parsed
+// config with the method FieldBackOff.
+//
+// It is possible to configure a back off policy that has no upper bound (no
+// maximum elapsed time set). In cases where this would be problematic the field
+// allowUnbounded should be set `false` in order to add linting rules that
+// ensure an upper bound is set.
+//
+// The advanced fields `randomization_factor` and `multiplier` allow users to
+// tune the jitter and growth rate of retry intervals.
+//
+// The defaults struct is optional, and if provided will be used to establish
+// default values for time interval, jitter and multiplier fields, where a zero
+// jitter or multiplier falls back to the library defaults. Otherwise the chosen
+// defaults result in one minute of retry attempts, starting at 500ms intervals.
+func NewBackOffField(name string, allowUnbounded bool, defaults *backoff.ExponentialBackOff) *ConfigField {
+	return NewObjectField(name, backOffFields(allowUnbounded, false, defaults)...).
+		Description("Determine time intervals and cut offs for retry attempts.").
+		LintRule(backOffLintRule(allowUnbounded))
+}
+
+// NewBackOffPolicyField defines a new object type config field that describes
+// a back off policy, often used for timing retry attempts. It is identical to
+// NewBackOffField with the addition of an advanced field `strategy`, which
+// allows users to select a constant or linear back off policy instead of an
+// exponential one. As a linear back off cannot be expressed as a
+// *backoff.ExponentialBackOff the resulting parsed config must be accessed with
+// either FieldBackOffPolicy or FieldBackOffPolicyCtor rather than FieldBackOff.
+func NewBackOffPolicyField(name string, allowUnbounded bool, defaults *backoff.ExponentialBackOff) *ConfigField {
+	return NewObjectField(name, backOffFields(allowUnbounded, true, defaults)...).
+		Description("Determine time intervals and cut offs for retry attempts.").
+		LintRule(backOffLintRule(allowUnbounded))
+}
+
+func (p *ParsedConfig) fieldExponentialBackOff(path ...string) (*backoff.ExponentialBackOff, error) {
 	b := backoff.NewExponentialBackOff()
 
 	var err error
@@ -64,10 +134,129 @@ func (p *ParsedConfig) FieldBackOff(path ...string) (*backoff.ExponentialBackOff
 	if b.MaxElapsedTime, err = p.FieldDuration(append(path, "max_elapsed_time")...); err != nil {
 		return nil, err
 	}
+	if p.Contains(append(path, "randomization_factor")...) {
+		if b.RandomizationFactor, err = p.FieldFloat(append(path, "randomization_factor")...); err != nil {
+			return nil, err
+		}
+	}
+	if p.Contains(append(path, "multiplier")...) {
+		if b.Multiplier, err = p.FieldFloat(append(path, "multiplier")...); err != nil {
+			return nil, err
+		}
+	}
+
+	strategy := backOffStrategyExponential
+	if p.Contains(append(path, "strategy")...) {
+		if strategy, err = p.FieldString(append(path, "strategy")...); err != nil {
+			return nil, err
+		}
+	}
+	switch strategy {
+	case backOffStrategyExponential:
+	case backOffStrategyConstant:
+		// An exponential back off that never grows is a constant back off that
+		// still honours jitter and the maximum elapsed time.
+		b.Multiplier = 1
+	case backOffStrategyLinear:
+		return nil, fmt.Errorf("back off strategy '%v' cannot be expressed as an exponential back off", strategy)
+	default:
+		return nil, fmt.Errorf("back off strategy '%v' was not recognised", strategy)
+	}
 
+	b.Reset()
 	return b, nil
 }
 
+// FieldBackOff accesses a field from a parsed config that was defined with
+// NewBackoffField and returns a *backoff.ExponentialBackOff, or an error if the
+// configuration was invalid. When used with a field defined with
+// NewBackOffPolicyField a `constant` strategy is expressed as an exponential
+// back off with a multiplier of 1, and a `linear` strategy results in an error,
+// use FieldBackOffPolicy in order to support all strategies.
+func (p *ParsedConfig) FieldBackOff(path ...string) (*backoff.ExponentialBackOff, error) {
+	return p.fieldExponentialBackOff(path...)
+}
+
+// FieldBackOffPolicy accesses a field from a parsed config that was defined
+// with NewBackOffPolicyField, NewBackOffField or NewBackOffToggledField and
+// returns a backoff.BackOff of the configured strategy, or an error if the
+// configuration was invalid. The `enabled` field of a toggled back off is not
+// checked by this method.
+//
+// The returned back off is stateful and therefore a new one should be
+// extracted for each sequence of retry attempts that may run in parallel, for
+// which FieldBackOffPolicyCtor avoids parsing the config each time.
+func (p *ParsedConfig) FieldBackOffPolicy(path ...string) (backoff.BackOff, error) {
+	ctor, err := p.FieldBackOffPolicyCtor(path...)
+	if err != nil {
+		return nil, err
+	}
+	return ctor(), nil
+}
+
+// FieldBackOffPolicyCtor accesses a field from a parsed config in the same way
+// as FieldBackOffPolicy, but returns a constructor that creates a fresh
+// backoff.BackOff of the configured strategy each time it is called.
+func (p *ParsedConfig) FieldBackOffPolicyCtor(path ...string) (func() backoff.BackOff, error) {
+	strategy := backOffStrategyExponential
+	if p.Contains(append(path, "strategy")...) {
+		var err error
+		if strategy, err = p.FieldString(append(path, "strategy")...); err != nil {
+			return nil, err
+		}
+	}
+	if strategy != backOffStrategyLinear {
+		b, err := p.fieldExponentialBackOff(path...)
+		if err != nil {
+			return nil, err
+		}
+		return func() backoff.BackOff {
+			c := *b
+			c.Reset()
+			return &c
+		}, nil
+	}
+
+	b := &linearBackOff{}
+
+	var err error
+	if b.initialInterval, err = p.FieldDuration(append(path, "initial_interval")...); err != nil {
+		return nil, err
+	}
+	if b.maxInterval, err = p.FieldDuration(append(path, "max_interval")...); err != nil {
+		return nil, err
+	}
+	if b.maxElapsedTime, err = p.FieldDuration(append(path, "max_elapsed_time")...); err != nil {
+		return nil, err
+	}
+	if p.Contains(append(path, "randomization_factor")...) {
+		if b.randomizationFactor, err = p.FieldFloat(append(path, "randomization_factor")...); err != nil {
+			return nil, err
+		}
+	}
+
+	return func() backoff.BackOff {
+		c := *b
+		c.Reset()
+		return &c
+	}, nil
+}
+
+// FieldBackOffCtx accesses a field from a parsed config that was defined with
+// NewBackOffPolicyField, NewBackOffField or NewBackOffToggledField and returns a
+// backoff.BackOffContext of the configured strategy bound to the provided
+// context, or an error if the configuration was invalid. Once the context is
+// cancelled the back off returns backoff.Stop, which allows retry loops within
+// methods such as Connect or Write to end promptly when a stream is shutting
+// down.
+func (p *ParsedConfig) FieldBackOffCtx(ctx context.Context, path ...string) (backoff.BackOffContext, error) {
+	b, err := p.FieldBackOffPolicy(path...)
+	if err != nil {
+		return nil, err
+	}
+	return backoff.WithContext(b, ctx), nil
+}
+
 // NewBackOffToggledField defines a new object type config field that describes
 // an exponential back off policy, often used for timing retry attempts. It is
 // then possible to extract a *backoff.ExponentialBackOff from the resulting
@@ -79,41 +268,23 @@ func (p *ParsedConfig) FieldBackOff(path ...string) (*backoff.ExponentialBackOff
 // allowUnbounded should be set `false` in order to add linting rules that
 // ensure an upper bound is set.
 //
+// The advanced fields `randomization_factor` and `multiplier` allow users to
+// tune the jitter and growth rate of retry intervals.
+//
 // The defaults struct is optional, and if provided will be used to establish
-// default values for time interval fields. Otherwise the chosen defaults result
-// in one minute of retry attempts, starting at 500ms intervals.
+// default values for time interval, jitter and multiplier fields, where a zero
+// jitter or multiplier falls back to the library defaults. Otherwise the chosen
+// defaults result in one minute of retry attempts, starting at 500ms intervals.
 func NewBackOffToggledField(name string, allowUnbounded bool, defaults *backoff.ExponentialBackOff) *ConfigField {
-	var (
-		initDefault       = "500ms"
-		maxDefault        = "10s"
-		maxElapsedDefault = "1m"
-	)
-	if defaults != nil {
-		initDefault = defaults.InitialInterval.String()
-		maxDefault = defaults.MaxInterval.String()
-		maxElapsedDefault = defaults.MaxElapsedTime.String()
-	}
-
-	maxElapsedTime := NewDurationField("max_elapsed_time").
-		Description("The maximum overall period of time to spend on retry attempts before the request is aborted.").
-		Default(maxElapsedDefault).Example("1m").Example("1h")
-	if allowUnbounded {
-		maxElapsedTime.field.Description += " Setting this value to a zeroed duration (such as `0s`) will result in unbounded retries."
-	}
-
-	// TODO: Add linting rule to ensure we aren't unbounded if necessary.
-	return NewObjectField(name,
+	fields := append([]*ConfigField{
 		NewBoolField("enabled").
 			Description("Whether retries should be enabled.").
 			Default(false),
-		NewDurationField("initial_interval").
-			Description("The initial period to wait between retry attempts.").
-			Default(initDefault).Example("50ms").Example("1s"),
-		NewDurationField("max_interval").
-			Description("The maximum period to wait between retry attempts").
-			Default(maxDefault).Example("5s").Example("1m"),
-		maxElapsedTime,
-	).Description("Determine time intervals and cut offs for retry attempts.")
+	}, backOffFields(allowUnbounded, false, defaults)...)
+
+	return NewObjectField(name, fields...).
+		Description("Determine time intervals and cut offs for retry attempts.").
+		LintRule(backOffLintRule(allowUnbounded))
 }
 
 // FieldBackOffToggled accesses a field from a parsed config that was defined
@@ -121,20 +292,47 @@ func NewBackOffToggledField(name string, allowUnbounded bool, defaults *backoff.
 // flag indicating whether retries are explicitly enabled, or an error if the
 // configuration was invalid.
 func (p *ParsedConfig) FieldBackOffToggled(path ...string) (boff *backoff.ExponentialBackOff, enabled bool, err error) {
-	boff = backoff.NewExponentialBackOff()
-
 	if enabled, err = p.FieldBool(append(path, "enabled")...); err != nil {
 		return
 	}
-	if boff.InitialInterval, err = p.FieldDuration(append(path, "initial_interval")...); err != nil {
-		return
-	}
-	if boff.MaxInterval, err = p.FieldDuration(append(path, "max_interval")...); err != nil {
-		return
+	boff, err = p.fieldExponentialBackOff(path...)
+	return
+}
+
+//------------------------------------------------------------------------------
+
+// linearBackOff is a backoff.BackOff where the period between attempts grows
+// by the initial interval after each attempt.
+type linearBackOff struct {
+	initialInterval     time.Duration
+	maxInterval         time.Duration
+	maxElapsedTime      time.Duration
+	randomizationFactor float64
+
+	currentInterval time.Duration
+	startTime       time.Time
+}
+
+func (b *linearBackOff) Reset() {
+	b.currentInterval = b.initialInterval
+	b.startTime = time.Now()
+}
+
+func (b *linearBackOff) NextBackOff() time.Duration {
+	next := b.currentInterval
+	if b.randomizationFactor > 0 {
+		delta := b.randomizationFactor * float64(next)
+		minInterval := float64(next) - delta
+		maxInterval := float64(next) + delta
+		next = time.Duration(minInterval + (rand.Float64() * (maxInterval - minInterval + 1)))
 	}
-	if boff.MaxElapsedTime, err = p.FieldDuration(append(path, "max_elapsed_time")...); err != nil {
-		return
+
+	if b.maxElapsedTime != 0 && time.Since(b.startTime)+next > b.maxElapsedTime {
+		return backoff.Stop
 	}
 
-	return
+	if b.currentInterval += b.initialInterval; b.maxInterval > 0 && b.currentInterval > b.maxInterval {
+		b.currentInterval = b.maxInterval
+	}
+	return next
 }
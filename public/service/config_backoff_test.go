@@ -1,12 +1,17 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/bundle"
+	"github.com/redpanda-data/benthos/v4/internal/docs"
 )
 
 func TestConfigBackOff(t *testing.T) {
@@ -55,3 +60,283 @@ a:
 	assert.Equal(t, time.Second*300, bConf.MaxInterval)
 	assert.Equal(t, time.Hour*6, bConf.MaxElapsedTime)
 }
+
+func TestConfigBackOffPartialDefaults(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewBackOffField("a", true, &backoff.ExponentialBackOff{
+			InitialInterval: time.Second,
+			MaxInterval:     time.Second * 10,
+		}))
+
+	parsedConfig, err := spec.ParseYAML(`a: {}`, nil)
+	require.NoError(t, err)
+
+	bConf, err := parsedConfig.FieldBackOff("a")
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Second, bConf.InitialInterval)
+	assert.Equal(t, backoff.DefaultRandomizationFactor, bConf.RandomizationFactor)
+	assert.Equal(t, backoff.DefaultMultiplier, bConf.Multiplier)
+}
+
+func TestConfigBackOffJitterAndMultiplier(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewBackOffField("a", true, nil)).
+		Field(NewBackOffToggledField("b", true, nil))
+
+	parsedConfig, err := spec.ParseYAML(`
+a:
+  randomization_factor: 0.1
+  multiplier: 3
+b:
+  enabled: true
+  randomization_factor: 0
+`, nil)
+	require.NoError(t, err)
+
+	aConf, err := parsedConfig.FieldBackOff("a")
+	require.NoError(t, err)
+
+	assert.Equal(t, 0.1, aConf.RandomizationFactor)
+	assert.Equal(t, 3.0, aConf.Multiplier)
+
+	bConf, enabled, err := parsedConfig.FieldBackOffToggled("b")
+	require.NoError(t, err)
+
+	assert.True(t, enabled)
+	assert.Equal(t, 0.0, bConf.RandomizationFactor)
+	assert.Equal(t, backoff.DefaultMultiplier, bConf.Multiplier)
+}
+
+func TestConfigBackOffJitterAndMultiplierLints(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewBackOffField("a", true, nil))
+
+	tests := []struct {
+		name   string
+		config string
+		lints  []docs.Lint
+	}{
+		{
+			name: "valid values",
+			config: `
+a:
+  randomization_factor: 1
+  multiplier: 1
+`,
+		},
+		{
+			name: "randomization factor out of range",
+			config: `
+a:
+  randomization_factor: 1.5
+`,
+			lints: []docs.Lint{
+				docs.NewLintError(3, docs.LintCustom, errors.New("randomization_factor must be between 0 and 1")),
+			},
+		},
+		{
+			name: "multiplier too small",
+			config: `
+a:
+  multiplier: 0.5
+`,
+			lints: []docs.Lint{
+				docs.NewLintError(3, docs.LintCustom, errors.New("multiplier must be greater than or equal to 1")),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node, err := NewStreamBuilder().getYAMLNode([]byte(test.config))
+			require.NoError(t, err)
+
+			assert.Equal(t, test.lints, spec.component.Config.Children.LintYAML(docs.NewLintContext(docs.NewLintConfig(bundle.GlobalEnvironment)), node))
+		})
+	}
+}
+
+func TestConfigBackOffStrategies(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewBackOffPolicyField("exp", true, nil)).
+		Field(NewBackOffPolicyField("const", true, nil)).
+		Field(NewBackOffPolicyField("lin", true, nil))
+
+	parsedConfig, err := spec.ParseYAML(`
+const:
+  strategy: constant
+  initial_interval: 1s
+  randomization_factor: 0
+lin:
+  strategy: linear
+  initial_interval: 1s
+  max_interval: 3s
+  max_elapsed_time: 0s
+  randomization_factor: 0
+`, nil)
+	require.NoError(t, err)
+
+	expBoff, err := parsedConfig.FieldBackOffPolicy("exp")
+	require.NoError(t, err)
+	assert.IsType(t, &backoff.ExponentialBackOff{}, expBoff)
+
+	constBoff, err := parsedConfig.FieldBackOffPolicy("const")
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, time.Second, constBoff.NextBackOff())
+	}
+
+	constExpBoff, err := parsedConfig.FieldBackOff("const")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, constExpBoff.Multiplier)
+
+	linBoff, err := parsedConfig.FieldBackOffPolicy("lin")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, linBoff.NextBackOff())
+	assert.Equal(t, time.Second*2, linBoff.NextBackOff())
+	assert.Equal(t, time.Second*3, linBoff.NextBackOff())
+	assert.Equal(t, time.Second*3, linBoff.NextBackOff())
+
+	linBoff.Reset()
+	assert.Equal(t, time.Second, linBoff.NextBackOff())
+
+	_, err = parsedConfig.FieldBackOff("lin")
+	require.Error(t, err)
+
+	linCtor, err := parsedConfig.FieldBackOffPolicyCtor("lin")
+	require.NoError(t, err)
+	linA, linB := linCtor(), linCtor()
+	assert.Equal(t, time.Second, linA.NextBackOff())
+	assert.Equal(t, time.Second*2, linA.NextBackOff())
+	assert.Equal(t, time.Second, linB.NextBackOff())
+}
+
+func TestConfigBackOffStrategyOptIn(t *testing.T) {
+	childNames := func(f *ConfigField) (names []string) {
+		for _, c := range f.field.Children {
+			names = append(names, c.Name)
+		}
+		return
+	}
+
+	assert.NotContains(t, childNames(NewBackOffField("a", true, nil)), "strategy")
+	assert.NotContains(t, childNames(NewBackOffToggledField("a", true, nil)), "strategy")
+	assert.Contains(t, childNames(NewBackOffPolicyField("a", true, nil)), "strategy")
+}
+
+func TestConfigBackOffLinearMaxElapsed(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewBackOffPolicyField("a", false, nil))
+
+	parsedConfig, err := spec.ParseYAML(`
+a:
+  strategy: linear
+  initial_interval: 1s
+  max_elapsed_time: 2500ms
+  randomization_factor: 0
+`, nil)
+	require.NoError(t, err)
+
+	boff, err := parsedConfig.FieldBackOffPolicy("a")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, boff.NextBackOff())
+	assert.Equal(t, time.Second*2, boff.NextBackOff())
+	assert.Equal(t, backoff.Stop, boff.NextBackOff())
+}
+
+func TestConfigBackOffUnboundedLints(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewBackOffField("a", false, nil)).
+		Field(NewBackOffToggledField("b", false, nil)).
+		Field(NewBackOffField("c", true, nil))
+
+	unboundedErr := errors.New("max_elapsed_time must be non-zero as unbounded retries are not permitted for this field")
+
+	tests := []struct {
+		name   string
+		config string
+		lints  []docs.Lint
+	}{
+		{
+			name: "bounded",
+			config: `
+a:
+  max_elapsed_time: 10s
+b:
+  enabled: true
+`,
+		},
+		{
+			name: "unbounded",
+			config: `
+a:
+  max_elapsed_time: 0s
+`,
+			lints: []docs.Lint{
+				docs.NewLintError(3, docs.LintCustom, unboundedErr),
+			},
+		},
+		{
+			name: "unbounded toggled enabled",
+			config: `
+b:
+  enabled: true
+  max_elapsed_time: 0s
+`,
+			lints: []docs.Lint{
+				docs.NewLintError(3, docs.LintCustom, unboundedErr),
+			},
+		},
+		{
+			name: "unbounded toggled disabled",
+			config: `
+b:
+  max_elapsed_time: 0s
+`,
+		},
+		{
+			name: "unbounded allowed",
+			config: `
+c:
+  max_elapsed_time: 0s
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node, err := NewStreamBuilder().getYAMLNode([]byte(test.config))
+			require.NoError(t, err)
+
+			assert.Equal(t, test.lints, spec.component.Config.Children.LintYAML(docs.NewLintContext(docs.NewLintConfig(bundle.GlobalEnvironment)), node))
+		})
+	}
+}
+
+func TestConfigBackOffCtx(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewBackOffPolicyField("a", true, nil))
+
+	parsedConfig, err := spec.ParseYAML(`
+a:
+  strategy: constant
+  initial_interval: 1s
+  randomization_factor: 0
+`, nil)
+	require.NoError(t, err)
+
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	boff, err := parsedConfig.FieldBackOffCtx(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, ctx, boff.Context())
+	assert.Equal(t, time.Second, boff.NextBackOff())
+
+	done()
+	assert.Equal(t, backoff.Stop, boff.NextBackOff())
+
+	_, err = parsedConfig.FieldBackOffCtx(context.Background(), "b")
+	require.Error(t, err)
+}
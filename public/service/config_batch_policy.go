@@ -16,10 +16,12 @@ import (
 // messages destined for a Batch output. This is returned by constructors of
 // batch outputs.
 type BatchPolicy struct {
-	ByteSize int
-	Count    int
-	Check    string
-	Period   string
+	ByteSize     int
+	Count        int
+	MinCount     int
+	Check        string
+	Period       string
+	PeriodJitter string
 
 	// Only available when using NewBatchPolicyField.
 	procs []processor.Config
@@ -29,8 +31,10 @@ func (b BatchPolicy) toInternal() batchconfig.Config {
 	batchConf := batchconfig.NewConfig()
 	batchConf.ByteSize = b.ByteSize
 	batchConf.Count = b.Count
+	batchConf.MinCount = b.MinCount
 	batchConf.Check = b.Check
 	batchConf.Period = b.Period
+	batchConf.PeriodJitter = b.PeriodJitter
 	batchConf.Processors = b.procs
 	return batchConf
 }
@@ -65,7 +69,9 @@ func (b *Batcher) Add(msg *Message) bool {
 // UntilNext returns a duration indicating how long until the current batch
 // should be flushed due to a configured period. A boolean is also returned
 // indicating whether the batching policy has a timed factor, if this is false
-// then the duration returned should be ignored.
+// then the duration returned should be ignored. When the policy has a minimum
+// count the boolean is false until enough messages have been added, and
+// therefore it should be checked again after each call to Add.
 func (b *Batcher) UntilNext() (time.Duration, bool) {
 	t := b.p.UntilNext()
 	if t > 0 {
@@ -156,6 +162,12 @@ func (p *ParsedConfig) FieldBatchPolicy(path ...string) (conf BatchPolicy, err e
 	if conf.Period, err = p.FieldString(append(path, "period")...); err != nil {
 		return
 	}
+	if conf.MinCount, err = p.FieldInt(append(path, "min_count")...); err != nil {
+		return
+	}
+	if conf.PeriodJitter, err = p.FieldString(append(path, "period_jitter")...); err != nil {
+		return
+	}
 	conf.procs, err = p.fieldProcessorListConfigs(append(path, "processors")...)
 	return
 }
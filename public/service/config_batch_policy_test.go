@@ -18,7 +18,9 @@ func TestConfigBatching(t *testing.T) {
 	parsedConfig, err := spec.ParseYAML(`
 a:
   count: 20
+  min_count: 5
   period: 5s
+  period_jitter: 500ms
   processors:
     - bloblang: 'root = content().uppercase()'
 `, nil)
@@ -32,6 +34,8 @@ a:
 
 	assert.Equal(t, 20, bConf.Count)
 	assert.Equal(t, "5s", bConf.Period)
+	assert.Equal(t, 5, bConf.MinCount)
+	assert.Equal(t, "500ms", bConf.PeriodJitter)
 	require.Len(t, bConf.procs, 1)
 	assert.Equal(t, "bloblang", bConf.procs[0].Type)
 }
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/redpanda-data/benthos/v4/internal/bytesize"
+	"github.com/redpanda-data/benthos/v4/internal/docs"
+)
+
+// NewByteSizeField describes a new config field that describes a quantity of
+// bytes, either as an integer or a string with a human readable unit such as
+// `512KB` or `10MiB`. Units are case insensitive, where SI units (KB, MB, GB,
+// etc) are powers of 1000 and IEC units (KiB, MiB, GiB, etc) are powers of
+// 1024. It is then possible to extract the number of bytes from the resulting
+// parsed config with the method FieldByteSize.
+func NewByteSizeField(name string) *ConfigField {
+	tf := docs.FieldString(name, "").LinterBlobl(fmt.Sprintf(`
+root = if !this.string().re_match("""%v""") {
+  [ "value %%v is not a valid byte size, expected a number followed by an optional unit such as KB or MiB".format(this) ]
+}
+`, bytesize.Pattern))
+	return &ConfigField{field: tf}
+}
+
+// FieldByteSize accesses a field from a parsed config that was defined with
+// NewByteSizeField and returns the number of bytes it describes, or an error if
+// the value was invalid.
+func (p *ParsedConfig) FieldByteSize(path ...string) (int64, error) {
+	v, exists := p.i.Field(path...)
+	if !exists {
+		return 0, fmt.Errorf("field '%v' was not found in the config", p.i.FullDotPath(path...))
+	}
+
+	str, ok := v.(string)
+	if !ok {
+		i, err := p.FieldInt(path...)
+		if err != nil {
+			return 0, err
+		}
+		if i < 0 {
+			return 0, fmt.Errorf("field '%v' must not be negative", p.i.FullDotPath(path...))
+		}
+		return int64(i), nil
+	}
+
+	b, err := bytesize.Parse(str)
+	if err != nil {
+		return 0, fmt.Errorf("field '%v': %w", p.i.FullDotPath(path...), err)
+	}
+	return b, nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/bundle"
+	"github.com/redpanda-data/benthos/v4/internal/docs"
+)
+
+func TestConfigByteSize(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewByteSizeField("a")).
+		Field(NewByteSizeField("b")).
+		Field(NewByteSizeField("c")).
+		Field(NewByteSizeField("d")).
+		Field(NewByteSizeField("e")).
+		Field(NewByteSizeField("f").Default("1KiB"))
+
+	parsedConfig, err := spec.ParseYAML(`
+a: 10MiB
+b: 512kb
+c: 1024
+d: 1.5 GB
+e: 20b
+`, nil)
+	require.NoError(t, err)
+
+	for k, exp := range map[string]int64{
+		"a": 10 * 1024 * 1024,
+		"b": 512 * 1000,
+		"c": 1024,
+		"d": 1500 * 1000 * 1000,
+		"e": 20,
+		"f": 1024,
+	} {
+		v, err := parsedConfig.FieldByteSize(k)
+		require.NoError(t, err, k)
+		assert.Equal(t, exp, v, k)
+	}
+
+	_, err = parsedConfig.FieldByteSize("g")
+	require.Error(t, err)
+}
+
+func TestConfigByteSizeLints(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewByteSizeField("a"))
+
+	tests := []struct {
+		name   string
+		config string
+		lints  []docs.Lint
+	}{
+		{
+			name:   "valid string",
+			config: `a: 10 MiB`,
+		},
+		{
+			name:   "valid int",
+			config: `a: 100`,
+		},
+		{
+			name:   "invalid unit",
+			config: `a: 10 furlongs`,
+			lints: []docs.Lint{
+				docs.NewLintError(1, docs.LintCustom, errors.New("value 10 furlongs is not a valid byte size, expected a number followed by an optional unit such as KB or MiB")),
+			},
+		},
+		{
+			name:   "negative int",
+			config: `a: -10`,
+			lints: []docs.Lint{
+				docs.NewLintError(1, docs.LintCustom, errors.New("value -10 is not a valid byte size, expected a number followed by an optional unit such as KB or MiB")),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node, err := NewStreamBuilder().getYAMLNode([]byte(test.config))
+			require.NoError(t, err)
+
+			assert.Equal(t, test.lints, spec.component.Config.Children.LintYAML(docs.NewLintContext(docs.NewLintConfig(bundle.GlobalEnvironment)), node))
+		})
+	}
+}
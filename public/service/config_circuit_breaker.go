@@ -0,0 +1,201 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	cbFieldFailureThreshold = "failure_threshold"
+	cbFieldHalfOpenProbes   = "half_open_probes"
+	cbFieldResetTimeout     = "reset_timeout"
+)
+
+// ErrCircuitBreakerOpen is returned by CircuitBreaker.Allow when the breaker is
+// open, or when it is half-open and the maximum number of probe attempts are
+// already in progress.
+var ErrCircuitBreakerOpen = errors.New("circuit breaker is open")
+
+// CircuitBreakerState describes the current state of a CircuitBreaker.
+type CircuitBreakerState int
+
+// The possible states of a CircuitBreaker.
+const (
+	// CircuitBreakerClosed means that attempts are permitted.
+	CircuitBreakerClosed CircuitBreakerState = iota
+	// CircuitBreakerOpen means that attempts are rejected until the reset
+	// timeout has elapsed.
+	CircuitBreakerOpen
+	// CircuitBreakerHalfOpen means that a limited number of probe attempts are
+	// permitted in order to determine whether the breaker should close.
+	CircuitBreakerHalfOpen
+)
+
+// String returns a human readable name of the state.
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitBreakerClosed:
+		return "closed"
+	case CircuitBreakerOpen:
+		return "open"
+	case CircuitBreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreaker tracks the success and failure of attempts to reach a
+// dependency and stops further attempts once a threshold of consecutive
+// failures is reached. After a reset timeout a number of probe attempts are
+// permitted, and if they all succeed the breaker closes again.
+//
+// A CircuitBreaker is safe to use from multiple goroutines.
+type CircuitBreaker struct {
+	failureThreshold int
+	halfOpenProbes   int
+	resetTimeout     time.Duration
+
+	mut       sync.Mutex
+	state     CircuitBreakerState
+	failures  int
+	probes    int
+	successes int
+	openedAt  time.Time
+
+	nowFn func() time.Time
+}
+
+// NewCircuitBreaker creates a closed circuit breaker that opens after
+// failureThreshold consecutive failures, and permits halfOpenProbes attempts
+// once resetTimeout has elapsed since opening.
+func NewCircuitBreaker(failureThreshold, halfOpenProbes int, resetTimeout time.Duration) *CircuitBreaker {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+	if halfOpenProbes < 1 {
+		halfOpenProbes = 1
+	}
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		halfOpenProbes:   halfOpenProbes,
+		resetTimeout:     resetTimeout,
+		nowFn:            time.Now,
+	}
+}
+
+// State returns the current state of the breaker.
+func (c *CircuitBreaker) State() CircuitBreakerState {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.checkResetTimeout()
+	return c.state
+}
+
+func (c *CircuitBreaker) checkResetTimeout() {
+	if c.state == CircuitBreakerOpen && c.nowFn().Sub(c.openedAt) >= c.resetTimeout {
+		c.state = CircuitBreakerHalfOpen
+		c.probes = 0
+		c.successes = 0
+	}
+}
+
+func (c *CircuitBreaker) open() {
+	c.state = CircuitBreakerOpen
+	c.openedAt = c.nowFn()
+	c.failures = 0
+}
+
+// Allow returns nil if an attempt should be made, or ErrCircuitBreakerOpen if
+// the attempt should be abandoned. Each permitted attempt should be followed by
+// a call to either Success or Failure.
+func (c *CircuitBreaker) Allow() error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.checkResetTimeout()
+	switch c.state {
+	case CircuitBreakerOpen:
+		return ErrCircuitBreakerOpen
+	case CircuitBreakerHalfOpen:
+		if c.probes >= c.halfOpenProbes {
+			return ErrCircuitBreakerOpen
+		}
+		c.probes++
+	}
+	return nil
+}
+
+// Success records that a permitted attempt succeeded.
+func (c *CircuitBreaker) Success() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	switch c.state {
+	case CircuitBreakerClosed:
+		c.failures = 0
+	case CircuitBreakerHalfOpen:
+		if c.successes++; c.successes >= c.halfOpenProbes {
+			c.state = CircuitBreakerClosed
+			c.failures = 0
+		}
+	}
+}
+
+// Failure records that a permitted attempt failed.
+func (c *CircuitBreaker) Failure() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	switch c.state {
+	case CircuitBreakerClosed:
+		if c.failures++; c.failures >= c.failureThreshold {
+			c.open()
+		}
+	case CircuitBreakerHalfOpen:
+		c.open()
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// NewCircuitBreakerField defines a new object type config field that describes
+// a circuit breaker, which stops attempts to reach a dependency after a number
+// of consecutive failures. It is then possible to extract a *CircuitBreaker
+// from the resulting parsed config with the method FieldCircuitBreaker.
+func NewCircuitBreakerField(name string) *ConfigField {
+	return NewObjectField(name,
+		NewIntField(cbFieldFailureThreshold).
+			Description("The number of consecutive failures after which the circuit breaker opens and further attempts are rejected.").
+			Default(5).
+			LintRule(`root = if this < 1 { [ "failure_threshold must be greater than zero" ] }`),
+		NewIntField(cbFieldHalfOpenProbes).
+			Description("The number of probe attempts permitted once the reset timeout has elapsed, all of which must succeed in order for the circuit breaker to close.").
+			Default(1).
+			LintRule(`root = if this < 1 { [ "half_open_probes must be greater than zero" ] }`).
+			Advanced(),
+		NewDurationField(cbFieldResetTimeout).
+			Description("The period of time to wait after the circuit breaker opens before probe attempts are permitted.").
+			Default("30s").Example("10s").Example("1m"),
+	).Description("Stop attempts after a number of consecutive failures, resuming them once a reset timeout has elapsed.")
+}
+
+// FieldCircuitBreaker accesses a field from a parsed config that was defined
+// with NewCircuitBreakerField and returns a *CircuitBreaker, or an error if the
+// configuration was invalid.
+func (p *ParsedConfig) FieldCircuitBreaker(path ...string) (*CircuitBreaker, error) {
+	failureThreshold, err := p.FieldInt(append(path, cbFieldFailureThreshold)...)
+	if err != nil {
+		return nil, err
+	}
+	halfOpenProbes, err := p.FieldInt(append(path, cbFieldHalfOpenProbes)...)
+	if err != nil {
+		return nil, err
+	}
+	resetTimeout, err := p.FieldDuration(append(path, cbFieldResetTimeout)...)
+	if err != nil {
+		return nil, err
+	}
+	return NewCircuitBreaker(failureThreshold, halfOpenProbes, resetTimeout), nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigCircuitBreaker(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewCircuitBreakerField("a"))
+
+	parsedConfig, err := spec.ParseYAML(`
+a:
+  failure_threshold: 2
+  half_open_probes: 2
+  reset_timeout: 1m
+`, nil)
+	require.NoError(t, err)
+
+	_, err = parsedConfig.FieldCircuitBreaker("b")
+	require.Error(t, err)
+
+	cb, err := parsedConfig.FieldCircuitBreaker("a")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, cb.failureThreshold)
+	assert.Equal(t, 2, cb.halfOpenProbes)
+	assert.Equal(t, time.Minute, cb.resetTimeout)
+}
+
+func TestCircuitBreakerStates(t *testing.T) {
+	now := time.Unix(0, 0)
+
+	cb := NewCircuitBreaker(2, 2, time.Second)
+	cb.nowFn = func() time.Time { return now }
+
+	require.NoError(t, cb.Allow())
+	cb.Failure()
+	require.NoError(t, cb.Allow())
+	cb.Success()
+	require.NoError(t, cb.Allow())
+	cb.Failure()
+	assert.Equal(t, CircuitBreakerClosed, cb.State())
+
+	require.NoError(t, cb.Allow())
+	cb.Failure()
+	assert.Equal(t, CircuitBreakerOpen, cb.State())
+	assert.ErrorIs(t, cb.Allow(), ErrCircuitBreakerOpen)
+
+	now = now.Add(time.Second)
+	assert.Equal(t, CircuitBreakerHalfOpen, cb.State())
+
+	require.NoError(t, cb.Allow())
+	require.NoError(t, cb.Allow())
+	assert.ErrorIs(t, cb.Allow(), ErrCircuitBreakerOpen)
+
+	cb.Success()
+	cb.Failure()
+	assert.Equal(t, CircuitBreakerOpen, cb.State())
+
+	now = now.Add(time.Second)
+	require.NoError(t, cb.Allow())
+	require.NoError(t, cb.Allow())
+	cb.Success()
+	assert.Equal(t, CircuitBreakerHalfOpen, cb.State())
+	cb.Success()
+	assert.Equal(t, CircuitBreakerClosed, cb.State())
+	require.NoError(t, cb.Allow())
+}
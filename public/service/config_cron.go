@@ -0,0 +1,61 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/redpanda-data/benthos/v4/internal/docs"
+)
+
+var cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+func parseCronSchedule(expr string) (cron.Schedule, error) {
+	expr = strings.TrimSpace(expr)
+
+	// If a time zone is not included then default to UTC
+	if !strings.HasPrefix(expr, "TZ=") && !strings.HasPrefix(expr, "CRON_TZ=") {
+		expr = "TZ=UTC " + expr
+	}
+	return cronParser.Parse(expr)
+}
+
+// NewCronField describes a new string type config field that contains a cron
+// expression, with an optional seconds field and support for descriptors such
+// as `@every 1m` or `@daily`. A timezone can be specified by prefixing the
+// expression with `TZ=<location name>`, where the location name corresponds to
+// a file within the IANA Time Zone database, otherwise UTC is used. It is then
+// possible to extract a cron.Schedule from the resulting parsed config with the
+// method FieldCronSchedule.
+//
+// Note that the linting rule that validates cron expressions is only effective
+// within the binary that defines it.
+func NewCronField(name string) *ConfigField {
+	tf := docs.FieldString(name, "").LinterFunc(func(ctx docs.LintContext, line, col int, value any) []docs.Lint {
+		str, ok := value.(string)
+		if !ok {
+			return nil
+		}
+		if _, err := parseCronSchedule(str); err != nil {
+			return []docs.Lint{docs.NewLintError(line, docs.LintCustom, fmt.Errorf("failed to parse cron expression: %w", err))}
+		}
+		return nil
+	})
+	return &ConfigField{field: tf}
+}
+
+// FieldCronSchedule accesses a field from a parsed config that was defined with
+// NewCronField and returns a cron.Schedule, or an error if the expression was
+// invalid.
+func (p *ParsedConfig) FieldCronSchedule(path ...string) (cron.Schedule, error) {
+	str, err := p.FieldString(path...)
+	if err != nil {
+		return nil, err
+	}
+	s, err := parseCronSchedule(str)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse field '%v' as a cron expression: %w", p.i.FullDotPath(path...), err)
+	}
+	return s, nil
+}
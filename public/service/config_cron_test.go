@@ -0,0 +1,75 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/bundle"
+	"github.com/redpanda-data/benthos/v4/internal/docs"
+)
+
+func TestConfigCron(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewCronField("a")).
+		Field(NewCronField("b")).
+		Field(NewCronField("c")).
+		Field(NewCronField("d"))
+
+	parsedConfig, err := spec.ParseYAML(`
+a: '0 30 * * * *'
+b: 'TZ=America/New_York 0 9 * * *'
+c: '@every 5m'
+d: 'not a cron'
+`, nil)
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	a, err := parsedConfig.FieldCronSchedule("a")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC), a.Next(start).UTC())
+
+	b, err := parsedConfig.FieldCronSchedule("b")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC), b.Next(start).UTC())
+
+	c, err := parsedConfig.FieldCronSchedule("c")
+	require.NoError(t, err)
+	assert.Equal(t, start.Add(5*time.Minute), c.Next(start).UTC())
+
+	_, err = parsedConfig.FieldCronSchedule("d")
+	require.Error(t, err)
+
+	_, err = parsedConfig.FieldCronSchedule("e")
+	require.Error(t, err)
+}
+
+func TestConfigCronLints(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewCronField("a"))
+
+	for _, test := range []struct {
+		config  string
+		lintErr bool
+	}{
+		{config: `a: '*/5 * * * *'`},
+		{config: `a: 'TZ=Europe/London 30 3-6,20-23 * * *'`},
+		{config: `a: '@hourly'`},
+		{config: `a: '1 2 3'`, lintErr: true},
+		{config: `a: 'TZ=Nope/Nowhere * * * * *'`, lintErr: true},
+	} {
+		node, err := NewStreamBuilder().getYAMLNode([]byte(test.config))
+		require.NoError(t, err)
+
+		lints := spec.component.Config.Children.LintYAML(docs.NewLintContext(docs.NewLintConfig(bundle.GlobalEnvironment)), node)
+		if test.lintErr {
+			require.Len(t, lints, 1, test.config)
+			assert.Contains(t, lints[0].What, "failed to parse cron expression", test.config)
+		} else {
+			assert.Empty(t, lints, test.config)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+const decodeTagName = "benthos"
+
+// DecodeInto populates the fields of a struct, provided as a pointer, from a
+// parsed config, optionally at a given path. Struct fields are associated with
+// config fields by the tag `benthos:"<name>"`, where fields without a tag (or
+// with the tag `benthos:"-"`) are ignored, and config fields that are absent
+// from the parsed config leave the struct field unmodified.
+//
+// Struct fields can be of any string, boolean, integer or float type, a slice
+// or map (with string keys) of those types, a nested struct (or pointer to a
+// struct), or a slice or map of structs, which are decoded from object fields.
+// The following types are also supported and correspond to their respective
+// config field types:
+//
+// - time.Duration from NewDurationField.
+// - *InterpolatedString (and slices or maps of) from NewInterpolatedStringField.
+// - *url.URL (and slices of) from NewURLField.
+// - *InterpolatedURL from NewInterpolatedURLField.
+// - *bloblang.Executor from NewBloblangField.
+// - *tls.Config from NewTLSField.
+// - *Secret from NewSecretField.
+// - BatchPolicy from NewBatchPolicyField.
+//
+// Integer fields defined with NewByteSizeField should be tagged with the
+// option `bytesize`, e.g. `benthos:"max_size,bytesize"`.
+func (p *ParsedConfig) DecodeInto(v any, path ...string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected a non-nil pointer to a struct, got %T", v)
+	}
+	return p.decodeStruct(rv.Elem(), path)
+}
+
+func (p *ParsedConfig) decodeStruct(sv reflect.Value, path []string) error {
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		tag, exists := sf.Tag.Lookup(decodeTagName)
+		if !exists || tag == "-" || !sf.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			return fmt.Errorf("struct field %v has an empty %v tag name", sf.Name, decodeTagName)
+		}
+
+		fieldPath := make([]string, 0, len(path)+1)
+		fieldPath = append(append(fieldPath, path...), name)
+		if !p.Contains(fieldPath...) {
+			continue
+		}
+		if err := p.decodeField(sv.Field(i), fieldPath, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ParsedConfig) decodeField(fv reflect.Value, path []string, opts string) (err error) {
+	switch t := fv.Addr().Interface().(type) {
+	case *time.Duration:
+		*t, err = p.FieldDuration(path...)
+		return
+	case **InterpolatedString:
+		*t, err = p.FieldInterpolatedString(path...)
+		return
+	case *[]*InterpolatedString:
+		*t, err = p.FieldInterpolatedStringList(path...)
+		return
+	case *map[string]*InterpolatedString:
+		*t, err = p.FieldInterpolatedStringMap(path...)
+		return
+	case **InterpolatedURL:
+		*t, err = p.FieldInterpolatedURL(path...)
+		return
+	case **url.URL:
+		*t, err = p.FieldURL(path...)
+		return
+	case *[]*url.URL:
+		*t, err = p.FieldURLList(path...)
+		return
+	case **bloblang.Executor:
+		*t, err = p.FieldBloblang(path...)
+		return
+	case **tls.Config:
+		*t, err = p.FieldTLS(path...)
+		return
+	case **Secret:
+		*t, err = p.FieldSecret(path...)
+		return
+	case *BatchPolicy:
+		*t, err = p.FieldBatchPolicy(path...)
+		return
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		var s string
+		if s, err = p.FieldString(path...); err != nil {
+			return
+		}
+		fv.SetString(s)
+	case reflect.Bool:
+		var b bool
+		if b, err = p.FieldBool(path...); err != nil {
+			return
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = p.decodeInt(path, opts); err != nil {
+			return
+		}
+		if fv.OverflowInt(i) {
+			return fmt.Errorf("field '%v' value %v overflows type %v", p.i.FullDotPath(path...), i, fv.Type())
+		}
+		fv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var i int64
+		if i, err = p.decodeInt(path, opts); err != nil {
+			return
+		}
+		if i < 0 || fv.OverflowUint(uint64(i)) {
+			return fmt.Errorf("field '%v' value %v overflows type %v", p.i.FullDotPath(path...), i, fv.Type())
+		}
+		fv.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = p.FieldFloat(path...); err != nil {
+			return
+		}
+		fv.SetFloat(f)
+	case reflect.Struct:
+		return p.decodeStruct(fv, path)
+	case reflect.Pointer:
+		if fv.Type().Elem().Kind() != reflect.Struct {
+			return p.unsupportedDecodeType(fv, path)
+		}
+		nv := reflect.New(fv.Type().Elem())
+		if err = p.decodeStruct(nv.Elem(), path); err != nil {
+			return
+		}
+		fv.Set(nv)
+	case reflect.Slice:
+		return p.decodeSlice(fv, path, opts)
+	case reflect.Map:
+		return p.decodeMap(fv, path, opts)
+	case reflect.Interface:
+		if fv.NumMethod() > 0 {
+			return p.unsupportedDecodeType(fv, path)
+		}
+		var a any
+		if a, err = p.FieldAny(path...); err != nil {
+			return
+		}
+		if a != nil {
+			fv.Set(reflect.ValueOf(a))
+		}
+	default:
+		return p.unsupportedDecodeType(fv, path)
+	}
+	return nil
+}
+
+func (p *ParsedConfig) decodeInt(path []string, opts string) (int64, error) {
+	if opts == "bytesize" {
+		return p.FieldByteSize(path...)
+	}
+	i, err := p.FieldInt(path...)
+	return int64(i), err
+}
+
+func (p *ParsedConfig) decodeSlice(fv reflect.Value, path []string, opts string) error {
+	var elems []*ParsedConfig
+	if fv.Type().Elem().Kind() == reflect.Struct {
+		var err error
+		if elems, err = p.FieldObjectList(path...); err != nil {
+			return err
+		}
+	} else {
+		l, err := p.FieldAnyList(path...)
+		if err != nil {
+			return err
+		}
+		elems = l
+	}
+
+	sv := reflect.MakeSlice(fv.Type(), len(elems), len(elems))
+	for i, e := range elems {
+		if err := e.decodeField(sv.Index(i), nil, opts); err != nil {
+			return fmt.Errorf("field '%v': %w", p.i.FullDotPath(append(path, strconv.Itoa(i))...), err)
+		}
+	}
+	fv.Set(sv)
+	return nil
+}
+
+func (p *ParsedConfig) decodeMap(fv reflect.Value, path []string, opts string) error {
+	if fv.Type().Key().Kind() != reflect.String {
+		return p.unsupportedDecodeType(fv, path)
+	}
+
+	var elems map[string]*ParsedConfig
+	if fv.Type().Elem().Kind() == reflect.Struct {
+		var err error
+		if elems, err = p.FieldObjectMap(path...); err != nil {
+			return err
+		}
+	} else {
+		m, err := p.FieldAnyMap(path...)
+		if err != nil {
+			return err
+		}
+		elems = m
+	}
+
+	mv := reflect.MakeMapWithSize(fv.Type(), len(elems))
+	for k, e := range elems {
+		ev := reflect.New(fv.Type().Elem()).Elem()
+		if err := e.decodeField(ev, nil, opts); err != nil {
+			return fmt.Errorf("field '%v': %w", p.i.FullDotPath(append(path, k)...), err)
+		}
+		mv.SetMapIndex(reflect.ValueOf(k).Convert(fv.Type().Key()), ev)
+	}
+	fv.Set(mv)
+	return nil
+}
+
+func (p *ParsedConfig) unsupportedDecodeType(fv reflect.Value, path []string) error {
+	return fmt.Errorf("field '%v' cannot be decoded into unsupported type %v", p.i.FullDotPath(path...), fv.Type())
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigDecodeInto(t *testing.T) {
+	type mode string
+
+	type nested struct {
+		Name    string `benthos:"name"`
+		Enabled bool   `benthos:"enabled"`
+	}
+
+	type target struct {
+		Str       string              `benthos:"str"`
+		Mode      mode                `benthos:"mode"`
+		Int       int                 `benthos:"int"`
+		Uint8     uint8               `benthos:"uint8"`
+		Float     float32             `benthos:"float"`
+		Duration  time.Duration       `benthos:"duration"`
+		ByteSize  int64               `benthos:"byte_size,bytesize"`
+		Interp    *InterpolatedString `benthos:"interp"`
+		StrList   []string            `benthos:"str_list"`
+		DurList   []time.Duration     `benthos:"dur_list"`
+		IntMap    map[string]int      `benthos:"int_map"`
+		Nested    nested              `benthos:"nested"`
+		NestedPtr *nested             `benthos:"nested_ptr"`
+		Objects   []nested            `benthos:"objects"`
+		ObjectMap map[string]nested   `benthos:"object_map"`
+		Any       any                 `benthos:"any"`
+		Optional  string              `benthos:"optional"`
+		Ignored   string              `benthos:"-"`
+		Untagged  string
+	}
+
+	spec := NewConfigSpec().
+		Field(NewStringField("str")).
+		Field(NewStringEnumField("mode", "foo", "bar")).
+		Field(NewIntField("int")).
+		Field(NewIntField("uint8")).
+		Field(NewFloatField("float")).
+		Field(NewDurationField("duration")).
+		Field(NewByteSizeField("byte_size")).
+		Field(NewInterpolatedStringField("interp")).
+		Field(NewStringListField("str_list")).
+		Field(NewStringListField("dur_list")).
+		Field(NewIntMapField("int_map")).
+		Field(NewObjectField("nested",
+			NewStringField("name"),
+			NewBoolField("enabled").Default(true),
+		)).
+		Field(NewObjectField("nested_ptr",
+			NewStringField("name"),
+			NewBoolField("enabled").Default(false),
+		)).
+		Field(NewObjectListField("objects",
+			NewStringField("name"),
+			NewBoolField("enabled").Default(false),
+		)).
+		Field(NewObjectMapField("object_map",
+			NewStringField("name"),
+			NewBoolField("enabled").Default(false),
+		)).
+		Field(NewAnyField("any")).
+		Field(NewStringField("optional").Optional())
+
+	parsed, err := spec.ParseYAML(`
+str: hello
+mode: bar
+int: 10
+uint8: 200
+float: 1.5
+duration: 3s
+byte_size: 2KiB
+interp: 'id: ${! counter() }'
+str_list: [ a, b ]
+dur_list: [ 1s, 1m ]
+int_map: { a: 1, b: 2 }
+nested:
+  name: foo
+nested_ptr:
+  name: bar
+  enabled: true
+objects:
+  - name: baz
+  - name: buz
+    enabled: true
+object_map:
+  first:
+    name: qux
+any: { foo: [ 1, 2 ] }
+`, nil)
+	require.NoError(t, err)
+
+	v := target{
+		Optional: "unchanged",
+		Ignored:  "unchanged",
+		Untagged: "unchanged",
+	}
+	require.NoError(t, parsed.DecodeInto(&v))
+
+	assert.Equal(t, "hello", v.Str)
+	assert.Equal(t, mode("bar"), v.Mode)
+	assert.Equal(t, 10, v.Int)
+	assert.Equal(t, uint8(200), v.Uint8)
+	assert.Equal(t, float32(1.5), v.Float)
+	assert.Equal(t, 3*time.Second, v.Duration)
+	assert.Equal(t, int64(2048), v.ByteSize)
+	require.NotNil(t, v.Interp)
+	assert.Equal(t, "id: 1", v.Interp.String(NewMessage(nil)))
+	assert.Equal(t, []string{"a", "b"}, v.StrList)
+	assert.Equal(t, []time.Duration{time.Second, time.Minute}, v.DurList)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, v.IntMap)
+	assert.Equal(t, nested{Name: "foo", Enabled: true}, v.Nested)
+	assert.Equal(t, &nested{Name: "bar", Enabled: true}, v.NestedPtr)
+	assert.Equal(t, []nested{{Name: "baz"}, {Name: "buz", Enabled: true}}, v.Objects)
+	assert.Equal(t, map[string]nested{"first": {Name: "qux"}}, v.ObjectMap)
+	assert.Equal(t, map[string]any{"foo": []any{1, 2}}, v.Any)
+	assert.Equal(t, "unchanged", v.Optional)
+	assert.Equal(t, "unchanged", v.Ignored)
+	assert.Equal(t, "unchanged", v.Untagged)
+
+	var nv nested
+	require.NoError(t, parsed.DecodeInto(&nv, "nested"))
+	assert.Equal(t, nested{Name: "foo", Enabled: true}, nv)
+}
+
+func TestConfigDecodeIntoErrors(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewIntField("a")).
+		Field(NewStringField("b"))
+
+	parsed, err := spec.ParseYAML(`
+a: 300
+b: nope
+`, nil)
+	require.NoError(t, err)
+
+	var s struct {
+		A int `benthos:"a"`
+	}
+	require.Error(t, parsed.DecodeInto(s))
+	require.Error(t, parsed.DecodeInto(nil))
+
+	var overflow struct {
+		A uint8 `benthos:"a"`
+	}
+	err = parsed.DecodeInto(&overflow)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "overflows type uint8")
+
+	var wrongType struct {
+		B time.Duration `benthos:"b"`
+	}
+	require.Error(t, parsed.DecodeInto(&wrongType))
+
+	var unsupported struct {
+		B chan string `benthos:"b"`
+	}
+	err = parsed.DecodeInto(&unsupported)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported type chan string")
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang"
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/field"
+	"github.com/redpanda-data/benthos/v4/internal/docs"
+)
+
+// Placeholders that interpolation functions are substituted with when linting
+// the static portions of an interpolated URL. Neither is valid in every part of
+// a URL (a port must be numeric, a scheme must begin with a letter) and so both
+// are attempted.
+var interpURLPlaceholders = []string{"interpolated", "0"}
+
+func interpolatedURLLintFunc(allowedSchemes []string) docs.LintFunc {
+	return func(ctx docs.LintContext, line, col int, value any) (lints []docs.Lint) {
+		str, ok := value.(string)
+		if !ok || str == "" {
+			return nil
+		}
+
+		// Interpolation functions that fail to parse are reported by the
+		// bloblang linter, so there's nothing further to check here.
+		e, err := bloblang.GlobalEnvironment().NewField(str)
+		if err != nil {
+			return nil
+		}
+
+		var u *url.URL
+		var tmpl string
+		for i, p := range interpURLPlaceholders {
+			tmpl = e.Template(p)
+			var pErr error
+			if u, pErr = url.Parse(tmpl); pErr == nil {
+				break
+			}
+			if i == 0 {
+				err = pErr
+			}
+		}
+		if u == nil {
+			return []docs.Lint{docs.NewLintError(line, docs.LintCustom, fmt.Errorf("failed to parse url: %w", err))}
+		}
+
+		if interpolatedURLStaticScheme(e, tmpl) {
+			if err := checkURLScheme(u, allowedSchemes); err != nil {
+				lints = append(lints, docs.NewLintError(line, docs.LintCustom, err))
+			}
+		}
+		return
+	}
+}
+
+// interpolatedURLStaticScheme returns true if the scheme of an interpolated URL
+// can be determined without resolving it, which is the case when the scheme
+// delimiter precedes the first interpolation function.
+func interpolatedURLStaticScheme(e *field.Expression, tmpl string) bool {
+	if e.NumDynamicExpressions() == 0 {
+		return true
+	}
+	firstDyn := strings.Index(e.Template("\x00"), "\x00")
+	schemeEnd := strings.Index(tmpl, ":")
+	return schemeEnd >= 0 && schemeEnd < firstDyn
+}
+
+// NewInterpolatedURLField defines a new config field that describes a URL that
+// supports Bloblang interpolation functions. The static portions of the URL are
+// validated at lint time, and it is then possible to extract an
+// *InterpolatedURL from the resulting parsed config with the method
+// FieldInterpolatedURL, which resolves and parses the URL for each message.
+//
+// Optionally, a list of allowed schemes (e.g. `https`) can be provided, in
+// which case URLs with any other scheme are rejected at lint time when the
+// scheme is static, by FieldInterpolatedURL when the URL is static, and by
+// TryURL for each resolved URL. Note that the linting rules for URL fields are
+// only effective within the binary that defines them.
+func NewInterpolatedURLField(name string, allowedSchemes ...string) *ConfigField {
+	tf := docs.FieldURL(name, "").IsInterpolated().LinterFunc(interpolatedURLLintFunc(allowedSchemes)).URLSchemes(allowedSchemes...)
+	return &ConfigField{field: tf}
+}
+
+// FieldInterpolatedURL accesses a field from a parsed config that was defined
+// with NewInterpolatedURLField and returns either an *InterpolatedURL or an
+// error if the interpolation functions or a static URL were invalid, or a static
+// URL has a scheme that is not allowed.
+func (p *ParsedConfig) FieldInterpolatedURL(path ...string) (*InterpolatedURL, error) {
+	v, exists := p.i.Field(path...)
+	if !exists {
+		return nil, fmt.Errorf("field '%v' was not found in the config", strings.Join(path, "."))
+	}
+
+	str, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected field '%v' to be a string, got %T", strings.Join(path, "."), v)
+	}
+
+	e, err := p.mgr.BloblEnvironment().NewField(str)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse interpolated field '%v': %v", strings.Join(path, "."), err)
+	}
+
+	i := &InterpolatedURL{expr: e, allowedSchemes: p.allowedURLSchemes(path...)}
+	if e.NumDynamicExpressions() == 0 {
+		if i.static, err = url.Parse(e.Template("")); err != nil {
+			return nil, fmt.Errorf("failed to parse url field '%v': %v", strings.Join(path, "."), err)
+		}
+		if err = checkURLScheme(i.static, i.allowedSchemes); err != nil {
+			return nil, fmt.Errorf("url field '%v': %w", strings.Join(path, "."), err)
+		}
+	}
+	return i, nil
+}
+
+// InterpolatedURL resolves a URL containing dynamic interpolation functions for
+// a given message.
+type InterpolatedURL struct {
+	expr           *field.Expression
+	static         *url.URL
+	allowedSchemes []string
+}
+
+// Static returns the parsed URL only if it contains zero dynamic expressions,
+// and is therefore static, otherwise nil is returned. A second boolean
+// parameter is also returned indicating whether the URL was static.
+func (i *InterpolatedURL) Static() (*url.URL, bool) {
+	if i.static == nil {
+		return nil, false
+	}
+	u := *i.static
+	return &u, true
+}
+
+// TryURL resolves the interpolated URL for a given message, returns an error
+// if any interpolation functions fail, the resolved string is not a valid URL,
+// or the scheme of the resolved URL is not allowed. The returned URL is safe
+// to modify.
+func (i *InterpolatedURL) TryURL(m *Message) (*url.URL, error) {
+	if u, ok := i.Static(); ok {
+		return u, nil
+	}
+	str, err := i.expr.String(0, fauxOldMessage{m.part})
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(str)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse resolved url: %w", err)
+	}
+	if err := checkURLScheme(u, i.allowedSchemes); err != nil {
+		return nil, fmt.Errorf("resolved url: %w", err)
+	}
+	return u, nil
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/bundle"
+	"github.com/redpanda-data/benthos/v4/internal/docs"
+)
+
+func TestInterpolatedURLFieldLints(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewInterpolatedURLField("a", "http", "https"))
+
+	tests := []struct {
+		name  string
+		input string
+		lints []string
+	}{
+		{
+			name:  "static valid",
+			input: `a: https://example.com/foo`,
+		},
+		{
+			name:  "dynamic path",
+			input: `a: 'https://example.com/${! meta("path") }'`,
+		},
+		{
+			name:  "dynamic port",
+			input: `a: 'http://localhost:${! meta("port") }/foo'`,
+		},
+		{
+			name:  "dynamic scheme",
+			input: `a: '${! meta("scheme") }://example.com'`,
+		},
+		{
+			name:  "dynamic url",
+			input: `a: '${! meta("url") }'`,
+		},
+		{
+			name:  "static scheme not allowed",
+			input: `a: 'ftp://example.com/${! meta("path") }'`,
+			lints: []string{"(1,1) url scheme 'ftp' is not allowed, expected one of: http, https"},
+		},
+		{
+			name:  "static portion invalid",
+			input: `a: 'http://exa mple.com/${! meta("path") }'`,
+			lints: []string{`(1,1) failed to parse url: parse "http://exa mple.com/interpolated": invalid character " " in host name`},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			node, err := NewStreamBuilder().getYAMLNode([]byte(test.input))
+			require.NoError(t, err)
+
+			lints := spec.component.Config.Children.LintYAML(docs.NewLintContext(docs.NewLintConfig(bundle.GlobalEnvironment)), node)
+			var lintStrs []string
+			for _, l := range lints {
+				lintStrs = append(lintStrs, l.Error())
+			}
+			assert.Equal(t, test.lints, lintStrs)
+		})
+	}
+}
+
+func TestInterpolatedURLFieldResolve(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewInterpolatedURLField("a")).
+		Field(NewInterpolatedURLField("b"))
+
+	parsed, err := spec.ParseYAML(`
+a: 'https://example.com/${! meta("path") }?id=${! content() }'
+b: 'https://example.com/static'
+`, nil)
+	require.NoError(t, err)
+
+	a, err := parsed.FieldInterpolatedURL("a")
+	require.NoError(t, err)
+
+	_, isStatic := a.Static()
+	assert.False(t, isStatic)
+
+	msg := NewMessage([]byte("foo"))
+	msg.MetaSetMut("path", "bar")
+	u, err := a.TryURL(msg)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", u.Host)
+	assert.Equal(t, "/bar", u.Path)
+	assert.Equal(t, "foo", u.Query().Get("id"))
+
+	msg.MetaSetMut("path", "%zz")
+	_, err = a.TryURL(msg)
+	require.Error(t, err)
+
+	b, err := parsed.FieldInterpolatedURL("b")
+	require.NoError(t, err)
+
+	u, isStatic = b.Static()
+	require.True(t, isStatic)
+	assert.Equal(t, "/static", u.Path)
+
+	u.Path = "/modified"
+	u, err = b.TryURL(NewMessage(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "/static", u.Path)
+}
+
+func TestInterpolatedURLFieldSchemes(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewInterpolatedURLField("a", "http", "https").Optional()).
+		Field(NewInterpolatedURLField("b", "http", "https").Optional())
+
+	parsed, err := spec.ParseYAML(`
+a: '${! @target }'
+b: 'file:///etc/passwd'
+`, nil)
+	require.NoError(t, err)
+
+	// Static URLs with a scheme that isn't allowed are rejected even though
+	// the config was not linted.
+	_, err = parsed.FieldInterpolatedURL("b")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "url scheme 'file' is not allowed, expected one of: http, https")
+
+	a, err := parsed.FieldInterpolatedURL("a")
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		target      string
+		errContains string
+	}{
+		{target: "https://example.com/foo"},
+		{target: "HTTP://example.com/foo"},
+		{target: "file:///etc/passwd", errContains: "url scheme 'file' is not allowed, expected one of: http, https"},
+		{target: "gopher://example.com", errContains: "url scheme 'gopher' is not allowed, expected one of: http, https"},
+	} {
+		msg := NewMessage(nil)
+		msg.MetaSetMut("target", test.target)
+
+		u, err := a.TryURL(msg)
+		if test.errContains == "" {
+			require.NoError(t, err, test.target)
+			assert.Equal(t, "/foo", u.Path)
+		} else {
+			require.Error(t, err, test.target)
+			assert.Contains(t, err.Error(), test.errContains, test.target)
+		}
+	}
+}
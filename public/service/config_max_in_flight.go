@@ -16,3 +16,28 @@ func NewOutputMaxInFlightField() *ConfigField {
 func (p *ParsedConfig) FieldMaxInFlight() (int, error) {
 	return p.FieldInt("max_in_flight")
 }
+
+const outputOrderedAcksField = "ordered_acks"
+
+// NewOutputOrderedAcksField creates a common field for outputs with a max in
+// flight greater than one, which when enabled causes messages to be written
+// concurrently but acknowledged upstream in the order that they were received.
+// Outputs that add this field do not need to parse it, as it is applied
+// automatically by the environment when the output is constructed.
+func NewOutputOrderedAcksField() *ConfigField {
+	return NewBoolField(outputOrderedAcksField).
+		Description("Whether messages written concurrently should be acknowledged in the order that they were received. When enabled a message that is written successfully is not acknowledged until all messages received before it have also been written, which preserves the ordering of acknowledgements for inputs that track contiguous offsets at the cost of holding completed messages in memory.").
+		Advanced().
+		Default(false).
+		Version("4.29.0")
+}
+
+// outputOrderedAcks returns whether a parsed config has a field defined with
+// NewOutputOrderedAcksField that is enabled.
+func outputOrderedAcks(p *ParsedConfig) bool {
+	if !p.Contains(outputOrderedAcksField) {
+		return false
+	}
+	ordered, _ := p.FieldBool(outputOrderedAcksField)
+	return ordered
+}
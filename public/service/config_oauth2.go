@@ -0,0 +1,399 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/redpanda-data/benthos/v4/internal/bundle"
+	"github.com/redpanda-data/benthos/v4/internal/component"
+	"github.com/redpanda-data/benthos/v4/internal/component/cache"
+)
+
+const (
+	ao2FieldEnabled        = "enabled"
+	ao2FieldClientKey      = "client_key"
+	ao2FieldClientSecret   = "client_secret"
+	ao2FieldTokenURL       = "token_url"
+	ao2FieldScopes         = "scopes"
+	ao2FieldEndpointParams = "endpoint_params"
+	ao2FieldFlow           = "flow"
+	ao2FieldRefreshToken   = "refresh_token"
+	ao2FieldDeviceAuthURL  = "device_auth_url"
+	ao2FieldTokenCache     = "token_cache"
+	ao2FieldTokenCacheKey  = "token_cache_key"
+)
+
+const (
+	ao2FlowClientCredentials = "client_credentials"
+	ao2FlowRefreshToken      = "refresh_token"
+	ao2FlowDeviceCode        = "device_code"
+)
+
+// NewOAuth2Field defines a new object type config field that describes OAuth2
+// authentication using either the client credentials, refresh token or device
+// code token flows. It is then possible to extract an oauth2.TokenSource from
+// the resulting parsed config with the method FieldOAuth2TokenSource.
+func NewOAuth2Field(name string) *ConfigField {
+	return NewObjectField(name,
+		NewBoolField(ao2FieldEnabled).
+			Description("Whether to use OAuth version 2 in requests.").
+			Default(false),
+
+		NewStringField(ao2FieldClientKey).
+			Description("A value used to identify the client to the token provider.").
+			Default(""),
+
+		NewStringField(ao2FieldClientSecret).
+			Description("A secret used to establish ownership of the client key.").
+			Default("").Secret(),
+
+		NewURLField(ao2FieldTokenURL).
+			Description("The URL of the token provider.").
+			Default(""),
+
+		NewStringListField(ao2FieldScopes).
+			Description("A list of optional requested permissions.").
+			Default([]any{}).
+			Advanced().
+			Version("3.45.0"),
+
+		NewAnyMapField(ao2FieldEndpointParams).
+			Description("A list of optional endpoint parameters, values should be arrays of strings.").
+			Advanced().
+			Example(map[string]any{
+				"foo": []string{"meow", "quack"},
+				"bar": []string{"woof"},
+			}).
+			Default(map[string]any{}).
+			Version("4.21.0").
+			Optional().
+			LintRule(`
+root = if this.type() == "object" {
+  this.values().map_each(ele -> if ele.type() != "array" {
+    "field must be an object containing arrays of strings, got %s (%v)".format(ele.format_json(no_indent: true), ele.type())
+  } else {
+    ele.map_each(str -> if str.type() != "string" {
+      "field values must be strings, got %s (%v)".format(str.format_json(no_indent: true), str.type())
+    } else { deleted() })
+  }).
+    flatten()
+}
+`),
+
+		NewStringAnnotatedEnumField(ao2FieldFlow, map[string]string{
+			ao2FlowClientCredentials: "Obtain tokens with the client key and secret.",
+			ao2FlowRefreshToken:      "Obtain tokens by exchanging a refresh token, which is taken from `refresh_token` until a newer one has been issued by the token provider.",
+			ao2FlowDeviceCode:        "Obtain an initial token by authorizing the device, where the URL to visit and the code to enter are logged, and thereafter obtain tokens by exchanging the issued refresh token.",
+		}).
+			Description("The token flow used to obtain access tokens.").
+			Advanced().
+			Default(ao2FlowClientCredentials).
+			Version("4.29.0"),
+
+		NewStringField(ao2FieldRefreshToken).
+			Description("A refresh token used to obtain access tokens when the `flow` is `refresh_token`. This is only used when there isn't already a token stored within the `token_cache`.").
+			Default("").
+			Secret().
+			Advanced().
+			Version("4.29.0"),
+
+		NewURLField(ao2FieldDeviceAuthURL).
+			Description("The URL of the device authorization endpoint, required when the `flow` is `device_code`.").
+			Default("").
+			Advanced().
+			Version("4.29.0"),
+
+		NewStringField(ao2FieldTokenCache).
+			Description("An optional xref:components:caches/about.adoc[cache resource] used to persist tokens, allowing refresh tokens issued by the token provider to survive restarts. Tokens are stored in plain text and therefore the cache should be treated as a secret.").
+			Advanced().
+			Optional().
+			Version("4.29.0"),
+
+		NewStringField(ao2FieldTokenCacheKey).
+			Description("The key under which tokens are stored within the `token_cache`.").
+			Default("benthos_oauth2_token").
+			Advanced().
+			Version("4.29.0"),
+	).
+		Description("Allows you to specify open authentication via OAuth version 2 using either the client credentials, refresh token or device code token flows.").
+		Optional().Advanced()
+}
+
+// FieldOAuth2TokenSource accesses a field from a parsed config that was defined
+// with NewOAuth2Field and returns an oauth2.TokenSource that caches tokens and
+// refreshes them from the token provider when they expire. If OAuth2 is not
+// enabled, or the field is absent, then a nil token source is returned.
+//
+// When a token cache is configured the token is restored from the cache when
+// the token source is first used, and each newly issued token is written back
+// to it.
+//
+// The provided context is used for all token requests made by the token
+// source, and a custom *http.Client for those requests can be provided with
+// the context key oauth2.HTTPClient.
+func (p *ParsedConfig) FieldOAuth2TokenSource(ctx context.Context, path ...string) (oauth2.TokenSource, error) {
+	if !p.Contains(path...) {
+		return nil, nil
+	}
+
+	enabled, err := p.FieldBool(append(path, ao2FieldEnabled)...)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	flow, err := p.FieldString(append(path, ao2FieldFlow)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var clientID, clientSecret, tokenURL string
+	if clientID, err = p.FieldString(append(path, ao2FieldClientKey)...); err != nil {
+		return nil, err
+	}
+	if clientSecret, err = p.FieldString(append(path, ao2FieldClientSecret)...); err != nil {
+		return nil, err
+	}
+	if tokenURL, err = p.FieldString(append(path, ao2FieldTokenURL)...); err != nil {
+		return nil, err
+	}
+
+	scopes, err := p.FieldStringList(append(path, ao2FieldScopes)...)
+	if err != nil {
+		return nil, err
+	}
+
+	endpointParams := map[string][]string{}
+	endpointParamsConf, err := p.FieldAnyMap(append(path, ao2FieldEndpointParams)...)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range endpointParamsConf {
+		if endpointParams[k], err = v.FieldStringList(); err != nil {
+			return nil, err
+		}
+	}
+
+	var newSource func(initial *oauth2.Token) oauth2.TokenSource
+	switch flow {
+	case ao2FlowClientCredentials:
+		conf := &clientcredentials.Config{
+			ClientID:       clientID,
+			ClientSecret:   clientSecret,
+			TokenURL:       tokenURL,
+			Scopes:         scopes,
+			EndpointParams: endpointParams,
+		}
+		newSource = func(initial *oauth2.Token) oauth2.TokenSource {
+			// The client credentials token source already caches the token
+			// until it expires.
+			if initial == nil {
+				return conf.TokenSource(ctx)
+			}
+			return oauth2.ReuseTokenSource(initial, conf.TokenSource(ctx))
+		}
+	case ao2FlowRefreshToken:
+		refreshToken, err := p.FieldString(append(path, ao2FieldRefreshToken)...)
+		if err != nil {
+			return nil, err
+		}
+		if refreshToken == "" && !p.Contains(append(path, ao2FieldTokenCache)...) {
+			return nil, fmt.Errorf("either a %v or %v is required with the %v flow", ao2FieldRefreshToken, ao2FieldTokenCache, ao2FlowRefreshToken)
+		}
+		conf := &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Endpoint:     oauth2.Endpoint{TokenURL: tokenURL},
+			Scopes:       scopes,
+		}
+		newSource = func(initial *oauth2.Token) oauth2.TokenSource {
+			if initial == nil {
+				initial = &oauth2.Token{RefreshToken: refreshToken}
+			}
+			return conf.TokenSource(ctx, initial)
+		}
+	case ao2FlowDeviceCode:
+		deviceAuthURL, err := p.FieldString(append(path, ao2FieldDeviceAuthURL)...)
+		if err != nil {
+			return nil, err
+		}
+		if deviceAuthURL == "" {
+			return nil, fmt.Errorf("a %v is required with the %v flow", ao2FieldDeviceAuthURL, ao2FlowDeviceCode)
+		}
+		conf := &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Endpoint: oauth2.Endpoint{
+				TokenURL:      tokenURL,
+				DeviceAuthURL: deviceAuthURL,
+			},
+			Scopes: scopes,
+		}
+		var opts []oauth2.AuthCodeOption
+		for k, vs := range endpointParams {
+			for _, v := range vs {
+				opts = append(opts, oauth2.SetAuthURLParam(k, v))
+			}
+		}
+		newSource = func(initial *oauth2.Token) oauth2.TokenSource {
+			d := &oauth2DeviceTokenSource{
+				ctx:  ctx,
+				conf: conf,
+				opts: opts,
+				mgr:  p.mgr,
+			}
+			if initial != nil {
+				d.refresher = conf.TokenSource(ctx, initial)
+			}
+			return d
+		}
+	default:
+		return nil, fmt.Errorf("unrecognised oauth2 flow: %v", flow)
+	}
+
+	if !p.Contains(append(path, ao2FieldTokenCache)...) {
+		return newSource(nil), nil
+	}
+
+	cacheName, err := p.FieldString(append(path, ao2FieldTokenCache)...)
+	if err != nil {
+		return nil, err
+	}
+	if !p.mgr.ProbeCache(cacheName) {
+		return nil, fmt.Errorf("cache resource '%v' was not found", cacheName)
+	}
+	cacheKey, err := p.FieldString(append(path, ao2FieldTokenCacheKey)...)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2PersistedTokenSource{
+		ctx:       ctx,
+		mgr:       p.mgr,
+		cacheName: cacheName,
+		cacheKey:  cacheKey,
+		newSource: newSource,
+	}, nil
+}
+
+// oauth2DeviceTokenSource obtains an initial token by authorizing the device,
+// which blocks until the user has completed the authorization, and thereafter
+// refreshes that token.
+type oauth2DeviceTokenSource struct {
+	ctx  context.Context
+	conf *oauth2.Config
+	opts []oauth2.AuthCodeOption
+	mgr  bundle.NewManagement
+
+	mut       sync.Mutex
+	refresher oauth2.TokenSource
+}
+
+func (d *oauth2DeviceTokenSource) Token() (*oauth2.Token, error) {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	if d.refresher != nil {
+		return d.refresher.Token()
+	}
+
+	res, err := d.conf.DeviceAuth(d.ctx, d.opts...)
+	if err != nil {
+		return nil, err
+	}
+	if res.VerificationURIComplete != "" {
+		d.mgr.Logger().Warn("OAuth2 device authorization required, visit %v to authorize", res.VerificationURIComplete)
+	} else {
+		d.mgr.Logger().Warn("OAuth2 device authorization required, visit %v and enter the code %v to authorize", res.VerificationURI, res.UserCode)
+	}
+
+	tok, err := d.conf.DeviceAccessToken(d.ctx, res, d.opts...)
+	if err != nil {
+		return nil, err
+	}
+	d.refresher = d.conf.TokenSource(d.ctx, tok)
+	return tok, nil
+}
+
+// oauth2PersistedTokenSource restores a token from a cache resource when first
+// used, and writes tokens back to the cache each time a new one is issued.
+type oauth2PersistedTokenSource struct {
+	ctx       context.Context
+	mgr       bundle.NewManagement
+	cacheName string
+	cacheKey  string
+	newSource func(initial *oauth2.Token) oauth2.TokenSource
+
+	mut    sync.Mutex
+	source oauth2.TokenSource
+	stored string
+}
+
+func (s *oauth2PersistedTokenSource) load() (*oauth2.Token, error) {
+	var tokBytes []byte
+	var err error
+	if cerr := s.mgr.AccessCache(s.ctx, s.cacheName, func(c cache.V1) {
+		tokBytes, err = c.Get(s.ctx, s.cacheKey)
+	}); cerr != nil {
+		return nil, cerr
+	}
+	if errors.Is(err, component.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var tok oauth2.Token
+	if err := json.Unmarshal(tokBytes, &tok); err != nil {
+		return nil, err
+	}
+	return &tok, nil
+}
+
+func (s *oauth2PersistedTokenSource) store(tok *oauth2.Token) error {
+	tokBytes, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	if cerr := s.mgr.AccessCache(s.ctx, s.cacheName, func(c cache.V1) {
+		err = c.Set(s.ctx, s.cacheKey, tokBytes, nil)
+	}); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+func (s *oauth2PersistedTokenSource) Token() (*oauth2.Token, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.source == nil {
+		initial, err := s.load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token from cache: %w", err)
+		}
+		if initial != nil {
+			s.stored = initial.AccessToken
+		}
+		s.source = s.newSource(initial)
+	}
+
+	tok, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok.AccessToken != s.stored {
+		// Failing to persist a token shouldn't prevent it from being used.
+		if err := s.store(tok); err != nil {
+			s.mgr.Logger().Error("Failed to write token to cache: %v", err)
+		} else {
+			s.stored = tok.AccessToken
+		}
+	}
+	return tok, nil
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/docs"
+	"github.com/redpanda-data/benthos/v4/internal/manager/mock"
+)
+
+func TestConfigOAuth2TokenSource(t *testing.T) {
+	var reqs int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&reqs, 1)
+
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.Form.Get("grant_type"))
+		assert.Equal(t, "foo bar", r.Form.Get("scope"))
+		assert.Equal(t, "meow", r.Form.Get("audience"))
+
+		user, pass, _ := r.BasicAuth()
+		assert.Equal(t, "key", user)
+		assert.Equal(t, "secret", pass)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": fmt.Sprintf("token%v", n),
+			"token_type":   "bearer",
+			"expires_in":   3600,
+		})
+	}))
+	t.Cleanup(tokenServer.Close)
+
+	spec := NewConfigSpec().
+		Field(NewOAuth2Field("a")).
+		Field(NewOAuth2Field("b"))
+
+	parsedConfig, err := spec.ParseYAML(`
+a:
+  enabled: true
+  client_key: key
+  client_secret: secret
+  token_url: `+tokenServer.URL+`
+  scopes: [ foo, bar ]
+  endpoint_params:
+    audience: [ meow ]
+`, nil)
+	require.NoError(t, err)
+
+	ts, err := parsedConfig.FieldOAuth2TokenSource(context.Background(), "a")
+	require.NoError(t, err)
+	require.NotNil(t, ts)
+
+	for i := 0; i < 3; i++ {
+		tok, err := ts.Token()
+		require.NoError(t, err)
+		assert.Equal(t, "token1", tok.AccessToken)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&reqs))
+
+	ts, err = parsedConfig.FieldOAuth2TokenSource(context.Background(), "b")
+	require.NoError(t, err)
+	assert.Nil(t, ts)
+
+	ts, err = parsedConfig.FieldOAuth2TokenSource(context.Background(), "c")
+	require.NoError(t, err)
+	assert.Nil(t, ts)
+}
+
+func TestConfigOAuth2RefreshTokenFlowPersisted(t *testing.T) {
+	var reqs int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&reqs, 1)
+
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh_token", r.Form.Get("grant_type"))
+		assert.Equal(t, fmt.Sprintf("refresh%v", n-1), r.Form.Get("refresh_token"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  fmt.Sprintf("token%v", n),
+			"refresh_token": fmt.Sprintf("refresh%v", n),
+			"token_type":    "bearer",
+			"expires_in":    1,
+		})
+	}))
+	t.Cleanup(tokenServer.Close)
+
+	spec := NewConfigSpec().Field(NewOAuth2Field("a"))
+	res := MockResources(MockResourcesOptAddCache("foo"))
+
+	node, err := docs.UnmarshalYAML([]byte(`
+a:
+  enabled: true
+  client_key: key
+  client_secret: secret
+  token_url: ` + tokenServer.URL + `
+  flow: refresh_token
+  refresh_token: refresh0
+  token_cache: foo
+`))
+	require.NoError(t, err)
+
+	parsedConfig, err := spec.configFromAny(res.mgr, node)
+	require.NoError(t, err)
+
+	ts, err := parsedConfig.FieldOAuth2TokenSource(context.Background(), "a")
+	require.NoError(t, err)
+	require.NotNil(t, ts)
+
+	tok, err := ts.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "token1", tok.AccessToken)
+
+	assert.Contains(t, string(res.mgr.(*mock.Manager).Caches["foo"]["benthos_oauth2_token"].Value), `"refresh_token":"refresh1"`)
+
+	// A new token source resumes from the persisted refresh token rather
+	// than the configured one, and refreshes it once it has expired.
+	ts, err = parsedConfig.FieldOAuth2TokenSource(context.Background(), "a")
+	require.NoError(t, err)
+
+	tok, err = ts.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "token2", tok.AccessToken)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&reqs))
+
+	assert.Contains(t, string(res.mgr.(*mock.Manager).Caches["foo"]["benthos_oauth2_token"].Value), `"refresh_token":"refresh2"`)
+}
+
+func TestConfigOAuth2DeviceCodeFlow(t *testing.T) {
+	var authReqs, tokenReqs int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&authReqs, 1)
+
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "key", r.Form.Get("client_id"))
+		assert.Equal(t, "meow", r.Form.Get("audience"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"device_code":      "devicecode",
+			"user_code":        "usercode",
+			"verification_uri": "http://example.com/device",
+			"expires_in":       60,
+			"interval":         1,
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&tokenReqs, 1)
+
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:device_code", r.Form.Get("grant_type"))
+		assert.Equal(t, "devicecode", r.Form.Get("device_code"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  fmt.Sprintf("token%v", n),
+			"refresh_token": "refresh",
+			"token_type":    "bearer",
+			"expires_in":    3600,
+		})
+	})
+	tokenServer := httptest.NewServer(mux)
+	t.Cleanup(tokenServer.Close)
+
+	spec := NewConfigSpec().Field(NewOAuth2Field("a"))
+
+	parsedConfig, err := spec.ParseYAML(`
+a:
+  enabled: true
+  client_key: key
+  token_url: `+tokenServer.URL+`/token
+  device_auth_url: `+tokenServer.URL+`/device
+  flow: device_code
+  endpoint_params:
+    audience: [ meow ]
+`, nil)
+	require.NoError(t, err)
+
+	ts, err := parsedConfig.FieldOAuth2TokenSource(context.Background(), "a")
+	require.NoError(t, err)
+	require.NotNil(t, ts)
+
+	for i := 0; i < 3; i++ {
+		tok, err := ts.Token()
+		require.NoError(t, err)
+		assert.Equal(t, "token1", tok.AccessToken)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&authReqs))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&tokenReqs))
+}
+
+func TestConfigOAuth2BadFlows(t *testing.T) {
+	spec := NewConfigSpec().Field(NewOAuth2Field("a"))
+
+	for _, test := range []struct {
+		name        string
+		config      string
+		errContains string
+	}{
+		{
+			name: "refresh token missing",
+			config: `
+a:
+  enabled: true
+  flow: refresh_token
+`,
+			errContains: "either a refresh_token or token_cache is required",
+		},
+		{
+			name: "device auth url missing",
+			config: `
+a:
+  enabled: true
+  flow: device_code
+`,
+			errContains: "a device_auth_url is required",
+		},
+		{
+			name: "cache missing",
+			config: `
+a:
+  enabled: true
+  token_cache: nope
+`,
+			errContains: "cache resource 'nope' was not found",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			parsedConfig, err := spec.ParseYAML(test.config, nil)
+			require.NoError(t, err)
+
+			_, err = parsedConfig.FieldOAuth2TokenSource(context.Background(), "a")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.errContains)
+		})
+	}
+}
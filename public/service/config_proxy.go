@@ -0,0 +1,244 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+)
+
+const (
+	pxFieldURL      = "url"
+	pxFieldNoProxy  = "no_proxy"
+	pxFieldUsername = "username"
+	pxFieldPassword = "password"
+)
+
+// NewProxyField defines a new object type config field that describes the
+// settings of an HTTP or SOCKS5 proxy to be used by networked components. It is
+// then possible to extract an *http.Transport from the resulting parsed config
+// with the method FieldProxyTransport, or a dial func for arbitrary network
+// connections with the method FieldProxyDialer.
+func NewProxyField(name string) *ConfigField {
+	return NewObjectField(name,
+		NewStringField(pxFieldURL).
+			Description("The URL of a proxy to route connections through, with the scheme `http`, `https`, `socks5` or `socks5h`. When empty connections are not routed through a proxy.").
+			Examples("http://localhost:3128", "socks5://localhost:1080").
+			LintRule(`root = if this != "" && !this.re_match("^(?i)(https?|socks5h?)://") { [ "proxy url must begin with one of the schemes http, https, socks5 or socks5h" ] }`).
+			Default(""),
+		NewStringListField(pxFieldNoProxy).
+			Description("A list of hosts that should be connected to directly, bypassing the proxy. Each entry can be a host name, a domain suffix such as `.example.com`, an IP address or a CIDR range, optionally with a port. Connections to localhost and loopback addresses always bypass the proxy.").
+			Example([]string{"internal.example.com", ".svc.cluster.local", "10.0.0.0/8"}).
+			Default([]any{}),
+		NewStringField(pxFieldUsername).
+			Description("An optional username for authenticating with the proxy.").
+			Default(""),
+		NewStringField(pxFieldPassword).
+			Description("An optional password for authenticating with the proxy.").
+			Default("").
+			Secret(),
+	).Description("Route connections through an HTTP or SOCKS5 proxy.").Advanced()
+}
+
+type proxyConfig struct {
+	u         *url.URL
+	proxyFunc func(*url.URL) (*url.URL, error)
+}
+
+func (p *ParsedConfig) fieldProxyConfig(path ...string) (*proxyConfig, error) {
+	urlStr, err := p.FieldString(append(path, pxFieldURL)...)
+	if err != nil {
+		return nil, err
+	}
+	if urlStr == "" {
+		return nil, nil
+	}
+
+	pc := &proxyConfig{}
+	if pc.u, err = url.Parse(urlStr); err != nil {
+		return nil, fmt.Errorf("failed to parse proxy url: %w", err)
+	}
+	switch pc.u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("proxy url scheme '%v' is not supported", pc.u.Scheme)
+	}
+
+	username, err := p.FieldString(append(path, pxFieldUsername)...)
+	if err != nil {
+		return nil, err
+	}
+	password, err := p.FieldString(append(path, pxFieldPassword)...)
+	if err != nil {
+		return nil, err
+	}
+	if username != "" || password != "" {
+		pc.u.User = url.UserPassword(username, password)
+	}
+
+	noProxy, err := p.FieldStringList(append(path, pxFieldNoProxy)...)
+	if err != nil {
+		return nil, err
+	}
+
+	pc.proxyFunc = (&httpproxy.Config{
+		HTTPProxy:  pc.u.String(),
+		HTTPSProxy: pc.u.String(),
+		NoProxy:    strings.Join(noProxy, ","),
+	}).ProxyFunc()
+	return pc, nil
+}
+
+// FieldProxyTransport accesses a field from a parsed config that was defined
+// with NewProxyField and returns an *http.Transport, cloned from the default
+// transport, that routes requests through the configured proxy. If a proxy URL
+// has not been configured then the transport is returned unmodified.
+func (p *ParsedConfig) FieldProxyTransport(path ...string) (*http.Transport, error) {
+	pc, err := p.fieldProxyConfig(path...)
+	if err != nil {
+		return nil, err
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if pc != nil {
+		t.Proxy = func(r *http.Request) (*url.URL, error) {
+			return pc.proxyFunc(r.URL)
+		}
+	}
+	return t, nil
+}
+
+// FieldProxyDialer accesses a field from a parsed config that was defined with
+// NewProxyField and returns a func for establishing network connections
+// through the configured proxy, where `http` and `https` proxies are tunnelled
+// through with the CONNECT method. If a proxy URL has not been configured then
+// connections are established directly.
+func (p *ParsedConfig) FieldProxyDialer(path ...string) (func(ctx context.Context, network, addr string) (net.Conn, error), error) {
+	pc, err := p.fieldProxyConfig(path...)
+	if err != nil {
+		return nil, err
+	}
+
+	direct := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if pc == nil {
+		return direct.DialContext, nil
+	}
+
+	var proxied func(ctx context.Context, network, addr string) (net.Conn, error)
+	switch pc.u.Scheme {
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if pc.u.User != nil {
+			auth = &proxy.Auth{User: pc.u.User.Username()}
+			auth.Password, _ = pc.u.User.Password()
+		}
+		d, err := proxy.SOCKS5("tcp", pc.u.Host, auth, direct)
+		if err != nil {
+			return nil, err
+		}
+		proxied = d.(proxy.ContextDialer).DialContext
+	default:
+		proxied = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialHTTPConnect(ctx, direct, pc.u, addr)
+		}
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		// The no_proxy rules are expressed in terms of URLs and so we check
+		// against a URL made of the target address.
+		pURL, err := pc.proxyFunc(&url.URL{Scheme: "https", Host: addr})
+		if err != nil {
+			return nil, err
+		}
+		if pURL == nil {
+			return direct.DialContext(ctx, network, addr)
+		}
+		return proxied(ctx, network, addr)
+	}, nil
+}
+
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (b *bufferedConn) Read(p []byte) (int, error) {
+	return b.r.Read(p)
+}
+
+func dialHTTPConnect(ctx context.Context, d *net.Dialer, proxyURL *url.URL, addr string) (net.Conn, error) {
+	proxyAddr := proxyURL.Host
+	if proxyURL.Port() == "" {
+		if proxyURL.Scheme == "https" {
+			proxyAddr = net.JoinHostPort(proxyURL.Hostname(), "443")
+		} else {
+			proxyAddr = net.JoinHostPort(proxyURL.Hostname(), "80")
+		}
+	}
+
+	conn, err := d.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{
+			ServerName: proxyURL.Hostname(),
+			MinVersion: tls.VersionTLS12,
+		})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer func() {
+			_ = conn.SetDeadline(time.Time{})
+		}()
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		_ = res.Body.Close()
+		conn.Close()
+		return nil, fmt.Errorf("proxy responded to CONNECT with status: %v", res.Status)
+	}
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigProxyTransport(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewProxyField("a")).
+		Field(NewProxyField("b"))
+
+	parsedConfig, err := spec.ParseYAML(`
+a:
+  url: http://proxy.example.com:3128
+  no_proxy: [ .internal.example.com ]
+  username: foo
+  password: bar
+`, nil)
+	require.NoError(t, err)
+
+	tr, err := parsedConfig.FieldProxyTransport("a")
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "https://www.example.com/foo", http.NoBody)
+	require.NoError(t, err)
+
+	pURL, err := tr.Proxy(req)
+	require.NoError(t, err)
+	require.NotNil(t, pURL)
+	assert.Equal(t, "proxy.example.com:3128", pURL.Host)
+	assert.Equal(t, "foo", pURL.User.Username())
+
+	req, err = http.NewRequest(http.MethodGet, "https://foo.internal.example.com/foo", http.NoBody)
+	require.NoError(t, err)
+
+	pURL, err = tr.Proxy(req)
+	require.NoError(t, err)
+	assert.Nil(t, pURL)
+
+	_, err = parsedConfig.FieldProxyTransport("b")
+	require.NoError(t, err)
+
+	_, err = parsedConfig.FieldProxyTransport("c")
+	require.Error(t, err)
+}
+
+func TestConfigProxyDialerHTTPConnect(t *testing.T) {
+	echoListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = echoListener.Close()
+	})
+	go func() {
+		for {
+			conn, err := echoListener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _ = io.Copy(conn, conn)
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	var connectHost, proxyAuth string
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connectHost = r.Host
+		proxyAuth = r.Header.Get("Proxy-Authorization")
+
+		target, err := net.Dial("tcp", echoListener.Addr().String())
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+
+		conn, brw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		go func() {
+			_, _ = io.Copy(target, brw)
+		}()
+		_, _ = io.Copy(conn, target)
+		_ = conn.Close()
+	}))
+	t.Cleanup(proxyServer.Close)
+
+	proxyURL, err := url.Parse(proxyServer.URL)
+	require.NoError(t, err)
+
+	spec := NewConfigSpec().
+		Field(NewProxyField("a"))
+
+	parsedConfig, err := spec.ParseYAML(`
+a:
+  url: http://`+proxyURL.Host+`
+  username: foo
+  password: bar
+`, nil)
+	require.NoError(t, err)
+
+	dialFn, err := parsedConfig.FieldProxyDialer("a")
+	require.NoError(t, err)
+
+	conn, err := dialFn(context.Background(), "tcp", "target.example.com:1234")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	_, err = conn.Write([]byte("hello world\n"))
+	require.NoError(t, err)
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "hello world\n", line)
+
+	assert.Equal(t, "target.example.com:1234", connectHost)
+	assert.Equal(t, "Basic Zm9vOmJhcg==", proxyAuth)
+}
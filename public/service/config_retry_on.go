@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"regexp"
+	"syscall"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	"github.com/redpanda-data/benthos/v4/internal/docs"
+)
+
+const (
+	roFieldStatusCodes  = "status_codes"
+	roFieldErrors       = "errors"
+	roFieldErrorClasses = "error_classes"
+)
+
+const (
+	retryClassTimeout    = "timeout"
+	retryClassConnection = "connection"
+	retryClassExit       = "exit"
+)
+
+func retryOnRegexpLintFunc(ctx docs.LintContext, line, col int, value any) []docs.Lint {
+	str, ok := value.(string)
+	if !ok {
+		return nil
+	}
+	if _, err := regexp.Compile(str); err != nil {
+		return []docs.Lint{docs.NewLintError(line, docs.LintCustom, fmt.Errorf("failed to compile error pattern: %w", err))}
+	}
+	return nil
+}
+
+// NewRetryOnField defines a new object type config field that describes which
+// failed attempts of a connector should be retried, in terms of response
+// status codes, patterns matched against error messages, and broad classes of
+// errors. It is then possible to extract a *RetryClassifier from the resulting
+// parsed config with the method FieldRetryOn, which is intended to be used in
+// combination with a back off policy from a field such as NewBackOffPolicyField.
+func NewRetryOnField(name string) *ConfigField {
+	errorsField := NewStringListField(roFieldErrors).
+		Description("A list of regular expression patterns, where errors with a message that matches any of them are retried.").
+		Example([]string{"(?i)too many requests", "^broken pipe$"}).
+		Default([]any{})
+	errorsField.field = errorsField.field.LinterFunc(retryOnRegexpLintFunc)
+
+	return NewObjectField(name,
+		NewIntListField(roFieldStatusCodes).
+			Description("A list of response status codes that should be retried, regardless of whether the attempt also resulted in an error.").
+			Example([]int{429, 502, 503}).
+			Default([]any{}),
+		errorsField,
+		NewStringListField(roFieldErrorClasses).
+			Description("A list of classes of errors that should be retried. Options are `"+retryClassTimeout+"` (the attempt timed out), `"+retryClassConnection+"` (the connection could not be established or was lost) and `"+retryClassExit+"` (a subprocess exited with a non-zero status).").
+			Example([]string{retryClassTimeout, retryClassConnection}).
+			Default([]any{}).
+			LintRule(`root = if this.type() == "string" && !["`+retryClassTimeout+`","`+retryClassConnection+`","`+retryClassExit+`"].contains(this) { [ "error class %v is not recognised".format(this) ] }`),
+	).
+		Description("Determine which failed attempts should be retried. When neither `" + roFieldErrors + "` nor `" + roFieldErrorClasses + "` are set all errors are retried.").
+		Advanced()
+}
+
+// RetryClassifier determines whether a failed attempt made by a connector
+// should be retried, and is extracted from a field defined with
+// NewRetryOnField.
+type RetryClassifier struct {
+	statusCodes map[int]struct{}
+	patterns    []*regexp.Regexp
+	classes     []string
+}
+
+// FieldRetryOn accesses a field from a parsed config that was defined with
+// NewRetryOnField and returns a *RetryClassifier, or an error if the
+// configuration was invalid.
+func (p *ParsedConfig) FieldRetryOn(path ...string) (*RetryClassifier, error) {
+	r := &RetryClassifier{
+		statusCodes: map[int]struct{}{},
+	}
+
+	codes, err := p.FieldIntList(append(path, roFieldStatusCodes)...)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range codes {
+		r.statusCodes[c] = struct{}{}
+	}
+
+	patterns, err := p.FieldStringList(append(path, roFieldErrors)...)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range patterns {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile error pattern '%v': %w", s, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+
+	if r.classes, err = p.FieldStringList(append(path, roFieldErrorClasses)...); err != nil {
+		return nil, err
+	}
+	for _, c := range r.classes {
+		switch c {
+		case retryClassTimeout, retryClassConnection, retryClassExit:
+		default:
+			return nil, fmt.Errorf("error class '%v' was not recognised", c)
+		}
+	}
+	return r, nil
+}
+
+// ShouldRetry returns true if an attempt that resulted in the provided error
+// and response status code should be retried. A status code of zero indicates
+// that no response was received, and a nil error indicates that the attempt
+// was otherwise successful.
+func (r *RetryClassifier) ShouldRetry(err error, statusCode int) bool {
+	if _, exists := r.statusCodes[statusCode]; exists && statusCode != 0 {
+		return true
+	}
+	if err == nil {
+		return false
+	}
+	if len(r.patterns) == 0 && len(r.classes) == 0 {
+		return true
+	}
+	for _, c := range r.classes {
+		if errorOfRetryClass(err, c) {
+			return true
+		}
+	}
+	for _, re := range r.patterns {
+		if re.MatchString(err.Error()) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextRetry determines whether an attempt that resulted in the provided error
+// and response status code should be retried and, if so, returns the period to
+// wait before doing so as given by the provided back off policy. False is
+// returned when the attempt should not be retried, or when the back off policy
+// has been exhausted.
+func (r *RetryClassifier) NextRetry(err error, statusCode int, boff backoff.BackOff) (time.Duration, bool) {
+	if !r.ShouldRetry(err, statusCode) {
+		return 0, false
+	}
+	next := boff.NextBackOff()
+	if next == backoff.Stop {
+		return 0, false
+	}
+	return next, true
+}
+
+func errorOfRetryClass(err error, class string) bool {
+	switch class {
+	case retryClassTimeout:
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+			return true
+		}
+		var nErr net.Error
+		return errors.As(err, &nErr) && nErr.Timeout()
+	case retryClassConnection:
+		if errors.Is(err, ErrNotConnected) ||
+			errors.Is(err, io.EOF) ||
+			errors.Is(err, io.ErrUnexpectedEOF) ||
+			errors.Is(err, net.ErrClosed) ||
+			errors.Is(err, syscall.ECONNREFUSED) ||
+			errors.Is(err, syscall.ECONNRESET) ||
+			errors.Is(err, syscall.ECONNABORTED) ||
+			errors.Is(err, syscall.EPIPE) {
+			return true
+		}
+		var opErr *net.OpError
+		return errors.As(err, &opErr) && opErr.Op == "dial"
+	case retryClassExit:
+		var exitErr *exec.ExitError
+		return errors.As(err, &exitErr)
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/bundle"
+	"github.com/redpanda-data/benthos/v4/internal/docs"
+)
+
+func TestConfigRetryOn(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewRetryOnField("a")).
+		Field(NewRetryOnField("b"))
+
+	parsedConfig, err := spec.ParseYAML(`
+a:
+  status_codes: [ 429, 503 ]
+  errors: [ '(?i)too many requests' ]
+  error_classes: [ timeout, connection ]
+`, nil)
+	require.NoError(t, err)
+
+	a, err := parsedConfig.FieldRetryOn("a")
+	require.NoError(t, err)
+
+	assert.True(t, a.ShouldRetry(nil, 429))
+	assert.True(t, a.ShouldRetry(errors.New("bad request"), 503))
+	assert.False(t, a.ShouldRetry(nil, 200))
+	assert.False(t, a.ShouldRetry(nil, 0))
+	assert.False(t, a.ShouldRetry(errors.New("bad request"), 400))
+	assert.True(t, a.ShouldRetry(errors.New("Too Many Requests"), 0))
+	assert.True(t, a.ShouldRetry(fmt.Errorf("write failed: %w", io.EOF), 0))
+	assert.True(t, a.ShouldRetry(ErrNotConnected, 0))
+	assert.True(t, a.ShouldRetry(context.DeadlineExceeded, 0))
+	assert.True(t, a.ShouldRetry(&net.OpError{Op: "dial", Err: errors.New("nope")}, 0))
+
+	b, err := parsedConfig.FieldRetryOn("b")
+	require.NoError(t, err)
+
+	assert.True(t, b.ShouldRetry(errors.New("anything"), 0))
+	assert.False(t, b.ShouldRetry(nil, 503))
+}
+
+func TestConfigRetryOnNextRetry(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewRetryOnField("a")).
+		Field(NewBackOffPolicyField("b", false, nil))
+
+	parsedConfig, err := spec.ParseYAML(`
+a:
+  error_classes: [ connection ]
+b:
+  strategy: constant
+  initial_interval: 1s
+  randomization_factor: 0
+`, nil)
+	require.NoError(t, err)
+
+	r, err := parsedConfig.FieldRetryOn("a")
+	require.NoError(t, err)
+
+	boff, err := parsedConfig.FieldBackOffPolicy("b")
+	require.NoError(t, err)
+
+	next, retry := r.NextRetry(io.EOF, 0, boff)
+	assert.True(t, retry)
+	assert.Equal(t, time.Second, next)
+
+	_, retry = r.NextRetry(errors.New("nope"), 0, boff)
+	assert.False(t, retry)
+
+	_, retry = r.NextRetry(io.EOF, 0, &backoff.StopBackOff{})
+	assert.False(t, retry)
+}
+
+func TestConfigRetryOnLints(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewRetryOnField("a"))
+
+	node, err := NewStreamBuilder().getYAMLNode([]byte(`
+a:
+  errors: [ 'foo(' ]
+  error_classes: [ timeout, meow ]
+`))
+	require.NoError(t, err)
+
+	lints := spec.component.Config.Children.LintYAML(docs.NewLintContext(docs.NewLintConfig(bundle.GlobalEnvironment)), node)
+	var lintStrs []string
+	for _, l := range lints {
+		lintStrs = append(lintStrs, l.Error())
+	}
+	assert.Equal(t, []string{
+		"(3,1) failed to compile error pattern: error parsing regexp: missing closing ): `foo(`",
+		"(4,1) error class meow is not recognised",
+	}, lintStrs)
+}
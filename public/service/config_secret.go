@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/redpanda-data/benthos/v4/internal/docs"
+	"github.com/redpanda-data/benthos/v4/internal/filepath/ifs"
+)
+
+// SecretProviderFunc resolves the value of a secret from a reference, where the
+// reference is the contents of a secret field following the `<scheme>://`
+// prefix that the provider was registered with.
+type SecretProviderFunc func(ctx context.Context, res *Resources, ref string) (string, error)
+
+var secretProviderNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+var secretProviders = struct {
+	sync.RWMutex
+	m map[string]SecretProviderFunc
+}{
+	m: map[string]SecretProviderFunc{
+		"env":  envSecretProvider,
+		"file": fileSecretProvider,
+		"exec": execSecretProvider,
+	},
+}
+
+// RegisterSecretProvider adds a secret provider that resolves the values of
+// fields defined with NewSecretField that are prefixed with `<scheme>://`. The
+// providers `env`, `file` and `exec` are registered by default, and
+// registering a provider with the same scheme replaces it.
+func RegisterSecretProvider(scheme string, fn SecretProviderFunc) error {
+	if !secretProviderNameRegexp.MatchString(scheme) {
+		return fmt.Errorf("secret provider scheme '%v' is invalid, expected lowercase alphanumeric characters and underscores", scheme)
+	}
+	if fn == nil {
+		return errors.New("secret provider func must not be nil")
+	}
+
+	secretProviders.Lock()
+	secretProviders.m[scheme] = fn
+	secretProviders.Unlock()
+	return nil
+}
+
+func getSecretProvider(value string) (fn SecretProviderFunc, ref string, exists bool) {
+	scheme, ref, found := strings.Cut(value, "://")
+	if !found {
+		return nil, "", false
+	}
+
+	secretProviders.RLock()
+	fn, exists = secretProviders.m[scheme]
+	secretProviders.RUnlock()
+	return
+}
+
+func envSecretProvider(ctx context.Context, res *Resources, ref string) (string, error) {
+	v, exists := os.LookupEnv(ref)
+	if !exists {
+		return "", fmt.Errorf("environment variable '%v' is not set", ref)
+	}
+	return v, nil
+}
+
+func fileSecretProvider(ctx context.Context, res *Resources, ref string) (string, error) {
+	b, err := ifs.ReadFile(res.FS().i, ref)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+func execSecretProvider(ctx context.Context, res *Resources, ref string) (string, error) {
+	args := strings.Fields(ref)
+	if len(args) == 0 {
+		return "", errors.New("a command must be specified")
+	}
+
+	b, err := exec.CommandContext(ctx, args[0], args[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("command '%v' failed: %w", args[0], err)
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+//------------------------------------------------------------------------------
+
+// Secret is a handle to a resolved secret value that was extracted from a field
+// defined with NewSecretField. When the secret was resolved via a provider it
+// can be refreshed, which informs registered callbacks when the value rotates.
+type Secret struct {
+	res *Resources
+	fn  SecretProviderFunc
+	ref string
+
+	mut      sync.RWMutex
+	value    string
+	onRotate []func(value string)
+}
+
+// Value returns the current value of the secret.
+func (s *Secret) Value() string {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	return s.value
+}
+
+// String returns a scrubbed representation of the secret in order to avoid
+// accidentally leaking it within logs, use Value in order to obtain the secret
+// itself.
+func (s *Secret) String() string {
+	return "!!!SECRET_SCRUBBED!!!"
+}
+
+// OnRotate registers a callback to be called with the new value of the secret
+// whenever a call to Refresh results in a changed value.
+func (s *Secret) OnRotate(fn func(value string)) {
+	s.mut.Lock()
+	s.onRotate = append(s.onRotate, fn)
+	s.mut.Unlock()
+}
+
+// Refresh resolves the secret from its provider again and, if the value has
+// changed, calls all callbacks registered with OnRotate. Secrets that were
+// provided as literal values are never rotated.
+func (s *Secret) Refresh(ctx context.Context) error {
+	if s.fn == nil {
+		return nil
+	}
+
+	v, err := s.fn(ctx, s.res, s.ref)
+	if err != nil {
+		return err
+	}
+
+	s.mut.Lock()
+	if v == s.value {
+		s.mut.Unlock()
+		return nil
+	}
+	s.value = v
+	callbacks := make([]func(string), len(s.onRotate))
+	copy(callbacks, s.onRotate)
+	s.mut.Unlock()
+
+	for _, fn := range callbacks {
+		fn(v)
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// NewSecretField defines a new string type config field that contains a
+// sensitive value, which is scrubbed from echoed configs. The value can either
+// be provided as a literal or as a reference in the form `<scheme>://<ref>`,
+// where the scheme corresponds to a secret provider, in which case the value is
+// resolved when the field is accessed. The following providers are available
+// by default:
+//
+// - `env://NAME` resolves the value of an environment variable.
+// - `file://path` reads the contents of a file, without trailing newlines.
+// - `exec://command args` executes a command and captures its output, without
+// trailing newlines.
+//
+// Custom providers can be added with RegisterSecretProvider, and the secret can
+// be extracted from the resulting parsed config with the method FieldSecret.
+func NewSecretField(name string) *ConfigField {
+	tf := docs.FieldString(name, "").Secret()
+
+	// Provider references are not themselves sensitive and are therefore
+	// preserved when the field is scrubbed. Providers are checked at the time
+	// of scrubbing as they may be registered after the field is defined.
+	secretScrubber := tf
+	tf = tf.ScrubberFunc(func(v any) (any, error) {
+		if s, ok := v.(string); ok {
+			if _, _, exists := getSecretProvider(s); exists {
+				return v, nil
+			}
+		}
+		return secretScrubber.ScrubValue(v)
+	})
+
+	return &ConfigField{field: tf}
+}
+
+// FieldSecret accesses a field from a parsed config that was defined with
+// NewSecretField and returns a *Secret, resolving the value from a secret
+// provider if required, or an error if the secret could not be resolved.
+func (p *ParsedConfig) FieldSecret(path ...string) (*Secret, error) {
+	v, err := p.FieldString(path...)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Secret{
+		res:   newResourcesFromManager(p.mgr),
+		value: v,
+	}
+
+	var exists bool
+	if s.fn, s.ref, exists = getSecretProvider(v); !exists {
+		return s, nil
+	}
+
+	if s.value, err = s.fn(context.Background(), s.res, s.ref); err != nil {
+		return nil, fmt.Errorf("failed to resolve secret field '%v': %w", p.i.FullDotPath(path...), err)
+	}
+	return s, nil
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -432,3 +433,244 @@ func TestConfigFields(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "evalue", e)
 }
+
+func TestConfigLintBounds(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewIntField("a").LintMin(1).Optional()).
+		Field(NewIntField("b").LintMin(1).LintMax(10).Optional()).
+		Field(NewFloatField("c").LintMax(0.5).Optional()).
+		Field(NewDurationField("d").LintMin(time.Second).Optional()).
+		Field(NewDurationField("e").LintMin(time.Millisecond).LintMax(time.Minute).Optional())
+
+	for _, test := range []struct {
+		config string
+		lint   string
+	}{
+		{config: `a: 1`},
+		{config: `a: 0`, lint: "value 0 is less than the minimum of 1"},
+		{config: `b: 10`},
+		{config: `b: 11`, lint: "value 11 is greater than the maximum of 10"},
+		{config: `c: 0.5`},
+		{config: `c: 0.75`, lint: "value 0.75 is greater than the maximum of 0.5"},
+		{config: `d: 1s`},
+		{config: `d: 0s`, lint: "value 0s is less than the minimum of 1s"},
+		{config: `d: 500ms`, lint: "value 500ms is less than the minimum of 1s"},
+		{config: `e: 30s`},
+		{config: `e: 0s`, lint: "value 0s is less than the minimum of 1ms"},
+		{config: `e: 1h`, lint: "value 1h is greater than the maximum of 1m0s"},
+	} {
+		node, err := NewStreamBuilder().getYAMLNode([]byte(test.config))
+		require.NoError(t, err)
+
+		lints := spec.component.Config.Children.LintYAML(docs.NewLintContext(docs.NewLintConfig(bundle.GlobalEnvironment)), node)
+		if test.lint == "" {
+			assert.Empty(t, lints, test.config)
+		} else {
+			require.Len(t, lints, 1, test.config)
+			assert.Equal(t, test.lint, lints[0].What, test.config)
+		}
+	}
+}
+
+func TestConfigLintBoundsTypes(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewIntField("a").LintMin(int32(1)).LintMax(uint(10)).Optional()).
+		Field(NewFloatField("b").LintMin(float32(0.5)).Optional())
+
+	for _, test := range []struct {
+		config string
+		lint   string
+	}{
+		{config: `a: 5`},
+		{config: `a: 0`, lint: "value 0 is less than the minimum of 1"},
+		{config: `a: 11`, lint: "value 11 is greater than the maximum of 10"},
+		{config: `b: 0.5`},
+		{config: `b: 0.25`, lint: "value 0.25 is less than the minimum of 0.5"},
+	} {
+		node, err := NewStreamBuilder().getYAMLNode([]byte(test.config))
+		require.NoError(t, err)
+
+		lints := spec.component.Config.Children.LintYAML(docs.NewLintContext(docs.NewLintConfig(bundle.GlobalEnvironment)), node)
+		if test.lint == "" {
+			assert.Empty(t, lints, test.config)
+		} else {
+			require.Len(t, lints, 1, test.config)
+			assert.Equal(t, test.lint, lints[0].What, test.config)
+		}
+	}
+
+	assert.PanicsWithValue(t, "unsupported lint bound 1s of type string, expected an integer, float or time.Duration", func() {
+		NewDurationField("c").LintMin("1s")
+	})
+	assert.PanicsWithValue(t, "mismatched lint bounds 1s (time.Duration) and 10 (int), both must either be durations or numbers", func() {
+		NewDurationField("c").LintMin(time.Second).LintMax(10)
+	})
+	assert.PanicsWithValue(t, "mismatched lint bounds 1 (int) and 1m0s (time.Duration), both must either be durations or numbers", func() {
+		NewIntField("c").LintMax(time.Minute).LintMin(1)
+	})
+}
+
+func TestConfigLintBoundsWithRule(t *testing.T) {
+	evenRule := `root = if this % 2 != 0 { [ "value must be even" ] }`
+
+	spec := NewConfigSpec().
+		Field(NewIntField("a").LintRule(evenRule).LintMin(2).Optional()).
+		Field(NewIntField("b").LintMax(10).LintRule(evenRule).Optional())
+
+	for _, test := range []struct {
+		config string
+		lints  []string
+	}{
+		{config: `a: 4`},
+		{config: `a: 3`, lints: []string{"value must be even"}},
+		{config: `a: 0`, lints: []string{"value 0 is less than the minimum of 2"}},
+		{config: `a: -1`, lints: []string{"value must be even", "value -1 is less than the minimum of 2"}},
+		{config: `b: 8`},
+		{config: `b: 7`, lints: []string{"value must be even"}},
+		{config: `b: 12`, lints: []string{"value 12 is greater than the maximum of 10"}},
+	} {
+		node, err := NewStreamBuilder().getYAMLNode([]byte(test.config))
+		require.NoError(t, err)
+
+		var whats []string
+		for _, l := range spec.component.Config.Children.LintYAML(docs.NewLintContext(docs.NewLintConfig(bundle.GlobalEnvironment)), node) {
+			whats = append(whats, l.What)
+		}
+		assert.Equal(t, test.lints, whats, test.config)
+	}
+}
+
+func TestConfigLintRulesWithConstructorLinter(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewURLField("a").LintRule(`root = if !this.has_prefix("https") { [ "url must use https" ] }`).Optional()).
+		Field(NewCronField("b").LintRule(`root = if this.has_prefix("@") { [ "descriptors are not allowed" ] }`).Optional()).
+		Field(NewByteSizeField("c").LintMin(10).Optional()).
+		Field(NewURLField("d").LintMax(10).LintRule("").Optional())
+
+	for _, test := range []struct {
+		config string
+		lints  []string
+	}{
+		{config: `a: https://example.com`},
+		{config: `a: http://example.com`, lints: []string{"url must use https"}},
+		{config: `a: "%"`, lints: []string{`failed to parse url: parse "%": invalid URL escape "%"`, "url must use https"}},
+		{config: `b: "0 * * * *"`},
+		{config: `b: "@every 1h"`, lints: []string{"descriptors are not allowed"}},
+		{config: `b: "nope"`, lints: []string{"failed to parse cron expression: expected 5 to 6 fields, found 1: [nope]"}},
+		{config: `c: 20`},
+		{config: `c: nope`, lints: []string{"value nope is not a valid byte size, expected a number followed by an optional unit such as KB or MiB"}},
+		{config: `d: "%"`, lints: []string{`failed to parse url: parse "%": invalid URL escape "%"`}},
+	} {
+		node, err := NewStreamBuilder().getYAMLNode([]byte(test.config))
+		require.NoError(t, err)
+
+		var whats []string
+		for _, l := range spec.component.Config.Children.LintYAML(docs.NewLintContext(docs.NewLintConfig(bundle.GlobalEnvironment)), node) {
+			whats = append(whats, l.What)
+		}
+		assert.Equal(t, test.lints, whats, test.config)
+	}
+}
+
+func TestConfigRenamedFields(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewStringField("a").RenamedFrom("old_a")).
+		Field(NewObjectField("b",
+			NewIntField("c").RenamedFrom("old_c").RenamedFrom("older_c"),
+		))
+
+	parsed, err := spec.ParseYAML(`
+old_a: foo
+b:
+  older_c: 10
+`, nil)
+	require.NoError(t, err)
+
+	a, err := parsed.FieldString("a")
+	require.NoError(t, err)
+	assert.Equal(t, "foo", a)
+
+	c, err := parsed.FieldInt("b", "c")
+	require.NoError(t, err)
+	assert.Equal(t, 10, c)
+
+	parsed, err = spec.ParseYAML(`
+a: foo
+old_a: bar
+b:
+  c: 20
+  old_c: 30
+`, nil)
+	require.NoError(t, err)
+
+	a, err = parsed.FieldString("a")
+	require.NoError(t, err)
+	assert.Equal(t, "foo", a)
+
+	c, err = parsed.FieldInt("b", "c")
+	require.NoError(t, err)
+	assert.Equal(t, 20, c)
+}
+
+func TestConfigFieldRequiresAndConflicts(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewStringField("username").Optional().Requires("password")).
+		Field(NewStringField("password").Optional().Secret()).
+		Field(NewStringField("token").Optional().ConflictsWith("username")).
+		Field(NewObjectField("nested",
+			NewStringField("a").Default("").ConflictsWith("b"),
+			NewStringField("b").Default(""),
+		).Optional())
+
+	for _, test := range []struct {
+		config string
+		lints  []string
+	}{
+		{config: `{}`},
+		{config: `password: foo`},
+		{config: `{ username: foo, password: bar }`},
+		{config: `username: foo`, lints: []string{"field password is required when username is set"}},
+		{config: `token: foo`},
+		{
+			config: `{ username: foo, password: bar, token: baz }`,
+			lints:  []string{"field token cannot be set alongside username"},
+		},
+		{config: `nested: { a: foo }`},
+		{config: `nested: { a: foo, b: bar }`, lints: []string{"field a cannot be set alongside b"}},
+	} {
+		node, err := NewStreamBuilder().getYAMLNode([]byte(test.config))
+		require.NoError(t, err)
+
+		var lints []string
+		for _, l := range spec.component.Config.Children.LintYAML(docs.NewLintContext(docs.NewLintConfig(bundle.GlobalEnvironment)), node) {
+			lints = append(lints, l.What)
+		}
+		assert.Equal(t, test.lints, lints, test.config)
+	}
+}
+
+func TestConfigDefaultFromEnv(t *testing.T) {
+	t.Setenv("TEST_CONFIG_DEFAULT_FROM_ENV", "2s")
+
+	spec := NewConfigSpec().
+		Field(NewDurationField("a").DefaultFromEnv("TEST_CONFIG_DEFAULT_FROM_ENV")).
+		Field(NewStringField("b").DefaultFromEnv("TEST_CONFIG_DEFAULT_FROM_ENV_UNSET").Default("static"))
+
+	parsed, err := spec.ParseYAML(`{}`, nil)
+	require.NoError(t, err)
+
+	a, err := parsed.FieldDuration("a")
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, a)
+
+	b, err := parsed.FieldString("b")
+	require.NoError(t, err)
+	assert.Equal(t, "static", b)
+
+	parsed, err = spec.ParseYAML(`a: 5s`, nil)
+	require.NoError(t, err)
+
+	a, err = parsed.FieldDuration("a")
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, a)
+}
@@ -9,18 +9,75 @@ import (
 	"github.com/redpanda-data/benthos/v4/internal/docs"
 )
 
+func urlLintFunc(splitCSV bool, allowedSchemes []string) docs.LintFunc {
+	return func(ctx docs.LintContext, line, col int, value any) (lints []docs.Lint) {
+		str, ok := value.(string)
+		if !ok {
+			return nil
+		}
+
+		strs := []string{str}
+		if splitCSV {
+			strs = strings.Split(str, ",")
+		}
+		for _, s := range strs {
+			if s = strings.TrimSpace(s); s == "" {
+				continue
+			}
+			u, err := url.Parse(s)
+			if err != nil {
+				lints = append(lints, docs.NewLintError(line, docs.LintCustom, fmt.Errorf("failed to parse url: %w", err)))
+				continue
+			}
+			if err := checkURLScheme(u, allowedSchemes); err != nil {
+				lints = append(lints, docs.NewLintError(line, docs.LintCustom, err))
+			}
+		}
+		return
+	}
+}
+
+// checkURLScheme returns an error if the scheme of a URL is not one of a list
+// of allowed schemes, where an empty list allows any scheme. An empty URL is
+// treated as unset and is therefore allowed.
+func checkURLScheme(u *url.URL, allowedSchemes []string) error {
+	if len(allowedSchemes) == 0 || u.String() == "" {
+		return nil
+	}
+	for _, s := range allowedSchemes {
+		if strings.EqualFold(u.Scheme, s) {
+			return nil
+		}
+	}
+	return fmt.Errorf("url scheme '%v' is not allowed, expected one of: %v", u.Scheme, strings.Join(allowedSchemes, ", "))
+}
+
+// allowedURLSchemes returns the schemes that the URLs of a field are restricted
+// to, if any.
+func (p *ParsedConfig) allowedURLSchemes(path ...string) []string {
+	if spec := p.fieldSpec(path...); spec != nil {
+		return spec.AllowedURLSchemes()
+	}
+	return nil
+}
+
 // NewURLField defines a new config field that describes a string that should
 // contain a valid URL. It is then possible to extract either a string or a
 // *url.URL from the resulting parsed config with the methods FieldString or
 // FieldURL respectively.
-func NewURLField(name string) *ConfigField {
-	tf := docs.FieldURL(name, "")
+//
+// Optionally, a list of allowed schemes (e.g. `https`) can be provided, in
+// which case URLs with any other scheme are rejected at lint time and by
+// FieldURL. Note that the linting rules for URL fields are only effective
+// within the binary that defines them.
+func NewURLField(name string, allowedSchemes ...string) *ConfigField {
+	tf := docs.FieldURL(name, "").LinterFunc(urlLintFunc(false, allowedSchemes)).URLSchemes(allowedSchemes...)
 	return &ConfigField{field: tf}
 }
 
 // FieldURL accesses a field from a parsed config that was defined with
 // NewURLField and returns either a *url.URL or an error if the string was
-// invalid.
+// invalid or its scheme is not allowed.
 func (p *ParsedConfig) FieldURL(path ...string) (*url.URL, error) {
 	v, exists := p.i.Field(path...)
 	if !exists {
@@ -36,6 +93,9 @@ func (p *ParsedConfig) FieldURL(path ...string) (*url.URL, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse url field '%v': %v", strings.Join(path, "."), err)
 	}
+	if err := checkURLScheme(u, p.allowedURLSchemes(path...)); err != nil {
+		return nil, fmt.Errorf("url field '%v': %w", strings.Join(path, "."), err)
+	}
 
 	return u, nil
 }
@@ -44,8 +104,13 @@ func (p *ParsedConfig) FieldURL(path ...string) (*url.URL, error) {
 // that should contain only valid URLs. It is then possible to extract either a
 // string slice or a slice of *url.URL from the resulting parsed config with the
 // methods FieldStringArray or FieldURLArray respectively.
-func NewURLListField(name string) *ConfigField {
-	tf := docs.FieldURL(name, "").Array()
+//
+// Optionally, a list of allowed schemes (e.g. `https`) can be provided, in
+// which case URLs with any other scheme are rejected at lint time and by
+// FieldURLList. Note that the linting rules for URL fields are only effective
+// within the binary that defines them.
+func NewURLListField(name string, allowedSchemes ...string) *ConfigField {
+	tf := docs.FieldURL(name, "").Array().LinterFunc(urlLintFunc(true, allowedSchemes)).URLSchemes(allowedSchemes...)
 	return &ConfigField{field: tf}
 }
 
@@ -65,8 +130,22 @@ func urlsFromStr(str string) (urls []*url.URL, err error) {
 
 // FieldURLList accesses a field from a parsed config that was defined with
 // NewURLListField and returns either a []*url.URL or an error if one or more
-// strings were invalid.
+// strings were invalid or have a scheme that is not allowed.
 func (p *ParsedConfig) FieldURLList(path ...string) ([]*url.URL, error) {
+	uList, err := p.fieldURLList(path...)
+	if err != nil {
+		return nil, err
+	}
+	allowedSchemes := p.allowedURLSchemes(path...)
+	for i, u := range uList {
+		if err := checkURLScheme(u, allowedSchemes); err != nil {
+			return nil, fmt.Errorf("url field '%v': %w", strings.Join(path, ".")+"."+strconv.Itoa(i), err)
+		}
+	}
+	return uList, nil
+}
+
+func (p *ParsedConfig) fieldURLList(path ...string) ([]*url.URL, error) {
 	v, exists := p.i.Field(path...)
 	if !exists {
 		return nil, fmt.Errorf("field '%v' was not found in the config", strings.Join(path, "."))
@@ -96,7 +175,7 @@ func (p *ParsedConfig) FieldURLList(path ...string) ([]*url.URL, error) {
 	for i, ev := range iList {
 		switch t := ev.(type) {
 		case *url.URL:
-			uList[i] = t
+			uList = append(uList, t)
 		case string:
 			urls, err := urlsFromStr(t)
 			if err != nil {
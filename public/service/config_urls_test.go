@@ -6,6 +6,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/bundle"
+	"github.com/redpanda-data/benthos/v4/internal/docs"
 )
 
 func TestURLListField(t *testing.T) {
@@ -79,3 +82,89 @@ func TestURLListField(t *testing.T) {
 		})
 	}
 }
+
+func TestURLFieldLints(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewURLField("a").Optional()).
+		Field(NewURLField("b", "https").Optional()).
+		Field(NewURLListField("c", "http", "https").Optional())
+
+	for _, test := range []struct {
+		config string
+		lints  []string
+	}{
+		{config: `a: http://example.com`},
+		{config: `a: ""`},
+		{config: `a: http://[::1`, lints: []string{"failed to parse url"}},
+		{config: `b: https://example.com`},
+		{config: `b: HTTPS://example.com`},
+		{config: `b: http://example.com`, lints: []string{"url scheme 'http' is not allowed, expected one of: https"}},
+		{config: `c: [ http://example.com, "https://foo,https://bar" ]`},
+		{
+			config: `c: [ ftp://example.com, "https://foo,nats://bar" ]`,
+			lints: []string{
+				"url scheme 'ftp' is not allowed, expected one of: http, https",
+				"url scheme 'nats' is not allowed, expected one of: http, https",
+			},
+		},
+	} {
+		node, err := NewStreamBuilder().getYAMLNode([]byte(test.config))
+		require.NoError(t, err)
+
+		lints := spec.component.Config.Children.LintYAML(docs.NewLintContext(docs.NewLintConfig(bundle.GlobalEnvironment)), node)
+		require.Len(t, lints, len(test.lints), test.config)
+		for i, l := range test.lints {
+			assert.Contains(t, lints[i].What, l, test.config)
+		}
+	}
+}
+
+func TestURLFieldSchemes(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewURLField("a", "https").Optional()).
+		Field(NewURLListField("b", "http", "https").Optional()).
+		Field(NewObjectField("c", NewURLField("d", "https").Optional()).Optional())
+
+	for _, test := range []struct {
+		config      string
+		path        []string
+		list        bool
+		errContains string
+	}{
+		{config: `a: https://example.com`, path: []string{"a"}},
+		{config: `a: HTTPS://example.com`, path: []string{"a"}},
+		{config: `a: ""`, path: []string{"a"}},
+		{config: `a: file:///etc/passwd`, path: []string{"a"}, errContains: "url scheme 'file' is not allowed, expected one of: https"},
+		{config: `b: [ http://example.com, "https://foo,https://bar" ]`, path: []string{"b"}, list: true},
+		{config: `b: [ http://example.com, "https://foo,gopher://bar" ]`, path: []string{"b"}, list: true, errContains: "url scheme 'gopher' is not allowed, expected one of: http, https"},
+		{config: `c: { d: https://example.com }`, path: []string{"c", "d"}},
+		{config: `c: { d: http://example.com }`, path: []string{"c", "d"}, errContains: "url scheme 'http' is not allowed, expected one of: https"},
+	} {
+		// Parsing does not lint, and therefore the schemes must be enforced
+		// when the field is accessed.
+		parsed, err := spec.ParseYAML(test.config, nil)
+		require.NoError(t, err, test.config)
+
+		if test.list {
+			_, err = parsed.FieldURLList(test.path...)
+		} else {
+			_, err = parsed.FieldURL(test.path...)
+		}
+		if test.errContains == "" {
+			assert.NoError(t, err, test.config)
+		} else {
+			require.Error(t, err, test.config)
+			assert.Contains(t, err.Error(), test.errContains, test.config)
+		}
+
+		// Also via a namespace of the parsed config.
+		if len(test.path) > 1 && !test.list {
+			_, err = parsed.Namespace(test.path[:len(test.path)-1]...).FieldURL(test.path[len(test.path)-1])
+			if test.errContains == "" {
+				assert.NoError(t, err, test.config)
+			} else {
+				assert.Error(t, err, test.config)
+			}
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	ibloblang "github.com/redpanda-data/benthos/v4/internal/bloblang"
 	"github.com/redpanda-data/benthos/v4/internal/bundle"
+	"github.com/redpanda-data/benthos/v4/internal/component"
 	"github.com/redpanda-data/benthos/v4/internal/component/buffer"
 	"github.com/redpanda-data/benthos/v4/internal/component/cache"
 	"github.com/redpanda-data/benthos/v4/internal/component/input"
@@ -321,11 +322,14 @@ func (e *Environment) RegisterOutput(name string, spec *ConfigSpec, ctor OutputC
 			if maxInFlight < 1 {
 				return nil, fmt.Errorf("invalid maxInFlight parameter: %v", maxInFlight)
 			}
-			w := newAirGapWriter(op)
+			w := nm.Environment().OutputWrapSink(name, nm, newAirGapWriter(op))
 			o, err := output.NewAsyncWriter(conf.Type, maxInFlight, w, nm)
 			if err != nil {
 				return nil, err
 			}
+			if outputOrderedAcks(pluginConf) {
+				o = output.WithOrderedAcks(o)
+			}
 			return output.OnlySinglePayloads(o), nil
 		},
 	), componentSpec)
@@ -360,23 +364,102 @@ func (e *Environment) RegisterBatchOutput(name string, spec *ConfigSpec, ctor Ba
 			if u, ok := op.(interface {
 				Unwrap() output.Streamed
 			}); ok {
-				return u.Unwrap(), nil
+				o := u.Unwrap()
+				if !outputIsComposite(componentSpec) {
+					if o, err = wrapStreamedWithMiddleware(conf.Type, name, maxInFlight, o, nm); err != nil {
+						return nil, err
+					}
+				}
+				if outputOrderedAcks(pluginConf) {
+					o = output.WithOrderedAcks(o)
+				}
+				return o, nil
 			}
 
 			if maxInFlight < 1 {
 				return nil, fmt.Errorf("invalid maxInFlight parameter: %v", maxInFlight)
 			}
 
-			w := newAirGapBatchWriter(op)
+			w := nm.Environment().OutputWrapSink(name, nm, newAirGapBatchWriter(op))
 			o, err := output.NewAsyncWriter(conf.Type, maxInFlight, w, nm)
 			if err != nil {
 				return nil, err
 			}
+			if outputOrderedAcks(pluginConf) {
+				o = output.WithOrderedAcks(o)
+			}
 			return batcher.NewFromConfig(batchPolicy.toInternal(), o, nm)
 		},
 	), componentSpec)
 }
 
+// RegisterOutputMiddleware adds a middleware that is applied to each
+// instantiation of an output plugin within a config. When type names are
+// provided the middleware is only applied to outputs of those types, otherwise
+// it is applied to outputs of all types.
+//
+// Middleware are applied to all outputs other than those that are composed of
+// other outputs, such as brokers and resource references, which are not
+// wrapped themselves but their children are. Middleware are applied in the
+// order that they are registered, and therefore the last middleware registered
+// is the first to intercept a write.
+func (e *Environment) RegisterOutputMiddleware(fn OutputMiddleware, typeNames ...string) {
+	var names map[string]struct{}
+	if len(typeNames) > 0 {
+		names = make(map[string]struct{}, len(typeNames))
+		for _, n := range typeNames {
+			names[n] = struct{}{}
+		}
+	}
+	e.internal.OutputAddSinkMiddleware(func(typeName string, mgr bundle.NewManagement, sink output.AsyncSink) output.AsyncSink {
+		if names != nil {
+			if _, exists := names[typeName]; !exists {
+				return sink
+			}
+		}
+		return newAirGapBatchWriter(fn(typeName, newResourcesFromManager(mgr), newReverseAirGapBatchWriter(sink)))
+	})
+}
+
+// outputIsComposite returns whether an output spec describes an output that is
+// composed of other outputs, either by containing output fields or by
+// referencing an output resource, in which case middleware are applied to the
+// children rather than the output itself.
+func outputIsComposite(spec docs.ComponentSpec) bool {
+	if spec.Name == "resource" {
+		return true
+	}
+	var hasOutputs func(f docs.FieldSpec) bool
+	hasOutputs = func(f docs.FieldSpec) bool {
+		if f.Type == docs.FieldTypeOutput {
+			return true
+		}
+		for _, c := range f.Children {
+			if hasOutputs(c) {
+				return true
+			}
+		}
+		return false
+	}
+	return hasOutputs(spec.Config)
+}
+
+// wrapStreamedWithMiddleware applies the output middleware of the environment
+// to an output that is only available as a Streamed. When no middleware applies
+// to the output it is returned unchanged.
+func wrapStreamedWithMiddleware(typeStr, name string, maxInFlight int, o output.Streamed, nm bundle.NewManagement) (output.Streamed, error) {
+	sink := output.NewStreamedSink(o)
+	w := nm.Environment().OutputWrapSink(name, nm, sink)
+	if w == output.AsyncSink(sink) {
+		return o, nil
+	}
+	if maxInFlight < 1 {
+		maxInFlight = 1
+	}
+	// The wrapped output already emits its own metrics, logs and traces.
+	return output.NewAsyncWriter(typeStr, maxInFlight, w, component.NoopObservability())
+}
+
 // WalkOutputs executes a provided function argument for every output component
 // that has been registered to the environment.
 func (e *Environment) WalkOutputs(fn func(name string, config *ConfigView)) {
@@ -1,12 +1,14 @@
 package service_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"testing/fstest"
 	"time"
@@ -220,3 +222,205 @@ output:
 world
 `, string(outBytes))
 }
+
+type recordingOutput struct {
+	mut  *sync.Mutex
+	msgs *[]string
+}
+
+func (r recordingOutput) Connect(ctx context.Context) error {
+	return nil
+}
+
+func (r recordingOutput) Write(ctx context.Context, msg *service.Message) error {
+	mBytes, err := msg.AsBytes()
+	if err != nil {
+		return err
+	}
+	r.mut.Lock()
+	*r.msgs = append(*r.msgs, string(mBytes))
+	r.mut.Unlock()
+	return nil
+}
+
+func (r recordingOutput) WriteBatch(ctx context.Context, batch service.MessageBatch) error {
+	for _, m := range batch {
+		if err := r.Write(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r recordingOutput) Close(ctx context.Context) error {
+	return nil
+}
+
+type middlewareOutput struct {
+	service.BatchOutput
+	writeFn func(ctx context.Context, batch service.MessageBatch) error
+}
+
+func (m middlewareOutput) WriteBatch(ctx context.Context, batch service.MessageBatch) error {
+	return m.writeFn(ctx, batch)
+}
+
+func TestEnvironmentOutputMiddleware(t *testing.T) {
+	env := service.NewEnvironment()
+
+	var mut sync.Mutex
+	var batchMsgs, singleMsgs, wrappedTypes []string
+
+	require.NoError(t, env.RegisterBatchOutput("batch_recorder", service.NewConfigSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchOutput, service.BatchPolicy, int, error) {
+			return recordingOutput{mut: &mut, msgs: &batchMsgs}, service.BatchPolicy{}, 1, nil
+		}))
+	require.NoError(t, env.RegisterOutput("single_recorder", service.NewConfigSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Output, int, error) {
+			return recordingOutput{mut: &mut, msgs: &singleMsgs}, 1, nil
+		}))
+
+	env.RegisterOutputMiddleware(func(typeName string, mgr *service.Resources, next service.BatchOutput) service.BatchOutput {
+		mut.Lock()
+		wrappedTypes = append(wrappedTypes, typeName)
+		mut.Unlock()
+		return next
+	})
+
+	env.RegisterOutputMiddleware(func(typeName string, mgr *service.Resources, next service.BatchOutput) service.BatchOutput {
+		return middlewareOutput{
+			BatchOutput: next,
+			writeFn: func(ctx context.Context, batch service.MessageBatch) error {
+				for _, m := range batch {
+					mBytes, err := m.AsBytes()
+					if err != nil {
+						return err
+					}
+					if string(mBytes) == "reject" {
+						return errors.New("rejected by middleware")
+					}
+					m.SetBytes(bytes.ToUpper(mBytes))
+				}
+				return next.WriteBatch(ctx, batch)
+			},
+		}
+	}, "batch_recorder")
+
+	strmBuilder := env.NewStreamBuilder()
+	require.NoError(t, strmBuilder.SetLoggerYAML("level: NONE"))
+	require.NoError(t, strmBuilder.AddOutputYAML(`
+broker:
+  pattern: fan_out_fail_fast
+  outputs:
+    - batch_recorder: {}
+    - single_recorder: {}
+`))
+
+	pFn, err := strmBuilder.AddProducerFunc()
+	require.NoError(t, err)
+
+	strm, err := strmBuilder.Build()
+	require.NoError(t, err)
+
+	go func() {
+		_ = strm.Run(context.Background())
+	}()
+
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, pFn(tCtx, service.NewMessage([]byte("hello"))))
+	require.NoError(t, pFn(tCtx, service.NewMessage([]byte("world"))))
+	require.Error(t, pFn(tCtx, service.NewMessage([]byte("reject"))))
+
+	require.NoError(t, strm.StopWithin(time.Second*5))
+
+	mut.Lock()
+	defer mut.Unlock()
+
+	assert.Equal(t, []string{"HELLO", "WORLD"}, batchMsgs)
+	// The rejected message is acknowledged as soon as the batch recorder
+	// rejects it, and therefore it may not have reached the single recorder.
+	require.GreaterOrEqual(t, len(singleMsgs), 2)
+	assert.Equal(t, []string{"hello", "world"}, singleMsgs[:2])
+	assert.ElementsMatch(t, []string{"batch_recorder", "single_recorder"}, wrappedTypes)
+}
+
+func TestEnvironmentOutputMiddlewareUnwrapped(t *testing.T) {
+	env := service.NewEnvironment()
+
+	var mut sync.Mutex
+	var wrappedTypes, droppedMsgs []string
+
+	env.RegisterOutputMiddleware(func(typeName string, mgr *service.Resources, next service.BatchOutput) service.BatchOutput {
+		mut.Lock()
+		wrappedTypes = append(wrappedTypes, typeName)
+		mut.Unlock()
+		return next
+	})
+
+	env.RegisterOutputMiddleware(func(typeName string, mgr *service.Resources, next service.BatchOutput) service.BatchOutput {
+		return middlewareOutput{
+			BatchOutput: next,
+			writeFn: func(ctx context.Context, batch service.MessageBatch) error {
+				for _, m := range batch {
+					mBytes, err := m.AsBytes()
+					if err != nil {
+						return err
+					}
+					if string(mBytes) == "reject" {
+						return errors.New("rejected by middleware")
+					}
+					mut.Lock()
+					droppedMsgs = append(droppedMsgs, string(mBytes))
+					mut.Unlock()
+				}
+				return next.WriteBatch(ctx, batch)
+			},
+		}
+	}, "drop")
+
+	strmBuilder := env.NewStreamBuilder()
+	require.NoError(t, strmBuilder.SetLoggerYAML("level: NONE"))
+	require.NoError(t, strmBuilder.AddResourcesYAML(`
+output_resources:
+  - label: foo
+    cache:
+      target: mem
+      key: ${! content() }
+cache_resources:
+  - label: mem
+    memory: {}
+`))
+	require.NoError(t, strmBuilder.AddOutputYAML(`
+broker:
+  pattern: fan_out_fail_fast
+  outputs:
+    - drop: {}
+    - resource: foo
+`))
+
+	pFn, err := strmBuilder.AddProducerFunc()
+	require.NoError(t, err)
+
+	strm, err := strmBuilder.Build()
+	require.NoError(t, err)
+
+	go func() {
+		_ = strm.Run(context.Background())
+	}()
+
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, pFn(tCtx, service.NewMessage([]byte("hello"))))
+	require.Error(t, pFn(tCtx, service.NewMessage([]byte("reject"))))
+
+	require.NoError(t, strm.StopWithin(time.Second*5))
+
+	mut.Lock()
+	defer mut.Unlock()
+
+	assert.Equal(t, []string{"hello"}, droppedMsgs)
+	assert.ElementsMatch(t, []string{"drop", "cache"}, wrappedTypes)
+}
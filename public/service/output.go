@@ -6,6 +6,7 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/redpanda-data/benthos/v4/internal/component"
 	"github.com/redpanda-data/benthos/v4/internal/component/output"
 	"github.com/redpanda-data/benthos/v4/internal/component/output/batcher"
 	"github.com/redpanda-data/benthos/v4/internal/message"
@@ -123,6 +124,48 @@ func (a *airGapBatchWriter) Close(ctx context.Context) error {
 
 //------------------------------------------------------------------------------
 
+// Implements BatchOutput around an output.AsyncSink.
+type reverseAirGapBatchWriter struct {
+	s output.AsyncSink
+}
+
+func newReverseAirGapBatchWriter(s output.AsyncSink) *reverseAirGapBatchWriter {
+	return &reverseAirGapBatchWriter{s: s}
+}
+
+func (r *reverseAirGapBatchWriter) Connect(ctx context.Context) error {
+	return internalToPublicOutputErr(r.s.Connect(ctx))
+}
+
+func (r *reverseAirGapBatchWriter) WriteBatch(ctx context.Context, b MessageBatch) error {
+	payload := make(message.Batch, len(b))
+	for i, m := range b {
+		payload[i] = m.part
+	}
+	return internalToPublicOutputErr(r.s.WriteBatch(ctx, payload))
+}
+
+func (r *reverseAirGapBatchWriter) Close(ctx context.Context) error {
+	return r.s.Close(ctx)
+}
+
+func internalToPublicOutputErr(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var e *component.ErrBackOff
+	if errors.As(err, &e) {
+		return &ErrBackOff{Err: internalToPublicOutputErr(e.Err), Wait: e.Wait}
+	}
+	if errors.Is(err, component.ErrNotConnected) {
+		return ErrNotConnected
+	}
+	return toPublicBatchError(err)
+}
+
+//------------------------------------------------------------------------------
+
 // ResourceOutput provides access to an output resource.
 type ResourceOutput struct {
 	o output.Sync
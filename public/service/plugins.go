@@ -107,6 +107,27 @@ func RegisterBatchOutput(name string, spec *ConfigSpec, ctor BatchOutputConstruc
 	return globalEnvironment.RegisterBatchOutput(name, spec, ctor)
 }
 
+// OutputMiddleware is a func that's provided the type name of an output
+// plugin, access to a service manager, and the output itself, and returns an
+// output that wraps it. This allows the connection attempts and writes of
+// outputs to be intercepted, for example in order to audit, transform or
+// reject messages.
+type OutputMiddleware func(typeName string, mgr *Resources, next BatchOutput) BatchOutput
+
+// RegisterOutputMiddleware adds a middleware that is applied to each
+// instantiation of an output plugin within a config. When type names are
+// provided the middleware is only applied to outputs of those types, otherwise
+// it is applied to outputs of all types.
+//
+// Middleware are applied to all outputs other than those that are composed of
+// other outputs, such as brokers and resource references, which are not
+// wrapped themselves but their children are. Middleware are applied in the
+// order that they are registered, and therefore the last middleware registered
+// is the first to intercept a write.
+func RegisterOutputMiddleware(fn OutputMiddleware, typeNames ...string) {
+	globalEnvironment.RegisterOutputMiddleware(fn, typeNames...)
+}
+
 // ProcessorConstructor is a func that's provided a configuration type and
 // access to a service manager and must return an instantiation of a processor
 // based on the config, or an error.
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io/fs"
 	"time"
 
@@ -135,6 +137,15 @@ func (f *wrapperFS) MkdirAll(path string, perm fs.FileMode) error {
 	return f.fallback.MkdirAll(path, perm)
 }
 
+// Rename renames (moves) a file when supported by the fallback filesystem.
+func (f *wrapperFS) Rename(oldpath, newpath string) error {
+	r, ok := f.fallback.(ifs.Renamer)
+	if !ok {
+		return fmt.Errorf("rename %v: %w", oldpath, errors.ErrUnsupported)
+	}
+	return r.Rename(oldpath, newpath)
+}
+
 // FS implements a superset of fs.FS and includes goodies that benthos
 // components specifically need.
 type FS struct {
@@ -170,6 +181,17 @@ func (f *FS) Remove(name string) error {
 	return f.i.Remove(name)
 }
 
+// Rename renames (moves) oldpath to newpath, replacing newpath if it already
+// exists. An error wrapping errors.ErrUnsupported is returned when the
+// filesystem does not support renaming files.
+func (f *FS) Rename(oldpath, newpath string) error {
+	r, ok := f.i.(ifs.Renamer)
+	if !ok {
+		return fmt.Errorf("rename %v: %w", oldpath, errors.ErrUnsupported)
+	}
+	return r.Rename(oldpath, newpath)
+}
+
 // MkdirAll creates a directory named path, along with any necessary parents,
 // and returns nil, or else returns an error.
 func (f *FS) MkdirAll(path string, perm fs.FileMode) error {